@@ -166,7 +166,8 @@ if ! echo "$TRANSCRIPT" | grep -q '"role":"assistant"'; then
 fi
 
 # === STOP CONDITION 1: All tasks complete (generic and legacy markers) ===
-if echo "$TRANSCRIPT" | grep -q "<promise>ALL_TASKS_COMPLETE</promise>"; then
+ALL_TASKS_COMPLETE_MARKER="${CLIVE_ALL_TASKS_COMPLETE_MARKER:-<promise>ALL_TASKS_COMPLETE</promise>}"
+if echo "$TRANSCRIPT" | grep -qF "$ALL_TASKS_COMPLETE_MARKER"; then
     echo "[STOP] All tasks complete - build loop finished successfully" >&2
     cleanup_state
     exit 0  # Allow normal exit
@@ -13,7 +13,6 @@ import type { ConductorConfig } from "./config.js";
 import type { ResourceGovernor } from "./resource-governor.js";
 import type { TaskRegistry } from "./task-registry.js";
 import {
-  COMPLETION_MARKERS,
   type AgentEntry,
   type AgentHealthCheck,
   type AgentType,
@@ -214,8 +213,10 @@ export class AgentManager {
     allComplete: boolean;
   } {
     return {
-      taskComplete: output.includes(COMPLETION_MARKERS.TASK_COMPLETE),
-      allComplete: output.includes(COMPLETION_MARKERS.ALL_TASKS_COMPLETE),
+      taskComplete: output.includes(this.config.contract.markers.taskComplete),
+      allComplete: output.includes(
+        this.config.contract.markers.allTasksComplete,
+      ),
     };
   }
 
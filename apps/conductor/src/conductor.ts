@@ -20,7 +20,6 @@ import type { PrMonitor } from "./pr-monitor.js";
 import type { SlackReporter } from "./slack-reporter.js";
 import type { TaskRegistry } from "./task-registry.js";
 import {
-  COMPLETION_MARKERS,
   type ConductorRequest,
   type TaskEntry,
 } from "./types.js";
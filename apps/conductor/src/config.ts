@@ -5,6 +5,7 @@
  */
 
 import "dotenv/config";
+import { type AgentContract, resolveAgentContract } from "@clive/agent-contract";
 
 export interface ConductorConfig {
   /** HTTP server port */
@@ -27,6 +28,8 @@ export interface ConductorConfig {
   maxRetries: number;
   /** Minutes of inactivity before an agent is considered stuck */
   stuckThresholdMinutes: number;
+  /** Completion markers emitted by clive-build */
+  contract: AgentContract;
 }
 
 export function loadConfig(): ConductorConfig {
@@ -41,5 +44,6 @@ export function loadConfig(): ConductorConfig {
     slackBotToken: process.env.SLACK_BOT_TOKEN,
     maxRetries: parseInt(process.env.CONDUCTOR_MAX_RETRIES || "3", 10),
     stuckThresholdMinutes: parseInt(process.env.CONDUCTOR_STUCK_THRESHOLD || "10", 10),
+    contract: resolveAgentContract(),
   };
 }
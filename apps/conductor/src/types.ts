@@ -89,12 +89,6 @@ export interface AcpxSpawnOptions {
   thread?: boolean;
 }
 
-/** Completion markers emitted by clive-build */
-export const COMPLETION_MARKERS = {
-  TASK_COMPLETE: "<promise>TASK_COMPLETE</promise>",
-  ALL_TASKS_COMPLETE: "<promise>ALL_TASKS_COMPLETE</promise>",
-} as const;
-
 /** Valid state transitions */
 export const STATE_TRANSITIONS: Record<TaskState, TaskState[]> = {
   pending: ["planning", "spawning", "failed"],
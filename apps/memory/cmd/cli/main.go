@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/cli"
+	"github.com/iammorganparry/clive/apps/memory/internal/tlsconfig"
+)
+
+func main() {
+	env := cli.EnvFromOS()
+	tlsCfg, err := tlsconfig.ClientFromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+	env.TLS = tlsCfg
+	os.Exit(cli.Run(env, os.Args[1:]))
+}
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
 	"github.com/iammorganparry/clive/apps/memory/internal/mcp"
+	"github.com/iammorganparry/clive/apps/memory/internal/tlsconfig"
 )
 
 func main() {
+	fixtures := flag.String("fixtures", os.Getenv("CLIVE_MEMORY_FIXTURES"),
+		"serve canned tool responses from this directory instead of the memory server")
+	flag.Parse()
+
 	serverURL := os.Getenv("MEMORY_SERVER_URL")
 	if serverURL == "" {
 		serverURL = "http://localhost:8741"
@@ -16,6 +22,35 @@ func main() {
 	namespace := os.Getenv("CLIVE_NAMESPACE")
 
 	server := mcp.NewServer(serverURL, namespace)
+	// The installers write CLIVE_MEMORY_API_KEY; MEMORY_API_KEY matches the CLI
+	apiKey := os.Getenv("CLIVE_MEMORY_API_KEY")
+	if apiKey == "" {
+		apiKey = os.Getenv("MEMORY_API_KEY")
+	}
+	server.SetAPIKey(apiKey)
+	// Set by the build loop for agents working on an epic
+	epicID := os.Getenv("CLIVE_EPIC_IDENTIFIER")
+	if epicID == "" {
+		epicID = os.Getenv("CLIVE_PARENT_ID")
+	}
+	server.SetDefaultEpic(epicID)
+	tlsCfg, err := tlsconfig.ClientFromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mcp server error: %s\n", err)
+		os.Exit(1)
+	}
+	if tlsCfg != nil {
+		server.SetTLSConfig(tlsCfg)
+	}
+	if *fixtures != "" {
+		if err := server.UseFixtures(*fixtures); err != nil {
+			fmt.Fprintf(os.Stderr, "mcp server error: %s\n", err)
+			os.Exit(1)
+		}
+	} else {
+		// Keeps the connectivity status current between tool calls
+		server.StartHeartbeat(mcp.DefaultHeartbeatInterval, nil)
+	}
 	if err := server.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "mcp server error: %s\n", err)
 		os.Exit(1)
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/config"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+// runCommand handles `memory-server export`, `import` and `site`. They
+// work on the database directly, so a backup, migration or publish needs
+// no running server. It returns the process exit code.
+func runCommand(args []string) int {
+	commands := map[string]func(context.Context, []string) error{
+		"export": runExport,
+		"import": runImport,
+		"site":   runSite,
+	}
+	run, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\nusage: memory-server [export|import|site]\n", args[0])
+		return 2
+	}
+	if err := run(context.Background(), args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		fmt.Fprintln(os.Stderr, "memory-server:", err)
+		return 1
+	}
+	return 0
+}
+
+func runExport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	var workspaces stringList
+	fs.Var(&workspaces, "workspace", "Workspace ID to export; repeatable (default all)")
+	out := fs.String("o", "-", "Archive file to write, - for stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: memory-server export [--workspace ID]... [-o FILE]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if len(workspaces) == 0 {
+		all, err := store.NewWorkspaceStore(db).ListWorkspaces(ctx)
+		if err != nil {
+			return err
+		}
+		for _, ws := range all {
+			workspaces = append(workspaces, ws.ID)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := store.NewArchiveStore(db).Export(ctx, workspaces, w); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "exported %d workspace(s)\n", len(workspaces))
+	return nil
+}
+
+func runImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: memory-server import FILE|-")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one archive file")
+	}
+
+	var r io.Reader = os.Stdin
+	if path := fs.Arg(0); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	report, longTerm, err := store.NewArchiveStore(db).Import(ctx, r)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	if len(longTerm) > 0 {
+		// Qdrant isn't touched offline; the server's vector reconciliation
+		// re-upserts these after the next compaction, or on demand
+		fmt.Fprintf(os.Stderr, "%d long-term memories need vectors: run POST /v1/vectors/reconcile once the server is up\n", len(longTerm))
+	}
+	return nil
+}
+
+func openDB() (*store.DB, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return store.Open(cfg.DBPath)
+}
+
+// stringList is a flag that may be given more than once.
+type stringList []string
+
+func (l *stringList) String() string { return fmt.Sprint(*l) }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
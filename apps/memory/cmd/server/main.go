@@ -2,27 +2,42 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/iammorganparry/clive/apps/memory/internal/api"
+	"github.com/iammorganparry/clive/apps/memory/internal/attachments"
 	"github.com/iammorganparry/clive/apps/memory/internal/config"
 	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
 	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
 	"github.com/iammorganparry/clive/apps/memory/internal/search"
+	"github.com/iammorganparry/clive/apps/memory/internal/seed"
 	"github.com/iammorganparry/clive/apps/memory/internal/sessions"
+	"github.com/iammorganparry/clive/apps/memory/internal/shutdown"
 	"github.com/iammorganparry/clive/apps/memory/internal/skills"
 	"github.com/iammorganparry/clive/apps/memory/internal/store"
 	"github.com/iammorganparry/clive/apps/memory/internal/threads"
+	"github.com/iammorganparry/clive/apps/memory/internal/tlsconfig"
+	"github.com/iammorganparry/clive/apps/memory/internal/tokens"
 	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
 )
 
 func main() {
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+	seedDir := flag.String("seed", "", "load fixture workspaces, memories and threads from this directory at startup")
+	flag.Parse()
+
 	// Logger
 	logLevel := slog.LevelInfo
 	if os.Getenv("LOG_LEVEL") == "debug" {
@@ -54,18 +69,27 @@ func main() {
 	linkStore := store.NewLinkStore(db)
 
 	// External services
-	ollamaClient := embedding.NewOllamaClient(cfg.OllamaBaseURL, cfg.EmbeddingModel)
+	textEmbedder := newEmbedder(cfg)
 	qdrantClient := vectorstore.NewQdrantClient(cfg.QdrantURL, cfg.EmbeddingDim)
 	collMgr := vectorstore.NewCollectionManager(qdrantClient)
+	shardStrategy, _ := cfg.ShardStrategy() // checked by config validation
+	collMgr.SetStrategy(shardStrategy)
+	collMgr.SetNamespaceResolver(workspaceStore.Namespace)
 
 	// Embedding with cache
-	embedder := embedding.NewCachedEmbedder(ollamaClient, embCacheStore, cfg.EmbeddingModel, cfg.EmbeddingDim)
+	embedder := embedding.NewCachedEmbedder(textEmbedder, embCacheStore, cfg.EmbeddingModel, cfg.EmbeddingDim)
 
 	// Search
 	searcher := search.NewHybridSearcher(
 		memoryStore, bm25Store, linkStore, qdrantClient, collMgr,
 		cfg.VectorWeight, cfg.BM25Weight, cfg.LongTermBoost,
 	)
+	var reranker search.Reranker
+	if cfg.RerankModel != "" {
+		reranker = newReranker(cfg)
+		searcher.SetReranker(reranker, cfg.RerankTopK)
+		logger.Info("search re-ranking enabled", "backend", cfg.RerankBackend, "model", cfg.RerankModel)
+	}
 
 	// Memory service
 	dedup := memory.NewDeduplicator(memoryStore, cfg.DedupThreshold)
@@ -73,17 +97,25 @@ func main() {
 		memoryStore, qdrantClient, collMgr,
 		cfg.PromotionAccessMin, cfg.PromotionConfidence, logger,
 	)
+	lifecycle.SetDemotion(cfg.CompactDemote)
+	lifecycle.SetConsolidation(cfg.CompactConsolidate)
 	svc := memory.NewService(
 		memoryStore, workspaceStore, bm25Store, embedder,
 		qdrantClient, collMgr, searcher, dedup, lifecycle,
 		cfg.ShortTermTTLHours, logger,
 	)
+	svc.SetImpactHalfLife(cfg.ImpactHalfLifeDays)
+	svc.SetQuota(memory.Quota{
+		MaxMemories: cfg.QuotaMaxMemories,
+		MaxBytes:    int64(cfg.QuotaMaxBytes),
+		Overflow:    cfg.QuotaOverflow,
+	})
 
 	// Ensure global workspace collection exists in Qdrant
 	if err := qdrantClient.HealthCheck(); err != nil {
 		logger.Warn("qdrant not available at startup, will retry on first use", "error", err)
 	} else {
-		if _, err := collMgr.EnsureForWorkspace("__global__"); err != nil {
+		if _, err := collMgr.EnsureForWorkspace(context.Background(), "__global__"); err != nil {
 			logger.Warn("failed to create global collection", "error", err)
 		}
 	}
@@ -91,20 +123,112 @@ func main() {
 	// Sessions
 	sessStore := sessions.NewSessionStore(db)
 	obsStore := sessions.NewObservationStore(db)
-	summarizer := sessions.NewSummarizer(cfg.OllamaBaseURL, cfg.SummaryModel, cfg.SummaryEnabled, logger)
+	var summaryProvider sessions.Provider = sessions.NewOllamaProvider(cfg.OllamaBaseURL)
+	if cfg.SummaryProvider == "openai" {
+		summaryProvider = sessions.NewOpenAIProvider(cfg.SummaryBaseURL, cfg.SummaryAPIKey)
+	}
+	summarizer := sessions.NewSummarizer(summaryProvider, cfg.SummaryModel, cfg.SummaryEnabled, logger)
+	for feature, model := range cfg.SummaryModels {
+		summarizer.SetFeatureModel(feature, model)
+	}
+	for feature, tokens := range cfg.SummaryTokenCaps {
+		summarizer.SetDailyTokenCap(feature, tokens)
+	}
 
 	// Skill sync
 	var skillSync *skills.SyncService
 	if len(cfg.SkillDirs) > 0 {
-		skillSync = skills.NewSyncService(svc, memoryStore, qdrantClient, cfg.SkillDirs, logger)
+		skillSync = skills.NewSyncService(svc, memoryStore, collMgr, cfg.SkillDirs, logger)
 	}
 
 	// Feature threads
 	threadStore := store.NewThreadStore(db)
-	threadSvc := threads.NewService(threadStore, memoryStore, workspaceStore, logger)
+	threadSvc := threads.NewService(threadStore, memoryStore, workspaceStore, summarizer, cfg.ThreadMaxEntryTokens, cfg.ThreadAutoSummarize, logger)
+	svc.SetThreadStore(threadStore)
+	calibrationStore := store.NewCalibrationStore(db)
+	svc.SetCalibration(calibrationStore)
+	threadSvc.SetCalibration(calibrationStore)
+	tokenizer, _ := tokens.New(cfg.Tokenizer) // validated by config.Load
+	threadSvc.SetTokenizer(tokenizer)
+	svc.SetArchive(store.NewArchiveStore(db))
+	svc.SetLinkStore(linkStore)
+
+	// Fixtures for demo and test instances
+	if *seedDir != "" {
+		if _, err := seed.NewLoader(svc, threadSvc, workspaceStore, logger).Load(context.Background(), "default", *seedDir); err != nil {
+			logger.Error("failed to load seed fixtures", "dir", *seedDir, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Background work (model warm-up, scheduled compaction) stops with the
+	// server
+	rootCtx, stopRoot := context.WithCancel(context.Background())
+	defer stopRoot()
+
+	// Model warm-up: load the embedding and rerank models before the first
+	// search needs them
+	var warmer *embedding.Warmer
+	// Hosted backends have no model to load, and keepalives would be billed
+	localEmbed := cfg.EmbeddingBackend == embedding.BackendOllama || cfg.EmbeddingBackend == embedding.BackendTEI
+	localRerank := reranker != nil && cfg.RerankBackend == embedding.RerankBackendOllama
+	if cfg.WarmupEnabled && (localEmbed || localRerank) {
+		var warmEmbedder embedding.Embedder
+		if localEmbed {
+			warmEmbedder = textEmbedder
+		}
+		warmer = embedding.NewWarmer(warmEmbedder, time.Duration(cfg.KeepaliveInterval)*time.Second, logger)
+		if localRerank {
+			warmer.SetReranker(reranker)
+		}
+		go warmer.Run(rootCtx)
+	}
+
+	// Shutdown coordination: drain in-flight writes, then flush Qdrant and the WAL
+	coord := shutdown.NewCoordinator(logger)
+	coord.AddFlusher("qdrant", qdrantClient.Flush)
+	coord.AddFlusher("sqlite", db.Checkpoint)
+
+	// Compaction: scheduled runs followed by a vacuum, history, report
+	// delivery and the database size alert
+	notifier := memory.NewReportNotifier(cfg.ReportWebhookURL, memory.ReportMail{
+		Addr:     cfg.ReportSMTPAddr,
+		Username: cfg.ReportSMTPUser,
+		Password: cfg.ReportSMTPPassword,
+		From:     cfg.ReportEmailFrom,
+		To:       cfg.ReportEmailTo,
+	}, logger)
+	compactSchedule, _ := cfg.CompactionSchedule() // checked by config validation
+	compactor := memory.NewCompactor(svc, db, store.NewCompactionStore(db), notifier,
+		compactSchedule, int64(cfg.DBSizeAlertMB)<<20, logger)
+
+	// Image attachments: blobs on disk, pruned once their memory is gone
+	var imageEmbedder *embedding.ImageEmbedder
+	if cfg.ImageEmbedderURL != "" {
+		imageEmbedder = embedding.NewImageEmbedder(cfg.ImageEmbedderURL, cfg.ImageEmbeddingModel)
+	}
+	attachmentSvc := attachments.NewService(cfg.AttachmentsDir, int64(cfg.AttachmentMaxBytes),
+		store.NewAttachmentStore(db), memoryStore, imageEmbedder, logger)
+	compactor.AddPruner("attachments", attachmentSvc.Prune)
+
+	// Qdrant points left behind by failed cleanup calls, and long-term
+	// vectors lost from Qdrant, are repaired after each compaction
+	compactor.AddPruner("qdrant", svc.PruneVectors)
+
+	go compactor.Schedule(rootCtx)
 
 	// Router
-	router := api.NewRouter(db, svc, ollamaClient, qdrantClient, skillSync, sessStore, obsStore, summarizer, threadSvc, cfg.APIKey, logger)
+	timeouts := api.Timeouts{
+		Default: cfg.EndpointTimeouts["default"],
+		Search:  cfg.EndpointTimeouts["search"],
+		Store:   cfg.EndpointTimeouts["store"],
+		Bulk:    cfg.EndpointTimeouts["bulk"],
+	}
+	auth := api.Auth{APIKey: cfg.APIKey, Keys: make(map[string]models.Caller, len(cfg.APIKeys))}
+	for identity, token := range cfg.APIKeys {
+		auth.Keys[token] = models.ParseCaller(identity)
+	}
+	router := api.NewRouter(db, svc, textEmbedder, warmer, qdrantClient, skillSync, sessStore, obsStore, summarizer, threadSvc, coord, compactor, attachmentSvc, timeouts, auth, logger)
 
 	// Server
 	addr := fmt.Sprintf(":%d", cfg.Port)
@@ -115,14 +239,43 @@ func main() {
 		WriteTimeout: 60 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
+	// Event streams stay open until the client leaves, so end them on shutdown
+	srv.RegisterOnShutdown(svc.CloseEvents)
+
+	// TLS: configured cert/key or a self-signed cert next to the database
+	if cfg.TLSEnabled() {
+		certFile, keyFile := cfg.TLSCertFile, cfg.TLSKeyFile
+		if cfg.TLSSelfSigned {
+			certFile, keyFile, err = tlsconfig.EnsureSelfSigned(filepath.Join(filepath.Dir(cfg.DBPath), "tls"))
+			if err != nil {
+				logger.Error("failed to generate self-signed certificate", "error", err)
+				os.Exit(1)
+			}
+			logger.Info("using self-signed certificate", "cert", certFile)
+		}
+		srv.TLSConfig, err = tlsconfig.Server(certFile, keyFile, cfg.TLSClientCAFile)
+		if err != nil {
+			logger.Error("failed to configure TLS", "error", err)
+			os.Exit(1)
+		}
+	}
 
 	// Graceful shutdown
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
-		logger.Info("memory server starting", "addr", addr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logger.Info("memory server starting", "addr", addr,
+			"tls", srv.TLSConfig != nil,
+			"mtls", cfg.TLSClientCAFile != "",
+		)
+		var err error
+		if srv.TLSConfig != nil {
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logger.Error("server error", "error", err)
 			os.Exit(1)
 		}
@@ -131,7 +284,7 @@ func main() {
 	// Auto-sync skills on startup
 	if cfg.SkillAutoSync && skillSync != nil {
 		go func() {
-			result, err := skillSync.Sync()
+			result, err := skillSync.Sync(context.Background())
 			if err != nil {
 				logger.Error("skill auto-sync failed", "error", err)
 				return
@@ -139,6 +292,9 @@ func main() {
 			logger.Info("skill auto-sync complete",
 				"found", result.Found,
 				"stored", result.Stored,
+				"unchanged", result.Unchanged,
+				"reembedded", result.Reembedded,
+				"removed", result.Removed,
 				"errors", result.Errors,
 			)
 		}()
@@ -146,6 +302,11 @@ func main() {
 
 	<-done
 	logger.Info("shutting down...")
+	stopRoot()
+
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownDrainSeconds)*time.Second)
+	defer cancelDrain()
+	manifest := coord.Drain(drainCtx)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -154,5 +315,49 @@ func main() {
 		logger.Error("shutdown error", "error", err)
 	}
 
-	logger.Info("server stopped")
+	logger.Info("server stopped",
+		"clean", manifest.Clean,
+		"in_flight", manifest.InFlightAtStart,
+		"drained", manifest.Drained,
+		"abandoned", manifest.Abandoned,
+		"rejected", manifest.Rejected,
+		"flushed", manifest.Flushed,
+		"flush_errors", manifest.FlushErrors,
+		"duration_ms", manifest.DurationMs,
+	)
+}
+
+// newEmbedder builds the configured embedding backend. Models that can
+// shorten their vectors are only asked to when EMBEDDING_DIM differs from
+// their native size, so OpenAI-compatible servers that lack the parameter
+// keep working.
+func newEmbedder(cfg *config.Config) embedding.Embedder {
+	dimensions := 0
+	if native := config.ModelDimension(cfg.EmbeddingModel); native != 0 && native != cfg.EmbeddingDim {
+		dimensions = cfg.EmbeddingDim
+	}
+	switch cfg.EmbeddingBackend {
+	case embedding.BackendOpenAI:
+		return embedding.NewOpenAIClient(cfg.EmbeddingBaseURL, cfg.EmbeddingAPIKey, cfg.EmbeddingModel, dimensions)
+	case embedding.BackendVoyage:
+		return embedding.NewVoyageClient(cfg.EmbeddingBaseURL, cfg.EmbeddingAPIKey, cfg.EmbeddingModel, dimensions)
+	case embedding.BackendTEI:
+		return embedding.NewTEIClient(cfg.EmbeddingBaseURL)
+	default:
+		return embedding.NewOllamaClient(cfg.OllamaBaseURL, cfg.EmbeddingModel)
+	}
+}
+
+// newReranker builds the configured re-ranking backend. The Ollama backend
+// uses RERANK_BASE_URL when set, so reranking can run on a bigger box than
+// embedding.
+func newReranker(cfg *config.Config) search.Reranker {
+	if cfg.RerankBackend == embedding.RerankBackendRemote {
+		return embedding.NewRemoteReranker(cfg.RerankBaseURL, cfg.RerankAPIKey, cfg.RerankModel)
+	}
+	baseURL := cfg.RerankBaseURL
+	if baseURL == "" {
+		baseURL = cfg.OllamaBaseURL
+	}
+	return embedding.NewOllamaReranker(baseURL, cfg.RerankModel)
 }
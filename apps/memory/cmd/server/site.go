@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/site"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+func runSite(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("site", flag.ContinueOnError)
+	workspace := fs.String("workspace", "", "Workspace ID or path to publish")
+	namespace := fs.String("namespace", "default", "Namespace a workspace path belongs to")
+	out := fs.String("o", "site", "Directory to write the site to")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: memory-server site --workspace ID|PATH [-o DIR]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *workspace == "" {
+		fs.Usage()
+		return fmt.Errorf("--workspace is required")
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	wsID := *workspace
+	if filepath.IsAbs(wsID) || store.IsRemoteWorkspace(wsID) {
+		if wsID, err = store.NewWorkspaceStore(db).ResolveWorkspaceID(ctx, *namespace, wsID); err != nil {
+			return err
+		}
+	}
+
+	report, err := site.NewGenerator(db).Generate(ctx, wsID, *out)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
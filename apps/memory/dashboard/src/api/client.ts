@@ -13,7 +13,7 @@ class ApiError extends Error {
 async function handleResponse<T>(res: Response): Promise<T> {
   if (!res.ok) {
     const body = await res.json().catch(() => ({ error: res.statusText }));
-    throw new ApiError(res.status, body.error ?? res.statusText);
+    throw new ApiError(res.status, body.detail ?? body.error ?? res.statusText);
   }
   if (res.status === 204) return undefined as T;
   return res.json();
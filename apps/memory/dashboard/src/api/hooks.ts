@@ -15,6 +15,7 @@ import type {
   SearchResponse,
   UpdateRequest,
   CompactResponse,
+  RetrievabilityCurve,
 } from "./types";
 
 function buildQuery(params: ListParams): string {
@@ -55,6 +56,15 @@ export function useMemory(id: string) {
   });
 }
 
+export function useRetrievability(id: string, days = 90) {
+  return useQuery({
+    queryKey: ["retrievability", id, days],
+    queryFn: () =>
+      api.get<RetrievabilityCurve>(`/memories/${id}/retrievability?days=${days}`),
+    enabled: !!id,
+  });
+}
+
 export function useWorkspaces() {
   return useQuery({
     queryKey: ["workspaces"],
@@ -82,6 +82,8 @@ export interface SearchResult {
   tags: string[] | null;
   source: string;
   createdAt: number;
+  /** Best keyword-matching window, matched terms wrapped in <mark></mark> */
+  snippet?: string;
 }
 
 export interface SearchMeta {
@@ -132,3 +134,21 @@ export interface CompactResponse {
   expired: number;
   promoted: number;
 }
+
+export interface RetrievabilityPoint {
+  day: number;
+  retrievability: number;
+  reinforced?: number;
+}
+
+export interface RetrievabilityCurve {
+  memoryId: string;
+  tier: Tier;
+  stability: number;
+  accessCount: number;
+  accessIntervalDays?: number;
+  current: number;
+  forgetThreshold: number;
+  forgottenInDays: number | null;
+  points: RetrievabilityPoint[];
+}
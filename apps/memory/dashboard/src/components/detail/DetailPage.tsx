@@ -5,6 +5,7 @@ import { Button } from "@/components/ui/button";
 import { Badge } from "@/components/ui/badge";
 import { MetadataPanel } from "./MetadataPanel";
 import { EditForm } from "./EditForm";
+import { RetrievabilityChart } from "./RetrievabilityChart";
 import { MEMORY_TYPE_COLORS, MEMORY_TYPE_LABELS } from "@/lib/constants";
 import { cn } from "@/lib/utils";
 import { ArrowLeft, Trash2 } from "lucide-react";
@@ -98,6 +99,8 @@ export function DetailPage() {
         <EditForm memory={memory} />
         <MetadataPanel memory={memory} />
       </div>
+
+      <RetrievabilityChart memoryId={memory.id} />
     </div>
   );
 }
@@ -0,0 +1,79 @@
+import {
+  LineChart,
+  Line,
+  XAxis,
+  YAxis,
+  Tooltip,
+  ReferenceLine,
+  ResponsiveContainer,
+} from "recharts";
+import { useRetrievability } from "@/api/hooks";
+import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
+
+interface RetrievabilityChartProps {
+  memoryId: string;
+}
+
+export function RetrievabilityChart({ memoryId }: RetrievabilityChartProps) {
+  const { data: curve } = useRetrievability(memoryId);
+
+  if (!curve) {
+    return null;
+  }
+
+  const reinforced = curve.points.some((p) => p.reinforced);
+
+  return (
+    <Card>
+      <CardHeader>
+        <CardTitle className="text-sm">Projected retrievability</CardTitle>
+        <p className="text-xs text-muted-foreground">
+          {curve.forgottenInDays === null
+            ? `Stays above ${curve.forgetThreshold} for the next ${curve.points.length - 1} days without access.`
+            : curve.forgottenInDays === 0
+              ? "Effectively forgotten now unless reinforced."
+              : `Effectively forgotten in ${curve.forgottenInDays} days without access.`}
+        </p>
+      </CardHeader>
+      <CardContent>
+        <ResponsiveContainer width="100%" height={220}>
+          <LineChart data={curve.points} margin={{ left: 0, right: 16 }}>
+            <XAxis
+              dataKey="day"
+              tick={{ fontSize: 12, fill: "#a3a3a3" }}
+              tickFormatter={(d: number) => `${d}d`}
+            />
+            <YAxis domain={[0, 1]} tick={{ fontSize: 12, fill: "#a3a3a3" }} />
+            <Tooltip
+              contentStyle={{
+                background: "#1c1c1c",
+                border: "1px solid rgba(255,255,255,0.1)",
+                borderRadius: 8,
+                fontSize: 12,
+              }}
+              formatter={(v: number) => v.toFixed(2)}
+              labelFormatter={(d: number) => `Day ${d}`}
+            />
+            <ReferenceLine y={curve.forgetThreshold} stroke="#ef4444" strokeDasharray="4 4" />
+            <Line
+              type="monotone"
+              dataKey="retrievability"
+              name="No access"
+              stroke="#3b82f6"
+              dot={false}
+            />
+            {reinforced && (
+              <Line
+                type="monotone"
+                dataKey="reinforced"
+                name="At current access rate"
+                stroke="#22c55e"
+                dot={false}
+              />
+            )}
+          </LineChart>
+        </ResponsiveContainer>
+      </CardContent>
+    </Card>
+  );
+}
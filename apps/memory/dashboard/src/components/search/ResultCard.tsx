@@ -35,6 +35,11 @@ export function ResultCard({ result }: ResultCardProps) {
           </span>
         </div>
         <p className="text-sm leading-relaxed">{result.content}</p>
+        {result.snippet && (
+          <p className="text-xs leading-relaxed text-muted-foreground">
+            <Snippet text={result.snippet} />
+          </p>
+        )}
         <div className="flex items-center gap-3">
           <div className="flex items-center gap-2 text-xs text-muted-foreground">
             <span>Score:</span>
@@ -63,3 +68,21 @@ export function ResultCard({ result }: ResultCardProps) {
     </Card>
   );
 }
+
+/** Renders <mark> markers from the server as highlights without using innerHTML. */
+function Snippet({ text }: { text: string }) {
+  const parts = text.split(/<mark>|<\/mark>/);
+  return (
+    <>
+      {parts.map((part, i) =>
+        i % 2 === 1 ? (
+          <mark key={i} className="rounded-sm bg-primary/20 px-0.5 text-foreground">
+            {part}
+          </mark>
+        ) : (
+          <span key={i}>{part}</span>
+        ),
+      )}
+    </>
+  );
+}
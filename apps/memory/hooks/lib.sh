@@ -12,6 +12,10 @@ fi
 MEMORY_SERVER="${CLIVE_MEMORY_URL:-http://localhost:8741}"
 MEMORY_API_KEY="${CLIVE_MEMORY_API_KEY:-}"
 CLIVE_NAMESPACE="${CLIVE_NAMESPACE:-}"
+# TLS: trust a self-signed server cert and/or present a client cert (mTLS)
+MEMORY_CA_FILE="${CLIVE_MEMORY_CA_FILE:-}"
+MEMORY_CLIENT_CERT="${CLIVE_MEMORY_CLIENT_CERT:-}"
+MEMORY_CLIENT_KEY="${CLIVE_MEMORY_CLIENT_KEY:-}"
 HOOK_TIMEOUT=5  # seconds
 
 # Read stdin JSON once and cache it. Call early in each hook.
@@ -47,20 +51,34 @@ get_session_id() {
 }
 
 # Call the memory server API. Returns the response body.
-# Usage: api_call METHOD PATH [JSON_BODY]
+# Usage: api_call METHOD PATH [JSON_BODY] [IDEMPOTENCY_KEY]
+# With an idempotency key the request is retried on timeouts; the server
+# replays the original response instead of storing a duplicate.
 api_call() {
   local method="$1"
   local path="$2"
   local body="${3:-}"
+  local idempotency_key="${4:-}"
 
   local url="${MEMORY_SERVER}${path}"
   local args=(-s -S --max-time "$HOOK_TIMEOUT" -X "$method")
 
+  if [ -n "$idempotency_key" ]; then
+    args+=(-H "Idempotency-Key: ${idempotency_key}" --retry 2 --retry-max-time "$((HOOK_TIMEOUT * 3))")
+  fi
+
   # Add auth header if API key is set
   if [ -n "$MEMORY_API_KEY" ]; then
     args+=(-H "Authorization: Bearer ${MEMORY_API_KEY}")
   fi
 
+  if [ -n "$MEMORY_CA_FILE" ]; then
+    args+=(--cacert "$MEMORY_CA_FILE")
+  fi
+  if [ -n "$MEMORY_CLIENT_CERT" ]; then
+    args+=(--cert "$MEMORY_CLIENT_CERT" --key "${MEMORY_CLIENT_KEY:-$MEMORY_CLIENT_CERT}")
+  fi
+
   # Add namespace header if set
   if [ -n "$CLIVE_NAMESPACE" ]; then
     args+=(-H "X-Clive-Namespace: ${CLIVE_NAMESPACE}")
@@ -202,7 +220,15 @@ store_memory() {
       }')
   fi
 
-  api_call POST /memories "$body" 2>/dev/null
+  api_call POST /memories "$body" "$(idempotency_key "$session_id" "$memory_type" "$content")" 2>/dev/null
+}
+
+# Derive a stable idempotency key from the given parts, so re-running a hook
+# for the same logical write reuses the key.
+idempotency_key() {
+  local hasher="shasum -a 256"
+  command -v sha256sum >/dev/null 2>&1 && hasher="sha256sum"
+  printf '%s\0' "$@" | $hasher 2>/dev/null | cut -c1-32
 }
 
 # Detect encoding context from the workspace.
@@ -280,6 +306,26 @@ get_branch() {
   fi
 }
 
+# Merge this checkout's path-based workspace into its git remote identity so
+# memories follow the repository across clones and machines. No-op without an
+# origin remote, or when CLIVE_WORKSPACE_IDENTITY=path.
+sync_workspace_identity() {
+  local workspace="$1"
+  [ "${CLIVE_WORKSPACE_IDENTITY:-remote}" = "path" ] && return 0
+  command -v git >/dev/null 2>&1 || return 0
+
+  local remote subdir body
+  remote=$(git -C "$workspace" remote get-url origin 2>/dev/null) || return 0
+  [ -n "$remote" ] || return 0
+  # Never send credentials embedded in HTTPS remotes
+  remote=$(echo "$remote" | sed -E 's#//[^@/]+@#//#')
+  subdir=$(git -C "$workspace" rev-parse --show-prefix 2>/dev/null) || subdir=""
+
+  body=$(jq -n --arg remote "$remote" --arg subdir "${subdir%/}" --arg path "$workspace" \
+    '{remote: $remote, subdir: $subdir, paths: [$path]}')
+  api_call POST /workspaces/merge "$body" >/dev/null 2>&1 || true
+}
+
 # Silently fail - hooks should never block Claude Code.
 safe_exit() {
   echo '{}'
@@ -65,7 +65,7 @@ if [ -n "$SUMMARY" ] && [ ${#SUMMARY} -gt 50 ]; then
       }')
   fi
 
-  api_call POST /memories "$STORE_BODY" >/dev/null 2>&1 || true
+  api_call POST /memories "$STORE_BODY" "$(idempotency_key "$SESSION_ID" pre_compact "$SUMMARY")" >/dev/null 2>&1 || true
 fi
 
 # Auto-append session context to active feature thread (prefer branch-matching)
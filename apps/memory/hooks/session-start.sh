@@ -16,6 +16,7 @@ if ! is_server_healthy; then
 fi
 
 WORKSPACE=$(get_workspace)
+sync_workspace_identity "$WORKSPACE"
 
 # --- Active Feature Threads (highest priority, branch-aware) ---
 THREAD_CONTEXT=""
@@ -77,7 +77,7 @@ case "$COMMAND" in
     if [ -n "$THREAD_ID" ]; then
       echo "Created feature thread: ${NAME} (${THREAD_ID})"
     else
-      ERROR=$(echo "$RESPONSE" | jq -r '.error // empty' 2>/dev/null) || true
+      ERROR=$(echo "$RESPONSE" | jq -r '.detail // .error // empty' 2>/dev/null) || true
       echo "Failed to create thread: ${ERROR:-unknown error}" >&2
     fi
     ;;
@@ -133,7 +133,7 @@ case "$COMMAND" in
     if [ -n "$SEQ" ]; then
       echo "Appended to thread '${NAME}' [${SECTION}] (seq: ${SEQ})"
     else
-      ERROR=$(echo "$RESPONSE" | jq -r '.error // empty' 2>/dev/null) || true
+      ERROR=$(echo "$RESPONSE" | jq -r '.detail // .error // empty' 2>/dev/null) || true
       echo "Failed to append: ${ERROR:-unknown error}" >&2
     fi
     ;;
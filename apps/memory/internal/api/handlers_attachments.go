@@ -0,0 +1,165 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/attachments"
+	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// recentAttachmentsScan is how many recent attachments ListRecent reads
+// before dropping those on memories the caller cannot see.
+const recentAttachmentsScan = 200
+
+// AttachmentHandler handles image attachment endpoints. Attachments are
+// visible to the callers, and keys, that can see their memory.
+type AttachmentHandler struct {
+	svc      *attachments.Service
+	memories *memory.Service
+}
+
+// NewAttachmentHandler creates a new AttachmentHandler.
+func NewAttachmentHandler(svc *attachments.Service, memories *memory.Service) *AttachmentHandler {
+	return &AttachmentHandler{svc: svc, memories: memories}
+}
+
+// attachmentListResponse is the response for attachment list endpoints.
+type attachmentListResponse struct {
+	Attachments []*models.Attachment `json:"attachments"`
+}
+
+// Upload handles POST /memories/{id}/attachments. The request body is the
+// raw image; the optional filename query parameter names it.
+func (h *AttachmentHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, ok := visibleMemory(h.memories, w, r, id); !ok {
+		return
+	}
+
+	att, err := h.svc.Add(r.Context(), id, r.URL.Query().Get("filename"), r.Body)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, att)
+}
+
+// ListForMemory handles GET /memories/{id}/attachments
+func (h *AttachmentHandler) ListForMemory(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, ok := visibleMemory(h.memories, w, r, id); !ok {
+		return
+	}
+
+	list, err := h.svc.List(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if list == nil {
+		list = []*models.Attachment{}
+	}
+
+	writeJSON(w, http.StatusOK, attachmentListResponse{Attachments: list})
+}
+
+// ListRecent handles GET /attachments, listing the most recent attachments
+// on memories the caller can see.
+func (h *AttachmentHandler) ListRecent(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > recentAttachmentsScan {
+		limit = 50
+	}
+
+	recent, err := h.svc.Recent(r.Context(), recentAttachmentsScan)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	ids := make([]string, 0, len(recent))
+	for _, att := range recent {
+		ids = append(ids, att.MemoryID)
+	}
+	batch, err := h.memories.BatchGet(r.Context(), &models.BatchGetRequest{IDs: ids, Caller: GetCaller(r)})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	visible := make(map[string]bool, len(batch.Memories))
+	for _, m := range inKeyScope(r, batch.Memories) {
+		visible[m.ID] = true
+	}
+
+	list := []*models.Attachment{}
+	for _, att := range recent {
+		if visible[att.MemoryID] && len(list) < limit {
+			list = append(list, att)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, attachmentListResponse{Attachments: list})
+}
+
+// attachment loads an attachment whose memory the caller can see, writing
+// the response when it is missing, hidden, or outside the key's workspace.
+func (h *AttachmentHandler) attachment(w http.ResponseWriter, r *http.Request) (*models.Attachment, bool) {
+	att, err := h.svc.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return nil, false
+	}
+	mem, err := h.memories.GetFor(r.Context(), GetCaller(r), att.MemoryID)
+	if err != nil {
+		writeServiceError(w, err)
+		return nil, false
+	}
+	if mem == nil {
+		// Reported like a missing attachment, so hidden IDs don't leak
+		writeProblem(w, http.StatusNotFound, "attachment_not_found", "attachment not found")
+		return nil, false
+	}
+	if !keyAllowsWorkspace(r, mem.WorkspaceID) {
+		writeWorkspaceDenied(w, GetAPIKey(r))
+		return nil, false
+	}
+	return att, true
+}
+
+// Get handles GET /attachments/{id}, returning the raw image bytes.
+func (h *AttachmentHandler) Get(w http.ResponseWriter, r *http.Request) {
+	att, ok := h.attachment(w, r)
+	if !ok {
+		return
+	}
+	att, data, err := h.svc.Read(r.Context(), att.ID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", att.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(att.Size, 10))
+	w.Header().Set("ETag", `"`+att.SHA256+`"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// Delete handles DELETE /attachments/{id}
+func (h *AttachmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	att, ok := h.attachment(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(r.Context(), att.ID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,18 +1,23 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
 	"github.com/iammorganparry/clive/apps/memory/internal/memory"
 	"github.com/iammorganparry/clive/apps/memory/internal/models"
 )
 
 type BulkHandler struct {
-	svc *memory.Service
+	svc       *memory.Service
+	compactor *memory.Compactor
 }
 
-func NewBulkHandler(svc *memory.Service) *BulkHandler {
-	return &BulkHandler{svc: svc}
+func NewBulkHandler(svc *memory.Service, compactor *memory.Compactor) *BulkHandler {
+	return &BulkHandler{svc: svc, compactor: compactor}
 }
 
 // BulkStore handles POST /memories/bulk
@@ -23,28 +28,211 @@ func (h *BulkHandler) BulkStore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	req.Namespace = GetNamespace(r)
+	req.Caller = GetCaller(r)
+	if !scopeBodyWorkspace(w, r, &req.Workspace) {
+		return
+	}
 
 	if len(req.Memories) == 0 {
 		writeError(w, http.StatusBadRequest, "memories array is required")
 		return
 	}
+	for _, m := range req.Memories {
+		if m.Global && workspaceScoped(r) {
+			writeScopeDenied(w, "workspace-scoped keys cannot store global memories")
+			return
+		}
+	}
 
-	resp, err := h.svc.BulkStore(&req)
+	resp, err := h.svc.BulkStore(r.Context(), &req)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, resp)
 }
 
-// Compact handles POST /memories/compact
+// Retag handles POST /memories/retag. Workspace-scoped keys are held to
+// their workspace's memories.
+func (h *BulkHandler) Retag(w http.ResponseWriter, r *http.Request) {
+	var req models.RetagRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	req.Filter.Caller = GetCaller(r)
+	if workspaceScoped(r) {
+		key := GetAPIKey(r)
+		if req.Filter.WorkspaceID == "" {
+			req.Filter.WorkspaceID = key.WorkspaceID
+		} else if req.Filter.WorkspaceID != key.WorkspaceID {
+			writeWorkspaceDenied(w, key)
+			return
+		}
+	}
+
+	resp, err := h.svc.Retag(r.Context(), &req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Compact handles POST /memories/compact. With a compactor configured the
+// run is recorded in the compaction history and the full report returned.
 func (h *BulkHandler) Compact(w http.ResponseWriter, r *http.Request) {
-	resp, err := h.svc.Compact()
+	if h.compactor != nil {
+		report, err := h.compactor.Run(r.Context(), memory.CompactTriggerManual)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, report)
+		return
+	}
+
+	resp, err := h.svc.Compact(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, resp)
 }
+
+// RecalculateImpact handles POST /memories/impact/recalculate. Scores are
+// rebuilt ?batch_size= memories at a time. A client that accepts
+// application/x-ndjson receives a progress line after each batch, ending
+// with the final result or a problem line.
+func (h *BulkHandler) RecalculateImpact(w http.ResponseWriter, r *http.Request) {
+	batchSize := 0
+	if v := r.URL.Query().Get("batch_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeProblem(w, http.StatusBadRequest, "invalid_batch_size", "batch_size must be a positive integer")
+			return
+		}
+		batchSize = n
+	}
+
+	if !strings.Contains(r.Header.Get("Accept"), "application/x-ndjson") {
+		resp, err := h.svc.RecalculateImpact(r.Context(), batchSize, nil)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	rc := http.NewResponseController(w)
+	emit := func(v any) {
+		enc.Encode(v)
+		rc.Flush()
+	}
+
+	resp, err := h.svc.RecalculateImpact(r.Context(), batchSize, func(p models.RecalculateImpactProgress) {
+		emit(p)
+	})
+	if err != nil {
+		e := apperr.From(err)
+		status := statusForKind(e.Kind)
+		emit(Problem{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: e.Error(), Code: e.Code})
+		return
+	}
+	emit(resp)
+}
+
+// CompactHistory handles GET /compact/history
+func (h *BulkHandler) CompactHistory(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	reports, err := h.compactor.History(r.Context(), limit)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if reports == nil {
+		reports = []*models.CompactionReport{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"runs": reports,
+	})
+}
+
+// DBStats handles GET /stats/db
+func (h *BulkHandler) DBStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.compactor.DBStats(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// VectorStats handles GET /stats/vectors: the current drift between SQLite
+// and Qdrant, measured without repairing anything.
+func (h *BulkHandler) VectorStats(w http.ResponseWriter, r *http.Request) {
+	report, err := h.svc.ReconcileVectors(r.Context(), true)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// ReconcileVectors handles POST /vectors/reconcile. It deletes orphan
+// Qdrant points and re-upserts missing long-term vectors; with
+// ?dry_run=true it only reports the drift.
+func (h *BulkHandler) ReconcileVectors(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			writeProblem(w, http.StatusBadRequest, "invalid_dry_run", "dry_run must be true or false")
+			return
+		}
+	}
+
+	report, err := h.svc.ReconcileVectors(r.Context(), dryRun)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// RebalanceVectors handles POST /vectors/rebalance. It moves Qdrant points
+// onto the collections the sharding strategy routes them to; with
+// ?dry_run=true it only reports the moves.
+func (h *BulkHandler) RebalanceVectors(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			writeProblem(w, http.StatusBadRequest, "invalid_dry_run", "dry_run must be true or false")
+			return
+		}
+	}
+
+	report, err := h.svc.RebalanceVectors(r.Context(), dryRun)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+const (
+	// eventBuffer is how many events a slow stream may fall behind by
+	// before it starts missing them.
+	eventBuffer = 256
+	// eventHeartbeat is how often an idle stream sends a comment, so
+	// proxies and clients can tell it is still open.
+	eventHeartbeat = 15 * time.Second
+)
+
+type EventHandler struct {
+	svc *memory.Service
+}
+
+func NewEventHandler(svc *memory.Service) *EventHandler {
+	return &EventHandler{svc: svc}
+}
+
+// Stream handles GET /events, a text/event-stream of memory lifecycle
+// events. ?types= limits it to a comma-separated list of event types and
+// ?workspace_id= to one workspace. Events are scoped to the namespace when
+// the request names one, to the workspace of a workspace-scoped key, and to
+// memories the caller may read.
+func (h *EventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	types := map[models.MemoryEventType]bool{}
+	if v := r.URL.Query().Get("types"); v != "" {
+		for _, t := range strings.Split(v, ",") {
+			t := models.MemoryEventType(strings.TrimSpace(t))
+			if !t.IsValid() {
+				writeProblem(w, http.StatusBadRequest, "invalid_event_type",
+					fmt.Sprintf("unknown event type %q: want stored, deduplicated, promoted, demoted, expired or superseded", t))
+				return
+			}
+			types[t] = true
+		}
+	}
+	caller := GetCaller(r)
+	workspaceID := r.URL.Query().Get("workspace_id")
+	namespace := ""
+	if key := GetAPIKey(r); r.Header.Get(namespaceHeader) != "" || key != nil && key.Namespace != "" {
+		namespace = GetNamespace(r)
+	}
+
+	events, unsubscribe := h.svc.Subscribe(eventBuffer)
+	defer unsubscribe()
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	rc.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if len(types) > 0 && !types[e.Type] ||
+				workspaceID != "" && e.WorkspaceID != workspaceID ||
+				namespace != "" && e.Namespace != namespace ||
+				!keyAllowsWorkspace(r, e.WorkspaceID) || !e.VisibleTo(caller) {
+				continue
+			}
+			data, _ := json.Marshal(e)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -10,13 +10,14 @@ import (
 )
 
 type HealthHandler struct {
-	db     *store.DB
-	ollama *embedding.OllamaClient
-	qdrant *vectorstore.QdrantClient
+	db       *store.DB
+	embedder embedding.Embedder
+	qdrant   *vectorstore.QdrantClient
+	warmer   *embedding.Warmer
 }
 
-func NewHealthHandler(db *store.DB, ollama *embedding.OllamaClient, qdrant *vectorstore.QdrantClient) *HealthHandler {
-	return &HealthHandler{db: db, ollama: ollama, qdrant: qdrant}
+func NewHealthHandler(db *store.DB, embedder embedding.Embedder, qdrant *vectorstore.QdrantClient, warmer *embedding.Warmer) *HealthHandler {
+	return &HealthHandler{db: db, embedder: embedder, qdrant: qdrant, warmer: warmer}
 }
 
 func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
@@ -24,13 +25,14 @@ func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
 		Status: "ok",
 	}
 
-	// Check Ollama
-	if err := h.ollama.HealthCheck(); err != nil {
-		resp.Ollama = models.ServiceCheck{Status: "error", Message: err.Error()}
+	// Check the embedding backend
+	if err := h.embedder.HealthCheck(); err != nil {
+		resp.Embedder = models.ServiceCheck{Status: "error", Message: err.Error()}
 		resp.Status = "degraded"
 	} else {
-		resp.Ollama = models.ServiceCheck{Status: "ok"}
+		resp.Embedder = models.ServiceCheck{Status: "ok"}
 	}
+	resp.Ollama = resp.Embedder
 
 	// Check Qdrant
 	if err := h.qdrant.HealthCheck(); err != nil {
@@ -41,7 +43,7 @@ func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check DB
-	count, err := h.db.MemoryCount()
+	count, err := h.db.MemoryCount(r.Context())
 	if err != nil {
 		resp.DB = models.ServiceCheck{Status: "error", Message: err.Error()}
 		resp.Status = "degraded"
@@ -50,9 +52,39 @@ func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
 		resp.MemoryCount = count
 	}
 
+	// Warm-up state is informational here; /ready gates on it.
+	if h.warmer != nil {
+		ws := h.warmer.Status()
+		resp.Warmup = models.ServiceCheck{Status: string(ws.State), Message: ws.Error}
+	} else {
+		resp.Warmup = models.ServiceCheck{Status: string(embedding.WarmupDisabled)}
+	}
+
 	status := http.StatusOK
 	if resp.Status != "ok" {
 		status = http.StatusServiceUnavailable
 	}
 	writeJSON(w, status, resp)
 }
+
+// Ready handles GET /ready. It returns 503 until the embedding model has
+// been warmed up, so load balancers don't route searches to a cold server.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	if h.warmer == nil {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"ready":  true,
+			"warmup": embedding.WarmupStatus{State: embedding.WarmupDisabled},
+		})
+		return
+	}
+
+	ready := h.warmer.Ready()
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, map[string]any{
+		"ready":  ready,
+		"warmup": h.warmer.Status(),
+	})
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+// KeyHandler manages the scoped API keys. Keys can only be created while
+// MEMORY_API_KEY is set: with auth disabled nothing would enforce them.
+type KeyHandler struct {
+	keys       *store.APIKeyStore
+	workspaces *store.WorkspaceStore
+	enabled    bool
+}
+
+func NewKeyHandler(keys *store.APIKeyStore, workspaces *store.WorkspaceStore, enabled bool) *KeyHandler {
+	return &KeyHandler{keys: keys, workspaces: workspaces, enabled: enabled}
+}
+
+// Create handles POST /keys. The key acts as the caller that created it;
+// its name is only a label.
+func (h *KeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled {
+		writeServiceError(w, apperr.Conflict("api_keys_disabled", "set MEMORY_API_KEY to manage scoped API keys"))
+		return
+	}
+	var req models.CreateAPIKeyRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	key := models.APIKey{
+		Name:      strings.TrimSpace(req.Name),
+		Identity:  GetCaller(r).String(),
+		Namespace: req.Namespace,
+		Access:    req.Access,
+	}
+	if key.Access == "" {
+		key.Access = models.KeyAccessReadWrite
+	}
+	switch {
+	case key.Name == "":
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	case !key.Access.IsValid():
+		writeError(w, http.StatusBadRequest, "access must be read or read_write")
+		return
+	case key.Namespace != "" && !isValidNamespace(key.Namespace):
+		writeError(w, http.StatusBadRequest, "invalid namespace: must be alphanumeric, hyphens, underscores only (max 64 chars)")
+		return
+	case req.Workspace != "" && key.Namespace == "":
+		writeError(w, http.StatusBadRequest, "a workspace scope needs the namespace the workspace is in")
+		return
+	}
+	if req.Workspace != "" {
+		key.Workspace = normalizeWorkspace(req.Workspace)
+		id, err := h.workspaces.ResolveWorkspaceID(r.Context(), key.Namespace, key.Workspace)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		key.WorkspaceID = id
+	}
+
+	token, err := h.keys.Create(r.Context(), &key)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, models.CreateAPIKeyResponse{APIKey: key, Token: token})
+}
+
+// List handles GET /keys
+func (h *KeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.keys.List(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"keys": keys})
+}
+
+// Revoke handles DELETE /keys/{id}
+func (h *KeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	key, err := h.keys.Revoke(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, key)
+}
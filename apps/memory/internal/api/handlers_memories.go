@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -35,6 +36,10 @@ func (h *MemoryHandler) List(w http.ResponseWriter, r *http.Request) {
 			memoryTypes = append(memoryTypes, models.MemoryType(t))
 		}
 	}
+	var tags []string
+	if t := r.URL.Query().Get("tags"); t != "" {
+		tags = strings.Split(t, ",")
+	}
 
 	req := &models.ListRequest{
 		Page:        page,
@@ -45,11 +50,13 @@ func (h *MemoryHandler) List(w http.ResponseWriter, r *http.Request) {
 		MemoryTypes: memoryTypes,
 		Tier:        tier,
 		Source:      source,
+		Tags:        tags,
+		Caller:      GetCaller(r),
 	}
 
-	resp, err := h.svc.List(req)
+	resp, err := h.svc.List(r.Context(), req)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -64,8 +71,16 @@ func (h *MemoryHandler) Store(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	req.Namespace = GetNamespace(r)
+	req.Caller = GetCaller(r)
+	if !scopeBodyWorkspace(w, r, &req.Workspace) {
+		return
+	}
+	if req.Global && workspaceScoped(r) {
+		writeScopeDenied(w, "workspace-scoped keys cannot store global memories")
+		return
+	}
 
-	if req.Content == "" {
+	if req.Content == "" && len(req.Fields) == 0 {
 		writeError(w, http.StatusBadRequest, "content is required")
 		return
 	}
@@ -74,9 +89,9 @@ func (h *MemoryHandler) Store(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := h.svc.Store(&req)
+	resp, err := h.svc.Store(r.Context(), &req)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -87,6 +102,21 @@ func (h *MemoryHandler) Store(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, status, resp)
 }
 
+// Templates handles GET /memories/templates
+func (h *MemoryHandler) Templates(w http.ResponseWriter, r *http.Request) {
+	templates := make([]models.ContentTemplate, 0, len(models.ContentTemplates))
+	for _, t := range models.ContentTemplates {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].MemoryType < templates[j].MemoryType
+	})
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"templates": templates,
+	})
+}
+
 // Search handles POST /memories/search
 func (h *MemoryHandler) Search(w http.ResponseWriter, r *http.Request) {
 	var req models.SearchRequest
@@ -95,15 +125,19 @@ func (h *MemoryHandler) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	req.Namespace = GetNamespace(r)
+	req.Caller = GetCaller(r)
+	if !scopeBodyWorkspace(w, r, &req.Workspace) {
+		return
+	}
 
 	if req.Query == "" {
 		writeError(w, http.StatusBadRequest, "query is required")
 		return
 	}
 
-	resp, err := h.svc.Search(&req)
+	resp, err := h.svc.Search(r.Context(), &req)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -114,22 +148,39 @@ func (h *MemoryHandler) Search(w http.ResponseWriter, r *http.Request) {
 func (h *MemoryHandler) Get(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	mem, err := h.svc.GetByID(id)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-	if mem == nil {
-		writeError(w, http.StatusNotFound, "memory not found")
+	mem, ok := h.visible(w, r, id)
+	if !ok {
 		return
 	}
 
 	writeJSON(w, http.StatusOK, mem)
 }
 
+// visible loads a memory the caller may read, writing a not found problem
+// when it doesn't exist or is hidden from them.
+func (h *MemoryHandler) visible(w http.ResponseWriter, r *http.Request, id string) (*models.Memory, bool) {
+	return visibleMemory(h.svc, w, r, id)
+}
+
+func visibleMemory(svc *memory.Service, w http.ResponseWriter, r *http.Request, id string) (*models.Memory, bool) {
+	mem, err := svc.GetFor(r.Context(), GetCaller(r), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return nil, false
+	}
+	if mem == nil {
+		writeProblem(w, http.StatusNotFound, "memory_not_found", "memory not found")
+		return nil, false
+	}
+	return mem, true
+}
+
 // Update handles PATCH /memories/{id}
 func (h *MemoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
+	if _, ok := h.visible(w, r, id); !ok {
+		return
+	}
 
 	var req models.UpdateRequest
 	if err := decodeJSON(r, &req); err != nil {
@@ -137,9 +188,9 @@ func (h *MemoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	mem, err := h.svc.Update(id, &req)
+	mem, err := h.svc.Update(r.Context(), id, &req)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -149,9 +200,12 @@ func (h *MemoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 // Delete handles DELETE /memories/{id}
 func (h *MemoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
+	if _, ok := h.visible(w, r, id); !ok {
+		return
+	}
 
-	if err := h.svc.Delete(id); err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		writeServiceError(w, err)
 		return
 	}
 
@@ -161,6 +215,9 @@ func (h *MemoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 // RecordImpact handles POST /memories/{id}/impact
 func (h *MemoryHandler) RecordImpact(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
+	if _, ok := h.visible(w, r, id); !ok {
+		return
+	}
 
 	var req models.RecordImpactRequest
 	if err := decodeJSON(r, &req); err != nil {
@@ -177,9 +234,9 @@ func (h *MemoryHandler) RecordImpact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := h.svc.RecordImpact(id, &req)
+	resp, err := h.svc.RecordImpact(r.Context(), id, &req)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -189,10 +246,13 @@ func (h *MemoryHandler) RecordImpact(w http.ResponseWriter, r *http.Request) {
 // ImpactEvents handles GET /memories/{id}/impact
 func (h *MemoryHandler) ImpactEvents(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
+	if _, ok := h.visible(w, r, id); !ok {
+		return
+	}
 
-	events, err := h.svc.GetImpactEvents(id)
+	events, err := h.svc.GetImpactEvents(r.Context(), id)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -205,14 +265,40 @@ func (h *MemoryHandler) ImpactEvents(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// Retrievability handles GET /memories/{id}/retrievability?days=N
+func (h *MemoryHandler) Retrievability(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, ok := h.visible(w, r, id); !ok {
+		return
+	}
+
+	days := 30
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeProblem(w, http.StatusBadRequest, "invalid_days", "days must be an integer")
+			return
+		}
+		days = n
+	}
+
+	curve, err := h.svc.RetrievabilityCurve(r.Context(), id, days)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, curve)
+}
+
 // ImpactLeaders handles GET /memories/impact-leaders
 func (h *MemoryHandler) ImpactLeaders(w http.ResponseWriter, r *http.Request) {
 	workspaceID := r.URL.Query().Get("workspace_id")
 	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
 
-	memories, err := h.svc.GetImpactLeaders(workspaceID, limit)
+	memories, err := h.svc.GetImpactLeaders(r.Context(), GetCaller(r), workspaceID, limit)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -225,6 +311,26 @@ func (h *MemoryHandler) ImpactLeaders(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// Calibration handles GET /memories/calibration
+func (h *MemoryHandler) Calibration(w http.ResponseWriter, r *http.Request) {
+	report, err := h.svc.CalibrationReport(r.Context(), r.URL.Query().Get("workspace_id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// ApplyCalibration handles POST /memories/calibration/apply
+func (h *MemoryHandler) ApplyCalibration(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.svc.ApplyCalibration(r.Context(), r.URL.Query().Get("workspace_id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
 // SearchIndex handles POST /memories/search/index (Layer 1 progressive disclosure)
 func (h *MemoryHandler) SearchIndex(w http.ResponseWriter, r *http.Request) {
 	var req models.SearchRequest
@@ -233,15 +339,19 @@ func (h *MemoryHandler) SearchIndex(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	req.Namespace = GetNamespace(r)
+	req.Caller = GetCaller(r)
+	if !scopeBodyWorkspace(w, r, &req.Workspace) {
+		return
+	}
 
 	if req.Query == "" {
 		writeError(w, http.StatusBadRequest, "query is required")
 		return
 	}
 
-	resp, err := h.svc.SearchIndex(&req)
+	resp, err := h.svc.SearchIndex(r.Context(), &req)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -256,17 +366,24 @@ func (h *MemoryHandler) Timeline(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	req.Namespace = GetNamespace(r)
+	req.Caller = GetCaller(r)
 
 	if req.MemoryID == "" {
 		writeError(w, http.StatusBadRequest, "memoryId is required")
 		return
 	}
 
-	resp, err := h.svc.Timeline(&req)
+	resp, err := h.svc.Timeline(r.Context(), &req)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
+	if !keyAllowsWorkspace(r, resp.Anchor.WorkspaceID) {
+		writeWorkspaceDenied(w, GetAPIKey(r))
+		return
+	}
+	resp.Before = inKeyScope(r, resp.Before)
+	resp.After = inKeyScope(r, resp.After)
 
 	writeJSON(w, http.StatusOK, resp)
 }
@@ -278,17 +395,27 @@ func (h *MemoryHandler) BatchGet(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
 		return
 	}
+	req.Caller = GetCaller(r)
 
 	if len(req.IDs) == 0 {
 		writeError(w, http.StatusBadRequest, "ids array is required")
 		return
 	}
 
-	resp, err := h.svc.BatchGet(&req)
+	resp, err := h.svc.BatchGet(r.Context(), &req)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
+	if scoped := inKeyScope(r, resp.Memories); len(scoped) < len(resp.Memories) {
+		// Memories outside the key's workspace read as missing
+		for _, m := range resp.Memories {
+			if !keyAllowsWorkspace(r, m.WorkspaceID) {
+				resp.Missing = append(resp.Missing, m.ID)
+			}
+		}
+		resp.Memories = scoped
+	}
 
 	writeJSON(w, http.StatusOK, resp)
 }
@@ -308,11 +435,96 @@ func (h *MemoryHandler) Supersede(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := h.svc.Supersede(id, req.NewMemoryID)
+	// The caller must be able to read both sides of the replacement
+	for _, memID := range []string{id, req.NewMemoryID} {
+		if _, ok := h.visible(w, r, memID); !ok {
+			return
+		}
+	}
+
+	resp, err := h.svc.Supersede(r.Context(), id, req.NewMemoryID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, resp)
 }
+
+// Link handles POST /memories/{id}/links
+func (h *MemoryHandler) Link(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateLinkRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	// A workspace-scoped key can only link within its workspace
+	if req.TargetID != "" && workspaceScoped(r) {
+		target, err := h.svc.GetFor(r.Context(), GetCaller(r), req.TargetID)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		if target != nil && !keyAllowsWorkspace(r, target.WorkspaceID) {
+			writeWorkspaceDenied(w, GetAPIKey(r))
+			return
+		}
+	}
+
+	edge, err := h.svc.Link(r.Context(), GetCaller(r), chi.URLParam(r, "id"), &req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, edge)
+}
+
+// Unlink handles DELETE /memories/{id}/links/{targetId}?type=supports
+func (h *MemoryHandler) Unlink(w http.ResponseWriter, r *http.Request) {
+	linkType := models.LinkType(r.URL.Query().Get("type"))
+	targetID := chi.URLParam(r, "targetId")
+
+	// A workspace-scoped key can only unlink within its workspace
+	if workspaceScoped(r) {
+		target, err := h.svc.GetFor(r.Context(), GetCaller(r), targetID)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		if target != nil && !keyAllowsWorkspace(r, target.WorkspaceID) {
+			writeWorkspaceDenied(w, GetAPIKey(r))
+			return
+		}
+	}
+
+	if err := h.svc.Unlink(r.Context(), GetCaller(r), chi.URLParam(r, "id"), targetID, linkType); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Graph handles GET /memories/{id}/graph?depth=N
+func (h *MemoryHandler) Graph(w http.ResponseWriter, r *http.Request) {
+	depth := 1
+	if v := r.URL.Query().Get("depth"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeProblem(w, http.StatusBadRequest, "invalid_depth", "depth must be an integer")
+			return
+		}
+		depth = n
+	}
+
+	caller := GetCaller(r)
+	visible := func(m *models.Memory) bool {
+		return caller.CanSee(m) && keyAllowsWorkspace(r, m.WorkspaceID)
+	}
+	graph, err := h.svc.Graph(r.Context(), visible, chi.URLParam(r, "id"), depth)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, graph)
+}
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/metrics"
+)
+
+// Metrics handles GET /metrics: search, embedding, Qdrant, store and
+// compaction metrics in the Prometheus text exposition format.
+func (h *BulkHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	collectors := []metrics.Collector{metrics.CollectorFunc(func(mw *metrics.Writer) {
+		h.svc.Collect(r.Context(), mw)
+	})}
+	if h.compactor != nil {
+		collectors = append(collectors, h.compactor)
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	// Errors here are failed writes to the client; there's nothing to report
+	_ = metrics.Default.Write(w, collectors...)
+}
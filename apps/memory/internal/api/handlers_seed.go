@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"path/filepath"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/seed"
+)
+
+type SeedHandler struct {
+	loader *seed.Loader
+}
+
+func NewSeedHandler(loader *seed.Loader) *SeedHandler {
+	return &SeedHandler{loader: loader}
+}
+
+// seedRequest is the payload for POST /admin/seed.
+type seedRequest struct {
+	Dir string `json:"dir"`
+}
+
+// Seed handles POST /admin/seed. The fixture directory is read on the
+// server's filesystem.
+func (h *SeedHandler) Seed(w http.ResponseWriter, r *http.Request) {
+	var req seedRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if !filepath.IsAbs(req.Dir) {
+		writeError(w, http.StatusBadRequest, "dir must be an absolute path")
+		return
+	}
+
+	report, err := h.loader.Load(r.Context(), GetNamespace(r), req.Dir)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -12,6 +13,9 @@ import (
 	"github.com/iammorganparry/clive/apps/memory/internal/store"
 )
 
+// maxObservationBatch caps the observations accepted in one batch.
+const maxObservationBatch = 500
+
 // SessionHandler handles session-related HTTP requests.
 type SessionHandler struct {
 	svc        *memory.Service
@@ -53,54 +57,80 @@ func (h *SessionHandler) Summarize(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Ensure session exists
-	workspaceID := store.NamespacedGlobalID(req.Namespace)
-	if req.Workspace != "" {
-		// Use service to resolve workspace
-		// For simplicity, resolve through session store
+	resp, err := h.endSession(r, req.SessionID, req.Namespace, req.Workspace, req.Transcript)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// EndSession handles POST /sessions/{id}/end. It closes a session whose
+// observations were captured as it ran and stores their summary as a
+// SESSION_SUMMARY memory, so agents need not store one explicitly.
+func (h *SessionHandler) EndSession(w http.ResponseWriter, r *http.Request) {
+	var req models.EndSessionRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
 	}
+	req.Namespace = GetNamespace(r)
 
-	sess, err := h.sessStore.EnsureSession(req.SessionID, workspaceID)
+	resp, err := h.endSession(r, chi.URLParam(r, "id"), req.Namespace, req.Workspace, req.Transcript)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "ensure session: "+err.Error())
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// endSession ends a session and summarizes it from the transcript and its
+// observations. A session with neither is ended without a summary.
+func (h *SessionHandler) endSession(r *http.Request, sessionID, namespace, workspace, transcript string) (*models.SummarizeResponse, error) {
+	sess, err := h.sessStore.EnsureSession(sessionID, store.NamespacedGlobalID(namespace))
+	if err != nil {
+		return nil, fmt.Errorf("ensure session: %w", err)
+	}
 
 	// End the session
 	_ = h.sessStore.EndSession(sess.ID)
 
+	// Get observations for richer summary
+	obsText, _ := h.obsStore.FormatForSummary(sess.ID)
+	if transcript == "" && obsText == "" {
+		return &models.SummarizeResponse{SessionID: sess.ID}, nil
+	}
+
 	// Generate summary
 	var summary string
 	if h.summarizer != nil && h.summarizer.IsEnabled() {
-		// Get observations for richer summary
-		obsText, _ := h.obsStore.FormatForSummary(sess.ID)
-		summary, err = h.summarizer.SummarizeWithObservations(req.Transcript, obsText)
-		if err != nil {
-			// Fallback: use raw transcript excerpt
-			summary = fallbackSummary(req.Transcript)
+		summary, err = h.summarizer.SummarizeWithObservations(r.Context(), transcript, obsText)
+	}
+	if summary == "" || err != nil {
+		// No summarizer available, or it failed: use a raw excerpt
+		summary = fallbackSummary(transcript)
+		if summary == "" {
+			summary = fallbackSummary(obsText)
 		}
-	} else {
-		// No summarizer available, use raw excerpt
-		summary = fallbackSummary(req.Transcript)
 	}
 
 	// Store as SESSION_SUMMARY memory
 	storeReq := &models.StoreRequest{
-		Namespace:  req.Namespace,
-		Workspace:  req.Workspace,
+		Namespace:  namespace,
+		Workspace:  workspace,
 		Content:    summary,
 		MemoryType: models.MemoryTypeSessionSummary,
 		Tier:       models.TierShort,
 		Confidence: 0.7,
 		Tags:       []string{"session-summary", "auto-generated"},
 		Source:     "session_summarizer",
-		SessionID:  req.SessionID,
+		SessionID:  sess.ID,
+		Caller:     GetCaller(r),
 	}
 
-	storeResp, err := h.svc.Store(storeReq)
+	storeResp, err := h.svc.Store(r.Context(), storeReq)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "store summary: "+err.Error())
-		return
+		return nil, fmt.Errorf("store summary: %w", err)
 	}
 
 	// Link summary to session
@@ -108,11 +138,11 @@ func (h *SessionHandler) Summarize(w http.ResponseWriter, r *http.Request) {
 		_ = h.sessStore.SetSummaryMemory(sess.ID, storeResp.ID)
 	}
 
-	writeJSON(w, http.StatusOK, models.SummarizeResponse{
+	return &models.SummarizeResponse{
 		SessionID:       sess.ID,
 		SummaryMemoryID: storeResp.ID,
 		Summary:         summary,
-	})
+	}, nil
 }
 
 // ListSessions handles GET /sessions
@@ -127,7 +157,7 @@ func (h *SessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
 
 	sessions, err := h.sessStore.List(workspaceID, limit)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -146,11 +176,11 @@ func (h *SessionHandler) GetSession(w http.ResponseWriter, r *http.Request) {
 
 	sess, err := h.sessStore.GetByID(id)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 	if sess == nil {
-		writeError(w, http.StatusNotFound, "session not found")
+		writeProblem(w, http.StatusNotFound, "session_not_found", "session not found")
 		return
 	}
 
@@ -174,13 +204,57 @@ func (h *SessionHandler) StoreObservation(w http.ResponseWriter, r *http.Request
 
 	obs, err := h.obsStore.Insert(sessionID, &req)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
 	writeJSON(w, http.StatusCreated, obs)
 }
 
+// StoreObservations handles POST /sessions/{id}/observations/batch, which
+// takes the tool calls an agent session captured since its last batch. The
+// session is created on its first batch.
+func (h *SessionHandler) StoreObservations(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+
+	var req models.BatchObservationsRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	switch {
+	case len(req.Observations) == 0:
+		writeError(w, http.StatusBadRequest, "observations must not be empty")
+		return
+	case len(req.Observations) > maxObservationBatch:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("at most %d observations per batch", maxObservationBatch))
+		return
+	}
+	for i, obs := range req.Observations {
+		if obs.ToolName == "" {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("observations[%d]: toolName is required", i))
+			return
+		}
+	}
+
+	if _, err := h.sessStore.EnsureSession(sessionID, store.NamespacedGlobalID(GetNamespace(r))); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	last, err := h.obsStore.InsertBatch(sessionID, req.Observations)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, models.BatchObservationsResponse{
+		SessionID:    sessionID,
+		Stored:       len(req.Observations),
+		LastSequence: last,
+	})
+}
+
 // ListObservations handles GET /sessions/{id}/observations
 func (h *SessionHandler) ListObservations(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "id")
@@ -188,7 +262,7 @@ func (h *SessionHandler) ListObservations(w http.ResponseWriter, r *http.Request
 
 	observations, err := h.obsStore.ListBySession(sessionID, limit)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/iammorganparry/clive/apps/memory/internal/skills"
 )
@@ -21,23 +22,38 @@ type syncRequest struct {
 	Dirs []string `json:"dirs"`
 }
 
-// Sync handles POST /skills/sync
+// Sync handles POST /skills/sync. With ?dry_run=true it reports the
+// changes a sync would make without writing anything.
 func (h *SkillHandler) Sync(w http.ResponseWriter, r *http.Request) {
 	var req syncRequest
 	// Body is optional - ignore decode errors
 	_ = decodeJSON(r, &req)
 
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			writeProblem(w, http.StatusBadRequest, "invalid_dry_run", "dry_run must be true or false")
+			return
+		}
+	}
+
 	var result *skills.SyncResult
 	var err error
 
-	if len(req.Dirs) > 0 {
-		result, err = h.syncSvc.SyncDirs(req.Dirs)
-	} else {
-		result, err = h.syncSvc.Sync()
+	switch {
+	case len(req.Dirs) > 0 && dryRun:
+		result, err = h.syncSvc.PreviewDirs(r.Context(), req.Dirs)
+	case len(req.Dirs) > 0:
+		result, err = h.syncSvc.SyncDirs(r.Context(), req.Dirs)
+	case dryRun:
+		result, err = h.syncSvc.Preview(r.Context())
+	default:
+		result, err = h.syncSvc.Sync(r.Context())
 	}
 
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -60,7 +76,7 @@ type skillListResponse struct {
 func (h *SkillHandler) List(w http.ResponseWriter, r *http.Request) {
 	metas, err := h.syncSvc.ListSkills()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
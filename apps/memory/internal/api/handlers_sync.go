@@ -0,0 +1,97 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+// maxSyncBlobBytes caps one encrypted config bundle.
+const maxSyncBlobBytes = 1 << 20
+
+// SyncHandler stores the encrypted config bundles behind
+// clive-memory config push/pull. Blobs are scoped to the namespace.
+type SyncHandler struct {
+	store *store.SyncStore
+}
+
+func NewSyncHandler(s *store.SyncStore) *SyncHandler {
+	return &SyncHandler{store: s}
+}
+
+// List handles GET /sync/blobs
+func (h *SyncHandler) List(w http.ResponseWriter, r *http.Request) {
+	blobs, err := h.store.List(r.Context(), GetNamespace(r))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"blobs": blobs})
+}
+
+// Get handles GET /sync/blobs/{name}
+func (h *SyncHandler) Get(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	blob, err := h.store.Get(r.Context(), GetNamespace(r), name)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if blob == nil {
+		writeProblem(w, http.StatusNotFound, "sync_blob_not_found", "no sync blob named "+name)
+		return
+	}
+	writeJSON(w, http.StatusOK, blob)
+}
+
+// Put handles PUT /sync/blobs/{name}
+func (h *SyncHandler) Put(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if !isValidNamespace(name) {
+		writeProblem(w, http.StatusBadRequest, "invalid_sync_name", "sync blob names must be alphanumeric, hyphens, underscores only (max 64 chars)")
+		return
+	}
+
+	// Base64 inflates the payload by a third
+	r.Body = http.MaxBytesReader(w, r.Body, maxSyncBlobBytes*4/3+1024)
+	var req models.PutSyncBlobRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if len(req.Data) == 0 {
+		writeError(w, http.StatusBadRequest, "data is required")
+		return
+	}
+	if len(req.Data) > maxSyncBlobBytes {
+		writeProblem(w, http.StatusRequestEntityTooLarge, "sync_blob_too_large",
+			fmt.Sprintf("sync blobs are limited to %d bytes, got %d", maxSyncBlobBytes, len(req.Data)))
+		return
+	}
+
+	blob, err := h.store.Put(r.Context(), GetNamespace(r), name, req.Data, req.BaseVersion)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, blob)
+}
+
+// Delete handles DELETE /sync/blobs/{name}
+func (h *SyncHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	deleted, err := h.store.Delete(r.Context(), GetNamespace(r), name)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if !deleted {
+		writeProblem(w, http.StatusNotFound, "sync_blob_not_found", "no sync blob named "+name)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -35,9 +36,9 @@ func (h *ThreadHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	thread, err := h.svc.Create(&req)
+	thread, err := h.svc.Create(r.Context(), &req)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -53,9 +54,9 @@ func (h *ThreadHandler) List(w http.ResponseWriter, r *http.Request) {
 		Name:      r.URL.Query().Get("name"),
 	}
 
-	threads, err := h.svc.List(req)
+	threads, err := h.svc.List(r.Context(), req)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -72,13 +73,13 @@ func (h *ThreadHandler) List(w http.ResponseWriter, r *http.Request) {
 func (h *ThreadHandler) Get(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	result, err := h.svc.Get(id)
+	result, err := h.svc.Get(r.Context(), id)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 	if result == nil {
-		writeError(w, http.StatusNotFound, "thread not found")
+		writeProblem(w, http.StatusNotFound, "thread_not_found", "thread not found")
 		return
 	}
 
@@ -100,9 +101,9 @@ func (h *ThreadHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	thread, err := h.svc.Update(id, &req)
+	thread, err := h.svc.Update(r.Context(), id, &req)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -113,8 +114,8 @@ func (h *ThreadHandler) Update(w http.ResponseWriter, r *http.Request) {
 func (h *ThreadHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	if err := h.svc.Delete(id); err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		writeServiceError(w, err)
 		return
 	}
 
@@ -132,28 +133,121 @@ func (h *ThreadHandler) AppendEntry(w http.ResponseWriter, r *http.Request) {
 	}
 	req.Namespace = GetNamespace(r)
 
-	if req.Content == "" {
-		writeError(w, http.StatusBadRequest, "content is required")
+	if msg := validateEntry(&req); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
 		return
 	}
 
-	if req.Section != "" && !req.Section.IsValid() {
-		writeError(w, http.StatusBadRequest, "invalid section: must be findings, decisions, architecture, todo, or context")
+	entry, err := h.svc.AppendEntry(r.Context(), id, &req)
+	if err != nil {
+		writeServiceError(w, err)
 		return
 	}
 
-	if req.MemoryType != "" && !req.MemoryType.IsValid() {
-		writeError(w, http.StatusBadRequest, "invalid memoryType")
+	writeJSON(w, http.StatusCreated, entry)
+}
+
+// AppendEntries handles POST /threads/{id}/entries/batch
+func (h *ThreadHandler) AppendEntries(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req models.AppendEntriesRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
 		return
 	}
 
-	entry, err := h.svc.AppendEntry(id, &req)
+	reqs := make([]*models.AppendEntryRequest, len(req.Entries))
+	for i := range req.Entries {
+		e := &req.Entries[i]
+		e.Namespace = GetNamespace(r)
+		if e.Workspace == "" {
+			e.Workspace = req.Workspace
+		}
+		if msg := validateEntry(e); msg != "" {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("entry %d: %s", i, msg))
+			return
+		}
+		reqs[i] = e
+	}
+
+	entries, err := h.svc.AppendEntries(r.Context(), id, reqs)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, entry)
+	writeJSON(w, http.StatusCreated, models.AppendEntriesResponse{Entries: entries})
+}
+
+// ReclassifyEntries handles POST /threads/{id}/entries/reclassify
+func (h *ThreadHandler) ReclassifyEntries(w http.ResponseWriter, r *http.Request) {
+	var req models.ReclassifyEntriesRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	resp, err := h.svc.ReclassifyEntries(r.Context(), chi.URLParam(r, "id"), &req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// UpdateEntry handles PATCH /threads/{id}/entries/{entryId}
+func (h *ThreadHandler) UpdateEntry(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateEntryRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	entry, err := h.svc.UpdateEntry(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "entryId"), &req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entry)
+}
+
+// DeleteEntry handles DELETE /threads/{id}/entries/{entryId}
+func (h *ThreadHandler) DeleteEntry(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.DeleteEntry(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "entryId")); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// EntryEdits handles GET /threads/{id}/edits
+func (h *ThreadHandler) EntryEdits(w http.ResponseWriter, r *http.Request) {
+	edits, err := h.svc.EntryEdits(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.EntryEditsResponse{Edits: edits})
+}
+
+// validateEntry checks an append request, returning a message for the first
+// problem found or "" when the entry is valid.
+func validateEntry(req *models.AppendEntryRequest) string {
+	if req.Content == "" {
+		return "content is required"
+	}
+	if req.Section != "" && !req.Section.IsValid() {
+		return "invalid section: must be findings, decisions, architecture, todo, or context"
+	}
+	if req.MemoryType != "" && !req.MemoryType.IsValid() {
+		return "invalid memoryType"
+	}
+	return ""
 }
 
 // Close handles POST /threads/{id}/close
@@ -166,9 +260,9 @@ func (h *ThreadHandler) Close(w http.ResponseWriter, r *http.Request) {
 		req = models.CloseThreadRequest{}
 	}
 
-	resp, err := h.svc.Close(id, req.Distill)
+	resp, err := h.svc.Close(r.Context(), id, req.Distill)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -179,9 +273,9 @@ func (h *ThreadHandler) Close(w http.ResponseWriter, r *http.Request) {
 func (h *ThreadHandler) GetContext(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	context, err := h.svc.GetContext(id)
+	context, err := h.svc.GetContext(r.Context(), id)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
@@ -196,9 +290,9 @@ func (h *ThreadHandler) GetActiveContext(w http.ResponseWriter, r *http.Request)
 	workspace := r.URL.Query().Get("workspace")
 	branch := r.URL.Query().Get("branch")
 
-	context, err := h.svc.GetActiveContext(namespace, workspace, branch)
+	context, err := h.svc.GetActiveContext(r.Context(), namespace, workspace, branch)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
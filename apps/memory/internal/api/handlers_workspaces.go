@@ -1,11 +1,16 @@
 package api
 
 import (
+	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
 )
 
 type WorkspaceHandler struct {
@@ -18,11 +23,20 @@ func NewWorkspaceHandler(svc *memory.Service) *WorkspaceHandler {
 
 // List handles GET /workspaces
 func (h *WorkspaceHandler) List(w http.ResponseWriter, r *http.Request) {
-	workspaces, err := h.svc.ListWorkspaces()
+	workspaces, err := h.svc.ListWorkspaces(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
+	if workspaceScoped(r) {
+		scoped := workspaces[:0]
+		for _, ws := range workspaces {
+			if keyAllowsWorkspace(r, ws.ID) {
+				scoped = append(scoped, ws)
+			}
+		}
+		workspaces = scoped
+	}
 
 	writeJSON(w, http.StatusOK, workspaces)
 }
@@ -31,11 +45,89 @@ func (h *WorkspaceHandler) List(w http.ResponseWriter, r *http.Request) {
 func (h *WorkspaceHandler) Stats(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	stats, err := h.svc.GetWorkspaceStats(id)
+	stats, err := h.svc.GetWorkspaceStats(r.Context(), id)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, stats)
 }
+
+// Merge handles POST /workspaces/merge
+func (h *WorkspaceHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	var req models.WorkspaceMergeRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	req.Namespace = GetNamespace(r)
+
+	if req.Remote == "" {
+		writeError(w, http.StatusBadRequest, "remote is required")
+		return
+	}
+	if len(req.Paths) == 0 {
+		writeError(w, http.StatusBadRequest, "paths array is required")
+		return
+	}
+	for _, p := range req.Paths {
+		if !filepath.IsAbs(p) {
+			writeError(w, http.StatusBadRequest, "paths must be absolute: "+p)
+			return
+		}
+	}
+
+	resp, err := h.svc.MergeWorkspaces(r.Context(), &req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// maxImportBytes caps an uploaded archive.
+const maxImportBytes = 1 << 30
+
+// Export handles POST /workspaces/{id}/export
+func (h *WorkspaceHandler) Export(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	// Spooled to disk so a failure part way through still gets a problem
+	// response, without holding a large workspace in memory
+	spool, err := os.CreateTemp("", "clive-export-*.ndjson.gz")
+	if err != nil {
+		writeServiceError(w, fmt.Errorf("create export spool: %w", err))
+		return
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	if err := h.svc.Export(r.Context(), GetCaller(r), id, spool); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		writeServiceError(w, fmt.Errorf("rewind export spool: %w", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="clive-memory-%s.ndjson.gz"`, id))
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, spool)
+}
+
+// Import handles POST /import
+func (h *WorkspaceHandler) Import(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+
+	report, err := h.svc.Import(r.Context(), r.Body)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+const idempotencyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLen bounds client-supplied keys.
+const maxIdempotencyKeyLen = 255
+
+// Idempotency replays the stored response when a request repeats an
+// Idempotency-Key within the store's window. Requests without the header
+// pass through. Only successful responses are recorded, so failed attempts
+// can be retried with the same key.
+func Idempotency(idem *store.IdempotencyStore, logger *slog.Logger) func(http.Handler) http.Handler {
+	// Striped locks serialize concurrent requests sharing a key, so a retry
+	// racing the original waits for its recorded response.
+	var locks [64]sync.Mutex
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLen {
+				writeError(w, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "read request body: "+err.Error())
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			requestHash := fmt.Sprintf("%x", sha256.Sum256(body))
+
+			namespace := GetNamespace(r)
+			route := r.Method + " " + unversionedPath(r.URL.Path)
+
+			h := fnv.New32a()
+			h.Write([]byte(namespace + "\x00" + route + "\x00" + key))
+			mu := &locks[h.Sum32()%uint32(len(locks))]
+			mu.Lock()
+			defer mu.Unlock()
+
+			prev, err := idem.Get(r.Context(), namespace, route, key)
+			if err != nil {
+				writeServiceError(w, err)
+				return
+			}
+			if prev != nil {
+				if prev.RequestHash != requestHash {
+					writeProblem(w, http.StatusUnprocessableEntity, "idempotency_key_reused",
+						"Idempotency-Key was already used with a different request body")
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(prev.Status)
+				w.Write(prev.Body)
+				return
+			}
+
+			rec := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if rec.status >= 200 && rec.status < 300 {
+				// Saved even if the client has gone, since that is when
+				// it retries
+				err := idem.Save(context.WithoutCancel(r.Context()), namespace, route, key, &store.IdempotentResponse{
+					RequestHash: requestHash,
+					Status:      rec.status,
+					Body:        rec.buf.Bytes(),
+				})
+				if err != nil {
+					logger.Warn("failed to record idempotency key", "error", err)
+				}
+			}
+		})
+	}
+}
+
+// recordingWriter captures the status and body while writing through.
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	buf    bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.buf.Write(b)
+	return w.ResponseWriter.Write(b)
+}
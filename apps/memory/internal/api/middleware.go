@@ -4,15 +4,22 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/shutdown"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
 )
 
 type contextKey string
 
 const requestIDKey contextKey = "requestID"
 const namespaceKey contextKey = "namespace"
+const callerKey contextKey = "caller"
+const apiKeyKey contextKey = "apiKey"
 
 const defaultNamespace = "default"
 const namespaceHeader = "X-Clive-Namespace"
@@ -62,8 +69,9 @@ func Recovery(logger *slog.Logger) func(http.Handler) http.Handler {
 func CORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Clive-Namespace")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Clive-Namespace, X-Clive-API-Version, Idempotency-Key")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Clive-API-Version, Deprecation, Link, Warning")
 
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
@@ -74,29 +82,83 @@ func CORS(next http.Handler) http.Handler {
 	})
 }
 
-// BearerAuth validates Authorization: Bearer <token> header.
-// If apiKey is empty, auth is disabled (passthrough).
-func BearerAuth(apiKey string) func(http.Handler) http.Handler {
+// Auth holds the accepted bearer tokens. APIKey is shared and anonymous;
+// Keys maps per-caller tokens to the identity memory ACLs are checked
+// against. Scoped holds the keys managed through /keys, which are limited
+// to a namespace or workspace and may be read-only.
+type Auth struct {
+	APIKey string
+	Keys   map[string]models.Caller
+	Scoped *store.APIKeyStore
+}
+
+// BearerAuth validates Authorization: Bearer <token> header and records
+// the caller a per-caller token belongs to, and for scoped keys the key
+// itself, which KeyScope enforces.
+// If no key is configured, auth is disabled (passthrough).
+func BearerAuth(auth Auth) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if apiKey == "" {
+			if auth.APIKey == "" && len(auth.Keys) == 0 {
 				next.ServeHTTP(w, r)
 				return
 			}
-			auth := r.Header.Get("Authorization")
-			if auth == "" || auth != "Bearer "+apiKey {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" {
 				writeError(w, http.StatusUnauthorized, "unauthorized")
 				return
 			}
-			next.ServeHTTP(w, r)
+			if token == auth.APIKey {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if caller, ok := auth.Keys[token]; ok {
+				ctx := context.WithValue(r.Context(), callerKey, caller)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+			if auth.Scoped == nil {
+				writeError(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+			key, err := auth.Scoped.Lookup(r.Context(), token)
+			if err != nil {
+				writeServiceError(w, err)
+				return
+			}
+			if key == nil {
+				writeError(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+			ctx := context.WithValue(r.Context(), callerKey, key.Caller())
+			ctx = context.WithValue(ctx, apiKeyKey, key)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// GetCaller retrieves the authenticated caller from request context.
+// Requests without a per-caller token are anonymous.
+func GetCaller(r *http.Request) models.Caller {
+	caller, _ := r.Context().Value(callerKey).(models.Caller)
+	return caller
+}
+
+// GetAPIKey retrieves the scoped API key a request was made with, or nil
+// for requests made with the shared or a per-caller key.
+func GetAPIKey(r *http.Request) *models.APIKey {
+	key, _ := r.Context().Value(apiKeyKey).(*models.APIKey)
+	return key
+}
+
 // NamespaceExtractor reads X-Clive-Namespace header and injects into context.
+// Requests made with a namespace-scoped key default to the key's namespace.
 func NamespaceExtractor(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ns := r.Header.Get(namespaceHeader)
+		if key := GetAPIKey(r); ns == "" && key != nil {
+			ns = key.Namespace
+		}
 		if ns == "" {
 			ns = defaultNamespace
 		}
@@ -138,3 +200,94 @@ func (w *statusWriter) WriteHeader(status int) {
 	w.status = status
 	w.ResponseWriter.WriteHeader(status)
 }
+
+// Unwrap exposes the underlying writer to http.ResponseController, so
+// handlers can flush streamed responses.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// readOnlyPosts are POST routes that only read, so they stay open while
+// the server drains writes during shutdown and to read-only keys.
+var readOnlyPosts = map[string]bool{
+	"/memories/search":       true,
+	"/memories/search/index": true,
+	"/memories/timeline":     true,
+	"/memories/batch":        true,
+}
+
+// isReadOnlyPost reports whether a POST path only reads, including
+// POST /workspaces/{id}/export.
+func isReadOnlyPost(path string) bool {
+	if readOnlyPosts[path] {
+		return true
+	}
+	id, ok := strings.CutPrefix(path, "/workspaces/")
+	if !ok {
+		return false
+	}
+	id, ok = strings.CutSuffix(id, "/export")
+	return ok && id != "" && !strings.Contains(id, "/")
+}
+
+// isWrite reports whether a request may mutate state.
+func isWrite(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	case http.MethodPost:
+		return !isReadOnlyPost(unversionedPath(r.URL.Path))
+	}
+	return true
+}
+
+// WriteGate registers mutating requests with the shutdown coordinator and
+// rejects them with 503 once draining has started.
+// If coord is nil, the gate is disabled (passthrough).
+func WriteGate(coord *shutdown.Coordinator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if coord == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isWrite(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			done, ok := coord.Begin(r.Method + " " + r.URL.Path)
+			if !ok {
+				w.Header().Set("Retry-After", "5")
+				writeProblem(w, http.StatusServiceUnavailable, "shutting_down", "server is shutting down")
+				return
+			}
+			defer done()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Timeouts are the per-endpoint request deadlines. A zero duration disables
+// the deadline for that class of endpoint.
+type Timeouts struct {
+	Default time.Duration // everything not listed below
+	Search  time.Duration // /memories/search and /memories/search/index
+	Store   time.Duration // POST /memories
+	Bulk    time.Duration // batch writes, compaction, merges, syncs and summaries
+}
+
+// Timeout bounds the request context with a deadline of d. Handlers see the
+// deadline through r.Context() and report it as 504 request_timeout.
+// If d is zero, the middleware is a passthrough.
+func Timeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if d <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
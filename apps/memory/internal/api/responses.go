@@ -3,16 +3,98 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
 )
 
+// statusClientClosedRequest is the non-standard status (popularised by nginx)
+// recorded when the client disconnects before the response is written.
+const statusClientClosedRequest = 499
+
+// Problem is an RFC 7807 problem details body. Code is a stable,
+// machine-readable identifier clients can branch on.
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Code   string `json:"code"`
+}
+
 func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(v)
 }
 
+// writeError writes a problem+json response with a generic code derived
+// from the status.
 func writeError(w http.ResponseWriter, status int, message string) {
-	writeJSON(w, status, map[string]string{"error": message})
+	writeProblem(w, status, codeForStatus(status), message)
+}
+
+// writeServiceError maps a service-layer error to a problem+json response.
+func writeServiceError(w http.ResponseWriter, err error) {
+	e := apperr.From(err)
+	writeProblem(w, statusForKind(e.Kind), e.Code, e.Error())
+}
+
+func writeProblem(w http.ResponseWriter, status int, code, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	title := http.StatusText(status)
+	if status == statusClientClosedRequest {
+		title = "Client Closed Request"
+	}
+	json.NewEncoder(w).Encode(Problem{
+		Type:   "about:blank",
+		Title:  title,
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	})
+}
+
+func statusForKind(kind apperr.Kind) int {
+	switch kind {
+	case apperr.KindNotFound:
+		return http.StatusNotFound
+	case apperr.KindConflict:
+		return http.StatusConflict
+	case apperr.KindValidationFailed:
+		return http.StatusBadRequest
+	case apperr.KindDependencyUnavailable:
+		return http.StatusServiceUnavailable
+	case apperr.KindTimeout:
+		return http.StatusGatewayTimeout
+	case apperr.KindCanceled:
+		return statusClientClosedRequest
+	case apperr.KindQuotaExceeded:
+		return http.StatusInsufficientStorage
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return string(apperr.KindValidationFailed)
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusNotFound:
+		return string(apperr.KindNotFound)
+	case http.StatusConflict:
+		return string(apperr.KindConflict)
+	case http.StatusServiceUnavailable:
+		return string(apperr.KindDependencyUnavailable)
+	case http.StatusGatewayTimeout:
+		return "request_timeout"
+	case http.StatusInsufficientStorage:
+		return string(apperr.KindQuotaExceeded)
+	default:
+		return string(apperr.KindInternal)
+	}
 }
 
 func decodeJSON(r *http.Request, v any) error {
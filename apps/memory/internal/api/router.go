@@ -1,31 +1,44 @@
 package api
 
 import (
+	"context"
 	"log/slog"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/iammorganparry/clive/apps/memory/internal/attachments"
 	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
 	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/seed"
 	"github.com/iammorganparry/clive/apps/memory/internal/sessions"
+	"github.com/iammorganparry/clive/apps/memory/internal/shutdown"
 	"github.com/iammorganparry/clive/apps/memory/internal/skills"
 	"github.com/iammorganparry/clive/apps/memory/internal/store"
 	"github.com/iammorganparry/clive/apps/memory/internal/threads"
 	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
 )
 
+// idempotencyWindow is how long Idempotency-Key responses are replayed.
+const idempotencyWindow = 24 * time.Hour
+
 // NewRouter creates the Chi router with all routes and middleware.
 func NewRouter(
 	db *store.DB,
 	svc *memory.Service,
-	ollama *embedding.OllamaClient,
+	embedder embedding.Embedder,
+	warmer *embedding.Warmer,
 	qdrant *vectorstore.QdrantClient,
 	skillSync *skills.SyncService,
 	sessStore *sessions.SessionStore,
 	obsStore *sessions.ObservationStore,
 	summarizer *sessions.Summarizer,
 	threadSvc *threads.Service,
-	apiKey string,
+	shutdownCoord *shutdown.Coordinator,
+	compactor *memory.Compactor,
+	attachmentSvc *attachments.Service,
+	timeouts Timeouts,
+	auth Auth,
 	logger *slog.Logger,
 ) *chi.Mux {
 	r := chi.NewRouter()
@@ -37,59 +50,164 @@ func NewRouter(
 	r.Use(Recovery(logger))
 
 	// Handlers
-	healthH := NewHealthHandler(db, ollama, qdrant)
+	healthH := NewHealthHandler(db, embedder, qdrant, warmer)
 	memoryH := NewMemoryHandler(svc)
-	bulkH := NewBulkHandler(svc)
+	bulkH := NewBulkHandler(svc, compactor)
 	workspaceH := NewWorkspaceHandler(svc)
+	idem := Idempotency(store.NewIdempotencyStore(db, idempotencyWindow), logger)
+	syncH := NewSyncHandler(store.NewSyncStore(db))
+	seedH := NewSeedHandler(seed.NewLoader(svc, threadSvc, store.NewWorkspaceStore(db), logger))
+	auth.Scoped = store.NewAPIKeyStore(db)
+	keyH := NewKeyHandler(auth.Scoped, store.NewWorkspaceStore(db), auth.APIKey != "")
+	var attachmentH *AttachmentHandler
+	if attachmentSvc != nil {
+		attachmentH = NewAttachmentHandler(attachmentSvc, svc)
+	}
+
+	// Workspace-scoped keys reach a memory or workspace by ID only within
+	// their workspace
+	memoryStore := store.NewMemoryStore(db)
+	memoryScope := WorkspaceScope(func(ctx context.Context, id string) (string, error) {
+		m, err := memoryStore.GetByID(ctx, id)
+		if err != nil || m == nil {
+			return "", err
+		}
+		return m.WorkspaceID, nil
+	})
+	workspaceScope := WorkspaceScope(func(_ context.Context, id string) (string, error) { return id, nil })
 
 	// Unauthenticated routes
 	r.Get("/health", healthH.Health)
+	r.Get("/ready", healthH.Ready)
 
-	// Authenticated routes
-	r.Group(func(r chi.Router) {
-		r.Use(BearerAuth(apiKey))
+	// Authenticated routes, served under /v1 and, deprecated, at the legacy
+	// unversioned paths
+	api := func(r chi.Router) {
+		r.Use(BearerAuth(auth))
 		r.Use(NamespaceExtractor)
+		r.Use(KeyScope)
+		r.Use(WriteGate(shutdownCoord))
+
+		// Deadlines can only shrink as middleware nests, so each route gets
+		// exactly one Timeout rather than a group default plus overrides.
+		deadline := Timeout(timeouts.Default)
+		bulk := Timeout(timeouts.Bulk)
 
 		r.Route("/memories", func(r chi.Router) {
-			r.Get("/", memoryH.List)
-			r.Post("/", memoryH.Store)
-			r.Post("/search", memoryH.Search)
-			r.Post("/search/index", memoryH.SearchIndex)
-			r.Post("/timeline", memoryH.Timeline)
-			r.Post("/batch", memoryH.BatchGet)
-			r.Post("/bulk", bulkH.BulkStore)
-			r.Post("/compact", bulkH.Compact)
-			r.Get("/impact-leaders", memoryH.ImpactLeaders)
-			r.Get("/{id}", memoryH.Get)
-			r.Patch("/{id}", memoryH.Update)
-			r.Delete("/{id}", memoryH.Delete)
-			r.Post("/{id}/impact", memoryH.RecordImpact)
-			r.Get("/{id}/impact", memoryH.ImpactEvents)
-			r.Post("/{id}/supersede", memoryH.Supersede)
+			r.With(idem, Timeout(timeouts.Store)).Post("/", memoryH.Store)
+			r.With(Timeout(timeouts.Search)).Post("/search", memoryH.Search)
+			r.With(Timeout(timeouts.Search)).Post("/search/index", memoryH.SearchIndex)
+			r.With(idem, bulk).Post("/bulk", bulkH.BulkStore)
+			r.With(idem, bulk).Post("/retag", bulkH.Retag)
+			r.With(AdminOnly, bulk).Post("/compact", bulkH.Compact)
+			r.With(AdminOnly, bulk).Post("/impact/recalculate", bulkH.RecalculateImpact)
+			r.With(AdminOnly, deadline).Post("/calibration/apply", memoryH.ApplyCalibration)
+
+			r.Group(func(r chi.Router) {
+				r.Use(deadline)
+				r.Use(memoryScope)
+				r.Get("/", memoryH.List)
+				r.Post("/timeline", memoryH.Timeline)
+				r.Post("/batch", memoryH.BatchGet)
+				r.Get("/impact-leaders", memoryH.ImpactLeaders)
+				r.Get("/templates", memoryH.Templates)
+				r.Get("/calibration", memoryH.Calibration)
+				r.Get("/{id}", memoryH.Get)
+				r.Patch("/{id}", memoryH.Update)
+				r.Delete("/{id}", memoryH.Delete)
+				r.Post("/{id}/impact", memoryH.RecordImpact)
+				r.Get("/{id}/impact", memoryH.ImpactEvents)
+				r.Get("/{id}/retrievability", memoryH.Retrievability)
+				r.Post("/{id}/supersede", memoryH.Supersede)
+				r.Post("/{id}/links", memoryH.Link)
+				r.Delete("/{id}/links/{targetId}", memoryH.Unlink)
+				r.Get("/{id}/graph", memoryH.Graph)
+				if attachmentH != nil {
+					r.Post("/{id}/attachments", attachmentH.Upload)
+					r.Get("/{id}/attachments", attachmentH.ListForMemory)
+				}
+			})
 		})
 
 		r.Route("/workspaces", func(r chi.Router) {
-			r.Get("/", workspaceH.List)
-			r.Get("/{id}/stats", workspaceH.Stats)
+			r.With(deadline).Get("/", workspaceH.List)
+			r.With(AdminOnly, bulk).Post("/merge", workspaceH.Merge)
+			r.With(workspaceScope, deadline).Get("/{id}/stats", workspaceH.Stats)
+			r.With(workspaceScope, bulk).Post("/{id}/export", workspaceH.Export)
+		})
+		r.With(AdminOnly, bulk).Post("/import", workspaceH.Import)
+
+		// Fixture loading for demo and test instances
+		r.With(AdminOnly, bulk).Post("/admin/seed", seedH.Seed)
+
+		// Scoped API key management
+		r.Route("/keys", func(r chi.Router) {
+			r.Use(AdminOnly, deadline)
+			r.Post("/", keyH.Create)
+			r.Get("/", keyH.List)
+			r.Delete("/{id}", keyH.Revoke)
 		})
 
 		// Session routes
 		if sessStore != nil {
 			sessionH := NewSessionHandler(svc, sessStore, obsStore, summarizer)
 			r.Route("/sessions", func(r chi.Router) {
-				r.Get("/", sessionH.ListSessions)
-				r.Post("/summarize", sessionH.Summarize)
-				r.Get("/{id}", sessionH.GetSession)
-				r.Post("/{id}/observations", sessionH.StoreObservation)
-				r.Get("/{id}/observations", sessionH.ListObservations)
+				r.With(bulk).Post("/summarize", sessionH.Summarize)
+				r.With(bulk).Post("/{id}/end", sessionH.EndSession)
+				r.With(idem, bulk).Post("/{id}/observations/batch", sessionH.StoreObservations)
+				r.Group(func(r chi.Router) {
+					r.Use(deadline)
+					r.Get("/", sessionH.ListSessions)
+					r.Get("/{id}", sessionH.GetSession)
+					r.Post("/{id}/observations", sessionH.StoreObservation)
+					r.Get("/{id}/observations", sessionH.ListObservations)
+				})
 			})
 		}
 
 		if skillSync != nil {
 			skillH := NewSkillHandler(skillSync)
 			r.Route("/skills", func(r chi.Router) {
-				r.Post("/sync", skillH.Sync)
-				r.Get("/", skillH.List)
+				r.With(AdminOnly, bulk).Post("/sync", skillH.Sync)
+				r.With(deadline).Get("/", skillH.List)
+			})
+		}
+
+		// Encrypted config sync
+		r.Route("/sync/blobs", func(r chi.Router) {
+			r.Use(deadline)
+			r.Get("/", syncH.List)
+			r.Get("/{name}", syncH.Get)
+			r.Put("/{name}", syncH.Put)
+			r.Delete("/{name}", syncH.Delete)
+		})
+
+		// Vector store drift between SQLite and Qdrant, and moving points
+		// after the sharding strategy changes
+		r.With(AdminOnly, bulk).Get("/stats/vectors", bulkH.VectorStats)
+		r.With(AdminOnly, bulk).Post("/vectors/reconcile", bulkH.ReconcileVectors)
+		r.With(AdminOnly, bulk).Post("/vectors/rebalance", bulkH.RebalanceVectors)
+
+		// Live memory lifecycle events. Streams have no deadline.
+		r.With(WorkspaceScope(nil)).Get("/events", NewEventHandler(svc).Stream)
+
+		// Prometheus metrics
+		r.With(AdminOnly, deadline).Get("/metrics", bulkH.Metrics)
+
+		// Compaction history and database size
+		if compactor != nil {
+			r.With(AdminOnly, deadline).Get("/compact/history", bulkH.CompactHistory)
+			r.With(AdminOnly, deadline).Get("/stats/db", bulkH.DBStats)
+		}
+
+		// Attachment routes. The handlers check the workspace of each
+		// attachment's memory against the key.
+		if attachmentH != nil {
+			r.Route("/attachments", func(r chi.Router) {
+				r.Use(WorkspaceScope(nil), deadline)
+				r.Get("/", attachmentH.ListRecent)
+				r.Get("/{id}", attachmentH.Get)
+				r.Delete("/{id}", attachmentH.Delete)
 			})
 		}
 
@@ -97,17 +215,34 @@ func NewRouter(
 		if threadSvc != nil {
 			threadH := NewThreadHandler(threadSvc)
 			r.Route("/threads", func(r chi.Router) {
-				r.Post("/", threadH.Create)
-				r.Get("/", threadH.List)
-				r.Get("/active/context", threadH.GetActiveContext)
-				r.Get("/{id}", threadH.Get)
-				r.Patch("/{id}", threadH.Update)
-				r.Delete("/{id}", threadH.Delete)
-				r.Post("/{id}/entries", threadH.AppendEntry)
-				r.Post("/{id}/close", threadH.Close)
-				r.Get("/{id}/context", threadH.GetContext)
+				r.With(bulk).Post("/{id}/close", threadH.Close)
+				r.With(idem, bulk).Post("/{id}/entries/batch", threadH.AppendEntries)
+				r.Group(func(r chi.Router) {
+					r.Use(deadline)
+					r.Post("/", threadH.Create)
+					r.Get("/", threadH.List)
+					r.Get("/active/context", threadH.GetActiveContext)
+					r.Get("/{id}", threadH.Get)
+					r.Patch("/{id}", threadH.Update)
+					r.Delete("/{id}", threadH.Delete)
+					r.Post("/{id}/entries", threadH.AppendEntry)
+					r.Post("/{id}/entries/reclassify", threadH.ReclassifyEntries)
+					r.Patch("/{id}/entries/{entryId}", threadH.UpdateEntry)
+					r.Delete("/{id}/entries/{entryId}", threadH.DeleteEntry)
+					r.Get("/{id}/edits", threadH.EntryEdits)
+					r.Get("/{id}/context", threadH.GetContext)
+				})
 			})
 		}
+	}
+	r.Route(versionPrefix(APIVersion), func(r chi.Router) {
+		r.Use(VersionNegotiation(APIVersion))
+		api(r)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(Deprecated)
+		r.Use(VersionNegotiation(APIVersion))
+		api(r)
 	})
 
 	return r
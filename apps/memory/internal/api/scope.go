@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+// workspaceScopedPrefixes are the route trees a workspace-scoped key may
+// reach. Every route under them checks the workspace it touches, either
+// with WorkspaceScope or in the handler.
+var workspaceScopedPrefixes = []string{"/memories", "/workspaces", "/events", "/attachments"}
+
+// KeyScope enforces the namespace, access level and route tree of scoped
+// API keys. Requests made with any other key, or with auth disabled, pass
+// through.
+func KeyScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := GetAPIKey(r)
+		if key == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if key.Namespace != "" && GetNamespace(r) != key.Namespace {
+			writeScopeDenied(w, fmt.Sprintf("key %s is scoped to namespace %s", key.Prefix, key.Namespace))
+			return
+		}
+		if key.Access != models.KeyAccessReadWrite && isWrite(r) {
+			writeProblem(w, http.StatusForbidden, "key_read_only", fmt.Sprintf("key %s is read-only", key.Prefix))
+			return
+		}
+		if key.WorkspaceID != "" && !underAny(unversionedPath(r.URL.Path), workspaceScopedPrefixes) {
+			writeWorkspaceDenied(w, key)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AdminOnly keeps a route to the shared API key: key management and the
+// maintenance routes that act on every workspace. Scoped keys and
+// per-caller identities are refused.
+func AdminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := GetAPIKey(r); key != nil {
+			writeScopeDenied(w, fmt.Sprintf("key %s is scoped and cannot use this route", key.Prefix))
+			return
+		}
+		if caller := GetCaller(r); !caller.Anonymous() {
+			writeScopeDenied(w, fmt.Sprintf("%s cannot use this route; it needs the shared API key", caller.Name))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WorkspaceScope holds workspace-scoped keys to their workspace on a route.
+// A workspace_id query parameter must name the key's workspace, and is set
+// to it when absent. If owner is set, it returns the workspace the route's
+// {id} belongs to, or "" when there is none for the handler to report.
+func WorkspaceScope(owner func(ctx context.Context, id string) (string, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !workspaceScoped(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := GetAPIKey(r)
+
+			q := r.URL.Query()
+			switch q.Get("workspace_id") {
+			case key.WorkspaceID:
+			case "":
+				q.Set("workspace_id", key.WorkspaceID)
+				r.URL.RawQuery = q.Encode()
+			default:
+				writeWorkspaceDenied(w, key)
+				return
+			}
+
+			if id := chi.URLParam(r, "id"); owner != nil && id != "" {
+				ws, err := owner(r.Context(), id)
+				if err != nil {
+					writeServiceError(w, err)
+					return
+				}
+				if ws != "" && ws != key.WorkspaceID {
+					writeWorkspaceDenied(w, key)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// scopeBodyWorkspace holds a request body's workspace to the key's,
+// filling it in when the body leaves it empty. It reports false, having
+// written the response, when the body names another workspace.
+func scopeBodyWorkspace(w http.ResponseWriter, r *http.Request, workspace *string) bool {
+	if !workspaceScoped(r) {
+		return true
+	}
+	key := GetAPIKey(r)
+	if *workspace == "" {
+		*workspace = key.Workspace
+		return true
+	}
+	if normalizeWorkspace(*workspace) != key.Workspace {
+		writeWorkspaceDenied(w, key)
+		return false
+	}
+	return true
+}
+
+// workspaceScoped reports whether the request was made with a
+// workspace-scoped key.
+func workspaceScoped(r *http.Request) bool {
+	key := GetAPIKey(r)
+	return key != nil && key.WorkspaceID != ""
+}
+
+// keyAllowsWorkspace reports whether the request's key reaches memories in
+// the given workspace.
+func keyAllowsWorkspace(r *http.Request, workspaceID string) bool {
+	return !workspaceScoped(r) || GetAPIKey(r).WorkspaceID == workspaceID
+}
+
+// inKeyScope drops the memories outside the request key's workspace.
+func inKeyScope(r *http.Request, memories []*models.Memory) []*models.Memory {
+	if !workspaceScoped(r) {
+		return memories
+	}
+	scoped := make([]*models.Memory, 0, len(memories))
+	for _, m := range memories {
+		if keyAllowsWorkspace(r, m.WorkspaceID) {
+			scoped = append(scoped, m)
+		}
+	}
+	return scoped
+}
+
+// normalizeWorkspace cleans a workspace path so equivalent spellings of it
+// compare equal. Remote workspaces are left as given.
+func normalizeWorkspace(workspace string) string {
+	if store.IsRemoteWorkspace(workspace) {
+		return workspace
+	}
+	return filepath.Clean(workspace)
+}
+
+func underAny(path string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func writeWorkspaceDenied(w http.ResponseWriter, key *models.APIKey) {
+	writeScopeDenied(w, fmt.Sprintf("key %s is scoped to workspace %s", key.Prefix, key.Workspace))
+}
+
+func writeScopeDenied(w http.ResponseWriter, detail string) {
+	writeProblem(w, http.StatusForbidden, "key_scope_denied", detail)
+}
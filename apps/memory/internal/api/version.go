@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// APIVersion is the current version of the HTTP API. Its routes are served
+// under /v1; the same routes at the unversioned legacy paths are deprecated.
+const APIVersion = "1"
+
+// versionHeader lets a client pin the API version it was written against.
+const versionHeader = "X-Clive-API-Version"
+
+// supportedVersions are the versions a client may request in versionHeader.
+var supportedVersions = map[string]bool{
+	"1": true,
+}
+
+// versionPrefix returns the path prefix for an API version.
+func versionPrefix(version string) string {
+	return "/v" + version
+}
+
+// unversionedPath strips a /v<N> prefix so middleware that matches on
+// paths sees the same route under every version.
+func unversionedPath(path string) string {
+	if !strings.HasPrefix(path, "/v") {
+		return path
+	}
+	rest := path[2:]
+	i := strings.IndexByte(rest, '/')
+	if i <= 0 || !supportedVersions[rest[:i]] {
+		return path
+	}
+	return rest[i:]
+}
+
+// VersionNegotiation checks the version a client asked for against the one
+// the route serves, and reports the version served in versionHeader.
+// A request without the header gets the route's version.
+func VersionNegotiation(version string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if want := strings.TrimPrefix(r.Header.Get(versionHeader), "v"); want != "" && want != version {
+				if !supportedVersions[want] {
+					writeProblem(w, http.StatusBadRequest, "unsupported_api_version",
+						"API version "+want+" is not supported; this server speaks version "+APIVersion)
+					return
+				}
+				writeProblem(w, http.StatusBadRequest, "api_version_mismatch",
+					"requested API version "+want+" but "+r.URL.Path+" serves version "+version)
+				return
+			}
+			w.Header().Set(versionHeader, version)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Deprecated marks a legacy unversioned route. Responses carry a
+// Deprecation header and a Link to the same route under the current
+// version, so hook scripts keep working while they migrate.
+func Deprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		successor := versionPrefix(APIVersion) + r.URL.Path
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+		w.Header().Set("Warning", `299 - "unversioned API paths are deprecated; use `+successor+`"`)
+		next.ServeHTTP(w, r)
+	})
+}
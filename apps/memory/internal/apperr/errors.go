@@ -0,0 +1,91 @@
+// Package apperr defines typed domain errors shared by the service layer and
+// the HTTP API. Each error carries a Kind, which decides the HTTP status, and
+// a stable Code that clients can branch on.
+package apperr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Kind classifies a domain error.
+type Kind string
+
+const (
+	KindNotFound              Kind = "not_found"
+	KindConflict              Kind = "conflict"
+	KindValidationFailed      Kind = "validation_failed"
+	KindDependencyUnavailable Kind = "dependency_unavailable"
+	KindTimeout               Kind = "timeout"
+	KindCanceled              Kind = "canceled"
+	KindQuotaExceeded         Kind = "quota_exceeded"
+	KindInternal              Kind = "internal"
+)
+
+// Error is a domain error with a stable machine-readable code.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// NotFound reports a missing resource, e.g. code "memory_not_found".
+func NotFound(code, format string, args ...any) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Conflict reports a request that clashes with the current resource state.
+func Conflict(code, format string, args ...any) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// ValidationFailed reports invalid client input.
+func ValidationFailed(code, format string, args ...any) *Error {
+	return &Error{Kind: KindValidationFailed, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// QuotaExceeded reports a write refused because a workspace is full.
+func QuotaExceeded(code, format string, args ...any) *Error {
+	return &Error{Kind: KindQuotaExceeded, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// DependencyUnavailable wraps a failure of an external service such as
+// Ollama or Qdrant.
+func DependencyUnavailable(code string, err error, format string, args ...any) *Error {
+	return &Error{Kind: KindDependencyUnavailable, Code: code, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// From extracts the first typed error in err's chain. A context deadline or
+// cancellation anywhere in the chain takes precedence, so a dependency call
+// cut short by the request context is not blamed on the dependency. Untyped
+// errors are reported as internal.
+func From(err error) *Error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Kind: KindTimeout, Code: "request_timeout", Message: "request deadline exceeded", Err: err}
+	case errors.Is(err, context.Canceled):
+		return &Error{Kind: KindCanceled, Code: "request_canceled", Message: "request canceled", Err: err}
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return &Error{Kind: KindInternal, Code: string(KindInternal), Message: err.Error()}
+}
+
+// Is reports whether err's chain contains a typed error of the given kind.
+func Is(err error, kind Kind) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Kind == kind
+}
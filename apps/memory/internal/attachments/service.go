@@ -0,0 +1,223 @@
+package attachments
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/search"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+// allowedTypes are the image formats accepted as attachments, keyed by the
+// sniffed content type.
+var allowedTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Service stores image attachments on disk under dir/<memoryID>/<attachmentID>
+// with their metadata in SQLite.
+type Service struct {
+	dir         string
+	maxBytes    int64
+	store       *store.AttachmentStore
+	memoryStore *store.MemoryStore
+	embedder    *embedding.ImageEmbedder
+	logger      *slog.Logger
+}
+
+// NewService creates an attachment service. A nil embedder stores attachments
+// without image embeddings.
+func NewService(
+	dir string,
+	maxBytes int64,
+	attachmentStore *store.AttachmentStore,
+	memoryStore *store.MemoryStore,
+	embedder *embedding.ImageEmbedder,
+	logger *slog.Logger,
+) *Service {
+	return &Service{
+		dir:         dir,
+		maxBytes:    maxBytes,
+		store:       attachmentStore,
+		memoryStore: memoryStore,
+		embedder:    embedder,
+		logger:      logger,
+	}
+}
+
+// Add reads an image from r and attaches it to a memory. The content type is
+// sniffed from the bytes rather than trusted from the client.
+func (s *Service) Add(ctx context.Context, memoryID, filename string, r io.Reader) (*models.Attachment, error) {
+	mem, err := s.memoryStore.GetByID(ctx, memoryID)
+	if err != nil {
+		return nil, fmt.Errorf("get memory: %w", err)
+	}
+	if mem == nil {
+		return nil, apperr.NotFound("memory_not_found", "memory not found: %s", memoryID)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, s.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read attachment: %w", err)
+	}
+	if int64(len(data)) > s.maxBytes {
+		return nil, apperr.ValidationFailed("attachment_too_large", "attachment exceeds %d bytes", s.maxBytes)
+	}
+	if len(data) == 0 {
+		return nil, apperr.ValidationFailed("empty_attachment", "attachment is empty")
+	}
+
+	contentType := http.DetectContentType(data)
+	if !allowedTypes[contentType] {
+		return nil, apperr.ValidationFailed("unsupported_attachment_type",
+			"unsupported attachment type %q (png, jpeg, gif or webp only)", contentType)
+	}
+
+	sum := sha256.Sum256(data)
+	att := &models.Attachment{
+		ID:          uuid.New().String(),
+		MemoryID:    memoryID,
+		Filename:    cleanFilename(filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		SHA256:      hex.EncodeToString(sum[:]),
+		CreatedAt:   time.Now().Unix(),
+	}
+	if s.embedder != nil {
+		// Non-fatal: the attachment is still stored and retrievable
+		vec, err := s.embedder.Embed(ctx, data)
+		if err != nil {
+			s.logger.Warn("failed to embed attachment", "memory_id", memoryID, "error", err)
+		} else {
+			att.Embedding = search.Float32ToBytes(vec)
+			att.EmbeddingModel = s.embedder.Model()
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(s.dir, memoryID), 0o755); err != nil {
+		return nil, fmt.Errorf("create attachment dir: %w", err)
+	}
+	path := s.blobPath(att)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return nil, fmt.Errorf("write attachment: %w", err)
+	}
+	if err := s.store.Insert(ctx, att); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	return att, nil
+}
+
+// Get returns an attachment's metadata.
+func (s *Service) Get(ctx context.Context, id string) (*models.Attachment, error) {
+	att, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if att == nil {
+		return nil, apperr.NotFound("attachment_not_found", "attachment not found: %s", id)
+	}
+	return att, nil
+}
+
+// List returns a memory's attachments, oldest first.
+func (s *Service) List(ctx context.Context, memoryID string) ([]*models.Attachment, error) {
+	return s.store.ListByMemory(ctx, memoryID)
+}
+
+// Recent returns the most recently added attachments.
+func (s *Service) Recent(ctx context.Context, limit int) ([]*models.Attachment, error) {
+	return s.store.ListRecent(ctx, limit)
+}
+
+// Read returns an attachment's metadata and bytes.
+func (s *Service) Read(ctx context.Context, id string) (*models.Attachment, []byte, error) {
+	att, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(s.blobPath(att))
+	if os.IsNotExist(err) {
+		return nil, nil, apperr.NotFound("attachment_not_found", "attachment data missing: %s", id)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("read attachment: %w", err)
+	}
+	return att, data, nil
+}
+
+// Delete removes an attachment and its bytes.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	att, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.store.Delete(ctx, id); err != nil {
+		return err
+	}
+	if err := os.Remove(s.blobPath(att)); err != nil && !os.IsNotExist(err) {
+		s.logger.Warn("failed to remove attachment data", "id", id, "error", err)
+	}
+	// Drop the memory's directory once it is empty; fails harmlessly otherwise
+	os.Remove(filepath.Join(s.dir, att.MemoryID))
+	return nil
+}
+
+// Prune removes blob directories whose memory no longer has attachments,
+// e.g. after the memory was deleted or expired. It returns the number of
+// directories removed.
+func (s *Service) Prune(ctx context.Context) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read attachments dir: %w", err)
+	}
+
+	live, err := s.store.MemoryIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, e := range entries {
+		if !e.IsDir() || live[e.Name()] {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(s.dir, e.Name())); err != nil {
+			return removed, fmt.Errorf("remove orphaned attachments: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func (s *Service) blobPath(att *models.Attachment) string {
+	return filepath.Join(s.dir, att.MemoryID, att.ID)
+}
+
+// cleanFilename keeps only the base name of a client-supplied filename.
+func cleanFilename(name string) string {
+	if name == "" {
+		return ""
+	}
+	return filepath.Base(name)
+}
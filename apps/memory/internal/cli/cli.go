@@ -0,0 +1,184 @@
+// Package cli implements the clive-memory command line client, a thin
+// wrapper over the memory server HTTP API for quick terminal lookups.
+package cli
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// apiPrefix selects the memory server API version the commands are written
+// against.
+const apiPrefix = "/v1"
+
+// Env holds the process environment the commands run against.
+type Env struct {
+	Stdout         io.Writer
+	Stderr         io.Writer
+	ServerURL      string
+	APIKey         string
+	Namespace      string
+	Color          bool
+	TLS            *tls.Config // nil uses the default transport
+	SyncPassphrase string      // encrypts config bundles; never sent to the server
+
+	flags *flag.FlagSet // the last flag set a command created
+}
+
+type command struct {
+	summary string
+	args    []string // fixed subcommands, offered by shell completion
+	hidden  bool     // left out of usage and completion
+	run     func(env *Env, args []string) error
+}
+
+var commands = map[string]command{
+	"config":      {summary: "Push or pull your encrypted Clive config across machines", args: []string{"push", "pull", "list"}, run: runConfig},
+	"conventions": {summary: "Store the project's tooling conventions as memories", args: []string{"sync"}, run: runConventions},
+	"keys":        {summary: "Create, list or revoke scoped API keys", args: []string{"create", "list", "revoke"}, run: runKeys},
+	"search":      {summary: "Search memories and print ranked results", run: runSearch},
+	"skills":      {summary: "Sync skill hints, or preview a sync with --dry-run", args: []string{"sync"}, run: runSkills},
+	"vectors":     {summary: "Move vectors onto the collections the sharding strategy routes them to", args: []string{"rebalance"}, run: runVectors},
+}
+
+// Run dispatches args (without the program name) to a subcommand and
+// returns the process exit code.
+func Run(env *Env, args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		usage(env.Stderr)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(env.Stderr, "unknown command %q\n\n", args[0])
+		usage(env.Stderr)
+		return 2
+	}
+
+	if err := cmd.run(env, args[1:]); err != nil {
+		fmt.Fprintf(env.Stderr, "error: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: clive-memory <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, name := range commandNames() {
+		fmt.Fprintf(w, "  %-12s %s\n", name, commands[name].summary)
+	}
+}
+
+// EnvFromOS builds an Env from the standard memory server variables.
+// Color is enabled only when stdout is a terminal and NO_COLOR is unset.
+func EnvFromOS() *Env {
+	serverURL := os.Getenv("MEMORY_SERVER_URL")
+	if serverURL == "" {
+		serverURL = "http://localhost:8741"
+	}
+	color := false
+	if fi, err := os.Stdout.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		color = os.Getenv("NO_COLOR") == ""
+	}
+	return &Env{
+		Stdout:         os.Stdout,
+		Stderr:         os.Stderr,
+		ServerURL:      serverURL,
+		APIKey:         os.Getenv("MEMORY_API_KEY"),
+		Namespace:      os.Getenv("CLIVE_NAMESPACE"),
+		Color:          color,
+		SyncPassphrase: os.Getenv("CLIVE_SYNC_PASSPHRASE"),
+	}
+}
+
+// newFlagSet creates a command's flag set. Shell completion and the man
+// page run commands with -h and read their flags back from env.flags.
+func (env *Env) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	env.flags = fs
+	return fs
+}
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+func (env *Env) client() *http.Client {
+	if env.TLS == nil {
+		return httpClient
+	}
+	return &http.Client{
+		Timeout:   httpClient.Timeout,
+		Transport: &http.Transport{TLSClientConfig: env.TLS},
+	}
+}
+
+// post sends a JSON request to the memory server and decodes the response
+// into out. Problem+json errors are reported as "code: detail".
+func (env *Env) post(path string, body, out any) error {
+	return env.do(http.MethodPost, path, body, out)
+}
+
+// do sends a request with an optional JSON body and decodes the response
+// into out, if set.
+func (env *Env) do(method, path string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, env.ServerURL+apiPrefix+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if env.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+env.APIKey)
+	}
+	if env.Namespace != "" {
+		req.Header.Set("X-Clive-Namespace", env.Namespace)
+	}
+
+	resp, err := env.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("memory server unreachable at %s: %w", env.ServerURL, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		var p struct {
+			Code   string `json:"code"`
+			Detail string `json:"detail"`
+		}
+		if json.Unmarshal(respBody, &p) == nil && p.Code != "" {
+			return fmt.Errorf("%s: %s", p.Code, p.Detail)
+		}
+		return fmt.Errorf("server returned %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
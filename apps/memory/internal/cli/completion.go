@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// completeCommand is the hidden command the completion scripts call back
+// into. Candidates come from the commands map and each command's flag set,
+// so completion never drifts from the CLI itself.
+const completeCommand = "__complete"
+
+func init() {
+	commands["completion"] = command{
+		summary: "Print a bash, zsh or fish completion script",
+		args:    []string{"bash", "zsh", "fish"},
+		run:     runCompletion,
+	}
+	commands["man"] = command{summary: "Print the clive-memory(1) man page", run: runMan}
+	commands[completeCommand] = command{hidden: true, run: runComplete}
+}
+
+const bashCompletion = `# bash completion for clive-memory
+# Load with: source <(clive-memory completion bash)
+_clive_memory() {
+	local IFS=$'\n'
+	COMPREPLY=($(clive-memory __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+	if [ ${#COMPREPLY[@]} -eq 0 ]; then
+		compopt -o default
+	fi
+}
+complete -F _clive_memory clive-memory
+`
+
+const zshCompletion = `#compdef clive-memory
+# zsh completion for clive-memory
+# Load with: source <(clive-memory completion zsh)
+_clive_memory() {
+	local -a candidates
+	candidates=("${(@f)$(clive-memory __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+	if [[ -n ${candidates[1]} ]]; then
+		compadd -a candidates
+	else
+		_files
+	fi
+}
+compdef _clive_memory clive-memory
+`
+
+const fishCompletion = `# fish completion for clive-memory
+# Load with: clive-memory completion fish | source
+function __clive_memory_complete
+	set -l tokens (commandline -opc)
+	set -e tokens[1]
+	clive-memory __complete $tokens (commandline -ct) 2>/dev/null
+end
+complete -c clive-memory -f -a '(__clive_memory_complete)'
+`
+
+func runCompletion(env *Env, args []string) error {
+	fs := env.newFlagSet("completion")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "usage: clive-memory completion bash|zsh|fish")
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one of bash, zsh or fish")
+	}
+
+	scripts := map[string]string{"bash": bashCompletion, "zsh": zshCompletion, "fish": fishCompletion}
+	script, ok := scripts[positional[0]]
+	if !ok {
+		fs.Usage()
+		return fmt.Errorf("unsupported shell %q", positional[0])
+	}
+	fmt.Fprint(env.Stdout, script)
+	return nil
+}
+
+// runComplete prints the candidates for the last of args, one per line.
+// It is best effort: a server that cannot be reached just yields no
+// candidates, and the shell falls back to file names.
+func runComplete(env *Env, args []string) error {
+	for _, c := range completions(env, args) {
+		fmt.Fprintln(env.Stdout, c)
+	}
+	return nil
+}
+
+// completions returns the candidates for the last word of args, the words
+// typed after the program name.
+func completions(env *Env, args []string) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	cur := args[len(args)-1]
+	if len(args) == 1 {
+		return withPrefix(commandNames(), cur)
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok || cmd.hidden {
+		return nil
+	}
+	fs := flagsFor(args[0])
+
+	if prev := args[len(args)-2]; strings.HasPrefix(prev, "-") && !strings.Contains(prev, "=") {
+		if f := fs.Lookup(strings.TrimLeft(prev, "-")); f != nil && !isBoolFlag(f) {
+			return flagValues(env, args[0], f.Name, cur)
+		}
+	}
+	if strings.HasPrefix(cur, "-") {
+		var names []string
+		fs.VisitAll(func(f *flag.Flag) {
+			names = append(names, "--"+f.Name)
+		})
+		return withPrefix(names, cur)
+	}
+	for _, w := range args[1 : len(args)-1] {
+		for _, a := range cmd.args {
+			if w == a {
+				return nil // subcommand already given
+			}
+		}
+	}
+	return withPrefix(cmd.args, cur)
+}
+
+// flagsFor returns a command's flag set by running it with -h against a
+// throwaway Env. Every command defines its flags before doing any work.
+func flagsFor(name string) *flag.FlagSet {
+	probe := &Env{Stdout: io.Discard, Stderr: io.Discard}
+	_ = commands[name].run(probe, []string{"-h"})
+	if probe.flags == nil {
+		return flag.NewFlagSet(name, flag.ContinueOnError)
+	}
+	return probe.flags
+}
+
+// flagValues completes the value of a flag. Workspaces and config bundle
+// names are looked up on the server; other flags complete to files.
+func flagValues(env *Env, cmd, flagName, cur string) []string {
+	switch {
+	case cmd == "search" && flagName == "type":
+		// A comma-separated list: complete the element being typed.
+		head := cur[:strings.LastIndex(cur, ",")+1]
+		var types []string
+		for t := range models.ValidMemoryTypes {
+			types = append(types, head+strings.ToLower(strings.ReplaceAll(string(t), "_", "-")))
+		}
+		sort.Strings(types)
+		return withPrefix(types, cur)
+	case flagName == "workspace":
+		var workspaces []models.Workspace
+		if err := env.do(http.MethodGet, "/workspaces", nil, &workspaces); err != nil {
+			return nil
+		}
+		var paths []string
+		for _, w := range workspaces {
+			if w.Path != "" {
+				paths = append(paths, w.Path)
+			}
+		}
+		sort.Strings(paths)
+		return withPrefix(paths, cur)
+	case cmd == "config" && flagName == "name":
+		var resp struct {
+			Blobs []models.SyncBlob `json:"blobs"`
+		}
+		if err := env.do(http.MethodGet, "/sync/blobs", nil, &resp); err != nil {
+			return nil
+		}
+		var names []string
+		for _, b := range resp.Blobs {
+			names = append(names, b.Name)
+		}
+		return withPrefix(names, cur)
+	}
+	return nil
+}
+
+// commandNames lists the commands shown in usage, sorted.
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name, cmd := range commands {
+		if !cmd.hidden {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func withPrefix(candidates []string, prefix string) []string {
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	bf, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
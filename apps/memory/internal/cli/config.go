@@ -0,0 +1,257 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// sealMagic prefixes every sealed bundle and is bound into the AEAD, so a
+// blob from another format or version fails to open rather than decoding
+// into garbage.
+var sealMagic = []byte("clive-sync\x01")
+
+const (
+	saltSize  = 16
+	kdfRounds = 600_000
+)
+
+// configBundle is the plaintext pushed to the server: every file under the
+// config directory keyed by its slash-separated relative path.
+type configBundle struct {
+	Files map[string]bundleFile `json:"files"`
+}
+
+type bundleFile struct {
+	Mode fs.FileMode `json:"mode"`
+	Data []byte      `json:"data"`
+}
+
+func runConfig(env *Env, args []string) error {
+	home, _ := os.UserHomeDir()
+	fset := env.newFlagSet("config")
+	dir := fset.String("dir", filepath.Join(home, ".clive"), "config directory to push from or pull into")
+	name := fset.String("name", "default", "name of the synced bundle, e.g. one per machine profile")
+	dryRun := fset.Bool("dry-run", false, "pull: list the files that would be written")
+	fset.Usage = func() {
+		fmt.Fprintln(env.Stderr, "usage: clive-memory config push|pull|list [--dir path] [--name name] [--dry-run]")
+		fmt.Fprintln(env.Stderr, "bundles are encrypted with CLIVE_SYNC_PASSPHRASE before they leave this machine")
+		fset.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fset, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fset.Usage()
+		return fmt.Errorf("expected one of push, pull or list")
+	}
+
+	switch positional[0] {
+	case "push":
+		return configPush(env, *dir, *name)
+	case "pull":
+		return configPull(env, *dir, *name, *dryRun)
+	case "list":
+		return configList(env)
+	default:
+		fset.Usage()
+		return fmt.Errorf("unknown config subcommand %q", positional[0])
+	}
+}
+
+func configPush(env *Env, dir, name string) error {
+	if env.SyncPassphrase == "" {
+		return fmt.Errorf("CLIVE_SYNC_PASSPHRASE must be set to encrypt the bundle")
+	}
+	bundle, err := readBundle(dir)
+	if err != nil {
+		return err
+	}
+	if len(bundle.Files) == 0 {
+		return fmt.Errorf("no files to push in %s", dir)
+	}
+	plain, err := json.Marshal(bundle)
+	if err != nil {
+		return fmt.Errorf("encode bundle: %w", err)
+	}
+	sealed, err := seal(env.SyncPassphrase, plain)
+	if err != nil {
+		return err
+	}
+
+	var blob models.SyncBlob
+	if err := env.do(http.MethodPut, "/sync/blobs/"+name, models.PutSyncBlobRequest{Data: sealed}, &blob); err != nil {
+		return err
+	}
+	fmt.Fprintf(env.Stdout, "pushed %d files from %s as %s v%d (%d bytes encrypted)\n",
+		len(bundle.Files), dir, blob.Name, blob.Version, blob.Size)
+	return nil
+}
+
+func configPull(env *Env, dir, name string, dryRun bool) error {
+	if env.SyncPassphrase == "" {
+		return fmt.Errorf("CLIVE_SYNC_PASSPHRASE must be set to decrypt the bundle")
+	}
+	var blob models.SyncBlob
+	if err := env.do(http.MethodGet, "/sync/blobs/"+name, nil, &blob); err != nil {
+		return err
+	}
+	plain, err := unseal(env.SyncPassphrase, blob.Data)
+	if err != nil {
+		return err
+	}
+	var bundle configBundle
+	if err := json.Unmarshal(plain, &bundle); err != nil {
+		return fmt.Errorf("decode bundle: %w", err)
+	}
+
+	paths := make([]string, 0, len(bundle.Files))
+	for p := range bundle.Files {
+		if !filepath.IsLocal(filepath.FromSlash(p)) {
+			return fmt.Errorf("bundle contains a path outside the config directory: %q", p)
+		}
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		f := bundle.Files[p]
+		target := filepath.Join(dir, filepath.FromSlash(p))
+		if dryRun {
+			fmt.Fprintf(env.Stdout, "would write %s\n", target)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+			return fmt.Errorf("create %s: %w", filepath.Dir(target), err)
+		}
+		if err := os.WriteFile(target, f.Data, f.Mode.Perm()); err != nil {
+			return fmt.Errorf("write %s: %w", target, err)
+		}
+		fmt.Fprintf(env.Stdout, "wrote %s\n", target)
+	}
+	fmt.Fprintf(env.Stdout, "pulled %s v%d: %d files\n", blob.Name, blob.Version, len(paths))
+	return nil
+}
+
+func configList(env *Env) error {
+	var resp struct {
+		Blobs []models.SyncBlob `json:"blobs"`
+	}
+	if err := env.do(http.MethodGet, "/sync/blobs", nil, &resp); err != nil {
+		return err
+	}
+	if len(resp.Blobs) == 0 {
+		fmt.Fprintln(env.Stdout, "no synced config bundles")
+		return nil
+	}
+	for _, b := range resp.Blobs {
+		fmt.Fprintf(env.Stdout, "%-20s v%-4d %7d bytes  %s\n", b.Name, b.Version, b.Size,
+			time.Unix(b.UpdatedAt, 0).Format(time.DateTime))
+	}
+	return nil
+}
+
+// readBundle collects the regular files under dir. Symlinks are skipped so
+// a push never follows them out of the directory.
+func readBundle(dir string) (*configBundle, error) {
+	bundle := &configBundle{Files: map[string]bundleFile{}}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		bundle.Files[filepath.ToSlash(rel)] = bundleFile{Mode: info.Mode().Perm(), Data: data}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read config dir: %w", err)
+	}
+	return bundle, nil
+}
+
+// seal encrypts plain with AES-256-GCM under a key derived from passphrase
+// with PBKDF2-SHA256 and a random salt: magic | salt | nonce | ciphertext.
+func seal(passphrase string, plain []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+	aead, err := syncCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	out := append(append(append([]byte{}, sealMagic...), salt...), nonce...)
+	return aead.Seal(out, nonce, plain, sealMagic), nil
+}
+
+// unseal reverses seal. A wrong passphrase and a tampered blob both fail
+// authentication and are reported the same way.
+func unseal(passphrase string, sealed []byte) ([]byte, error) {
+	if !bytes.HasPrefix(sealed, sealMagic) {
+		return nil, errors.New("not a clive config bundle")
+	}
+	rest := sealed[len(sealMagic):]
+	if len(rest) < saltSize {
+		return nil, errors.New("config bundle is truncated")
+	}
+	aead, err := syncCipher(passphrase, rest[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	rest = rest[saltSize:]
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("config bundle is truncated")
+	}
+	plain, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], sealMagic)
+	if err != nil {
+		return nil, errors.New("cannot decrypt config bundle: wrong CLIVE_SYNC_PASSPHRASE or corrupted data")
+	}
+	return plain, nil
+}
+
+func syncCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, kdfRounds, 32)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("init cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/conventions"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+func runConventions(env *Env, args []string) error {
+	fs := env.newFlagSet("conventions")
+	workspace := fs.String("workspace", ".", "project root to scan")
+	dryRun := fs.Bool("dry-run", false, "print the conventions found without storing them")
+	asJSON := fs.Bool("json", false, "print the conventions as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "usage: clive-memory conventions sync [--workspace .] [--dry-run] [--json]")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || positional[0] != "sync" {
+		fs.Usage()
+		return fmt.Errorf("expected the sync subcommand")
+	}
+
+	root, err := filepath.Abs(*workspace)
+	if err != nil {
+		return fmt.Errorf("resolve workspace: %w", err)
+	}
+	found, err := conventions.Scan(root)
+	if err != nil {
+		return err
+	}
+
+	paint := func(code, s string) string {
+		if !env.Color {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	if *dryRun {
+		if *asJSON {
+			enc := json.NewEncoder(env.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(found)
+		}
+		for _, c := range found {
+			fmt.Fprintf(env.Stdout, "%s %s\n", paint(typeColors[c.MemoryType], "["+string(c.MemoryType)+"]"), c.Content)
+		}
+		fmt.Fprintf(env.Stdout, "%d conventions found in %s (dry run, nothing stored)\n", len(found), root)
+		return nil
+	}
+
+	stored, known := 0, 0
+	for _, c := range found {
+		req := models.StoreRequest{
+			Workspace:    root,
+			Content:      fmt.Sprintf("[Convention: %s] %s", c.Area, c.Content),
+			MemoryType:   c.MemoryType,
+			Tier:         models.TierLong,
+			Confidence:   0.9,
+			Tags:         []string{"convention", "convention:" + c.Area},
+			Source:       "conventions",
+			RelatedFiles: c.Files,
+		}
+		var resp models.StoreResponse
+		if err := env.post("/memories", req, &resp); err != nil {
+			return fmt.Errorf("store convention %q: %w", c.Content, err)
+		}
+
+		mark := paint(ansiGreen, "+")
+		if resp.Deduplicated {
+			mark = paint(ansiDim, "=")
+			known++
+		} else {
+			stored++
+		}
+		if !*asJSON {
+			fmt.Fprintf(env.Stdout, "%s %s %s\n", mark, paint(typeColors[c.MemoryType], "["+string(c.MemoryType)+"]"), c.Content)
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(env.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]int{"found": len(found), "stored": stored, "unchanged": known})
+	}
+	fmt.Fprintf(env.Stdout, "%d conventions: %d stored, %d already known\n", len(found), stored, known)
+	return nil
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+func runKeys(env *Env, args []string) error {
+	fs := env.newFlagSet("keys")
+	name := fs.String("name", "", "create: a label for the key, such as who or what it is for")
+	namespace := fs.String("scope-namespace", "", "create: limit the key to one namespace")
+	workspace := fs.String("scope-workspace", "", "create: limit the key to one workspace path or remote in --scope-namespace")
+	readOnly := fs.Bool("read-only", false, "create: only allow requests that read")
+	asJSON := fs.Bool("json", false, "print the raw JSON response")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "usage: clive-memory keys create --name name [--scope-namespace ns] [--scope-workspace path] [--read-only]")
+		fmt.Fprintln(env.Stderr, "       clive-memory keys list")
+		fmt.Fprintln(env.Stderr, "       clive-memory keys revoke <id>")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		fs.Usage()
+		return fmt.Errorf("expected one of create, list or revoke")
+	}
+
+	var raw json.RawMessage
+	switch positional[0] {
+	case "create":
+		access := models.KeyAccessReadWrite
+		if *readOnly {
+			access = models.KeyAccessRead
+		}
+		req := models.CreateAPIKeyRequest{Name: *name, Namespace: *namespace, Workspace: *workspace, Access: access}
+		if req.Name == "" {
+			fs.Usage()
+			return fmt.Errorf("--name is required")
+		}
+		err = env.post("/keys", req, &raw)
+	case "list":
+		err = env.do(http.MethodGet, "/keys", nil, &raw)
+	case "revoke":
+		if len(positional) != 2 {
+			fs.Usage()
+			return fmt.Errorf("expected the id of the key to revoke")
+		}
+		err = env.do(http.MethodDelete, "/keys/"+url.PathEscape(positional[1]), nil, &raw)
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown keys subcommand %q", positional[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		var out bytes.Buffer
+		json.Indent(&out, raw, "", "  ")
+		out.WriteByte('\n')
+		_, err := out.WriteTo(env.Stdout)
+		return err
+	}
+
+	switch positional[0] {
+	case "create":
+		var created models.CreateAPIKeyResponse
+		if err := json.Unmarshal(raw, &created); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+		printKey(env, &created.APIKey)
+		fmt.Fprintln(env.Stdout, created.Token)
+		fmt.Fprintln(env.Stderr, "store the token now: it is not kept and cannot be shown again")
+	case "list":
+		var resp struct {
+			Keys []models.APIKey `json:"keys"`
+		}
+		if err := json.Unmarshal(raw, &resp); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+		if len(resp.Keys) == 0 {
+			fmt.Fprintln(env.Stdout, "no scoped api keys")
+		}
+		for i := range resp.Keys {
+			printKey(env, &resp.Keys[i])
+		}
+	case "revoke":
+		var key models.APIKey
+		if err := json.Unmarshal(raw, &key); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+		printKey(env, &key)
+	}
+	return nil
+}
+
+func printKey(env *Env, key *models.APIKey) {
+	paint := func(code, s string) string {
+		if !env.Color {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	scope := "all namespaces"
+	switch {
+	case key.Workspace != "":
+		scope = key.Namespace + ":" + key.Workspace
+	case key.Namespace != "":
+		scope = key.Namespace
+	}
+	status := "created " + time.Unix(key.CreatedAt, 0).Format(time.DateTime)
+	if key.RevokedAt != nil {
+		status = paint(ansiRed, "revoked "+time.Unix(*key.RevokedAt, 0).Format(time.DateTime))
+	}
+	fmt.Fprintf(env.Stdout, "%s  %s…  %-10s %-20s %s  %s\n",
+		key.ID, key.Prefix, key.Access, key.Name, scope, status)
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// manEnv documents the environment variables the CLI reads.
+var manEnv = []struct{ name, desc string }{
+	{"MEMORY_SERVER_URL", "Memory server base URL (default http://localhost:8741)."},
+	{"MEMORY_API_KEY", "Bearer token sent with every request."},
+	{"CLIVE_NAMESPACE", "Namespace to read and write memories in."},
+	{"CLIVE_SYNC_PASSPHRASE", "Passphrase that encrypts config bundles. It never leaves this machine."},
+	{"MEMORY_TLS_CA_FILE", "CA bundle used to verify the server certificate."},
+	{"MEMORY_TLS_CLIENT_CERT", "Client certificate for mutual TLS."},
+	{"MEMORY_TLS_CLIENT_KEY", "Key for MEMORY_TLS_CLIENT_CERT."},
+	{"NO_COLOR", "Disables colored output."},
+}
+
+func runMan(env *Env, args []string) error {
+	fs := env.newFlagSet("man")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "usage: clive-memory man > clive-memory.1")
+	}
+	if _, err := parseInterspersed(fs, args); err != nil {
+		return err
+	}
+	writeManPage(env.Stdout)
+	return nil
+}
+
+// writeManPage renders clive-memory(1) in roff from the commands map and
+// each command's flag set.
+func writeManPage(w io.Writer) {
+	fmt.Fprintln(w, `.TH CLIVE-MEMORY 1 "" "clive-memory" "User Commands"`)
+	fmt.Fprintln(w, ".SH NAME")
+	fmt.Fprintln(w, `clive-memory \- command line client for the Clive memory server`)
+	fmt.Fprintln(w, ".SH SYNOPSIS")
+	fmt.Fprintln(w, `.B clive-memory`)
+	fmt.Fprintln(w, `\fIcommand\fR [\fIflags\fR] [\fIargs\fR]`)
+	fmt.Fprintln(w, ".SH DESCRIPTION")
+	fmt.Fprintln(w, "A thin wrapper over the memory server HTTP API for quick terminal lookups,")
+	fmt.Fprintln(w, "skill and convention syncs, and encrypted config sync across machines.")
+	fmt.Fprintln(w, "Flags may appear before or after positional arguments.")
+
+	fmt.Fprintln(w, ".SH COMMANDS")
+	home, _ := os.UserHomeDir()
+	for _, name := range commandNames() {
+		cmd := commands[name]
+		fmt.Fprintf(w, ".SS %s", roffEscape(name))
+		if len(cmd.args) > 0 {
+			fmt.Fprintf(w, " %s", roffEscape(strings.Join(cmd.args, "|")))
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, roffEscape(cmd.summary)+".")
+		flagsFor(name).VisitAll(func(f *flag.Flag) {
+			valueName, usage := flag.UnquoteUsage(f)
+			fmt.Fprintln(w, ".TP")
+			fmt.Fprintf(w, `\fB\-\-%s\fR`, roffEscape(f.Name))
+			if valueName != "" {
+				fmt.Fprintf(w, ` \fI%s\fR`, roffEscape(valueName))
+			}
+			fmt.Fprintln(w)
+			if def := f.DefValue; def != "" && def != "false" && def != "[]" {
+				if home != "" && strings.HasPrefix(def, home) {
+					def = "~" + def[len(home):] // not the generating machine's home
+				}
+				usage += " (default " + def + ")"
+			}
+			fmt.Fprintln(w, roffEscape(usage))
+		})
+	}
+
+	fmt.Fprintln(w, ".SH ENVIRONMENT")
+	for _, e := range manEnv {
+		fmt.Fprintln(w, ".TP")
+		fmt.Fprintf(w, ".B %s\n", e.name)
+		fmt.Fprintln(w, roffEscape(e.desc))
+	}
+
+	fmt.Fprintln(w, ".SH EXIT STATUS")
+	fmt.Fprintln(w, "0 on success, 1 when a command fails and 2 on a usage error.")
+	fmt.Fprintln(w, ".SH SEE ALSO")
+	fmt.Fprintln(w, `Shell completion: \fBclive-memory completion bash|zsh|fish\fR.`)
+}
+
+// roffEscape escapes backslashes and hyphens, and keeps a line from being
+// read as a roff request.
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
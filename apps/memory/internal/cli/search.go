@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+const (
+	ansiReset  = "\033[0m"
+	ansiBold   = "\033[1m"
+	ansiDim    = "\033[2m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+	ansiBlue   = "\033[34m"
+	ansiPurple = "\033[35m"
+	ansiCyan   = "\033[36m"
+)
+
+// typeColors gives each memory type a stable color in terminal output.
+var typeColors = map[models.MemoryType]string{
+	models.MemoryTypeGotcha:          ansiRed,
+	models.MemoryTypeFailure:         ansiRed,
+	models.MemoryTypeWorkingSolution: ansiGreen,
+	models.MemoryTypeDecision:        ansiBlue,
+	models.MemoryTypePattern:         ansiPurple,
+	models.MemoryTypePreference:      ansiYellow,
+	models.MemoryTypeContext:         ansiCyan,
+}
+
+// previewLen is the maximum content length printed per result.
+const previewLen = 240
+
+func runSearch(env *Env, args []string) error {
+	fs := env.newFlagSet("search")
+	workspace := fs.String("workspace", ".", "workspace path (\"\" for global only)")
+	types := fs.String("type", "", "comma-separated memory types, e.g. gotcha,decision")
+	limit := fs.Int("limit", 10, "maximum results")
+	minScore := fs.Float64("min-score", 0.3, "minimum relevance score")
+	global := fs.Bool("global", true, "include cross-project global memories")
+	asJSON := fs.Bool("json", false, "print the raw JSON response")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "usage: clive-memory search \"<query>\" [--workspace .] [--type gotcha] [--json]")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		fs.Usage()
+		return fmt.Errorf("query is required")
+	}
+
+	req := models.SearchRequest{
+		Query:         strings.Join(positional, " "),
+		MaxResults:    *limit,
+		MinScore:      *minScore,
+		IncludeGlobal: *global,
+		SearchMode:    models.SearchModeHybrid,
+	}
+	if *workspace != "" {
+		abs, err := filepath.Abs(*workspace)
+		if err != nil {
+			return fmt.Errorf("resolve workspace: %w", err)
+		}
+		req.Workspace = abs
+	}
+	for _, t := range strings.Split(*types, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		mt := models.MemoryType(strings.ToUpper(strings.ReplaceAll(t, "-", "_")))
+		if !mt.IsValid() {
+			return fmt.Errorf("unknown memory type %q", t)
+		}
+		req.MemoryTypes = append(req.MemoryTypes, mt)
+	}
+
+	var resp models.SearchResponse
+	if err := env.post("/memories/search", req, &resp); err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(env.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(resp)
+	}
+
+	printResults(env, resp.Results)
+	return nil
+}
+
+func printResults(env *Env, results []models.SearchResult) {
+	paint := func(code, s string) string {
+		if !env.Color || code == "" {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintln(env.Stdout, paint(ansiDim, "no memories found"))
+		return
+	}
+
+	for i, r := range results {
+		id := r.ID
+		if len(id) > 8 {
+			id = id[:8]
+		}
+		fmt.Fprintf(env.Stdout, "%s %s %s %s\n",
+			paint(ansiBold, fmt.Sprintf("%2d.", i+1)),
+			paint(typeColors[r.MemoryType], "["+string(r.MemoryType)+"]"),
+			paint(ansiBold, fmt.Sprintf("%.2f", r.Score)),
+			paint(ansiDim, string(r.Tier)+" "+id),
+		)
+		if r.Snippet != "" {
+			fmt.Fprintf(env.Stdout, "    %s\n", highlight(r.Snippet, env.Color))
+		} else {
+			fmt.Fprintf(env.Stdout, "    %s\n", preview(r.Content))
+		}
+		if len(r.Tags) > 0 {
+			fmt.Fprintf(env.Stdout, "    %s\n", paint(ansiDim, "tags: "+strings.Join(r.Tags, ", ")))
+		}
+	}
+}
+
+// preview collapses whitespace and truncates content to previewLen runes.
+func preview(content string) string {
+	s := strings.Join(strings.Fields(content), " ")
+	if r := []rune(s); len(r) > previewLen {
+		return string(r[:previewLen]) + "..."
+	}
+	return s
+}
+
+// highlight renders a search snippet, turning match markers into bold
+// yellow text, or dropping them when color is off.
+func highlight(snippet string, color bool) string {
+	start, end := "", ""
+	if color {
+		start, end = ansiBold+ansiYellow, ansiReset
+	}
+	s := strings.Join(strings.Fields(snippet), " ")
+	return strings.NewReplacer("<mark>", start, "</mark>", end).Replace(s)
+}
+
+// parseInterspersed parses flags that may appear before or after positional
+// arguments, returning the positional arguments in order.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
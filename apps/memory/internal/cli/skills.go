@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// syncResult mirrors the parts of the POST /skills/sync response the
+// command prints, without linking the server-side skills package.
+type syncResult struct {
+	DryRun  bool `json:"dryRun"`
+	Found   int  `json:"found"`
+	Changes []struct {
+		Name   string `json:"name"`
+		Action string `json:"action"`
+		Reason string `json:"reason,omitempty"`
+	} `json:"changes"`
+}
+
+// actionOrder lists sync actions in summary order, each with the mark
+// that prefixes its lines so the diff reads at a glance.
+var actionOrder = []struct{ action, mark, color string }{
+	{"added", "+", ansiGreen},
+	{"updated", "~", ansiYellow},
+	{"unchanged", "=", ansiDim},
+	{"removed", "-", ansiRed},
+	{"failed", "!", ansiRed},
+}
+
+// dirList collects a repeatable --dir flag.
+type dirList []string
+
+func (d *dirList) String() string { return strings.Join(*d, ",") }
+
+func (d *dirList) Set(v string) error {
+	abs, err := filepath.Abs(v)
+	if err != nil {
+		return fmt.Errorf("resolve dir: %w", err)
+	}
+	*d = append(*d, abs)
+	return nil
+}
+
+func runSkills(env *Env, args []string) error {
+	fs := env.newFlagSet("skills")
+	dryRun := fs.Bool("dry-run", false, "report what a sync would change without writing")
+	all := fs.Bool("all", false, "also list unchanged skills")
+	asJSON := fs.Bool("json", false, "print the raw JSON response")
+	var dirs dirList
+	fs.Var(&dirs, "dir", "skill directory to scan instead of the server's (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "usage: clive-memory skills sync [--dry-run] [--dir path]... [--all] [--json]")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || positional[0] != "sync" {
+		fs.Usage()
+		return fmt.Errorf("expected the sync subcommand")
+	}
+
+	path := "/skills/sync"
+	if *dryRun {
+		path += "?dry_run=true"
+	}
+	var raw json.RawMessage
+	if err := env.post(path, map[string]any{"dirs": []string(dirs)}, &raw); err != nil {
+		return err
+	}
+
+	if *asJSON {
+		var out bytes.Buffer
+		json.Indent(&out, raw, "", "  ")
+		out.WriteByte('\n')
+		_, err := out.WriteTo(env.Stdout)
+		return err
+	}
+
+	var result syncResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	printSyncResult(env, &result, *all)
+	return nil
+}
+
+func printSyncResult(env *Env, result *syncResult, all bool) {
+	paint := func(code, s string) string {
+		if !env.Color || code == "" {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	if result.DryRun {
+		fmt.Fprintln(env.Stdout, paint(ansiBold, "dry run: nothing was written"))
+	}
+
+	width := 0
+	for _, c := range result.Changes {
+		width = max(width, len(c.Name))
+	}
+	counts := map[string]int{}
+	for _, a := range actionOrder {
+		for _, c := range result.Changes {
+			if c.Action != a.action {
+				continue
+			}
+			counts[a.action]++
+			if a.action == "unchanged" && !all {
+				continue
+			}
+			line := fmt.Sprintf("%s %-*s %-9s %s", a.mark, width, c.Name, c.Action, c.Reason)
+			fmt.Fprintln(env.Stdout, paint(a.color, strings.TrimRight(line, " ")))
+		}
+	}
+
+	summary := make([]string, len(actionOrder))
+	for i, a := range actionOrder {
+		summary[i] = fmt.Sprintf("%d %s", counts[a.action], a.action)
+	}
+	fmt.Fprintf(env.Stdout, "%d found: %s\n", result.Found, strings.Join(summary, ", "))
+}
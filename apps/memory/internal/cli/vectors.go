@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+func runVectors(env *Env, args []string) error {
+	fs := env.newFlagSet("vectors")
+	dryRun := fs.Bool("dry-run", false, "report the moves a rebalance would make without moving anything")
+	asJSON := fs.Bool("json", false, "print the raw JSON response")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "usage: clive-memory vectors rebalance [--dry-run] [--json]")
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || positional[0] != "rebalance" {
+		fs.Usage()
+		return fmt.Errorf("expected the rebalance subcommand")
+	}
+
+	path := "/vectors/rebalance"
+	if *dryRun {
+		path += "?dry_run=true"
+	}
+	var raw json.RawMessage
+	if err := env.post(path, nil, &raw); err != nil {
+		return err
+	}
+
+	if *asJSON {
+		var out bytes.Buffer
+		json.Indent(&out, raw, "", "  ")
+		out.WriteByte('\n')
+		_, err := out.WriteTo(env.Stdout)
+		return err
+	}
+
+	var report models.VectorRebalanceReport
+	if err := json.Unmarshal(raw, &report); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	printRebalanceReport(env, &report)
+	return nil
+}
+
+func printRebalanceReport(env *Env, report *models.VectorRebalanceReport) {
+	paint := func(code, s string) string {
+		if !env.Color {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	if report.DryRun {
+		fmt.Fprintln(env.Stdout, paint(ansiBold, "dry run: nothing was moved"))
+	}
+	for _, m := range report.Moves {
+		fmt.Fprintf(env.Stdout, "%s -> %s  %d points\n", m.From, m.To, m.Points)
+	}
+	for _, name := range report.Dropped {
+		fmt.Fprintln(env.Stdout, paint(ansiRed, "- "+name))
+	}
+	fmt.Fprintf(env.Stdout, "%s sharding: %d points, %d moved, %d skipped, %d collections dropped\n",
+		report.Strategy, report.Points, report.Moved, report.Skipped, len(report.Dropped))
+}
@@ -6,6 +6,11 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/schedule"
+	"github.com/iammorganparry/clive/apps/memory/internal/tokens"
+	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
 )
 
 type Config struct {
@@ -16,6 +21,21 @@ type Config struct {
 	EmbeddingModel string
 	EmbeddingDim   int
 	LogLevel       string
+	// Embedding backend: "ollama" (OllamaBaseURL), "openai" for any
+	// OpenAI-compatible embeddings API, "voyage", or "tei" for a Hugging Face
+	// text-embeddings-inference server, which must be run separately and
+	// reachable at EmbeddingBaseURL. The model and dimension default per
+	// backend.
+	EmbeddingBackend string
+	EmbeddingBaseURL string
+	EmbeddingAPIKey  string
+	// Qdrant sharding: "workspace" (a collection each), "namespace" (one
+	// shared per namespace) or "size" (split past QdrantShardMaxPoints)
+	QdrantSharding       string
+	QdrantShardMaxPoints int
+	// Embedding warm-up
+	WarmupEnabled     bool
+	KeepaliveInterval int // seconds; 0 disables keepalive embeds
 	// Search tuning
 	VectorWeight      float64
 	BM25Weight        float64
@@ -23,46 +43,201 @@ type Config struct {
 	DedupThreshold    float64
 	DefaultMinScore   float64
 	DefaultMaxResults int
+	// Re-ranking: RERANK_MODEL enables it, scored by Ollama (OllamaBaseURL)
+	// or by a "remote" Cohere-style /rerank API at RerankBaseURL
+	RerankModel   string
+	RerankBackend string
+	RerankBaseURL string
+	RerankAPIKey  string
+	RerankTopK    int
 	// Lifecycle
 	ShortTermTTLHours   int
 	PromotionAccessMin  int
 	PromotionConfidence float64
+	CompactDemote       bool // demote faded long-term memories back to short-term
+	CompactConsolidate  bool // supersede short-term near-duplicates
 	// Skills
 	SkillDirs     []string
 	SkillAutoSync bool
 	// Session summarization
 	SummaryModel   string
 	SummaryEnabled bool
+	// Summarizer backend: "ollama" (OllamaBaseURL) or "openai" for any
+	// OpenAI-compatible chat API, with per-feature models and daily token caps
+	SummaryProvider  string
+	SummaryBaseURL   string
+	SummaryAPIKey    string
+	SummaryModels    map[string]string
+	SummaryTokenCaps map[string]int
 	// MCP adapter
 	MemoryServerURL string
-	// API authentication
-	APIKey string
+	// API authentication. APIKeys maps "name" or "name@team" identities to
+	// their own tokens; memories stored with them can be labeled team or
+	// private. APIKey is the shared, anonymous key.
+	APIKey  string
+	APIKeys map[string]string
+	// Shutdown
+	ShutdownDrainSeconds int
+	// Feature threads
+	ThreadMaxEntryTokens int    // 0 disables the per-entry limit
+	ThreadAutoSummarize  bool   // condense oversized entries instead of rejecting them
+	Tokenizer            string // prices thread entries and context: chars, cl100k or claude
+	// Scheduled compaction and its report delivery
+	CompactIntervalHours int    // 0 disables scheduled compaction
+	CompactSchedule      string // a duration or cron expression; overrides CompactIntervalHours
+	DBSizeAlertMB        int    // 0 disables the database size alert
+	ReportWebhookURL     string
+	ReportSMTPAddr       string
+	ReportSMTPUser       string
+	ReportSMTPPassword   string
+	ReportEmailFrom      string
+	ReportEmailTo        []string
+	// Image attachments
+	AttachmentsDir      string // defaults to an attachments dir next to the database
+	AttachmentMaxBytes  int
+	ImageEmbedderURL    string // empty disables image embeddings
+	ImageEmbeddingModel string
+	// TLS: serve HTTPS with a cert/key pair or a generated self-signed cert,
+	// optionally requiring client certificates signed by TLSClientCAFile
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSClientCAFile string
+	TLSSelfSigned   bool
+	// Impact decay half-life in days; 0 keeps impact scores forever
+	ImpactHalfLifeDays float64
+	// Per-workspace quotas (0 is unlimited) and what happens on overflow:
+	// "reject" refuses the store, "evict" drops the least retrievable memories
+	QuotaMaxMemories int
+	QuotaMaxBytes    int
+	QuotaOverflow    string
+	// Per-endpoint request deadlines keyed by default, search, store and bulk
+	EndpointTimeouts map[string]time.Duration
+}
+
+// embeddingDefaults are each backend's default base URL and model.
+var embeddingDefaults = map[string]struct{ baseURL, model string }{
+	"ollama": {"", "nomic-embed-text"},
+	"openai": {"https://api.openai.com/v1", "text-embedding-3-small"},
+	"voyage": {"https://api.voyageai.com/v1", "voyage-3"},
+	"tei":    {"http://localhost:8080", "all-MiniLM-L6-v2"},
+}
+
+// summaryModelDefaults are each summary provider's default model.
+var summaryModelDefaults = map[string]string{
+	"ollama": "qwen2.5:1.5b",
+	"openai": "gpt-4o-mini",
+}
+
+// modelDimensions are the native vector sizes of well-known models, used
+// when EMBEDDING_DIM is unset.
+var modelDimensions = map[string]int{
+	"nomic-embed-text":       768,
+	"mxbai-embed-large":      1024,
+	"all-minilm":             384,
+	"text-embedding-3-small": 1536,
+	"text-embedding-3-large": 3072,
+	"text-embedding-ada-002": 1536,
+	"voyage-3":               1024,
+	"voyage-3-lite":          512,
+	"voyage-3-large":         1024,
+	"voyage-code-3":          1024,
+	"all-MiniLM-L6-v2":       384,
+	"bge-small-en-v1.5":      384,
+	"bge-base-en-v1.5":       768,
+}
+
+// ModelDimension returns model's native vector size, or 0 if unknown.
+func ModelDimension(model string) int {
+	return modelDimensions[model]
+}
+
+// defaultEndpointTimeouts apply to any key missing from ENDPOINT_TIMEOUTS.
+// Bulk matches the server's write timeout.
+var defaultEndpointTimeouts = map[string]time.Duration{
+	"default": 30 * time.Second,
+	"search":  10 * time.Second,
+	"store":   30 * time.Second,
+	"bulk":    60 * time.Second,
 }
 
 func Load() (*Config, error) {
 	cfg := &Config{
-		Port:                envInt("PORT", 8741),
-		DBPath:              envStr("MEMORY_DB_PATH", "/data/memory.db"),
-		OllamaBaseURL:       envStr("OLLAMA_BASE_URL", "http://localhost:11434"),
-		QdrantURL:           envStr("QDRANT_URL", "http://localhost:6333"),
-		EmbeddingModel:      envStr("EMBEDDING_MODEL", "nomic-embed-text"),
-		EmbeddingDim:        envInt("EMBEDDING_DIM", 768),
-		LogLevel:            envStr("LOG_LEVEL", "info"),
-		VectorWeight:        envFloat("VECTOR_WEIGHT", 0.7),
-		BM25Weight:          envFloat("BM25_WEIGHT", 0.3),
-		LongTermBoost:       envFloat("LONG_TERM_BOOST", 1.2),
-		DedupThreshold:      envFloat("DEDUP_THRESHOLD", 0.92),
-		DefaultMinScore:     envFloat("DEFAULT_MIN_SCORE", 0.3),
-		DefaultMaxResults:   envInt("DEFAULT_MAX_RESULTS", 10),
-		ShortTermTTLHours:   envInt("SHORT_TERM_TTL_HOURS", 72),
-		PromotionAccessMin:  envInt("PROMOTION_ACCESS_MIN", 3),
-		PromotionConfidence: envFloat("PROMOTION_CONFIDENCE_MIN", 0.85),
-		SkillDirs:           envSkillDirs("SKILL_DIRS"),
-		SkillAutoSync:       envBool("SKILL_AUTO_SYNC", true),
-		SummaryModel:        envStr("SUMMARY_MODEL", "qwen2.5:1.5b"),
-		SummaryEnabled:      envBool("SUMMARY_ENABLED", true),
-		MemoryServerURL:     envStr("MEMORY_SERVER_URL", "http://localhost:8741"),
-		APIKey:              envStr("MEMORY_API_KEY", ""),
+		Port:                 envInt("PORT", 8741),
+		DBPath:               envStr("MEMORY_DB_PATH", "/data/memory.db"),
+		OllamaBaseURL:        envStr("OLLAMA_BASE_URL", "http://localhost:11434"),
+		QdrantURL:            envStr("QDRANT_URL", "http://localhost:6333"),
+		LogLevel:             envStr("LOG_LEVEL", "info"),
+		EmbeddingBackend:     envStr("EMBEDDING_BACKEND", "ollama"),
+		EmbeddingAPIKey:      envStr("EMBEDDING_API_KEY", ""),
+		QdrantSharding:       envStr("QDRANT_SHARDING", vectorstore.ShardByWorkspace),
+		QdrantShardMaxPoints: envInt("QDRANT_SHARD_MAX_POINTS", vectorstore.DefaultShardMaxPoints),
+		WarmupEnabled:        envBool("WARMUP_ENABLED", true),
+		KeepaliveInterval:    envInt("EMBED_KEEPALIVE_SECONDS", 240),
+		VectorWeight:         envFloat("VECTOR_WEIGHT", 0.7),
+		BM25Weight:           envFloat("BM25_WEIGHT", 0.3),
+		LongTermBoost:        envFloat("LONG_TERM_BOOST", 1.2),
+		DedupThreshold:       envFloat("DEDUP_THRESHOLD", 0.92),
+		DefaultMinScore:      envFloat("DEFAULT_MIN_SCORE", 0.3),
+		DefaultMaxResults:    envInt("DEFAULT_MAX_RESULTS", 10),
+		RerankModel:          envStr("RERANK_MODEL", ""),
+		RerankBackend:        envStr("RERANK_BACKEND", "ollama"),
+		RerankBaseURL:        envStr("RERANK_BASE_URL", ""),
+		RerankAPIKey:         envStr("RERANK_API_KEY", ""),
+		RerankTopK:           envInt("RERANK_TOP_K", 20),
+		ShortTermTTLHours:    envInt("SHORT_TERM_TTL_HOURS", 72),
+		PromotionAccessMin:   envInt("PROMOTION_ACCESS_MIN", 3),
+		PromotionConfidence:  envFloat("PROMOTION_CONFIDENCE_MIN", 0.85),
+		CompactDemote:        envBool("COMPACT_DEMOTE", false),
+		CompactConsolidate:   envBool("COMPACT_CONSOLIDATE", false),
+		SkillDirs:            envSkillDirs("SKILL_DIRS"),
+		SkillAutoSync:        envBool("SKILL_AUTO_SYNC", true),
+		SummaryEnabled:       envBool("SUMMARY_ENABLED", true),
+		SummaryProvider:      envStr("SUMMARY_PROVIDER", "ollama"),
+		SummaryBaseURL:       envStr("SUMMARY_BASE_URL", "https://api.openai.com/v1"),
+		SummaryAPIKey:        envStr("SUMMARY_API_KEY", ""),
+		SummaryModels:        envMap("SUMMARY_MODELS"),
+		SummaryTokenCaps:     envIntMap("SUMMARY_DAILY_TOKEN_CAPS"),
+		MemoryServerURL:      envStr("MEMORY_SERVER_URL", "http://localhost:8741"),
+		APIKey:               envStr("MEMORY_API_KEY", ""),
+		APIKeys:              envMap("MEMORY_API_KEYS"),
+		ShutdownDrainSeconds: envInt("SHUTDOWN_DRAIN_SECONDS", 30),
+		ThreadMaxEntryTokens: envInt("THREAD_MAX_ENTRY_TOKENS", 0),
+		ThreadAutoSummarize:  envBool("THREAD_AUTO_SUMMARIZE", false),
+		Tokenizer:            envStr("TOKENIZER", tokens.Default),
+		CompactIntervalHours: envInt("COMPACT_INTERVAL_HOURS", 24),
+		CompactSchedule:      envStr("COMPACT_SCHEDULE", ""),
+		DBSizeAlertMB:        envInt("DB_SIZE_ALERT_MB", 1024),
+		ReportWebhookURL:     envStr("COMPACT_REPORT_WEBHOOK_URL", ""),
+		ReportSMTPAddr:       envStr("COMPACT_REPORT_SMTP_ADDR", ""),
+		ReportSMTPUser:       envStr("COMPACT_REPORT_SMTP_USER", ""),
+		ReportSMTPPassword:   envStr("COMPACT_REPORT_SMTP_PASSWORD", ""),
+		ReportEmailFrom:      envStr("COMPACT_REPORT_EMAIL_FROM", "clive-memory@localhost"),
+		ReportEmailTo:        envList("COMPACT_REPORT_EMAIL_TO"),
+		AttachmentsDir:       envStr("ATTACHMENTS_DIR", ""),
+		AttachmentMaxBytes:   envInt("ATTACHMENT_MAX_BYTES", 2<<20),
+		ImageEmbedderURL:     envStr("IMAGE_EMBEDDER_URL", ""),
+		ImageEmbeddingModel:  envStr("IMAGE_EMBEDDING_MODEL", "clip-vit-b-32"),
+		TLSCertFile:          envStr("TLS_CERT_FILE", ""),
+		TLSKeyFile:           envStr("TLS_KEY_FILE", ""),
+		TLSClientCAFile:      envStr("TLS_CLIENT_CA_FILE", ""),
+		TLSSelfSigned:        envBool("TLS_SELF_SIGNED", false),
+		ImpactHalfLifeDays:   envFloat("IMPACT_HALF_LIFE_DAYS", 90),
+		QuotaMaxMemories:     envInt("WORKSPACE_MAX_MEMORIES", 0),
+		QuotaMaxBytes:        envInt("WORKSPACE_MAX_BYTES", 0),
+		QuotaOverflow:        envStr("WORKSPACE_QUOTA_OVERFLOW", "reject"),
+		EndpointTimeouts:     envDurationMap("ENDPOINT_TIMEOUTS", defaultEndpointTimeouts),
+	}
+	defaults := embeddingDefaults[cfg.EmbeddingBackend]
+	cfg.EmbeddingBaseURL = envStr("EMBEDDING_BASE_URL", defaults.baseURL)
+	cfg.EmbeddingModel = envStr("EMBEDDING_MODEL", defaults.model)
+	dim := ModelDimension(cfg.EmbeddingModel)
+	if dim == 0 {
+		dim = 768 // what EMBEDDING_DIM defaulted to before backends were pluggable
+	}
+	cfg.EmbeddingDim = envInt("EMBEDDING_DIM", dim)
+	cfg.SummaryModel = envStr("SUMMARY_MODEL", summaryModelDefaults[cfg.SummaryProvider])
+	if cfg.AttachmentsDir == "" {
+		cfg.AttachmentsDir = filepath.Join(filepath.Dir(cfg.DBPath), "attachments")
 	}
 
 	if err := cfg.validate(); err != nil {
@@ -72,6 +247,23 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// CompactionSchedule returns when compaction runs: COMPACT_SCHEDULE if set,
+// otherwise every COMPACT_INTERVAL_HOURS. Nil means never.
+func (c *Config) CompactionSchedule() (schedule.Schedule, error) {
+	if c.CompactSchedule != "" {
+		return schedule.Parse(c.CompactSchedule)
+	}
+	if c.CompactIntervalHours > 0 {
+		return schedule.Every(time.Duration(c.CompactIntervalHours) * time.Hour), nil
+	}
+	return nil, nil
+}
+
+// ShardStrategy returns the Qdrant sharding strategy QDRANT_SHARDING names.
+func (c *Config) ShardStrategy() (vectorstore.ShardStrategy, error) {
+	return vectorstore.ParseShardStrategy(c.QdrantSharding, c.QdrantShardMaxPoints)
+}
+
 func (c *Config) validate() error {
 	if c.Port < 1 || c.Port > 65535 {
 		return fmt.Errorf("PORT must be between 1 and 65535, got %d", c.Port)
@@ -82,9 +274,100 @@ func (c *Config) validate() error {
 	if c.OllamaBaseURL == "" {
 		return fmt.Errorf("OLLAMA_BASE_URL must not be empty")
 	}
+	if c.SummaryProvider != "ollama" && c.SummaryProvider != "openai" {
+		return fmt.Errorf("SUMMARY_PROVIDER must be ollama or openai, got %q", c.SummaryProvider)
+	}
+	if c.SummaryEnabled && c.SummaryModel == "" {
+		return fmt.Errorf("SUMMARY_MODEL must not be empty when summarization is enabled")
+	}
+	if c.SummaryEnabled && c.SummaryProvider == "openai" && c.SummaryBaseURL == "https://api.openai.com/v1" && c.SummaryAPIKey == "" {
+		return fmt.Errorf("SUMMARY_API_KEY is required for the openai summary provider")
+	}
+	if _, ok := embeddingDefaults[c.EmbeddingBackend]; !ok {
+		return fmt.Errorf("EMBEDDING_BACKEND must be ollama, openai, voyage or tei, got %q", c.EmbeddingBackend)
+	}
+	if c.EmbeddingBackend != "ollama" && c.EmbeddingBaseURL == "" {
+		return fmt.Errorf("EMBEDDING_BASE_URL must not be empty for the %s backend", c.EmbeddingBackend)
+	}
+	if (c.EmbeddingBackend == "openai" && c.EmbeddingBaseURL == embeddingDefaults["openai"].baseURL ||
+		c.EmbeddingBackend == "voyage") && c.EmbeddingAPIKey == "" {
+		return fmt.Errorf("EMBEDDING_API_KEY is required for the %s backend", c.EmbeddingBackend)
+	}
 	if c.EmbeddingDim < 1 {
 		return fmt.Errorf("EMBEDDING_DIM must be positive, got %d", c.EmbeddingDim)
 	}
+	if c.RerankBackend != "ollama" && c.RerankBackend != "remote" {
+		return fmt.Errorf("RERANK_BACKEND must be ollama or remote, got %q", c.RerankBackend)
+	}
+	if c.RerankModel != "" && c.RerankBackend == "remote" && c.RerankBaseURL == "" {
+		return fmt.Errorf("RERANK_BASE_URL is required for the remote rerank backend")
+	}
+	if c.RerankTopK < 1 {
+		return fmt.Errorf("RERANK_TOP_K must be positive, got %d", c.RerankTopK)
+	}
+	if _, err := c.ShardStrategy(); err != nil {
+		return fmt.Errorf("QDRANT_SHARDING: %w", err)
+	}
+	if c.CompactIntervalHours < 0 {
+		return fmt.Errorf("COMPACT_INTERVAL_HOURS must not be negative, got %d", c.CompactIntervalHours)
+	}
+	if _, err := c.CompactionSchedule(); err != nil {
+		return fmt.Errorf("COMPACT_SCHEDULE: %w", err)
+	}
+	if c.DBSizeAlertMB < 0 {
+		return fmt.Errorf("DB_SIZE_ALERT_MB must not be negative, got %d", c.DBSizeAlertMB)
+	}
+	if c.ThreadMaxEntryTokens < 0 {
+		return fmt.Errorf("THREAD_MAX_ENTRY_TOKENS must not be negative, got %d", c.ThreadMaxEntryTokens)
+	}
+	if _, err := tokens.New(c.Tokenizer); err != nil {
+		return fmt.Errorf("TOKENIZER: %w", err)
+	}
+	if c.AttachmentMaxBytes < 1 {
+		return fmt.Errorf("ATTACHMENT_MAX_BYTES must be positive, got %d", c.AttachmentMaxBytes)
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if c.TLSCertFile != "" && c.TLSSelfSigned {
+		return fmt.Errorf("TLS_SELF_SIGNED cannot be combined with TLS_CERT_FILE")
+	}
+	if c.TLSClientCAFile != "" && !c.TLSEnabled() {
+		return fmt.Errorf("TLS_CLIENT_CA_FILE requires TLS_CERT_FILE or TLS_SELF_SIGNED")
+	}
+	if c.ImpactHalfLifeDays < 0 {
+		return fmt.Errorf("IMPACT_HALF_LIFE_DAYS must not be negative, got %f", c.ImpactHalfLifeDays)
+	}
+	if c.QuotaMaxMemories < 0 {
+		return fmt.Errorf("WORKSPACE_MAX_MEMORIES must not be negative, got %d", c.QuotaMaxMemories)
+	}
+	if c.QuotaMaxBytes < 0 {
+		return fmt.Errorf("WORKSPACE_MAX_BYTES must not be negative, got %d", c.QuotaMaxBytes)
+	}
+	if c.QuotaOverflow != "reject" && c.QuotaOverflow != "evict" {
+		return fmt.Errorf("WORKSPACE_QUOTA_OVERFLOW must be reject or evict, got %q", c.QuotaOverflow)
+	}
+	for name, d := range c.EndpointTimeouts {
+		if _, ok := defaultEndpointTimeouts[name]; !ok {
+			return fmt.Errorf("ENDPOINT_TIMEOUTS has unknown endpoint %q (want default, search, store or bulk)", name)
+		}
+		if d < 0 {
+			return fmt.Errorf("ENDPOINT_TIMEOUTS %s must not be negative, got %s", name, d)
+		}
+	}
+	tokens := make(map[string]string, len(c.APIKeys))
+	for identity, token := range c.APIKeys {
+		if name, _, _ := strings.Cut(identity, "@"); strings.TrimSpace(name) == "" {
+			return fmt.Errorf("MEMORY_API_KEYS identity %q has no name", identity)
+		}
+		if token == "" || token == c.APIKey {
+			return fmt.Errorf("MEMORY_API_KEYS %s needs its own non-empty token", identity)
+		}
+		if other, ok := tokens[token]; ok {
+			return fmt.Errorf("MEMORY_API_KEYS %s and %s share a token", other, identity)
+		}
+		tokens[token] = identity
+	}
 	sum := c.VectorWeight + c.BM25Weight
 	if sum < 0.99 || sum > 1.01 {
 		return fmt.Errorf("VECTOR_WEIGHT + BM25_WEIGHT must equal 1.0, got %f", sum)
@@ -92,6 +375,11 @@ func (c *Config) validate() error {
 	return nil
 }
 
+// TLSEnabled reports whether the server should serve HTTPS.
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" || c.TLSSelfSigned
+}
+
 func envStr(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -127,6 +415,58 @@ func envBool(key string, fallback bool) bool {
 	return fallback
 }
 
+// envMap parses comma-separated key=value pairs, e.g.
+// "session=gpt-4o-mini,distill=gpt-4o". Malformed pairs are skipped.
+func envMap(key string) map[string]string {
+	m := make(map[string]string)
+	for _, item := range envList(key) {
+		k, v, ok := strings.Cut(item, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if ok && k != "" && v != "" {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// envIntMap parses comma-separated key=integer pairs, skipping malformed ones.
+func envIntMap(key string) map[string]int {
+	m := make(map[string]int)
+	for k, v := range envMap(key) {
+		if i, err := strconv.Atoi(v); err == nil {
+			m[k] = i
+		}
+	}
+	return m
+}
+
+// envDurationMap parses comma-separated key=duration pairs, e.g.
+// "search=5s,bulk=2m", over a copy of defaults. Malformed durations are
+// skipped; "0" disables a deadline.
+func envDurationMap(key string, defaults map[string]time.Duration) map[string]time.Duration {
+	m := make(map[string]time.Duration, len(defaults))
+	for k, v := range defaults {
+		m[k] = v
+	}
+	for k, v := range envMap(key) {
+		if d, err := time.ParseDuration(v); err == nil {
+			m[k] = d
+		}
+	}
+	return m
+}
+
+// envList parses a comma-separated list, dropping empty items.
+func envList(key string) []string {
+	var items []string
+	for _, p := range strings.Split(os.Getenv(key), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			items = append(items, p)
+		}
+	}
+	return items
+}
+
 func envSkillDirs(key string) []string {
 	if v := os.Getenv(key); v != "" {
 		parts := strings.Split(v, ",")
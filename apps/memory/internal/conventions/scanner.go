@@ -0,0 +1,546 @@
+// Package conventions reads a project's tooling configuration (formatter and
+// linter settings, package manifests, CI workflows) and describes the
+// conventions it implies as PATTERN and PREFERENCE memories.
+package conventions
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// Convention is one observed project convention.
+type Convention struct {
+	Area       string            `json:"area"` // formatting, linting, testing, tooling, ci or language
+	MemoryType models.MemoryType `json:"memoryType"`
+	Content    string            `json:"content"`
+	Files      []string          `json:"files"` // paths relative to the project root
+}
+
+// maxCISteps caps how many CI commands are listed per workflow.
+const maxCISteps = 8
+
+// detector inspects the project rooted at a directory and returns whatever
+// conventions it recognises. Missing files are not errors.
+type detector func(p *project) []Convention
+
+var detectors = []detector{
+	detectEditorConfig,
+	detectPrettier,
+	detectJSLinters,
+	detectTypeScript,
+	detectPackageManager,
+	detectScripts,
+	detectJSTestRunners,
+	detectGo,
+	detectRust,
+	detectPython,
+	detectMakefile,
+	detectGitHooks,
+	detectWorkflows,
+}
+
+// Scan returns the conventions found in the project rooted at root.
+func Scan(root string) ([]Convention, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("scan conventions: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("scan conventions: %s is not a directory", root)
+	}
+
+	p := &project{root: root}
+	var found []Convention
+	for _, detect := range detectors {
+		found = append(found, detect(p)...)
+	}
+	return found, nil
+}
+
+// project gives detectors read access to files under the root.
+type project struct {
+	root string
+	pkg  *packageJSON
+}
+
+type packageJSON struct {
+	PackageManager string            `json:"packageManager"`
+	Scripts        map[string]string `json:"scripts"`
+	Prettier       map[string]any    `json:"prettier"`
+}
+
+func (p *project) exists(rel string) bool {
+	_, err := os.Stat(filepath.Join(p.root, rel))
+	return err == nil
+}
+
+func (p *project) read(rel string) ([]byte, bool) {
+	data, err := os.ReadFile(filepath.Join(p.root, rel))
+	return data, err == nil
+}
+
+// first returns the first of the candidate paths that exists.
+func (p *project) first(candidates ...string) (string, bool) {
+	for _, rel := range candidates {
+		if p.exists(rel) {
+			return rel, true
+		}
+	}
+	return "", false
+}
+
+// packageJSON returns the root package.json, or nil when there is none.
+func (p *project) packageJSON() *packageJSON {
+	if p.pkg != nil {
+		return p.pkg
+	}
+	data, ok := p.read("package.json")
+	if !ok {
+		return nil
+	}
+	var pkg packageJSON
+	if json.Unmarshal(data, &pkg) != nil {
+		return nil
+	}
+	p.pkg = &pkg
+	return p.pkg
+}
+
+func preference(area, content string, files ...string) Convention {
+	return Convention{Area: area, MemoryType: models.MemoryTypePreference, Content: content, Files: files}
+}
+
+func pattern(area, content string, files ...string) Convention {
+	return Convention{Area: area, MemoryType: models.MemoryTypePattern, Content: content, Files: files}
+}
+
+func detectEditorConfig(p *project) []Convention {
+	data, ok := p.read(".editorconfig")
+	if !ok {
+		return nil
+	}
+
+	// Only the catch-all section describes the project default
+	settings := map[string]string{}
+	section := ""
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "["):
+			section = strings.Trim(line, "[]")
+		case section == "*":
+			if k, v, ok := strings.Cut(line, "="); ok {
+				settings[strings.ToLower(strings.TrimSpace(k))] = strings.ToLower(strings.TrimSpace(v))
+			}
+		}
+	}
+
+	var parts []string
+	switch settings["indent_style"] {
+	case "tab":
+		parts = append(parts, "indent with tabs")
+	case "space":
+		if size := settings["indent_size"]; size != "" {
+			parts = append(parts, fmt.Sprintf("indent with %s spaces", size))
+		} else {
+			parts = append(parts, "indent with spaces")
+		}
+	}
+	if eol := settings["end_of_line"]; eol != "" {
+		parts = append(parts, strings.ToUpper(eol)+" line endings")
+	}
+	if n := settings["max_line_length"]; n != "" && n != "off" {
+		parts = append(parts, fmt.Sprintf("lines up to %s characters", n))
+	}
+	if settings["insert_final_newline"] == "true" {
+		parts = append(parts, "end files with a newline")
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return []Convention{preference("formatting", "Editor settings: "+strings.Join(parts, ", ")+".", ".editorconfig")}
+}
+
+func detectPrettier(p *project) []Convention {
+	var opts map[string]any
+	file, ok := p.first(".prettierrc", ".prettierrc.json", ".prettierrc.yaml", ".prettierrc.yml")
+	if ok {
+		data, _ := p.read(file)
+		// YAML is a superset of JSON, so one decoder covers both forms
+		if yaml.Unmarshal(data, &opts) != nil {
+			opts = nil
+		}
+	} else if file, ok = p.first("prettier.config.js", "prettier.config.mjs", "prettier.config.cjs", ".prettierrc.js"); !ok {
+		if pkg := p.packageJSON(); pkg != nil && pkg.Prettier != nil {
+			file, ok, opts = "package.json", true, pkg.Prettier
+		}
+	}
+	if !ok {
+		return nil
+	}
+
+	var parts []string
+	if v, ok := opts["semi"].(bool); ok {
+		parts = append(parts, map[bool]string{true: "semicolons", false: "no semicolons"}[v])
+	}
+	if v, ok := opts["singleQuote"].(bool); ok {
+		parts = append(parts, map[bool]string{true: "single quotes", false: "double quotes"}[v])
+	}
+	if v, ok := opts["tabWidth"]; ok {
+		parts = append(parts, fmt.Sprintf("tab width %v", v))
+	}
+	if v, ok := opts["useTabs"].(bool); ok && v {
+		parts = append(parts, "tabs for indentation")
+	}
+	if v, ok := opts["printWidth"]; ok {
+		parts = append(parts, fmt.Sprintf("print width %v", v))
+	}
+	if v, ok := opts["trailingComma"].(string); ok {
+		parts = append(parts, fmt.Sprintf("trailing commas: %s", v))
+	}
+
+	content := "Code is formatted with Prettier"
+	if len(parts) > 0 {
+		content += " (" + strings.Join(parts, ", ") + ")"
+	}
+	return []Convention{preference("formatting", content+"; format with Prettier rather than by hand.", file)}
+}
+
+func detectJSLinters(p *project) []Convention {
+	var found []Convention
+	if file, ok := p.first("biome.json", "biome.jsonc"); ok {
+		found = append(found, preference("linting", "Lint and format JavaScript/TypeScript with Biome.", file))
+	}
+	if file, ok := p.first("eslint.config.js", "eslint.config.mjs", "eslint.config.cjs", "eslint.config.ts",
+		".eslintrc", ".eslintrc.js", ".eslintrc.cjs", ".eslintrc.json", ".eslintrc.yml", ".eslintrc.yaml"); ok {
+		found = append(found, preference("linting", "Lint JavaScript/TypeScript with ESLint.", file))
+	}
+	return found
+}
+
+func detectTypeScript(p *project) []Convention {
+	data, ok := p.read("tsconfig.json")
+	if !ok {
+		return nil
+	}
+	// tsconfig allows comments and trailing commas, so look for the flag
+	// rather than decoding the file
+	compact := strings.Join(strings.Fields(string(data)), "")
+	if strings.Contains(compact, `"strict":true`) {
+		return []Convention{preference("language", "TypeScript runs in strict mode; avoid any and implicit nulls.", "tsconfig.json")}
+	}
+	return []Convention{preference("language", "The project is written in TypeScript.", "tsconfig.json")}
+}
+
+// lockfiles maps each JavaScript package manager to its lockfile, in
+// detection order.
+var lockfiles = []struct{ manager, file string }{
+	{"pnpm", "pnpm-lock.yaml"},
+	{"yarn", "yarn.lock"},
+	{"bun", "bun.lock"},
+	{"bun", "bun.lockb"},
+	{"npm", "package-lock.json"},
+}
+
+// packageManager returns the JavaScript package manager in use and the file
+// that says so.
+func (p *project) packageManager() (string, string) {
+	if pkg := p.packageJSON(); pkg != nil && pkg.PackageManager != "" {
+		name, _, _ := strings.Cut(pkg.PackageManager, "@")
+		return name, "package.json"
+	}
+	for _, l := range lockfiles {
+		if p.exists(l.file) {
+			return l.manager, l.file
+		}
+	}
+	if p.packageJSON() != nil {
+		return "npm", "package.json"
+	}
+	return "", ""
+}
+
+func detectPackageManager(p *project) []Convention {
+	manager, file := p.packageManager()
+	if manager == "" {
+		return nil
+	}
+	content := fmt.Sprintf("Use %s for JavaScript dependencies; do not mix in another package manager.", manager)
+	if p.exists("pnpm-workspace.yaml") || p.exists("turbo.json") {
+		content = fmt.Sprintf("This is a %s workspace monorepo; use %s for JavaScript dependencies and add them to the package that needs them.", manager, manager)
+	}
+	return []Convention{preference("tooling", content, file)}
+}
+
+// scriptRoles are the package.json scripts worth remembering, in the order
+// they are reported.
+var scriptRoles = []struct{ name, role string }{
+	{"build", "build"},
+	{"test", "run tests"},
+	{"lint", "lint"},
+	{"format", "format"},
+	{"typecheck", "type-check"},
+	{"check", "run checks"},
+}
+
+func detectScripts(p *project) []Convention {
+	pkg := p.packageJSON()
+	if pkg == nil || len(pkg.Scripts) == 0 {
+		return nil
+	}
+	manager, _ := p.packageManager()
+	run := manager + " run "
+	if manager == "yarn" || manager == "pnpm" {
+		run = manager + " "
+	}
+
+	var parts []string
+	for _, s := range scriptRoles {
+		if _, ok := pkg.Scripts[s.name]; ok {
+			parts = append(parts, fmt.Sprintf("%s with `%s%s`", s.role, run, s.name))
+		}
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return []Convention{pattern("tooling", "Project scripts: "+strings.Join(parts, ", ")+".", "package.json")}
+}
+
+// jsTestRunners maps test runner config files to the runner's name.
+var jsTestRunners = []struct {
+	name  string
+	files []string
+}{
+	{"Vitest", []string{"vitest.config.ts", "vitest.config.mts", "vitest.config.js", "vitest.workspace.ts"}},
+	{"Jest", []string{"jest.config.ts", "jest.config.js", "jest.config.cjs", "jest.config.mjs"}},
+	{"Playwright", []string{"playwright.config.ts", "playwright.config.js"}},
+}
+
+func detectJSTestRunners(p *project) []Convention {
+	var found []Convention
+	for _, r := range jsTestRunners {
+		if file, ok := p.first(r.files...); ok {
+			found = append(found, pattern("testing", fmt.Sprintf("Tests run with %s; follow its conventions for new tests.", r.name), file))
+		}
+	}
+	return found
+}
+
+func detectGo(p *project) []Convention {
+	data, ok := p.read("go.mod")
+	if !ok {
+		return nil
+	}
+	var module, version string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "module" {
+			module = fields[1]
+		}
+		if len(fields) == 2 && fields[0] == "go" {
+			version = fields[1]
+		}
+	}
+
+	content := fmt.Sprintf("Go module %s", module)
+	if version != "" {
+		content += fmt.Sprintf(" targeting Go %s", version)
+	}
+	found := []Convention{pattern("language", content+"; format with gofmt and test with `go test ./...`.", "go.mod")}
+	if file, ok := p.first(".golangci.yml", ".golangci.yaml", ".golangci.toml"); ok {
+		found = append(found, preference("linting", "Lint Go code with golangci-lint.", file))
+	}
+	return found
+}
+
+func detectRust(p *project) []Convention {
+	data, ok := p.read("Cargo.toml")
+	if !ok {
+		return nil
+	}
+	content := "Rust crate built with Cargo"
+	if edition := tomlValue(string(data), "package", "edition"); edition != "" {
+		content += fmt.Sprintf(" (edition %s)", edition)
+	}
+	found := []Convention{pattern("language", content+"; test with `cargo test` and lint with `cargo clippy`.", "Cargo.toml")}
+	if file, ok := p.first("rustfmt.toml", ".rustfmt.toml"); ok {
+		found = append(found, preference("formatting", "Format Rust code with rustfmt using the project rustfmt.toml.", file))
+	}
+	return found
+}
+
+// pythonTools are pyproject.toml sections and the convention each implies.
+var pythonTools = []struct{ section, area, content string }{
+	{"tool.ruff", "linting", "Lint Python with Ruff."},
+	{"tool.black", "formatting", "Format Python with Black."},
+	{"tool.mypy", "language", "Python code is type-checked with mypy; keep annotations complete."},
+	{"tool.pytest.ini_options", "testing", "Python tests run with pytest."},
+	{"tool.poetry", "tooling", "Use Poetry for Python dependencies."},
+	{"tool.uv", "tooling", "Use uv for Python dependencies."},
+}
+
+func detectPython(p *project) []Convention {
+	var found []Convention
+	if data, ok := p.read("pyproject.toml"); ok {
+		sections := tomlSections(string(data))
+		for _, t := range pythonTools {
+			if sections[t.section] {
+				found = append(found, preference(t.area, t.content, "pyproject.toml"))
+			}
+		}
+		if n := tomlValue(string(data), "tool.ruff", "line-length"); n != "" {
+			found = append(found, preference("formatting", fmt.Sprintf("Python lines are limited to %s characters.", n), "pyproject.toml"))
+		}
+	}
+	if p.exists("ruff.toml") {
+		found = append(found, preference("linting", "Lint Python with Ruff.", "ruff.toml"))
+	}
+	if p.exists("uv.lock") && !hasConvention(found, "Use uv") {
+		found = append(found, preference("tooling", "Use uv for Python dependencies.", "uv.lock"))
+	}
+	return found
+}
+
+// hasConvention reports whether found already has a convention starting with prefix.
+func hasConvention(found []Convention, prefix string) bool {
+	for _, c := range found {
+		if strings.HasPrefix(c.Content, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// makeTargets are the Makefile targets worth remembering.
+var makeTargets = []string{"build", "test", "lint", "fmt", "format", "check"}
+
+func detectMakefile(p *project) []Convention {
+	data, ok := p.read("Makefile")
+	if !ok {
+		return nil
+	}
+	targets := map[string]bool{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if name, _, ok := strings.Cut(line, ":"); ok && !strings.ContainsAny(name, " \t=$") {
+			targets[name] = true
+		}
+	}
+	var cmds []string
+	for _, t := range makeTargets {
+		if targets[t] {
+			cmds = append(cmds, "`make "+t+"`")
+		}
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	return []Convention{pattern("tooling", "Prefer the Makefile targets "+strings.Join(cmds, ", ")+" over invoking tools directly.", "Makefile")}
+}
+
+func detectGitHooks(p *project) []Convention {
+	if file, ok := p.first(".pre-commit-config.yaml", "lefthook.yml", "lefthook.yaml", ".husky"); ok {
+		return []Convention{pattern("tooling", "Git hooks run checks before each commit; run them locally instead of skipping them.", file)}
+	}
+	return nil
+}
+
+type workflow struct {
+	Jobs map[string]struct {
+		Steps []struct {
+			Run string `yaml:"run"`
+		} `yaml:"steps"`
+	} `yaml:"jobs"`
+}
+
+func detectWorkflows(p *project) []Convention {
+	dir := filepath.Join(p.root, ".github", "workflows")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var found []Convention
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+		rel := filepath.ToSlash(filepath.Join(".github", "workflows", e.Name()))
+		data, ok := p.read(rel)
+		if !ok {
+			continue
+		}
+		var wf workflow
+		if yaml.Unmarshal(data, &wf) != nil {
+			continue
+		}
+
+		jobs := make([]string, 0, len(wf.Jobs))
+		for name := range wf.Jobs {
+			jobs = append(jobs, name)
+		}
+		sort.Strings(jobs)
+
+		seen := map[string]bool{}
+		var cmds []string
+		for _, name := range jobs {
+			for _, step := range wf.Jobs[name].Steps {
+				cmd, _, _ := strings.Cut(strings.TrimSpace(step.Run), "\n")
+				if cmd == "" || seen[cmd] || len(cmds) == maxCISteps {
+					continue
+				}
+				seen[cmd] = true
+				cmds = append(cmds, "`"+cmd+"`")
+			}
+		}
+		if len(cmds) == 0 {
+			continue
+		}
+		found = append(found, pattern("ci", fmt.Sprintf("CI (%s) runs %s; run these before pushing.", rel, strings.Join(cmds, ", ")), rel))
+	}
+	return found
+}
+
+// tomlSections returns the table names declared in a TOML document.
+func tomlSections(doc string) map[string]bool {
+	sections := map[string]bool{}
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			sections[strings.Trim(line, "[] ")] = true
+		}
+	}
+	return sections
+}
+
+// tomlValue returns the unquoted value of key in the given table, or "" if
+// it is not set. It understands only simple key = value lines, which is all
+// the detectors need.
+func tomlValue(doc, table, key string) string {
+	current := ""
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.Trim(line, "[] ")
+			continue
+		}
+		if current != table {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok && strings.TrimSpace(k) == key {
+			return strings.Trim(strings.TrimSpace(v), `"'`)
+		}
+	}
+	return ""
+}
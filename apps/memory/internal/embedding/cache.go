@@ -1,49 +1,149 @@
 package embedding
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
+	"slices"
+	"sync"
 
+	"github.com/iammorganparry/clive/apps/memory/internal/metrics"
 	"github.com/iammorganparry/clive/apps/memory/internal/models"
 	"github.com/iammorganparry/clive/apps/memory/internal/search"
 	"github.com/iammorganparry/clive/apps/memory/internal/store"
 )
 
-// CachedEmbedder wraps an OllamaClient with content-hash caching via SQLite.
+var cacheLookups = metrics.NewCounter("clive_embedding_cache_lookups_total",
+	"Embedding cache lookups by result, hit or miss.", "result")
+
+var embedsCoalesced = metrics.NewCounter("clive_embedding_coalesced_total",
+	"Cache misses served by an identical embed already in flight.")
+
+// CachedEmbedder wraps an Embedder with content-hash caching via SQLite.
+// Concurrent misses for the same content share one upstream call.
 type CachedEmbedder struct {
-	client *OllamaClient
+	client Embedder
 	cache  *store.EmbeddingCacheStore
 	model  string
 	dim    int
+
+	mu       sync.Mutex
+	inflight map[string]*embedCall
 }
 
-func NewCachedEmbedder(client *OllamaClient, cache *store.EmbeddingCacheStore, model string, dim int) *CachedEmbedder {
+// embedCall is an upstream embed that callers missing the cache for the
+// same content wait on. vec and err are set before done is closed. The
+// embed is cancelled once every waiter has given up.
+type embedCall struct {
+	done    chan struct{}
+	vec     []float32
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+func NewCachedEmbedder(client Embedder, cache *store.EmbeddingCacheStore, model string, dim int) *CachedEmbedder {
 	return &CachedEmbedder{
-		client: client,
-		cache:  cache,
-		model:  model,
-		dim:    dim,
+		client:   client,
+		cache:    cache,
+		model:    model,
+		dim:      dim,
+		inflight: map[string]*embedCall{},
 	}
 }
 
 // Embed returns the embedding for text, using cache when available.
-func (e *CachedEmbedder) Embed(text string) ([]float32, error) {
+func (e *CachedEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
 	hash := ContentHash(text)
 
 	// Check cache
-	entry, err := e.cache.Get(hash)
+	entry, err := e.cache.Get(ctx, hash)
 	if err != nil {
 		return nil, fmt.Errorf("cache lookup: %w", err)
 	}
-	if entry != nil {
+	if entry != nil && entry.Model == e.model && entry.Dimension == e.dim {
+		cacheLookups.Inc("hit")
 		return search.BytesToFloat32(entry.Embedding), nil
 	}
+	cacheLookups.Inc("miss")
+
+	call, callCtx := e.join(ctx, hash)
+	leader := callCtx != nil
+	if leader {
+		go e.run(callCtx, call, hash, text)
+	} else {
+		embedsCoalesced.Inc()
+	}
+
+	select {
+	case <-call.done:
+		if call.err != nil || leader {
+			return call.vec, call.err
+		}
+		// Each caller owns the vector it gets back
+		return slices.Clone(call.vec), nil
+	case <-ctx.Done():
+		e.leave(hash, call)
+		return nil, ctx.Err()
+	}
+}
+
+// join returns the in-flight embed for hash, registering a new one if there
+// is none. The caller that registers it, the leader, gets the context to run
+// it with; others get nil.
+func (e *CachedEmbedder) join(ctx context.Context, hash string) (*embedCall, context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if call, ok := e.inflight[hash]; ok {
+		call.waiters++
+		return call, nil
+	}
+	// The embed outlives the caller that started it, so the others waiting
+	// on it are not failed by a request they have no part in
+	callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	call := &embedCall{done: make(chan struct{}), waiters: 1, cancel: cancel}
+	e.inflight[hash] = call
+	return call, callCtx
+}
 
-	// Generate embedding
-	vec, err := e.client.Embed(text)
+// leave gives up on an in-flight embed, cancelling it if no one else is
+// waiting. Later callers start a new one.
+func (e *CachedEmbedder) leave(hash string, call *embedCall) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	if e.inflight[hash] == call {
+		delete(e.inflight, hash)
+	}
+	call.cancel()
+}
+
+// run completes an in-flight embed and releases its waiters.
+func (e *CachedEmbedder) run(ctx context.Context, call *embedCall, hash, text string) {
+	call.vec, call.err = e.generate(ctx, hash, text)
+	e.mu.Lock()
+	if e.inflight[hash] == call {
+		delete(e.inflight, hash)
+	}
+	call.cancel()
+	e.mu.Unlock()
+	close(call.done)
+}
+
+// generate embeds text upstream and caches the result.
+func (e *CachedEmbedder) generate(ctx context.Context, hash, text string) ([]float32, error) {
+	vec, err := e.client.Embed(ctx, text)
 	if err != nil {
 		return nil, err
 	}
+	// A vector of the wrong size would be rejected by Qdrant, or worse,
+	// compared against vectors from another model
+	if len(vec) != e.dim {
+		return nil, fmt.Errorf("%s returned %d dimensions, EMBEDDING_DIM is %d", e.model, len(vec), e.dim)
+	}
 
 	// Store in cache
 	cacheEntry := &models.EmbeddingCacheEntry{
@@ -52,7 +152,7 @@ func (e *CachedEmbedder) Embed(text string) ([]float32, error) {
 		Dimension:   e.dim,
 		Model:       e.model,
 	}
-	if err := e.cache.Put(cacheEntry); err != nil {
+	if err := e.cache.Put(ctx, cacheEntry); err != nil {
 		// Non-fatal: log but continue
 		_ = err
 	}
@@ -60,6 +160,16 @@ func (e *CachedEmbedder) Embed(text string) ([]float32, error) {
 	return vec, nil
 }
 
+// HealthCheck verifies the embedding backend is reachable.
+func (e *CachedEmbedder) HealthCheck() error {
+	return e.client.HealthCheck()
+}
+
+// Model returns the name of the embedding model vectors are generated with.
+func (e *CachedEmbedder) Model() string {
+	return e.model
+}
+
 // ContentHash computes a SHA-256 hash of text content.
 func ContentHash(text string) string {
 	h := sha256.Sum256([]byte(text))
@@ -0,0 +1,78 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Embedder generates text embeddings with one backend.
+type Embedder interface {
+	// Embed generates an embedding vector for text. The request is
+	// abandoned when ctx is cancelled or its deadline passes.
+	Embed(ctx context.Context, text string) ([]float32, error)
+	// HealthCheck verifies the backend is reachable.
+	HealthCheck() error
+}
+
+// Embedding backends selectable with EMBEDDING_BACKEND.
+const (
+	BackendOllama = "ollama"
+	BackendOpenAI = "openai"
+	BackendVoyage = "voyage"
+	BackendTEI    = "tei"
+)
+
+// postJSON sends body to url and decodes a 200 response into out. name
+// prefixes errors, e.g. "openai embed".
+func postJSON(ctx context.Context, client *http.Client, url, apiKey, name string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal %s request: %w", name, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create %s request: %w", name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read %s response: %w", name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: status %d: %s", name, resp.StatusCode, string(respBody))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("decode %s response: %w", name, err)
+	}
+	return nil
+}
+
+// dataResponse is the {"data": [{"embedding": [...]}]} shape shared by
+// the OpenAI and Voyage embeddings APIs.
+type dataResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (r dataResponse) first(name string) ([]float32, error) {
+	if len(r.Data) == 0 || len(r.Data[0].Embedding) == 0 {
+		return nil, fmt.Errorf("%s returned no embeddings", name)
+	}
+	return r.Data[0].Embedding, nil
+}
@@ -0,0 +1,87 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ImageEmbedder generates image embeddings via a CLIP-style HTTP service.
+// The service receives {"model", "image"} with the image base64-encoded and
+// responds with {"embedding": [...]}.
+type ImageEmbedder struct {
+	url        string
+	model      string
+	httpClient *http.Client
+}
+
+func NewImageEmbedder(url, model string) *ImageEmbedder {
+	return &ImageEmbedder{
+		url:   url,
+		model: model,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+type imageEmbedRequest struct {
+	Model string `json:"model"`
+	Image string `json:"image"`
+}
+
+type imageEmbedResponse struct {
+	Embedding []float32 `json:"embedding"`
+}
+
+// Model returns the name of the image embedding model.
+func (c *ImageEmbedder) Model() string {
+	return c.model
+}
+
+// Embed generates an embedding vector for the given image bytes.
+func (c *ImageEmbedder) Embed(ctx context.Context, image []byte) ([]float32, error) {
+	data, err := json.Marshal(imageEmbedRequest{
+		Model: c.model,
+		Image: base64.StdEncoding.EncodeToString(image),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal image embed request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("create image embed request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("image embed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read image embed response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image embed: status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result imageEmbedResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("decode image embed response: %w", err)
+	}
+	if len(result.Embedding) == 0 {
+		return nil, fmt.Errorf("image embedder returned no embedding")
+	}
+
+	return result.Embedding, nil
+}
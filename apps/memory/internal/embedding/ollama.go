@@ -2,6 +2,7 @@ package embedding
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -35,8 +36,9 @@ type embedResponse struct {
 	Embeddings [][]float32 `json:"embeddings"`
 }
 
-// Embed generates an embedding vector for the given text.
-func (c *OllamaClient) Embed(text string) ([]float32, error) {
+// Embed generates an embedding vector for the given text. The request is
+// abandoned when ctx is cancelled or its deadline passes.
+func (c *OllamaClient) Embed(ctx context.Context, text string) ([]float32, error) {
 	reqBody := embedRequest{
 		Model: c.model,
 		Input: text,
@@ -47,7 +49,13 @@ func (c *OllamaClient) Embed(text string) ([]float32, error) {
 		return nil, fmt.Errorf("marshal embed request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(c.baseURL+"/api/embed", "application/json", bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/embed", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("create embed request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("ollama embed: %w", err)
 	}
@@ -0,0 +1,75 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIClient generates text embeddings with an OpenAI-compatible
+// /embeddings endpoint (OpenAI, Azure OpenAI, vLLM, LM Studio, ...).
+type OpenAIClient struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	dimensions int
+	httpClient *http.Client
+}
+
+// NewOpenAIClient creates a client for the API at baseURL, e.g.
+// "https://api.openai.com/v1". A non-zero dimensions asks the model to
+// shorten its vectors, which only the text-embedding-3 models support.
+func NewOpenAIClient(baseURL, apiKey, model string, dimensions int) *OpenAIClient {
+	return &OpenAIClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		dimensions: dimensions,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+type openAIEmbedRequest struct {
+	Model      string `json:"model"`
+	Input      string `json:"input"`
+	Dimensions int    `json:"dimensions,omitempty"`
+}
+
+// Embed generates an embedding vector for the given text.
+func (c *OpenAIClient) Embed(ctx context.Context, text string) ([]float32, error) {
+	var result dataResponse
+	err := postJSON(ctx, c.httpClient, c.baseURL+"/embeddings", c.apiKey, "openai embed", openAIEmbedRequest{
+		Model:      c.model,
+		Input:      text,
+		Dimensions: c.dimensions,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result.first("openai")
+}
+
+// HealthCheck verifies the API is reachable and the key is accepted.
+func (c *OpenAIClient) HealthCheck() error {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("openai health check: %w", err)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("openai health check: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("openai health check: status %d", resp.StatusCode)
+	}
+	return nil
+}
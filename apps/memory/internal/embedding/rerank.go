@@ -0,0 +1,161 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Reranker backends selectable with RERANK_BACKEND. Both implement
+// search.Reranker.
+const (
+	RerankBackendOllama = "ollama"
+	RerankBackendRemote = "remote"
+)
+
+// RemoteReranker calls a /rerank endpoint in the shape shared by Cohere,
+// Jina, Voyage and vLLM: {model, query, documents} in, and a list of
+// {index, relevance_score} out.
+type RemoteReranker struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// NewRemoteReranker creates a client for the API at baseURL, e.g.
+// "https://api.jina.ai/v1".
+func NewRemoteReranker(baseURL, apiKey, model string) *RemoteReranker {
+	return &RemoteReranker{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+type remoteRerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+}
+
+type rerankScore struct {
+	Index          int     `json:"index"`
+	RelevanceScore float64 `json:"relevance_score"`
+}
+
+// remoteRerankResponse accepts both "results" (Cohere, Jina, vLLM) and
+// "data" (Voyage).
+type remoteRerankResponse struct {
+	Results []rerankScore `json:"results"`
+	Data    []rerankScore `json:"data"`
+}
+
+// Rerank scores documents against query.
+func (c *RemoteReranker) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	var result remoteRerankResponse
+	err := postJSON(ctx, c.httpClient, c.baseURL+"/rerank", c.apiKey, "rerank", remoteRerankRequest{
+		Model:     c.model,
+		Query:     query,
+		Documents: documents,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Results == nil {
+		result.Results = result.Data
+	}
+
+	scores := make([]float64, len(documents))
+	seen := make([]bool, len(documents))
+	for _, r := range result.Results {
+		if r.Index < 0 || r.Index >= len(documents) {
+			return nil, fmt.Errorf("rerank returned index %d for %d documents", r.Index, len(documents))
+		}
+		scores[r.Index] = r.RelevanceScore
+		seen[r.Index] = true
+	}
+	for i, ok := range seen {
+		if !ok {
+			return nil, fmt.Errorf("rerank returned no score for document %d", i)
+		}
+	}
+	return scores, nil
+}
+
+// OllamaReranker scores documents with a generative model served by
+// Ollama, which has no rerank endpoint: the model reads the query and all
+// candidates in one prompt and rates each one.
+type OllamaReranker struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+}
+
+func NewOllamaReranker(baseURL, model string) *OllamaReranker {
+	return &OllamaReranker{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+type ollamaGenerateRequest struct {
+	Model   string         `json:"model"`
+	Prompt  string         `json:"prompt"`
+	Format  string         `json:"format"`
+	Stream  bool           `json:"stream"`
+	Options map[string]any `json:"options"`
+}
+
+type ollamaGenerateResponse struct {
+	Response string `json:"response"`
+}
+
+// maxRerankDocChars bounds each candidate in the prompt, so a long memory
+// can't push the rest out of the model's context.
+const maxRerankDocChars = 1500
+
+// Rerank scores documents against query from 0 to 10.
+func (c *OllamaReranker) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	var prompt strings.Builder
+	prompt.WriteString("Rate how relevant each passage is to the query, from 0 (unrelated) to 10 (answers it directly).\n")
+	fmt.Fprintf(&prompt, "Reply with JSON only: {\"scores\": [...]} with exactly %d numbers, one per passage, in order.\n\n", len(documents))
+	fmt.Fprintf(&prompt, "Query: %s\n", query)
+	for i, doc := range documents {
+		if r := []rune(doc); len(r) > maxRerankDocChars {
+			doc = string(r[:maxRerankDocChars])
+		}
+		fmt.Fprintf(&prompt, "\nPassage %d:\n%s\n", i+1, doc)
+	}
+
+	var result ollamaGenerateResponse
+	err := postJSON(ctx, c.httpClient, c.baseURL+"/api/generate", "", "ollama rerank", ollamaGenerateRequest{
+		Model:   c.model,
+		Prompt:  prompt.String(),
+		Format:  "json",
+		Options: map[string]any{"temperature": 0},
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed struct {
+		Scores []float64 `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(result.Response), &parsed); err != nil {
+		return nil, fmt.Errorf("decode ollama rerank scores: %w", err)
+	}
+	if len(parsed.Scores) != len(documents) {
+		return nil, fmt.Errorf("ollama rerank returned %d scores for %d documents", len(parsed.Scores), len(documents))
+	}
+	return parsed.Scores, nil
+}
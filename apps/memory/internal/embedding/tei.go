@@ -0,0 +1,64 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TEIClient generates text embeddings with a Hugging Face
+// text-embeddings-inference (TEI) server, e.g. one serving all-MiniLM-L6-v2
+// or bge-small. The server is an external dependency, run alongside this
+// one (for instance the ghcr.io/huggingface/text-embeddings-inference
+// image), so the model runs out of process and this server needs no cgo
+// runtime bindings.
+type TEIClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewTEIClient(baseURL string) *TEIClient {
+	return &TEIClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+type teiEmbedRequest struct {
+	Inputs    string `json:"inputs"`
+	Normalize bool   `json:"normalize"`
+}
+
+// Embed generates an embedding vector for the given text.
+func (c *TEIClient) Embed(ctx context.Context, text string) ([]float32, error) {
+	var result [][]float32
+	err := postJSON(ctx, c.httpClient, c.baseURL+"/embed", "", "tei embed", teiEmbedRequest{
+		Inputs:    text,
+		Normalize: true,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 || len(result[0]) == 0 {
+		return nil, fmt.Errorf("tei server returned no embeddings")
+	}
+	return result[0], nil
+}
+
+// HealthCheck verifies the server is up and its model is loaded.
+func (c *TEIClient) HealthCheck() error {
+	resp, err := c.httpClient.Get(c.baseURL + "/health")
+	if err != nil {
+		return fmt.Errorf("tei health check: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("tei health check: status %d", resp.StatusCode)
+	}
+	return nil
+}
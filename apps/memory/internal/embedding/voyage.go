@@ -0,0 +1,64 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VoyageClient generates text embeddings via the Voyage AI API.
+type VoyageClient struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	dimensions int
+	httpClient *http.Client
+}
+
+// NewVoyageClient creates a client for the API at baseURL, e.g.
+// "https://api.voyageai.com/v1". A non-zero dimensions is sent as
+// output_dimension, which voyage-3-large and voyage-code-3 support.
+func NewVoyageClient(baseURL, apiKey, model string, dimensions int) *VoyageClient {
+	return &VoyageClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		dimensions: dimensions,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+type voyageEmbedRequest struct {
+	Model           string   `json:"model"`
+	Input           []string `json:"input"`
+	OutputDimension int      `json:"output_dimension,omitempty"`
+}
+
+// Embed generates an embedding vector for the given text.
+func (c *VoyageClient) Embed(ctx context.Context, text string) ([]float32, error) {
+	var result dataResponse
+	err := postJSON(ctx, c.httpClient, c.baseURL+"/embeddings", c.apiKey, "voyage embed", voyageEmbedRequest{
+		Model:           c.model,
+		Input:           []string{text},
+		OutputDimension: c.dimensions,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result.first("voyage")
+}
+
+// HealthCheck embeds a short string, as Voyage has no endpoint that
+// checks the key without doing work.
+func (c *VoyageClient) HealthCheck() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := c.Embed(ctx, "health"); err != nil {
+		return fmt.Errorf("voyage health check: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// WarmupState describes how far the embedding model warm-up has progressed.
+type WarmupState string
+
+const (
+	WarmupPending  WarmupState = "pending"
+	WarmupWarming  WarmupState = "warming"
+	WarmupReady    WarmupState = "ready"
+	WarmupFailed   WarmupState = "failed"
+	WarmupDisabled WarmupState = "disabled"
+)
+
+// warmupText is embedded (and reranked) to force a local backend to load
+// the model into memory.
+const warmupText = "clive memory warm-up"
+
+// warmupTimeout bounds one warm-up, so a backend that accepts the request
+// but never answers doesn't hold it forever.
+const warmupTimeout = 2 * time.Minute
+
+// Reranker scores documents against a query. It matches search.Reranker,
+// which the rerank backends here implement, without importing search.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, documents []string) ([]float64, error)
+}
+
+// Warmer preloads the embedding model, and the rerank model when one is
+// set, on startup and keeps them resident with periodic keepalive calls, so
+// the first real search doesn't pay the model load cost.
+type Warmer struct {
+	client   Embedder
+	reranker Reranker
+	interval time.Duration
+	logger   *slog.Logger
+
+	mu        sync.RWMutex
+	state     WarmupState
+	lastError string
+	lastWarm  time.Time
+	duration  time.Duration
+}
+
+// NewWarmer creates a warmer. A zero interval disables keepalive embeds. A
+// nil client skips the embedding model, for when only the reranker is local.
+func NewWarmer(client Embedder, interval time.Duration, logger *slog.Logger) *Warmer {
+	return &Warmer{
+		client:   client,
+		interval: interval,
+		logger:   logger,
+		state:    WarmupPending,
+	}
+}
+
+// SetReranker adds a rerank model to warm up alongside the embedding model.
+// The warmer is only ready once both have loaded. Call it before Run.
+func (w *Warmer) SetReranker(r Reranker) {
+	w.reranker = r
+}
+
+// WarmupStatus is a snapshot of the warmer state for readiness reporting.
+type WarmupStatus struct {
+	State      WarmupState `json:"state"`
+	Error      string      `json:"error,omitempty"`
+	LastWarmAt int64       `json:"lastWarmAt,omitempty"`
+	DurationMs int64       `json:"durationMs,omitempty"`
+}
+
+// Status returns the current warm-up state.
+func (w *Warmer) Status() WarmupStatus {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	status := WarmupStatus{
+		State:      w.state,
+		Error:      w.lastError,
+		DurationMs: w.duration.Milliseconds(),
+	}
+	if !w.lastWarm.IsZero() {
+		status.LastWarmAt = w.lastWarm.Unix()
+	}
+	return status
+}
+
+// Ready reports whether the models have been loaded at least once.
+func (w *Warmer) Ready() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.state == WarmupReady
+}
+
+// Warmup issues a single dummy embed, and a dummy rerank when a reranker
+// is set, and records the result. It gives up when ctx is cancelled or
+// after warmupTimeout.
+func (w *Warmer) Warmup(ctx context.Context) error {
+	w.mu.Lock()
+	if w.state != WarmupReady {
+		w.state = WarmupWarming
+	}
+	w.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, warmupTimeout)
+	defer cancel()
+
+	start := time.Now()
+	var err error
+	if w.client != nil {
+		_, err = w.client.Embed(ctx, warmupText)
+	}
+	if err == nil && w.reranker != nil {
+		if _, rerr := w.reranker.Rerank(ctx, warmupText, []string{warmupText}); rerr != nil {
+			err = fmt.Errorf("rerank: %w", rerr)
+		}
+	}
+	elapsed := time.Since(start)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		// A failed keepalive doesn't unload a model that already loaded.
+		if w.state != WarmupReady {
+			w.state = WarmupFailed
+		}
+		w.lastError = err.Error()
+		return err
+	}
+	w.state = WarmupReady
+	w.lastError = ""
+	w.lastWarm = time.Now()
+	w.duration = elapsed
+	return nil
+}
+
+// Run performs the initial warm-up, retrying until it succeeds, then sends
+// keepalive embeds every interval until ctx is cancelled.
+func (w *Warmer) Run(ctx context.Context) {
+	retry := 5 * time.Second
+	for {
+		err := w.Warmup(ctx)
+		if err == nil {
+			w.logger.Info("models warmed up", "duration_ms", w.Status().DurationMs, "rerank", w.reranker != nil)
+			break
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		w.logger.Warn("model warm-up failed, retrying", "error", err, "retry_in", retry.String())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		if retry < time.Minute {
+			retry *= 2
+		}
+	}
+
+	if w.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.Warmup(ctx); err != nil && ctx.Err() == nil {
+				w.logger.Warn("model keepalive failed", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// UseFixtures switches the server to test mode: tool calls are answered
+// from <dir>/<tool name>.json instead of the HTTP memory server, so MCP
+// clients can be developed and tested offline. A <tool name>.error.json
+// fixture is returned as a tool error.
+func (s *Server) UseFixtures(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("fixtures dir: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("fixtures dir: %s is not a directory", dir)
+	}
+	s.fixturesDir = dir
+	return nil
+}
+
+// fixtureResponse returns the canned response for a tool.
+func (s *Server) fixtureResponse(tool string) (string, bool) {
+	if data, err := os.ReadFile(filepath.Join(s.fixturesDir, tool+".json")); err == nil {
+		return string(data), false
+	}
+	if data, err := os.ReadFile(filepath.Join(s.fixturesDir, tool+".error.json")); err == nil {
+		return formatProblem(data), true
+	}
+	return fmt.Sprintf("no fixture for tool %s in %s", tool, s.fixturesDir), true
+}
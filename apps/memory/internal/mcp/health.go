@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Retry policy for requests that could not reach the memory server, e.g.
+// while it restarts. Only connection failures are retried: the request was
+// never received, so resending is safe even for writes.
+const (
+	defaultRetryAttempts = 5
+	defaultRetryBase     = 200 * time.Millisecond
+	maxRetryDelay        = 2 * time.Second
+)
+
+// DefaultHeartbeatInterval is how often StartHeartbeat checks the memory
+// server.
+const DefaultHeartbeatInterval = 15 * time.Second
+
+// BackendStatus is the memory server connectivity reported by the
+// memory_status tool.
+type BackendStatus struct {
+	ServerURL string `json:"serverUrl"`
+	Connected bool   `json:"connected"`
+	// Health is the server's own status, "ok" or "degraded" when one of its
+	// dependencies is down. Empty when the server was not reached.
+	Health              string `json:"health,omitempty"`
+	LastError           string `json:"lastError,omitempty"`
+	LastCheckedAt       int64  `json:"lastCheckedAt,omitempty"`
+	LastConnectedAt     int64  `json:"lastConnectedAt,omitempty"`
+	DownSince           int64  `json:"downSince,omitempty"`
+	ConsecutiveFailures int    `json:"consecutiveFailures"`
+}
+
+// backendMonitor tracks memory server connectivity from heartbeats and
+// tool calls.
+type backendMonitor struct {
+	mu     sync.Mutex
+	status BackendStatus
+}
+
+// reached records a response from the server.
+func (m *backendMonitor) reached() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.markReached()
+}
+
+// checked records a health check response.
+func (m *backendMonitor) checked(health string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.markReached()
+	m.status.Health = health
+}
+
+func (m *backendMonitor) markReached() {
+	now := time.Now().Unix()
+	m.status.Connected = true
+	m.status.LastError = ""
+	m.status.LastCheckedAt = now
+	m.status.LastConnectedAt = now
+	m.status.DownSince = 0
+	m.status.ConsecutiveFailures = 0
+}
+
+func (m *backendMonitor) failed(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now().Unix()
+	if m.status.DownSince == 0 {
+		m.status.DownSince = now
+	}
+	m.status.Connected = false
+	m.status.Health = ""
+	m.status.LastError = err.Error()
+	m.status.LastCheckedAt = now
+	m.status.ConsecutiveFailures++
+}
+
+func (m *backendMonitor) snapshot() BackendStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+// SetRetry sets how many times a request that can't reach the memory
+// server is attempted, and the first backoff delay, which doubles per
+// attempt up to 2s. attempts of 1 disables retries.
+func (s *Server) SetRetry(attempts int, base time.Duration) {
+	s.retryAttempts = max(attempts, 1)
+	s.retryBase = base
+}
+
+// StartHeartbeat checks the memory server every interval until stop is
+// closed, so memory_status reflects an outage before a tool call hits it.
+func (s *Server) StartHeartbeat(interval time.Duration, stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s.CheckBackend()
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// CheckBackend probes the memory server's /health endpoint and returns the
+// updated status. A degraded server still counts as connected: its tools
+// work as far as its dependencies allow.
+func (s *Server) CheckBackend() BackendStatus {
+	resp, err := s.client.Get(s.serverURL + "/health")
+	if err != nil {
+		s.monitor.failed(err)
+		return s.monitor.snapshot()
+	}
+	defer resp.Body.Close()
+
+	var health struct {
+		Status string `json:"status"`
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(body, &health); err != nil || health.Status == "" {
+		health.Status = fmt.Sprintf("status %d", resp.StatusCode)
+	}
+	s.monitor.checked(health.Status)
+	return s.monitor.snapshot()
+}
+
+// toolStatus reports memory server connectivity after a fresh check.
+func (s *Server) toolStatus() (string, bool) {
+	data, err := json.Marshal(s.CheckBackend())
+	if err != nil {
+		return fmt.Sprintf("marshal error: %s", err), true
+	}
+	return string(data), false
+}
+
+// send performs req, retrying with exponential backoff while the memory
+// server is unreachable. newReq must build a fresh request per attempt.
+func (s *Server) send(newReq func() (*http.Request, error)) (*http.Response, error) {
+	delay := s.retryBase
+	for attempt := 1; ; attempt++ {
+		req, err := newReq()
+		if err != nil {
+			return nil, fmt.Errorf("request error: %s", err)
+		}
+		resp, err := s.client.Do(req)
+		if err == nil {
+			s.monitor.reached()
+			return resp, nil
+		}
+		if !unreachable(err) {
+			return nil, fmt.Errorf("HTTP error: %s", err)
+		}
+		s.monitor.failed(err)
+		if attempt >= s.retryAttempts {
+			return nil, fmt.Errorf("backend_unavailable: the memory server at %s is unreachable (%s); "+
+				"memory tools are unavailable, continue without them and check memory_status later", s.serverURL, err)
+		}
+		time.Sleep(delay)
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// unreachable reports whether err means the request never reached the
+// server: the connection was refused or could not be opened.
+func unreachable(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
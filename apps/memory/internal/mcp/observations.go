@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+const (
+	// observationBatchSize is how many observations a session buffers
+	// before they are sent to the memory server in one batch.
+	observationBatchSize = 20
+	// maxBufferedObservations bounds a session's buffer while the memory
+	// server is unreachable; the oldest observations are dropped first.
+	maxBufferedObservations = 500
+)
+
+// toolObserve buffers one observed tool call and sends the session's buffer
+// once it holds a full batch.
+func (s *Server) toolObserve(args map[string]interface{}) (string, bool) {
+	sessionID, _ := args["sessionId"].(string)
+	toolName, _ := args["toolName"].(string)
+	if sessionID == "" || toolName == "" {
+		return "sessionId and toolName are required", true
+	}
+
+	obs := map[string]interface{}{
+		"toolName": toolName,
+		"input":    args["input"],
+		"output":   args["output"],
+		"success":  getBool(args, "success", true),
+	}
+	if s.observations == nil {
+		s.observations = map[string][]map[string]interface{}{}
+	}
+	pending := append(s.observations[sessionID], obs)
+	if len(pending) > maxBufferedObservations {
+		pending = pending[len(pending)-maxBufferedObservations:]
+	}
+	s.observations[sessionID] = pending
+
+	if len(pending) < observationBatchSize {
+		return observeResult(sessionID, len(pending)), false
+	}
+	if result, isErr := s.flushObservations(sessionID); isErr {
+		return result, true
+	}
+	return observeResult(sessionID, 0), false
+}
+
+// toolSessionEnd flushes the session's observations and ends it, which
+// stores the session's summary as a memory.
+func (s *Server) toolSessionEnd(args map[string]interface{}) (string, bool) {
+	sessionID, _ := args["sessionId"].(string)
+	if sessionID == "" {
+		return "sessionId is required", true
+	}
+	if result, isErr := s.flushObservations(sessionID); isErr {
+		return result, true
+	}
+
+	body := map[string]interface{}{
+		"workspace": args["workspace"],
+	}
+	if transcript, ok := args["transcript"].(string); ok && transcript != "" {
+		body["transcript"] = transcript
+	}
+	return s.httpPost(fmt.Sprintf("/sessions/%s/end", url.PathEscape(sessionID)), body)
+}
+
+// flushObservations sends a session's buffered observations. They stay
+// buffered if the memory server rejects them or cannot be reached.
+func (s *Server) flushObservations(sessionID string) (string, bool) {
+	pending := s.observations[sessionID]
+	if len(pending) == 0 {
+		return "", false
+	}
+	result, isErr := s.httpPost(fmt.Sprintf("/sessions/%s/observations/batch", url.PathEscape(sessionID)),
+		map[string]interface{}{"observations": pending})
+	if !isErr {
+		delete(s.observations, sessionID)
+	}
+	return result, isErr
+}
+
+// flushAllObservations sends every session's buffer, e.g. when the client
+// disconnects without ending its sessions.
+func (s *Server) flushAllObservations() {
+	for sessionID := range s.observations {
+		s.flushObservations(sessionID)
+	}
+}
+
+func observeResult(sessionID string, buffered int) string {
+	data, _ := json.Marshal(map[string]interface{}{"sessionId": sessionID, "buffered": buffered})
+	return string(data)
+}
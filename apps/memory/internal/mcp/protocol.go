@@ -36,7 +36,8 @@ type Notification struct {
 
 // ServerCapabilities describes what the MCP server supports.
 type ServerCapabilities struct {
-	Tools *ToolCapabilities `json:"tools,omitempty"`
+	Tools     *ToolCapabilities     `json:"tools,omitempty"`
+	Resources *ResourceCapabilities `json:"resources,omitempty"`
 }
 
 // ToolCapabilities describes tool support.
@@ -44,6 +45,12 @@ type ToolCapabilities struct {
 	ListChanged bool `json:"listChanged,omitempty"`
 }
 
+// ResourceCapabilities describes resource support.
+type ResourceCapabilities struct {
+	Subscribe   bool `json:"subscribe,omitempty"`
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
 // InitializeResult is returned from initialize.
 type InitializeResult struct {
 	ProtocolVersion string             `json:"protocolVersion"`
@@ -78,6 +85,8 @@ type Property struct {
 	Enum        []string `json:"enum,omitempty"`
 	Default     any      `json:"default,omitempty"`
 	Items       *Items   `json:"items,omitempty"`
+	// Properties describes the fields of an object-typed property.
+	Properties map[string]Property `json:"properties,omitempty"`
 }
 
 // Items describes array item schema.
@@ -107,3 +116,47 @@ type ContentBlock struct {
 	Type string `json:"type"`
 	Text string `json:"text"`
 }
+
+// Resource describes a readable MCP resource.
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceTemplate describes a parameterised resource URI.
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourcesListResult is returned from resources/list.
+type ResourcesListResult struct {
+	Resources []Resource `json:"resources"`
+}
+
+// ResourceTemplatesListResult is returned from resources/templates/list.
+type ResourceTemplatesListResult struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+}
+
+// ReadResourceParams is the params for resources/read.
+type ReadResourceParams struct {
+	URI string `json:"uri"`
+}
+
+// ReadResourceResult is returned from resources/read.
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// ResourceContents holds a resource's data: text, or base64 for binary data.
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
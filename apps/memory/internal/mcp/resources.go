@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// attachmentURIPrefix addresses memory attachments as MCP resources.
+const attachmentURIPrefix = "memory://attachments/"
+
+func (s *Server) handleResourcesList(req *Request) *Response {
+	body, _, err := s.httpGet("/attachments")
+	if err != nil {
+		return s.errorResponse(req.ID, -32603, err.Error())
+	}
+
+	var list struct {
+		Attachments []models.Attachment `json:"attachments"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return s.errorResponse(req.ID, -32603, "decode attachments: "+err.Error())
+	}
+
+	resources := make([]Resource, len(list.Attachments))
+	for i, a := range list.Attachments {
+		name := a.Filename
+		if name == "" {
+			name = a.ID
+		}
+		resources[i] = Resource{
+			URI:         attachmentURIPrefix + a.ID,
+			Name:        name,
+			Description: "Attachment on memory " + a.MemoryID,
+			MimeType:    a.ContentType,
+		}
+	}
+
+	return &Response{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result:  ResourcesListResult{Resources: resources},
+	}
+}
+
+func (s *Server) handleResourceTemplatesList(req *Request) *Response {
+	return &Response{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: ResourceTemplatesListResult{ResourceTemplates: []ResourceTemplate{{
+			URITemplate: attachmentURIPrefix + "{id}",
+			Name:        "Memory attachment",
+			Description: "An image (diagram, screenshot) attached to a memory. Attachment IDs are listed by GET /memories/{id}/attachments.",
+		}}},
+	}
+}
+
+func (s *Server) handleResourcesRead(req *Request) *Response {
+	paramsBytes, err := json.Marshal(req.Params)
+	if err != nil {
+		return s.errorResponse(req.ID, -32602, "invalid params")
+	}
+
+	var params ReadResourceParams
+	if err := json.Unmarshal(paramsBytes, &params); err != nil {
+		return s.errorResponse(req.ID, -32602, "invalid params: "+err.Error())
+	}
+
+	id, ok := strings.CutPrefix(params.URI, attachmentURIPrefix)
+	if !ok || id == "" || strings.Contains(id, "/") {
+		return s.errorResponse(req.ID, -32602, "unknown resource: "+params.URI)
+	}
+
+	data, contentType, err := s.httpGet("/attachments/" + id)
+	if err != nil {
+		return s.errorResponse(req.ID, -32603, err.Error())
+	}
+
+	return &Response{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: ReadResourceResult{Contents: []ResourceContents{{
+			URI:      params.URI,
+			MimeType: contentType,
+			Blob:     base64.StdEncoding.EncodeToString(data),
+		}}},
+	}
+}
+
+// httpGet fetches a raw response body and its content type. Error responses
+// are rendered with formatProblem.
+func (s *Server) httpGet(path string) ([]byte, string, error) {
+	resp, err := s.send(func() (*http.Request, error) {
+		req, err := http.NewRequest("GET", s.serverURL+apiPrefix+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.setHeaders(req)
+		return req, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("read error: %s", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, "", fmt.Errorf("%s", formatProblem(body))
+	}
+
+	return body, resp.Header.Get("Content-Type"), nil
+}
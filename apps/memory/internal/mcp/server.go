@@ -3,10 +3,12 @@ package mcp
 import (
 	"bufio"
 	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -14,27 +16,82 @@ import (
 
 const protocolVersion = "2024-11-05"
 
+// apiPrefix selects the memory server API version the tools are written against.
+const apiPrefix = "/v1"
+
 // Server implements an MCP stdio server that delegates to the HTTP memory server.
 type Server struct {
-	serverURL string
-	namespace string
-	client    *http.Client
+	serverURL   string
+	namespace   string
+	apiKey      string
+	client      *http.Client
+	fixturesDir string // test mode: serve canned responses, see UseFixtures
+	epicID      string // default provenance epic for memory_store
+	out         io.Writer
+	// Connectivity to the memory server, and the retry policy for calls
+	// made while it is unreachable
+	monitor       backendMonitor
+	retryAttempts int
+	retryBase     time.Duration
+	// Observations buffered per session until a batch is full or the
+	// session ends, see session_observe
+	observations map[string][]map[string]interface{}
 }
 
 // NewServer creates a new MCP server.
 func NewServer(serverURL, namespace string) *Server {
-	return &Server{
+	s := &Server{
 		serverURL: strings.TrimRight(serverURL, "/"),
 		namespace: namespace,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		retryAttempts: defaultRetryAttempts,
+		retryBase:     defaultRetryBase,
+	}
+	s.monitor.status.ServerURL = s.serverURL
+	return s
+}
+
+// SetDefaultEpic sets the epic recorded as provenance on stored memories
+// when the agent does not supply one.
+func (s *Server) SetDefaultEpic(epicID string) {
+	s.epicID = epicID
+}
+
+// SetAPIKey sets the bearer token sent with every request to the memory
+// server.
+func (s *Server) SetAPIKey(key string) {
+	s.apiKey = key
+}
+
+// setHeaders adds the namespace and bearer token to a memory server request.
+func (s *Server) setHeaders(req *http.Request) {
+	if s.namespace != "" {
+		req.Header.Set("X-Clive-Namespace", s.namespace)
+	}
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
 	}
 }
 
+// SetTLSConfig makes the server talk to the memory server over TLS with the
+// given config, e.g. to trust a self-signed certificate or present a client
+// certificate for mTLS.
+func (s *Server) SetTLSConfig(cfg *tls.Config) {
+	s.client.Transport = &http.Transport{TLSClientConfig: cfg}
+}
+
 // Run starts the stdio event loop. Blocks until stdin is closed.
 func (s *Server) Run() error {
-	scanner := bufio.NewScanner(os.Stdin)
+	return s.Serve(os.Stdin, os.Stdout)
+}
+
+// Serve runs the event loop over arbitrary streams, one JSON-RPC message
+// per line. Blocks until in is exhausted.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	scanner := bufio.NewScanner(in)
 	// Increase buffer for large messages
 	buf := make([]byte, 0, 1024*1024)
 	scanner.Buffer(buf, 1024*1024)
@@ -57,6 +114,8 @@ func (s *Server) Run() error {
 		}
 	}
 
+	// The client is gone: keep what its sessions observed
+	s.flushAllObservations()
 	return scanner.Err()
 }
 
@@ -71,6 +130,12 @@ func (s *Server) handleRequest(req *Request) *Response {
 		return s.handleToolsList(req)
 	case "tools/call":
 		return s.handleToolsCall(req)
+	case "resources/list":
+		return s.handleResourcesList(req)
+	case "resources/templates/list":
+		return s.handleResourceTemplatesList(req)
+	case "resources/read":
+		return s.handleResourcesRead(req)
 	case "ping":
 		return &Response{JSONRPC: "2.0", ID: req.ID, Result: map[string]string{}}
 	default:
@@ -89,7 +154,8 @@ func (s *Server) handleInitialize(req *Request) *Response {
 		Result: InitializeResult{
 			ProtocolVersion: protocolVersion,
 			Capabilities: ServerCapabilities{
-				Tools: &ToolCapabilities{},
+				Tools:     &ToolCapabilities{},
+				Resources: &ResourceCapabilities{},
 			},
 			ServerInfo: ServerInfo{
 				Name:    "clive-memory",
@@ -131,6 +197,10 @@ func (s *Server) handleToolsCall(req *Request) *Response {
 }
 
 func (s *Server) dispatchTool(name string, args map[string]interface{}) (string, bool) {
+	if s.fixturesDir != "" && isKnownTool(name) {
+		return s.fixtureResponse(name)
+	}
+
 	switch name {
 	case "memory_search_index":
 		return s.toolSearchIndex(args)
@@ -144,6 +214,16 @@ func (s *Server) dispatchTool(name string, args map[string]interface{}) (string,
 		return s.toolImpact(args)
 	case "memory_supersede":
 		return s.toolSupersede(args)
+	case "thread_entry_update":
+		return s.toolEntryUpdate(args)
+	case "thread_entry_delete":
+		return s.toolEntryDelete(args)
+	case "memory_status":
+		return s.toolStatus()
+	case "session_observe":
+		return s.toolObserve(args)
+	case "session_end":
+		return s.toolSessionEnd(args)
 	default:
 		return fmt.Sprintf("unknown tool: %s", name), true
 	}
@@ -160,6 +240,18 @@ func (s *Server) toolSearchIndex(args map[string]interface{}) (string, bool) {
 		"includeGlobal": getBool(args, "includeGlobal", true),
 		"searchMode":    "hybrid",
 	}
+	if getBool(args, "groupByType", false) {
+		body["groupByType"] = true
+	}
+	if caps, ok := args["typeCaps"]; ok {
+		body["typeCaps"] = caps
+	}
+	if threadID, ok := args["threadId"].(string); ok && threadID != "" {
+		body["threadId"] = threadID
+	}
+	if getBool(args, "rerank", false) {
+		body["rerank"] = true
+	}
 	return s.httpPost("/memories/search/index", body)
 }
 
@@ -180,6 +272,12 @@ func (s *Server) toolTimeline(args map[string]interface{}) (string, bool) {
 }
 
 func (s *Server) toolStore(args map[string]interface{}) (string, bool) {
+	content, _ := args["content"].(string)
+	fields, _ := args["fields"].(map[string]interface{})
+	structured := getBool(args, "structured", false)
+	if content == "" && (!structured || len(fields) == 0) {
+		return "content is required, or structured=true with fields", true
+	}
 	body := map[string]interface{}{
 		"workspace":  args["workspace"],
 		"content":    args["content"],
@@ -188,9 +286,36 @@ func (s *Server) toolStore(args map[string]interface{}) (string, bool) {
 		"tags":       args["tags"],
 		"source":     "mcp",
 	}
+	if structured {
+		body["structured"] = true
+	}
+	if len(fields) > 0 {
+		body["fields"] = fields
+	}
+	if provenance := s.provenance(args); len(provenance) > 0 {
+		body["provenance"] = provenance
+	}
+	if v, ok := args["visibility"].(string); ok && v != "" {
+		body["visibility"] = v
+	}
 	return s.httpPost("/memories", body)
 }
 
+// provenance returns the memory_store provenance argument with the default
+// epic filled in.
+func (s *Server) provenance(args map[string]interface{}) map[string]interface{} {
+	p := map[string]interface{}{}
+	if v, ok := args["provenance"].(map[string]interface{}); ok {
+		for k, val := range v {
+			p[k] = val
+		}
+	}
+	if epic, _ := p["epicId"].(string); epic == "" && s.epicID != "" {
+		p["epicId"] = s.epicID
+	}
+	return p
+}
+
 func (s *Server) toolImpact(args map[string]interface{}) (string, bool) {
 	memoryID, _ := args["memoryId"].(string)
 	body := map[string]interface{}{
@@ -208,27 +333,62 @@ func (s *Server) toolSupersede(args map[string]interface{}) (string, bool) {
 	return s.httpPost(fmt.Sprintf("/memories/%s/supersede", oldID), body)
 }
 
+func (s *Server) toolEntryUpdate(args map[string]interface{}) (string, bool) {
+	body := map[string]interface{}{}
+	for _, key := range []string{"content", "section"} {
+		if v, ok := args[key].(string); ok && v != "" {
+			body[key] = v
+		}
+	}
+	return s.httpDo("PATCH", entryPath(args), body)
+}
+
+func (s *Server) toolEntryDelete(args map[string]interface{}) (string, bool) {
+	result, isErr := s.httpDo("DELETE", entryPath(args), nil)
+	if !isErr && result == "" {
+		result = `{"deleted":true}`
+	}
+	return result, isErr
+}
+
+func entryPath(args map[string]interface{}) string {
+	threadID, _ := args["threadId"].(string)
+	entryID, _ := args["entryId"].(string)
+	return fmt.Sprintf("/threads/%s/entries/%s", url.PathEscape(threadID), url.PathEscape(entryID))
+}
+
 // --- HTTP helpers ---
 
 func (s *Server) httpPost(path string, body interface{}) (string, bool) {
-	jsonBody, err := json.Marshal(body)
-	if err != nil {
-		return fmt.Sprintf("marshal error: %s", err), true
-	}
+	return s.httpDo("POST", path, body)
+}
 
-	url := s.serverURL + path
-	req, err := http.NewRequest("POST", url, bytes.NewReader(jsonBody))
-	if err != nil {
-		return fmt.Sprintf("request error: %s", err), true
-	}
-	req.Header.Set("Content-Type", "application/json")
-	if s.namespace != "" {
-		req.Header.Set("X-Clive-Namespace", s.namespace)
+// httpDo sends body, if any, as JSON.
+func (s *Server) httpDo(method, path string, body interface{}) (string, bool) {
+	var jsonBody []byte
+	if body != nil {
+		var err error
+		if jsonBody, err = json.Marshal(body); err != nil {
+			return fmt.Sprintf("marshal error: %s", err), true
+		}
 	}
 
-	resp, err := s.client.Do(req)
+	resp, err := s.send(func() (*http.Request, error) {
+		// A retried request needs its body from the start again
+		var reqBody io.Reader
+		if jsonBody != nil {
+			reqBody = bytes.NewReader(jsonBody)
+		}
+		req, err := http.NewRequest(method, s.serverURL+apiPrefix+path, reqBody)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		s.setHeaders(req)
+		return req, nil
+	})
 	if err != nil {
-		return fmt.Sprintf("HTTP error: %s", err), true
+		return err.Error(), true
 	}
 	defer resp.Body.Close()
 
@@ -238,17 +398,30 @@ func (s *Server) httpPost(path string, body interface{}) (string, bool) {
 	}
 
 	if resp.StatusCode >= 400 {
-		return string(respBody), true
+		return formatProblem(respBody), true
 	}
 
 	return string(respBody), false
 }
 
+// formatProblem renders a problem+json error body as "code: detail" so the
+// stable error code reaches the model. Non-problem bodies pass through.
+func formatProblem(body []byte) string {
+	var p struct {
+		Code   string `json:"code"`
+		Detail string `json:"detail"`
+	}
+	if err := json.Unmarshal(body, &p); err != nil || p.Code == "" {
+		return string(body)
+	}
+	return p.Code + ": " + p.Detail
+}
+
 // --- Response helpers ---
 
 func (s *Server) writeResponse(resp *Response) {
 	data, _ := json.Marshal(resp)
-	fmt.Fprintf(os.Stdout, "%s\n", data)
+	fmt.Fprintf(s.out, "%s\n", data)
 }
 
 func (s *Server) writeError(id interface{}, code int, message string) {
@@ -1,5 +1,12 @@
 package mcp
 
+import (
+	"sort"
+	"strings"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
 // ToolDefinitions returns the MCP tool definitions for the memory server.
 func ToolDefinitions() []ToolDefinition {
 	return []ToolDefinition{
@@ -17,6 +24,12 @@ func ToolDefinitions() []ToolDefinition {
 						Default: 5},
 					"includeGlobal": {Type: "boolean", Description: "Include cross-project global memories",
 						Default: true},
+					"groupByType": {Type: "boolean", Description: "Cap results per memory type (default 3 each) and group them by type",
+						Default: false},
+					"typeCaps": {Type: "object", Description: "Per-type result caps, e.g. {\"GOTCHA\": 5, \"CONTEXT\": 1}"},
+					"threadId": {Type: "string", Description: "Active feature thread ID; its entries and linked memories rank higher"},
+					"rerank": {Type: "boolean", Description: "Reorder the best candidates with the server's cross-encoder reranker, if configured",
+						Default: false},
 				},
 				Required: []string{"workspace", "query"},
 			},
@@ -58,15 +71,31 @@ func ToolDefinitions() []ToolDefinition {
 				Type: "object",
 				Properties: map[string]Property{
 					"workspace":  {Type: "string", Description: "Absolute path to the project workspace"},
-					"content":    {Type: "string", Description: "The memory content — write as a standalone sentence with WHY, not just WHAT"},
+					"content":    {Type: "string", Description: "The memory content — write as a standalone sentence with WHY, not just WHAT. " +
+						"Required unless structured=true with fields"},
 					"memoryType": {Type: "string", Description: "Type of memory",
 						Enum: []string{"GOTCHA", "WORKING_SOLUTION", "DECISION", "PATTERN", "FAILURE", "PREFERENCE", "CONTEXT"}},
 					"confidence": {Type: "number", Description: "Confidence level 0.0-1.0 (0.9+ proven, 0.7-0.8 confident, 0.5-0.6 uncertain)",
 						Default: 0.8},
 					"tags": {Type: "array", Description: "Descriptive tags for categorization",
 						Items: &Items{Type: "string"}},
+					"structured": {Type: "boolean", Description: "Validate or build content using the memory type's template. " +
+						"Prefer this for DECISION, GOTCHA, WORKING_SOLUTION, FAILURE, and PATTERN memories",
+						Default: false},
+					"fields": {Type: "object", Description: "Template fields used with structured=true; content is built from them. " +
+						templateFieldsDescription()},
+					"provenance": {Type: "object", Description: "Optional traceability for memories written during a build. " +
+						"epicId defaults to the active epic when running under the build loop",
+						Properties: map[string]Property{
+							"taskId":    {Type: "string", Description: "Tracker ID of the task being worked on"},
+							"epicId":    {Type: "string", Description: "Tracker ID of the parent epic"},
+							"files":     {Type: "array", Description: "Files the memory relates to", Items: &Items{Type: "string"}},
+							"commitSha": {Type: "string", Description: "Commit the memory relates to"},
+						}},
+					"visibility": {Type: "string", Description: "Who may read the memory. team and private need a named API key",
+						Enum: []string{"public", "team", "private"}},
 				},
-				Required: []string{"workspace", "content", "memoryType"},
+				Required: []string{"workspace", "memoryType"},
 			},
 		},
 		{
@@ -97,5 +126,111 @@ func ToolDefinitions() []ToolDefinition {
 				Required: []string{"oldMemoryId", "newMemoryId"},
 			},
 		},
+		{
+			Name: "thread_entry_update",
+			Description: "Correct a feature thread entry: fix its content or move it to another section. " +
+				"The previous version is kept in the thread's edit log.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"threadId": {Type: "string", Description: "ID of the thread"},
+					"entryId":  {Type: "string", Description: "ID of the entry to change"},
+					"content":  {Type: "string", Description: "Replacement content"},
+					"section": {Type: "string", Description: "Section to move the entry to",
+						Enum: []string{"findings", "decisions", "architecture", "todo", "context"}},
+				},
+				Required: []string{"threadId", "entryId"},
+			},
+		},
+		{
+			Name: "thread_entry_delete",
+			Description: "Remove a wrong or duplicated feature thread entry. " +
+				"It is kept in the thread's edit log.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"threadId": {Type: "string", Description: "ID of the thread"},
+					"entryId":  {Type: "string", Description: "ID of the entry to remove"},
+				},
+				Required: []string{"threadId", "entryId"},
+			},
+		},
+		{
+			Name: "memory_status",
+			Description: "Check whether the memory server is reachable. " +
+				"Call this after a backend_unavailable error before retrying memory tools; " +
+				"while it reports connected: false, carry on without memory.",
+			InputSchema: InputSchema{
+				Type:       "object",
+				Properties: map[string]Property{},
+			},
+		},
+		{
+			Name: "session_observe",
+			Description: "Record a tool call made in this session. Observations are batched and, " +
+				"when the session ends, summarized into a session memory automatically; " +
+				"there is no need to store a summary yourself.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"sessionId": {Type: "string", Description: "ID of the current agent session"},
+					"toolName":  {Type: "string", Description: "Name of the tool that was called"},
+					"input":     {Type: "string", Description: "The tool's input, abbreviated if long"},
+					"output":    {Type: "string", Description: "The tool's output, abbreviated if long"},
+					"success": {Type: "boolean", Description: "Whether the tool call succeeded",
+						Default: true},
+				},
+				Required: []string{"sessionId", "toolName"},
+			},
+		},
+		{
+			Name: "session_end",
+			Description: "End the session: flush its recorded observations and store their summary " +
+				"as a SESSION_SUMMARY memory for future sessions.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"sessionId":  {Type: "string", Description: "ID of the session to end"},
+					"workspace":  {Type: "string", Description: "Absolute path of the project workspace"},
+					"transcript": {Type: "string", Description: "Optional session transcript to summarize with the observations"},
+				},
+				Required: []string{"sessionId", "workspace"},
+			},
+		},
+	}
+}
+
+// templateFieldsDescription lists the template fields per memory type so the
+// agent knows which keys to fill when storing structured memories.
+func templateFieldsDescription() string {
+	types := make([]string, 0, len(models.ContentTemplates))
+	for t := range models.ContentTemplates {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		tmpl := models.ContentTemplates[models.MemoryType(t)]
+		names := make([]string, 0, len(tmpl.Fields))
+		for _, f := range tmpl.Fields {
+			name := f.Name
+			if !f.Required {
+				name += "?"
+			}
+			names = append(names, name)
+		}
+		parts = append(parts, t+": "+strings.Join(names, ", "))
+	}
+	return "Fields by type (? = optional): " + strings.Join(parts, "; ")
+}
+
+// isKnownTool reports whether name is one of the tools in ToolDefinitions.
+func isKnownTool(name string) bool {
+	for _, t := range ToolDefinitions() {
+		if t.Name == name {
+			return true
+		}
 	}
+	return false
 }
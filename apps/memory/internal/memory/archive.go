@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+// SetArchive enables workspace export and import.
+func (s *Service) SetArchive(as *store.ArchiveStore) {
+	s.archive = as
+}
+
+// Export writes an archive of one workspace's memories, threads, sessions
+// and embeddings to w, holding only the memories caller may read.
+func (s *Service) Export(ctx context.Context, caller models.Caller, workspaceID string, w io.Writer) error {
+	if s.archive == nil {
+		return apperr.Conflict("archive_disabled", "export is not enabled on this server")
+	}
+	ws, err := s.workspaceStore.GetWorkspace(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	if ws == nil {
+		return apperr.NotFound("workspace_not_found", "workspace not found: %s", workspaceID)
+	}
+	return s.archive.ExportFor(ctx, caller, []string{workspaceID}, w)
+}
+
+// Import restores an archive. Long-term memories it adds are re-embedded
+// into Qdrant, usually from the archived embedding cache; one that fails
+// is left for vector reconciliation to repair.
+func (s *Service) Import(ctx context.Context, r io.Reader) (*models.ImportReport, error) {
+	if s.archive == nil {
+		return nil, apperr.Conflict("archive_disabled", "import is not enabled on this server")
+	}
+	start := time.Now()
+	report, longTerm, err := s.archive.Import(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range longTerm {
+		if ctx.Err() != nil {
+			report.ReembedFailed += len(longTerm) - report.Reembedded - report.ReembedFailed
+			break
+		}
+		m, err := s.memoryStore.GetByID(ctx, id)
+		if err == nil && m != nil {
+			err = s.Reembed(ctx, m)
+		}
+		if err != nil {
+			s.logger.Warn("re-embed imported memory failed", "id", id, "error", err)
+			report.ReembedFailed++
+			continue
+		}
+		report.Reembedded++
+	}
+
+	report.DurationMs = time.Since(start).Milliseconds()
+	return report, nil
+}
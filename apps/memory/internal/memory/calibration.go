@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+const (
+	// minCalibrationSamples is how many resolved memories a source group
+	// needs before an adjustment is suggested.
+	minCalibrationSamples = 10
+	// maxSuggestedAdjustment bounds a single suggestion, and
+	// maxAppliedAdjustment the accumulated adjustment of a group.
+	maxSuggestedAdjustment = 0.3
+	maxAppliedAdjustment   = 0.5
+)
+
+// calibrationBands are the confidence bands reported per source group.
+var calibrationBands = [][2]float64{{0, 0.5}, {0.5, 0.7}, {0.7, 0.9}, {0.9, 1}}
+
+// SetCalibration enables per-source confidence adjustments on Store and
+// the calibration report.
+func (s *Service) SetCalibration(cs *store.CalibrationStore) {
+	s.calibration = cs
+}
+
+// CalibrationReport compares stored confidence with realized outcomes for
+// each source group, across all memories or within one workspace.
+func (s *Service) CalibrationReport(ctx context.Context, workspaceID string) (*models.CalibrationReport, error) {
+	if s.calibration == nil {
+		return nil, apperr.ValidationFailed("calibration_unavailable", "confidence calibration is not enabled")
+	}
+	samples, err := s.calibration.Samples(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := s.calibration.Adjustments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byGroup := make(map[string][]store.CalibrationSample)
+	for _, c := range samples {
+		g := models.SourceGroup(c.Source)
+		byGroup[g] = append(byGroup[g], c)
+	}
+
+	report := &models.CalibrationReport{
+		Sources:     make([]models.SourceCalibration, 0, len(byGroup)),
+		MinSamples:  minCalibrationSamples,
+		GeneratedAt: time.Now().Unix(),
+	}
+	for group, groupSamples := range byGroup {
+		report.Sources = append(report.Sources, calibrate(group, groupSamples, applied[group]))
+	}
+	sort.Slice(report.Sources, func(i, j int) bool {
+		return report.Sources[i].Source < report.Sources[j].Source
+	})
+	return report, nil
+}
+
+// ApplyCalibration adds each group's suggested adjustment to the one already
+// in effect, so memories stored from then on start out calibrated.
+func (s *Service) ApplyCalibration(ctx context.Context, workspaceID string) (*models.ApplyCalibrationResponse, error) {
+	report, err := s.CalibrationReport(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.ApplyCalibrationResponse{Applied: map[string]float64{}, Report: report}
+	for i := range report.Sources {
+		src := &report.Sources[i]
+		if src.SuggestedAdjustment == 0 {
+			continue
+		}
+		adj := round2(math.Max(-maxAppliedAdjustment, math.Min(maxAppliedAdjustment, src.AppliedAdjustment+src.SuggestedAdjustment)))
+		if err := s.calibration.SetAdjustment(ctx, src.Source, adj, src.Resolved); err != nil {
+			return nil, err
+		}
+		src.AppliedAdjustment = adj
+		resp.Applied[src.Source] = adj
+	}
+
+	s.logger.Info("applied confidence calibration", "adjustments", resp.Applied)
+	return resp, nil
+}
+
+func calibrate(group string, samples []store.CalibrationSample, applied float64) models.SourceCalibration {
+	cal := models.SourceCalibration{
+		Source:            group,
+		Memories:          len(samples),
+		AppliedAdjustment: applied,
+		Buckets:           make([]models.CalibrationBucket, len(calibrationBands)),
+	}
+	for i, band := range calibrationBands {
+		cal.Buckets[i] = models.CalibrationBucket{MinConfidence: band[0], MaxConfidence: band[1]}
+	}
+
+	var confSum float64
+	bandUsed := make([]int, len(calibrationBands))
+	for _, c := range samples {
+		if !c.Used && !c.Contradicted {
+			continue
+		}
+		correct := c.Used && !c.Contradicted
+		cal.Resolved++
+		confSum += c.Confidence
+		if correct {
+			cal.Used++
+		} else {
+			cal.Contradicted++
+		}
+
+		band := len(calibrationBands) - 1
+		for i, b := range calibrationBands {
+			if c.Confidence < b[1] {
+				band = i
+				break
+			}
+		}
+		cal.Buckets[band].Memories++
+		if correct {
+			bandUsed[band]++
+		}
+	}
+	for i := range cal.Buckets {
+		if n := cal.Buckets[i].Memories; n > 0 {
+			cal.Buckets[i].Accuracy = round2(float64(bandUsed[i]) / float64(n))
+		}
+	}
+
+	if cal.Resolved == 0 {
+		cal.Note = "no memories have been used or contradicted yet"
+		return cal
+	}
+	cal.MeanConfidence = round2(confSum / float64(cal.Resolved))
+	cal.Accuracy = round2(float64(cal.Used) / float64(cal.Resolved))
+	cal.Gap = round2(cal.Accuracy - cal.MeanConfidence)
+	if cal.Resolved < minCalibrationSamples {
+		cal.Note = "not enough resolved memories to suggest an adjustment"
+		return cal
+	}
+	cal.SuggestedAdjustment = round2(math.Max(-maxSuggestedAdjustment, math.Min(maxSuggestedAdjustment, cal.Gap)))
+	return cal
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,227 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/schedule"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+// Compaction triggers recorded in the history.
+const (
+	CompactTriggerManual    = "manual"
+	CompactTriggerScheduled = "scheduled"
+)
+
+// Compactor runs compaction, records a report for every run and delivers
+// reports for scheduled runs through the notifier. Each successful run is
+// followed by a vacuum and a check of the database size against maxDBBytes.
+type Compactor struct {
+	svc        *Service
+	db         *store.DB
+	history    *store.CompactionStore
+	notifier   *ReportNotifier
+	schedule   schedule.Schedule
+	maxDBBytes int64
+	logger     *slog.Logger
+	pruners    []namedPruner
+	metrics    compactionMetrics
+
+	mu sync.Mutex // one run at a time
+}
+
+// Pruner removes data orphaned by compaction, returning how many items it
+// removed.
+type Pruner func(ctx context.Context) (int, error)
+
+type namedPruner struct {
+	name string
+	fn   Pruner
+}
+
+// NewCompactor creates a compactor. A nil schedule disables scheduled runs,
+// a nil notifier disables report delivery and a zero maxDBBytes disables the
+// size alert.
+func NewCompactor(
+	svc *Service,
+	db *store.DB,
+	history *store.CompactionStore,
+	notifier *ReportNotifier,
+	sched schedule.Schedule,
+	maxDBBytes int64,
+	logger *slog.Logger,
+) *Compactor {
+	return &Compactor{
+		svc:        svc,
+		db:         db,
+		history:    history,
+		notifier:   notifier,
+		schedule:   sched,
+		maxDBBytes: maxDBBytes,
+		logger:     logger,
+	}
+}
+
+// AddPruner registers a prune step run after each successful compaction, in
+// registration order.
+func (c *Compactor) AddPruner(name string, fn Pruner) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruners = append(c.pruners, namedPruner{name: name, fn: fn})
+}
+
+// Run compacts once and records the outcome. A failed compaction is still
+// recorded, with its error, before the error is returned.
+func (c *Compactor) Run(ctx context.Context, trigger string) (*models.CompactionReport, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	start := time.Now()
+	report := &models.CompactionReport{
+		ID:        uuid.New().String(),
+		Trigger:   trigger,
+		StartedAt: start.Unix(),
+	}
+
+	before, err := c.db.UsedBytes(ctx)
+	if err != nil {
+		c.logger.Warn("failed to measure database size", "error", err)
+	}
+
+	resp, compactErr := c.svc.Compact(ctx)
+	if compactErr != nil {
+		report.Error = compactErr.Error()
+	} else {
+		report.CompactResponse = *resp
+		c.prune(ctx)
+		if vac, err := c.db.Vacuum(context.Background()); err != nil {
+			c.logger.Warn("vacuum after compaction failed", "error", err)
+		} else {
+			report.ReclaimedBytes = vac.ReclaimedBytes
+			if vac.FullVacuum {
+				c.logger.Info("switched database to incremental auto-vacuum", "duration_ms", vac.DurationMs)
+			}
+		}
+	}
+
+	after, err := c.db.UsedBytes(ctx)
+	if err != nil {
+		c.logger.Warn("failed to measure database size", "error", err)
+	}
+	report.DBSizeBefore = before
+	report.DBSizeAfter = after
+	report.DBSizeDelta = after - before
+	report.DurationMs = time.Since(start).Milliseconds()
+
+	if err := c.history.Insert(ctx, report); err != nil {
+		c.logger.Error("failed to record compaction run", "error", err)
+	}
+	c.metrics.record(report)
+	if trigger == CompactTriggerScheduled && c.notifier != nil {
+		c.notifier.Deliver(report)
+	}
+	c.checkSize(ctx)
+
+	return report, compactErr
+}
+
+func (c *Compactor) prune(ctx context.Context) {
+	for _, p := range c.pruners {
+		n, err := p.fn(ctx)
+		if err != nil {
+			c.logger.Warn("prune step failed", "pruner", p.name, "error", err)
+			continue
+		}
+		if n > 0 {
+			c.logger.Info("pruned orphaned data", "pruner", p.name, "removed", n)
+		}
+	}
+}
+
+// DBStats reports the database size along with the alert threshold.
+func (c *Compactor) DBStats(ctx context.Context) (*models.DBStats, error) {
+	stats, err := c.db.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c.maxDBBytes > 0 {
+		stats.ThresholdBytes = c.maxDBBytes
+		stats.OverThreshold = stats.FileBytes+stats.WALBytes > c.maxDBBytes
+	}
+	return stats, nil
+}
+
+// checkSize warns, and alerts through the notifier, when the database has
+// grown past the threshold even after compaction and vacuum.
+func (c *Compactor) checkSize(ctx context.Context) {
+	if c.maxDBBytes <= 0 {
+		return
+	}
+	stats, err := c.DBStats(ctx)
+	if err != nil {
+		c.logger.Warn("failed to measure database size", "error", err)
+		return
+	}
+	if !stats.OverThreshold {
+		return
+	}
+	c.logger.Warn("database size over threshold",
+		"file_bytes", stats.FileBytes,
+		"wal_bytes", stats.WALBytes,
+		"threshold_bytes", stats.ThresholdBytes,
+	)
+	if c.notifier != nil {
+		c.notifier.Alert(stats)
+	}
+}
+
+// History returns the most recent compaction reports, newest first.
+func (c *Compactor) History(ctx context.Context, limit int) ([]*models.CompactionReport, error) {
+	return c.history.List(ctx, limit)
+}
+
+// Schedule runs compaction on the compactor's schedule until ctx is
+// cancelled.
+func (c *Compactor) Schedule(ctx context.Context) {
+	if c.schedule == nil {
+		return
+	}
+	c.logger.Info("compaction scheduled", "schedule", c.schedule.String())
+
+	for {
+		next := c.schedule.Next(time.Now())
+		if next.IsZero() {
+			c.logger.Warn("compaction schedule has no further runs", "schedule", c.schedule.String())
+			return
+		}
+		c.metrics.setNextRun(next)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			report, err := c.Run(ctx, CompactTriggerScheduled)
+			if err != nil {
+				c.logger.Error("scheduled compaction failed", "error", err)
+				continue
+			}
+			c.logger.Info("scheduled compaction complete",
+				"expired", report.Expired,
+				"forgotten_low", report.ForgottenLow,
+				"consolidated", report.Consolidated,
+				"promoted", report.Promoted,
+				"demoted", report.Demoted,
+				"impact_decayed", report.ImpactDecayed,
+				"db_size_delta", report.DBSizeDelta,
+				"duration_ms", report.DurationMs,
+			)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/metrics"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// compactionMetrics are cumulative counters and last-run gauges for this
+// compactor since the server started.
+type compactionMetrics struct {
+	mu sync.Mutex
+	// runs counts runs by trigger and then by outcome, "success" or "error"
+	runs           map[string]map[string]int64
+	expired        int64
+	promoted       int64
+	demoted        int64
+	consolidated   int64
+	forgottenLow   int64
+	impactDecayed  int64
+	reclaimedBytes int64
+
+	lastRunAt       int64 // unix seconds; 0 before the first run
+	lastSuccessAt   int64
+	lastDurationMs  int64
+	dbSizeBytes     int64 // measured after the last run
+	nextScheduledAt int64 // 0 when runs aren't scheduled
+}
+
+func (c *compactionMetrics) record(r *models.CompactionReport) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.runs == nil {
+		c.runs = map[string]map[string]int64{}
+	}
+	if c.runs[r.Trigger] == nil {
+		c.runs[r.Trigger] = map[string]int64{}
+	}
+	outcome := "success"
+	if r.Error != "" {
+		outcome = "error"
+	} else {
+		c.lastSuccessAt = r.StartedAt
+	}
+	c.runs[r.Trigger][outcome]++
+	c.expired += int64(r.Expired)
+	c.promoted += int64(r.Promoted)
+	c.demoted += int64(r.Demoted)
+	c.consolidated += int64(r.Consolidated)
+	c.forgottenLow += int64(r.ForgottenLow)
+	c.impactDecayed += int64(r.ImpactDecayed)
+	c.reclaimedBytes += r.ReclaimedBytes
+	c.lastRunAt = r.StartedAt
+	c.lastDurationMs = r.DurationMs
+	c.dbSizeBytes = r.DBSizeAfter
+}
+
+func (c *compactionMetrics) setNextRun(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextScheduledAt = t.Unix()
+}
+
+// Collect writes the compaction metrics for GET /metrics.
+func (c *Compactor) Collect(w *metrics.Writer) {
+	m := &c.metrics
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w.Family("clive_compaction_runs_total", "counter", "Compaction runs by trigger and outcome.")
+	triggers := make([]string, 0, len(m.runs))
+	for t := range m.runs {
+		triggers = append(triggers, t)
+	}
+	sort.Strings(triggers)
+	for _, t := range triggers {
+		for _, outcome := range []string{"success", "error"} {
+			w.Sample("clive_compaction_runs_total", float64(m.runs[t][outcome]), "trigger", t, "outcome", outcome)
+		}
+	}
+
+	w.Family("clive_compaction_memories_total", "counter", "Memories changed by compaction, by action.")
+	w.Sample("clive_compaction_memories_total", float64(m.expired), "action", "expired")
+	w.Sample("clive_compaction_memories_total", float64(m.promoted), "action", "promoted")
+	w.Sample("clive_compaction_memories_total", float64(m.demoted), "action", "demoted")
+	w.Sample("clive_compaction_memories_total", float64(m.consolidated), "action", "consolidated")
+	w.Sample("clive_compaction_memories_total", float64(m.forgottenLow), "action", "forgotten_low")
+	w.Sample("clive_compaction_memories_total", float64(m.impactDecayed), "action", "impact_decayed")
+
+	w.Family("clive_compaction_reclaimed_bytes_total", "counter", "File space released by post-compaction vacuums.")
+	w.Sample("clive_compaction_reclaimed_bytes_total", float64(m.reclaimedBytes))
+	w.Gauge("clive_compaction_last_run_timestamp_seconds", "When the last compaction started.", float64(m.lastRunAt))
+	w.Gauge("clive_compaction_last_success_timestamp_seconds", "When the last successful compaction started.", float64(m.lastSuccessAt))
+	w.Gauge("clive_compaction_last_duration_seconds", "How long the last compaction took.", float64(m.lastDurationMs)/1000)
+	w.Gauge("clive_compaction_next_run_timestamp_seconds", "When the next scheduled compaction runs; 0 if unscheduled.", float64(m.nextScheduledAt))
+	w.Gauge("clive_db_size_bytes", "Database size measured after the last compaction.", float64(m.dbSizeBytes))
+}
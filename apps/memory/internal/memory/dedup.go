@@ -1,6 +1,8 @@
 package memory
 
 import (
+	"context"
+
 	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
 	"github.com/iammorganparry/clive/apps/memory/internal/search"
 	"github.com/iammorganparry/clive/apps/memory/internal/store"
@@ -36,12 +38,12 @@ func NewDeduplicator(memoryStore *store.MemoryStore, threshold float64) *Dedupli
 // CheckDuplicate checks for exact hash match, exact vector duplicate, or near-duplicate.
 // - ExactDuplicateID: blocks storage (content is identical or cosine ≥ threshold)
 // - NearDuplicateID: does NOT block storage but signals a similar memory exists
-func (d *Deduplicator) CheckDuplicate(workspaceID, content string, vec []float32) (*DedupResult, error) {
+func (d *Deduplicator) CheckDuplicate(ctx context.Context, workspaceID, content string, vec []float32) (*DedupResult, error) {
 	result := &DedupResult{}
 	hash := embedding.ContentHash(content)
 
 	// Exact hash match
-	existing, err := d.memoryStore.FindByContentHash(workspaceID, hash)
+	existing, err := d.memoryStore.FindByContentHash(ctx, workspaceID, hash)
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +53,7 @@ func (d *Deduplicator) CheckDuplicate(workspaceID, content string, vec []float32
 	}
 
 	// Vector similarity check against short-term memories in same workspace
-	shortTermMems, err := d.memoryStore.GetShortTermWithEmbeddings([]string{workspaceID})
+	shortTermMems, err := d.memoryStore.GetShortTermWithEmbeddings(ctx, []string{workspaceID})
 	if err != nil {
 		return nil, err
 	}
@@ -84,8 +86,8 @@ func (d *Deduplicator) CheckDuplicate(workspaceID, content string, vec []float32
 
 // IsDuplicate is the legacy API — returns the duplicate ID or empty string.
 // Maintained for backward compatibility.
-func (d *Deduplicator) IsDuplicate(workspaceID, content string, vec []float32) (string, error) {
-	result, err := d.CheckDuplicate(workspaceID, content, vec)
+func (d *Deduplicator) IsDuplicate(ctx context.Context, workspaceID, content string, vec []float32) (string, error) {
+	result, err := d.CheckDuplicate(ctx, workspaceID, content, vec)
 	if err != nil {
 		return "", err
 	}
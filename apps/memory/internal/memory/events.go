@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/metrics"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+var eventsDropped = metrics.NewCounter("clive_memory_events_dropped_total",
+	"Memory events not delivered to a subscriber that had fallen behind.")
+
+// EventBus fans memory lifecycle events out to subscribers, such as the
+// GET /events stream. Publishing never blocks: a subscriber whose buffer
+// is full misses the event.
+type EventBus struct {
+	mu     sync.Mutex
+	subs   map[chan models.MemoryEvent]struct{}
+	closed bool
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subs: map[chan models.MemoryEvent]struct{}{}}
+}
+
+// Subscribe returns a channel of events published from now on, buffering
+// up to buffer of them, and a function that ends the subscription. The
+// channel is closed when the subscription ends or the bus is closed.
+func (b *EventBus) Subscribe(buffer int) (<-chan models.MemoryEvent, func()) {
+	ch := make(chan models.MemoryEvent, buffer)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subs[ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close ends every subscription, so streams finish when the server shuts
+// down rather than holding it open.
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for ch := range b.subs {
+		delete(b.subs, ch)
+		close(ch)
+	}
+}
+
+// Publish sends an event to every subscriber.
+func (b *EventBus) Publish(e models.MemoryEvent) {
+	if e.At == 0 {
+		e.At = time.Now().Unix()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			eventsDropped.Inc()
+		}
+	}
+}
+
+// Subscribe streams the service's memory lifecycle events. See
+// EventBus.Subscribe.
+func (s *Service) Subscribe(buffer int) (<-chan models.MemoryEvent, func()) {
+	return s.events.Subscribe(buffer)
+}
+
+// CloseEvents ends every event subscription. See EventBus.Close.
+func (s *Service) CloseEvents() {
+	s.events.Close()
+}
+
+// publish records the namespace of the event's workspace and sends it.
+func (s *Service) publish(ctx context.Context, e models.MemoryEvent) {
+	if e.Namespace == "" && e.WorkspaceID != "" {
+		if ns, err := s.workspaceStore.Namespace(ctx, e.WorkspaceID); err == nil {
+			e.Namespace = ns
+		}
+	}
+	s.events.Publish(e)
+}
@@ -1,22 +1,36 @@
 package memory
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"sort"
+	"time"
 
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
 	"github.com/iammorganparry/clive/apps/memory/internal/models"
 	"github.com/iammorganparry/clive/apps/memory/internal/search"
 	"github.com/iammorganparry/clive/apps/memory/internal/store"
 	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
 )
 
-// LifecycleManager handles TTL expiry, short->long promotion, and compaction.
+// promoteImpact is the impact score that promotes a short-term memory.
+// Long-term memories below it may be demoted once they fade.
+const promoteImpact = 0.5
+
+// LifecycleManager handles TTL expiry, short->long promotion, long->short
+// demotion, and compaction.
 type LifecycleManager struct {
 	memoryStore     *store.MemoryStore
 	qdrantClient    *vectorstore.QdrantClient
 	collMgr         *vectorstore.CollectionManager
 	minAccess       int
 	minConfidence   float64
+	notify          func(context.Context, models.MemoryEvent) // set by the Service it belongs to
+	shortTermTTL    time.Duration                             // set by the Service; how long demoted memories live
+	consolidateAt   float64                                   // set by the Service; the dedup threshold
+	demoteFaded     bool
+	mergeDuplicates bool
 	logger          *slog.Logger
 }
 
@@ -34,142 +48,331 @@ func NewLifecycleManager(
 		collMgr:       collMgr,
 		minAccess:     minAccess,
 		minConfidence: minConfidence,
+		notify:        func(context.Context, models.MemoryEvent) {},
+		consolidateAt: 0.92,
 		logger:        logger,
 	}
 }
 
-// Compact runs TTL expiry, retrievability-based cleanup, and promotion.
-// Returns counts of expired, promoted, and forgotten-low-retrievability memories.
-func (l *LifecycleManager) Compact() (expired int, promoted int, forgottenLow int, err error) {
+// SetDemotion turns on demoting faded long-term memories during compaction.
+func (l *LifecycleManager) SetDemotion(enabled bool) {
+	l.demoteFaded = enabled
+}
+
+// SetConsolidation turns on superseding short-term near-duplicates during
+// compaction.
+func (l *LifecycleManager) SetConsolidation(enabled bool) {
+	l.mergeDuplicates = enabled
+}
+
+// Compact runs TTL expiry, retrievability-based cleanup, promotion and,
+// when enabled, consolidation and demotion, and returns how many memories
+// each changed.
+func (l *LifecycleManager) Compact(ctx context.Context) (*models.CompactResponse, error) {
+	resp := &models.CompactResponse{}
+
 	// 1. Expire old short-term memories (existing TTL-based expiry)
-	n, err := l.memoryStore.DeleteExpired()
+	expiredMems, err := l.memoryStore.DeleteExpiredMemories(ctx)
 	if err != nil {
-		return 0, 0, 0, fmt.Errorf("expire memories: %w", err)
+		return resp, fmt.Errorf("expire memories: %w", err)
 	}
-	expired = int(n)
-	if expired > 0 {
-		l.logger.Info("expired memories", "count", expired)
+	for _, m := range expiredMems {
+		l.notify(ctx, models.MemoryEvent{
+			Type:        models.MemoryEventExpired,
+			MemoryID:    m.ID,
+			WorkspaceID: m.WorkspaceID,
+			Tier:        models.TierShort,
+			Visibility:  m.Visibility,
+			Owner:       m.Owner,
+			OwnerTeam:   m.OwnerTeam,
+		})
+	}
+	resp.Expired = len(expiredMems)
+	if resp.Expired > 0 {
+		l.logger.Info("expired memories", "count", resp.Expired)
 	}
 
 	// 2. Feature 1: Retrievability-based cleanup for short-term memories.
 	// Delete memories whose retrievability has dropped below 0.05 (effectively forgotten).
 	// This supplements TTL expiry — a memory may not have expired by TTL but is
 	// effectively forgotten if never accessed and stability is low.
-	shortTermMems, err := l.memoryStore.GetAllShortTerm()
+	shortTermMems, err := l.memoryStore.GetAllShortTerm(ctx)
 	if err != nil {
 		l.logger.Warn("failed to get short-term memories for retrievability cleanup", "error", err)
 	} else {
+		remaining := shortTermMems[:0]
 		for _, m := range shortTermMems {
 			retr := search.Retrievability(m.CreatedAt, m.LastAccessedAt, m.Stability)
-			if retr < 0.05 {
-				if err := l.memoryStore.Delete(m.ID); err != nil {
-					l.logger.Error("failed to delete forgotten memory", "id", m.ID, "error", err)
-					continue
-				}
-				forgottenLow++
+			if retr >= 0.05 {
+				remaining = append(remaining, m)
+				continue
+			}
+			if err := l.memoryStore.Delete(ctx, m.ID); err != nil {
+				l.logger.Error("failed to delete forgotten memory", "id", m.ID, "error", err)
+				continue
 			}
+			l.notify(ctx, models.MemoryEvent{
+				Type:        models.MemoryEventExpired,
+				MemoryID:    m.ID,
+				WorkspaceID: m.WorkspaceID,
+				MemoryType:  m.MemoryType,
+				Tier:        m.Tier,
+				Visibility:  m.Visibility,
+				Owner:       m.Owner,
+				OwnerTeam:   m.OwnerTeam,
+			})
+			resp.ForgottenLow++
 		}
-		if forgottenLow > 0 {
-			l.logger.Info("forgotten low-retrievability memories", "count", forgottenLow)
+		if resp.ForgottenLow > 0 {
+			l.logger.Info("forgotten low-retrievability memories", "count", resp.ForgottenLow)
+		}
+
+		// 3. Consolidate short-term near-duplicates into the strongest copy
+		if l.mergeDuplicates {
+			resp.Consolidated = l.consolidate(ctx, remaining)
+		}
+		if resp.Consolidated > 0 {
+			l.logger.Info("consolidated memories", "count", resp.Consolidated)
 		}
 	}
 
-	// 3. Promote eligible short-term memories to long-term
+	// 4. Promote eligible short-term memories to long-term
 	// Candidates from access count + confidence threshold
-	accessCandidates, err := l.memoryStore.GetPromotionCandidates(l.minAccess, l.minConfidence)
+	accessCandidates, err := l.memoryStore.GetPromotionCandidates(ctx, l.minAccess, l.minConfidence)
 	if err != nil {
-		return expired, 0, forgottenLow, fmt.Errorf("get promotion candidates: %w", err)
+		return resp, fmt.Errorf("get promotion candidates: %w", err)
 	}
 
 	// Candidates from high impact score
-	impactCandidates, err := l.memoryStore.GetImpactPromotionCandidates(0.5)
+	impactCandidates, err := l.memoryStore.GetImpactPromotionCandidates(ctx, promoteImpact)
 	if err != nil {
-		return expired, 0, forgottenLow, fmt.Errorf("get impact promotion candidates: %w", err)
+		return resp, fmt.Errorf("get impact promotion candidates: %w", err)
 	}
 
-	// Deduplicate candidates
+	// Deduplicate candidates, leaving out superseded memories
 	seen := make(map[string]bool)
 	var allCandidates []*models.Memory
-	for _, m := range accessCandidates {
-		if !seen[m.ID] {
-			seen[m.ID] = true
-			allCandidates = append(allCandidates, m)
-		}
-	}
-	for _, m := range impactCandidates {
-		if !seen[m.ID] {
+	for _, m := range append(accessCandidates, impactCandidates...) {
+		if !seen[m.ID] && (m.SupersededBy == nil || *m.SupersededBy == "") {
 			seen[m.ID] = true
 			allCandidates = append(allCandidates, m)
 		}
 	}
 
 	for _, m := range allCandidates {
-		if err := l.promote(m); err != nil {
+		if err := l.promote(ctx, m); err != nil {
 			l.logger.Error("failed to promote memory", "id", m.ID, "error", err)
 			continue
 		}
-		promoted++
+		resp.Promoted++
 	}
 
-	if promoted > 0 {
-		l.logger.Info("promoted memories", "count", promoted)
+	if resp.Promoted > 0 {
+		l.logger.Info("promoted memories", "count", resp.Promoted)
 	}
 
-	return expired, promoted, forgottenLow, nil
+	// 5. Demote long-term memories that have faded back to short-term, where
+	// they expire unless recalled again
+	if !l.demoteFaded {
+		return resp, nil
+	}
+	demoteCandidates, err := l.memoryStore.GetDemotionCandidates(ctx, promoteImpact)
+	if err != nil {
+		return resp, fmt.Errorf("get demotion candidates: %w", err)
+	}
+	for _, m := range demoteCandidates {
+		if search.Retrievability(m.CreatedAt, m.LastAccessedAt, m.Stability) > search.MinRetrievability {
+			continue
+		}
+		if err := l.demote(ctx, m); err != nil {
+			l.logger.Error("failed to demote memory", "id", m.ID, "error", err)
+			continue
+		}
+		resp.Demoted++
+	}
+
+	if resp.Demoted > 0 {
+		l.logger.Info("demoted memories", "count", resp.Demoted)
+	}
+
+	return resp, nil
 }
 
-func (l *LifecycleManager) promote(m *models.Memory) error {
+// consolidate supersedes each short-term memory whose embedding is within
+// the dedup threshold of a stronger one: more accessed, then more
+// impactful, more confident and newer. Only memories in the same
+// workspace, with the same access control and embedding model, are
+// compared, so consolidation never hides a memory behind one its readers
+// can't see. Thread entries, skill hints and global memories are left
+// alone. Returns how many were superseded.
+func (l *LifecycleManager) consolidate(ctx context.Context, mems []*models.Memory) int {
+	type group struct {
+		workspaceID, model, owner, ownerTeam string
+		visibility                           models.Visibility
+	}
+	groups := map[group][]*models.Memory{}
+	for _, m := range mems {
+		if len(m.Embedding) == 0 || m.ThreadID != nil || (m.SupersededBy != nil && *m.SupersededBy != "") ||
+			m.MemoryType == models.MemoryTypeSkillHint || models.IsGlobalWorkspace(m.WorkspaceID) {
+			continue
+		}
+		g := group{m.WorkspaceID, m.EmbeddingModel, m.Owner, m.OwnerTeam, m.Visibility}
+		groups[g] = append(groups[g], m)
+	}
+
+	consolidated := 0
+	for _, ms := range groups {
+		sort.SliceStable(ms, func(i, j int) bool { return stronger(ms[i], ms[j]) })
+		vecs := make([][]float32, len(ms))
+		for i, m := range ms {
+			vecs[i] = search.BytesToFloat32(m.Embedding)
+		}
+		superseded := make([]bool, len(ms))
+		for i, keep := range ms {
+			if superseded[i] {
+				continue
+			}
+			for j := i + 1; j < len(ms); j++ {
+				if superseded[j] || search.CosineSimilarity(vecs[i], vecs[j]) < l.consolidateAt {
+					continue
+				}
+				m := ms[j]
+				if err := l.memoryStore.Supersede(ctx, m.ID, keep.ID); err != nil {
+					l.logger.Error("failed to consolidate memory", "id", m.ID, "into", keep.ID, "error", err)
+					continue
+				}
+				superseded[j] = true
+				l.notify(ctx, models.MemoryEvent{
+					Type:        models.MemoryEventSuperseded,
+					MemoryID:    m.ID,
+					WorkspaceID: m.WorkspaceID,
+					MemoryType:  m.MemoryType,
+					Tier:        m.Tier,
+					RelatedID:   keep.ID,
+					Visibility:  m.Visibility,
+					Owner:       m.Owner,
+					OwnerTeam:   m.OwnerTeam,
+				})
+				consolidated++
+			}
+		}
+	}
+	return consolidated
+}
+
+// stronger reports whether a should be kept over b when they are consolidated.
+func stronger(a, b *models.Memory) bool {
+	switch {
+	case a.AccessCount != b.AccessCount:
+		return a.AccessCount > b.AccessCount
+	case a.ImpactScore != b.ImpactScore:
+		return a.ImpactScore > b.ImpactScore
+	case a.Confidence != b.Confidence:
+		return a.Confidence > b.Confidence
+	default:
+		return a.CreatedAt > b.CreatedAt
+	}
+}
+
+func (l *LifecycleManager) promote(ctx context.Context, m *models.Memory) error {
 	// Move embedding from SQLite to Qdrant
 	if len(m.Embedding) == 0 {
 		return fmt.Errorf("memory %s has no embedding to promote", m.ID)
 	}
 
-	colName, err := l.collMgr.EnsureForWorkspace(m.WorkspaceID)
+	colName, err := l.collMgr.EnsureForPoint(ctx, m.WorkspaceID, m.ID)
 	if err != nil {
-		return fmt.Errorf("ensure collection: %w", err)
+		return apperr.DependencyUnavailable("vector_store_unavailable", err, "ensure collection")
 	}
 
 	vec := search.BytesToFloat32(m.Embedding)
 	point := vectorstore.Point{
-		ID:     m.ID,
-		Vector: vec,
-		Payload: map[string]any{
-			"memory_type":     string(m.MemoryType),
-			"confidence":      m.Confidence,
-			"tags":            m.Tags,
-			"content_preview": truncate(m.Content, 200),
-			"created_at":      m.CreatedAt,
-		},
+		ID:      m.ID,
+		Vector:  vec,
+		Payload: vectorPayload(m),
 	}
 
-	if err := l.qdrantClient.Upsert(colName, []vectorstore.Point{point}); err != nil {
-		return fmt.Errorf("upsert to qdrant: %w", err)
+	if err := l.qdrantClient.Upsert(ctx, colName, []vectorstore.Point{point}); err != nil {
+		return apperr.DependencyUnavailable("vector_store_unavailable", err, "upsert to qdrant")
 	}
 
 	// Update SQLite: clear embedding, set tier to long, remove expiry
-	if err := l.memoryStore.ClearEmbedding(m.ID); err != nil {
-		return fmt.Errorf("clear embedding: %w", err)
+	if err := l.memoryStore.Promote(ctx, m.ID); err != nil {
+		return err
+	}
+
+	l.notify(ctx, models.MemoryEvent{
+		Type:        models.MemoryEventPromoted,
+		MemoryID:    m.ID,
+		WorkspaceID: m.WorkspaceID,
+		MemoryType:  m.MemoryType,
+		Tier:        models.TierLong,
+		Visibility:  m.Visibility,
+		Owner:       m.Owner,
+		OwnerTeam:   m.OwnerTeam,
+	})
+	return nil
+}
+
+// demote moves a long-term memory's vector from Qdrant back into SQLite and
+// returns the memory to the short-term tier.
+func (l *LifecycleManager) demote(ctx context.Context, m *models.Memory) error {
+	vec, err := l.collMgr.Vector(ctx, m.WorkspaceID, m.ID)
+	if err != nil {
+		return apperr.DependencyUnavailable("vector_store_unavailable", err, "get vector from qdrant")
+	}
+	if vec == nil {
+		return fmt.Errorf("memory %s has no vector to demote", m.ID)
+	}
+
+	expiresAt := time.Now().Add(l.shortTermTTL).Unix()
+	if err := l.memoryStore.Demote(ctx, m.ID, search.Float32ToBytes(vec), expiresAt); err != nil {
+		return err
 	}
-	if err := l.memoryStore.SetTier(m.ID, models.TierLong, nil); err != nil {
-		return fmt.Errorf("set tier: %w", err)
+	// SQLite is the source of truth; a point left behind is removed by reconcile
+	if err := l.collMgr.DeletePoints(ctx, m.WorkspaceID, []string{m.ID}); err != nil {
+		l.logger.Warn("failed to delete demoted memory's vector", "id", m.ID, "error", err)
 	}
 
+	l.notify(ctx, models.MemoryEvent{
+		Type:        models.MemoryEventDemoted,
+		MemoryID:    m.ID,
+		WorkspaceID: m.WorkspaceID,
+		MemoryType:  m.MemoryType,
+		Tier:        models.TierShort,
+		Visibility:  m.Visibility,
+		Owner:       m.Owner,
+		OwnerTeam:   m.OwnerTeam,
+	})
 	return nil
 }
 
 // PromoteByID explicitly promotes a specific memory from short to long term.
-func (l *LifecycleManager) PromoteByID(id string) error {
-	m, err := l.memoryStore.GetByID(id)
+func (l *LifecycleManager) PromoteByID(ctx context.Context, id string) error {
+	m, err := l.memoryStore.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("get memory: %w", err)
 	}
 	if m == nil {
-		return fmt.Errorf("memory not found: %s", id)
+		return apperr.NotFound("memory_not_found", "memory not found: %s", id)
 	}
 	if m.Tier == models.TierLong {
 		return nil // Already long-term
 	}
-	return l.promote(m)
+	return l.promote(ctx, m)
+}
+
+// vectorPayload is the Qdrant payload stored with a memory's vector.
+// workspace_id lets searches filter collections shared by workspaces.
+func vectorPayload(m *models.Memory) map[string]any {
+	return map[string]any{
+		"workspace_id":    m.WorkspaceID,
+		"memory_type":     string(m.MemoryType),
+		"confidence":      m.Confidence,
+		"tags":            m.Tags,
+		"content_preview": truncate(m.Content, 200),
+		"created_at":      m.CreatedAt,
+	}
 }
 
 func truncate(s string, maxLen int) string {
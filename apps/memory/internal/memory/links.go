@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+// Graph traversal limits. Fanout bounds the links followed from one
+// memory, strongest first; the node cap bounds the whole response.
+const (
+	MaxGraphDepth = 3
+	graphFanout   = 25
+	maxGraphNodes = 200
+)
+
+// SetLinkStore enables the typed link and graph API.
+func (s *Service) SetLinkStore(ls *store.LinkStore) {
+	s.linkStore = ls
+}
+
+// Link creates a typed link from sourceID to req.TargetID, or replaces
+// the strength of the same link. Both memories must be visible to caller.
+func (s *Service) Link(ctx context.Context, caller models.Caller, sourceID string, req *models.CreateLinkRequest) (*models.GraphEdge, error) {
+	if s.linkStore == nil {
+		return nil, apperr.Conflict("links_disabled", "memory links are not enabled on this server")
+	}
+	if !req.LinkType.IsValid() {
+		return nil, apperr.ValidationFailed("invalid_link_type", "linkType must be supports, contradicts, refines or caused_by")
+	}
+	if req.TargetID == "" {
+		return nil, apperr.ValidationFailed("missing_target", "targetId is required")
+	}
+	if req.TargetID == sourceID {
+		return nil, apperr.ValidationFailed("self_link", "a memory can't link to itself")
+	}
+	if req.Strength == 0 {
+		req.Strength = 1
+	}
+	if req.Strength < 0 || req.Strength > models.MaxLinkStrength {
+		return nil, apperr.ValidationFailed("invalid_strength", "strength must be between 0 and %g", models.MaxLinkStrength)
+	}
+	for _, id := range []string{sourceID, req.TargetID} {
+		m, err := s.GetFor(ctx, caller, id)
+		if err != nil {
+			return nil, err
+		}
+		if m == nil {
+			return nil, apperr.NotFound("memory_not_found", "memory not found: %s", id)
+		}
+	}
+
+	l, err := s.linkStore.Set(ctx, sourceID, req.TargetID, string(req.LinkType), req.Strength)
+	if err != nil {
+		return nil, err
+	}
+	return graphEdge(*l), nil
+}
+
+// Unlink removes a typed link from sourceID to targetID.
+func (s *Service) Unlink(ctx context.Context, caller models.Caller, sourceID, targetID string, linkType models.LinkType) error {
+	if s.linkStore == nil {
+		return apperr.Conflict("links_disabled", "memory links are not enabled on this server")
+	}
+	if !linkType.IsValid() {
+		return apperr.ValidationFailed("invalid_link_type", "type must be supports, contradicts, refines or caused_by")
+	}
+	m, err := s.GetFor(ctx, caller, sourceID)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		return apperr.NotFound("memory_not_found", "memory not found: %s", sourceID)
+	}
+	found, err := s.linkStore.Delete(ctx, sourceID, targetID, string(linkType))
+	if err != nil {
+		return err
+	}
+	if !found {
+		return apperr.NotFound("link_not_found", "no %s link from %s to %s", linkType, sourceID, targetID)
+	}
+	return nil
+}
+
+// Graph walks links in both directions from id, breadth first, up to
+// depth hops. Memories for which visible reports false, such as those the
+// caller can't see or outside their key's workspace, are left out along
+// with their links, so they are never traversed through.
+func (s *Service) Graph(ctx context.Context, visible func(*models.Memory) bool, id string, depth int) (*models.MemoryGraph, error) {
+	if s.linkStore == nil {
+		return nil, apperr.Conflict("links_disabled", "memory links are not enabled on this server")
+	}
+	if depth < 1 || depth > MaxGraphDepth {
+		return nil, apperr.ValidationFailed("invalid_depth", "depth must be between 1 and %d", MaxGraphDepth)
+	}
+	root, err := s.memoryStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if root == nil || !visible(root) {
+		return nil, apperr.NotFound("memory_not_found", "memory not found: %s", id)
+	}
+
+	graph := &models.MemoryGraph{
+		RootID: id,
+		Depth:  depth,
+		Nodes:  []models.GraphNode{{Memory: root, Depth: 0}},
+		Edges:  []models.GraphEdge{},
+	}
+	seen := map[string]bool{id: true}
+	hidden := map[string]bool{}
+	edges := map[int64]bool{}
+	frontier := []string{id}
+
+	for d := 1; d <= depth && len(frontier) > 0; d++ {
+		var pending []store.MemoryLink
+		var next []string
+		queued := map[string]bool{}
+		for _, from := range frontier {
+			links, err := s.linkStore.GetLinked(ctx, from, graphFanout)
+			if err != nil {
+				return nil, err
+			}
+			for _, l := range links {
+				other := l.TargetID
+				if other == from {
+					other = l.SourceID
+				}
+				if hidden[other] {
+					continue
+				}
+				pending = append(pending, l)
+				if !seen[other] && !queued[other] {
+					queued[other] = true
+					next = append(next, other)
+				}
+			}
+		}
+
+		mems, err := s.memoryStore.GetByIDs(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[string]*models.Memory, len(mems))
+		for _, m := range mems {
+			byID[m.ID] = m
+		}
+		frontier = frontier[:0]
+		for _, nid := range next {
+			m := byID[nid]
+			if m == nil || !visible(m) {
+				hidden[nid] = true
+				continue
+			}
+			if len(graph.Nodes) >= maxGraphNodes {
+				graph.Truncated = true
+				hidden[nid] = true // keep edges consistent with nodes
+				continue
+			}
+			seen[nid] = true
+			graph.Nodes = append(graph.Nodes, models.GraphNode{Memory: m, Depth: d})
+			frontier = append(frontier, nid)
+		}
+
+		for _, l := range pending {
+			if edges[l.ID] || !seen[l.SourceID] || !seen[l.TargetID] {
+				continue
+			}
+			edges[l.ID] = true
+			graph.Edges = append(graph.Edges, *graphEdge(l))
+		}
+	}
+	return graph, nil
+}
+
+func graphEdge(l store.MemoryLink) *models.GraphEdge {
+	return &models.GraphEdge{
+		SourceID:  l.SourceID,
+		TargetID:  l.TargetID,
+		LinkType:  models.LinkType(l.LinkType),
+		Strength:  l.Strength,
+		UpdatedAt: l.UpdatedAt,
+	}
+}
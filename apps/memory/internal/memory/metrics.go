@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/metrics"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+var (
+	searchDuration = metrics.NewHistogram("clive_search_duration_seconds",
+		"Search latency, including the query embedding, by search mode.",
+		metrics.DefaultLatencyBuckets, "mode")
+	// storeResults gives the dedup rate: deduplicated stores return an
+	// existing memory, near_duplicate ones are stored but flagged.
+	storeResults = metrics.NewCounter("clive_memory_stores_total",
+		"Store requests by result: stored, near_duplicate or deduplicated.", "result")
+)
+
+// Collect writes the number of memories in each tier, read at scrape time.
+func (s *Service) Collect(ctx context.Context, w *metrics.Writer) {
+	counts, err := s.memoryStore.CountByTier(ctx)
+	if err != nil {
+		s.logger.Warn("metrics: count memories by tier", "error", err)
+		return
+	}
+	w.Family("clive_memories", "gauge", "Stored memories by tier.")
+	for _, tier := range []models.Tier{models.TierShort, models.TierLong} {
+		w.Sample("clive_memories", float64(counts[tier]), "tier", string(tier))
+	}
+}
+
+// modeLabel bounds the search mode label to the known modes, since the
+// request field is free text.
+func modeLabel(mode models.SearchMode) string {
+	switch mode {
+	case "":
+		return string(models.SearchModeHybrid)
+	case models.SearchModeHybrid, models.SearchModeVector, models.SearchModeBM25:
+		return string(mode)
+	}
+	return "other"
+}
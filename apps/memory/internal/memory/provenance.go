@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// ApplyProvenance folds a store request's provenance into the fields that are
+// persisted: task, epic and commit go into the encoding context and files are
+// added to the related files. Requests without provenance are left untouched.
+func ApplyProvenance(req *models.StoreRequest) error {
+	p := req.Provenance
+	if p == nil {
+		return nil
+	}
+
+	sha := strings.ToLower(strings.TrimSpace(p.CommitSHA))
+	if sha != "" && !commitSHAPattern.MatchString(sha) {
+		return apperr.ValidationFailed("invalid_commit_sha", "commitSha must be 7-40 hex characters, got %q", p.CommitSHA)
+	}
+
+	if p.TaskID != "" || p.EpicID != "" || sha != "" {
+		if req.EncodingContext == nil {
+			req.EncodingContext = &models.EncodingContext{}
+		}
+		if p.TaskID != "" {
+			req.EncodingContext.TaskID = p.TaskID
+		}
+		if p.EpicID != "" {
+			req.EncodingContext.EpicID = p.EpicID
+		}
+		if sha != "" {
+			req.EncodingContext.CommitSHA = sha
+		}
+	}
+
+	seen := make(map[string]bool, len(req.RelatedFiles))
+	for _, f := range req.RelatedFiles {
+		seen[f] = true
+	}
+	for _, f := range p.Files {
+		if f == "" || seen[f] {
+			continue
+		}
+		seen[f] = true
+		req.RelatedFiles = append(req.RelatedFiles, f)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+// Quota overflow behaviours.
+const (
+	QuotaReject = store.QuotaReject
+	QuotaEvict  = store.QuotaEvict
+)
+
+// Quota caps what each project workspace may hold; see store.Quota.
+type Quota = store.Quota
+
+// SetQuota sets the per-workspace quota. It is enforced by the store in
+// the transaction that inserts a memory or thread entry, so a store that
+// fails evicts nothing and concurrent stores cannot both overflow.
+func (s *Service) SetQuota(q Quota) {
+	s.memoryStore.SetQuota(q, s.onEvicted)
+}
+
+// checkQuota refuses a memory of size bytes up front when it can't fit:
+// it is larger than the whole quota, or the workspace is full under the
+// reject policy. It saves embedding a memory that will be refused; the
+// insert still makes the final check.
+func (s *Service) checkQuota(ctx context.Context, workspaceID string, size int64) error {
+	q := s.memoryStore.Quota()
+	if !q.AppliesTo(workspaceID) {
+		return nil
+	}
+	if q.MaxBytes > 0 && size > q.MaxBytes {
+		return apperr.QuotaExceeded("quota_exceeded",
+			"memory is %d bytes, larger than the workspace quota of %d bytes", size, q.MaxBytes)
+	}
+	if q.Overflow == QuotaEvict {
+		return nil
+	}
+	count, bytes, err := s.memoryStore.WorkspaceUsage(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	if !q.Fits(count+1, bytes+size) {
+		return apperr.QuotaExceeded("quota_exceeded",
+			"workspace holds %d memories and %d bytes, which is at its quota", count, bytes)
+	}
+	return nil
+}
+
+// onEvicted removes the vectors of evicted long-term memories once the
+// eviction has committed.
+func (s *Service) onEvicted(ctx context.Context, evicted []store.EvictedMemory) {
+	byWorkspace := map[string][]string{}
+	for _, m := range evicted {
+		if m.Tier == models.TierLong {
+			byWorkspace[m.WorkspaceID] = append(byWorkspace[m.WorkspaceID], m.ID)
+		}
+	}
+	for workspaceID, ids := range byWorkspace {
+		if err := s.collMgr.DeletePoints(ctx, workspaceID, ids); err != nil {
+			s.logger.Warn("delete evicted vectors failed", "workspace", workspaceID, "error", err)
+		}
+	}
+	s.logger.Info("evicted memories to stay within quota", "count", len(evicted))
+}
@@ -0,0 +1,218 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
+)
+
+// rebalanceBatch is how many points a rebalance upserts per request.
+const rebalanceBatch = 256
+
+// RebalanceVectors moves long-term vectors onto the collections the
+// sharding strategy routes them to: after QDRANT_SHARDING changes, or once
+// size sharding finds a workspace has outgrown its collections. Points keep
+// their vectors, so nothing is re-embedded, and each is written to its new
+// collection before it is deleted from the old one, so an interrupted
+// rebalance loses nothing and can be rerun. Collections left empty that no
+// workspace routes to are dropped.
+//
+// Points with no live long-term memory stay where they are for reconcile
+// to remove.
+func (s *Service) RebalanceVectors(ctx context.Context, dryRun bool) (*models.VectorRebalanceReport, error) {
+	start := time.Now()
+	dimension := s.qdrantClient.Dimension()
+	strategy := s.collMgr.Strategy()
+
+	collections, err := s.qdrantClient.ListCollections(ctx)
+	if err != nil {
+		return nil, apperr.DependencyUnavailable("vector_store_unavailable", err, "list qdrant collections")
+	}
+	owned := vectorstore.OwnedCollections(collections, dimension)
+	owners, err := s.memoryStore.LiveLongTermWorkspaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.VectorRebalanceReport{
+		DryRun:    dryRun,
+		Strategy:  strategy.Name(),
+		Moves:     []models.VectorMove{},
+		Dropped:   []string{},
+		CheckedAt: start.Unix(),
+	}
+
+	// Where each point is now, and how many distinct points each
+	// workspace holds. An interrupted rebalance can leave a point in two
+	// collections.
+	located := map[string][]string{}
+	counts := map[string]int{}
+	workspaces := map[string]bool{}
+	seen := map[string]bool{}
+	for _, name := range owned {
+		if id, ok := vectorstore.WorkspaceForCollection(name, dimension); ok {
+			workspaces[id] = true
+		}
+		ids, err := s.qdrantClient.ListPointIDs(ctx, name)
+		if err != nil {
+			return nil, apperr.DependencyUnavailable("vector_store_unavailable", err, "list points in %s", name)
+		}
+		located[name] = ids
+		report.Points += len(ids)
+		for _, id := range ids {
+			ws, ok := owners[id]
+			if !ok || seen[id] {
+				continue
+			}
+			seen[id] = true
+			counts[ws]++
+			workspaces[ws] = true
+		}
+	}
+
+	// Each workspace's route, with the shard count its size calls for
+	routes := map[string]vectorstore.Route{}
+	routed := map[string]bool{}
+	splitter, splits := strategy.(vectorstore.Splitter)
+	for ws := range workspaces {
+		r, err := s.collMgr.Route(ctx, ws)
+		if err != nil {
+			return nil, apperr.DependencyUnavailable("vector_store_unavailable", err, "route workspace %s", ws)
+		}
+		if splits {
+			r.Shards = splitter.ShardCount(counts[ws])
+		}
+		routes[ws] = r
+		for _, name := range strategy.Collections(r) {
+			routed[name] = true
+		}
+	}
+
+	// Points whose routed collection is not the one holding them
+	moves := map[string]map[string]string{} // from -> point ID -> to
+	moved := map[models.VectorMove]int{}
+	for _, from := range owned {
+		for _, id := range located[from] {
+			ws, ok := owners[id]
+			if !ok {
+				report.Skipped++
+				continue
+			}
+			to := strategy.CollectionFor(routes[ws], id)
+			if to == from {
+				continue
+			}
+			if moves[from] == nil {
+				moves[from] = map[string]string{}
+			}
+			moves[from][id] = to
+			moved[models.VectorMove{From: from, To: to}]++
+			report.Moved++
+		}
+	}
+	for move, n := range moved {
+		move.Points = n
+		report.Moves = append(report.Moves, move)
+	}
+	sort.Slice(report.Moves, func(i, j int) bool {
+		a, b := report.Moves[i], report.Moves[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+
+	if !dryRun {
+		if splits {
+			for ws, r := range routes {
+				s.collMgr.SetShards(ws, r.Shards)
+				if _, err := s.collMgr.EnsureForWorkspace(ctx, ws); err != nil {
+					return nil, apperr.DependencyUnavailable("vector_store_unavailable", err, "ensure shards of workspace %s", ws)
+				}
+			}
+		}
+		for _, from := range owned {
+			if len(moves[from]) == 0 {
+				continue
+			}
+			if err := s.movePoints(ctx, from, moves[from], owners); err != nil {
+				return nil, apperr.DependencyUnavailable("vector_store_unavailable", err, "move points out of %s", from)
+			}
+		}
+	}
+
+	for _, name := range owned {
+		if routed[name] || len(located[name]) != len(moves[name]) {
+			continue
+		}
+		if !dryRun {
+			if err := s.collMgr.DropCollection(ctx, name); err != nil {
+				return nil, apperr.DependencyUnavailable("vector_store_unavailable", err, "drop collection %s", name)
+			}
+		}
+		report.Dropped = append(report.Dropped, name)
+	}
+	report.DurationMs = time.Since(start).Milliseconds()
+
+	if report.Moved > 0 || len(report.Dropped) > 0 {
+		s.logger.Info("rebalanced vectors",
+			"strategy", report.Strategy,
+			"moved", report.Moved,
+			"dropped", len(report.Dropped),
+			"dry_run", dryRun,
+		)
+	}
+	return report, nil
+}
+
+// movePoints copies the given points out of a collection into their target
+// collections, then deletes them from it.
+func (s *Service) movePoints(ctx context.Context, from string, targets map[string]string, owners map[string]string) error {
+	batches := map[string][]vectorstore.Point{}
+	upsert := func(to string) error {
+		if len(batches[to]) == 0 {
+			return nil
+		}
+		err := s.qdrantClient.Upsert(ctx, to, batches[to])
+		batches[to] = batches[to][:0]
+		return err
+	}
+
+	var copied []string
+	err := s.qdrantClient.ScrollPoints(ctx, from, func(p vectorstore.Point) error {
+		to, ok := targets[p.ID]
+		if !ok {
+			return nil
+		}
+		if _, err := s.collMgr.EnsureForPoint(ctx, owners[p.ID], p.ID); err != nil {
+			return err
+		}
+		// Points stored before collections could be shared lack it
+		if p.Payload == nil {
+			p.Payload = map[string]any{}
+		}
+		p.Payload["workspace_id"] = owners[p.ID]
+		batches[to] = append(batches[to], p)
+		copied = append(copied, p.ID)
+		if len(batches[to]) >= rebalanceBatch {
+			return upsert(to)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for to := range batches {
+		if err := upsert(to); err != nil {
+			return err
+		}
+	}
+	if len(copied) == 0 {
+		return nil
+	}
+	return s.qdrantClient.DeletePoints(ctx, from, copied)
+}
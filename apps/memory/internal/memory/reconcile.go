@@ -0,0 +1,211 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
+)
+
+// ReconcileVectors diffs every workspace's live long-term memories against
+// the points in the Qdrant collections its sharding strategy routes it to.
+// Unless dryRun is set, points with no live memory (deleted or superseded,
+// where the cleanup call failed) are removed and memories missing a point
+// are re-embedded into Qdrant.
+//
+// A failure in one collection is recorded on its drift entry and does not
+// stop the others; only failing to enumerate collections is an error.
+func (s *Service) ReconcileVectors(ctx context.Context, dryRun bool) (*models.VectorReconcileReport, error) {
+	start := time.Now()
+
+	collections, err := s.qdrantClient.ListCollections(ctx)
+	if err != nil {
+		return nil, apperr.DependencyUnavailable("vector_store_unavailable", err, "list qdrant collections")
+	}
+	workspaceIDs, err := s.memoryStore.LongTermWorkspaceIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Workspaces with a collection, plus those whose collection is gone
+	// entirely but still hold long-term memories.
+	exists := map[string]bool{}
+	targets := map[string]bool{}
+	for _, name := range collections {
+		exists[name] = true
+		if id, ok := vectorstore.WorkspaceForCollection(name, s.qdrantClient.Dimension()); ok {
+			targets[id] = true
+		}
+	}
+	for _, id := range workspaceIDs {
+		targets[id] = true
+	}
+	ordered := make([]string, 0, len(targets))
+	for id := range targets {
+		ordered = append(ordered, id)
+	}
+	sort.Strings(ordered)
+
+	report := &models.VectorReconcileReport{
+		DryRun:      dryRun,
+		Collections: []models.VectorDrift{},
+		CheckedAt:   start.Unix(),
+	}
+	for _, wsID := range ordered {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		drift := s.reconcileWorkspace(ctx, wsID, exists, dryRun)
+		report.Orphans += drift.Orphans
+		report.Missing += drift.Missing
+		report.Deleted += drift.Deleted
+		report.Reupserted += drift.Reupserted
+		report.Collections = append(report.Collections, drift)
+	}
+	report.DurationMs = time.Since(start).Milliseconds()
+
+	if report.Orphans > 0 || report.Missing > 0 {
+		s.logger.Info("vector store drift",
+			"orphans", report.Orphans,
+			"missing", report.Missing,
+			"deleted", report.Deleted,
+			"reupserted", report.Reupserted,
+			"dry_run", dryRun,
+		)
+	}
+	return report, nil
+}
+
+func (s *Service) reconcileWorkspace(ctx context.Context, wsID string, exists map[string]bool, dryRun bool) models.VectorDrift {
+	drift := models.VectorDrift{WorkspaceID: wsID}
+	names, err := s.collMgr.Collections(ctx, wsID)
+	if err != nil {
+		drift.Error = err.Error()
+		return drift
+	}
+	drift.Collection = names[0]
+	if len(names) > 1 {
+		drift.Shards = len(names)
+	}
+	var filter []string
+	if s.collMgr.Strategy().Shared() {
+		filter = []string{wsID}
+	}
+	var present []string
+	for _, name := range names {
+		if exists[name] {
+			present = append(present, name)
+		}
+	}
+
+	// Read SQLite before Qdrant: a memory promoted in between then shows
+	// up as an orphan candidate, which the re-check below clears, rather
+	// than as a missing point that would be embedded twice.
+	live, err := s.memoryStore.LiveLongTermIDs(ctx, wsID)
+	if err != nil {
+		drift.Error = err.Error()
+		return drift
+	}
+	drift.LongTerm = len(live)
+
+	var points []string
+	for _, name := range present {
+		ids, err := s.qdrantClient.ListPointIDs(ctx, name, filter...)
+		if err != nil {
+			drift.Error = err.Error()
+			return drift
+		}
+		points = append(points, ids...)
+	}
+	drift.Points = len(points)
+
+	inQdrant := make(map[string]bool, len(points))
+	var orphans []string
+	for _, id := range points {
+		inQdrant[id] = true
+		if !live[id] {
+			orphans = append(orphans, id)
+		}
+	}
+	var missing []string
+	for id := range live {
+		if !inQdrant[id] {
+			missing = append(missing, id)
+		}
+	}
+	sort.Strings(missing)
+	drift.Orphans, drift.Missing = len(orphans), len(missing)
+	if dryRun {
+		return drift
+	}
+
+	if len(orphans) > 0 {
+		stale, err := s.stillOrphaned(ctx, orphans)
+		for _, name := range present {
+			if err != nil || len(stale) == 0 {
+				break
+			}
+			err = s.qdrantClient.DeletePoints(ctx, name, stale)
+		}
+		if err != nil {
+			drift.Error = err.Error()
+		} else {
+			drift.Deleted = len(stale)
+		}
+	}
+
+	if len(missing) > 0 {
+		mems, err := s.memoryStore.GetByIDs(ctx, missing)
+		if err != nil {
+			drift.Error = err.Error()
+			return drift
+		}
+		for _, m := range mems {
+			if m.Tier != models.TierLong || (m.SupersededBy != nil && *m.SupersededBy != "") {
+				continue
+			}
+			if err := s.Reembed(ctx, m); err != nil {
+				drift.Error = err.Error()
+				continue
+			}
+			drift.Reupserted++
+		}
+	}
+	return drift
+}
+
+// stillOrphaned re-reads orphan candidates and keeps those that are still
+// not live long-term memories, so a promotion that finished during the
+// diff does not lose its fresh point.
+func (s *Service) stillOrphaned(ctx context.Context, ids []string) ([]string, error) {
+	mems, err := s.memoryStore.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	live := map[string]bool{}
+	for _, m := range mems {
+		if m.Tier == models.TierLong && (m.SupersededBy == nil || *m.SupersededBy == "") {
+			live[m.ID] = true
+		}
+	}
+	var stale []string
+	for _, id := range ids {
+		if !live[id] {
+			stale = append(stale, id)
+		}
+	}
+	return stale, nil
+}
+
+// PruneVectors repairs vector store drift as a compaction prune step,
+// returning how many points it deleted or re-upserted.
+func (s *Service) PruneVectors(ctx context.Context) (int, error) {
+	report, err := s.ReconcileVectors(ctx, false)
+	if err != nil {
+		return 0, err
+	}
+	return report.Deleted + report.Reupserted, nil
+}
@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// ReportMail configures email delivery of compaction reports.
+type ReportMail struct {
+	Addr     string // SMTP server host:port
+	Username string // optional; enables PLAIN auth
+	Password string
+	From     string
+	To       []string
+}
+
+// ReportNotifier delivers compaction reports to a webhook and/or by email.
+type ReportNotifier struct {
+	webhookURL string
+	mail       ReportMail
+	client     *http.Client
+	logger     *slog.Logger
+}
+
+// NewReportNotifier returns a notifier, or nil when no delivery channel is
+// configured.
+func NewReportNotifier(webhookURL string, mail ReportMail, logger *slog.Logger) *ReportNotifier {
+	if webhookURL == "" && (mail.Addr == "" || len(mail.To) == 0) {
+		return nil
+	}
+	return &ReportNotifier{
+		webhookURL: webhookURL,
+		mail:       mail,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		logger:     logger,
+	}
+}
+
+// Deliver sends the report to every configured channel. Failures are logged,
+// not returned, so delivery never fails a compaction.
+func (n *ReportNotifier) Deliver(r *models.CompactionReport) {
+	n.send("compaction report", FormatCompactionReport(r), "report", r)
+}
+
+// Alert sends a database size alert to every configured channel.
+func (n *ReportNotifier) Alert(stats *models.DBStats) {
+	n.send("size alert", FormatSizeAlert(stats), "dbStats", stats)
+}
+
+func (n *ReportNotifier) send(kind, text, key string, payload any) {
+	if n.webhookURL != "" {
+		if err := n.postWebhook(text, key, payload); err != nil {
+			n.logger.Warn("failed to deliver "+kind+" webhook", "error", err)
+		}
+	}
+	if n.mail.Addr != "" && len(n.mail.To) > 0 {
+		if err := n.sendMail(text); err != nil {
+			n.logger.Warn("failed to deliver "+kind+" email", "error", err)
+		}
+	}
+}
+
+// postWebhook posts {"text": ..., key: payload}. The text field lets the
+// message render directly in Slack-compatible incoming webhooks.
+func (n *ReportNotifier) postWebhook(text, key string, payload any) error {
+	body, err := json.Marshal(map[string]any{"text": text, key: payload})
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(n.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (n *ReportNotifier) sendMail(text string) error {
+	var auth smtp.Auth
+	if n.mail.Username != "" {
+		host, _, err := net.SplitHostPort(n.mail.Addr)
+		if err != nil {
+			return fmt.Errorf("smtp addr: %w", err)
+		}
+		auth = smtp.PlainAuth("", n.mail.Username, n.mail.Password, host)
+	}
+
+	subject := strings.SplitN(text, "\n", 2)[0]
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		n.mail.From, strings.Join(n.mail.To, ", "), subject, strings.ReplaceAll(text, "\n", "\r\n"))
+	return smtp.SendMail(n.mail.Addr, auth, n.mail.From, n.mail.To, []byte(msg))
+}
+
+// FormatCompactionReport renders a report as plain text. The first line is a
+// short headline suitable for an email subject.
+func FormatCompactionReport(r *models.CompactionReport) string {
+	var sb strings.Builder
+	started := time.Unix(r.StartedAt, 0).UTC().Format("2006-01-02 15:04 UTC")
+	fmt.Fprintf(&sb, "Memory compaction (%s) %s\n", r.Trigger, started)
+	if r.Error != "" {
+		fmt.Fprintf(&sb, "FAILED: %s\n", r.Error)
+	}
+	fmt.Fprintf(&sb, "Expired: %d\n", r.Expired)
+	fmt.Fprintf(&sb, "Forgotten (low retrievability): %d\n", r.ForgottenLow)
+	fmt.Fprintf(&sb, "Consolidated into near-duplicates: %d\n", r.Consolidated)
+	fmt.Fprintf(&sb, "Promoted to long-term: %d\n", r.Promoted)
+	fmt.Fprintf(&sb, "Demoted to short-term: %d\n", r.Demoted)
+	if r.ImpactDecayed > 0 {
+		fmt.Fprintf(&sb, "Impact scores decayed: %d\n", r.ImpactDecayed)
+	}
+	fmt.Fprintf(&sb, "Database size: %s -> %s (%s)\n",
+		formatBytes(r.DBSizeBefore), formatBytes(r.DBSizeAfter), formatBytesDelta(r.DBSizeDelta))
+	if r.ReclaimedBytes > 0 {
+		fmt.Fprintf(&sb, "Reclaimed by vacuum: %s\n", formatBytes(r.ReclaimedBytes))
+	}
+	fmt.Fprintf(&sb, "Took %dms", r.DurationMs)
+	return sb.String()
+}
+
+// FormatSizeAlert renders a database size alert as plain text, headline first.
+func FormatSizeAlert(s *models.DBStats) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Memory database over size threshold: %s of %s\n",
+		formatBytes(s.FileBytes+s.WALBytes), formatBytes(s.ThresholdBytes))
+	fmt.Fprintf(&sb, "Live data: %s\n", formatBytes(s.UsedBytes))
+	fmt.Fprintf(&sb, "Free pages: %s\n", formatBytes(s.FreeBytes))
+	fmt.Fprintf(&sb, "WAL: %s", formatBytes(s.WALBytes))
+	return sb.String()
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+func formatBytesDelta(n int64) string {
+	if n < 0 {
+		return "-" + formatBytes(-n)
+	}
+	return "+" + formatBytes(n)
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// retagSampleSize caps the changes listed in a retag response.
+const retagSampleSize = 20
+
+// Retag adds and removes tags on every memory matching the request's
+// filter that the caller may read. A dry run reports what would change
+// without writing anything.
+func (s *Service) Retag(ctx context.Context, req *models.RetagRequest) (*models.RetagResponse, error) {
+	add, remove := cleanTags(req.Add), cleanTags(req.Remove)
+	if len(add) == 0 && len(remove) == 0 {
+		return nil, apperr.ValidationFailed("empty_retag", "add or remove is required")
+	}
+	for _, tag := range add {
+		if slices.Contains(remove, tag) {
+			return nil, apperr.ValidationFailed("conflicting_tags", "tag %q is both added and removed", tag)
+		}
+	}
+	f := &req.Filter
+	if f.WorkspaceID == "" && len(f.MemoryTypes) == 0 && f.Tier == "" && f.Source == "" && len(f.Tags) == 0 {
+		return nil, apperr.ValidationFailed("empty_filter", "filter must set at least one of workspaceId, memoryTypes, tier, source or tags")
+	}
+
+	matched, changes, err := s.memoryStore.Retag(ctx, f, func(tags []string) []string {
+		return editTags(tags, add, remove)
+	}, req.DryRun)
+	if err != nil {
+		return nil, fmt.Errorf("retag memories: %w", err)
+	}
+
+	sample := changes
+	if len(sample) > retagSampleSize {
+		sample = sample[:retagSampleSize]
+	}
+	if sample == nil {
+		sample = []models.RetagChange{}
+	}
+	return &models.RetagResponse{
+		Matched: matched,
+		Changed: len(changes),
+		DryRun:  req.DryRun,
+		Sample:  sample,
+	}, nil
+}
+
+// editTags drops the removed tags, then appends the added ones that are not
+// already present. The order of the remaining tags is kept.
+func editTags(tags, add, remove []string) []string {
+	out := make([]string, 0, len(tags)+len(add))
+	for _, tag := range tags {
+		if !slices.Contains(remove, tag) {
+			out = append(out, tag)
+		}
+	}
+	for _, tag := range add {
+		if !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// cleanTags trims tags and drops empty and repeated ones.
+func cleanTags(tags []string) []string {
+	var out []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/search"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+// MaxCurveDays is the longest retrievability projection served.
+const MaxCurveDays = 90
+
+// RetrievabilityCurve projects a memory's retrievability over the next days.
+// The plain curve assumes no further access. The reinforced curve replays the
+// memory's observed access rate (at most one access per day), applying
+// store.StabilityBoost on each access as UpdateStabilityOnAccess does.
+func (s *Service) RetrievabilityCurve(ctx context.Context, id string, days int) (*models.RetrievabilityCurve, error) {
+	if days < 0 || days > MaxCurveDays {
+		return nil, apperr.ValidationFailed("invalid_days", "days must be between 0 and %d, got %d", MaxCurveDays, days)
+	}
+
+	m, err := s.memoryStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, apperr.NotFound("memory_not_found", "memory not found: %s", id)
+	}
+
+	now := time.Now().Unix()
+	refTime := m.CreatedAt
+	if m.LastAccessedAt != nil && *m.LastAccessedAt > 0 {
+		refTime = *m.LastAccessedAt
+	}
+	sinceAccess := math.Max(0, float64(now-refTime)/86400.0)
+
+	curve := &models.RetrievabilityCurve{
+		MemoryID:        m.ID,
+		Tier:            m.Tier,
+		Stability:       m.Stability,
+		AccessCount:     m.AccessCount,
+		Current:         search.RetrievabilityAfter(sinceAccess, m.Stability),
+		ForgetThreshold: search.MinRetrievability,
+		Points:          make([]models.RetrievabilityPoint, 0, days+1),
+	}
+
+	// Day at which the unreinforced curve reaches the floor
+	forgetAt := int(math.Ceil(stabilityOrDefault(m.Stability)*math.Log(1/search.MinRetrievability) - sinceAccess))
+	if forgetAt < 0 {
+		forgetAt = 0
+	}
+	if forgetAt <= days {
+		curve.ForgottenInDays = &forgetAt
+	}
+
+	// Reinforcement simulation state
+	interval := 0.0
+	if m.AccessCount > 0 {
+		ageDays := float64(now-m.CreatedAt) / 86400.0
+		interval = math.Max(1, ageDays/float64(m.AccessCount))
+		curve.AccessIntervalDays = interval
+	}
+	stability := stabilityOrDefault(m.Stability)
+	lastAccess := -sinceAccess
+	nextAccess := lastAccess + interval
+	impact := m.ImpactScore
+	if m.ImpactUpdatedAt != nil {
+		impact = store.ImpactAfter(impact, float64(now-*m.ImpactUpdatedAt)/86400.0, s.impactHalfLife)
+	}
+	boost := store.StabilityBoost(impact)
+
+	for d := 0; d <= days; d++ {
+		p := models.RetrievabilityPoint{
+			Day:            d,
+			Retrievability: search.RetrievabilityAfter(sinceAccess+float64(d), m.Stability),
+		}
+		if interval > 0 {
+			for nextAccess <= float64(d) {
+				stability = math.Min(store.MaxStability, stability*boost)
+				lastAccess = nextAccess
+				nextAccess += interval
+			}
+			p.Reinforced = search.RetrievabilityAfter(float64(d)-lastAccess, stability)
+		}
+		curve.Points = append(curve.Points, p)
+	}
+
+	return curve, nil
+}
+
+// stabilityOrDefault mirrors the default applied by search.RetrievabilityAfter.
+func stabilityOrDefault(stability float64) float64 {
+	if stability <= 0 {
+		return 5.0
+	}
+	return stability
+}
@@ -1,12 +1,15 @@
 package memory
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
 	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
 	"github.com/iammorganparry/clive/apps/memory/internal/models"
 	"github.com/iammorganparry/clive/apps/memory/internal/privacy"
@@ -15,6 +18,14 @@ import (
 	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
 )
 
+// defaultTypeCap is the per-type result cap used when grouping by type
+// without explicit caps.
+const defaultTypeCap = 3
+
+// vectorCleanupTimeout bounds deleting the vector of a store that failed
+// after it was written, which outlives the request that wrote it.
+const vectorCleanupTimeout = 5 * time.Second
+
 // Service is the main facade for all memory operations.
 type Service struct {
 	memoryStore    *store.MemoryStore
@@ -27,6 +38,12 @@ type Service struct {
 	dedup          *Deduplicator
 	lifecycle      *LifecycleManager
 	shortTermTTL   time.Duration
+	impactHalfLife float64 // days; 0 disables impact decay
+	threadStore    *store.ThreadStore // resolves threadId search anchors
+	calibration    *store.CalibrationStore
+	archive        *store.ArchiveStore
+	linkStore      *store.LinkStore
+	events         *EventBus
 	logger         *slog.Logger
 }
 
@@ -44,7 +61,7 @@ func NewService(
 	shortTermTTLHours int,
 	logger *slog.Logger,
 ) *Service {
-	return &Service{
+	s := &Service{
 		memoryStore:    memoryStore,
 		workspaceStore: workspaceStore,
 		bm25Store:      bm25Store,
@@ -55,18 +72,78 @@ func NewService(
 		dedup:          dedup,
 		lifecycle:      lifecycle,
 		shortTermTTL:   time.Duration(shortTermTTLHours) * time.Hour,
+		events:         NewEventBus(),
 		logger:         logger,
 	}
+	lifecycle.notify = s.publish
+	lifecycle.shortTermTTL = s.shortTermTTL
+	if dedup != nil {
+		lifecycle.consolidateAt = dedup.threshold
+	}
+	return s
+}
+
+// SetImpactHalfLife sets the half-life, in days, over which impact scores
+// decay. Compaction persists the decay, search applies it at query time and
+// new signals are added to the decayed score. 0 disables decay.
+func (s *Service) SetImpactHalfLife(days float64) {
+	s.impactHalfLife = days
+	s.searcher.SetImpactHalfLife(days)
+}
+
+// SetThreadStore enables thread-anchored search.
+func (s *Service) SetThreadStore(ts *store.ThreadStore) {
+	s.threadStore = ts
+}
+
+// threadAnchors returns the memory IDs of a thread's entries.
+func (s *Service) threadAnchors(ctx context.Context, threadID string) ([]string, error) {
+	if s.threadStore == nil {
+		return nil, apperr.ValidationFailed("threads_unavailable", "thread-anchored search is not enabled")
+	}
+	thread, err := s.threadStore.GetThread(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+	if thread == nil {
+		return nil, apperr.NotFound("thread_not_found", "thread not found: %s", threadID)
+	}
+	entries, err := s.threadStore.GetEntries(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.MemoryID
+	}
+	return ids, nil
 }
 
 // Store creates a new memory with dedup, embedding, and cognitive science fields.
-func (s *Service) Store(req *models.StoreRequest) (*models.StoreResponse, error) {
+// Nothing is written once ctx is done.
+func (s *Service) Store(ctx context.Context, req *models.StoreRequest) (*models.StoreResponse, error) {
+	// Structured content is rendered from its fields before it is checked
+	if err := ApplyTemplate(req); err != nil {
+		return nil, err
+	}
+	if err := ApplyProvenance(req); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, apperr.ValidationFailed("content_required", "content is required")
+	}
+
 	// Privacy filter: strip <private>...</private> blocks before processing
 	if privacy.HasOnlyPrivateContent(req.Content) {
 		return &models.StoreResponse{Skipped: true, SkipReason: "content_private"}, nil
 	}
 	req.Content = privacy.StripPrivateTags(req.Content)
 
+	visibility, err := storeVisibility(req)
+	if err != nil {
+		return nil, err
+	}
+
 	// Determine workspace
 	namespace := req.Namespace
 	if namespace == "" {
@@ -75,7 +152,7 @@ func (s *Service) Store(req *models.StoreRequest) (*models.StoreResponse, error)
 
 	workspaceID := store.NamespacedGlobalID(namespace)
 	if !req.Global && req.Workspace != "" {
-		id, err := s.workspaceStore.EnsureWorkspace(namespace, req.Workspace)
+		id, err := s.workspaceStore.EnsureWorkspace(ctx, namespace, req.Workspace)
 		if err != nil {
 			return nil, fmt.Errorf("ensure workspace: %w", err)
 		}
@@ -83,19 +160,35 @@ func (s *Service) Store(req *models.StoreRequest) (*models.StoreResponse, error)
 	}
 
 	// Generate embedding
-	vec, err := s.embedder.Embed(req.Content)
+	vec, err := s.embedder.Embed(ctx, req.Content)
 	if err != nil {
-		return nil, fmt.Errorf("embed content: %w", err)
+		return nil, apperr.DependencyUnavailable("embedding_unavailable", err, "embed content")
 	}
 
 	// Dedup check (Feature 3: enhanced with near-duplicate detection)
-	dedupResult, err := s.dedup.CheckDuplicate(workspaceID, req.Content, vec)
+	dedupResult, err := s.dedup.CheckDuplicate(ctx, workspaceID, req.Content, vec)
 	if err != nil {
 		s.logger.Warn("dedup check failed", "error", err)
 		dedupResult = &DedupResult{} // continue with empty result
 	}
-	if dedupResult.ExactDuplicateID != "" {
-		return &models.StoreResponse{ID: dedupResult.ExactDuplicateID, Deduplicated: true}, nil
+	// A match the caller can't read must not be handed back as their memory
+	if dup := s.hideDuplicates(ctx, req.Caller, dedupResult); dup != nil {
+		storeResults.Inc("deduplicated")
+		s.publish(ctx, models.MemoryEvent{
+			Type:        models.MemoryEventDeduplicated,
+			MemoryID:    dup.ID,
+			WorkspaceID: workspaceID,
+			Namespace:   namespace,
+			MemoryType:  req.MemoryType,
+			Visibility:  dup.Visibility,
+			Owner:       dup.Owner,
+			OwnerTeam:   dup.OwnerTeam,
+		})
+		return &models.StoreResponse{ID: dup.ID, Deduplicated: true}, nil
+	}
+
+	if err := s.checkQuota(ctx, workspaceID, int64(len(req.Content))); err != nil {
+		return nil, err
 	}
 
 	// Set defaults
@@ -107,6 +200,9 @@ func (s *Service) Store(req *models.StoreRequest) (*models.StoreResponse, error)
 	if confidence == 0 {
 		confidence = 0.8
 	}
+	if s.calibration != nil {
+		confidence = s.calibration.Adjust(ctx, req.Source, confidence)
+	}
 
 	now := time.Now().Unix()
 	id := uuid.New().String()
@@ -131,13 +227,16 @@ func (s *Service) Store(req *models.StoreRequest) (*models.StoreResponse, error)
 		SessionID:       req.SessionID,
 		ContentHash:     contentHash,
 		RelatedFiles:    req.RelatedFiles,
-		EmbeddingModel:  "nomic-embed-text",
+		EmbeddingModel:  s.embedder.Model(),
 		CreatedAt:       now,
 		UpdatedAt:       now,
 		Stability:       stability,
 		LastAccessedAt:  &now,
 		EncodingContext: req.EncodingContext,
 		CompletionStatus: req.CompletionStatus,
+		Visibility:      visibility,
+		Owner:           req.Caller.Name,
+		OwnerTeam:       req.Caller.Team,
 	}
 
 	if tier == models.TierShort {
@@ -147,64 +246,147 @@ func (s *Service) Store(req *models.StoreRequest) (*models.StoreResponse, error)
 		mem.ExpiresAt = &expiresAt
 	} else {
 		// Long-term: store embedding in Qdrant
-		colName, err := s.collMgr.EnsureForWorkspace(workspaceID)
+		colName, err := s.collMgr.EnsureForPoint(ctx, workspaceID, id)
 		if err != nil {
-			return nil, fmt.Errorf("ensure qdrant collection: %w", err)
+			return nil, apperr.DependencyUnavailable("vector_store_unavailable", err, "ensure qdrant collection")
 		}
 
 		point := vectorstore.Point{
-			ID:     id,
-			Vector: vec,
-			Payload: map[string]any{
-				"memory_type":     string(req.MemoryType),
-				"confidence":      confidence,
-				"tags":            req.Tags,
-				"content_preview": truncate(req.Content, 200),
-				"created_at":      now,
-			},
-		}
-		if err := s.qdrantClient.Upsert(colName, []vectorstore.Point{point}); err != nil {
-			return nil, fmt.Errorf("upsert to qdrant: %w", err)
+			ID:      id,
+			Vector:  vec,
+			Payload: vectorPayload(mem),
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := s.qdrantClient.Upsert(ctx, colName, []vectorstore.Point{point}); err != nil {
+			return nil, apperr.DependencyUnavailable("vector_store_unavailable", err, "upsert to qdrant")
 		}
 		// No embedding or expiry in SQLite for long-term
 	}
 
-	if err := s.memoryStore.Insert(mem); err != nil {
+	err = ctx.Err()
+	var evicted []store.EvictedMemory
+	if err == nil {
+		evicted, err = s.memoryStore.InsertWithinQuota(ctx, mem)
+	}
+	if err != nil {
+		// Don't leave a vector behind for a memory that was never stored,
+		// even when that is because the request was cancelled
+		if tier == models.TierLong {
+			cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), vectorCleanupTimeout)
+			if derr := s.collMgr.DeletePoints(cleanupCtx, workspaceID, []string{id}); derr != nil {
+				s.logger.Warn("failed to delete orphaned vector", "id", id, "error", derr)
+			}
+			cancel()
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		return nil, fmt.Errorf("insert memory: %w", err)
 	}
 
-	resp := &models.StoreResponse{ID: id, Deduplicated: false}
+	resp := &models.StoreResponse{ID: id, Deduplicated: false, Evicted: len(evicted)}
 
 	// Feature 3: Include near-duplicate info in response
 	if dedupResult.NearDuplicateID != "" {
 		resp.NearDuplicateID = dedupResult.NearDuplicateID
 		resp.NearDupSimilarity = dedupResult.NearDupSimilarity
-	}
+		storeResults.Inc("near_duplicate")
+	} else {
+		storeResults.Inc("stored")
+	}
+	s.publish(ctx, models.MemoryEvent{
+		Type:        models.MemoryEventStored,
+		MemoryID:    id,
+		WorkspaceID: workspaceID,
+		Namespace:   namespace,
+		MemoryType:  mem.MemoryType,
+		Tier:        tier,
+		Visibility:  mem.Visibility,
+		Owner:       mem.Owner,
+		OwnerTeam:   mem.OwnerTeam,
+	})
 
 	return resp, nil
 }
 
+// storeVisibility validates the requested label. Team and private memories
+// need an owner to be readable at all, so anonymous callers may only store
+// public ones.
+func storeVisibility(req *models.StoreRequest) (models.Visibility, error) {
+	v := req.Visibility
+	if v == "" {
+		return models.VisibilityPublic, nil
+	}
+	if !v.IsValid() {
+		return "", apperr.ValidationFailed("invalid_visibility", "visibility must be public, team or private")
+	}
+	if v != models.VisibilityPublic && req.Caller.Anonymous() {
+		return "", apperr.ValidationFailed("acl_requires_identity", "%s memories need a named API key", v)
+	}
+	if v == models.VisibilityTeam && req.Caller.Team == "" {
+		return "", apperr.ValidationFailed("acl_requires_team", "team memories need an API key with a team")
+	}
+	return v, nil
+}
+
+// hideDuplicates drops dedup matches the caller can't read, and returns
+// the exact duplicate if one is left.
+func (s *Service) hideDuplicates(ctx context.Context, caller models.Caller, r *DedupResult) *models.Memory {
+	visible := func(id string) (*models.Memory, bool) {
+		m, err := s.memoryStore.GetByID(ctx, id)
+		return m, err == nil && m != nil && caller.CanSee(m)
+	}
+	var exact *models.Memory
+	if r.ExactDuplicateID != "" {
+		if m, ok := visible(r.ExactDuplicateID); ok {
+			exact = m
+		} else {
+			r.ExactDuplicateID = ""
+		}
+	}
+	if r.NearDuplicateID != "" {
+		if _, ok := visible(r.NearDuplicateID); !ok {
+			r.NearDuplicateID = ""
+			r.NearDupSimilarity = 0
+		}
+	}
+	return exact
+}
+
 // Supersede marks an old memory as superseded by a new one (Feature 3).
-func (s *Service) Supersede(oldID, newID string) (*models.SupersedeResponse, error) {
+func (s *Service) Supersede(ctx context.Context, oldID, newID string) (*models.SupersedeResponse, error) {
 	// Verify both memories exist
-	oldMem, err := s.memoryStore.GetByID(oldID)
+	oldMem, err := s.memoryStore.GetByID(ctx, oldID)
 	if err != nil {
 		return nil, err
 	}
 	if oldMem == nil {
-		return nil, fmt.Errorf("old memory not found: %s", oldID)
+		return nil, apperr.NotFound("memory_not_found", "old memory not found: %s", oldID)
 	}
-	newMem, err := s.memoryStore.GetByID(newID)
+	newMem, err := s.memoryStore.GetByID(ctx, newID)
 	if err != nil {
 		return nil, err
 	}
 	if newMem == nil {
-		return nil, fmt.Errorf("new memory not found: %s", newID)
+		return nil, apperr.NotFound("memory_not_found", "new memory not found: %s", newID)
 	}
 
-	if err := s.memoryStore.Supersede(oldID, newID); err != nil {
+	if err := s.memoryStore.Supersede(ctx, oldID, newID); err != nil {
 		return nil, fmt.Errorf("supersede: %w", err)
 	}
+	s.publish(ctx, models.MemoryEvent{
+		Type:        models.MemoryEventSuperseded,
+		MemoryID:    oldID,
+		WorkspaceID: oldMem.WorkspaceID,
+		MemoryType:  oldMem.MemoryType,
+		Tier:        oldMem.Tier,
+		RelatedID:   newID,
+		Visibility:  oldMem.Visibility,
+		Owner:       oldMem.Owner,
+		OwnerTeam:   oldMem.OwnerTeam,
+	})
 
 	return &models.SupersedeResponse{
 		SupersededID: oldID,
@@ -212,16 +394,59 @@ func (s *Service) Supersede(oldID, newID string) (*models.SupersedeResponse, err
 	}, nil
 }
 
+// EmbeddingModel returns the model new embeddings are generated with.
+func (s *Service) EmbeddingModel() string {
+	return s.embedder.Model()
+}
+
+// Reembed regenerates a memory's embedding with the current model, writing
+// the vector to SQLite (short-term) or Qdrant (long-term).
+func (s *Service) Reembed(ctx context.Context, m *models.Memory) error {
+	vec, err := s.embedder.Embed(ctx, m.Content)
+	if err != nil {
+		return apperr.DependencyUnavailable("embedding_unavailable", err, "embed memory %s", m.ID)
+	}
+
+	var blob []byte
+	if m.Tier == models.TierShort {
+		blob = search.Float32ToBytes(vec)
+	} else {
+		colName, err := s.collMgr.EnsureForPoint(ctx, m.WorkspaceID, m.ID)
+		if err != nil {
+			return apperr.DependencyUnavailable("vector_store_unavailable", err, "ensure qdrant collection")
+		}
+		point := vectorstore.Point{
+			ID:      m.ID,
+			Vector:  vec,
+			Payload: vectorPayload(m),
+		}
+		if err := s.qdrantClient.Upsert(ctx, colName, []vectorstore.Point{point}); err != nil {
+			return apperr.DependencyUnavailable("vector_store_unavailable", err, "upsert to qdrant")
+		}
+	}
+
+	if err := s.memoryStore.SetEmbedding(ctx, m.ID, blob, s.embedder.Model()); err != nil {
+		return fmt.Errorf("set embedding: %w", err)
+	}
+	return nil
+}
+
 // Search performs hybrid search.
-func (s *Service) Search(req *models.SearchRequest) (*models.SearchResponse, error) {
+func (s *Service) Search(ctx context.Context, req *models.SearchRequest) (*models.SearchResponse, error) {
+	start := time.Now()
+	defer func() { searchDuration.Observe(time.Since(start).Seconds(), modeLabel(req.SearchMode)) }()
+
 	namespace := req.Namespace
 	if namespace == "" {
 		namespace = "default"
 	}
+	if req.Rerank && !s.searcher.CanRerank() {
+		return nil, apperr.Conflict("rerank_disabled", "re-ranking is not enabled on this server; set RERANK_MODEL")
+	}
 
 	workspaceIDs := []string{}
 	if req.Workspace != "" {
-		id, err := s.workspaceStore.EnsureWorkspace(namespace, req.Workspace)
+		id, err := s.workspaceStore.EnsureWorkspace(ctx, namespace, req.Workspace)
 		if err != nil {
 			return nil, fmt.Errorf("ensure workspace: %w", err)
 		}
@@ -234,10 +459,19 @@ func (s *Service) Search(req *models.SearchRequest) (*models.SearchResponse, err
 		return &models.SearchResponse{Results: []models.SearchResult{}}, nil
 	}
 
+	var anchorIDs []string
+	if req.ThreadID != "" {
+		ids, err := s.threadAnchors(ctx, req.ThreadID)
+		if err != nil {
+			return nil, err
+		}
+		anchorIDs = ids
+	}
+
 	// Embed query
-	vec, err := s.embedder.Embed(req.Query)
+	vec, err := s.embedder.Embed(ctx, req.Query)
 	if err != nil {
-		return nil, fmt.Errorf("embed query: %w", err)
+		return nil, apperr.DependencyUnavailable("embedding_unavailable", err, "embed query")
 	}
 
 	maxResults := req.MaxResults
@@ -256,9 +490,16 @@ func (s *Service) Search(req *models.SearchRequest) (*models.SearchResponse, err
 		Tier:           req.Tier,
 		SearchMode:     req.SearchMode,
 		SessionContext: req.SessionContext,
+		TypeCaps:       req.TypeCaps,
+		AnchorIDs:      anchorIDs,
+		Caller:         req.Caller,
+		Rerank:         req.Rerank,
+	}
+	if req.GroupByType {
+		params.DefaultTypeCap = defaultTypeCap
 	}
 
-	results, vectorCount, bm25Count, dur, err := s.searcher.Search(params)
+	results, vectorCount, bm25Count, dur, err := s.searcher.Search(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("search: %w", err)
 	}
@@ -279,10 +520,13 @@ func (s *Service) Search(req *models.SearchRequest) (*models.SearchResponse, err
 			Stability:      r.Memory.Stability,
 			LastAccessedAt: r.Memory.LastAccessedAt,
 			Retrievability: r.Retrievability,
+			ThreadAnchored: r.Anchored,
+			Snippet:        r.Snippet,
+			RerankScore:    r.RerankScore,
 		}
 	}
 
-	return &models.SearchResponse{
+	resp := &models.SearchResponse{
 		Results: searchResults,
 		Meta: models.SearchMeta{
 			TotalResults:  len(searchResults),
@@ -290,12 +534,40 @@ func (s *Service) Search(req *models.SearchRequest) (*models.SearchResponse, err
 			BM25Results:   bm25Count,
 			SearchTimeMs:  int(dur.Milliseconds()),
 		},
-	}, nil
+	}
+	for _, r := range searchResults {
+		if r.RerankScore != nil {
+			resp.Meta.Reranked = true
+			break
+		}
+	}
+	if req.GroupByType {
+		resp.Groups = groupByType(searchResults)
+	}
+	return resp, nil
+}
+
+// groupByType buckets result IDs by memory type. Groups are ordered by their
+// best-scoring result, so the most relevant type comes first.
+func groupByType(results []models.SearchResult) []models.SearchGroup {
+	var groups []models.SearchGroup
+	index := make(map[models.MemoryType]int)
+	for _, r := range results {
+		i, ok := index[r.MemoryType]
+		if !ok {
+			i = len(groups)
+			index[r.MemoryType] = i
+			groups = append(groups, models.SearchGroup{MemoryType: r.MemoryType})
+		}
+		groups[i].IDs = append(groups[i].IDs, r.ID)
+		groups[i].Count++
+	}
+	return groups
 }
 
 // SearchIndex performs a search and returns compact index results (Layer 1 of progressive disclosure).
-func (s *Service) SearchIndex(req *models.SearchRequest) (*models.SearchIndexResponse, error) {
-	fullResp, err := s.Search(req)
+func (s *Service) SearchIndex(ctx context.Context, req *models.SearchRequest) (*models.SearchIndexResponse, error) {
+	fullResp, err := s.Search(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -311,24 +583,27 @@ func (s *Service) SearchIndex(req *models.SearchRequest) (*models.SearchIndexRes
 			Tags:           r.Tags,
 			ImpactScore:    r.ImpactScore,
 			ContentPreview: truncate(r.Content, 80),
+			Snippet:        r.Snippet,
 			CreatedAt:      r.CreatedAt,
+			ThreadAnchored: r.ThreadAnchored,
 		}
 	}
 
 	return &models.SearchIndexResponse{
 		Results: indexResults,
+		Groups:  fullResp.Groups,
 		Meta:    fullResp.Meta,
 	}, nil
 }
 
 // Timeline returns chronological context around a memory (Layer 2 of progressive disclosure).
-func (s *Service) Timeline(req *models.TimelineRequest) (*models.TimelineResponse, error) {
-	anchor, err := s.memoryStore.GetByID(req.MemoryID)
+func (s *Service) Timeline(ctx context.Context, req *models.TimelineRequest) (*models.TimelineResponse, error) {
+	anchor, err := s.memoryStore.GetByID(ctx, req.MemoryID)
 	if err != nil {
 		return nil, fmt.Errorf("get anchor: %w", err)
 	}
-	if anchor == nil {
-		return nil, fmt.Errorf("memory not found: %s", req.MemoryID)
+	if anchor == nil || !req.Caller.CanSee(anchor) {
+		return nil, apperr.NotFound("memory_not_found", "memory not found: %s", req.MemoryID)
 	}
 
 	windowMinutes := req.WindowMinutes
@@ -340,29 +615,32 @@ func (s *Service) Timeline(req *models.TimelineRequest) (*models.TimelineRespons
 		maxResults = 5
 	}
 
-	before, after, err := s.memoryStore.GetTimelineAround(req.MemoryID, windowMinutes, maxResults)
+	before, after, err := s.memoryStore.GetTimelineAround(ctx, req.MemoryID, windowMinutes, maxResults)
 	if err != nil {
 		return nil, fmt.Errorf("get timeline: %w", err)
 	}
 
 	return &models.TimelineResponse{
 		Anchor: anchor,
-		Before: before,
-		After:  after,
+		Before: visibleTo(req.Caller, before),
+		After:  visibleTo(req.Caller, after),
 	}, nil
 }
 
 // BatchGet retrieves full content for specific memory IDs (Layer 3 of progressive disclosure).
-func (s *Service) BatchGet(req *models.BatchGetRequest) (*models.BatchGetResponse, error) {
+func (s *Service) BatchGet(ctx context.Context, req *models.BatchGetRequest) (*models.BatchGetResponse, error) {
 	if len(req.IDs) == 0 {
 		return &models.BatchGetResponse{Memories: []*models.Memory{}}, nil
 	}
 
-	memories, err := s.memoryStore.GetByIDs(req.IDs)
+	memories, err := s.memoryStore.GetByIDs(ctx, req.IDs)
 	if err != nil {
 		return nil, fmt.Errorf("batch get: %w", err)
 	}
 
+	// Hidden memories are reported as missing, so their IDs don't leak
+	memories = visibleTo(req.Caller, memories)
+
 	// Build set of found IDs to determine missing
 	found := make(map[string]bool, len(memories))
 	for _, m := range memories {
@@ -381,11 +659,16 @@ func (s *Service) BatchGet(req *models.BatchGetRequest) (*models.BatchGetRespons
 	}, nil
 }
 
-// BulkStore stores multiple memories in a batch.
-func (s *Service) BulkStore(req *models.BulkStoreRequest) (*models.BulkStoreResponse, error) {
+// BulkStore stores multiple memories in a batch. It stops at the first item
+// after ctx is done; memories stored before that are kept, and the counts
+// so far are returned along with ctx's error.
+func (s *Service) BulkStore(ctx context.Context, req *models.BulkStoreRequest) (*models.BulkStoreResponse, error) {
 	resp := &models.BulkStoreResponse{}
 
 	for _, bm := range req.Memories {
+		if err := ctx.Err(); err != nil {
+			return resp, err
+		}
 		storeReq := &models.StoreRequest{
 			Namespace:  req.Namespace,
 			Workspace:  req.Workspace,
@@ -397,10 +680,18 @@ func (s *Service) BulkStore(req *models.BulkStoreRequest) (*models.BulkStoreResp
 			Source:     bm.Source,
 			SessionID:  req.SessionID,
 			Global:     bm.Global,
+			Caller:     req.Caller,
+			RelatedFiles: bm.RelatedFiles,
+			Structured:   bm.Structured,
+			Fields:       bm.Fields,
+			Provenance:   bm.Provenance,
 		}
 
-		result, err := s.Store(storeReq)
+		result, err := s.Store(ctx, storeReq)
 		if err != nil {
+			if ctx.Err() != nil {
+				return resp, ctx.Err()
+			}
 			s.logger.Error("bulk store item failed", "error", err)
 			resp.Failed++
 			continue
@@ -416,75 +707,100 @@ func (s *Service) BulkStore(req *models.BulkStoreRequest) (*models.BulkStoreResp
 }
 
 // Compact runs lifecycle management.
-func (s *Service) Compact() (*models.CompactResponse, error) {
-	expired, promoted, forgottenLow, err := s.lifecycle.Compact()
+func (s *Service) Compact(ctx context.Context) (*models.CompactResponse, error) {
+	resp, err := s.lifecycle.Compact(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &models.CompactResponse{
-		Expired:      expired,
-		Promoted:     promoted,
-		ForgottenLow: forgottenLow,
-	}, nil
+	resp.ImpactDecayed, err = s.memoryStore.DecayImpact(ctx, s.impactHalfLife)
+	if err != nil {
+		return nil, fmt.Errorf("decay impact: %w", err)
+	}
+	return resp, nil
 }
 
 // GetByID retrieves a memory by ID.
-func (s *Service) GetByID(id string) (*models.Memory, error) {
-	return s.memoryStore.GetByID(id)
+func (s *Service) GetByID(ctx context.Context, id string) (*models.Memory, error) {
+	return s.memoryStore.GetByID(ctx, id)
+}
+
+// GetFor retrieves a memory the caller may read. A memory hidden from the
+// caller is reported as missing.
+func (s *Service) GetFor(ctx context.Context, caller models.Caller, id string) (*models.Memory, error) {
+	m, err := s.memoryStore.GetByID(ctx, id)
+	if err != nil || m == nil || !caller.CanSee(m) {
+		return nil, err
+	}
+	return m, nil
+}
+
+// visibleTo filters memories down to those the caller may read.
+func visibleTo(caller models.Caller, memories []*models.Memory) []*models.Memory {
+	out := memories[:0]
+	for _, m := range memories {
+		if caller.CanSee(m) {
+			out = append(out, m)
+		}
+	}
+	return out
 }
 
 // Update applies partial updates to a memory.
-func (s *Service) Update(id string, req *models.UpdateRequest) (*models.Memory, error) {
+func (s *Service) Update(ctx context.Context, id string, req *models.UpdateRequest) (*models.Memory, error) {
 	// If promoting to long-term, use lifecycle manager
 	if req.Tier != nil && *req.Tier == models.TierLong {
-		existing, err := s.memoryStore.GetByID(id)
+		existing, err := s.memoryStore.GetByID(ctx, id)
 		if err != nil {
 			return nil, err
 		}
 		if existing != nil && existing.Tier == models.TierShort {
-			if err := s.lifecycle.PromoteByID(id); err != nil {
+			if err := s.lifecycle.PromoteByID(ctx, id); err != nil {
 				return nil, fmt.Errorf("promote: %w", err)
 			}
 		}
 	}
-	return s.memoryStore.Update(id, req)
+	return s.memoryStore.Update(ctx, id, req)
 }
 
 // Delete removes a memory and its Qdrant vector if applicable.
-func (s *Service) Delete(id string) error {
-	mem, err := s.memoryStore.GetByID(id)
+func (s *Service) Delete(ctx context.Context, id string) error {
+	mem, err := s.memoryStore.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 	if mem == nil {
-		return fmt.Errorf("memory not found: %s", id)
+		return apperr.NotFound("memory_not_found", "memory not found: %s", id)
 	}
 
 	// Remove from Qdrant if long-term
 	if mem.Tier == models.TierLong {
-		colName := vectorstore.CollectionName(mem.WorkspaceID)
-		_ = s.qdrantClient.DeletePoints(colName, []string{id})
+		_ = s.collMgr.DeletePoints(ctx, mem.WorkspaceID, []string{id})
 	}
 
-	return s.memoryStore.Delete(id)
+	return s.memoryStore.Delete(ctx, id)
 }
 
 // GetWorkspaceStats returns statistics for a workspace.
-func (s *Service) GetWorkspaceStats(workspaceID string) (*models.WorkspaceStats, error) {
-	ws, err := s.workspaceStore.GetWorkspace(workspaceID)
+func (s *Service) GetWorkspaceStats(ctx context.Context, workspaceID string) (*models.WorkspaceStats, error) {
+	ws, err := s.workspaceStore.GetWorkspace(ctx, workspaceID)
 	if err != nil {
 		return nil, err
 	}
 	if ws == nil {
-		return nil, fmt.Errorf("workspace not found: %s", workspaceID)
+		return nil, apperr.NotFound("workspace_not_found", "workspace not found: %s", workspaceID)
+	}
+
+	total, shortTerm, longTerm, byType, err := s.memoryStore.CountByWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, err
 	}
 
-	total, shortTerm, longTerm, byType, err := s.memoryStore.CountByWorkspace(workspaceID)
+	_, contentBytes, err := s.memoryStore.WorkspaceUsage(ctx, workspaceID)
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.WorkspaceStats{
+	stats := &models.WorkspaceStats{
 		WorkspaceID:    ws.ID,
 		WorkspaceName:  ws.Name,
 		WorkspacePath:  ws.Path,
@@ -493,12 +809,23 @@ func (s *Service) GetWorkspaceStats(workspaceID string) (*models.WorkspaceStats,
 		LongTermCount:  longTerm,
 		ByType:         byType,
 		LastAccessedAt: ws.LastAccessedAt,
-	}, nil
+		ContentBytes:   contentBytes,
+	}
+	if q := s.memoryStore.Quota(); q.AppliesTo(workspaceID) {
+		stats.Quota = &models.QuotaUsage{
+			Memories:    total,
+			MaxMemories: q.MaxMemories,
+			Bytes:       contentBytes,
+			MaxBytes:    q.MaxBytes,
+			Overflow:    q.Overflow,
+		}
+	}
+	return stats, nil
 }
 
 // List returns a paginated list of memories with filtering and sorting.
-func (s *Service) List(req *models.ListRequest) (*models.ListResponse, error) {
-	memories, total, err := s.memoryStore.List(req)
+func (s *Service) List(ctx context.Context, req *models.ListRequest) (*models.ListResponse, error) {
+	memories, total, err := s.memoryStore.List(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("list memories: %w", err)
 	}
@@ -529,21 +856,21 @@ func (s *Service) List(req *models.ListRequest) (*models.ListResponse, error) {
 }
 
 // ListWorkspaces returns all registered workspaces.
-func (s *Service) ListWorkspaces() ([]models.Workspace, error) {
-	return s.workspaceStore.ListWorkspaces()
+func (s *Service) ListWorkspaces(ctx context.Context) ([]models.Workspace, error) {
+	return s.workspaceStore.ListWorkspaces(ctx)
 }
 
 // RecordImpact records an impact signal on a memory and optionally auto-promotes.
-func (s *Service) RecordImpact(id string, req *models.RecordImpactRequest) (*models.RecordImpactResponse, error) {
-	mem, err := s.memoryStore.GetByID(id)
+func (s *Service) RecordImpact(ctx context.Context, id string, req *models.RecordImpactRequest) (*models.RecordImpactResponse, error) {
+	mem, err := s.memoryStore.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	if mem == nil {
-		return nil, fmt.Errorf("memory not found: %s", id)
+		return nil, apperr.NotFound("memory_not_found", "memory not found: %s", id)
 	}
 
-	score, err := s.memoryStore.RecordImpact(id, req.Signal, req.Source, req.SessionID)
+	score, err := s.memoryStore.RecordImpact(ctx, id, req.Signal, req.Source, req.SessionID, s.impactHalfLife)
 	if err != nil {
 		return nil, fmt.Errorf("record impact: %w", err)
 	}
@@ -554,7 +881,7 @@ func (s *Service) RecordImpact(id string, req *models.RecordImpactRequest) (*mod
 
 	// Auto-promote if signal is "promoted" and memory is short-term
 	if req.Signal == models.SignalPromoted && mem.Tier == models.TierShort {
-		if err := s.lifecycle.PromoteByID(id); err != nil {
+		if err := s.lifecycle.PromoteByID(ctx, id); err != nil {
 			s.logger.Error("auto-promote failed", "id", id, "error", err)
 		} else {
 			resp.Promoted = true
@@ -564,12 +891,63 @@ func (s *Service) RecordImpact(id string, req *models.RecordImpactRequest) (*mod
 	return resp, nil
 }
 
+// defaultImpactBatchSize is how many memories RecalculateImpact rewrites per
+// transaction when the caller does not choose.
+const defaultImpactBatchSize = 500
+
+// RecalculateImpact rebuilds every memory's impact score from its impact
+// event log with the current SignalDeltas, batchSize memories per
+// transaction. progress, if set, is called after each batch. Batches
+// already written are kept if ctx is cancelled part way.
+func (s *Service) RecalculateImpact(ctx context.Context, batchSize int, progress func(models.RecalculateImpactProgress)) (*models.RecalculateImpactProgress, error) {
+	if batchSize <= 0 {
+		batchSize = defaultImpactBatchSize
+	}
+	start := time.Now()
+	total, err := s.memoryStore.CountMemories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p := models.RecalculateImpactProgress{Total: total}
+	afterID := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		lastID, scanned, changed, err := s.memoryStore.RecalculateImpactBatch(ctx, afterID, batchSize, s.impactHalfLife)
+		if err != nil {
+			return nil, err
+		}
+		if scanned == 0 {
+			break
+		}
+		afterID = lastID
+		p.Scanned += scanned
+		p.Changed += changed
+		p.Batches++
+		p.DurationMs = time.Since(start).Milliseconds()
+		s.logger.Info("recalculated impact batch", "batch", p.Batches, "scanned", p.Scanned, "total", p.Total, "changed", p.Changed)
+		if progress != nil {
+			progress(p)
+		}
+		if scanned < batchSize {
+			break
+		}
+	}
+
+	p.Done = true
+	p.DurationMs = time.Since(start).Milliseconds()
+	return &p, nil
+}
+
 // GetImpactEvents returns the impact audit trail for a memory.
-func (s *Service) GetImpactEvents(id string) ([]models.ImpactEvent, error) {
-	return s.memoryStore.GetImpactEvents(id)
+func (s *Service) GetImpactEvents(ctx context.Context, id string) ([]models.ImpactEvent, error) {
+	return s.memoryStore.GetImpactEvents(ctx, id)
 }
 
-// GetImpactLeaders returns the top memories by impact score.
-func (s *Service) GetImpactLeaders(workspaceID string, limit int) ([]*models.Memory, error) {
-	return s.memoryStore.GetImpactLeaders(workspaceID, limit)
+// GetImpactLeaders returns the top memories by impact score that the
+// caller may read.
+func (s *Service) GetImpactLeaders(ctx context.Context, caller models.Caller, workspaceID string, limit int) ([]*models.Memory, error) {
+	return s.memoryStore.GetImpactLeaders(ctx, caller, workspaceID, limit)
 }
@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"strings"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// ApplyTemplate enforces the content template for structured store requests.
+// When fields are supplied, the content is rendered from them in template
+// order. Otherwise the existing content must contain a "Label:" line for
+// every required field. Non-structured requests are left untouched, but may
+// not carry fields, which would otherwise be dropped.
+func ApplyTemplate(req *models.StoreRequest) error {
+	if !req.Structured {
+		if len(req.Fields) > 0 {
+			return apperr.ValidationFailed("fields_require_structured", "fields are only used with structured=true")
+		}
+		return nil
+	}
+
+	tmpl, ok := models.ContentTemplates[req.MemoryType]
+	if !ok {
+		return apperr.ValidationFailed("template_not_found", "no content template for memoryType %s", req.MemoryType)
+	}
+
+	if len(req.Fields) > 0 {
+		content, err := RenderTemplate(tmpl, req.Fields)
+		if err != nil {
+			return err
+		}
+		req.Content = content
+		return nil
+	}
+
+	var missing []string
+	lower := strings.ToLower(req.Content)
+	for _, f := range tmpl.Fields {
+		if f.Required && !strings.Contains(lower, strings.ToLower(f.Label)+":") {
+			missing = append(missing, f.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return apperr.ValidationFailed("template_sections_missing", "structured %s content is missing sections: %s",
+			req.MemoryType, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// RenderTemplate builds "Label: value" content from template fields.
+// Unknown field names are rejected so typos don't silently drop content.
+func RenderTemplate(tmpl models.ContentTemplate, fields map[string]string) (string, error) {
+	known := make(map[string]bool, len(tmpl.Fields))
+	for _, f := range tmpl.Fields {
+		known[f.Name] = true
+	}
+	for name := range fields {
+		if !known[name] {
+			return "", apperr.ValidationFailed("template_field_unknown", "unknown field %q for memoryType %s", name, tmpl.MemoryType)
+		}
+	}
+
+	var lines []string
+	var missing []string
+	for _, f := range tmpl.Fields {
+		value := strings.TrimSpace(fields[f.Name])
+		if value == "" {
+			if f.Required {
+				missing = append(missing, f.Name)
+			}
+			continue
+		}
+		lines = append(lines, f.Label+": "+value)
+	}
+	if len(missing) > 0 {
+		return "", apperr.ValidationFailed("template_fields_missing", "missing required fields for memoryType %s: %s",
+			tmpl.MemoryType, strings.Join(missing, ", "))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
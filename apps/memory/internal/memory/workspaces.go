@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
+)
+
+// MergeWorkspaces folds path-based workspaces into a remote-based workspace.
+// Long-term vectors are re-homed into the target Qdrant collection before the
+// SQLite rows move, so a failed embed or upsert leaves the source intact.
+func (s *Service) MergeWorkspaces(ctx context.Context, req *models.WorkspaceMergeRequest) (*models.WorkspaceMergeResponse, error) {
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	targetID, identity, err := s.workspaceStore.EnsureRemoteWorkspace(ctx, namespace, req.Remote, req.Subdir)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.WorkspaceMergeResponse{
+		WorkspaceID: targetID,
+		Identity:    identity,
+		Results:     []models.WorkspaceMergeResult{},
+	}
+
+	for _, p := range req.Paths {
+		sourceID := store.WorkspaceID(namespace, p)
+		result := models.WorkspaceMergeResult{Path: p, SourceID: sourceID}
+
+		if sourceID != targetID {
+			ws, err := s.workspaceStore.GetWorkspace(ctx, sourceID)
+			if err != nil {
+				return nil, err
+			}
+			if ws != nil {
+				if err := s.rehomeVectors(ctx, sourceID, targetID); err != nil {
+					return nil, err
+				}
+				counts, err := s.workspaceStore.Reassign(ctx, sourceID, targetID)
+				if err != nil {
+					return nil, err
+				}
+				result.Merged = true
+				result.Memories = counts.Memories
+				result.Threads = counts.Threads
+				result.Sessions = counts.Sessions
+				s.logger.Info("merged workspace",
+					"source", sourceID, "target", targetID, "memories", counts.Memories)
+			}
+		}
+
+		if err := s.workspaceStore.AddAlias(ctx, namespace, p, targetID); err != nil {
+			return nil, err
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	return resp, nil
+}
+
+// rehomeVectors copies long-term vectors from the source workspace collection
+// into the target collection, then removes them from the source.
+func (s *Service) rehomeVectors(ctx context.Context, sourceID, targetID string) error {
+	mems, err := s.memoryStore.GetLongTermByWorkspace(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	if len(mems) == 0 {
+		return nil
+	}
+
+	points := map[string][]vectorstore.Point{}
+	ids := make([]string, 0, len(mems))
+	for _, m := range mems {
+		colName, err := s.collMgr.EnsureForPoint(ctx, targetID, m.ID)
+		if err != nil {
+			return apperr.DependencyUnavailable("vector_store_unavailable", err, "ensure qdrant collection")
+		}
+		vec, err := s.embedder.Embed(ctx, m.Content)
+		if err != nil {
+			return apperr.DependencyUnavailable("embedding_unavailable", err, "embed memory %s", m.ID)
+		}
+		payload := vectorPayload(m)
+		payload["workspace_id"] = targetID
+		points[colName] = append(points[colName], vectorstore.Point{ID: m.ID, Vector: vec, Payload: payload})
+		ids = append(ids, m.ID)
+	}
+
+	for colName, batch := range points {
+		if err := s.qdrantClient.Upsert(ctx, colName, batch); err != nil {
+			return apperr.DependencyUnavailable("vector_store_unavailable", err, "upsert to qdrant")
+		}
+	}
+	// Collections both workspaces share now hold the moved points
+	sources, err := s.collMgr.Collections(ctx, sourceID)
+	if err != nil {
+		s.logger.Warn("failed to delete merged vectors", "workspace", sourceID, "error", err)
+		return nil
+	}
+	for _, colName := range sources {
+		if _, moved := points[colName]; moved {
+			continue
+		}
+		if err := s.qdrantClient.DeletePoints(ctx, colName, ids); err != nil {
+			s.logger.Warn("failed to delete merged vectors", "workspace", sourceID, "collection", colName, "error", err)
+		}
+	}
+	return nil
+}
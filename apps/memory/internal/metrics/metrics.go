@@ -0,0 +1,244 @@
+// Package metrics keeps the server's counters and histograms and writes
+// them in the Prometheus text exposition format for GET /metrics.
+//
+// Metrics are package-level values registered with Default when declared,
+// so any package can record without threading a registry through its
+// constructors. Values that live elsewhere, such as row counts, are read at
+// scrape time through a Collector.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Collector writes one or more metric families.
+type Collector interface {
+	Collect(w *Writer)
+}
+
+// CollectorFunc adapts a function to Collector.
+type CollectorFunc func(w *Writer)
+
+func (f CollectorFunc) Collect(w *Writer) { f(w) }
+
+// Registry is an ordered set of collectors.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []Collector
+}
+
+// Default holds every metric declared with NewCounter and NewHistogram.
+var Default = &Registry{}
+
+// Register adds c to r.
+func (r *Registry) Register(c Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// Write writes r's metrics followed by extra's, which are typically bound
+// to one server's stores.
+func (r *Registry) Write(out io.Writer, extra ...Collector) error {
+	r.mu.Lock()
+	collectors := append(append([]Collector(nil), r.collectors...), extra...)
+	r.mu.Unlock()
+
+	w := &Writer{out: out}
+	for _, c := range collectors {
+		c.Collect(w)
+	}
+	return w.err
+}
+
+// Writer formats metric families.
+type Writer struct {
+	out io.Writer
+	err error
+}
+
+// Family starts a metric family; kind is "counter", "gauge" or "histogram".
+func (w *Writer) Family(name, kind, help string) {
+	w.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// Sample writes one sample. labels alternate names and values.
+func (w *Writer) Sample(name string, value float64, labels ...string) {
+	w.printf("%s%s %s\n", name, formatLabels(labels), formatValue(value))
+}
+
+// Gauge writes a family holding a single unlabelled gauge.
+func (w *Writer) Gauge(name, help string, value float64) {
+	w.Family(name, "gauge", help)
+	w.Sample(name, value)
+}
+
+func (w *Writer) printf(format string, args ...any) {
+	if w.err == nil {
+		_, w.err = fmt.Fprintf(w.out, format, args...)
+	}
+}
+
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(labels[i])
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[i+1]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatValue(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// series holds one value per label combination, keyed by the joined label
+// values.
+type series struct {
+	mu     sync.Mutex
+	labels []string
+	keys   []string
+	values map[string][]string
+}
+
+func (s *series) key(values []string) string {
+	if len(values) != len(s.labels) {
+		panic(fmt.Sprintf("metrics: got %d label values for labels %v", len(values), s.labels))
+	}
+	k := strings.Join(values, "\xff")
+	if _, ok := s.values[k]; !ok {
+		if s.values == nil {
+			s.values = map[string][]string{}
+		}
+		s.values[k] = append([]string(nil), values...)
+		s.keys = append(s.keys, k)
+		sort.Strings(s.keys)
+	}
+	return k
+}
+
+// pairs interleaves label names with the values stored under key.
+func (s *series) pairs(key string, extra ...string) []string {
+	out := make([]string, 0, 2*len(s.labels)+len(extra))
+	for i, name := range s.labels {
+		out = append(out, name, s.values[key][i])
+	}
+	return append(out, extra...)
+}
+
+// Counter is a monotonically increasing value per label combination.
+type Counter struct {
+	name, help string
+	series
+	counts map[string]float64
+}
+
+// NewCounter declares a counter with the given label names and registers it
+// with Default.
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{name: name, help: help, series: series{labels: labels}, counts: map[string]float64{}}
+	Default.Register(c)
+	return c
+}
+
+// Inc adds 1 for the given label values.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, which must not be negative, for the given label values.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[c.key(labelValues)] += v
+}
+
+func (c *Counter) Collect(w *Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w.Family(c.name, "counter", c.help)
+	if len(c.labels) == 0 && len(c.keys) == 0 {
+		w.Sample(c.name, 0)
+	}
+	for _, k := range c.keys {
+		w.Sample(c.name, c.counts[k], c.pairs(k)...)
+	}
+}
+
+// DefaultLatencyBuckets suit request latencies in seconds.
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Histogram counts observations into cumulative buckets per label
+// combination.
+type Histogram struct {
+	name, help string
+	buckets    []float64
+	series
+	data map[string]*histogramData
+}
+
+type histogramData struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram declares a histogram with the given upper bounds, which
+// must be sorted, and registers it with Default.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{name: name, help: help, buckets: buckets, series: series{labels: labels}, data: map[string]*histogramData{}}
+	Default.Register(h)
+	return h
+}
+
+// Observe records v for the given label values.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	k := h.key(labelValues)
+	d := h.data[k]
+	if d == nil {
+		d = &histogramData{counts: make([]uint64, len(h.buckets))}
+		h.data[k] = d
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		d.counts[i]++
+	}
+	d.count++
+	d.sum += v
+}
+
+func (h *Histogram) Collect(w *Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	w.Family(h.name, "histogram", h.help)
+	for _, k := range h.keys {
+		d := h.data[k]
+		var cumulative uint64
+		for i, le := range h.buckets {
+			cumulative += d.counts[i]
+			w.Sample(h.name+"_bucket", float64(cumulative), h.pairs(k, "le", formatValue(le))...)
+		}
+		w.Sample(h.name+"_bucket", float64(d.count), h.pairs(k, "le", "+Inf")...)
+		w.Sample(h.name+"_sum", d.sum, h.pairs(k)...)
+		w.Sample(h.name+"_count", float64(d.count), h.pairs(k)...)
+	}
+}
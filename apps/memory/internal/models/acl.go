@@ -0,0 +1,60 @@
+package models
+
+import "strings"
+
+// Visibility is a memory's access control label. It is enforced when
+// memories are read, against the caller identified by the request's API key.
+type Visibility string
+
+const (
+	// VisibilityPublic memories are visible to every caller. Memories
+	// stored without a label are public.
+	VisibilityPublic Visibility = "public"
+	// VisibilityTeam memories are visible to callers on the owner's team.
+	VisibilityTeam Visibility = "team"
+	// VisibilityPrivate memories are visible only to their owner.
+	VisibilityPrivate Visibility = "private"
+)
+
+func (v Visibility) IsValid() bool {
+	return v == VisibilityPublic || v == VisibilityTeam || v == VisibilityPrivate
+}
+
+// Caller is the identity a request is made with. Requests authenticated
+// with the shared API key, or made with auth disabled, are anonymous and
+// see only public memories.
+type Caller struct {
+	Name string `json:"name,omitempty"`
+	Team string `json:"team,omitempty"`
+}
+
+// ParseCaller reads an identity of the form "name" or "name@team".
+func ParseCaller(identity string) Caller {
+	name, team, _ := strings.Cut(identity, "@")
+	return Caller{Name: strings.TrimSpace(name), Team: strings.TrimSpace(team)}
+}
+
+// String formats the caller the way ParseCaller reads it.
+func (c Caller) String() string {
+	if c.Team == "" {
+		return c.Name
+	}
+	return c.Name + "@" + c.Team
+}
+
+// Anonymous reports whether the caller has no identity.
+func (c Caller) Anonymous() bool {
+	return c.Name == ""
+}
+
+// CanSee reports whether the caller may read m.
+func (c Caller) CanSee(m *Memory) bool {
+	switch m.Visibility {
+	case VisibilityPrivate:
+		return !c.Anonymous() && c.Name == m.Owner
+	case VisibilityTeam:
+		return !c.Anonymous() && (c.Name == m.Owner || (c.Team != "" && c.Team == m.OwnerTeam))
+	default:
+		return true
+	}
+}
@@ -0,0 +1,56 @@
+package models
+
+// KeyAccess is what a scoped API key may do within its scope.
+type KeyAccess string
+
+const (
+	// KeyAccessRead keys may only make requests that read: GETs and the
+	// read-only POSTs such as search.
+	KeyAccessRead KeyAccess = "read"
+	// KeyAccessReadWrite keys may also store, update and delete.
+	KeyAccessReadWrite KeyAccess = "read_write"
+)
+
+func (a KeyAccess) IsValid() bool {
+	return a == KeyAccessRead || a == KeyAccessReadWrite
+}
+
+// APIKey is a bearer token managed through /keys and scoped to a namespace
+// and, optionally, one workspace within it. Keys with no namespace reach
+// every namespace. Only a hash of the token is stored; it is shown once,
+// when the key is created. Name is a label for listings; the identity
+// memory ACLs see is the creator's, recorded in Identity.
+type APIKey struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Identity    string    `json:"identity,omitempty"`
+	Prefix      string    `json:"prefix"` // the token's first characters, to tell keys apart
+	Namespace   string    `json:"namespace,omitempty"`
+	Workspace   string    `json:"workspace,omitempty"`
+	WorkspaceID string    `json:"workspaceId,omitempty"`
+	Access      KeyAccess `json:"access"`
+	CreatedAt   int64     `json:"createdAt"`
+	RevokedAt   *int64    `json:"revokedAt,omitempty"`
+}
+
+// Caller is the identity memory ACLs are checked against for requests made
+// with the key: the identity of the caller that created it.
+func (k *APIKey) Caller() Caller {
+	return ParseCaller(k.Identity)
+}
+
+// CreateAPIKeyRequest is the payload for POST /keys. A workspace scope
+// needs the namespace the workspace lives in.
+type CreateAPIKeyRequest struct {
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace,omitempty"`
+	Workspace string    `json:"workspace,omitempty"`
+	Access    KeyAccess `json:"access"`
+}
+
+// CreateAPIKeyResponse is returned from POST /keys. Token is not stored and
+// cannot be retrieved again.
+type CreateAPIKeyResponse struct {
+	APIKey
+	Token string `json:"token"`
+}
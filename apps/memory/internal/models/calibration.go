@@ -0,0 +1,77 @@
+package models
+
+import "strings"
+
+// Source groups used by confidence calibration. Sources that belong to no
+// group are calibrated under their own name.
+const (
+	SourceGroupHooks   = "hooks"
+	SourceGroupThreads = "threads"
+	SourceGroupManual  = "manual"
+)
+
+// sourceGroups maps the sources set by the hooks, thread service and
+// clients onto calibration groups.
+var sourceGroups = map[string]string{
+	"hook":               SourceGroupHooks,
+	"pre_compact":        SourceGroupHooks,
+	"session_summarizer": SourceGroupHooks,
+	"thread":             SourceGroupThreads,
+	"thread-distill":     SourceGroupThreads,
+	"":                   SourceGroupManual,
+	"manual":             SourceGroupManual,
+	"mcp":                SourceGroupManual,
+}
+
+// SourceGroup returns the calibration group for a memory source.
+func SourceGroup(source string) string {
+	if g, ok := sourceGroups[source]; ok {
+		return g
+	}
+	if strings.HasPrefix(source, "hook") {
+		return SourceGroupHooks
+	}
+	return source
+}
+
+// CalibrationBucket compares the stated confidence of a band of resolved
+// memories with how often they turned out to be right.
+type CalibrationBucket struct {
+	MinConfidence float64 `json:"minConfidence"`
+	MaxConfidence float64 `json:"maxConfidence"`
+	Memories      int     `json:"memories"`
+	Accuracy      float64 `json:"accuracy"`
+}
+
+// SourceCalibration is the calibration of one source group. A memory is
+// resolved once it has been used (received an impact signal) or
+// contradicted (superseded); accuracy is the share of resolved memories
+// that were used and never contradicted.
+type SourceCalibration struct {
+	Source              string              `json:"source"`
+	Memories            int                 `json:"memories"`
+	Resolved            int                 `json:"resolved"`
+	Used                int                 `json:"used"`
+	Contradicted        int                 `json:"contradicted"`
+	MeanConfidence      float64             `json:"meanConfidence"`
+	Accuracy            float64             `json:"accuracy"`
+	Gap                 float64             `json:"gap"` // accuracy - meanConfidence; positive means under-confident
+	SuggestedAdjustment float64             `json:"suggestedAdjustment"`
+	AppliedAdjustment   float64             `json:"appliedAdjustment"`
+	Buckets             []CalibrationBucket `json:"buckets"`
+	Note                string              `json:"note,omitempty"`
+}
+
+// CalibrationReport is returned from GET /memories/calibration.
+type CalibrationReport struct {
+	Sources     []SourceCalibration `json:"sources"`
+	MinSamples  int                 `json:"minSamples"` // resolved memories needed before an adjustment is suggested
+	GeneratedAt int64               `json:"generatedAt"`
+}
+
+// ApplyCalibrationResponse is returned from POST /memories/calibration/apply
+// with the adjustment now in effect for each source group it changed.
+type ApplyCalibrationResponse struct {
+	Applied map[string]float64 `json:"applied"`
+	Report  *CalibrationReport `json:"report"`
+}
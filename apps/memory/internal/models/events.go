@@ -0,0 +1,46 @@
+package models
+
+// MemoryEventType is a memory lifecycle change streamed from GET /events.
+type MemoryEventType string
+
+const (
+	MemoryEventStored       MemoryEventType = "stored"
+	MemoryEventDeduplicated MemoryEventType = "deduplicated"
+	MemoryEventPromoted     MemoryEventType = "promoted"
+	MemoryEventDemoted      MemoryEventType = "demoted"
+	MemoryEventExpired      MemoryEventType = "expired"
+	MemoryEventSuperseded   MemoryEventType = "superseded"
+)
+
+func (t MemoryEventType) IsValid() bool {
+	switch t {
+	case MemoryEventStored, MemoryEventDeduplicated, MemoryEventPromoted, MemoryEventDemoted,
+		MemoryEventExpired, MemoryEventSuperseded:
+		return true
+	}
+	return false
+}
+
+// MemoryEvent is one lifecycle change to a memory. RelatedID is the memory
+// a deduplicated store matched, or the one a superseded memory was replaced
+// by. The memory's access control is carried so a stream only sends the
+// event to callers that may read the memory; it is never sent itself.
+type MemoryEvent struct {
+	Type        MemoryEventType `json:"type"`
+	MemoryID    string          `json:"memoryId"`
+	WorkspaceID string          `json:"workspaceId,omitempty"`
+	Namespace   string          `json:"namespace,omitempty"`
+	MemoryType  MemoryType      `json:"memoryType,omitempty"`
+	Tier        Tier            `json:"tier,omitempty"`
+	RelatedID   string          `json:"relatedId,omitempty"`
+	At          int64           `json:"at"`
+
+	Visibility Visibility `json:"-"`
+	Owner      string     `json:"-"`
+	OwnerTeam  string     `json:"-"`
+}
+
+// VisibleTo reports whether the caller may read the event's memory.
+func (e MemoryEvent) VisibleTo(c Caller) bool {
+	return c.CanSee(&Memory{Visibility: e.Visibility, Owner: e.Owner, OwnerTeam: e.OwnerTeam})
+}
@@ -0,0 +1,62 @@
+package models
+
+// LinkType is the relationship a memory link records, read as "source
+// <type> target", e.g. a fix that refines an earlier workaround.
+type LinkType string
+
+const (
+	LinkSupports    LinkType = "supports"
+	LinkContradicts LinkType = "contradicts"
+	LinkRefines     LinkType = "refines"
+	LinkCausedBy    LinkType = "caused_by"
+	// LinkCoAccessed links are built by search between memories retrieved
+	// together, and can't be created through the API.
+	LinkCoAccessed LinkType = "co_accessed"
+)
+
+// IsValid reports whether links of type t may be created through the API.
+func (t LinkType) IsValid() bool {
+	switch t {
+	case LinkSupports, LinkContradicts, LinkRefines, LinkCausedBy:
+		return true
+	}
+	return false
+}
+
+// MaxLinkStrength caps link strength, matching co_accessed links.
+const MaxLinkStrength = 5.0
+
+// CreateLinkRequest is the payload for POST /memories/{id}/links.
+// Strength defaults to 1.
+type CreateLinkRequest struct {
+	TargetID string   `json:"targetId"`
+	LinkType LinkType `json:"linkType"`
+	Strength float64  `json:"strength,omitempty"`
+}
+
+// GraphEdge is one link between two memories.
+type GraphEdge struct {
+	SourceID  string   `json:"sourceId"`
+	TargetID  string   `json:"targetId"`
+	LinkType  LinkType `json:"linkType"`
+	Strength  float64  `json:"strength"`
+	UpdatedAt int64    `json:"updatedAt"`
+}
+
+// GraphNode is a memory reached by a graph traversal, Depth links from
+// the root.
+type GraphNode struct {
+	Memory *Memory `json:"memory"`
+	Depth  int     `json:"depth"`
+}
+
+// MemoryGraph is returned from GET /memories/{id}/graph. Links are
+// followed in both directions; Truncated is set when the node cap cut the
+// traversal short.
+type MemoryGraph struct {
+	RootID    string      `json:"rootId"`
+	Depth     int         `json:"depth"`
+	Nodes     []GraphNode `json:"nodes"`
+	Edges     []GraphEdge `json:"edges"`
+	Truncated bool        `json:"truncated,omitempty"`
+}
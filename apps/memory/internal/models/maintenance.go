@@ -0,0 +1,98 @@
+package models
+
+// DBStats is returned from GET /stats/db.
+type DBStats struct {
+	FileBytes      int64 `json:"fileBytes"` // main database file
+	WALBytes       int64 `json:"walBytes"`
+	UsedBytes      int64 `json:"usedBytes"` // live pages
+	FreeBytes      int64 `json:"freeBytes"` // free pages awaiting vacuum
+	PageSize       int64 `json:"pageSize"`
+	ThresholdBytes int64 `json:"thresholdBytes,omitempty"`
+	OverThreshold  bool  `json:"overThreshold"`
+}
+
+// VacuumResult describes one incremental vacuum and optimize pass.
+type VacuumResult struct {
+	FileBytesBefore int64 `json:"fileBytesBefore"`
+	FileBytesAfter  int64 `json:"fileBytesAfter"`
+	ReclaimedBytes  int64 `json:"reclaimedBytes"`
+	DurationMs      int64 `json:"durationMs"`
+	FullVacuum      bool  `json:"fullVacuum,omitempty"` // one-off switch to incremental auto-vacuum
+}
+
+// VectorDrift compares one workspace's live long-term memories in SQLite
+// with the points in its Qdrant collection.
+type VectorDrift struct {
+	WorkspaceID string `json:"workspaceId"`
+	Collection  string `json:"collection"`
+	Shards      int    `json:"shards,omitempty"` // collections searched, when split
+	Points      int    `json:"points"`
+	LongTerm    int    `json:"longTerm"`
+	Orphans     int    `json:"orphans"` // points with no live long-term memory
+	Missing     int    `json:"missing"` // live long-term memories with no point
+	Deleted     int    `json:"deleted"`
+	Reupserted  int    `json:"reupserted"`
+	Error       string `json:"error,omitempty"`
+}
+
+// VectorReconcileReport describes one reconciliation of SQLite against
+// Qdrant. A dry run reports drift without repairing it.
+type VectorReconcileReport struct {
+	DryRun      bool          `json:"dryRun"`
+	Orphans     int           `json:"orphans"`
+	Missing     int           `json:"missing"`
+	Deleted     int           `json:"deleted"`
+	Reupserted  int           `json:"reupserted"`
+	Collections []VectorDrift `json:"collections"`
+	CheckedAt   int64         `json:"checkedAt"`
+	DurationMs  int64         `json:"durationMs"`
+}
+
+// VectorMove counts the points a rebalance moved between two collections.
+type VectorMove struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Points int    `json:"points"`
+}
+
+// VectorRebalanceReport describes one rebalance of Qdrant points onto the
+// collections the sharding strategy routes them to. A dry run reports the
+// moves without making them.
+type VectorRebalanceReport struct {
+	DryRun   bool   `json:"dryRun"`
+	Strategy string `json:"strategy"`
+	Points   int    `json:"points"`
+	Moved    int    `json:"moved"`
+	// Skipped points have no live long-term memory; reconcile removes them
+	Skipped    int          `json:"skipped"`
+	Moves      []VectorMove `json:"moves"`
+	Dropped    []string     `json:"dropped"` // collections left empty and unrouted
+	CheckedAt  int64        `json:"checkedAt"`
+	DurationMs int64        `json:"durationMs"`
+}
+
+// ArchiveFormat identifies memory server archives. ArchiveVersion changes
+// only when the archive layout does; schema changes are absorbed on import.
+const (
+	ArchiveFormat  = "clive-memory-archive"
+	ArchiveVersion = 1
+)
+
+// ArchiveHeader is the first record of an archive.
+type ArchiveHeader struct {
+	Format     string   `json:"format"`
+	Version    int      `json:"version"`
+	ExportedAt int64    `json:"exportedAt"`
+	Workspaces []string `json:"workspaces"`
+}
+
+// ImportReport describes one archive import. Rows already present are
+// skipped, so importing an archive twice changes nothing.
+type ImportReport struct {
+	Workspaces    []string       `json:"workspaces"`
+	Imported      map[string]int `json:"imported"`
+	Skipped       map[string]int `json:"skipped"`
+	Reembedded    int            `json:"reembedded"`
+	ReembedFailed int            `json:"reembedFailed,omitempty"`
+	DurationMs    int64          `json:"durationMs"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "strings"
+
 // Memory is the core domain entity stored in SQLite.
 type Memory struct {
 	ID             string     `json:"id"`
@@ -36,6 +38,14 @@ type Memory struct {
 
 	// Feature Thread association
 	ThreadID *string `json:"threadId,omitempty"`
+
+	// Impact decay: when ImpactScore was last brought current
+	ImpactUpdatedAt *int64 `json:"impactUpdatedAt,omitempty"`
+
+	// Access control: who stored the memory and who may read it
+	Visibility Visibility `json:"visibility,omitempty"`
+	Owner      string     `json:"owner,omitempty"`
+	OwnerTeam  string     `json:"ownerTeam,omitempty"`
 }
 
 // EncodingContext captures the context in which a memory was created,
@@ -44,6 +54,47 @@ type EncodingContext struct {
 	FileTypes  []string `json:"fileTypes,omitempty"`
 	Frameworks []string `json:"frameworks,omitempty"`
 	TaskType   string   `json:"taskType,omitempty"`
+	// Provenance of memories written during a build
+	TaskID    string `json:"taskId,omitempty"`
+	EpicID    string `json:"epicId,omitempty"`
+	CommitSHA string `json:"commitSha,omitempty"`
+}
+
+// RetrievabilityCurve is returned from GET /memories/:id/retrievability.
+// Each point projects retrievability Day days from now, without further
+// access and, when the memory has been accessed before, assuming accesses
+// continue at the observed rate.
+type RetrievabilityCurve struct {
+	MemoryID           string                `json:"memoryId"`
+	Tier               Tier                  `json:"tier"`
+	Stability          float64               `json:"stability"`
+	AccessCount        int                   `json:"accessCount"`
+	AccessIntervalDays float64               `json:"accessIntervalDays,omitempty"`
+	Current            float64               `json:"current"`
+	ForgetThreshold    float64               `json:"forgetThreshold"`
+	ForgottenInDays    *int                  `json:"forgottenInDays"` // nil if not within the horizon
+	Points             []RetrievabilityPoint `json:"points"`
+}
+
+// RetrievabilityPoint is one day of a projected retrievability curve.
+type RetrievabilityPoint struct {
+	Day            int     `json:"day"`
+	Retrievability float64 `json:"retrievability"`
+	Reinforced     float64 `json:"reinforced,omitempty"`
+}
+
+// Attachment is a small image stored alongside a memory. The bytes live on
+// disk; Embedding holds the optional image embedding.
+type Attachment struct {
+	ID             string `json:"id"`
+	MemoryID       string `json:"memoryId"`
+	Filename       string `json:"filename,omitempty"`
+	ContentType    string `json:"contentType"`
+	Size           int64  `json:"size"`
+	SHA256         string `json:"sha256"`
+	Embedding      []byte `json:"-"`
+	EmbeddingModel string `json:"embeddingModel,omitempty"`
+	CreatedAt      int64  `json:"createdAt"`
 }
 
 // Workspace tracks registered project workspaces.
@@ -66,3 +117,9 @@ type EmbeddingCacheEntry struct {
 
 // GlobalWorkspaceID is the sentinel workspace for cross-project knowledge.
 const GlobalWorkspaceID = "__global__"
+
+// IsGlobalWorkspace reports whether id is the global workspace or a
+// namespace's global workspace.
+func IsGlobalWorkspace(id string) bool {
+	return id == GlobalWorkspaceID || strings.HasPrefix(id, GlobalWorkspaceID+":")
+}
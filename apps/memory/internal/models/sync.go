@@ -0,0 +1,19 @@
+package models
+
+// SyncBlob is an opaque, client-encrypted bundle stored for config sync.
+// The server never sees its plaintext. Data is omitted from listings.
+type SyncBlob struct {
+	Name      string `json:"name"`
+	Version   int    `json:"version"`
+	Size      int    `json:"size"`
+	UpdatedAt int64  `json:"updatedAt"`
+	Data      []byte `json:"data,omitempty"`
+}
+
+// PutSyncBlobRequest is the payload for PUT /sync/blobs/{name}. When
+// BaseVersion is set, the write only succeeds if the stored blob is still
+// at that version (0 meaning it must not exist yet).
+type PutSyncBlobRequest struct {
+	Data        []byte `json:"data"`
+	BaseVersion *int   `json:"baseVersion,omitempty"`
+}
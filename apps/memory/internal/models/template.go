@@ -0,0 +1,62 @@
+package models
+
+// TemplateField is a single named section of a structured memory.
+type TemplateField struct {
+	Name        string `json:"name"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+}
+
+// ContentTemplate describes the canonical structure for a memory type.
+// Structured memories render their fields as "Label: value" lines in
+// template order, which keeps content consistent for search and distillation.
+type ContentTemplate struct {
+	MemoryType MemoryType      `json:"memoryType"`
+	Fields     []TemplateField `json:"fields"`
+}
+
+// ContentTemplates maps memory types to their structured content template.
+// Types without an entry accept free-form content only.
+var ContentTemplates = map[MemoryType]ContentTemplate{
+	MemoryTypeDecision: {
+		MemoryType: MemoryTypeDecision,
+		Fields: []TemplateField{
+			{Name: "context", Label: "Context", Description: "The situation or forces that required a decision", Required: true},
+			{Name: "decision", Label: "Decision", Description: "What was decided", Required: true},
+			{Name: "consequences", Label: "Consequences", Description: "Trade-offs and follow-on effects of the decision", Required: true},
+		},
+	},
+	MemoryTypeGotcha: {
+		MemoryType: MemoryTypeGotcha,
+		Fields: []TemplateField{
+			{Name: "symptom", Label: "Symptom", Description: "What goes wrong and how it shows up", Required: true},
+			{Name: "cause", Label: "Cause", Description: "Why it happens", Required: true},
+			{Name: "fix", Label: "Fix", Description: "How to avoid or resolve it", Required: true},
+		},
+	},
+	MemoryTypeWorkingSolution: {
+		MemoryType: MemoryTypeWorkingSolution,
+		Fields: []TemplateField{
+			{Name: "problem", Label: "Problem", Description: "The problem being solved", Required: true},
+			{Name: "solution", Label: "Solution", Description: "The approach that worked", Required: true},
+			{Name: "verification", Label: "Verification", Description: "How the solution was verified", Required: false},
+		},
+	},
+	MemoryTypeFailure: {
+		MemoryType: MemoryTypeFailure,
+		Fields: []TemplateField{
+			{Name: "attempt", Label: "Attempt", Description: "What was tried", Required: true},
+			{Name: "outcome", Label: "Outcome", Description: "What happened instead", Required: true},
+			{Name: "lesson", Label: "Lesson", Description: "What to do differently next time", Required: true},
+		},
+	},
+	MemoryTypePattern: {
+		MemoryType: MemoryTypePattern,
+		Fields: []TemplateField{
+			{Name: "pattern", Label: "Pattern", Description: "The convention or pattern to follow", Required: true},
+			{Name: "rationale", Label: "Rationale", Description: "Why this pattern is used", Required: true},
+			{Name: "example", Label: "Example", Description: "A short example or file reference", Required: false},
+		},
+	},
+}
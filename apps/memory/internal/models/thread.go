@@ -44,6 +44,7 @@ type FeatureThread struct {
 	ClosedAt     *int64       `json:"closedAt,omitempty"`
 	EntryCount   int          `json:"entryCount"`
 	TokenBudget  int          `json:"tokenBudget"`
+	TokenUsage   int          `json:"tokenUsage"`
 	Summary      string       `json:"summary"`
 	RelatedFiles []string     `json:"relatedFiles,omitempty"`
 	Tags         []string     `json:"tags,omitempty"`
@@ -58,6 +59,9 @@ type ThreadEntry struct {
 	Section   ThreadSection `json:"section"`
 	CreatedAt int64         `json:"createdAt"`
 
+	// Set on append when the content was condensed to fit the entry limit.
+	Summarized bool `json:"summarized,omitempty"`
+
 	// Populated by joins, not stored directly.
 	Content    string     `json:"content,omitempty"`
 	MemoryType MemoryType `json:"memoryType,omitempty"`
@@ -96,6 +100,65 @@ type AppendEntryRequest struct {
 	Tags       []string      `json:"tags,omitempty"`
 }
 
+// AppendEntriesRequest is the payload for POST /threads/{id}/entries/batch.
+// Entries without a workspace inherit Workspace.
+type AppendEntriesRequest struct {
+	Namespace string               `json:"-"`
+	Workspace string               `json:"workspace"`
+	Entries   []AppendEntryRequest `json:"entries"`
+}
+
+// AppendEntriesResponse is returned from POST /threads/{id}/entries/batch.
+type AppendEntriesResponse struct {
+	Entries []*ThreadEntry `json:"entries"`
+}
+
+// ReclassifyEntriesRequest is the payload for
+// POST /threads/{id}/entries/reclassify.
+type ReclassifyEntriesRequest struct {
+	EntryIDs []string      `json:"entryIds"`
+	Section  ThreadSection `json:"section"`
+}
+
+// ReclassifyEntriesResponse is returned from
+// POST /threads/{id}/entries/reclassify.
+type ReclassifyEntriesResponse struct {
+	Updated int           `json:"updated"`
+	Section ThreadSection `json:"section"`
+}
+
+// UpdateEntryRequest is the payload for PATCH /threads/{id}/entries/{entryId}.
+type UpdateEntryRequest struct {
+	Content *string        `json:"content,omitempty"`
+	Section *ThreadSection `json:"section,omitempty"`
+}
+
+// EntryEditAction is what an edit did to a thread entry.
+type EntryEditAction string
+
+const (
+	EntryEditUpdated EntryEditAction = "updated"
+	EntryEditDeleted EntryEditAction = "deleted"
+)
+
+// ThreadEntryEdit is one record of a thread's edit log, keeping what an
+// entry held before it was updated or deleted.
+type ThreadEntryEdit struct {
+	ID              int64           `json:"id"`
+	ThreadID        string          `json:"threadId"`
+	EntryID         string          `json:"entryId"`
+	MemoryID        string          `json:"memoryId"`
+	Action          EntryEditAction `json:"action"`
+	PreviousContent string          `json:"previousContent"`
+	PreviousSection ThreadSection   `json:"previousSection"`
+	CreatedAt       int64           `json:"createdAt"`
+}
+
+// EntryEditsResponse is returned from GET /threads/{id}/edits.
+type EntryEditsResponse struct {
+	Edits []ThreadEntryEdit `json:"edits"`
+}
+
 // CloseThreadRequest is the payload for POST /threads/{id}/close.
 type CloseThreadRequest struct {
 	Distill bool `json:"distill"`
@@ -73,6 +73,7 @@ const (
 // StoreRequest is the payload for POST /memories.
 type StoreRequest struct {
 	Namespace        string           `json:"-"` // Set from X-Clive-Namespace header, not JSON body
+	Caller           Caller           `json:"-"` // Set from the API key, not JSON body
 	Workspace        string           `json:"workspace"`
 	Content          string           `json:"content"`
 	MemoryType       MemoryType       `json:"memoryType"`
@@ -85,6 +86,23 @@ type StoreRequest struct {
 	RelatedFiles     []string         `json:"relatedFiles,omitempty"`
 	EncodingContext  *EncodingContext `json:"encodingContext,omitempty"`
 	CompletionStatus *string          `json:"completionStatus,omitempty"`
+	// Structured requests are validated against (or rendered from) the
+	// memory type's ContentTemplate.
+	Structured bool              `json:"structured,omitempty"`
+	Fields     map[string]string `json:"fields,omitempty"`
+	// Provenance is merged into EncodingContext and RelatedFiles.
+	Provenance *Provenance `json:"provenance,omitempty"`
+	// Visibility labels the memory public (default), team or private to
+	// the caller. Team and private require a named API key.
+	Visibility Visibility `json:"visibility,omitempty"`
+}
+
+// Provenance links a memory to the task, epic, files and commit it came from.
+type Provenance struct {
+	TaskID    string   `json:"taskId,omitempty"`
+	EpicID    string   `json:"epicId,omitempty"`
+	Files     []string `json:"files,omitempty"`
+	CommitSHA string   `json:"commitSha,omitempty"`
 }
 
 // StoreResponse is returned from POST /memories.
@@ -95,11 +113,13 @@ type StoreResponse struct {
 	NearDupSimilarity float64 `json:"nearDupSimilarity,omitempty"`
 	Skipped           bool    `json:"skipped,omitempty"`
 	SkipReason        string  `json:"skipReason,omitempty"`
+	Evicted           int     `json:"evicted,omitempty"` // memories evicted to stay within quota
 }
 
 // SearchRequest is the payload for POST /memories/search.
 type SearchRequest struct {
 	Namespace      string           `json:"-"` // Set from X-Clive-Namespace header, not JSON body
+	Caller         Caller           `json:"-"` // Set from the API key, not JSON body
 	Workspace      string           `json:"workspace"`
 	Query          string           `json:"query"`
 	MaxResults     int              `json:"maxResults"`
@@ -109,6 +129,23 @@ type SearchRequest struct {
 	IncludeGlobal  bool             `json:"includeGlobal"`
 	SearchMode     SearchMode       `json:"searchMode"`
 	SessionContext *EncodingContext `json:"sessionContext,omitempty"`
+	// GroupByType caps each memory type (default 3, overridable via
+	// TypeCaps) and returns results grouped by type.
+	GroupByType bool               `json:"groupByType,omitempty"`
+	TypeCaps    map[MemoryType]int `json:"typeCaps,omitempty"`
+	// ThreadID anchors the search to a feature thread: its entries and the
+	// memories linked to them rank higher.
+	ThreadID string `json:"threadId,omitempty"`
+	// Rerank reorders the best candidates with the server's reranker
+	// (RERANK_MODEL), which reads the query and each memory together.
+	Rerank bool `json:"rerank,omitempty"`
+}
+
+// SearchGroup lists the result IDs of one memory type, in score order.
+type SearchGroup struct {
+	MemoryType MemoryType `json:"memoryType"`
+	Count      int        `json:"count"`
+	IDs        []string   `json:"ids"`
 }
 
 // SearchResult is a single result from a search.
@@ -126,11 +163,19 @@ type SearchResult struct {
 	Stability      float64    `json:"stability"`
 	LastAccessedAt *int64     `json:"lastAccessedAt,omitempty"`
 	Retrievability float64    `json:"retrievability"`
+	ThreadAnchored bool       `json:"threadAnchored,omitempty"` // boosted by the search's thread
+	// Snippet is the best keyword-matching window of the content, with
+	// matched terms wrapped in <mark></mark>. Empty for vector-only matches.
+	Snippet string `json:"snippet,omitempty"`
+	// RerankScore is the reranker's relevance score, set when the result
+	// was among the reranked candidates.
+	RerankScore *float64 `json:"rerankScore,omitempty"`
 }
 
 // SearchResponse is returned from POST /memories/search.
 type SearchResponse struct {
 	Results []SearchResult `json:"results"`
+	Groups  []SearchGroup  `json:"groups,omitempty"`
 	Meta    SearchMeta     `json:"meta"`
 }
 
@@ -139,11 +184,16 @@ type SearchMeta struct {
 	VectorResults int `json:"vectorResults"`
 	BM25Results   int `json:"bm25Results"`
 	SearchTimeMs  int `json:"searchTimeMs"`
+	// Reranked reports whether the reranker ordered the results. It stays
+	// false when a rerank was asked for but the reranker failed, leaving
+	// results in blended order.
+	Reranked bool `json:"reranked,omitempty"`
 }
 
 // BulkStoreRequest is the payload for POST /memories/bulk.
 type BulkStoreRequest struct {
 	Namespace string         `json:"-"` // Set from X-Clive-Namespace header, not JSON body
+	Caller    Caller       `json:"-"` // Set from the API key, not JSON body
 	Workspace string         `json:"workspace"`
 	Memories  []BulkMemory   `json:"memories"`
 	SessionID string         `json:"sessionId"`
@@ -157,6 +207,9 @@ type BulkMemory struct {
 	Source       string     `json:"source"`
 	Global       bool       `json:"global"`
 	RelatedFiles []string   `json:"relatedFiles,omitempty"`
+	Structured   bool              `json:"structured,omitempty"`
+	Fields       map[string]string `json:"fields,omitempty"`
+	Provenance   *Provenance       `json:"provenance,omitempty"`
 }
 
 // BulkStoreResponse is returned from POST /memories/bulk.
@@ -177,6 +230,26 @@ type CompactResponse struct {
 	Expired       int `json:"expired"`
 	Promoted      int `json:"promoted"`
 	ForgottenLow  int `json:"forgottenLow,omitempty"`
+	ImpactDecayed int `json:"impactDecayed,omitempty"`
+	Demoted       int `json:"demoted"`
+	Consolidated  int `json:"consolidated"`
+}
+
+// CompactionReport records one compaction run for GET /compact/history.
+type CompactionReport struct {
+	ID         string `json:"id"`
+	Trigger    string `json:"trigger"` // "manual" or "scheduled"
+	StartedAt  int64  `json:"startedAt"`
+	DurationMs int64  `json:"durationMs"`
+	CompactResponse
+	DBSizeBefore int64  `json:"dbSizeBefore"`
+	DBSizeAfter  int64  `json:"dbSizeAfter"`
+	DBSizeDelta  int64  `json:"dbSizeDelta"`
+	Error        string `json:"error,omitempty"`
+
+	// ReclaimedBytes is the file space released by the vacuum that
+	// follows a successful compaction.
+	ReclaimedBytes int64 `json:"reclaimedBytes"`
 }
 
 // UpdateRequest is the payload for PATCH /memories/:id.
@@ -203,6 +276,7 @@ type SupersedeResponse struct {
 // ListRequest holds parsed query params for GET /memories.
 // Sort whitelist: "created_at", "updated_at", "confidence", "access_count", "impact_score"
 type ListRequest struct {
+	Caller      Caller       `json:"-"` // Set from the API key, not JSON body
 	Page        int          `json:"page"`
 	Limit       int          `json:"limit"`
 	Sort        string       `json:"sort"`
@@ -211,6 +285,33 @@ type ListRequest struct {
 	MemoryTypes []MemoryType `json:"memoryTypes"`
 	Tier        string       `json:"tier"`
 	Source      string       `json:"source"`
+	Tags        []string     `json:"tags"` // Matches memories with any of these tags
+}
+
+// RetagRequest is the payload for POST /memories/retag. Remove is applied
+// before Add, so renaming a tag is {"remove": [old], "add": [new]} with a
+// filter on the old tag.
+type RetagRequest struct {
+	Filter ListRequest `json:"filter"` // Paging and sort fields are ignored
+	Add    []string    `json:"add"`
+	Remove []string    `json:"remove"`
+	DryRun bool        `json:"dryRun"`
+}
+
+// RetagChange is one memory whose tags a retag changes.
+type RetagChange struct {
+	ID     string   `json:"id"`
+	Before []string `json:"before"`
+	After  []string `json:"after"`
+}
+
+// RetagResponse is returned from POST /memories/retag. Sample holds the
+// first changes, so a dry run can be checked before it is applied.
+type RetagResponse struct {
+	Matched int           `json:"matched"`
+	Changed int           `json:"changed"`
+	DryRun  bool          `json:"dryRun"`
+	Sample  []RetagChange `json:"sample"`
 }
 
 // Pagination holds pagination metadata.
@@ -237,6 +338,45 @@ type WorkspaceStats struct {
 	LongTermCount  int            `json:"longTermCount"`
 	ByType         map[string]int `json:"byType"`
 	LastAccessedAt int64          `json:"lastAccessedAt"`
+	ContentBytes   int64          `json:"contentBytes"`
+	Quota          *QuotaUsage    `json:"quota,omitempty"` // nil when no quota applies
+}
+
+// QuotaUsage reports a workspace's usage against its quota. A zero limit
+// is unlimited.
+type QuotaUsage struct {
+	Memories    int    `json:"memories"`
+	MaxMemories int    `json:"maxMemories"`
+	Bytes       int64  `json:"bytes"`
+	MaxBytes    int64  `json:"maxBytes"`
+	Overflow    string `json:"overflow"` // reject or evict
+}
+
+// WorkspaceMergeRequest is the body for POST /workspaces/merge. Each path-based
+// workspace in Paths is merged into the workspace identified by Remote and
+// Subdir, and the paths are aliased to it for future requests.
+type WorkspaceMergeRequest struct {
+	Namespace string   `json:"-"`
+	Remote    string   `json:"remote"`
+	Subdir    string   `json:"subdir,omitempty"`
+	Paths     []string `json:"paths"`
+}
+
+// WorkspaceMergeResult describes what was moved for one source path.
+type WorkspaceMergeResult struct {
+	Path     string `json:"path"`
+	SourceID string `json:"sourceId"`
+	Merged   bool   `json:"merged"`
+	Memories int    `json:"memories"`
+	Threads  int    `json:"threads"`
+	Sessions int    `json:"sessions"`
+}
+
+// WorkspaceMergeResponse is returned from POST /workspaces/merge.
+type WorkspaceMergeResponse struct {
+	WorkspaceID string                 `json:"workspaceId"`
+	Identity    string                 `json:"identity"`
+	Results     []WorkspaceMergeResult `json:"results"`
 }
 
 // ImpactSignal represents the type of impact event.
@@ -283,6 +423,18 @@ type RecordImpactResponse struct {
 	Promoted    bool    `json:"promoted"`
 }
 
+// RecalculateImpactProgress reports a run of POST /memories/impact/recalculate.
+// It is streamed after each batch when the client accepts NDJSON, and the
+// final value (Done set) is the response body.
+type RecalculateImpactProgress struct {
+	Total      int   `json:"total"`
+	Scanned    int   `json:"scanned"`
+	Changed    int   `json:"changed"`
+	Batches    int   `json:"batches"`
+	Done       bool  `json:"done"`
+	DurationMs int64 `json:"durationMs"`
+}
+
 // --- Progressive Token Disclosure (3-Layer Search) ---
 
 // SearchIndexResult is a compact search result for Layer 1 (index only).
@@ -295,18 +447,22 @@ type SearchIndexResult struct {
 	Tags           []string   `json:"tags"`
 	ImpactScore    float64    `json:"impactScore"`
 	ContentPreview string     `json:"contentPreview"`
+	Snippet        string     `json:"snippet,omitempty"`
 	CreatedAt      int64      `json:"createdAt"`
+	ThreadAnchored bool       `json:"threadAnchored,omitempty"`
 }
 
 // SearchIndexResponse is returned from POST /memories/search/index (Layer 1).
 type SearchIndexResponse struct {
 	Results []SearchIndexResult `json:"results"`
+	Groups  []SearchGroup       `json:"groups,omitempty"`
 	Meta    SearchMeta          `json:"meta"`
 }
 
 // TimelineRequest is the payload for POST /memories/timeline (Layer 2).
 type TimelineRequest struct {
 	Namespace    string `json:"-"` // Set from X-Clive-Namespace header, not JSON body
+	Caller        Caller `json:"-"` // Set from the API key, not JSON body
 	MemoryID     string `json:"memoryId"`
 	Workspace    string `json:"workspace"`
 	WindowMinutes int   `json:"windowMinutes"`
@@ -322,7 +478,8 @@ type TimelineResponse struct {
 
 // BatchGetRequest is the payload for POST /memories/batch (Layer 3).
 type BatchGetRequest struct {
-	IDs []string `json:"ids"`
+	Caller Caller   `json:"-"` // Set from the API key, not JSON body
+	IDs    []string `json:"ids"`
 }
 
 // BatchGetResponse is returned from POST /memories/batch (Layer 3).
@@ -380,12 +537,35 @@ type StoreObservationRequest struct {
 	Success  bool   `json:"success"`
 }
 
+// BatchObservationsRequest is the payload for POST /sessions/{id}/observations/batch.
+type BatchObservationsRequest struct {
+	Observations []StoreObservationRequest `json:"observations"`
+}
+
+// BatchObservationsResponse is returned from POST /sessions/{id}/observations/batch.
+type BatchObservationsResponse struct {
+	SessionID    string `json:"sessionId"`
+	Stored       int    `json:"stored"`
+	LastSequence int    `json:"lastSequence"`
+}
+
+// EndSessionRequest is the payload for POST /sessions/{id}/end. The
+// transcript is optional: without one the summary is built from the
+// session's observations.
+type EndSessionRequest struct {
+	Namespace  string `json:"-"` // Set from X-Clive-Namespace header, not JSON body
+	Workspace  string `json:"workspace"`
+	Transcript string `json:"transcript,omitempty"`
+}
+
 // HealthResponse is returned from GET /health.
 type HealthResponse struct {
 	Status      string       `json:"status"`
-	Ollama      ServiceCheck `json:"ollama"`
+	Embedder    ServiceCheck `json:"embedder"`
+	Ollama      ServiceCheck `json:"ollama"` // same as Embedder, for older clients
 	Qdrant      ServiceCheck `json:"qdrant"`
 	DB          ServiceCheck `json:"db"`
+	Warmup      ServiceCheck `json:"warmup"`
 	MemoryCount int          `json:"memoryCount"`
 }
 
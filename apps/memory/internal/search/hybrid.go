@@ -1,6 +1,7 @@
 package search
 
 import (
+	"context"
 	"math"
 	"sort"
 	"time"
@@ -23,6 +24,9 @@ type HybridSearcher struct {
 	vectorWeight  float64
 	bm25Weight    float64
 	longTermBoost float64
+	halfLife      float64 // impact half-life in days; 0 disables decay
+	reranker      Reranker
+	rerankTopK    int
 }
 
 func NewHybridSearcher(
@@ -45,6 +49,22 @@ func NewHybridSearcher(
 	}
 }
 
+// SetImpactHalfLife sets the half-life, in days, applied to impact scores at
+// query time, so the stability boost on access uses the impact as it stands
+// today rather than as of the last compaction.
+func (h *HybridSearcher) SetImpactHalfLife(days float64) {
+	h.halfLife = days
+}
+
+// currentImpact returns mem's impact score decayed to now.
+func (h *HybridSearcher) currentImpact(mem *models.Memory) float64 {
+	if mem.ImpactUpdatedAt == nil {
+		return mem.ImpactScore
+	}
+	elapsed := float64(time.Now().Unix()-*mem.ImpactUpdatedAt) / 86400.0
+	return store.ImpactAfter(mem.ImpactScore, elapsed, h.halfLife)
+}
+
 // SearchParams controls how a search is executed.
 type SearchParams struct {
 	QueryVector    []float32
@@ -56,6 +76,17 @@ type SearchParams struct {
 	Tier           string
 	SearchMode     models.SearchMode
 	SessionContext *models.EncodingContext
+	// TypeCaps limits how many results of each memory type are returned.
+	// DefaultTypeCap applies to types not listed; 0 means uncapped.
+	TypeCaps       map[models.MemoryType]int
+	DefaultTypeCap int
+	// AnchorIDs are the memories of the active feature thread. They and the
+	// memories linked to them are boosted.
+	AnchorIDs []string
+	// Caller limits results to the memories it may read.
+	Caller models.Caller
+	// Rerank reorders the best candidates with the configured reranker.
+	Rerank bool
 }
 
 // Result is a merged, scored search result.
@@ -65,29 +96,40 @@ type Result struct {
 	BM25Score      float64
 	FinalScore     float64
 	Retrievability float64
+	Snippet        string   // highlighted BM25 match window, empty for vector-only hits
+	Anchored       bool     // boosted as part of, or linked to, the active thread
+	RerankScore    *float64 // the reranker's relevance score, nil when not reranked
 }
 
+// MinRetrievability is the floor applied to retrievability scores.
+const MinRetrievability = 0.05
+
 // Retrievability computes the exponential decay of a memory based on elapsed
 // time since last access and its stability (Ebbinghaus forgetting curve).
 // Returns a value in [0.05, 1.0].
 func Retrievability(createdAt int64, lastAccessedAt *int64, stability float64) float64 {
-	if stability <= 0 {
-		stability = 5.0
-	}
-
 	refTime := createdAt
 	if lastAccessedAt != nil && *lastAccessedAt > 0 {
 		refTime = *lastAccessedAt
 	}
 
 	elapsedDays := float64(time.Now().Unix()-refTime) / 86400.0
+	return RetrievabilityAfter(elapsedDays, stability)
+}
+
+// RetrievabilityAfter returns the retrievability of a memory elapsedDays
+// after its last access, floored at MinRetrievability.
+func RetrievabilityAfter(elapsedDays, stability float64) float64 {
+	if stability <= 0 {
+		stability = 5.0
+	}
 	if elapsedDays < 0 {
 		elapsedDays = 0
 	}
 
 	r := math.Exp(-elapsedDays / stability)
-	if r < 0.05 {
-		return 0.05
+	if r < MinRetrievability {
+		return MinRetrievability
 	}
 	return r
 }
@@ -150,8 +192,9 @@ func setOverlapRatio(a, b []string) float64 {
 	return float64(intersection) / float64(len(union))
 }
 
-// Search executes the hybrid search and returns merged results.
-func (h *HybridSearcher) Search(params SearchParams) ([]Result, int, int, time.Duration, error) {
+// Search executes the hybrid search and returns merged results. It stops
+// early with ctx's error once ctx is done, before any access is recorded.
+func (h *HybridSearcher) Search(ctx context.Context, params SearchParams) ([]Result, int, int, time.Duration, error) {
 	start := time.Now()
 	merged := make(map[string]*Result)
 	vectorCount := 0
@@ -166,7 +209,7 @@ func (h *HybridSearcher) Search(params SearchParams) ([]Result, int, int, time.D
 	if mode == models.SearchModeHybrid || mode == models.SearchModeVector {
 		// Short-term: brute-force cosine on SQLite BLOBs
 		if params.Tier == "" || params.Tier == string(models.TierShort) {
-			shortMems, err := h.memoryStore.GetShortTermWithEmbeddings(params.WorkspaceIDs)
+			shortMems, err := h.memoryStore.GetShortTermWithEmbeddings(ctx, params.WorkspaceIDs)
 			if err != nil {
 				return nil, 0, 0, 0, err
 			}
@@ -183,20 +226,23 @@ func (h *HybridSearcher) Search(params SearchParams) ([]Result, int, int, time.D
 			}
 		}
 
-		// Long-term: Qdrant ANN search per workspace collection
+		// Long-term: Qdrant ANN search in each collection the sharding
+		// strategy routes the workspaces to
 		if params.Tier == "" || params.Tier == string(models.TierLong) {
-			for _, wsID := range params.WorkspaceIDs {
-				colName := vectorstore.CollectionName(wsID)
-				exists, err := h.qdrantClient.CollectionExists(colName)
-				if err != nil || !exists {
-					continue
-				}
-				results, err := h.qdrantClient.Search(colName, params.QueryVector, params.MaxResults*2, params.MinScore)
+			targets, err := h.collMgr.SearchTargets(ctx, params.WorkspaceIDs)
+			if err != nil && ctx.Err() != nil {
+				return nil, 0, 0, 0, ctx.Err()
+			}
+			for _, target := range targets {
+				results, err := h.qdrantClient.Search(ctx, target.Collection, params.QueryVector, params.MaxResults*2, params.MinScore, target.WorkspaceIDs...)
 				if err != nil {
+					if ctx.Err() != nil {
+						return nil, 0, 0, 0, ctx.Err()
+					}
 					continue // Non-fatal: skip this collection
 				}
 				for _, r := range results {
-					mem, err := h.memoryStore.GetByID(r.ID)
+					mem, err := h.memoryStore.GetByID(ctx, r.ID)
 					if err != nil || mem == nil {
 						continue
 					}
@@ -212,7 +258,10 @@ func (h *HybridSearcher) Search(params SearchParams) ([]Result, int, int, time.D
 
 	// BM25 search
 	if mode == models.SearchModeHybrid || mode == models.SearchModeBM25 {
-		bm25Results, err := h.bm25Store.Search(params.QueryText, params.WorkspaceIDs, params.MaxResults*3)
+		bm25Results, err := h.bm25Store.Search(ctx, params.QueryText, params.WorkspaceIDs, params.MaxResults*3)
+		if err != nil && ctx.Err() != nil {
+			return nil, 0, 0, 0, ctx.Err()
+		}
 		if err == nil {
 			// Normalize BM25 scores: scale to [0, 1] range
 			maxRank := 0.0
@@ -222,7 +271,7 @@ func (h *HybridSearcher) Search(params SearchParams) ([]Result, int, int, time.D
 				}
 			}
 			for _, r := range bm25Results {
-				mem, err := h.memoryStore.GetByID(r.ID)
+				mem, err := h.memoryStore.GetByID(ctx, r.ID)
 				if err != nil || mem == nil {
 					continue
 				}
@@ -239,10 +288,18 @@ func (h *HybridSearcher) Search(params SearchParams) ([]Result, int, int, time.D
 					boost = h.longTermBoost
 				}
 				h.addOrUpdateCogSci(merged, mem, 0, normalizedScore, boost, params.SessionContext)
+				if res, ok := merged[mem.ID]; ok {
+					res.Snippet = r.Snippet
+				}
 			}
 		}
 	}
 
+	// Thread anchoring: prefer context gathered for the active feature
+	if len(params.AnchorIDs) > 0 {
+		h.applyThreadBoost(ctx, merged, params.AnchorIDs)
+	}
+
 	// Sort by final score
 	results := make([]Result, 0, len(merged))
 	for _, r := range merged {
@@ -254,14 +311,21 @@ func (h *HybridSearcher) Search(params SearchParams) ([]Result, int, int, time.D
 		return results[i].FinalScore > results[j].FinalScore
 	})
 
-	// Limit
-	if len(results) > params.MaxResults {
-		results = results[:params.MaxResults]
+	// Re-rank the best candidates before the limit, so it can promote a
+	// result the blended score would have cut. A failing reranker degrades
+	// to the blended order rather than failing the search.
+	if params.Rerank && h.reranker != nil && len(results) > 1 {
+		if err := h.rerank(ctx, params.QueryText, results); err != nil && ctx.Err() != nil {
+			return nil, 0, 0, 0, ctx.Err()
+		}
 	}
 
+	// Limit
+	results = limitResults(results, params)
+
 	// Feature 4: Spreading Activation — one-hop boost from linked memories
 	if h.linkStore != nil && len(results) > 0 {
-		results = h.applySpreadingActivation(results, merged, params)
+		results = h.applySpreadingActivation(ctx, results, merged, params)
 	}
 
 	// Re-sort after spreading activation
@@ -270,8 +334,12 @@ func (h *HybridSearcher) Search(params SearchParams) ([]Result, int, int, time.D
 	})
 
 	// Final limit
-	if len(results) > params.MaxResults {
-		results = results[:params.MaxResults]
+	results = limitResults(results, params)
+
+	// A caller that has gone away never sees these results, so don't count
+	// them as accesses.
+	if err := ctx.Err(); err != nil {
+		return nil, 0, 0, 0, err
 	}
 
 	// Post-search: increment access counts and update stability for returned results.
@@ -279,15 +347,16 @@ func (h *HybridSearcher) Search(params SearchParams) ([]Result, int, int, time.D
 	resultIDs := make([]string, len(results))
 	for i, r := range results {
 		resultIDs[i] = r.Memory.ID
-		_ = h.memoryStore.IncrementAccessCount(r.Memory.ID)
-		_ = h.memoryStore.UpdateStabilityOnAccess(r.Memory.ID, r.Memory.ImpactScore)
+		r.Memory.ImpactScore = h.currentImpact(r.Memory)
+		_ = h.memoryStore.IncrementAccessCount(ctx, r.Memory.ID)
+		_ = h.memoryStore.UpdateStabilityOnAccess(ctx, r.Memory.ID, r.Memory.ImpactScore)
 	}
 
 	// Feature 4: Build co_accessed links between co-retrieved memories
 	if h.linkStore != nil && len(resultIDs) > 1 {
 		for i := 0; i < len(resultIDs); i++ {
 			for j := i + 1; j < len(resultIDs); j++ {
-				_ = h.linkStore.CreateOrStrengthen(resultIDs[i], resultIDs[j], "co_accessed", 0.1)
+				_ = h.linkStore.CreateOrStrengthen(ctx, resultIDs[i], resultIDs[j], string(models.LinkCoAccessed), 0.1)
 			}
 		}
 	}
@@ -346,17 +415,77 @@ func (h *HybridSearcher) addOrUpdateCogSci(
 	}
 }
 
+// Thread anchoring multipliers for thread entries and for memories one link
+// away from an entry. They reorder close matches without letting weak
+// thread context crowd out strong global hits.
+const (
+	threadEntryBoost = 1.3
+	threadLinkBoost  = 1.15
+)
+
+// applyThreadBoost scales the scores of candidates that are entries of the
+// active thread, or are linked to one.
+func (h *HybridSearcher) applyThreadBoost(ctx context.Context, merged map[string]*Result, anchorIDs []string) {
+	anchors := make(map[string]bool, len(anchorIDs))
+	for _, id := range anchorIDs {
+		anchors[id] = true
+	}
+
+	for id, r := range merged {
+		boost := 1.0
+		if anchors[id] {
+			boost = threadEntryBoost
+		} else if h.linkStore != nil {
+			links, err := h.linkStore.GetLinked(ctx, id, 20)
+			if err != nil {
+				continue
+			}
+			for _, link := range links {
+				if activation(link.LinkType) == 0 {
+					continue
+				}
+				if anchors[link.SourceID] || anchors[link.TargetID] {
+					boost = threadLinkBoost
+					break
+				}
+			}
+		}
+		if boost > 1 {
+			r.FinalScore *= boost
+			r.Anchored = true
+		}
+	}
+}
+
+// linkActivation scales spreading activation by link type. A
+// contradiction is no reason to rank its counterpart higher, and a cause is
+// context rather than an answer. Unknown types spread fully.
+var linkActivation = map[models.LinkType]float64{
+	models.LinkSupports:    1.0,
+	models.LinkRefines:     1.0,
+	models.LinkCausedBy:    0.5,
+	models.LinkContradicts: 0,
+	models.LinkCoAccessed:  1.0,
+}
+
+func activation(linkType string) float64 {
+	if w, ok := linkActivation[models.LinkType(linkType)]; ok {
+		return w
+	}
+	return 1
+}
+
 // applySpreadingActivation does a one-hop activation boost for the top-3 results.
 // Linked memories that aren't already in results get an additive boost of
-// link.Strength × 0.1, capped at 0.2 total.
-func (h *HybridSearcher) applySpreadingActivation(results []Result, merged map[string]*Result, params SearchParams) []Result {
+// link.Strength × 0.1 × the link type's weight, capped at 0.2 total.
+func (h *HybridSearcher) applySpreadingActivation(ctx context.Context, results []Result, merged map[string]*Result, params SearchParams) []Result {
 	topN := 3
 	if len(results) < topN {
 		topN = len(results)
 	}
 
 	for i := 0; i < topN; i++ {
-		links, err := h.linkStore.GetLinked(results[i].Memory.ID, 5)
+		links, err := h.linkStore.GetLinked(ctx, results[i].Memory.ID, 5)
 		if err != nil {
 			continue
 		}
@@ -367,7 +496,11 @@ func (h *HybridSearcher) applySpreadingActivation(results []Result, merged map[s
 				linkedID = link.SourceID
 			}
 
-			activationBoost := link.Strength * 0.1
+			weight := activation(link.LinkType)
+			if weight == 0 {
+				continue
+			}
+			activationBoost := link.Strength * 0.1 * weight
 			if activationBoost > 0.2 {
 				activationBoost = 0.2
 			}
@@ -377,7 +510,7 @@ func (h *HybridSearcher) applySpreadingActivation(results []Result, merged map[s
 				existing.FinalScore += activationBoost
 			} else {
 				// Not in results — fetch and add with spreading activation bonus
-				mem, err := h.memoryStore.GetByID(linkedID)
+				mem, err := h.memoryStore.GetByID(ctx, linkedID)
 				if err != nil || mem == nil {
 					continue
 				}
@@ -403,7 +536,36 @@ func (h *HybridSearcher) applySpreadingActivation(results []Result, merged map[s
 	return results
 }
 
+// limitResults applies per-type caps (if any) to score-ordered results and
+// truncates to MaxResults, so capped types leave room for other types.
+func limitResults(results []Result, p SearchParams) []Result {
+	if len(p.TypeCaps) > 0 || p.DefaultTypeCap > 0 {
+		counts := make(map[models.MemoryType]int)
+		capped := results[:0]
+		for _, r := range results {
+			limit, ok := p.TypeCaps[r.Memory.MemoryType]
+			if !ok {
+				limit = p.DefaultTypeCap
+			}
+			if limit > 0 && counts[r.Memory.MemoryType] >= limit {
+				continue
+			}
+			counts[r.Memory.MemoryType]++
+			capped = append(capped, r)
+		}
+		results = capped
+	}
+
+	if len(results) > p.MaxResults {
+		results = results[:p.MaxResults]
+	}
+	return results
+}
+
 func (h *HybridSearcher) matchesFilters(m *models.Memory, p SearchParams) bool {
+	if !p.Caller.CanSee(m) {
+		return false
+	}
 	if len(p.MemoryTypes) > 0 {
 		found := false
 		for _, t := range p.MemoryTypes {
@@ -0,0 +1,64 @@
+package search
+
+import (
+	"context"
+	"sort"
+)
+
+// Reranker scores documents against a query with a cross-encoder, which
+// reads the query and each document together and so ranks more precisely
+// than comparing separately computed embeddings.
+type Reranker interface {
+	// Rerank returns one relevance score per document, in document order.
+	// Higher is more relevant; scores are only comparable within one call.
+	Rerank(ctx context.Context, query string, documents []string) ([]float64, error)
+}
+
+// DefaultRerankTopK is how many candidates are reranked when SetReranker
+// is given no limit.
+const DefaultRerankTopK = 20
+
+// SetReranker enables the re-ranking stage for searches that ask for it,
+// applied to the topK best blended candidates.
+func (h *HybridSearcher) SetReranker(r Reranker, topK int) {
+	if topK <= 0 {
+		topK = DefaultRerankTopK
+	}
+	h.reranker = r
+	h.rerankTopK = topK
+}
+
+// CanRerank reports whether a reranker is configured.
+func (h *HybridSearcher) CanRerank() bool {
+	return h.reranker != nil
+}
+
+// rerank reorders the head of results, which must be sorted by FinalScore,
+// by the reranker's scores. The head's existing scores are handed out again
+// in the new order, so later stages and MinScore keep working on the blended
+// scale. On a reranker error results are left in blended order.
+func (h *HybridSearcher) rerank(ctx context.Context, query string, results []Result) error {
+	head := results[:min(len(results), h.rerankTopK)]
+	docs := make([]string, len(head))
+	scores := make([]float64, len(head))
+	for i, r := range head {
+		docs[i] = r.Memory.Content
+		scores[i] = r.FinalScore
+	}
+
+	relevance, err := h.reranker.Rerank(ctx, query, docs)
+	if err != nil {
+		return err
+	}
+	for i := range head {
+		score := relevance[i]
+		head[i].RerankScore = &score
+	}
+	sort.SliceStable(head, func(i, j int) bool {
+		return *head[i].RerankScore > *head[j].RerankScore
+	})
+	for i := range head {
+		head[i].FinalScore = scores[i]
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+// Package seed loads fixture workspaces, memories and threads into a
+// memory server, so demo environments and integration setups can be
+// rebuilt in one step.
+package seed
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+	"github.com/iammorganparry/clive/apps/memory/internal/threads"
+)
+
+// Fixture is the contents of one fixture file. A YAML file holds one
+// Fixture; an NDJSON file holds one record per line, each with a kind of
+// workspace, memory or thread.
+type Fixture struct {
+	Workspaces []Workspace `json:"workspaces" yaml:"workspaces"`
+	Memories   []Memory    `json:"memories" yaml:"memories"`
+	Threads    []Thread    `json:"threads" yaml:"threads"`
+}
+
+// Workspace registers a workspace that may have no memories yet.
+type Workspace struct {
+	Path string `json:"path" yaml:"path"`
+}
+
+// Memory is stored as if through POST /memories, so it is embedded and
+// deduplicated like any other.
+type Memory struct {
+	Workspace  string            `json:"workspace" yaml:"workspace"`
+	Global     bool              `json:"global" yaml:"global"`
+	Content    string            `json:"content" yaml:"content"`
+	MemoryType models.MemoryType `json:"memoryType" yaml:"memoryType"`
+	Tier       models.Tier       `json:"tier" yaml:"tier"`
+	Confidence float64           `json:"confidence" yaml:"confidence"`
+	Tags       []string          `json:"tags" yaml:"tags"`
+	Source     string            `json:"source" yaml:"source"`
+}
+
+// Thread is created with its entries appended in order.
+type Thread struct {
+	Workspace   string   `json:"workspace" yaml:"workspace"`
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description" yaml:"description"`
+	Tags        []string `json:"tags" yaml:"tags"`
+	Entries     []Entry  `json:"entries" yaml:"entries"`
+}
+
+// Entry is one thread entry.
+type Entry struct {
+	Section    models.ThreadSection `json:"section" yaml:"section"`
+	Content    string               `json:"content" yaml:"content"`
+	MemoryType models.MemoryType    `json:"memoryType" yaml:"memoryType"`
+}
+
+// Report counts what a load did. Memories already stored are counted as
+// deduplicated and threads that already exist are skipped, so loading the
+// same fixtures twice adds nothing.
+type Report struct {
+	Files          int `json:"files"`
+	Workspaces     int `json:"workspaces"`
+	Memories       int `json:"memories"`
+	Deduplicated   int `json:"deduplicated"`
+	Threads        int `json:"threads"`
+	SkippedThreads int `json:"skippedThreads"`
+	Entries        int `json:"entries"`
+}
+
+// Loader loads fixture directories through the memory and thread services.
+type Loader struct {
+	svc        *memory.Service
+	threadSvc  *threads.Service
+	workspaces *store.WorkspaceStore
+	logger     *slog.Logger
+}
+
+func NewLoader(svc *memory.Service, threadSvc *threads.Service, workspaces *store.WorkspaceStore, logger *slog.Logger) *Loader {
+	return &Loader{svc: svc, threadSvc: threadSvc, workspaces: workspaces, logger: logger}
+}
+
+// Load reads every .yaml, .yml, .ndjson and .jsonl file in dir, in name
+// order, and loads it into namespace. All files are parsed before anything
+// is written, so a malformed fixture loads nothing.
+func (l *Loader) Load(ctx context.Context, namespace, dir string) (*Report, error) {
+	fixtures, err := ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Files: len(fixtures)}
+	for _, f := range fixtures {
+		if err := l.load(ctx, namespace, f, report); err != nil {
+			return report, err
+		}
+	}
+	l.logger.Info("seed loaded", "dir", dir, "memories", report.Memories, "threads", report.Threads)
+	return report, nil
+}
+
+func (l *Loader) load(ctx context.Context, namespace string, f Fixture, report *Report) error {
+	for _, ws := range f.Workspaces {
+		if _, err := l.workspaces.EnsureWorkspace(ctx, namespace, ws.Path); err != nil {
+			return fmt.Errorf("seed workspace %s: %w", ws.Path, err)
+		}
+		report.Workspaces++
+	}
+
+	for _, m := range f.Memories {
+		resp, err := l.svc.Store(ctx, &models.StoreRequest{
+			Namespace:  namespace,
+			Workspace:  m.Workspace,
+			Global:     m.Global,
+			Content:    m.Content,
+			MemoryType: m.MemoryType,
+			Tier:       m.Tier,
+			Confidence: m.Confidence,
+			Tags:       m.Tags,
+			Source:     m.Source,
+		})
+		if err != nil {
+			return fmt.Errorf("seed memory %q: %w", truncate(m.Content), err)
+		}
+		if resp.Deduplicated {
+			report.Deduplicated++
+		} else {
+			report.Memories++
+		}
+	}
+
+	for _, t := range f.Threads {
+		if l.threadSvc == nil {
+			return apperr.ValidationFailed("threads_disabled", "fixtures hold threads but threads are not enabled")
+		}
+		thread, err := l.threadSvc.Create(ctx, &models.CreateThreadRequest{
+			Namespace:   namespace,
+			Workspace:   t.Workspace,
+			Name:        t.Name,
+			Description: t.Description,
+			Tags:        t.Tags,
+		})
+		if apperr.Is(err, apperr.KindConflict) {
+			report.SkippedThreads++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("seed thread %s: %w", t.Name, err)
+		}
+		report.Threads++
+
+		if len(t.Entries) == 0 {
+			continue
+		}
+		reqs := make([]*models.AppendEntryRequest, len(t.Entries))
+		for i, e := range t.Entries {
+			reqs[i] = &models.AppendEntryRequest{
+				Namespace:  namespace,
+				Content:    e.Content,
+				Section:    e.Section,
+				MemoryType: e.MemoryType,
+			}
+		}
+		// Batches are capped, so long threads go in several
+		for len(reqs) > 0 {
+			n := min(len(reqs), threads.MaxBatchEntries)
+			if _, err := l.threadSvc.AppendEntries(ctx, thread.ID, reqs[:n]); err != nil {
+				return fmt.Errorf("seed thread %s entries: %w", t.Name, err)
+			}
+			report.Entries += n
+			reqs = reqs[n:]
+		}
+	}
+	return nil
+}
+
+// ReadDir parses and validates every fixture file in dir.
+func ReadDir(dir string) ([]Fixture, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, apperr.ValidationFailed("invalid_seed_dir", "read seed dir: %s", err)
+	}
+	var names []string
+	for _, e := range entries {
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".yaml", ".yml", ".ndjson", ".jsonl":
+			if !e.IsDir() {
+				names = append(names, e.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+
+	fixtures := make([]Fixture, 0, len(names))
+	for _, name := range names {
+		f, err := readFile(filepath.Join(dir, name))
+		if err == nil {
+			err = f.validate()
+		}
+		if err != nil {
+			return nil, apperr.ValidationFailed("invalid_fixture", "%s: %s", name, err)
+		}
+		fixtures = append(fixtures, f)
+	}
+	return fixtures, nil
+}
+
+func readFile(path string) (Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Fixture{}, err
+	}
+
+	var f Fixture
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+			return Fixture{}, err
+		}
+		return f, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64<<10), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var kind struct {
+			Kind string `json:"kind"`
+		}
+		if err := json.Unmarshal([]byte(text), &kind); err != nil {
+			return Fixture{}, fmt.Errorf("line %d: %w", line, err)
+		}
+		var target any
+		switch kind.Kind {
+		case "workspace":
+			f.Workspaces = append(f.Workspaces, Workspace{})
+			target = &f.Workspaces[len(f.Workspaces)-1]
+		case "memory":
+			f.Memories = append(f.Memories, Memory{})
+			target = &f.Memories[len(f.Memories)-1]
+		case "thread":
+			f.Threads = append(f.Threads, Thread{})
+			target = &f.Threads[len(f.Threads)-1]
+		default:
+			return Fixture{}, fmt.Errorf("line %d: kind must be workspace, memory or thread, got %q", line, kind.Kind)
+		}
+		if err := json.Unmarshal([]byte(text), target); err != nil {
+			return Fixture{}, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return f, scanner.Err()
+}
+
+// validate checks what the services would otherwise reject part way
+// through a load.
+func (f Fixture) validate() error {
+	for i, ws := range f.Workspaces {
+		if !filepath.IsAbs(ws.Path) && !store.IsRemoteWorkspace(ws.Path) {
+			return fmt.Errorf("workspace %d: path must be absolute or a git remote", i)
+		}
+	}
+	for i, m := range f.Memories {
+		if m.Content == "" {
+			return fmt.Errorf("memory %d: content is required", i)
+		}
+		if !m.MemoryType.IsValid() {
+			return fmt.Errorf("memory %d: invalid memoryType %q", i, m.MemoryType)
+		}
+		if m.Tier != "" && m.Tier != models.TierShort && m.Tier != models.TierLong {
+			return fmt.Errorf("memory %d: tier must be short or long", i)
+		}
+	}
+	for i, t := range f.Threads {
+		if t.Name == "" || t.Workspace == "" {
+			return fmt.Errorf("thread %d: name and workspace are required", i)
+		}
+		for j, e := range t.Entries {
+			if e.Content == "" {
+				return fmt.Errorf("thread %s entry %d: content is required", t.Name, j)
+			}
+			if e.Section != "" && !e.Section.IsValid() {
+				return fmt.Errorf("thread %s entry %d: invalid section %q", t.Name, j, e.Section)
+			}
+			if e.MemoryType != "" && !e.MemoryType.IsValid() {
+				return fmt.Errorf("thread %s entry %d: invalid memoryType %q", t.Name, j, e.MemoryType)
+			}
+		}
+	}
+	return nil
+}
+
+func truncate(s string) string {
+	if len(s) > 40 {
+		return s[:40] + "..."
+	}
+	return s
+}
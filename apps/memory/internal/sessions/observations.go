@@ -62,6 +62,52 @@ func (s *ObservationStore) Insert(sessionID string, req *models.StoreObservation
 	}, nil
 }
 
+// InsertBatch stores observations in order in one transaction, with the
+// same privacy filtering as Insert. It returns the last sequence number
+// assigned.
+func (s *ObservationStore) InsertBatch(sessionID string, reqs []models.StoreObservationRequest) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var seq int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(sequence), 0) FROM observations WHERE session_id = ?`, sessionID).Scan(&seq); err != nil {
+		return 0, fmt.Errorf("get sequence: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO observations (id, session_id, tool_name, input, output, success, created_at, sequence)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now().Unix()
+	for _, req := range reqs {
+		seq++
+		successInt := 1
+		if !req.Success {
+			successInt = 0
+		}
+		_, err := stmt.Exec(uuid.New().String(), sessionID, req.ToolName,
+			truncateStr(privacy.StripPrivateTags(req.Input), 500),
+			truncateStr(privacy.StripPrivateTags(req.Output), 200),
+			successInt, now, seq)
+		if err != nil {
+			return 0, fmt.Errorf("insert observation: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return seq, nil
+}
+
 // ListBySession returns all observations for a session, ordered by sequence.
 func (s *ObservationStore) ListBySession(sessionID string, limit int) ([]*models.Observation, error) {
 	if limit <= 0 {
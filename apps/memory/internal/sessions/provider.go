@@ -0,0 +1,185 @@
+package sessions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Usage is the token accounting reported for one generation.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Total returns prompt plus completion tokens.
+func (u Usage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Provider runs a single-prompt, non-streaming text generation. It stops
+// early when ctx is cancelled.
+type Provider interface {
+	Generate(ctx context.Context, model, prompt string) (string, Usage, error)
+}
+
+// generationTimeout bounds a single generation; LLM generation can be slow.
+const generationTimeout = 120 * time.Second
+
+// OllamaProvider generates text with Ollama's /api/generate endpoint.
+type OllamaProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewOllamaProvider creates a provider for the Ollama server at baseURL.
+func NewOllamaProvider(baseURL string) *OllamaProvider {
+	return &OllamaProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: generationTimeout},
+	}
+}
+
+// ollamaRequest is the request body for Ollama /api/generate.
+type ollamaRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+}
+
+// ollamaResponse is the response body from Ollama /api/generate.
+type ollamaResponse struct {
+	Response        string `json:"response"`
+	Done            bool   `json:"done"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+}
+
+// Generate runs a non-streaming Ollama completion for prompt.
+func (p *OllamaProvider) Generate(ctx context.Context, model, prompt string) (string, Usage, error) {
+	body, err := json.Marshal(ollamaRequest{
+		Model:  model,
+		Prompt: prompt,
+		Stream: false,
+	})
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("ollama generate: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", Usage{}, fmt.Errorf("ollama returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var ollamaResp ollamaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
+		return "", Usage{}, fmt.Errorf("decode ollama response: %w", err)
+	}
+
+	if ollamaResp.Response == "" {
+		return "", Usage{}, fmt.Errorf("empty response from ollama")
+	}
+
+	usage := Usage{PromptTokens: ollamaResp.PromptEvalCount, CompletionTokens: ollamaResp.EvalCount}
+	return strings.TrimSpace(ollamaResp.Response), usage, nil
+}
+
+// OpenAIProvider generates text with an OpenAI-compatible
+// /chat/completions endpoint (OpenAI, OpenRouter, vLLM, LM Studio, ...).
+type OpenAIProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewOpenAIProvider creates a provider for the API at baseURL, e.g.
+// "https://api.openai.com/v1". apiKey may be empty for local servers.
+func NewOpenAIProvider(baseURL, apiKey string) *OpenAIProvider {
+	return &OpenAIProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: generationTimeout},
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// Generate sends prompt as a single user message.
+func (p *OpenAIProvider) Generate(ctx context.Context, model, prompt string) (string, Usage, error) {
+	body, err := json.Marshal(chatRequest{
+		Model:    model,
+		Messages: []chatMessage{{Role: "user", Content: prompt}},
+	})
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("chat completion: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", Usage{}, fmt.Errorf("chat completion returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var chatResp chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return "", Usage{}, fmt.Errorf("decode chat response: %w", err)
+	}
+	if len(chatResp.Choices) == 0 || strings.TrimSpace(chatResp.Choices[0].Message.Content) == "" {
+		return "", Usage{}, fmt.Errorf("empty response from chat completion")
+	}
+
+	usage := Usage{
+		PromptTokens:     chatResp.Usage.PromptTokens,
+		CompletionTokens: chatResp.Usage.CompletionTokens,
+	}
+	return strings.TrimSpace(chatResp.Choices[0].Message.Content), usage, nil
+}
@@ -1,36 +1,75 @@
 package sessions
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
-// Summarizer generates AI-compressed session summaries using Ollama.
+// Summarization features. Each can use its own model and daily token cap.
+const (
+	FeatureSession  = "session"  // session summaries
+	FeatureCondense = "condense" // oversized thread entries
+	FeatureDistill  = "distill"  // thread distillation on close
+)
+
+// Summarizer generates AI-compressed summaries through a chat provider.
 type Summarizer struct {
-	ollamaURL string
-	model     string
-	enabled   bool
-	logger    *slog.Logger
-	client    *http.Client
+	provider Provider
+	model    string
+	enabled  bool
+	logger   *slog.Logger
+
+	mu            sync.Mutex
+	featureModels map[string]string
+	tokenCaps     map[string]int
+	usageDay      string
+	usage         map[string]int // tokens used per feature on usageDay
 }
 
-// NewSummarizer creates a new session summarizer.
-func NewSummarizer(ollamaURL, model string, enabled bool, logger *slog.Logger) *Summarizer {
+// NewSummarizer creates a summarizer that uses model for every feature
+// unless overridden with SetFeatureModel.
+func NewSummarizer(provider Provider, model string, enabled bool, logger *slog.Logger) *Summarizer {
 	return &Summarizer{
-		ollamaURL: ollamaURL,
-		model:     model,
-		enabled:   enabled,
-		logger:    logger,
-		client: &http.Client{
-			Timeout: 120 * time.Second, // LLM generation can be slow
-		},
+		provider:      provider,
+		model:         model,
+		enabled:       enabled,
+		logger:        logger,
+		featureModels: make(map[string]string),
+		tokenCaps:     make(map[string]int),
+		usage:         make(map[string]int),
+	}
+}
+
+// SetFeatureModel overrides the model used for one feature.
+func (s *Summarizer) SetFeatureModel(feature, model string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.featureModels[feature] = model
+}
+
+// SetDailyTokenCap limits the tokens one feature may use per UTC day. Once
+// the cap is reached, generation for that feature fails until the next day
+// and callers fall back to their non-LLM path. Zero removes the cap.
+func (s *Summarizer) SetDailyTokenCap(feature string, tokens int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokenCaps[feature] = tokens
+}
+
+// TokensUsedToday returns the tokens each feature has used today.
+func (s *Summarizer) TokensUsedToday() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rollDay()
+	used := make(map[string]int, len(s.usage))
+	for k, v := range s.usage {
+		used[k] = v
 	}
+	return used
 }
 
 // IsEnabled returns whether summarization is active.
@@ -56,22 +95,9 @@ FILES: Key files that were modified or relevant
 ## Transcript
 %s`
 
-// ollamaRequest is the request body for Ollama /api/generate.
-type ollamaRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
-}
-
-// ollamaResponse is the response body from Ollama /api/generate.
-type ollamaResponse struct {
-	Response string `json:"response"`
-	Done     bool   `json:"done"`
-}
-
 // Summarize generates a structured summary from a session transcript.
 // Returns the summary text, or an error if generation fails.
-func (s *Summarizer) Summarize(transcript string) (string, error) {
+func (s *Summarizer) Summarize(ctx context.Context, transcript string) (string, error) {
 	if !s.enabled {
 		return "", fmt.Errorf("summarization disabled")
 	}
@@ -82,47 +108,90 @@ func (s *Summarizer) Summarize(transcript string) (string, error) {
 		transcript = transcript[:8000] + "\n\n[... middle truncated ...]\n\n" + transcript[len(transcript)-24000:]
 	}
 
-	prompt := fmt.Sprintf(summaryPrompt, transcript)
+	return s.generate(ctx, FeatureSession, fmt.Sprintf(summaryPrompt, transcript))
+}
+
+const condensePrompt = `Condense the following developer note to at most %d words.
+Keep file names, decisions, error messages and identifiers verbatim. Drop filler,
+repetition and narration. Output only the condensed note.
+
+## Note
+%s`
 
-	reqBody := ollamaRequest{
-		Model:  s.model,
-		Prompt: prompt,
-		Stream: false,
+// Condense shortens a single note so it fits within roughly maxTokens tokens.
+func (s *Summarizer) Condense(ctx context.Context, text string, maxTokens int) (string, error) {
+	if !s.enabled {
+		return "", fmt.Errorf("summarization disabled")
 	}
+	// ~0.75 words per token
+	return s.generate(ctx, FeatureCondense, fmt.Sprintf(condensePrompt, maxTokens*3/4, text))
+}
 
-	body, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("marshal request: %w", err)
+// generate runs prompt on the feature's model, enforcing its daily cap.
+// It fails fast when ctx is already done.
+func (s *Summarizer) generate(ctx context.Context, feature, prompt string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
 	}
 
-	url := strings.TrimRight(s.ollamaURL, "/") + "/api/generate"
-	resp, err := s.client.Post(url, "application/json", bytes.NewReader(body))
-	if err != nil {
-		return "", fmt.Errorf("ollama generate: %w", err)
+	s.mu.Lock()
+	s.rollDay()
+	model := s.model
+	if m, ok := s.featureModels[feature]; ok && m != "" {
+		model = m
 	}
-	defer resp.Body.Close()
+	limit, used := s.tokenCaps[feature], s.usage[feature]
+	s.mu.Unlock()
 
-	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("ollama returned %d: %s", resp.StatusCode, string(respBody))
+	if limit > 0 && used >= limit {
+		s.logger.Warn("summarizer daily token cap reached", "feature", feature, "used", used, "cap", limit)
+		return "", fmt.Errorf("daily token cap reached for %s (%d/%d)", feature, used, limit)
 	}
 
-	var ollamaResp ollamaResponse
-	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
-		return "", fmt.Errorf("decode ollama response: %w", err)
+	text, usage, err := s.provider.Generate(ctx, model, prompt)
+	if err != nil {
+		return "", err
 	}
 
-	if ollamaResp.Response == "" {
-		return "", fmt.Errorf("empty response from ollama")
+	s.mu.Lock()
+	s.rollDay()
+	s.usage[feature] += usage.Total()
+	s.mu.Unlock()
+	s.logger.Debug("summarizer generation", "feature", feature, "model", model,
+		"prompt_tokens", usage.PromptTokens, "completion_tokens", usage.CompletionTokens)
+
+	return text, nil
+}
+
+// rollDay resets usage at the start of each UTC day. Callers hold mu.
+func (s *Summarizer) rollDay() {
+	today := time.Now().UTC().Format("2006-01-02")
+	if s.usageDay != today {
+		s.usageDay = today
+		s.usage = make(map[string]int)
 	}
+}
+
+const distillPrompt = `Merge the following notes from the %q section of a feature thread into one
+durable knowledge note for future work on this codebase. Keep file names,
+decisions, error messages and identifiers verbatim. Drop anything only relevant
+while the feature was in progress. Output only the note.
+
+## Notes
+%s`
 
-	return strings.TrimSpace(ollamaResp.Response), nil
+// Distill merges a thread section's entries into a single durable note.
+func (s *Summarizer) Distill(ctx context.Context, section string, entries []string) (string, error) {
+	if !s.enabled {
+		return "", fmt.Errorf("summarization disabled")
+	}
+	return s.generate(ctx, FeatureDistill, fmt.Sprintf(distillPrompt, section, "- "+strings.Join(entries, "\n- ")))
 }
 
 // SummarizeWithObservations generates a summary incorporating tool observations.
-func (s *Summarizer) SummarizeWithObservations(transcript string, observations string) (string, error) {
+func (s *Summarizer) SummarizeWithObservations(ctx context.Context, transcript string, observations string) (string, error) {
 	if observations != "" {
 		transcript = transcript + "\n\n## Tool Observations\n" + observations
 	}
-	return s.Summarize(transcript)
+	return s.Summarize(ctx, transcript)
 }
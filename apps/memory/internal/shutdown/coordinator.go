@@ -0,0 +1,150 @@
+package shutdown
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Flusher persists buffered state after in-flight operations have drained.
+type Flusher func(ctx context.Context) error
+
+// flushTimeout bounds each flusher. Flushers get their own deadline because
+// the drain's may already have expired waiting on in-flight operations.
+const flushTimeout = 10 * time.Second
+
+type namedFlusher struct {
+	name string
+	fn   Flusher
+}
+
+// Coordinator tracks in-flight write operations so shutdown can stop
+// accepting new writes, wait for running ones to finish, and flush
+// downstream stores before the process exits.
+type Coordinator struct {
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	draining bool
+	inFlight map[uint64]string
+	nextID   uint64
+	idle     chan struct{}
+	rejected int
+	flushers []namedFlusher
+}
+
+// NewCoordinator creates a coordinator that accepts operations until Drain.
+func NewCoordinator(logger *slog.Logger) *Coordinator {
+	return &Coordinator{
+		logger:   logger,
+		inFlight: make(map[uint64]string),
+	}
+}
+
+// Manifest summarizes a shutdown for the final log line.
+type Manifest struct {
+	InFlightAtStart int      `json:"inFlightAtStart"`
+	Drained         int      `json:"drained"`
+	Abandoned       []string `json:"abandoned,omitempty"`
+	Rejected        int      `json:"rejected"`
+	Flushed         []string `json:"flushed,omitempty"`
+	FlushErrors     []string `json:"flushErrors,omitempty"`
+	DurationMs      int64    `json:"durationMs"`
+	Clean           bool     `json:"clean"`
+}
+
+// Begin registers an in-flight operation. It returns false once draining has
+// started; otherwise the caller must invoke done when the operation ends.
+func (c *Coordinator) Begin(op string) (done func(), ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.draining {
+		c.rejected++
+		return nil, false
+	}
+
+	c.nextID++
+	id := c.nextID
+	c.inFlight[id] = op
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			delete(c.inFlight, id)
+			if c.idle != nil && len(c.inFlight) == 0 {
+				close(c.idle)
+				c.idle = nil
+			}
+		})
+	}, true
+}
+
+// Draining reports whether shutdown has started.
+func (c *Coordinator) Draining() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.draining
+}
+
+// AddFlusher registers a flush step run after draining, in registration order.
+func (c *Coordinator) AddFlusher(name string, fn Flusher) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flushers = append(c.flushers, namedFlusher{name: name, fn: fn})
+}
+
+// Drain stops accepting operations, waits for in-flight ones until ctx
+// expires, then runs the flushers, each with up to flushTimeout. Operations
+// still running at the deadline are reported as abandoned.
+func (c *Coordinator) Drain(ctx context.Context) Manifest {
+	start := time.Now()
+
+	c.mu.Lock()
+	c.draining = true
+	m := Manifest{InFlightAtStart: len(c.inFlight)}
+	var idle chan struct{}
+	if len(c.inFlight) > 0 {
+		idle = make(chan struct{})
+		c.idle = idle
+	}
+	c.mu.Unlock()
+
+	if idle != nil {
+		c.logger.Info("draining in-flight operations", "count", m.InFlightAtStart)
+		select {
+		case <-idle:
+		case <-ctx.Done():
+		}
+	}
+
+	c.mu.Lock()
+	for _, op := range c.inFlight {
+		m.Abandoned = append(m.Abandoned, op)
+	}
+	m.Drained = m.InFlightAtStart - len(m.Abandoned)
+	m.Rejected = c.rejected
+	flushers := c.flushers
+	c.mu.Unlock()
+
+	for _, f := range flushers {
+		if err := runFlusher(f.fn); err != nil {
+			m.FlushErrors = append(m.FlushErrors, f.name+": "+err.Error())
+			continue
+		}
+		m.Flushed = append(m.Flushed, f.name)
+	}
+
+	m.DurationMs = time.Since(start).Milliseconds()
+	m.Clean = len(m.Abandoned) == 0 && len(m.FlushErrors) == 0
+	return m
+}
+
+func runFlusher(fn Flusher) error {
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+	return fn(ctx)
+}
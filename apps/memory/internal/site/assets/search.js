@@ -0,0 +1,66 @@
+// Searches the index embedded in the page, so the site works offline and
+// loads no third-party code. Every query word must prefix-match a word of
+// a memory's title, tags or body; title matches rank highest, then tags.
+(function () {
+  var root = document.body.dataset.root || "";
+  var input = document.getElementById("search");
+  var list = document.getElementById("results");
+  var boosts = { title: 5, tags: 2, body: 1 };
+
+  function words(text) {
+    return text.toLowerCase().split(/[^\p{L}\p{N}]+/u).filter(Boolean);
+  }
+
+  var docs = JSON.parse(document.getElementById("search-index").textContent || "[]");
+  docs.forEach(function (d) {
+    d.words = { title: words(d.title), tags: words((d.tags || []).join(" ")), body: words(d.body) };
+  });
+
+  function matches(fieldWords, term) {
+    for (var i = 0; i < fieldWords.length; i++) {
+      if (fieldWords[i].lastIndexOf(term, 0) === 0) return true;
+    }
+    return false;
+  }
+
+  function search(q) {
+    var terms = words(q);
+    var hits = [];
+    if (!terms.length) return hits;
+    docs.forEach(function (d) {
+      var score = 0;
+      for (var i = 0; i < terms.length; i++) {
+        var termScore = 0;
+        for (var field in boosts) {
+          if (matches(d.words[field], terms[i])) termScore += boosts[field];
+        }
+        if (!termScore) return;
+        score += termScore;
+      }
+      hits.push({ doc: d, score: score });
+    });
+    hits.sort(function (a, b) { return b.score - a.score; });
+    return hits.map(function (h) { return h.doc; });
+  }
+
+  input.addEventListener("input", function () {
+    var q = input.value.trim();
+    list.innerHTML = "";
+    list.hidden = !q;
+    if (!q) return;
+    search(q).slice(0, 20).forEach(function (d) {
+      var li = document.createElement("li");
+      var a = document.createElement("a");
+      a.href = root + d.url;
+      a.textContent = d.title;
+      var type = document.createElement("small");
+      type.textContent = " " + d.type;
+      li.appendChild(a);
+      li.appendChild(type);
+      list.appendChild(li);
+    });
+    if (!list.children.length) {
+      list.innerHTML = "<li>No matches</li>";
+    }
+  });
+})();
@@ -0,0 +1,288 @@
+// Package site renders a workspace's long-term memories and distilled
+// threads as a static, searchable knowledge base that can be published
+// alongside other internal docs.
+package site
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+//go:embed templates/*.html assets/*
+var files embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"date": func(unix int64) string { return time.Unix(unix, 0).UTC().Format("2006-01-02") },
+}).ParseFS(files, "templates/*.html"))
+
+// distilledPrefix matches the "[Thread: name] [section] " header distilled
+// memories carry, which the thread page shows as headings instead.
+var distilledPrefix = regexp.MustCompile(`^\[Thread: [^\]]*\] \[[^\]]*\] `)
+
+// generated lists what Generate writes, so a regeneration can clear the
+// previous run's pages without touching anything else in the directory.
+var generated = []string{"index.html", "threads", "search-index.json", "search.js", "style.css"}
+
+// Report counts what a generation wrote.
+type Report struct {
+	Workspace string `json:"workspace"`
+	Memories  int    `json:"memories"`
+	Threads   int    `json:"threads"`
+	Dir       string `json:"dir"`
+}
+
+// Generator renders sites from the database.
+type Generator struct {
+	memories   *store.MemoryStore
+	threads    *store.ThreadStore
+	workspaces *store.WorkspaceStore
+}
+
+func NewGenerator(db *store.DB) *Generator {
+	return &Generator{
+		memories:   store.NewMemoryStore(db),
+		threads:    store.NewThreadStore(db),
+		workspaces: store.NewWorkspaceStore(db),
+	}
+}
+
+// entry is one memory as rendered and indexed.
+type entry struct {
+	ID        string
+	Title     string
+	Body      string
+	Type      models.MemoryType
+	Tags      []string
+	Section   string // distilled thread section
+	UpdatedAt int64
+}
+
+type typeGroup struct {
+	Type    models.MemoryType
+	Entries []entry
+}
+
+type threadPage struct {
+	Slug    string
+	Name    string
+	Thread  *models.FeatureThread // nil when the thread has since been deleted
+	Entries []entry
+}
+
+type pageData struct {
+	Title       string
+	Root        string // relative path back to the site root
+	Workspace   *models.Workspace
+	GeneratedAt int64
+	Groups      []typeGroup
+	Threads     []*threadPage
+	Thread      *threadPage
+	Count       int
+	Index       []indexDoc // embedded in every page, so search works offline
+}
+
+type indexDoc struct {
+	ID    string   `json:"id"`
+	URL   string   `json:"url"`
+	Title string   `json:"title"`
+	Body  string   `json:"body"`
+	Type  string   `json:"type"`
+	Tags  []string `json:"tags"`
+}
+
+// Generate writes the site for workspaceID into dir. Only public,
+// current long-term memories are published: short-term memories are
+// unvetted, and private or team memories must not leak to a shared site.
+func (g *Generator) Generate(ctx context.Context, workspaceID, dir string) (*Report, error) {
+	ws, err := g.workspaces.GetWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if ws == nil {
+		return nil, fmt.Errorf("workspace not found: %s", workspaceID)
+	}
+	mems, err := g.memories.GetLongTermByWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	byType := map[models.MemoryType][]entry{}
+	threads := map[string]*threadPage{}
+	slugs := map[string]bool{}
+	var docs []indexDoc
+	count := 0
+	for _, m := range mems {
+		if (m.SupersededBy != nil && *m.SupersededBy != "") || !(models.Caller{}).CanSee(m) {
+			continue
+		}
+		count++
+		e := entry{ID: m.ID, Body: m.Content, Type: m.MemoryType, Tags: m.Tags, UpdatedAt: m.UpdatedAt}
+
+		if name := threadName(m); name != "" {
+			page := threads[name]
+			if page == nil {
+				page = &threadPage{Slug: slug(name, slugs), Name: name}
+				if page.Thread, err = g.threads.GetThreadByName(ctx, workspaceID, name); err != nil {
+					return nil, err
+				}
+				threads[name] = page
+			}
+			e.Body = distilledPrefix.ReplaceAllString(m.Content, "")
+			e.Section = distilledSection(m)
+			e.Title = page.Name + ": " + e.Section
+			page.Entries = append(page.Entries, e)
+			docs = append(docs, doc(e, "threads/"+page.Slug+".html#m-"+e.ID))
+			continue
+		}
+
+		e.Title = title(m.Content)
+		byType[m.MemoryType] = append(byType[m.MemoryType], e)
+		docs = append(docs, doc(e, "index.html#m-"+e.ID))
+	}
+
+	data := pageData{
+		Title:       "Knowledge base: " + ws.Name,
+		Workspace:   ws,
+		GeneratedAt: time.Now().Unix(),
+		Count:       count,
+	}
+	for t, entries := range byType {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].UpdatedAt > entries[j].UpdatedAt })
+		data.Groups = append(data.Groups, typeGroup{Type: t, Entries: entries})
+	}
+	sort.Slice(data.Groups, func(i, j int) bool { return data.Groups[i].Type < data.Groups[j].Type })
+	for _, page := range threads {
+		sort.Slice(page.Entries, func(i, j int) bool { return page.Entries[i].Section < page.Entries[j].Section })
+		data.Threads = append(data.Threads, page)
+	}
+	sort.Slice(data.Threads, func(i, j int) bool { return data.Threads[i].Name < data.Threads[j].Name })
+	if docs == nil {
+		docs = []indexDoc{}
+	}
+	data.Index = docs
+
+	if err := removeGenerated(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "threads"), 0o755); err != nil {
+		return nil, err
+	}
+	if err := render(filepath.Join(dir, "index.html"), "index.html", data); err != nil {
+		return nil, err
+	}
+	for _, page := range data.Threads {
+		pd := data
+		pd.Title = "Thread: " + page.Name
+		pd.Root = "../"
+		pd.Thread = page
+		if err := render(filepath.Join(dir, "threads", page.Slug+".html"), "thread.html", pd); err != nil {
+			return nil, err
+		}
+	}
+
+	index, err := json.Marshal(docs)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "search-index.json"), index, 0o644); err != nil {
+		return nil, err
+	}
+	for _, name := range []string{"search.js", "style.css"} {
+		data, err := files.ReadFile("assets/" + name)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Report{Workspace: ws.Name, Memories: count, Threads: len(threads), Dir: dir}, nil
+}
+
+// removeGenerated removes the previous generation's files from dir.
+func removeGenerated(dir string) error {
+	for _, name := range generated {
+		if err := os.RemoveAll(filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("clear %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func render(path, name string, data pageData) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := templates.ExecuteTemplate(f, name, data); err != nil {
+		return fmt.Errorf("render %s: %w", filepath.Base(path), err)
+	}
+	return f.Close()
+}
+
+// threadName returns the thread a distilled memory came from.
+func threadName(m *models.Memory) string {
+	if m.Source != "thread-distill" {
+		return ""
+	}
+	for _, tag := range m.Tags {
+		if name, ok := strings.CutPrefix(tag, "thread:"); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// distilledSection returns the thread section a distilled memory holds,
+// which distillation records as a tag.
+func distilledSection(m *models.Memory) string {
+	for _, tag := range m.Tags {
+		if models.ThreadSection(tag).IsValid() {
+			return tag
+		}
+	}
+	return "notes"
+}
+
+func doc(e entry, url string) indexDoc {
+	return indexDoc{ID: e.ID, URL: url, Title: e.Title, Body: e.Body, Type: string(e.Type), Tags: e.Tags}
+}
+
+// title is a memory's first line, shortened for headings.
+func title(content string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+	if r := []rune(line); len(r) > 80 {
+		return string(r[:77]) + "..."
+	}
+	return line
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug turns a thread name into a file name not already in used.
+func slug(name string, used map[string]bool) string {
+	base := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if base == "" {
+		base = "thread"
+	}
+	s := base
+	for i := 2; used[s]; i++ {
+		s = fmt.Sprintf("%s-%d", base, i)
+	}
+	used[s] = true
+	return s
+}
@@ -1,86 +1,176 @@
 package skills
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
+	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
 	"github.com/iammorganparry/clive/apps/memory/internal/memory"
 	"github.com/iammorganparry/clive/apps/memory/internal/models"
 	"github.com/iammorganparry/clive/apps/memory/internal/store"
 	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
 )
 
-// SyncResult reports what happened during a skill sync.
+// Change actions reported per skill in a SyncResult.
+const (
+	ActionAdded     = "added"
+	ActionUpdated   = "updated"
+	ActionUnchanged = "unchanged"
+	ActionRemoved   = "removed"
+	ActionFailed    = "failed"
+)
+
+// SkillChange describes what a sync did, or would do, to one skill.
+type SkillChange struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// SyncResult reports what happened during a skill sync. In a dry run the
+// counts and changes describe what a real sync would do; nothing is written.
 type SyncResult struct {
-	Found  int `json:"found"`
-	Stored int `json:"stored"`
-	Errors int `json:"errors"`
+	DryRun           bool          `json:"dryRun"`
+	Found            int           `json:"found"`
+	Stored           int           `json:"stored"`
+	Unchanged        int           `json:"unchanged"`
+	Reembedded       int           `json:"reembedded"`
+	Removed          int           `json:"removed"`
+	Errors           int           `json:"errors"`
+	EmbeddingModel   string        `json:"embeddingModel"`
+	ReembeddedSkills []string      `json:"reembeddedSkills,omitempty"`
+	Changes          []SkillChange `json:"changes"`
+}
+
+func (r *SyncResult) record(name, action, reason string) {
+	r.Changes = append(r.Changes, SkillChange{Name: name, Action: action, Reason: reason})
 }
 
 // SyncService scans skill directories and stores skill descriptions
 // as SKILL_HINT memories in the global workspace.
 type SyncService struct {
-	svc          *memory.Service
-	memoryStore  *store.MemoryStore
-	qdrantClient *vectorstore.QdrantClient
-	dirs         []string
-	logger       *slog.Logger
+	svc         *memory.Service
+	memoryStore *store.MemoryStore
+	collMgr     *vectorstore.CollectionManager
+	dirs        []string
+	logger      *slog.Logger
 }
 
 // NewSyncService creates a new SyncService.
 func NewSyncService(
 	svc *memory.Service,
 	memoryStore *store.MemoryStore,
-	qdrantClient *vectorstore.QdrantClient,
+	collMgr *vectorstore.CollectionManager,
 	dirs []string,
 	logger *slog.Logger,
 ) *SyncService {
 	return &SyncService{
-		svc:          svc,
-		memoryStore:  memoryStore,
-		qdrantClient: qdrantClient,
-		dirs:         dirs,
-		logger:       logger,
+		svc:         svc,
+		memoryStore: memoryStore,
+		collMgr:     collMgr,
+		dirs:        dirs,
+		logger:      logger,
 	}
 }
 
-// Sync scans skill directories, removes old SKILL_HINT memories,
-// and stores fresh ones. This is idempotent.
-func (s *SyncService) Sync() (*SyncResult, error) {
-	return s.SyncDirs(s.dirs)
+// Sync scans skill directories and reconciles them with the stored
+// SKILL_HINT memories. This is idempotent.
+func (s *SyncService) Sync(ctx context.Context) (*SyncResult, error) {
+	return s.SyncDirs(ctx, s.dirs)
+}
+
+// Preview reports what Sync would do without writing anything.
+func (s *SyncService) Preview(ctx context.Context) (*SyncResult, error) {
+	return s.run(ctx, s.dirs, true)
 }
 
 // SyncDirs runs sync for specific directories (used by API override).
-func (s *SyncService) SyncDirs(dirs []string) (*SyncResult, error) {
+// Unchanged skills are kept, skills embedded with a different model than
+// the current one are re-embedded, new skills are stored and skills that
+// no longer exist are removed.
+func (s *SyncService) SyncDirs(ctx context.Context, dirs []string) (*SyncResult, error) {
+	return s.run(ctx, dirs, false)
+}
+
+// PreviewDirs is the dry-run counterpart of SyncDirs.
+func (s *SyncService) PreviewDirs(ctx context.Context, dirs []string) (*SyncResult, error) {
+	return s.run(ctx, dirs, true)
+}
+
+func (s *SyncService) run(ctx context.Context, dirs []string, dryRun bool) (*SyncResult, error) {
 	skills, err := ScanSkills(dirs)
 	if err != nil {
 		return nil, fmt.Errorf("scan skills: %w", err)
 	}
 
-	result := &SyncResult{Found: len(skills)}
+	model := s.svc.EmbeddingModel()
+	result := &SyncResult{DryRun: dryRun, Found: len(skills), EmbeddingModel: model, Changes: []SkillChange{}}
 
-	// Delete all existing SKILL_HINT memories from the global workspace
-	deletedIDs, err := s.memoryStore.DeleteByTypeAndWorkspace(
+	existing, err := s.memoryStore.GetByTypeAndWorkspace(ctx,
 		string(models.MemoryTypeSkillHint),
 		models.GlobalWorkspaceID,
 	)
 	if err != nil {
-		s.logger.Warn("failed to delete old skill hints", "error", err)
+		return nil, fmt.Errorf("load skill hints: %w", err)
 	}
-
-	// Clean up Qdrant points for deleted memories
-	if len(deletedIDs) > 0 {
-		colName := vectorstore.CollectionName(models.GlobalWorkspaceID)
-		if err := s.qdrantClient.DeletePoints(colName, deletedIDs); err != nil {
-			s.logger.Warn("failed to clean qdrant points", "error", err)
+	byHash := make(map[string]*models.Memory, len(existing))
+	byName := make(map[string]*models.Memory, len(existing))
+	for _, m := range existing {
+		byHash[m.ContentHash] = m
+		if name := skillName(m); name != "" {
+			byName[name] = m
 		}
 	}
+	// Hints being replaced by a new description are deleted with the stale
+	// ones but reported as updated rather than removed
+	replaced := make(map[string]bool)
 
-	// Store each skill as a SKILL_HINT memory
 	for _, skill := range skills {
 		content := fmt.Sprintf("[Skill: %s] %s", skill.Name, skill.Description)
-		tags := []string{"skill", fmt.Sprintf("skill:%s", skill.Name)}
+		hash := embedding.ContentHash(content)
 
+		if m, ok := byHash[hash]; ok {
+			delete(byHash, hash)
+			if m.EmbeddingModel == model {
+				result.Unchanged++
+				result.record(skill.Name, ActionUnchanged, "")
+				continue
+			}
+			reason := fmt.Sprintf("re-embed with %s (was %s)", model, m.EmbeddingModel)
+			if !dryRun {
+				if err := s.svc.Reembed(ctx, m); err != nil {
+					s.logger.Error("failed to re-embed skill hint",
+						"skill", skill.Name,
+						"error", err,
+					)
+					result.Errors++
+					result.record(skill.Name, ActionFailed, err.Error())
+					continue
+				}
+			}
+			result.Reembedded++
+			result.ReembeddedSkills = append(result.ReembeddedSkills, skill.Name)
+			result.record(skill.Name, ActionUpdated, reason)
+			continue
+		}
+
+		action, reason := ActionAdded, ""
+		old, ok := byName[skill.Name]
+		if ok && byHash[old.ContentHash] == old {
+			action, reason = ActionUpdated, "description changed"
+		}
+		if dryRun {
+			if action == ActionUpdated {
+				replaced[old.ID] = true
+			}
+			result.Stored++
+			result.record(skill.Name, action, reason)
+			continue
+		}
+
+		tags := []string{"skill", fmt.Sprintf("skill:%s", skill.Name)}
 		req := &models.StoreRequest{
 			Content:    content,
 			MemoryType: models.MemoryTypeSkillHint,
@@ -91,22 +181,77 @@ func (s *SyncService) SyncDirs(dirs []string) (*SyncResult, error) {
 			Global:     true,
 		}
 
-		_, err := s.svc.Store(req)
+		_, err := s.svc.Store(ctx, req)
 		if err != nil {
 			s.logger.Error("failed to store skill hint",
 				"skill", skill.Name,
 				"error", err,
 			)
 			result.Errors++
+			result.record(skill.Name, ActionFailed, err.Error())
+			// Keep the previous description rather than losing the skill
+			if action == ActionUpdated {
+				delete(byHash, old.ContentHash)
+			}
 			continue
 		}
 
+		if action == ActionUpdated {
+			replaced[old.ID] = true
+		}
 		result.Stored++
+		result.record(skill.Name, action, reason)
+	}
+
+	// Whatever is left no longer matches a skill on disk
+	var staleIDs []string
+	for _, m := range byHash {
+		name := skillName(m)
+		if name == "" {
+			name = m.ID
+		}
+		if !dryRun {
+			if err := s.memoryStore.Delete(ctx, m.ID); err != nil {
+				s.logger.Warn("failed to delete stale skill hint", "id", m.ID, "error", err)
+				result.Errors++
+				result.record(name, ActionFailed, err.Error())
+				continue
+			}
+		}
+		staleIDs = append(staleIDs, m.ID)
+		if !replaced[m.ID] {
+			result.record(name, ActionRemoved, "no longer on disk")
+		}
+	}
+	result.Removed = len(staleIDs)
+
+	// Clean up Qdrant points for deleted memories
+	if len(staleIDs) > 0 && !dryRun {
+		if err := s.collMgr.DeletePoints(ctx, models.GlobalWorkspaceID, staleIDs); err != nil {
+			s.logger.Warn("failed to clean qdrant points", "error", err)
+		}
+	}
+
+	if result.Reembedded > 0 && !dryRun {
+		s.logger.Info("re-embedded skill hints after model change",
+			"model", model,
+			"count", result.Reembedded,
+		)
 	}
 
 	return result, nil
 }
 
+// skillName recovers the skill name from a hint's "skill:<name>" tag.
+func skillName(m *models.Memory) string {
+	for _, tag := range m.Tags {
+		if name, ok := strings.CutPrefix(tag, "skill:"); ok {
+			return name
+		}
+	}
+	return ""
+}
+
 // ListSkills returns the currently scannable skills (without syncing).
 func (s *SyncService) ListSkills() ([]SkillMeta, error) {
 	return ScanSkills(s.dirs)
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// apiKeyPrefixLen is how much of a token is kept in the clear to identify
+// the key in listings.
+const apiKeyPrefixLen = 12
+
+const apiKeyColumns = `id, name, identity, prefix, namespace, workspace, workspace_id, access, created_at, revoked_at`
+
+// APIKeyStore keeps the scoped API keys. Tokens are random and only their
+// SHA-256 is stored, so a leaked database does not leak working keys.
+type APIKeyStore struct {
+	db *DB
+}
+
+func NewAPIKeyStore(db *DB) *APIKeyStore {
+	return &APIKeyStore{db: db}
+}
+
+// Create stores a new key with the given name, identity, scope and access, filling in
+// its ID, prefix and creation time. It returns the token, which is not
+// stored and cannot be recovered.
+func (s *APIKeyStore) Create(ctx context.Context, key *models.APIKey) (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("generate api key: %w", err)
+	}
+	token := "clive_" + hex.EncodeToString(secret)
+
+	key.ID = uuid.New().String()
+	key.Prefix = token[:apiKeyPrefixLen]
+	key.CreatedAt = time.Now().Unix()
+	key.RevokedAt = nil
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO api_keys (id, name, identity, token_hash, prefix, namespace, workspace, workspace_id, access, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, key.ID, key.Name, key.Identity, hashToken(token), key.Prefix, key.Namespace, key.Workspace, key.WorkspaceID, key.Access, key.CreatedAt)
+	if err != nil {
+		return "", fmt.Errorf("create api key: %w", err)
+	}
+	return token, nil
+}
+
+// Lookup returns the live key a token belongs to, or nil if the token is
+// unknown or its key has been revoked.
+func (s *APIKeyStore) Lookup(ctx context.Context, token string) (*models.APIKey, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE token_hash = ? AND revoked_at IS NULL`, hashToken(token))
+	key, err := scanAPIKey(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("look up api key: %w", err)
+	}
+	return key, nil
+}
+
+// List returns every key, revoked ones included, newest first.
+func (s *APIKeyStore) List(ctx context.Context) ([]models.APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at DESC, id`)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan api key: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke stops a key from authenticating. Revoking a revoked key keeps its
+// original revocation time.
+func (s *APIKeyStore) Revoke(ctx context.Context, id string) (*models.APIKey, error) {
+	_, err := s.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, time.Now().Unix(), id)
+	if err != nil {
+		return nil, fmt.Errorf("revoke api key: %w", err)
+	}
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("api_key_not_found", "no api key with id %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+	return key, nil
+}
+
+func scanAPIKey(row interface{ Scan(...any) error }) (*models.APIKey, error) {
+	var k models.APIKey
+	var revokedAt sql.NullInt64
+	err := row.Scan(&k.ID, &k.Name, &k.Identity, &k.Prefix, &k.Namespace, &k.Workspace, &k.WorkspaceID, &k.Access, &k.CreatedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		k.RevokedAt = &revokedAt.Int64
+	}
+	return &k, nil
+}
+
+func hashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,326 @@
+package store
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// archiveTable is one table in an archive. where selects a workspace's
+// rows: :ws is bound to the workspace ID and :visible limits memories to
+// the ones the exporting caller may read. Tables keyed by an
+// autoincrement ID drop it on export and are imported only alongside the
+// parent row named by parent, so a second import doesn't duplicate them.
+type archiveTable struct {
+	name   string
+	where  string
+	parent string // column naming the parent: memories.id or feature_threads.id
+}
+
+// archiveTables lists what an archive holds, parents before children.
+// Attachment blobs live on disk and are not archived.
+var archiveTables = []archiveTable{
+	{name: "workspaces", where: "id = :ws"},
+	{name: "workspace_aliases", where: "workspace_id = :ws"},
+	{name: "feature_threads", where: "workspace_id = :ws"},
+	{name: "memories", where: "workspace_id = :ws AND :visible"},
+	{name: "embedding_cache", where: "content_hash IN (SELECT content_hash FROM memories WHERE workspace_id = :ws AND :visible)"},
+	{name: "memory_links", parent: "source_id",
+		where: "source_id IN (SELECT id FROM memories WHERE workspace_id = :ws AND :visible) AND target_id IN (SELECT id FROM memories WHERE workspace_id = :ws AND :visible)"},
+	{name: "memory_impacts", parent: "memory_id",
+		where: "memory_id IN (SELECT id FROM memories WHERE workspace_id = :ws AND :visible)"},
+	{name: "thread_entries",
+		where: "thread_id IN (SELECT id FROM feature_threads WHERE workspace_id = :ws) AND memory_id IN (SELECT id FROM memories WHERE workspace_id = :ws AND :visible)"},
+	{name: "thread_entry_edits", parent: "thread_id",
+		where: "thread_id IN (SELECT id FROM feature_threads WHERE workspace_id = :ws)"},
+	{name: "sessions", where: "workspace_id = :ws"},
+	{name: "observations", where: "session_id IN (SELECT id FROM sessions WHERE workspace_id = :ws)"},
+}
+
+// archivePlaceholder matches the named placeholders in archiveTable.where.
+var archivePlaceholder = regexp.MustCompile(`:ws\b|:visible\b`)
+
+// archiveDangling clears optional references an import can leave pointing
+// outside the archive, e.g. to a thread in a workspace that wasn't exported.
+var archiveDangling = []string{
+	`UPDATE memories SET thread_id = NULL WHERE thread_id IS NOT NULL AND thread_id NOT IN (SELECT id FROM feature_threads)`,
+	`UPDATE memories SET superseded_by = NULL WHERE superseded_by IS NOT NULL AND superseded_by NOT IN (SELECT id FROM memories)`,
+	`UPDATE sessions SET summary_memory_id = NULL WHERE summary_memory_id IS NOT NULL AND summary_memory_id NOT IN (SELECT id FROM memories)`,
+}
+
+// archiveLocal lists columns holding this server's lifecycle state, which
+// an import doesn't restore: an imported memory wasn't promoted here, so
+// compaction never demotes it.
+var archiveLocal = map[string]bool{"promoted_at": true}
+
+// archiveRecord is one line of an archive after the header.
+type archiveRecord struct {
+	Table string         `json:"table"`
+	Row   map[string]any `json:"row"`
+}
+
+// archiveBlob wraps BLOB values, which JSON can't tell apart from text.
+type archiveBlob struct {
+	Base64 string `json:"$base64"`
+}
+
+// ArchiveStore dumps workspaces to portable archives and restores them.
+// An archive is gzipped JSON lines: an ArchiveHeader, then one record per
+// row. Rows are written by column name, so archives survive schema changes
+// in either direction.
+type ArchiveStore struct {
+	db *DB
+}
+
+func NewArchiveStore(db *DB) *ArchiveStore {
+	return &ArchiveStore{db: db}
+}
+
+// Export writes an archive of the given workspaces to w, read in one
+// transaction so it is a consistent snapshot. Every memory is included,
+// whatever its visibility; it is meant for operators with the database.
+func (s *ArchiveStore) Export(ctx context.Context, workspaceIDs []string, w io.Writer) error {
+	return s.export(ctx, workspaceIDs, "1 = 1", nil, w)
+}
+
+// ExportFor is Export limited to the memories caller may read. Links,
+// impacts, thread entries and cached embeddings of the others are left out
+// with them.
+func (s *ArchiveStore) ExportFor(ctx context.Context, caller models.Caller, workspaceIDs []string, w io.Writer) error {
+	visible, args := visibleTo(caller)
+	return s.export(ctx, workspaceIDs, visible, args, w)
+}
+
+func (s *ArchiveStore) export(ctx context.Context, workspaceIDs []string, visible string, visibleArgs []any, w io.Writer) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	gz := gzip.NewWriter(w)
+	enc := json.NewEncoder(gz)
+	header := models.ArchiveHeader{
+		Format:     models.ArchiveFormat,
+		Version:    models.ArchiveVersion,
+		ExportedAt: time.Now().Unix(),
+		Workspaces: workspaceIDs,
+	}
+	if err := enc.Encode(header); err != nil {
+		return fmt.Errorf("write archive header: %w", err)
+	}
+
+	for _, wsID := range workspaceIDs {
+		for _, t := range archiveTables {
+			if err := exportTable(ctx, tx, t, wsID, visible, visibleArgs, enc); err != nil {
+				return err
+			}
+		}
+	}
+	return gz.Close()
+}
+
+func exportTable(ctx context.Context, tx *sql.Tx, t archiveTable, workspaceID, visible string, visibleArgs []any, enc *json.Encoder) error {
+	var args []any
+	where := archivePlaceholder.ReplaceAllStringFunc(t.where, func(p string) string {
+		if p == ":ws" {
+			args = append(args, workspaceID)
+			return "?"
+		}
+		args = append(args, visibleArgs...)
+		return "(" + visible + ")"
+	})
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s WHERE %s ORDER BY rowid", t.name, where), args...)
+	if err != nil {
+		return fmt.Errorf("export %s: %w", t.name, err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("export %s: %w", t.name, err)
+	}
+	for rows.Next() {
+		values := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return fmt.Errorf("scan %s: %w", t.name, err)
+		}
+		row := make(map[string]any, len(cols))
+		for i, col := range cols {
+			if t.parent != "" && col == "id" {
+				continue
+			}
+			if b, ok := values[i].([]byte); ok {
+				row[col] = archiveBlob{Base64: base64.StdEncoding.EncodeToString(b)}
+				continue
+			}
+			row[col] = values[i]
+		}
+		if err := enc.Encode(archiveRecord{Table: t.name, Row: row}); err != nil {
+			return fmt.Errorf("write %s: %w", t.name, err)
+		}
+	}
+	return rows.Err()
+}
+
+// Import restores an archive in one transaction, skipping rows whose key
+// already exists. Columns this schema doesn't have are dropped, and ones
+// the archive lacks take their defaults. It returns the IDs of the
+// long-term memories it inserted, whose vectors live outside SQLite.
+func (s *ArchiveStore) Import(ctx context.Context, r io.Reader) (*models.ImportReport, []string, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, apperr.ValidationFailed("invalid_archive", "archive is not gzipped: %s", err)
+	}
+	defer gz.Close()
+	dec := json.NewDecoder(bufio.NewReader(gz))
+	dec.UseNumber()
+
+	var header models.ArchiveHeader
+	if err := dec.Decode(&header); err != nil || header.Format != models.ArchiveFormat {
+		return nil, nil, apperr.ValidationFailed("invalid_archive", "not a memory server archive")
+	}
+	if header.Version > models.ArchiveVersion {
+		return nil, nil, apperr.ValidationFailed("unsupported_archive_version",
+			"archive version %d is newer than this server supports (%d)", header.Version, models.ArchiveVersion)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	// Rows may arrive before what they reference, e.g. a memory superseded
+	// by a later one
+	if _, err := tx.ExecContext(ctx, `PRAGMA defer_foreign_keys = ON`); err != nil {
+		return nil, nil, fmt.Errorf("defer foreign keys: %w", err)
+	}
+
+	tables := make(map[string]archiveTable, len(archiveTables))
+	for _, t := range archiveTables {
+		tables[t.name] = t
+	}
+	report := &models.ImportReport{
+		Workspaces: header.Workspaces,
+		Imported:   map[string]int{},
+		Skipped:    map[string]int{},
+	}
+	columns := map[string]map[string]bool{}
+	inserted := map[string]bool{} // memory and thread IDs new to this database
+	var longTerm []string
+
+	for {
+		var rec archiveRecord
+		if err := dec.Decode(&rec); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, apperr.ValidationFailed("invalid_archive", "read archive: %s", err)
+		}
+		t, ok := tables[rec.Table]
+		if !ok {
+			report.Skipped[rec.Table]++
+			continue
+		}
+		if t.parent != "" {
+			if parent, _ := rec.Row[t.parent].(string); !inserted[parent] {
+				report.Skipped[t.name]++
+				continue
+			}
+		}
+		if columns[t.name] == nil {
+			if columns[t.name], err = tableColumns(ctx, tx, t.name); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		var names, marks []string
+		var args []any
+		for col, v := range rec.Row {
+			if !columns[t.name][col] || archiveLocal[col] {
+				continue
+			}
+			names = append(names, col)
+			marks = append(marks, "?")
+			args = append(args, archiveValue(v))
+		}
+		res, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT OR IGNORE INTO %s (%s) VALUES (%s)",
+			t.name, strings.Join(names, ", "), strings.Join(marks, ", ")), args...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("import %s: %w", t.name, err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			report.Skipped[t.name]++
+			continue
+		}
+		report.Imported[t.name]++
+
+		if t.name == "memories" || t.name == "feature_threads" {
+			id, _ := rec.Row["id"].(string)
+			inserted[id] = true
+			if tier, _ := rec.Row["tier"].(string); tier == string(models.TierLong) {
+				longTerm = append(longTerm, id)
+			}
+		}
+	}
+
+	for _, stmt := range archiveDangling {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return nil, nil, fmt.Errorf("clear dangling references: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("commit import: %w", err)
+	}
+	return report, longTerm, nil
+}
+
+func tableColumns(ctx context.Context, tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT name FROM pragma_table_info('%s')", table))
+	if err != nil {
+		return nil, fmt.Errorf("read %s columns: %w", table, err)
+	}
+	defer rows.Close()
+	cols := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("read %s columns: %w", table, err)
+		}
+		cols[name] = true
+	}
+	return cols, rows.Err()
+}
+
+// archiveValue turns a decoded JSON value back into a column value.
+func archiveValue(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		if s, ok := v["$base64"].(string); ok {
+			if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+				return b
+			}
+		}
+	}
+	return v
+}
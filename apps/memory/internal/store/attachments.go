@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+const attachmentColumns = `id, memory_id, filename, content_type, size, sha256,
+	embedding, embedding_model, created_at`
+
+// AttachmentStore handles attachment metadata in SQLite.
+type AttachmentStore struct {
+	db *DB
+}
+
+func NewAttachmentStore(db *DB) *AttachmentStore {
+	return &AttachmentStore{db: db}
+}
+
+// Insert records a new attachment.
+func (s *AttachmentStore) Insert(ctx context.Context, a *models.Attachment) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO attachments (
+			id, memory_id, filename, content_type, size, sha256,
+			embedding, embedding_model, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		a.ID, a.MemoryID, a.Filename, a.ContentType, a.Size, a.SHA256,
+		a.Embedding, nullString(a.EmbeddingModel), a.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert attachment: %w", err)
+	}
+	return nil
+}
+
+// Get returns an attachment by ID, or nil if it does not exist.
+func (s *AttachmentStore) Get(ctx context.Context, id string) (*models.Attachment, error) {
+	rows, err := s.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT %s FROM attachments WHERE id = ?`, attachmentColumns), id)
+	if err != nil {
+		return nil, fmt.Errorf("get attachment: %w", err)
+	}
+	defer rows.Close()
+	list, err := scanAttachments(rows)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return list[0], nil
+}
+
+// ListByMemory returns a memory's attachments, oldest first.
+func (s *AttachmentStore) ListByMemory(ctx context.Context, memoryID string) ([]*models.Attachment, error) {
+	rows, err := s.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT %s FROM attachments WHERE memory_id = ? ORDER BY created_at ASC`, attachmentColumns),
+		memoryID)
+	if err != nil {
+		return nil, fmt.Errorf("list attachments: %w", err)
+	}
+	defer rows.Close()
+	return scanAttachments(rows)
+}
+
+// ListRecent returns the most recently added attachments.
+func (s *AttachmentStore) ListRecent(ctx context.Context, limit int) ([]*models.Attachment, error) {
+	rows, err := s.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT %s FROM attachments ORDER BY created_at DESC LIMIT ?`, attachmentColumns),
+		limit)
+	if err != nil {
+		return nil, fmt.Errorf("list recent attachments: %w", err)
+	}
+	defer rows.Close()
+	return scanAttachments(rows)
+}
+
+// Delete removes an attachment record. It reports whether a row was deleted.
+func (s *AttachmentStore) Delete(ctx context.Context, id string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM attachments WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("delete attachment: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// MemoryIDs returns the set of memory IDs that still have attachments.
+func (s *AttachmentStore) MemoryIDs(ctx context.Context) (map[string]bool, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT memory_id FROM attachments`)
+	if err != nil {
+		return nil, fmt.Errorf("list attachment memory ids: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan attachment memory id: %w", err)
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+func scanAttachments(rows *sql.Rows) ([]*models.Attachment, error) {
+	var result []*models.Attachment
+	for rows.Next() {
+		var a models.Attachment
+		var model sql.NullString
+		if err := rows.Scan(
+			&a.ID, &a.MemoryID, &a.Filename, &a.ContentType, &a.Size, &a.SHA256,
+			&a.Embedding, &model, &a.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan attachment: %w", err)
+		}
+		a.EmbeddingModel = model.String
+		result = append(result, &a)
+	}
+	return result, rows.Err()
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
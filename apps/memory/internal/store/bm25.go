@@ -1,15 +1,28 @@
 package store
 
 import (
+	"context"
 	"fmt"
 	"strings"
 )
 
+// Markers wrapped around matched terms in BM25 snippets.
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// snippetTokens is the size of the content window returned as a snippet.
+const snippetTokens = 24
+
 // BM25Result holds an FTS5 match result.
 type BM25Result struct {
 	RowID int64
 	ID    string
 	Rank  float64
+	// Snippet is the best-matching window of the content with matched
+	// terms wrapped in HighlightStart/HighlightEnd.
+	Snippet string
 }
 
 // BM25Store handles full-text search via SQLite FTS5.
@@ -23,14 +36,14 @@ func NewBM25Store(db *DB) *BM25Store {
 
 // Search performs BM25 full-text search, scoped to a set of workspace IDs.
 // Returns memory IDs ranked by BM25 score (lower rank = better match).
-func (s *BM25Store) Search(query string, workspaceIDs []string, limit int) ([]BM25Result, error) {
+func (s *BM25Store) Search(ctx context.Context, query string, workspaceIDs []string, limit int) ([]BM25Result, error) {
 	if query == "" || len(workspaceIDs) == 0 {
 		return nil, nil
 	}
 
 	placeholders := make([]string, len(workspaceIDs))
-	args := make([]any, 0, len(workspaceIDs)+2)
-	args = append(args, query)
+	args := make([]any, 0, len(workspaceIDs)+4)
+	args = append(args, HighlightStart, HighlightEnd, query)
 	for i, id := range workspaceIDs {
 		placeholders[i] = "?"
 		args = append(args, id)
@@ -40,17 +53,19 @@ func (s *BM25Store) Search(query string, workspaceIDs []string, limit int) ([]BM
 	// Join FTS5 results back to memories table for workspace filtering.
 	// bm25() returns negative values where more negative = better match,
 	// so we negate to get positive scores where higher = better.
+	// snippet() picks the content window with the most matched terms.
 	q := fmt.Sprintf(`
-		SELECT m.rowid, m.id, -rank AS score
+		SELECT m.rowid, m.id, -rank AS score,
+			snippet(memories_fts, 0, ?, ?, '…', %d)
 		FROM memories_fts
 		JOIN memories m ON m.rowid = memories_fts.rowid
 		WHERE memories_fts MATCH ?
 		  AND m.workspace_id IN (%s)
 		ORDER BY rank
 		LIMIT ?
-	`, strings.Join(placeholders, ","))
+	`, snippetTokens, strings.Join(placeholders, ","))
 
-	rows, err := s.db.Query(q, args...)
+	rows, err := s.db.QueryContext(ctx, q, args...)
 	if err != nil {
 		return nil, fmt.Errorf("bm25 search: %w", err)
 	}
@@ -59,7 +74,7 @@ func (s *BM25Store) Search(query string, workspaceIDs []string, limit int) ([]BM
 	var results []BM25Result
 	for rows.Next() {
 		var r BM25Result
-		if err := rows.Scan(&r.RowID, &r.ID, &r.Rank); err != nil {
+		if err := rows.Scan(&r.RowID, &r.ID, &r.Rank, &r.Snippet); err != nil {
 			return nil, fmt.Errorf("scan bm25 result: %w", err)
 		}
 		results = append(results, r)
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// CalibrationSample is one memory's stated confidence and what became of it.
+type CalibrationSample struct {
+	Source       string
+	Confidence   float64
+	Used         bool // received at least one impact signal
+	Contradicted bool // superseded by a newer memory
+}
+
+// CalibrationStore reads the outcomes confidence calibration is based on
+// and keeps the per-source-group adjustments applied to new memories.
+type CalibrationStore struct {
+	db *DB
+}
+
+func NewCalibrationStore(db *DB) *CalibrationStore {
+	return &CalibrationStore{db: db}
+}
+
+// Samples returns a calibration sample for every memory, or for one
+// workspace when workspaceID is set.
+func (s *CalibrationStore) Samples(ctx context.Context, workspaceID string) ([]CalibrationSample, error) {
+	query := `
+		SELECT m.source, m.confidence,
+			EXISTS (SELECT 1 FROM memory_impacts i WHERE i.memory_id = m.id),
+			m.superseded_by IS NOT NULL AND m.superseded_by != ''
+		FROM memories m`
+	var args []any
+	if workspaceID != "" {
+		query += ` WHERE m.workspace_id = ?`
+		args = append(args, workspaceID)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("calibration samples: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []CalibrationSample
+	for rows.Next() {
+		var c CalibrationSample
+		var source sql.NullString
+		if err := rows.Scan(&source, &c.Confidence, &c.Used, &c.Contradicted); err != nil {
+			return nil, fmt.Errorf("scan calibration sample: %w", err)
+		}
+		c.Source = source.String
+		samples = append(samples, c)
+	}
+	return samples, rows.Err()
+}
+
+// Adjustments returns the applied adjustment for each source group.
+func (s *CalibrationStore) Adjustments(ctx context.Context) (map[string]float64, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT source_group, adjustment FROM confidence_adjustments`)
+	if err != nil {
+		return nil, fmt.Errorf("list confidence adjustments: %w", err)
+	}
+	defer rows.Close()
+
+	adj := make(map[string]float64)
+	for rows.Next() {
+		var group string
+		var v float64
+		if err := rows.Scan(&group, &v); err != nil {
+			return nil, fmt.Errorf("scan confidence adjustment: %w", err)
+		}
+		adj[group] = v
+	}
+	return adj, rows.Err()
+}
+
+// SetAdjustment records the adjustment for a source group and the number of
+// resolved memories it was derived from.
+func (s *CalibrationStore) SetAdjustment(ctx context.Context, group string, adjustment float64, samples int) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO confidence_adjustments (source_group, adjustment, samples, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(source_group) DO UPDATE SET
+			adjustment = excluded.adjustment,
+			samples = excluded.samples,
+			updated_at = excluded.updated_at
+	`, group, adjustment, samples, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("set confidence adjustment: %w", err)
+	}
+	return nil
+}
+
+// Adjust applies the source group's adjustment to a confidence, keeping it
+// within [0.05, 1]. The confidence is returned unchanged if the lookup fails.
+func (s *CalibrationStore) Adjust(ctx context.Context, source string, confidence float64) float64 {
+	var adj float64
+	err := s.db.QueryRowContext(ctx, `SELECT adjustment FROM confidence_adjustments WHERE source_group = ?`,
+		models.SourceGroup(source)).Scan(&adj)
+	if err != nil {
+		return confidence
+	}
+	return min(max(confidence+adj, 0.05), 1)
+}
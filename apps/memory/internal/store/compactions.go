@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// CompactionStore keeps a history of compaction runs.
+type CompactionStore struct {
+	db *DB
+}
+
+func NewCompactionStore(db *DB) *CompactionStore {
+	return &CompactionStore{db: db}
+}
+
+// Insert records a compaction run.
+func (s *CompactionStore) Insert(ctx context.Context, r *models.CompactionReport) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO compaction_runs (
+			id, trigger, started_at, duration_ms,
+			expired, forgotten_low, promoted,
+			db_size_before, db_size_after, error, reclaimed_bytes, impact_decayed,
+			demoted, consolidated
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		r.ID, r.Trigger, r.StartedAt, r.DurationMs,
+		r.Expired, r.ForgottenLow, r.Promoted,
+		r.DBSizeBefore, r.DBSizeAfter, r.Error, r.ReclaimedBytes, r.ImpactDecayed,
+		r.Demoted, r.Consolidated,
+	)
+	if err != nil {
+		return fmt.Errorf("insert compaction run: %w", err)
+	}
+	return nil
+}
+
+// List returns the most recent compaction runs, newest first.
+func (s *CompactionStore) List(ctx context.Context, limit int) ([]*models.CompactionReport, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, trigger, started_at, duration_ms,
+			expired, forgotten_low, promoted,
+			db_size_before, db_size_after, error, reclaimed_bytes, impact_decayed,
+			demoted, consolidated
+		FROM compaction_runs ORDER BY started_at DESC, rowid DESC LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list compaction runs: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*models.CompactionReport
+	for rows.Next() {
+		var r models.CompactionReport
+		if err := rows.Scan(
+			&r.ID, &r.Trigger, &r.StartedAt, &r.DurationMs,
+			&r.Expired, &r.ForgottenLow, &r.Promoted,
+			&r.DBSizeBefore, &r.DBSizeAfter, &r.Error, &r.ReclaimedBytes, &r.ImpactDecayed,
+			&r.Demoted, &r.Consolidated,
+		); err != nil {
+			return nil, fmt.Errorf("scan compaction run: %w", err)
+		}
+		r.DBSizeDelta = r.DBSizeAfter - r.DBSizeBefore
+		reports = append(reports, &r)
+	}
+	return reports, rows.Err()
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -18,9 +19,9 @@ func NewEmbeddingCacheStore(db *DB) *EmbeddingCacheStore {
 }
 
 // Get returns a cached embedding by content hash, or nil if not found.
-func (s *EmbeddingCacheStore) Get(contentHash string) (*models.EmbeddingCacheEntry, error) {
+func (s *EmbeddingCacheStore) Get(ctx context.Context, contentHash string) (*models.EmbeddingCacheEntry, error) {
 	var e models.EmbeddingCacheEntry
-	err := s.db.QueryRow(`
+	err := s.db.QueryRowContext(ctx, `
 		SELECT content_hash, embedding, dimension, model, updated_at
 		FROM embedding_cache WHERE content_hash = ?
 	`, contentHash).Scan(&e.ContentHash, &e.Embedding, &e.Dimension, &e.Model, &e.UpdatedAt)
@@ -35,9 +36,9 @@ func (s *EmbeddingCacheStore) Get(contentHash string) (*models.EmbeddingCacheEnt
 }
 
 // Put upserts an embedding cache entry.
-func (s *EmbeddingCacheStore) Put(entry *models.EmbeddingCacheEntry) error {
+func (s *EmbeddingCacheStore) Put(ctx context.Context, entry *models.EmbeddingCacheEntry) error {
 	entry.UpdatedAt = time.Now().Unix()
-	_, err := s.db.Exec(`
+	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO embedding_cache (content_hash, embedding, dimension, model, updated_at)
 		VALUES (?, ?, ?, ?, ?)
 		ON CONFLICT(content_hash) DO UPDATE SET
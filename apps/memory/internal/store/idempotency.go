@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// IdempotentResponse is a stored response replayed for a repeated key.
+type IdempotentResponse struct {
+	RequestHash string
+	Status      int
+	Body        []byte
+	CreatedAt   int64
+}
+
+// IdempotencyStore records responses by Idempotency-Key so retried writes
+// return the original result instead of creating duplicates.
+type IdempotencyStore struct {
+	db  *DB
+	ttl time.Duration
+}
+
+func NewIdempotencyStore(db *DB, ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{db: db, ttl: ttl}
+}
+
+// Get returns the stored response for a key, or nil if none exists within
+// the dedupe window.
+func (s *IdempotencyStore) Get(ctx context.Context, namespace, route, key string) (*IdempotentResponse, error) {
+	var r IdempotentResponse
+	err := s.db.QueryRowContext(ctx, `
+		SELECT request_hash, status, response, created_at
+		FROM idempotency_keys
+		WHERE namespace = ? AND route = ? AND key = ? AND created_at > ?
+	`, namespace, route, key, time.Now().Add(-s.ttl).Unix()).Scan(&r.RequestHash, &r.Status, &r.Body, &r.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get idempotency key: %w", err)
+	}
+	return &r, nil
+}
+
+// Save stores the response for a key and purges entries past the window.
+func (s *IdempotencyStore) Save(ctx context.Context, namespace, route, key string, r *IdempotentResponse) error {
+	now := time.Now()
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (namespace, route, key, request_hash, status, response, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(namespace, route, key) DO UPDATE SET
+			request_hash = excluded.request_hash,
+			status = excluded.status,
+			response = excluded.response,
+			created_at = excluded.created_at
+	`, namespace, route, key, r.RequestHash, r.Status, r.Body, now.Unix())
+	if err != nil {
+		return fmt.Errorf("save idempotency key: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE created_at <= ?`, now.Add(-s.ttl).Unix()); err != nil {
+		return fmt.Errorf("purge idempotency keys: %w", err)
+	}
+	return nil
+}
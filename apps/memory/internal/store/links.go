@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"fmt"
 	"time"
 )
@@ -27,9 +28,9 @@ func NewLinkStore(db *DB) *LinkStore {
 
 // CreateOrStrengthen creates a link or strengthens an existing one.
 // Uses ON CONFLICT to upsert, capping strength at 5.0.
-func (s *LinkStore) CreateOrStrengthen(sourceID, targetID, linkType string, delta float64) error {
+func (s *LinkStore) CreateOrStrengthen(ctx context.Context, sourceID, targetID, linkType string, delta float64) error {
 	now := time.Now().Unix()
-	_, err := s.db.Exec(`
+	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO memory_links (source_id, target_id, link_type, strength, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?)
 		ON CONFLICT(source_id, target_id, link_type) DO UPDATE SET
@@ -43,11 +44,11 @@ func (s *LinkStore) CreateOrStrengthen(sourceID, targetID, linkType string, delt
 }
 
 // GetLinked returns memories linked to the given memory ID, ordered by strength.
-func (s *LinkStore) GetLinked(id string, limit int) ([]MemoryLink, error) {
+func (s *LinkStore) GetLinked(ctx context.Context, id string, limit int) ([]MemoryLink, error) {
 	if limit <= 0 {
 		limit = 10
 	}
-	rows, err := s.db.Query(`
+	rows, err := s.db.QueryContext(ctx, `
 		SELECT id, source_id, target_id, link_type, strength, created_at, updated_at
 		FROM memory_links
 		WHERE source_id = ? OR target_id = ?
@@ -69,3 +70,34 @@ func (s *LinkStore) GetLinked(id string, limit int) ([]MemoryLink, error) {
 	}
 	return links, rows.Err()
 }
+
+// Set creates a link or replaces the strength of an existing one, for
+// links asserted through the API rather than built up by search.
+func (s *LinkStore) Set(ctx context.Context, sourceID, targetID, linkType string, strength float64) (*MemoryLink, error) {
+	now := time.Now().Unix()
+	l := &MemoryLink{SourceID: sourceID, TargetID: targetID, LinkType: linkType, Strength: strength, UpdatedAt: now}
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO memory_links (source_id, target_id, link_type, strength, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(source_id, target_id, link_type) DO UPDATE SET
+			strength = excluded.strength,
+			updated_at = excluded.updated_at
+		RETURNING id, created_at
+	`, sourceID, targetID, linkType, strength, now, now).Scan(&l.ID, &l.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("set link: %w", err)
+	}
+	return l, nil
+}
+
+// Delete removes one link, reporting whether it existed.
+func (s *LinkStore) Delete(ctx context.Context, sourceID, targetID, linkType string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM memory_links WHERE source_id = ? AND target_id = ? AND link_type = ?
+	`, sourceID, targetID, linkType)
+	if err != nil {
+		return false, fmt.Errorf("delete link: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// Stats reports the database's on-disk size and page usage.
+func (db *DB) Stats(ctx context.Context) (*models.DBStats, error) {
+	stats := &models.DBStats{}
+	var pages, free int64
+	err := db.QueryRowContext(ctx, `
+		SELECT p.page_count, f.freelist_count, s.page_size
+		FROM pragma_page_count() p, pragma_freelist_count() f, pragma_page_size() s
+	`).Scan(&pages, &free, &stats.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("read page counts: %w", err)
+	}
+	stats.UsedBytes = (pages - free) * stats.PageSize
+	stats.FreeBytes = free * stats.PageSize
+	stats.FileBytes = fileSize(db.path)
+	stats.WALBytes = fileSize(db.path + "-wal")
+	return stats, nil
+}
+
+// Vacuum releases free pages and tidies indexes: it merges the FTS5 index
+// segments, runs an incremental vacuum, refreshes planner statistics and
+// checkpoints the WAL so the file actually shrinks. A database created
+// before incremental auto-vacuum gets a one-off full VACUUM instead, which
+// switches it over.
+func (db *DB) Vacuum(ctx context.Context) (*models.VacuumResult, error) {
+	start := time.Now()
+	result := &models.VacuumResult{FileBytesBefore: fileSize(db.path) + fileSize(db.path+"-wal")}
+
+	var mode int
+	if err := db.QueryRowContext(ctx, "PRAGMA auto_vacuum").Scan(&mode); err != nil {
+		return nil, fmt.Errorf("read auto_vacuum: %w", err)
+	}
+	if mode != 2 { // 2 = INCREMENTAL
+		// Open sets auto_vacuum on every connection; VACUUM applies it
+		if _, err := db.ExecContext(ctx, "VACUUM"); err != nil {
+			return nil, fmt.Errorf("vacuum for auto_vacuum: %w", err)
+		}
+		result.FullVacuum = true
+	}
+
+	if _, err := db.ExecContext(ctx, `INSERT INTO memories_fts(memories_fts) VALUES('optimize')`); err != nil {
+		return nil, fmt.Errorf("optimize fts index: %w", err)
+	}
+	// incremental_vacuum frees one page per step, so drain it as a query
+	// rather than Exec, which only steps once.
+	rows, err := db.QueryContext(ctx, "PRAGMA incremental_vacuum")
+	if err != nil {
+		return nil, fmt.Errorf("incremental vacuum: %w", err)
+	}
+	for rows.Next() {
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("incremental vacuum: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, "PRAGMA optimize"); err != nil {
+		return nil, fmt.Errorf("optimize: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return nil, fmt.Errorf("wal checkpoint: %w", err)
+	}
+
+	result.FileBytesAfter = fileSize(db.path) + fileSize(db.path+"-wal")
+	result.ReclaimedBytes = result.FileBytesBefore - result.FileBytesAfter
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+func fileSize(path string) int64 {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return fi.Size()
+}
@@ -1,12 +1,16 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"math"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
 	"github.com/iammorganparry/clive/apps/memory/internal/models"
 )
 
@@ -20,7 +24,9 @@ const memoryColumns = `id, workspace_id, content, memory_type, tier, confidence,
 	encoding_context,
 	superseded_by,
 	completion_status,
-	thread_id`
+	thread_id,
+	impact_updated_at,
+	visibility, owner, owner_team`
 
 // MemoryStore handles Memory CRUD operations on SQLite.
 type MemoryStore struct {
@@ -31,8 +37,42 @@ func NewMemoryStore(db *DB) *MemoryStore {
 	return &MemoryStore{db: db}
 }
 
+// execer is satisfied by both *sql.DB and *sql.Tx, so inserts can join a
+// caller's transaction.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
 // Insert stores a new memory. The caller must set all required fields including ID and ContentHash.
-func (s *MemoryStore) Insert(m *models.Memory) error {
+func (s *MemoryStore) Insert(ctx context.Context, m *models.Memory) error {
+	return insertMemory(ctx, s.db, m)
+}
+
+// InsertWithinQuota stores a new memory and enforces the workspace quota in
+// the same transaction: the memory is refused, or others are evicted to
+// make room for it. It returns the evicted memories.
+func (s *MemoryStore) InsertWithinQuota(ctx context.Context, m *models.Memory) ([]EvictedMemory, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertMemory(ctx, tx, m); err != nil {
+		return nil, err
+	}
+	evicted, err := s.db.enforceQuota(ctx, tx, m.WorkspaceID, m.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit insert: %w", err)
+	}
+	s.db.evicted(ctx, evicted)
+	return evicted, nil
+}
+
+func insertMemory(ctx context.Context, db execer, m *models.Memory) error {
 	tagsJSON, _ := json.Marshal(m.Tags)
 	relatedFilesJSON, _ := json.Marshal(m.RelatedFiles)
 
@@ -41,7 +81,12 @@ func (s *MemoryStore) Insert(m *models.Memory) error {
 		encodingCtxJSON, _ = json.Marshal(m.EncodingContext)
 	}
 
-	_, err := s.db.Exec(`
+	visibility := m.Visibility
+	if visibility == "" {
+		visibility = models.VisibilityPublic
+	}
+
+	_, err := db.ExecContext(ctx, `
 		INSERT INTO memories (
 			id, workspace_id, content, memory_type, tier, confidence,
 			access_count, tags, source, session_id, content_hash,
@@ -51,8 +96,10 @@ func (s *MemoryStore) Insert(m *models.Memory) error {
 			encoding_context,
 			superseded_by,
 			completion_status,
-			thread_id
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			thread_id,
+			impact_updated_at,
+			visibility, owner, owner_team
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		m.ID, m.WorkspaceID, m.Content, string(m.MemoryType), string(m.Tier),
 		m.Confidence, m.AccessCount, string(tagsJSON), m.Source, m.SessionID,
@@ -64,6 +111,8 @@ func (s *MemoryStore) Insert(m *models.Memory) error {
 		m.SupersededBy,
 		m.CompletionStatus,
 		m.ThreadID,
+		m.ImpactUpdatedAt,
+		string(visibility), nullString(m.Owner), nullString(m.OwnerTeam),
 	)
 	if err != nil {
 		return fmt.Errorf("insert memory: %w", err)
@@ -72,8 +121,8 @@ func (s *MemoryStore) Insert(m *models.Memory) error {
 }
 
 // GetByID fetches a single memory by ID.
-func (s *MemoryStore) GetByID(id string) (*models.Memory, error) {
-	m, err := s.scanOne(s.db.QueryRow(
+func (s *MemoryStore) GetByID(ctx context.Context, id string) (*models.Memory, error) {
+	m, err := s.scanOne(s.db.QueryRowContext(ctx,
 		fmt.Sprintf(`SELECT %s FROM memories WHERE id = ?`, memoryColumns), id))
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -82,20 +131,20 @@ func (s *MemoryStore) GetByID(id string) (*models.Memory, error) {
 }
 
 // Delete removes a memory by ID.
-func (s *MemoryStore) Delete(id string) error {
-	res, err := s.db.Exec("DELETE FROM memories WHERE id = ?", id)
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM memories WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("delete memory: %w", err)
 	}
 	n, _ := res.RowsAffected()
 	if n == 0 {
-		return fmt.Errorf("memory not found: %s", id)
+		return apperr.NotFound("memory_not_found", "memory not found: %s", id)
 	}
 	return nil
 }
 
 // Update applies partial updates to a memory.
-func (s *MemoryStore) Update(id string, req *models.UpdateRequest) (*models.Memory, error) {
+func (s *MemoryStore) Update(ctx context.Context, id string, req *models.UpdateRequest) (*models.Memory, error) {
 	sets := []string{"updated_at = ?"}
 	args := []any{time.Now().Unix()}
 
@@ -127,21 +176,21 @@ func (s *MemoryStore) Update(id string, req *models.UpdateRequest) (*models.Memo
 
 	args = append(args, id)
 	query := fmt.Sprintf("UPDATE memories SET %s WHERE id = ?", strings.Join(sets, ", "))
-	res, err := s.db.Exec(query, args...)
+	res, err := s.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("update memory: %w", err)
 	}
 	n, _ := res.RowsAffected()
 	if n == 0 {
-		return nil, fmt.Errorf("memory not found: %s", id)
+		return nil, apperr.NotFound("memory_not_found", "memory not found: %s", id)
 	}
 
-	return s.GetByID(id)
+	return s.GetByID(ctx, id)
 }
 
 // FindByContentHash finds memories with the given content hash in a workspace.
-func (s *MemoryStore) FindByContentHash(workspaceID, hash string) ([]*models.Memory, error) {
-	rows, err := s.db.Query(
+func (s *MemoryStore) FindByContentHash(ctx context.Context, workspaceID, hash string) ([]*models.Memory, error) {
+	rows, err := s.db.QueryContext(ctx,
 		fmt.Sprintf(`SELECT %s FROM memories WHERE workspace_id = ? AND content_hash = ?`, memoryColumns),
 		workspaceID, hash)
 	if err != nil {
@@ -153,7 +202,7 @@ func (s *MemoryStore) FindByContentHash(workspaceID, hash string) ([]*models.Mem
 
 // GetShortTermWithEmbeddings returns all short-term memories with embeddings
 // for a set of workspace IDs (used for brute-force cosine search).
-func (s *MemoryStore) GetShortTermWithEmbeddings(workspaceIDs []string) ([]*models.Memory, error) {
+func (s *MemoryStore) GetShortTermWithEmbeddings(ctx context.Context, workspaceIDs []string) ([]*models.Memory, error) {
 	if len(workspaceIDs) == 0 {
 		return nil, nil
 	}
@@ -169,7 +218,7 @@ func (s *MemoryStore) GetShortTermWithEmbeddings(workspaceIDs []string) ([]*mode
 		WHERE workspace_id IN (%s) AND tier = 'short' AND embedding IS NOT NULL
 	`, memoryColumns, strings.Join(placeholders, ","))
 
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("get short-term: %w", err)
 	}
@@ -178,28 +227,133 @@ func (s *MemoryStore) GetShortTermWithEmbeddings(workspaceIDs []string) ([]*mode
 }
 
 // IncrementAccessCount bumps a memory's access count and last_accessed_at timestamp.
-func (s *MemoryStore) IncrementAccessCount(id string) error {
+func (s *MemoryStore) IncrementAccessCount(ctx context.Context, id string) error {
 	now := time.Now().Unix()
-	_, err := s.db.Exec(`
+	_, err := s.db.ExecContext(ctx, `
 		UPDATE memories SET access_count = access_count + 1, last_accessed_at = ?, updated_at = ?
 		WHERE id = ?
 	`, now, now, id)
 	return err
 }
 
-// UpdateStabilityOnAccess reinforces a memory's stability using the FSRS-inspired formula:
-// stability = MIN(365, stability × (1 + 0.5 × (1 + impact_score)))
-func (s *MemoryStore) UpdateStabilityOnAccess(id string, impactScore float64) error {
-	_, err := s.db.Exec(`
-		UPDATE memories SET stability = MIN(365.0, stability * (1.0 + 0.5 * (1.0 + ?)))
+// MaxStability caps how far accesses can reinforce a memory's stability, in days.
+const MaxStability = 365.0
+
+// StabilityBoost is the factor one access multiplies stability by, using the
+// FSRS-inspired formula 1 + 0.5 × (1 + impact_score). Impactful memories are
+// reinforced faster.
+func StabilityBoost(impactScore float64) float64 {
+	return 1 + 0.5*(1+impactScore)
+}
+
+// UpdateStabilityOnAccess reinforces a memory's stability:
+// stability = MIN(MaxStability, stability × StabilityBoost(impact_score))
+func (s *MemoryStore) UpdateStabilityOnAccess(ctx context.Context, id string, impactScore float64) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE memories SET stability = MIN(?, stability * ?)
 		WHERE id = ?
-	`, impactScore, id)
+	`, MaxStability, StabilityBoost(impactScore), id)
 	return err
 }
 
+// GetLongTermByWorkspace returns all long-term memories in a workspace.
+func (s *MemoryStore) GetLongTermByWorkspace(ctx context.Context, workspaceID string) ([]*models.Memory, error) {
+	rows, err := s.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT %s FROM memories WHERE workspace_id = ? AND tier = 'long'`, memoryColumns),
+		workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("get long-term by workspace: %w", err)
+	}
+	defer rows.Close()
+	return s.scanMany(rows)
+}
+
+// LiveLongTermIDs returns the IDs of a workspace's long-term memories that
+// have not been superseded: exactly the memories that should have a point
+// in the workspace's Qdrant collection.
+func (s *MemoryStore) LiveLongTermIDs(ctx context.Context, workspaceID string) (map[string]bool, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id FROM memories
+		WHERE workspace_id = ? AND tier = 'long' AND superseded_by IS NULL
+	`, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("live long-term ids: %w", err)
+	}
+	defer rows.Close()
+
+	ids := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan id: %w", err)
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// LongTermWorkspaceIDs returns every workspace holding at least one live
+// long-term memory.
+func (s *MemoryStore) LongTermWorkspaceIDs(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT DISTINCT workspace_id FROM memories
+		WHERE tier = 'long' AND superseded_by IS NULL
+		ORDER BY workspace_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("long-term workspace ids: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan workspace id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// LiveLongTermWorkspaces maps every live long-term memory's ID to its
+// workspace.
+func (s *MemoryStore) LiveLongTermWorkspaces(ctx context.Context) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, workspace_id FROM memories
+		WHERE tier = 'long' AND superseded_by IS NULL
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("live long-term workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	workspaces := map[string]string{}
+	for rows.Next() {
+		var id, workspaceID string
+		if err := rows.Scan(&id, &workspaceID); err != nil {
+			return nil, fmt.Errorf("scan memory workspace: %w", err)
+		}
+		workspaces[id] = workspaceID
+	}
+	return workspaces, rows.Err()
+}
+
+// GetByTypeAndWorkspace returns all memories of a type in a workspace.
+func (s *MemoryStore) GetByTypeAndWorkspace(ctx context.Context, memoryType string, workspaceID string) ([]*models.Memory, error) {
+	rows, err := s.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT %s FROM memories WHERE memory_type = ? AND workspace_id = ?`, memoryColumns),
+		memoryType, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("get by type and workspace: %w", err)
+	}
+	defer rows.Close()
+	return s.scanMany(rows)
+}
+
 // GetAllShortTerm returns all short-term memories (for retrievability-based cleanup).
-func (s *MemoryStore) GetAllShortTerm() ([]*models.Memory, error) {
-	rows, err := s.db.Query(
+func (s *MemoryStore) GetAllShortTerm(ctx context.Context) ([]*models.Memory, error) {
+	rows, err := s.db.QueryContext(ctx,
 		fmt.Sprintf(`SELECT %s FROM memories WHERE tier = 'short'`, memoryColumns))
 	if err != nil {
 		return nil, fmt.Errorf("get all short-term: %w", err)
@@ -209,9 +363,9 @@ func (s *MemoryStore) GetAllShortTerm() ([]*models.Memory, error) {
 }
 
 // Supersede marks an old memory as superseded by a new memory.
-func (s *MemoryStore) Supersede(oldID, newID string) error {
+func (s *MemoryStore) Supersede(ctx context.Context, oldID, newID string) error {
 	now := time.Now().Unix()
-	res, err := s.db.Exec(`
+	res, err := s.db.ExecContext(ctx, `
 		UPDATE memories SET superseded_by = ?, updated_at = ?
 		WHERE id = ? AND superseded_by IS NULL
 	`, newID, now, oldID)
@@ -220,23 +374,39 @@ func (s *MemoryStore) Supersede(oldID, newID string) error {
 	}
 	n, _ := res.RowsAffected()
 	if n == 0 {
-		return fmt.Errorf("memory not found or already superseded: %s", oldID)
+		return apperr.Conflict("memory_superseded", "memory not found or already superseded: %s", oldID)
 	}
 	return nil
 }
 
-// ClearEmbedding sets embedding to NULL (used when promoting to Qdrant).
-func (s *MemoryStore) ClearEmbedding(id string) error {
-	_, err := s.db.Exec(`
-		UPDATE memories SET embedding = NULL, updated_at = ?
+// Promote moves a memory to the long-term tier once its embedding is in
+// Qdrant: the SQLite copy and the expiry are cleared and the promotion is
+// timestamped.
+func (s *MemoryStore) Promote(ctx context.Context, id string) error {
+	now := time.Now().Unix()
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE memories SET tier = 'long', embedding = NULL, expires_at = NULL, promoted_at = ?, updated_at = ?
+		WHERE id = ?
+	`, now, now, id)
+	if err != nil {
+		return fmt.Errorf("promote memory: %w", err)
+	}
+	return nil
+}
+
+// SetEmbedding records a regenerated embedding and the model that produced it.
+// Long-term memories pass a nil embedding since their vectors live in Qdrant.
+func (s *MemoryStore) SetEmbedding(ctx context.Context, id string, embedding []byte, model string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE memories SET embedding = ?, embedding_model = ?, updated_at = ?
 		WHERE id = ?
-	`, time.Now().Unix(), id)
+	`, embedding, model, time.Now().Unix(), id)
 	return err
 }
 
 // SetTier updates the tier and expires_at for a memory.
-func (s *MemoryStore) SetTier(id string, tier models.Tier, expiresAt *int64) error {
-	_, err := s.db.Exec(`
+func (s *MemoryStore) SetTier(ctx context.Context, id string, tier models.Tier, expiresAt *int64) error {
+	_, err := s.db.ExecContext(ctx, `
 		UPDATE memories SET tier = ?, expires_at = ?, updated_at = ?
 		WHERE id = ?
 	`, string(tier), expiresAt, time.Now().Unix(), id)
@@ -245,23 +415,46 @@ func (s *MemoryStore) SetTier(id string, tier models.Tier, expiresAt *int64) err
 
 // DeleteExpired removes all memories whose expires_at has passed.
 // Active thread entries are exempt from expiry.
-func (s *MemoryStore) DeleteExpired() (int64, error) {
-	res, err := s.db.Exec(`
+func (s *MemoryStore) DeleteExpired(ctx context.Context) (int64, error) {
+	deleted, err := s.DeleteExpiredMemories(ctx)
+	return int64(len(deleted)), err
+}
+
+// DeleteExpiredMemories is DeleteExpired, returning the ID, workspace ID and
+// access control of each deleted memory.
+func (s *MemoryStore) DeleteExpiredMemories(ctx context.Context) ([]*models.Memory, error) {
+	rows, err := s.db.QueryContext(ctx, `
 		DELETE FROM memories
 		WHERE expires_at IS NOT NULL AND expires_at < ?
 		  AND (thread_id IS NULL OR thread_id NOT IN (
 		    SELECT id FROM feature_threads WHERE status = 'active'
 		  ))
+		RETURNING id, workspace_id, visibility, owner, owner_team
 	`, time.Now().Unix())
 	if err != nil {
-		return 0, fmt.Errorf("delete expired: %w", err)
+		return nil, fmt.Errorf("delete expired: %w", err)
+	}
+	defer rows.Close()
+
+	var deleted []*models.Memory
+	for rows.Next() {
+		var m models.Memory
+		var owner, ownerTeam sql.NullString
+		if err := rows.Scan(&m.ID, &m.WorkspaceID, &m.Visibility, &owner, &ownerTeam); err != nil {
+			return nil, fmt.Errorf("scan expired memory: %w", err)
+		}
+		m.Owner, m.OwnerTeam = owner.String, ownerTeam.String
+		deleted = append(deleted, &m)
 	}
-	return res.RowsAffected()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("delete expired: %w", err)
+	}
+	return deleted, nil
 }
 
 // GetPromotionCandidates returns short-term memories eligible for promotion.
-func (s *MemoryStore) GetPromotionCandidates(minAccess int, minConfidence float64) ([]*models.Memory, error) {
-	rows, err := s.db.Query(
+func (s *MemoryStore) GetPromotionCandidates(ctx context.Context, minAccess int, minConfidence float64) ([]*models.Memory, error) {
+	rows, err := s.db.QueryContext(ctx,
 		fmt.Sprintf(`SELECT %s FROM memories WHERE tier = 'short' AND access_count >= ? AND confidence >= ?`, memoryColumns),
 		minAccess, minConfidence)
 	if err != nil {
@@ -273,9 +466,9 @@ func (s *MemoryStore) GetPromotionCandidates(minAccess int, minConfidence float6
 
 // DeleteByTypeAndWorkspace removes all memories matching a type and workspace.
 // Returns the IDs of deleted memories so callers can clean up Qdrant points.
-func (s *MemoryStore) DeleteByTypeAndWorkspace(memoryType string, workspaceID string) ([]string, error) {
+func (s *MemoryStore) DeleteByTypeAndWorkspace(ctx context.Context, memoryType string, workspaceID string) ([]string, error) {
 	// First, collect the IDs so we can return them for Qdrant cleanup
-	rows, err := s.db.Query(
+	rows, err := s.db.QueryContext(ctx,
 		`SELECT id FROM memories WHERE memory_type = ? AND workspace_id = ?`,
 		memoryType, workspaceID,
 	)
@@ -301,7 +494,7 @@ func (s *MemoryStore) DeleteByTypeAndWorkspace(memoryType string, workspaceID st
 	}
 
 	// Delete all matching memories
-	_, err = s.db.Exec(
+	_, err = s.db.ExecContext(ctx,
 		`DELETE FROM memories WHERE memory_type = ? AND workspace_id = ?`,
 		memoryType, workspaceID,
 	)
@@ -312,30 +505,21 @@ func (s *MemoryStore) DeleteByTypeAndWorkspace(memoryType string, workspaceID st
 	return ids, nil
 }
 
-// List returns a paginated, filtered, sorted list of memories.
-func (s *MemoryStore) List(req *models.ListRequest) ([]*models.Memory, int, error) {
-	// Whitelist sort columns to prevent injection
-	allowedSorts := map[string]string{
-		"created_at":   "created_at",
-		"updated_at":   "updated_at",
-		"confidence":   "confidence",
-		"access_count": "access_count",
-		"impact_score": "impact_score",
-		"stability":    "stability",
-	}
-	sortCol, ok := allowedSorts[req.Sort]
-	if !ok {
-		sortCol = "created_at"
-	}
-
-	order := "DESC"
-	if req.Order == "asc" {
-		order = "ASC"
+// visibleTo returns a WHERE condition limiting a query to the memories
+// caller may read; see models.Caller.CanSee.
+func visibleTo(caller models.Caller) (string, []any) {
+	if caller.Anonymous() {
+		return "visibility = 'public'", nil
 	}
+	return "(visibility = 'public' OR owner = ? OR (visibility = 'team' AND owner_team = ?))",
+		[]any{caller.Name, caller.Team}
+}
 
-	// Build WHERE clause dynamically
-	var conditions []string
-	var args []any
+// listWhere builds the WHERE clause for a list filter, limited to the
+// memories the caller may read.
+func listWhere(req *models.ListRequest) (string, []any) {
+	visible, args := visibleTo(req.Caller)
+	conditions := []string{visible}
 
 	if req.WorkspaceID != "" {
 		conditions = append(conditions, "workspace_id = ?")
@@ -357,16 +541,47 @@ func (s *MemoryStore) List(req *models.ListRequest) ([]*models.Memory, int, erro
 		conditions = append(conditions, "source = ?")
 		args = append(args, req.Source)
 	}
+	if len(req.Tags) > 0 {
+		placeholders := make([]string, len(req.Tags))
+		for i, tag := range req.Tags {
+			placeholders[i] = "?"
+			args = append(args, tag)
+		}
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM json_each(memories.tags) WHERE json_each.value IN (%s))",
+			strings.Join(placeholders, ",")))
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// List returns a paginated, filtered, sorted list of memories the caller
+// may read.
+func (s *MemoryStore) List(ctx context.Context, req *models.ListRequest) ([]*models.Memory, int, error) {
+	// Whitelist sort columns to prevent injection
+	allowedSorts := map[string]string{
+		"created_at":   "created_at",
+		"updated_at":   "updated_at",
+		"confidence":   "confidence",
+		"access_count": "access_count",
+		"impact_score": "impact_score",
+		"stability":    "stability",
+	}
+	sortCol, ok := allowedSorts[req.Sort]
+	if !ok {
+		sortCol = "created_at"
+	}
 
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	order := "DESC"
+	if req.Order == "asc" {
+		order = "ASC"
 	}
 
+	whereClause, args := listWhere(req)
+
 	// Count total
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM memories %s", whereClause)
 	var total int
-	if err := s.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+	if err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("count memories: %w", err)
 	}
 
@@ -389,7 +604,7 @@ func (s *MemoryStore) List(req *models.ListRequest) ([]*models.Memory, int, erro
 	`, memoryColumns, whereClause, sortCol, order)
 
 	queryArgs := append(args, limit, offset)
-	rows, err := s.db.Query(selectQuery, queryArgs...)
+	rows, err := s.db.QueryContext(ctx, selectQuery, queryArgs...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("list memories: %w", err)
 	}
@@ -403,24 +618,86 @@ func (s *MemoryStore) List(req *models.ListRequest) ([]*models.Memory, int, erro
 	return memories, total, nil
 }
 
+// Retag rewrites the tags of every memory matching the filter with edit,
+// in one transaction. It returns how many memories matched and the changes
+// made; with dryRun nothing is written.
+func (s *MemoryStore) Retag(ctx context.Context, filter *models.ListRequest, edit func(tags []string) []string, dryRun bool) (int, []models.RetagChange, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	whereClause, args := listWhere(filter)
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT id, tags FROM memories %s ORDER BY created_at`, whereClause), args...)
+	if err != nil {
+		return 0, nil, fmt.Errorf("select memories to retag: %w", err)
+	}
+	matched := 0
+	var changes []models.RetagChange
+	for rows.Next() {
+		var id string
+		var tagsJSON sql.NullString
+		if err := rows.Scan(&id, &tagsJSON); err != nil {
+			rows.Close()
+			return 0, nil, fmt.Errorf("scan memory tags: %w", err)
+		}
+		matched++
+
+		var before []string
+		if tagsJSON.Valid {
+			json.Unmarshal([]byte(tagsJSON.String), &before)
+		}
+		after := edit(before)
+		if !slices.Equal(before, after) {
+			changes = append(changes, models.RetagChange{ID: id, Before: before, After: after})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, nil, fmt.Errorf("select memories to retag: %w", err)
+	}
+	if dryRun || len(changes) == 0 {
+		return matched, changes, nil
+	}
+
+	stmt, err := tx.Prepare(`UPDATE memories SET tags = ?, updated_at = ? WHERE id = ?`)
+	if err != nil {
+		return 0, nil, fmt.Errorf("prepare retag: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now().Unix()
+	for _, c := range changes {
+		tagsJSON, _ := json.Marshal(c.After)
+		if _, err := stmt.ExecContext(ctx, string(tagsJSON), now, c.ID); err != nil {
+			return 0, nil, fmt.Errorf("retag memory %s: %w", c.ID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, nil, fmt.Errorf("commit retag: %w", err)
+	}
+	return matched, changes, nil
+}
+
 // CountByWorkspace returns per-type counts for a workspace.
-func (s *MemoryStore) CountByWorkspace(workspaceID string) (total, shortTerm, longTerm int, byType map[string]int, err error) {
+func (s *MemoryStore) CountByWorkspace(ctx context.Context, workspaceID string) (total, shortTerm, longTerm int, byType map[string]int, err error) {
 	byType = make(map[string]int)
 
-	err = s.db.QueryRow(`SELECT COUNT(*) FROM memories WHERE workspace_id = ?`, workspaceID).Scan(&total)
+	err = s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM memories WHERE workspace_id = ?`, workspaceID).Scan(&total)
 	if err != nil {
 		return
 	}
-	err = s.db.QueryRow(`SELECT COUNT(*) FROM memories WHERE workspace_id = ? AND tier = 'short'`, workspaceID).Scan(&shortTerm)
+	err = s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM memories WHERE workspace_id = ? AND tier = 'short'`, workspaceID).Scan(&shortTerm)
 	if err != nil {
 		return
 	}
-	err = s.db.QueryRow(`SELECT COUNT(*) FROM memories WHERE workspace_id = ? AND tier = 'long'`, workspaceID).Scan(&longTerm)
+	err = s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM memories WHERE workspace_id = ? AND tier = 'long'`, workspaceID).Scan(&longTerm)
 	if err != nil {
 		return
 	}
 
-	rows, err := s.db.Query(`SELECT memory_type, COUNT(*) FROM memories WHERE workspace_id = ? GROUP BY memory_type`, workspaceID)
+	rows, err := s.db.QueryContext(ctx, `SELECT memory_type, COUNT(*) FROM memories WHERE workspace_id = ? GROUP BY memory_type`, workspaceID)
 	if err != nil {
 		return
 	}
@@ -437,15 +714,48 @@ func (s *MemoryStore) CountByWorkspace(workspaceID string) (total, shortTerm, lo
 	return
 }
 
+// WorkspaceUsage returns how many memories a workspace holds and how many
+// bytes of content they take up.
+func (s *MemoryStore) WorkspaceUsage(ctx context.Context, workspaceID string) (count int, bytes int64, err error) {
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(LENGTH(CAST(content AS BLOB))), 0)
+		FROM memories WHERE workspace_id = ?
+	`, workspaceID).Scan(&count, &bytes)
+	if err != nil {
+		return 0, 0, fmt.Errorf("workspace usage: %w", err)
+	}
+	return count, bytes, nil
+}
+
+// ImpactAfter returns an impact score decayed over elapsedDays with the given
+// half-life. A half-life of zero or less disables decay.
+func ImpactAfter(score, elapsedDays, halfLifeDays float64) float64 {
+	if halfLifeDays <= 0 || elapsedDays <= 0 || score <= 0 {
+		return score
+	}
+	return score * math.Pow(0.5, elapsedDays/halfLifeDays)
+}
+
+// impactFloor is the score below which a decayed impact is cleared to zero.
+const impactFloor = 0.001
+
 // RecordImpact inserts an impact event and increments the memory's impact_score.
-func (s *MemoryStore) RecordImpact(memoryID string, signal models.ImpactSignal, source, sessionID string) (float64, error) {
+// The existing score is first decayed to now with halfLifeDays, so a signal
+// is added to the score as it stands today.
+func (s *MemoryStore) RecordImpact(ctx context.Context, memoryID string, signal models.ImpactSignal, source, sessionID string, halfLifeDays float64) (float64, error) {
 	delta, ok := models.SignalDeltas[signal]
 	if !ok {
 		return 0, fmt.Errorf("unknown signal: %s", signal)
 	}
 
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
 	now := time.Now().Unix()
-	_, err := s.db.Exec(`
+	_, err = tx.ExecContext(ctx, `
 		INSERT INTO memory_impacts (memory_id, signal, source, session_id, created_at)
 		VALUES (?, ?, ?, ?, ?)
 	`, memoryID, string(signal), source, sessionID, now)
@@ -453,26 +763,240 @@ func (s *MemoryStore) RecordImpact(memoryID string, signal models.ImpactSignal,
 		return 0, fmt.Errorf("insert impact event: %w", err)
 	}
 
-	_, err = s.db.Exec(`
-		UPDATE memories SET impact_score = MIN(1.0, impact_score + ?), updated_at = ?
+	var score float64
+	var updatedAt sql.NullInt64
+	err = tx.QueryRowContext(ctx, `SELECT impact_score, impact_updated_at FROM memories WHERE id = ?`, memoryID).Scan(&score, &updatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("read impact score: %w", err)
+	}
+	if updatedAt.Valid {
+		score = ImpactAfter(score, float64(now-updatedAt.Int64)/86400.0, halfLifeDays)
+	}
+	score = math.Min(1.0, score+delta)
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE memories SET impact_score = ?, impact_updated_at = ?, updated_at = ?
 		WHERE id = ?
-	`, delta, now, memoryID)
+	`, score, now, now, memoryID)
 	if err != nil {
 		return 0, fmt.Errorf("update impact score: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit impact: %w", err)
+	}
+	return score, nil
+}
+
+// DecayImpact brings every non-zero impact score current with halfLifeDays,
+// clearing scores that decay below impactFloor. Returns the number of
+// memories whose score changed.
+func (s *MemoryStore) DecayImpact(ctx context.Context, halfLifeDays float64) (int, error) {
+	if halfLifeDays <= 0 {
+		return 0, nil
+	}
+
+	now := time.Now().Unix()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, impact_score, impact_updated_at FROM memories
+		WHERE impact_score > 0 AND impact_updated_at IS NOT NULL AND impact_updated_at < ?
+	`, now)
+	if err != nil {
+		return 0, fmt.Errorf("select impact scores: %w", err)
+	}
+
+	type decayed struct {
+		id    string
+		score float64
+	}
+	var updates []decayed
+	for rows.Next() {
+		var id string
+		var score float64
+		var updatedAt int64
+		if err := rows.Scan(&id, &score, &updatedAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan impact score: %w", err)
+		}
+		next := ImpactAfter(score, float64(now-updatedAt)/86400.0, halfLifeDays)
+		if next < impactFloor {
+			next = 0
+		}
+		updates = append(updates, decayed{id: id, score: next})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterate impact scores: %w", err)
+	}
+	if len(updates) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`UPDATE memories SET impact_score = ?, impact_updated_at = ? WHERE id = ?`)
+	if err != nil {
+		return 0, fmt.Errorf("prepare impact decay: %w", err)
+	}
+	defer stmt.Close()
+	for _, u := range updates {
+		if _, err := stmt.ExecContext(ctx, u.score, now, u.id); err != nil {
+			return 0, fmt.Errorf("decay impact %s: %w", u.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit impact decay: %w", err)
+	}
+	return len(updates), nil
+}
+
+// ImpactFromEvents replays impact events in time order: each signal adds
+// its current delta to the score as decayed since the previous event,
+// capped at 1. It returns the score as of the last event and that event's
+// time; signals no longer in SignalDeltas are skipped.
+func ImpactFromEvents(events []models.ImpactEvent, halfLifeDays float64) (float64, int64) {
 	var score float64
-	err = s.db.QueryRow(`SELECT impact_score FROM memories WHERE id = ?`, memoryID).Scan(&score)
+	var at int64
+	for _, e := range events {
+		delta, ok := models.SignalDeltas[e.Signal]
+		if !ok {
+			continue
+		}
+		if at != 0 {
+			score = ImpactAfter(score, float64(e.CreatedAt-at)/86400.0, halfLifeDays)
+		}
+		score = math.Min(1.0, score+delta)
+		at = e.CreatedAt
+	}
+	return score, at
+}
+
+// CountMemories returns the total number of memories.
+func (s *MemoryStore) CountMemories(ctx context.Context) (int, error) {
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM memories`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count memories: %w", err)
+	}
+	return n, nil
+}
+
+// CountByTier returns the number of memories in each tier.
+func (s *MemoryStore) CountByTier(ctx context.Context) (map[models.Tier]int, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT tier, COUNT(*) FROM memories GROUP BY tier`)
 	if err != nil {
-		return 0, fmt.Errorf("read impact score: %w", err)
+		return nil, fmt.Errorf("count memories by tier: %w", err)
 	}
+	defer rows.Close()
 
-	return score, nil
+	counts := map[models.Tier]int{}
+	for rows.Next() {
+		var tier models.Tier
+		var n int
+		if err := rows.Scan(&tier, &n); err != nil {
+			return nil, fmt.Errorf("scan tier count: %w", err)
+		}
+		counts[tier] = n
+	}
+	return counts, rows.Err()
+}
+
+// RecalculateImpactBatch recomputes impact_score from the memory_impacts
+// event log for up to limit memories with IDs after afterID, in ID order.
+// It returns the last ID scanned (empty once no memories remain), how many
+// were scanned and how many scores changed.
+func (s *MemoryStore) RecalculateImpactBatch(ctx context.Context, afterID string, limit int, halfLifeDays float64) (string, int, int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, impact_score, impact_updated_at FROM memories
+		WHERE id > ? ORDER BY id LIMIT ?
+	`, afterID, limit)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("select impact batch: %w", err)
+	}
+
+	type current struct {
+		id        string
+		score     float64
+		updatedAt sql.NullInt64
+	}
+	var batch []current
+	for rows.Next() {
+		var c current
+		if err := rows.Scan(&c.id, &c.score, &c.updatedAt); err != nil {
+			rows.Close()
+			return "", 0, 0, fmt.Errorf("scan impact batch: %w", err)
+		}
+		batch = append(batch, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", 0, 0, fmt.Errorf("iterate impact batch: %w", err)
+	}
+	if len(batch) == 0 {
+		return "", 0, 0, nil
+	}
+	lastID := batch[len(batch)-1].id
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT memory_id, signal, created_at FROM memory_impacts
+		WHERE memory_id > ? AND memory_id <= ?
+		ORDER BY memory_id, created_at, id
+	`, afterID, lastID)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("select impact events: %w", err)
+	}
+	events := make(map[string][]models.ImpactEvent)
+	for rows.Next() {
+		var e models.ImpactEvent
+		if err := rows.Scan(&e.MemoryID, &e.Signal, &e.CreatedAt); err != nil {
+			rows.Close()
+			return "", 0, 0, fmt.Errorf("scan impact event: %w", err)
+		}
+		events[e.MemoryID] = append(events[e.MemoryID], e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", 0, 0, fmt.Errorf("iterate impact events: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`UPDATE memories SET impact_score = ?, impact_updated_at = ? WHERE id = ?`)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("prepare impact recalculation: %w", err)
+	}
+	defer stmt.Close()
+
+	changed := 0
+	for _, c := range batch {
+		score, at := ImpactFromEvents(events[c.id], halfLifeDays)
+		updatedAt := sql.NullInt64{Int64: at, Valid: at != 0}
+		if math.Abs(score-c.score) < 1e-9 && updatedAt == c.updatedAt {
+			continue
+		}
+		if _, err := stmt.ExecContext(ctx, score, updatedAt, c.id); err != nil {
+			return "", 0, 0, fmt.Errorf("recalculate impact %s: %w", c.id, err)
+		}
+		changed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", 0, 0, fmt.Errorf("commit impact recalculation: %w", err)
+	}
+	return lastID, len(batch), changed, nil
 }
 
 // GetImpactEvents returns all impact events for a memory, ordered by creation time.
-func (s *MemoryStore) GetImpactEvents(memoryID string) ([]models.ImpactEvent, error) {
-	rows, err := s.db.Query(`
+func (s *MemoryStore) GetImpactEvents(ctx context.Context, memoryID string) ([]models.ImpactEvent, error) {
+	rows, err := s.db.QueryContext(ctx, `
 		SELECT id, memory_id, signal, source, session_id, created_at
 		FROM memory_impacts
 		WHERE memory_id = ?
@@ -498,18 +1022,19 @@ func (s *MemoryStore) GetImpactEvents(memoryID string) ([]models.ImpactEvent, er
 	return events, rows.Err()
 }
 
-// GetImpactLeaders returns top memories by impact_score for a workspace.
-func (s *MemoryStore) GetImpactLeaders(workspaceID string, limit int) ([]*models.Memory, error) {
+// GetImpactLeaders returns top memories by impact_score for a workspace,
+// limited to the memories caller may read.
+func (s *MemoryStore) GetImpactLeaders(ctx context.Context, caller models.Caller, workspaceID string, limit int) ([]*models.Memory, error) {
 	if limit <= 0 {
 		limit = 10
 	}
 
+	visible, args := visibleTo(caller)
 	query := fmt.Sprintf(`
 		SELECT %s
 		FROM memories
-		WHERE impact_score > 0
-	`, memoryColumns)
-	args := []any{}
+		WHERE impact_score > 0 AND %s
+	`, memoryColumns, visible)
 
 	if workspaceID != "" {
 		query += ` AND workspace_id = ?`
@@ -519,7 +1044,7 @@ func (s *MemoryStore) GetImpactLeaders(workspaceID string, limit int) ([]*models
 	query += ` ORDER BY impact_score DESC LIMIT ?`
 	args = append(args, limit)
 
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("get impact leaders: %w", err)
 	}
@@ -528,8 +1053,8 @@ func (s *MemoryStore) GetImpactLeaders(workspaceID string, limit int) ([]*models
 }
 
 // GetImpactPromotionCandidates returns short-term memories with impact >= threshold.
-func (s *MemoryStore) GetImpactPromotionCandidates(minImpact float64) ([]*models.Memory, error) {
-	rows, err := s.db.Query(
+func (s *MemoryStore) GetImpactPromotionCandidates(ctx context.Context, minImpact float64) ([]*models.Memory, error) {
+	rows, err := s.db.QueryContext(ctx,
 		fmt.Sprintf(`SELECT %s FROM memories WHERE tier = 'short' AND impact_score >= ?`, memoryColumns),
 		minImpact)
 	if err != nil {
@@ -539,8 +1064,41 @@ func (s *MemoryStore) GetImpactPromotionCandidates(minImpact float64) ([]*models
 	return s.scanMany(rows)
 }
 
+// GetDemotionCandidates returns live long-term memories that compaction
+// promoted and whose impact score is below maxImpact. Memories stored
+// straight to long-term, skill hints, global memories and thread entries
+// are never demoted.
+func (s *MemoryStore) GetDemotionCandidates(ctx context.Context, maxImpact float64) ([]*models.Memory, error) {
+	rows, err := s.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT %s FROM memories
+			WHERE tier = 'long' AND promoted_at IS NOT NULL AND superseded_by IS NULL AND impact_score < ?
+			  AND memory_type != ? AND workspace_id != ? AND workspace_id NOT LIKE ?
+			  AND id NOT IN (SELECT memory_id FROM thread_entries)`, memoryColumns),
+		maxImpact, string(models.MemoryTypeSkillHint), models.GlobalWorkspaceID, models.GlobalWorkspaceID+":%")
+	if err != nil {
+		return nil, fmt.Errorf("get demotion candidates: %w", err)
+	}
+	defer rows.Close()
+	return s.scanMany(rows)
+}
+
+// Demote moves a memory back to the short-term tier with the embedding it
+// had in Qdrant. Its access count is reset, so it must be recalled again
+// before it is promoted.
+func (s *MemoryStore) Demote(ctx context.Context, id string, embedding []byte, expiresAt int64) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE memories SET tier = 'short', embedding = ?, expires_at = ?, access_count = 0,
+			promoted_at = NULL, updated_at = ?
+		WHERE id = ?
+	`, embedding, expiresAt, time.Now().Unix(), id)
+	if err != nil {
+		return fmt.Errorf("demote memory: %w", err)
+	}
+	return nil
+}
+
 // GetByIDs fetches multiple memories by their IDs in a single query.
-func (s *MemoryStore) GetByIDs(ids []string) ([]*models.Memory, error) {
+func (s *MemoryStore) GetByIDs(ctx context.Context, ids []string) ([]*models.Memory, error) {
 	if len(ids) == 0 {
 		return nil, nil
 	}
@@ -552,7 +1110,7 @@ func (s *MemoryStore) GetByIDs(ids []string) ([]*models.Memory, error) {
 	}
 	query := fmt.Sprintf(`SELECT %s FROM memories WHERE id IN (%s)`,
 		memoryColumns, strings.Join(placeholders, ","))
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("get by ids: %w", err)
 	}
@@ -562,7 +1120,7 @@ func (s *MemoryStore) GetByIDs(ids []string) ([]*models.Memory, error) {
 
 // GetTimelineAround returns memories created around the same time as the anchor memory.
 // It queries by session_id first (if available), falling back to a time window.
-func (s *MemoryStore) GetTimelineAround(anchorID string, windowMinutes int, maxResults int) (before []*models.Memory, after []*models.Memory, err error) {
+func (s *MemoryStore) GetTimelineAround(ctx context.Context, anchorID string, windowMinutes int, maxResults int) (before []*models.Memory, after []*models.Memory, err error) {
 	if maxResults <= 0 {
 		maxResults = 5
 	}
@@ -570,9 +1128,9 @@ func (s *MemoryStore) GetTimelineAround(anchorID string, windowMinutes int, maxR
 		windowMinutes = 30
 	}
 
-	anchor, err := s.GetByID(anchorID)
+	anchor, err := s.GetByID(ctx, anchorID)
 	if err != nil || anchor == nil {
-		return nil, nil, fmt.Errorf("anchor memory not found: %s", anchorID)
+		return nil, nil, apperr.NotFound("memory_not_found", "anchor memory not found: %s", anchorID)
 	}
 
 	windowSecs := int64(windowMinutes * 60)
@@ -581,7 +1139,7 @@ func (s *MemoryStore) GetTimelineAround(anchorID string, windowMinutes int, maxR
 
 	// Try session-based timeline first
 	if anchor.SessionID != "" {
-		beforeRows, err := s.db.Query(
+		beforeRows, err := s.db.QueryContext(ctx,
 			fmt.Sprintf(`SELECT %s FROM memories WHERE session_id = ? AND created_at < ? AND id != ? ORDER BY created_at DESC LIMIT ?`,
 				memoryColumns),
 			anchor.SessionID, anchor.CreatedAt, anchorID, maxResults)
@@ -590,7 +1148,7 @@ func (s *MemoryStore) GetTimelineAround(anchorID string, windowMinutes int, maxR
 			before, _ = s.scanMany(beforeRows)
 		}
 
-		afterRows, err := s.db.Query(
+		afterRows, err := s.db.QueryContext(ctx,
 			fmt.Sprintf(`SELECT %s FROM memories WHERE session_id = ? AND created_at > ? AND id != ? ORDER BY created_at ASC LIMIT ?`,
 				memoryColumns),
 			anchor.SessionID, anchor.CreatedAt, anchorID, maxResults)
@@ -605,7 +1163,7 @@ func (s *MemoryStore) GetTimelineAround(anchorID string, windowMinutes int, maxR
 	}
 
 	// Fallback: time-window based
-	beforeRows, err := s.db.Query(
+	beforeRows, err := s.db.QueryContext(ctx,
 		fmt.Sprintf(`SELECT %s FROM memories WHERE workspace_id = ? AND created_at >= ? AND created_at < ? AND id != ? ORDER BY created_at DESC LIMIT ?`,
 			memoryColumns),
 		anchor.WorkspaceID, startTime, anchor.CreatedAt, anchorID, maxResults)
@@ -618,7 +1176,7 @@ func (s *MemoryStore) GetTimelineAround(anchorID string, windowMinutes int, maxR
 		return nil, nil, err
 	}
 
-	afterRows, err := s.db.Query(
+	afterRows, err := s.db.QueryContext(ctx,
 		fmt.Sprintf(`SELECT %s FROM memories WHERE workspace_id = ? AND created_at > ? AND created_at <= ? AND id != ? ORDER BY created_at ASC LIMIT ?`,
 			memoryColumns),
 		anchor.WorkspaceID, anchor.CreatedAt, endTime, anchorID, maxResults)
@@ -645,6 +1203,8 @@ func (s *MemoryStore) scanOne(row *sql.Row) (*models.Memory, error) {
 	var supersededBy sql.NullString
 	var completionStatus sql.NullString
 	var threadID sql.NullString
+	var impactUpdatedAt sql.NullInt64
+	var owner, ownerTeam sql.NullString
 
 	err := row.Scan(
 		&m.ID, &m.WorkspaceID, &m.Content, &m.MemoryType, &m.Tier,
@@ -657,13 +1217,16 @@ func (s *MemoryStore) scanOne(row *sql.Row) (*models.Memory, error) {
 		&supersededBy,
 		&completionStatus,
 		&threadID,
+		&impactUpdatedAt,
+		&m.Visibility, &owner, &ownerTeam,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	populateMemoryNullables(&m, tagsJSON, source, sessionID, embModel, expiresAt,
-		relatedFilesJSON, lastAccessedAt, encodingCtxJSON, supersededBy, completionStatus, threadID)
+		relatedFilesJSON, lastAccessedAt, encodingCtxJSON, supersededBy, completionStatus, threadID, impactUpdatedAt)
+	m.Owner, m.OwnerTeam = owner.String, ownerTeam.String
 
 	return &m, nil
 }
@@ -681,6 +1244,8 @@ func (s *MemoryStore) scanMany(rows *sql.Rows) ([]*models.Memory, error) {
 		var supersededBy sql.NullString
 		var completionStatus sql.NullString
 		var threadID sql.NullString
+		var impactUpdatedAt sql.NullInt64
+		var owner, ownerTeam sql.NullString
 
 		if err := rows.Scan(
 			&m.ID, &m.WorkspaceID, &m.Content, &m.MemoryType, &m.Tier,
@@ -693,12 +1258,15 @@ func (s *MemoryStore) scanMany(rows *sql.Rows) ([]*models.Memory, error) {
 			&supersededBy,
 			&completionStatus,
 			&threadID,
+			&impactUpdatedAt,
+			&m.Visibility, &owner, &ownerTeam,
 		); err != nil {
 			return nil, fmt.Errorf("scan memory: %w", err)
 		}
 
 		populateMemoryNullables(&m, tagsJSON, source, sessionID, embModel, expiresAt,
-			relatedFilesJSON, lastAccessedAt, encodingCtxJSON, supersededBy, completionStatus, threadID)
+			relatedFilesJSON, lastAccessedAt, encodingCtxJSON, supersededBy, completionStatus, threadID, impactUpdatedAt)
+		m.Owner, m.OwnerTeam = owner.String, ownerTeam.String
 
 		result = append(result, &m)
 	}
@@ -713,6 +1281,7 @@ func populateMemoryNullables(
 	relatedFilesJSON sql.NullString,
 	lastAccessedAt sql.NullInt64,
 	encodingCtxJSON, supersededBy, completionStatus, threadID sql.NullString,
+	impactUpdatedAt sql.NullInt64,
 ) {
 	if tagsJSON.Valid {
 		json.Unmarshal([]byte(tagsJSON.String), &m.Tags)
@@ -750,6 +1319,9 @@ func populateMemoryNullables(
 	if threadID.Valid {
 		m.ThreadID = &threadID.String
 	}
+	if impactUpdatedAt.Valid {
+		m.ImpactUpdatedAt = &impactUpdatedAt.Int64
+	}
 }
 
 // nullableString converts a byte slice to a *string for nullable TEXT columns.
@@ -0,0 +1,173 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// Quota overflow behaviours.
+const (
+	QuotaReject = "reject" // refuse the new memory
+	QuotaEvict  = "evict"  // delete the least retrievable memories to make room
+)
+
+// evictionBatch is how many eviction candidates are read at a time.
+const evictionBatch = 64
+
+// Quota caps what each project workspace may hold. A zero limit is
+// unlimited. Global workspaces are shared and never subject to a quota.
+type Quota struct {
+	MaxMemories int
+	MaxBytes    int64
+	Overflow    string
+}
+
+// AppliesTo reports whether the quota limits the given workspace.
+func (q Quota) AppliesTo(workspaceID string) bool {
+	if q.MaxMemories <= 0 && q.MaxBytes <= 0 {
+		return false
+	}
+	return !models.IsGlobalWorkspace(workspaceID)
+}
+
+// Fits reports whether a workspace with count memories and bytes of content
+// stays within the quota.
+func (q Quota) Fits(count int, bytes int64) bool {
+	return (q.MaxMemories <= 0 || count <= q.MaxMemories) &&
+		(q.MaxBytes <= 0 || bytes <= q.MaxBytes)
+}
+
+// EvictedMemory is a memory deleted to keep its workspace within quota.
+type EvictedMemory struct {
+	ID          string
+	WorkspaceID string
+	Tier        models.Tier
+}
+
+// SetQuota sets the quota enforced when memories are inserted with
+// InsertWithinQuota or appended to threads. onEvict, if set, is called
+// after an eviction commits, to clean up what lives outside SQLite.
+func (db *DB) SetQuota(q Quota, onEvict func(context.Context, []EvictedMemory)) {
+	db.quota = q
+	db.onEvict = onEvict
+}
+
+// Quota returns the quota set with SetQuota.
+func (db *DB) Quota() Quota {
+	return db.quota
+}
+
+// evicted reports committed evictions to the onEvict hook.
+func (db *DB) evicted(ctx context.Context, evicted []EvictedMemory) {
+	if len(evicted) > 0 && db.onEvict != nil {
+		db.onEvict(ctx, evicted)
+	}
+}
+
+// enforceQuota checks a workspace against the quota inside tx, after its
+// new memories were inserted. Under the reject policy an overfull
+// workspace fails; under evict the memories least likely to be recalled
+// are deleted until it fits. Thread entries and the keep IDs are never
+// evicted. The caller rolls back on error, so a refused store deletes
+// nothing, and since the database has a single connection no other write
+// can interleave between the check and the deletes.
+func (db *DB) enforceQuota(ctx context.Context, tx *sql.Tx, workspaceID string, keep ...string) ([]EvictedMemory, error) {
+	q := db.quota
+	if !q.AppliesTo(workspaceID) {
+		return nil, nil
+	}
+
+	var count int
+	var bytes int64
+	err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(LENGTH(CAST(content AS BLOB))), 0)
+		FROM memories WHERE workspace_id = ?
+	`, workspaceID).Scan(&count, &bytes)
+	if err != nil {
+		return nil, fmt.Errorf("workspace usage: %w", err)
+	}
+	if q.Fits(count, bytes) {
+		return nil, nil
+	}
+	if q.Overflow != QuotaEvict {
+		return nil, apperr.QuotaExceeded("quota_exceeded",
+			"workspace holds %d memories and %d bytes, which is at its quota", count-len(keep), bytes)
+	}
+
+	exclude := ""
+	if len(keep) > 0 {
+		exclude = " AND id NOT IN (?" + strings.Repeat(", ?", len(keep)-1) + ")"
+	}
+	// Retrievability falls with days since last access over stability, so
+	// the highest ratio is the memory least likely to be recalled
+	query := `
+		SELECT id, tier, LENGTH(CAST(content AS BLOB)) FROM memories
+		WHERE workspace_id = ?
+		  AND id NOT IN (SELECT memory_id FROM thread_entries)` + exclude + `
+		ORDER BY (? - COALESCE(NULLIF(last_accessed_at, 0), created_at))
+		       / (CASE WHEN stability > 0 THEN stability ELSE 5.0 END) DESC
+		LIMIT ?`
+	args := []any{workspaceID}
+	for _, id := range keep {
+		args = append(args, id)
+	}
+	args = append(args, time.Now().Unix(), evictionBatch)
+
+	var evicted []EvictedMemory
+	for !q.Fits(count, bytes) {
+		type candidate struct {
+			EvictedMemory
+			size int64
+		}
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("get eviction candidates: %w", err)
+		}
+		var batch []candidate
+		for rows.Next() {
+			c := candidate{EvictedMemory: EvictedMemory{WorkspaceID: workspaceID}}
+			if err := rows.Scan(&c.ID, &c.Tier, &c.size); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("scan eviction candidate: %w", err)
+			}
+			batch = append(batch, c)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("get eviction candidates: %w", err)
+		}
+		if len(batch) == 0 {
+			return nil, apperr.QuotaExceeded("quota_exceeded",
+				"workspace is at its quota and has no memories left to evict")
+		}
+
+		for _, c := range batch {
+			if q.Fits(count, bytes) {
+				break
+			}
+			if _, err := tx.ExecContext(ctx, `DELETE FROM memories WHERE id = ?`, c.ID); err != nil {
+				return nil, fmt.Errorf("evict memory: %w", err)
+			}
+			count--
+			bytes -= c.size
+			evicted = append(evicted, c.EvictedMemory)
+		}
+	}
+	return evicted, nil
+}
+
+// SetQuota sets the quota on the store's database; see DB.SetQuota.
+func (s *MemoryStore) SetQuota(q Quota, onEvict func(context.Context, []EvictedMemory)) {
+	s.db.SetQuota(q, onEvict)
+}
+
+// Quota returns the quota set on the store's database.
+func (s *MemoryStore) Quota() Quota {
+	return s.db.Quota()
+}
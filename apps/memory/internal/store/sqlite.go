@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
@@ -12,6 +13,9 @@ import (
 // DB wraps the SQLite connection with initialization logic.
 type DB struct {
 	*sql.DB
+	path    string
+	quota   Quota
+	onEvict func(context.Context, []EvictedMemory)
 }
 
 // Open creates or opens the SQLite database at the given path, runs schema
@@ -22,7 +26,9 @@ func Open(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("create db directory: %w", err)
 	}
 
-	db, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL&_synchronous=NORMAL&_busy_timeout=5000&_foreign_keys=ON")
+	// _auto_vacuum only takes effect on a new database; older ones are
+	// converted by the first Vacuum (see runIncrementalVacuumMigration).
+	db, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL&_synchronous=NORMAL&_busy_timeout=5000&_foreign_keys=ON&_auto_vacuum=incremental")
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite: %w", err)
 	}
@@ -39,7 +45,7 @@ func Open(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("run migrations: %w", err)
 	}
 
-	return &DB{db}, nil
+	return &DB{DB: db, path: dbPath}, nil
 }
 
 // runMigrations applies incremental schema changes that were added after the
@@ -95,6 +101,455 @@ func runMigrations(db *sql.DB) error {
 		return err
 	}
 
+	// --- Migration v6: Workspace aliases ---
+	if err := runWorkspaceAliasMigration(db); err != nil {
+		return err
+	}
+
+	// --- Migration v7: Idempotency keys ---
+	if err := runIdempotencyMigration(db); err != nil {
+		return err
+	}
+
+	// --- Migration v8: Thread token usage ---
+	if err := runThreadTokenUsageMigration(db); err != nil {
+		return err
+	}
+
+	// --- Migration v9: Compaction history ---
+	if err := runCompactionHistoryMigration(db); err != nil {
+		return err
+	}
+
+	// --- Migration v10: Attachments ---
+	if err := runAttachmentsMigration(db); err != nil {
+		return err
+	}
+
+	// --- Migration v11: Incremental vacuum ---
+	if err := runIncrementalVacuumMigration(db); err != nil {
+		return err
+	}
+
+	// --- Migration v12: Impact decay ---
+	if err := runImpactDecayMigration(db); err != nil {
+		return err
+	}
+
+	// --- Migration v13: Confidence calibration ---
+	if err := runCalibrationMigration(db); err != nil {
+		return err
+	}
+
+	// --- Migration v14: Encrypted config sync ---
+	if err := runSyncBlobsMigration(db); err != nil {
+		return err
+	}
+
+	// --- Migration v15: Memory access control labels ---
+	if err := runMemoryACLMigration(db); err != nil {
+		return err
+	}
+
+	// --- Migration v16: Thread entry edit log ---
+	if err := runThreadEntryEditsMigration(db); err != nil {
+		return err
+	}
+
+	// --- Migration v17: Scoped API keys ---
+	if err := runAPIKeysMigration(db); err != nil {
+		return err
+	}
+
+	// --- Migration v18: Per-entry token counts ---
+	if err := runEntryTokensMigration(db); err != nil {
+		return err
+	}
+
+	// --- Migration v19: API key identities ---
+	if err := runAPIKeyIdentityMigration(db); err != nil {
+		return err
+	}
+
+	// --- Migration v20: Demotion and consolidation ---
+	if err := runCompactionLifecycleMigration(db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// runCompactionLifecycleMigration records demoted and consolidated memories
+// in the compaction history and when compaction promoted each memory, since
+// only promoted memories are demoted (Migration v20).
+func runCompactionLifecycleMigration(db *sql.DB) error {
+	exists, err := columnExists(db, "memories", "promoted_at")
+	if err != nil {
+		return fmt.Errorf("check promoted_at column: %w", err)
+	}
+	if !exists {
+		if _, err := db.Exec(`ALTER TABLE memories ADD COLUMN promoted_at INTEGER`); err != nil {
+			return fmt.Errorf("add promoted_at column: %w", err)
+		}
+	}
+
+	for _, col := range []string{"demoted", "consolidated"} {
+		exists, err := columnExists(db, "compaction_runs", col)
+		if err != nil {
+			return fmt.Errorf("check %s column: %w", col, err)
+		}
+		if exists {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE compaction_runs ADD COLUMN ` + col + ` INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return fmt.Errorf("add %s column: %w", col, err)
+		}
+	}
+	return nil
+}
+
+// runAPIKeyIdentityMigration adds the identity column to api_keys
+// (Migration v19). Keys used to take their ACL identity from their name,
+// which whoever created the key chose freely. Existing keys are left
+// anonymous rather than trusted with the identity their name claims.
+func runAPIKeyIdentityMigration(db *sql.DB) error {
+	hasIdentity, err := columnExists(db, "api_keys", "identity")
+	if err != nil {
+		return fmt.Errorf("check identity column: %w", err)
+	}
+	if hasIdentity {
+		return nil
+	}
+	if _, err := db.Exec(`ALTER TABLE api_keys ADD COLUMN identity TEXT NOT NULL DEFAULT ''`); err != nil {
+		return fmt.Errorf("add identity to api_keys: %w", err)
+	}
+	return nil
+}
+
+// runEntryTokensMigration adds the tokens column to thread_entries
+// (Migration v18). Each entry records what it added to its thread's
+// token_usage, so edits and deletes subtract the same amount whatever the
+// tokenizer is now. Existing entries are priced as the v8 backfill priced
+// them and token_usage is recomputed to match.
+func runEntryTokensMigration(db *sql.DB) error {
+	hasTokens, err := columnExists(db, "thread_entries", "tokens")
+	if err != nil {
+		return fmt.Errorf("check tokens column: %w", err)
+	}
+	if hasTokens {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`ALTER TABLE thread_entries ADD COLUMN tokens INTEGER NOT NULL DEFAULT 0`); err != nil {
+		return fmt.Errorf("add tokens to thread_entries: %w", err)
+	}
+	_, err = tx.Exec(`
+		UPDATE thread_entries SET tokens = COALESCE(
+			(SELECT LENGTH(m.content) / 4 FROM memories m WHERE m.id = thread_entries.memory_id), 0)
+	`)
+	if err != nil {
+		return fmt.Errorf("backfill entry tokens: %w", err)
+	}
+	_, err = tx.Exec(`
+		UPDATE feature_threads SET token_usage = (
+			SELECT COALESCE(SUM(tokens), 0) FROM thread_entries WHERE thread_id = feature_threads.id
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("recompute thread token_usage: %w", err)
+	}
+	return tx.Commit()
+}
+
+// runAPIKeysMigration creates the api_keys table (Migration v17), which
+// holds the scoped keys managed through /keys. Only a hash of each token is
+// kept; revoked keys stay listed with their revocation time.
+func runAPIKeysMigration(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS api_keys (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			prefix TEXT NOT NULL,
+			namespace TEXT NOT NULL DEFAULT '',
+			workspace TEXT NOT NULL DEFAULT '',
+			workspace_id TEXT NOT NULL DEFAULT '',
+			access TEXT NOT NULL,
+			created_at INTEGER NOT NULL,
+			revoked_at INTEGER
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("create api_keys table: %w", err)
+	}
+	return nil
+}
+
+// runThreadEntryEditsMigration creates the thread_entry_edits table, the
+// log of entry updates and deletions (Migration v16). Rows outlive deleted
+// entries but go with their thread.
+func runThreadEntryEditsMigration(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS thread_entry_edits (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			thread_id TEXT NOT NULL,
+			entry_id TEXT NOT NULL,
+			memory_id TEXT NOT NULL,
+			action TEXT NOT NULL,
+			previous_content TEXT NOT NULL,
+			previous_section TEXT NOT NULL,
+			created_at INTEGER NOT NULL,
+			FOREIGN KEY (thread_id) REFERENCES feature_threads(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_thread_entry_edits_thread ON thread_entry_edits(thread_id, created_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("create thread_entry_edits table: %w", err)
+	}
+	return nil
+}
+
+// runMemoryACLMigration adds each memory's visibility label and the
+// identity that stored it (Migration v15). Existing memories are public.
+func runMemoryACLMigration(db *sql.DB) error {
+	exists, err := columnExists(db, "memories", "visibility")
+	if err != nil {
+		return fmt.Errorf("check visibility column: %w", err)
+	}
+	if exists {
+		return nil
+	}
+	stmts := []string{
+		`ALTER TABLE memories ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public'`,
+		`ALTER TABLE memories ADD COLUMN owner TEXT`,
+		`ALTER TABLE memories ADD COLUMN owner_team TEXT`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("memory acl migration: %w", err)
+		}
+	}
+	return nil
+}
+
+// runSyncBlobsMigration creates the sync_blobs table (Migration v14), which
+// holds the client-encrypted config bundles pushed with clive-memory config.
+func runSyncBlobsMigration(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS sync_blobs (
+			namespace TEXT NOT NULL,
+			name TEXT NOT NULL,
+			data BLOB NOT NULL,
+			version INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL,
+			PRIMARY KEY (namespace, name)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("create sync_blobs table: %w", err)
+	}
+	return nil
+}
+
+// runCalibrationMigration creates the confidence_adjustments table
+// (Migration v13), which holds the per-source-group confidence offsets
+// applied to new memories.
+func runCalibrationMigration(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS confidence_adjustments (
+			source_group TEXT PRIMARY KEY,
+			adjustment REAL NOT NULL,
+			samples INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("create confidence_adjustments table: %w", err)
+	}
+	return nil
+}
+
+// runImpactDecayMigration adds impact_updated_at, the time a memory's
+// impact_score was last brought current, and records decayed scores in the
+// compaction history (Migration v12). Existing scores are dated from their
+// latest impact event.
+func runImpactDecayMigration(db *sql.DB) error {
+	exists, err := columnExists(db, "memories", "impact_updated_at")
+	if err != nil {
+		return fmt.Errorf("check impact_updated_at column: %w", err)
+	}
+	if !exists {
+		stmts := []string{
+			`ALTER TABLE memories ADD COLUMN impact_updated_at INTEGER`,
+			`UPDATE memories SET impact_updated_at = COALESCE(
+				(SELECT MAX(created_at) FROM memory_impacts WHERE memory_id = memories.id),
+				updated_at)
+			WHERE impact_score > 0`,
+		}
+		for _, stmt := range stmts {
+			if _, err := db.Exec(stmt); err != nil {
+				return fmt.Errorf("impact decay migration: %w", err)
+			}
+		}
+	}
+
+	exists, err = columnExists(db, "compaction_runs", "impact_decayed")
+	if err != nil {
+		return fmt.Errorf("check impact_decayed column: %w", err)
+	}
+	if !exists {
+		if _, err := db.Exec(`ALTER TABLE compaction_runs ADD COLUMN impact_decayed INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return fmt.Errorf("add impact_decayed column: %w", err)
+		}
+	}
+	return nil
+}
+
+// runIncrementalVacuumMigration records reclaimed space in the compaction
+// history (Migration v11). New databases are created with incremental
+// auto-vacuum by Open; switching an existing one needs a full VACUUM, which
+// rewrites the whole file, so it is left to the first Vacuum maintenance
+// pass rather than run here and block startup.
+func runIncrementalVacuumMigration(db *sql.DB) error {
+	exists, err := columnExists(db, "compaction_runs", "reclaimed_bytes")
+	if err != nil {
+		return fmt.Errorf("check reclaimed_bytes column: %w", err)
+	}
+	if !exists {
+		if _, err := db.Exec(`ALTER TABLE compaction_runs ADD COLUMN reclaimed_bytes INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return fmt.Errorf("add reclaimed_bytes column: %w", err)
+		}
+	}
+	return nil
+}
+
+// runAttachmentsMigration creates the attachments table (Migration v10).
+// Attachment bytes live on disk; this table holds metadata and the optional
+// image embedding.
+func runAttachmentsMigration(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS attachments (
+			id TEXT PRIMARY KEY,
+			memory_id TEXT NOT NULL,
+			filename TEXT NOT NULL DEFAULT '',
+			content_type TEXT NOT NULL,
+			size INTEGER NOT NULL,
+			sha256 TEXT NOT NULL,
+			embedding BLOB,
+			embedding_model TEXT,
+			created_at INTEGER NOT NULL,
+			FOREIGN KEY (memory_id) REFERENCES memories(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("create attachments table: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_attachments_memory ON attachments(memory_id)`); err != nil {
+		return fmt.Errorf("create attachments index: %w", err)
+	}
+	return nil
+}
+
+// runCompactionHistoryMigration creates the compaction_runs table (Migration v9).
+func runCompactionHistoryMigration(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS compaction_runs (
+			id TEXT PRIMARY KEY,
+			trigger TEXT NOT NULL,
+			started_at INTEGER NOT NULL,
+			duration_ms INTEGER NOT NULL,
+			expired INTEGER NOT NULL DEFAULT 0,
+			forgotten_low INTEGER NOT NULL DEFAULT 0,
+			promoted INTEGER NOT NULL DEFAULT 0,
+			db_size_before INTEGER NOT NULL DEFAULT 0,
+			db_size_after INTEGER NOT NULL DEFAULT 0,
+			error TEXT NOT NULL DEFAULT ''
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("create compaction_runs table: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_compaction_runs_started_at ON compaction_runs(started_at)`); err != nil {
+		return fmt.Errorf("create compaction_runs index: %w", err)
+	}
+	return nil
+}
+
+// runThreadTokenUsageMigration adds the cumulative token_usage column to
+// feature_threads and backfills it from existing entries (Migration v8).
+func runThreadTokenUsageMigration(db *sql.DB) error {
+	hasTokenUsage, err := columnExists(db, "feature_threads", "token_usage")
+	if err != nil {
+		return fmt.Errorf("check token_usage column: %w", err)
+	}
+	if hasTokenUsage {
+		return nil
+	}
+
+	if _, err := db.Exec(`ALTER TABLE feature_threads ADD COLUMN token_usage INTEGER NOT NULL DEFAULT 0`); err != nil {
+		return fmt.Errorf("add token_usage to feature_threads: %w", err)
+	}
+	_, err = db.Exec(`
+		UPDATE feature_threads SET token_usage = (
+			SELECT COALESCE(SUM(LENGTH(m.content) / 4), 0)
+			FROM thread_entries te
+			JOIN memories m ON te.memory_id = m.id
+			WHERE te.thread_id = feature_threads.id
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("backfill thread token_usage: %w", err)
+	}
+	return nil
+}
+
+// runIdempotencyMigration creates the idempotency_keys table (Migration v7).
+func runIdempotencyMigration(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS idempotency_keys (
+			namespace TEXT NOT NULL,
+			route TEXT NOT NULL,
+			key TEXT NOT NULL,
+			request_hash TEXT NOT NULL,
+			status INTEGER NOT NULL,
+			response BLOB NOT NULL,
+			created_at INTEGER NOT NULL,
+			PRIMARY KEY (namespace, route, key)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("create idempotency_keys table: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_idempotency_created_at ON idempotency_keys(created_at)`); err != nil {
+		return fmt.Errorf("create idempotency_keys index: %w", err)
+	}
+	return nil
+}
+
+// runWorkspaceAliasMigration creates the workspace_aliases table (Migration v6),
+// which maps path-based workspace IDs onto remote-based workspaces.
+func runWorkspaceAliasMigration(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS workspace_aliases (
+			alias_id TEXT PRIMARY KEY,
+			workspace_id TEXT NOT NULL,
+			path TEXT NOT NULL,
+			created_at INTEGER NOT NULL,
+			FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("create workspace_aliases table: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_workspace_aliases_workspace ON workspace_aliases(workspace_id)`); err != nil {
+		return fmt.Errorf("create workspace_aliases index: %w", err)
+	}
 	return nil
 }
 
@@ -406,12 +861,32 @@ END;`,
 }
 
 // MemoryCount returns the total number of memories in the database.
-func (db *DB) MemoryCount() (int, error) {
+func (db *DB) MemoryCount(ctx context.Context) (int, error) {
 	var count int
-	err := db.QueryRow("SELECT COUNT(*) FROM memories").Scan(&count)
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM memories").Scan(&count)
 	return count, err
 }
 
+// UsedBytes returns the bytes occupied by live pages. Unlike the file size it
+// shrinks when rows are deleted, without needing a VACUUM.
+func (db *DB) UsedBytes(ctx context.Context) (int64, error) {
+	var used int64
+	err := db.QueryRowContext(ctx, `
+		SELECT (p.page_count - f.freelist_count) * s.page_size
+		FROM pragma_page_count() p, pragma_freelist_count() f, pragma_page_size() s
+	`).Scan(&used)
+	return used, err
+}
+
+// Checkpoint flushes the WAL into the main database file so a clean
+// shutdown leaves no pending writes in the journal.
+func (db *DB) Checkpoint(ctx context.Context) error {
+	if _, err := db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("wal checkpoint: %w", err)
+	}
+	return nil
+}
+
 // columnExists checks if a column exists in a table. It properly closes the
 // rows cursor before returning, avoiding deadlocks with MaxOpenConns(1).
 func columnExists(db *sql.DB, table, column string) (bool, error) {
@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// SyncStore keeps the encrypted config bundles clients push and pull. Each
+// write bumps the blob's version so clients can detect concurrent pushes.
+type SyncStore struct {
+	db *DB
+}
+
+func NewSyncStore(db *DB) *SyncStore {
+	return &SyncStore{db: db}
+}
+
+// Get returns a blob with its data, or nil if none is stored under name.
+func (s *SyncStore) Get(ctx context.Context, namespace, name string) (*models.SyncBlob, error) {
+	b := &models.SyncBlob{Name: name}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT data, version, updated_at FROM sync_blobs WHERE namespace = ? AND name = ?
+	`, namespace, name).Scan(&b.Data, &b.Version, &b.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get sync blob: %w", err)
+	}
+	b.Size = len(b.Data)
+	return b, nil
+}
+
+// List returns the blobs in a namespace without their data.
+func (s *SyncStore) List(ctx context.Context, namespace string) ([]models.SyncBlob, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, version, LENGTH(data), updated_at FROM sync_blobs
+		WHERE namespace = ? ORDER BY name
+	`, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("list sync blobs: %w", err)
+	}
+	defer rows.Close()
+
+	blobs := []models.SyncBlob{}
+	for rows.Next() {
+		var b models.SyncBlob
+		if err := rows.Scan(&b.Name, &b.Version, &b.Size, &b.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan sync blob: %w", err)
+		}
+		blobs = append(blobs, b)
+	}
+	return blobs, rows.Err()
+}
+
+// Put stores data under name and returns the new version. With baseVersion
+// set, a blob that has moved on since (or, for 0, already exists) is a
+// sync_conflict.
+func (s *SyncStore) Put(ctx context.Context, namespace, name string, data []byte, baseVersion *int) (*models.SyncBlob, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current int
+	err = tx.QueryRowContext(ctx, `SELECT version FROM sync_blobs WHERE namespace = ? AND name = ?`, namespace, name).Scan(&current)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("read sync blob version: %w", err)
+	}
+	if baseVersion != nil && *baseVersion != current {
+		return nil, apperr.Conflict("sync_conflict", "sync blob %q is at version %d, not %d; pull before pushing", name, current, *baseVersion)
+	}
+
+	b := &models.SyncBlob{Name: name, Version: current + 1, Size: len(data), UpdatedAt: time.Now().Unix()}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO sync_blobs (namespace, name, data, version, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(namespace, name) DO UPDATE SET
+			data = excluded.data,
+			version = excluded.version,
+			updated_at = excluded.updated_at
+	`, namespace, name, data, b.Version, b.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("put sync blob: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit sync blob: %w", err)
+	}
+	return b, nil
+}
+
+// Delete removes a blob, reporting whether one existed.
+func (s *SyncStore) Delete(ctx context.Context, namespace, name string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM sync_blobs WHERE namespace = ? AND name = ?`, namespace, name)
+	if err != nil {
+		return false, fmt.Errorf("delete sync blob: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
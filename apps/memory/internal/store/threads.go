@@ -1,12 +1,14 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
 	"github.com/iammorganparry/clive/apps/memory/internal/models"
 )
 
@@ -20,11 +22,11 @@ func NewThreadStore(db *DB) *ThreadStore {
 }
 
 // CreateThread inserts a new feature thread.
-func (s *ThreadStore) CreateThread(t *models.FeatureThread) error {
+func (s *ThreadStore) CreateThread(ctx context.Context, t *models.FeatureThread) error {
 	relatedFilesJSON, _ := json.Marshal(t.RelatedFiles)
 	tagsJSON, _ := json.Marshal(t.Tags)
 
-	_, err := s.db.Exec(`
+	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO feature_threads (
 			id, workspace_id, name, description, status,
 			created_at, updated_at, entry_count, token_budget,
@@ -42,11 +44,11 @@ func (s *ThreadStore) CreateThread(t *models.FeatureThread) error {
 }
 
 // GetThread fetches a thread by ID.
-func (s *ThreadStore) GetThread(id string) (*models.FeatureThread, error) {
-	t, err := s.scanThread(s.db.QueryRow(`
+func (s *ThreadStore) GetThread(ctx context.Context, id string) (*models.FeatureThread, error) {
+	t, err := s.scanThread(s.db.QueryRowContext(ctx, `
 		SELECT id, workspace_id, name, description, status,
 			created_at, updated_at, closed_at, entry_count, token_budget,
-			summary, related_files, tags
+			token_usage, summary, related_files, tags
 		FROM feature_threads WHERE id = ?
 	`, id))
 	if err == sql.ErrNoRows {
@@ -56,11 +58,11 @@ func (s *ThreadStore) GetThread(id string) (*models.FeatureThread, error) {
 }
 
 // GetThreadByName fetches a thread by name within a workspace.
-func (s *ThreadStore) GetThreadByName(workspaceID, name string) (*models.FeatureThread, error) {
-	t, err := s.scanThread(s.db.QueryRow(`
+func (s *ThreadStore) GetThreadByName(ctx context.Context, workspaceID, name string) (*models.FeatureThread, error) {
+	t, err := s.scanThread(s.db.QueryRowContext(ctx, `
 		SELECT id, workspace_id, name, description, status,
 			created_at, updated_at, closed_at, entry_count, token_budget,
-			summary, related_files, tags
+			token_usage, summary, related_files, tags
 		FROM feature_threads WHERE workspace_id = ? AND name = ?
 	`, workspaceID, name))
 	if err == sql.ErrNoRows {
@@ -70,7 +72,7 @@ func (s *ThreadStore) GetThreadByName(workspaceID, name string) (*models.Feature
 }
 
 // ListThreads returns threads filtered by workspace, status, and/or name.
-func (s *ThreadStore) ListThreads(workspaceID string, status models.ThreadStatus, name string) ([]*models.FeatureThread, error) {
+func (s *ThreadStore) ListThreads(ctx context.Context, workspaceID string, status models.ThreadStatus, name string) ([]*models.FeatureThread, error) {
 	var conditions []string
 	var args []any
 
@@ -92,10 +94,10 @@ func (s *ThreadStore) ListThreads(workspaceID string, status models.ThreadStatus
 		where = "WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	rows, err := s.db.Query(fmt.Sprintf(`
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
 		SELECT id, workspace_id, name, description, status,
 			created_at, updated_at, closed_at, entry_count, token_budget,
-			summary, related_files, tags
+			token_usage, summary, related_files, tags
 		FROM feature_threads %s ORDER BY updated_at DESC
 	`, where), args...)
 	if err != nil {
@@ -106,7 +108,7 @@ func (s *ThreadStore) ListThreads(workspaceID string, status models.ThreadStatus
 }
 
 // UpdateThread applies partial updates to a thread.
-func (s *ThreadStore) UpdateThread(id string, req *models.UpdateThreadRequest) (*models.FeatureThread, error) {
+func (s *ThreadStore) UpdateThread(ctx context.Context, id string, req *models.UpdateThreadRequest) (*models.FeatureThread, error) {
 	sets := []string{"updated_at = ?"}
 	args := []any{time.Now().Unix()}
 
@@ -144,63 +146,305 @@ func (s *ThreadStore) UpdateThread(id string, req *models.UpdateThreadRequest) (
 
 	args = append(args, id)
 	query := fmt.Sprintf("UPDATE feature_threads SET %s WHERE id = ?", strings.Join(sets, ", "))
-	res, err := s.db.Exec(query, args...)
+	res, err := s.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("update thread: %w", err)
 	}
 	n, _ := res.RowsAffected()
 	if n == 0 {
-		return nil, fmt.Errorf("thread not found: %s", id)
+		return nil, apperr.NotFound("thread_not_found", "thread not found: %s", id)
 	}
 
-	return s.GetThread(id)
+	return s.GetThread(ctx, id)
 }
 
 // DeleteThread removes a thread and its entries (cascading).
-func (s *ThreadStore) DeleteThread(id string) error {
+func (s *ThreadStore) DeleteThread(ctx context.Context, id string) error {
 	// Clear thread_id on associated memories first
-	_, err := s.db.Exec(`UPDATE memories SET thread_id = NULL WHERE thread_id = ?`, id)
+	_, err := s.db.ExecContext(ctx, `UPDATE memories SET thread_id = NULL WHERE thread_id = ?`, id)
 	if err != nil {
 		return fmt.Errorf("clear memory thread_ids: %w", err)
 	}
 
-	res, err := s.db.Exec("DELETE FROM feature_threads WHERE id = ?", id)
+	res, err := s.db.ExecContext(ctx, "DELETE FROM feature_threads WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("delete thread: %w", err)
 	}
 	n, _ := res.RowsAffected()
 	if n == 0 {
-		return fmt.Errorf("thread not found: %s", id)
+		return apperr.NotFound("thread_not_found", "thread not found: %s", id)
+	}
+	return nil
+}
+
+// PendingEntry is a thread entry and the memory backing it, written together
+// by AppendEntries.
+type PendingEntry struct {
+	Memory *models.Memory
+	Entry  *models.ThreadEntry
+	Tokens int
+}
+
+// AppendEntries inserts each entry's memory and thread entry in a single
+// transaction, so a batch lands whole or not at all. Sequences are assigned
+// in order after the thread's current maximum.
+func (s *ThreadStore) AppendEntries(ctx context.Context, threadID string, pending []PendingEntry) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var maxSeq sql.NullInt64
+	if err := tx.QueryRowContext(ctx, `SELECT MAX(sequence) FROM thread_entries WHERE thread_id = ?`, threadID).Scan(&maxSeq); err != nil {
+		return fmt.Errorf("get next sequence: %w", err)
+	}
+	seq := int(maxSeq.Int64)
+
+	tokens := 0
+	workspaces := map[string]bool{}
+	for _, p := range pending {
+		if err := insertMemory(ctx, tx, p.Memory); err != nil {
+			return err
+		}
+		workspaces[p.Memory.WorkspaceID] = true
+		seq++
+		p.Entry.Sequence = seq
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO thread_entries (id, thread_id, memory_id, sequence, section, tokens, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, p.Entry.ID, threadID, p.Entry.MemoryID, p.Entry.Sequence, string(p.Entry.Section), p.Tokens, p.Entry.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("insert thread entry: %w", err)
+		}
+		tokens += p.Tokens
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE feature_threads
+		SET entry_count = entry_count + ?, token_usage = token_usage + ?, updated_at = ?
+		WHERE id = ?
+	`, len(pending), tokens, time.Now().Unix(), threadID)
+	if err != nil {
+		return fmt.Errorf("update thread entry count: %w", err)
+	}
+
+	// Entries count against the quota like any memory, though they are
+	// never evicted themselves
+	var evicted []EvictedMemory
+	for workspaceID := range workspaces {
+		ev, err := s.db.enforceQuota(ctx, tx, workspaceID)
+		if err != nil {
+			return err
+		}
+		evicted = append(evicted, ev...)
 	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit entries: %w", err)
+	}
+	s.db.evicted(ctx, evicted)
 	return nil
 }
 
-// AppendEntry adds a new entry to a thread and increments entry_count.
-func (s *ThreadStore) AppendEntry(entry *models.ThreadEntry) error {
-	_, err := s.db.Exec(`
-		INSERT INTO thread_entries (id, thread_id, memory_id, sequence, section, created_at)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, entry.ID, entry.ThreadID, entry.MemoryID, entry.Sequence, string(entry.Section), entry.CreatedAt)
+// ReclassifyEntries moves entries of a thread to section in one transaction.
+// Nothing moves unless every ID belongs to the thread.
+func (s *ThreadStore) ReclassifyEntries(ctx context.Context, threadID string, entryIDs []string, section models.ThreadSection) (int, error) {
+	unique := make(map[string]bool, len(entryIDs))
+	args := []any{string(section), threadID}
+	for _, id := range entryIDs {
+		if !unique[id] {
+			unique[id] = true
+			args = append(args, id)
+		}
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(unique)), ",")
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("insert thread entry: %w", err)
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE thread_entries SET section = ?
+		WHERE thread_id = ? AND id IN (%s)
+	`, placeholders), args...)
+	if err != nil {
+		return 0, fmt.Errorf("reclassify entries: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("reclassify entries: %w", err)
+	}
+	if int(n) != len(unique) {
+		return 0, apperr.NotFound("entry_not_found", "%d of %d entries not found in thread %s", len(unique)-int(n), len(unique), threadID)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE feature_threads SET updated_at = ? WHERE id = ?`, time.Now().Unix(), threadID); err != nil {
+		return 0, fmt.Errorf("touch thread: %w", err)
 	}
 
-	// Increment entry count and update timestamp
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit reclassify: %w", err)
+	}
+	return int(n), nil
+}
+
+// GetEntry returns one entry of a thread with its memory content joined, or
+// nil if the thread has no such entry.
+func (s *ThreadStore) GetEntry(ctx context.Context, threadID, entryID string) (*models.ThreadEntry, error) {
+	var e models.ThreadEntry
+	err := s.db.QueryRowContext(ctx, `
+		SELECT te.id, te.thread_id, te.memory_id, te.sequence, te.section, te.created_at,
+			m.content, m.memory_type
+		FROM thread_entries te
+		JOIN memories m ON te.memory_id = m.id
+		WHERE te.thread_id = ? AND te.id = ?
+	`, threadID, entryID).Scan(
+		&e.ID, &e.ThreadID, &e.MemoryID, &e.Sequence, &e.Section, &e.CreatedAt,
+		&e.Content, &e.MemoryType,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get entry: %w", err)
+	}
+	return &e, nil
+}
+
+// UpdateEntry writes entry's section and content, rewriting its memory, and
+// logs previous in the thread's edit log, all in one transaction. When the
+// content changed, tokens is its new token count; the thread's token usage
+// swaps the entry's recorded count for it.
+func (s *ThreadStore) UpdateEntry(ctx context.Context, entry, previous *models.ThreadEntry, contentHash string, tokens int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
 	now := time.Now().Unix()
-	_, err = s.db.Exec(`
-		UPDATE feature_threads SET entry_count = entry_count + 1, updated_at = ?
+	if _, err := tx.ExecContext(ctx, `UPDATE thread_entries SET section = ? WHERE id = ?`, string(entry.Section), entry.ID); err != nil {
+		return fmt.Errorf("update entry: %w", err)
+	}
+	if entry.Content != previous.Content {
+		_, err := tx.ExecContext(ctx, `UPDATE memories SET content = ?, content_hash = ?, updated_at = ? WHERE id = ?`,
+			entry.Content, contentHash, now, entry.MemoryID)
+		if err != nil {
+			return fmt.Errorf("update entry memory: %w", err)
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE feature_threads
+			SET token_usage = MAX(token_usage + ? - (SELECT tokens FROM thread_entries WHERE id = ?), 0)
+			WHERE id = ?
+		`, tokens, entry.ID, entry.ThreadID)
+		if err != nil {
+			return fmt.Errorf("update thread token usage: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE thread_entries SET tokens = ? WHERE id = ?`, tokens, entry.ID); err != nil {
+			return fmt.Errorf("update entry tokens: %w", err)
+		}
+	}
+	if err := logEntryEdit(ctx, tx, previous, models.EntryEditUpdated, now); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE feature_threads SET updated_at = ? WHERE id = ?`, now, entry.ThreadID); err != nil {
+		return fmt.Errorf("touch thread: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit entry update: %w", err)
+	}
+	return nil
+}
+
+// DeleteEntry removes entry and the memory backing it, closes the gap it
+// leaves in the thread's sequence and logs it in the edit log, all in one
+// transaction. The thread's token usage drops by the entry's recorded count.
+func (s *ThreadStore) DeleteEntry(ctx context.Context, entry *models.ThreadEntry) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	var tokens int
+	if err := tx.QueryRowContext(ctx, `SELECT tokens FROM thread_entries WHERE id = ?`, entry.ID).Scan(&tokens); err != nil {
+		return fmt.Errorf("get entry tokens: %w", err)
+	}
+	if err := logEntryEdit(ctx, tx, entry, models.EntryEditDeleted, now); err != nil {
+		return err
+	}
+	// The memory exists only to back this entry
+	if _, err := tx.ExecContext(ctx, `DELETE FROM memories WHERE id = ?`, entry.MemoryID); err != nil {
+		return fmt.Errorf("delete entry memory: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM thread_entries WHERE id = ?`, entry.ID); err != nil {
+		return fmt.Errorf("delete entry: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE thread_entries SET sequence = sequence - 1 WHERE thread_id = ? AND sequence > ?`,
+		entry.ThreadID, entry.Sequence)
+	if err != nil {
+		return fmt.Errorf("resequence entries: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE feature_threads
+		SET entry_count = MAX(entry_count - 1, 0), token_usage = MAX(token_usage - ?, 0), updated_at = ?
 		WHERE id = ?
-	`, now, entry.ThreadID)
+	`, tokens, now, entry.ThreadID)
 	if err != nil {
 		return fmt.Errorf("update thread entry count: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit entry delete: %w", err)
+	}
+	return nil
+}
+
+func logEntryEdit(ctx context.Context, tx *sql.Tx, previous *models.ThreadEntry, action models.EntryEditAction, now int64) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO thread_entry_edits (thread_id, entry_id, memory_id, action, previous_content, previous_section, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, previous.ThreadID, previous.ID, previous.MemoryID, string(action),
+		previous.Content, string(previous.Section), now)
+	if err != nil {
+		return fmt.Errorf("log entry edit: %w", err)
+	}
 	return nil
 }
 
+// GetEntryEdits returns a thread's edit log, oldest first.
+func (s *ThreadStore) GetEntryEdits(ctx context.Context, threadID string) ([]models.ThreadEntryEdit, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, thread_id, entry_id, memory_id, action, previous_content, previous_section, created_at
+		FROM thread_entry_edits
+		WHERE thread_id = ?
+		ORDER BY id ASC
+	`, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("get entry edits: %w", err)
+	}
+	defer rows.Close()
+
+	edits := []models.ThreadEntryEdit{}
+	for rows.Next() {
+		var e models.ThreadEntryEdit
+		if err := rows.Scan(&e.ID, &e.ThreadID, &e.EntryID, &e.MemoryID, &e.Action,
+			&e.PreviousContent, &e.PreviousSection, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan entry edit: %w", err)
+		}
+		edits = append(edits, e)
+	}
+	return edits, rows.Err()
+}
+
 // GetEntries returns all entries for a thread, ordered by sequence, with memory content joined.
-func (s *ThreadStore) GetEntries(threadID string) ([]models.ThreadEntry, error) {
-	rows, err := s.db.Query(`
+func (s *ThreadStore) GetEntries(ctx context.Context, threadID string) ([]models.ThreadEntry, error) {
+	rows, err := s.db.QueryContext(ctx, `
 		SELECT te.id, te.thread_id, te.memory_id, te.sequence, te.section, te.created_at,
 			m.content, m.memory_type
 		FROM thread_entries te
@@ -228,8 +472,8 @@ func (s *ThreadStore) GetEntries(threadID string) ([]models.ThreadEntry, error)
 }
 
 // GetEntriesBySection returns entries for a thread filtered by section.
-func (s *ThreadStore) GetEntriesBySection(threadID string, section models.ThreadSection) ([]models.ThreadEntry, error) {
-	rows, err := s.db.Query(`
+func (s *ThreadStore) GetEntriesBySection(ctx context.Context, threadID string, section models.ThreadSection) ([]models.ThreadEntry, error) {
+	rows, err := s.db.QueryContext(ctx, `
 		SELECT te.id, te.thread_id, te.memory_id, te.sequence, te.section, te.created_at,
 			m.content, m.memory_type
 		FROM thread_entries te
@@ -256,24 +500,9 @@ func (s *ThreadStore) GetEntriesBySection(threadID string, section models.Thread
 	return entries, rows.Err()
 }
 
-// NextSequence returns the next sequence number for a thread.
-func (s *ThreadStore) NextSequence(threadID string) (int, error) {
-	var maxSeq sql.NullInt64
-	err := s.db.QueryRow(`
-		SELECT MAX(sequence) FROM thread_entries WHERE thread_id = ?
-	`, threadID).Scan(&maxSeq)
-	if err != nil {
-		return 1, nil
-	}
-	if !maxSeq.Valid {
-		return 1, nil
-	}
-	return int(maxSeq.Int64) + 1, nil
-}
-
 // GetActiveThreadIDs returns IDs of all active threads.
-func (s *ThreadStore) GetActiveThreadIDs() ([]string, error) {
-	rows, err := s.db.Query(`SELECT id FROM feature_threads WHERE status = 'active'`)
+func (s *ThreadStore) GetActiveThreadIDs(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM feature_threads WHERE status = 'active'`)
 	if err != nil {
 		return nil, fmt.Errorf("get active thread ids: %w", err)
 	}
@@ -298,7 +527,7 @@ func (s *ThreadStore) scanThread(row *sql.Row) (*models.FeatureThread, error) {
 	err := row.Scan(
 		&t.ID, &t.WorkspaceID, &t.Name, &t.Description, &t.Status,
 		&t.CreatedAt, &t.UpdatedAt, &closedAt, &t.EntryCount, &t.TokenBudget,
-		&t.Summary, &relatedFilesJSON, &tagsJSON,
+		&t.TokenUsage, &t.Summary, &relatedFilesJSON, &tagsJSON,
 	)
 	if err != nil {
 		return nil, err
@@ -327,7 +556,7 @@ func (s *ThreadStore) scanThreads(rows *sql.Rows) ([]*models.FeatureThread, erro
 		if err := rows.Scan(
 			&t.ID, &t.WorkspaceID, &t.Name, &t.Description, &t.Status,
 			&t.CreatedAt, &t.UpdatedAt, &closedAt, &t.EntryCount, &t.TokenBudget,
-			&t.Summary, &relatedFilesJSON, &tagsJSON,
+			&t.TokenUsage, &t.Summary, &relatedFilesJSON, &tagsJSON,
 		); err != nil {
 			return nil, fmt.Errorf("scan thread: %w", err)
 		}
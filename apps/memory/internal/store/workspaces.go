@@ -1,12 +1,18 @@
 package store
 
 import (
+	"context"
 	"crypto/sha256"
 	"database/sql"
 	"fmt"
+	"net/url"
+	"path"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
 	"github.com/iammorganparry/clive/apps/memory/internal/models"
 )
 
@@ -54,19 +60,76 @@ func NamespacedGlobalID(namespace string) string {
 
 // EnsureWorkspace registers a workspace if it doesn't exist, or updates
 // last_accessed_at if it does. Returns the workspace ID.
-func (s *WorkspaceStore) EnsureWorkspace(namespace, absPath string) (string, error) {
-	id := WorkspaceID(namespace, absPath)
-	name := filepath.Base(absPath)
+//
+// The workspace may be an absolute path or a git remote URL (optionally with
+// a "#subdir" suffix). Paths that were merged into a remote-based workspace
+// resolve to that workspace through their alias.
+func (s *WorkspaceStore) EnsureWorkspace(ctx context.Context, namespace, workspace string) (string, error) {
+	if IsRemoteWorkspace(workspace) {
+		remote, subdir, _ := strings.Cut(workspace, "#")
+		id, _, err := s.EnsureRemoteWorkspace(ctx, namespace, remote, subdir)
+		return id, err
+	}
+
+	id, err := s.ResolveWorkspaceID(ctx, namespace, workspace)
+	if err != nil {
+		return "", err
+	}
+	return s.ensure(ctx, namespace, id, workspace, filepath.Base(workspace))
+}
+
+// ResolveWorkspaceID returns the ID a workspace path or remote maps to,
+// following aliases, without registering anything.
+func (s *WorkspaceStore) ResolveWorkspaceID(ctx context.Context, namespace, workspace string) (string, error) {
+	if IsRemoteWorkspace(workspace) {
+		remote, subdir, _ := strings.Cut(workspace, "#")
+		identity, err := RemoteIdentity(remote, subdir)
+		if err != nil {
+			return "", apperr.ValidationFailed("invalid_remote", "%s", err.Error())
+		}
+		return WorkspaceID(namespace, identity), nil
+	}
+
+	id := WorkspaceID(namespace, workspace)
+	target, err := s.resolveAlias(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if target != "" {
+		return target, nil
+	}
+	return id, nil
+}
+
+// EnsureRemoteWorkspace registers the workspace identified by a git remote
+// and optional subdirectory. Returns the workspace ID and normalized identity.
+func (s *WorkspaceStore) EnsureRemoteWorkspace(ctx context.Context, namespace, remote, subdir string) (string, string, error) {
+	identity, err := RemoteIdentity(remote, subdir)
+	if err != nil {
+		return "", "", apperr.ValidationFailed("invalid_remote", "%s", err.Error())
+	}
+
+	repo, _, _ := strings.Cut(identity, "#")
+	name := path.Base(repo)
+	if sub := cleanSubdir(subdir); sub != "" {
+		name = path.Base(sub)
+	}
+
+	id, err := s.ensure(ctx, namespace, WorkspaceID(namespace, identity), identity, name)
+	return id, identity, err
+}
+
+func (s *WorkspaceStore) ensure(ctx context.Context, namespace, id, workspacePath, name string) (string, error) {
 	now := time.Now().Unix()
 
 	// For non-default namespaces, prefix the stored path to avoid UNIQUE constraint
 	// conflicts when two namespaces use the same workspace path.
-	storedPath := absPath
+	storedPath := workspacePath
 	if namespace != "" && namespace != "default" {
-		storedPath = namespace + ":" + absPath
+		storedPath = namespace + ":" + workspacePath
 	}
 
-	_, err := s.db.Exec(`
+	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO workspaces (id, path, name, created_at, last_accessed_at)
 		VALUES (?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET last_accessed_at = ?
@@ -78,15 +141,149 @@ func (s *WorkspaceStore) EnsureWorkspace(namespace, absPath string) (string, err
 	return id, nil
 }
 
+// resolveAlias returns the workspace a path-based ID was merged into, or "".
+func (s *WorkspaceStore) resolveAlias(ctx context.Context, aliasID string) (string, error) {
+	var target string
+	err := s.db.QueryRowContext(ctx, `SELECT workspace_id FROM workspace_aliases WHERE alias_id = ?`, aliasID).Scan(&target)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("resolve workspace alias: %w", err)
+	}
+	return target, nil
+}
+
+// AddAlias routes a workspace path to another workspace from now on.
+func (s *WorkspaceStore) AddAlias(ctx context.Context, namespace, absPath, workspaceID string) error {
+	storedPath := absPath
+	if namespace != "" && namespace != "default" {
+		storedPath = namespace + ":" + absPath
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO workspace_aliases (alias_id, workspace_id, path, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(alias_id) DO UPDATE SET workspace_id = excluded.workspace_id
+	`, WorkspaceID(namespace, absPath), workspaceID, storedPath, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("add workspace alias: %w", err)
+	}
+	return nil
+}
+
+// WorkspaceMoveCounts reports how many rows Reassign moved.
+type WorkspaceMoveCounts struct {
+	Memories int
+	Threads  int
+	Sessions int
+}
+
+// Reassign moves all memories, threads and sessions from one workspace to
+// another and deletes the source workspace, in a single transaction.
+func (s *WorkspaceStore) Reassign(ctx context.Context, fromID, toID string) (*WorkspaceMoveCounts, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin reassign: %w", err)
+	}
+	defer tx.Rollback()
+
+	var counts WorkspaceMoveCounts
+	moves := []struct {
+		query string
+		n     *int
+	}{
+		{`UPDATE memories SET workspace_id = ? WHERE workspace_id = ?`, &counts.Memories},
+		{`UPDATE feature_threads SET workspace_id = ? WHERE workspace_id = ?`, &counts.Threads},
+		{`UPDATE sessions SET workspace_id = ? WHERE workspace_id = ?`, &counts.Sessions},
+	}
+	for _, m := range moves {
+		res, err := tx.ExecContext(ctx, m.query, toID, fromID)
+		if err != nil {
+			return nil, fmt.Errorf("reassign workspace: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		*m.n = int(n)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE workspace_aliases SET workspace_id = ? WHERE workspace_id = ?`, toID, fromID); err != nil {
+		return nil, fmt.Errorf("reassign workspace aliases: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM workspaces WHERE id = ?`, fromID); err != nil {
+		return nil, fmt.Errorf("delete merged workspace: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit reassign: %w", err)
+	}
+	return &counts, nil
+}
+
+// scpRemote matches scp-style git remotes such as git@github.com:owner/repo.git.
+var scpRemote = regexp.MustCompile(`^[\w.-]+@([\w.-]+):(.+)$`)
+
+// IsRemoteWorkspace reports whether a workspace string is a git remote URL
+// rather than a filesystem path.
+func IsRemoteWorkspace(workspace string) bool {
+	if strings.HasPrefix(workspace, "/") {
+		return false
+	}
+	return strings.Contains(workspace, "://") || scpRemote.MatchString(workspace)
+}
+
+// NormalizeRemote converts a git remote URL into a lowercase host/owner/repo
+// form, so SSH and HTTPS clones of the same repository share an identity.
+func NormalizeRemote(remote string) (string, error) {
+	remote = strings.TrimSpace(remote)
+	var host, repoPath string
+
+	if m := scpRemote.FindStringSubmatch(remote); m != nil && !strings.Contains(remote, "://") {
+		host, repoPath = m[1], m[2]
+	} else {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return "", fmt.Errorf("parse remote %q: %w", remote, err)
+		}
+		host, repoPath = u.Hostname(), u.Path
+	}
+
+	repoPath = strings.Trim(repoPath, "/")
+	repoPath = strings.TrimSuffix(repoPath, ".git")
+	if host == "" || repoPath == "" {
+		return "", fmt.Errorf("remote %q has no host or repository path", remote)
+	}
+	return strings.ToLower(host + "/" + repoPath), nil
+}
+
+// RemoteIdentity builds the workspace identity for a remote and optional
+// subdirectory, e.g. "github.com/owner/repo#apps/memory".
+func RemoteIdentity(remote, subdir string) (string, error) {
+	identity, err := NormalizeRemote(remote)
+	if err != nil {
+		return "", err
+	}
+	if sub := cleanSubdir(subdir); sub != "" {
+		identity += "#" + sub
+	}
+	return identity, nil
+}
+
+func cleanSubdir(subdir string) string {
+	sub := strings.Trim(path.Clean("/"+subdir), "/")
+	if sub == "." {
+		return ""
+	}
+	return sub
+}
+
 // EnsureNamespacedGlobal ensures the global workspace exists for a namespace.
-func (s *WorkspaceStore) EnsureNamespacedGlobal(namespace string) {
+func (s *WorkspaceStore) EnsureNamespacedGlobal(ctx context.Context, namespace string) {
 	globalID := NamespacedGlobalID(namespace)
 	globalPath := "__global__"
 	if namespace != "" && namespace != "default" {
 		globalPath = "__global__:" + namespace
 	}
 	now := time.Now().Unix()
-	s.db.Exec(`
+	s.db.ExecContext(ctx, `
 		INSERT INTO workspaces (id, path, name, created_at, last_accessed_at)
 		VALUES (?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO NOTHING
@@ -94,9 +291,9 @@ func (s *WorkspaceStore) EnsureNamespacedGlobal(namespace string) {
 }
 
 // GetWorkspace returns a workspace by ID.
-func (s *WorkspaceStore) GetWorkspace(id string) (*models.Workspace, error) {
+func (s *WorkspaceStore) GetWorkspace(ctx context.Context, id string) (*models.Workspace, error) {
 	var w models.Workspace
-	err := s.db.QueryRow(`
+	err := s.db.QueryRowContext(ctx, `
 		SELECT id, path, name, created_at, last_accessed_at
 		FROM workspaces WHERE id = ?
 	`, id).Scan(&w.ID, &w.Path, &w.Name, &w.CreatedAt, &w.LastAccessedAt)
@@ -109,9 +306,27 @@ func (s *WorkspaceStore) GetWorkspace(id string) (*models.Workspace, error) {
 	return &w, nil
 }
 
+// Namespace returns the namespace a workspace belongs to. It isn't stored:
+// non-default namespaces prefix the stored path with "namespace:", and the
+// prefix counts only if it hashes back to the workspace's ID, so a path that
+// merely contains a colon stays in "default".
+func (s *WorkspaceStore) Namespace(ctx context.Context, id string) (string, error) {
+	if ns, ok := strings.CutPrefix(id, models.GlobalWorkspaceID+":"); ok {
+		return ns, nil
+	}
+	w, err := s.GetWorkspace(ctx, id)
+	if err != nil || w == nil {
+		return "default", err
+	}
+	if ns, rest, ok := strings.Cut(w.Path, ":"); ok && WorkspaceID(ns, rest) == id {
+		return ns, nil
+	}
+	return "default", nil
+}
+
 // ListWorkspaces returns all registered workspaces.
-func (s *WorkspaceStore) ListWorkspaces() ([]models.Workspace, error) {
-	rows, err := s.db.Query(`
+func (s *WorkspaceStore) ListWorkspaces(ctx context.Context) ([]models.Workspace, error) {
+	rows, err := s.db.QueryContext(ctx, `
 		SELECT id, path, name, created_at, last_accessed_at
 		FROM workspaces ORDER BY last_accessed_at DESC
 	`)
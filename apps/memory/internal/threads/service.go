@@ -1,6 +1,7 @@
 package threads
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"log/slog"
@@ -9,14 +10,21 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
 	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/sessions"
 	"github.com/iammorganparry/clive/apps/memory/internal/store"
+	"github.com/iammorganparry/clive/apps/memory/internal/tokens"
 )
 
 const (
 	defaultTokenBudget = 4000
 	totalBudgetCap     = 6000
 	stalenessWarningDays = 7
+
+	// distillTimeout bounds summarizer calls while closing a thread; any
+	// section not merged by then is stored from its raw entries.
+	distillTimeout = 60 * time.Second
 )
 
 // Service handles feature thread business logic.
@@ -24,38 +32,74 @@ type Service struct {
 	threadStore    *store.ThreadStore
 	memoryStore    *store.MemoryStore
 	workspaceStore *store.WorkspaceStore
+	summarizer     *sessions.Summarizer
+	maxEntryTokens int
+	autoSummarize  bool
+	calibration    *store.CalibrationStore
+	tokenizer      tokens.Counter
 	logger         *slog.Logger
 }
 
+// NewService creates a thread service. Entries larger than maxEntryTokens
+// (or the thread's own budget, if smaller) are rejected, or condensed with
+// the summarizer when autoSummarize is set. A maxEntryTokens of 0 only
+// applies the thread budget.
 func NewService(
 	threadStore *store.ThreadStore,
 	memoryStore *store.MemoryStore,
 	workspaceStore *store.WorkspaceStore,
+	summarizer *sessions.Summarizer,
+	maxEntryTokens int,
+	autoSummarize bool,
 	logger *slog.Logger,
 ) *Service {
 	return &Service{
 		threadStore:    threadStore,
 		memoryStore:    memoryStore,
 		workspaceStore: workspaceStore,
+		summarizer:     summarizer,
+		maxEntryTokens: maxEntryTokens,
+		autoSummarize:  autoSummarize,
+		tokenizer:      tokens.Chars{},
 		logger:         logger,
 	}
 }
 
+// SetTokenizer replaces the len/4 heuristic used to price entries and
+// budget thread context.
+func (s *Service) SetTokenizer(c tokens.Counter) {
+	s.tokenizer = c
+}
+
+// SetCalibration applies per-source confidence adjustments to new entries
+// and distilled memories.
+func (s *Service) SetCalibration(cs *store.CalibrationStore) {
+	s.calibration = cs
+}
+
+// calibrated adjusts a confidence for the given memory source.
+func (s *Service) calibrated(ctx context.Context, source string, confidence float64) float64 {
+	if s.calibration == nil {
+		return confidence
+	}
+	return s.calibration.Adjust(ctx, source, confidence)
+}
+
 // Create creates a new feature thread.
-func (s *Service) Create(req *models.CreateThreadRequest) (*models.FeatureThread, error) {
-	workspaceID, err := s.workspaceStore.EnsureWorkspace(req.Namespace, req.Workspace)
+func (s *Service) Create(ctx context.Context, req *models.CreateThreadRequest) (*models.FeatureThread, error) {
+	workspaceID, err := s.workspaceStore.EnsureWorkspace(ctx, req.Namespace, req.Workspace)
 	if err != nil {
 		return nil, fmt.Errorf("ensure workspace: %w", err)
 	}
 
 	// Check for duplicate name
-	existing, err := s.threadStore.GetThreadByName(workspaceID, req.Name)
+	existing, err := s.threadStore.GetThreadByName(ctx, workspaceID, req.Name)
 	if err != nil {
 		return nil, fmt.Errorf("check existing thread: %w", err)
 	}
 	if existing != nil {
 		if existing.Status == models.ThreadStatusActive || existing.Status == models.ThreadStatusPaused {
-			return nil, fmt.Errorf("thread with name %q already exists (status: %s)", req.Name, existing.Status)
+			return nil, apperr.Conflict("thread_exists", "thread with name %q already exists (status: %s)", req.Name, existing.Status)
 		}
 	}
 
@@ -77,7 +121,7 @@ func (s *Service) Create(req *models.CreateThreadRequest) (*models.FeatureThread
 		Tags:        req.Tags,
 	}
 
-	if err := s.threadStore.CreateThread(thread); err != nil {
+	if err := s.threadStore.CreateThread(ctx, thread); err != nil {
 		return nil, fmt.Errorf("create thread: %w", err)
 	}
 
@@ -86,8 +130,8 @@ func (s *Service) Create(req *models.CreateThreadRequest) (*models.FeatureThread
 }
 
 // Get returns a thread with all its entries.
-func (s *Service) Get(id string) (*models.ThreadWithEntries, error) {
-	thread, err := s.threadStore.GetThread(id)
+func (s *Service) Get(ctx context.Context, id string) (*models.ThreadWithEntries, error) {
+	thread, err := s.threadStore.GetThread(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("get thread: %w", err)
 	}
@@ -95,7 +139,7 @@ func (s *Service) Get(id string) (*models.ThreadWithEntries, error) {
 		return nil, nil
 	}
 
-	entries, err := s.threadStore.GetEntries(id)
+	entries, err := s.threadStore.GetEntries(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("get entries: %w", err)
 	}
@@ -110,41 +154,100 @@ func (s *Service) Get(id string) (*models.ThreadWithEntries, error) {
 }
 
 // List returns threads filtered by workspace, status, and name.
-func (s *Service) List(req *models.ListThreadsRequest) ([]*models.FeatureThread, error) {
+func (s *Service) List(ctx context.Context, req *models.ListThreadsRequest) ([]*models.FeatureThread, error) {
 	workspaceID := ""
 	if req.Workspace != "" {
-		workspaceID = store.WorkspaceID(req.Namespace, req.Workspace)
+		id, err := s.workspaceStore.ResolveWorkspaceID(ctx, req.Namespace, req.Workspace)
+		if err != nil {
+			return nil, err
+		}
+		workspaceID = id
 	}
-	return s.threadStore.ListThreads(workspaceID, req.Status, req.Name)
+	return s.threadStore.ListThreads(ctx, workspaceID, req.Status, req.Name)
 }
 
 // Update applies partial updates to a thread.
-func (s *Service) Update(id string, req *models.UpdateThreadRequest) (*models.FeatureThread, error) {
-	return s.threadStore.UpdateThread(id, req)
+func (s *Service) Update(ctx context.Context, id string, req *models.UpdateThreadRequest) (*models.FeatureThread, error) {
+	return s.threadStore.UpdateThread(ctx, id, req)
 }
 
 // Delete removes a thread.
-func (s *Service) Delete(id string) error {
-	return s.threadStore.DeleteThread(id)
+func (s *Service) Delete(ctx context.Context, id string) error {
+	return s.threadStore.DeleteThread(ctx, id)
 }
 
+// MaxBatchEntries caps the entries accepted by one AppendEntries call.
+const MaxBatchEntries = 50
+
 // AppendEntry creates a memory and links it to the thread as an entry.
-func (s *Service) AppendEntry(threadID string, req *models.AppendEntryRequest) (*models.ThreadEntry, error) {
-	thread, err := s.threadStore.GetThread(threadID)
+func (s *Service) AppendEntry(ctx context.Context, threadID string, req *models.AppendEntryRequest) (*models.ThreadEntry, error) {
+	entries, err := s.appendEntries(ctx, threadID, []*models.AppendEntryRequest{req}, false)
+	if err != nil {
+		return nil, err
+	}
+	return entries[0], nil
+}
+
+// AppendEntries appends several entries in one transaction. Every entry is
+// resolved and sized before anything is written, so one bad entry rejects
+// the whole batch; errors name the offending entry's index.
+func (s *Service) AppendEntries(ctx context.Context, threadID string, reqs []*models.AppendEntryRequest) ([]*models.ThreadEntry, error) {
+	if len(reqs) == 0 {
+		return nil, apperr.ValidationFailed("empty_batch", "entries must not be empty")
+	}
+	if len(reqs) > MaxBatchEntries {
+		return nil, apperr.ValidationFailed("batch_too_large", "at most %d entries per batch, got %d", MaxBatchEntries, len(reqs))
+	}
+	return s.appendEntries(ctx, threadID, reqs, true)
+}
+
+func (s *Service) appendEntries(ctx context.Context, threadID string, reqs []*models.AppendEntryRequest, indexed bool) ([]*models.ThreadEntry, error) {
+	thread, err := s.threadStore.GetThread(ctx, threadID)
 	if err != nil {
 		return nil, fmt.Errorf("get thread: %w", err)
 	}
 	if thread == nil {
-		return nil, fmt.Errorf("thread not found: %s", threadID)
+		return nil, apperr.NotFound("thread_not_found", "thread not found: %s", threadID)
 	}
 	if thread.Status == models.ThreadStatusClosed {
-		return nil, fmt.Errorf("cannot append to closed thread")
+		return nil, apperr.Conflict("thread_closed", "cannot append to closed thread")
+	}
+
+	pending := make([]store.PendingEntry, 0, len(reqs))
+	for i, req := range reqs {
+		p, err := s.prepareEntry(ctx, thread, req)
+		if err != nil {
+			if indexed {
+				return nil, atEntry(i, err)
+			}
+			return nil, err
+		}
+		pending = append(pending, p)
+	}
+
+	if err := s.threadStore.AppendEntries(ctx, threadID, pending); err != nil {
+		return nil, fmt.Errorf("append entries: %w", err)
 	}
 
+	entries := make([]*models.ThreadEntry, len(pending))
+	for i, p := range pending {
+		entries[i] = p.Entry
+	}
+	return entries, nil
+}
+
+// prepareEntry builds the memory and entry for req without writing them.
+func (s *Service) prepareEntry(ctx context.Context, thread *models.FeatureThread, req *models.AppendEntryRequest) (store.PendingEntry, error) {
+	threadID := thread.ID
+
 	// Resolve workspace
 	workspaceID := thread.WorkspaceID
 	if req.Workspace != "" {
-		workspaceID = store.WorkspaceID(req.Namespace, req.Workspace)
+		id, err := s.workspaceStore.ResolveWorkspaceID(ctx, req.Namespace, req.Workspace)
+		if err != nil {
+			return store.PendingEntry{}, err
+		}
+		workspaceID = id
 	}
 
 	// Default memory type
@@ -165,12 +268,18 @@ func (s *Service) AppendEntry(threadID string, req *models.AppendEntryRequest) (
 		section = models.ThreadSectionContext
 	}
 
+	content, summarized, err := s.fitEntry(ctx, thread, req.Content)
+	if err != nil {
+		return store.PendingEntry{}, err
+	}
+	tokens := s.estimateTokens(content)
+
 	// Create the memory
 	now := time.Now().Unix()
-	contentHash := fmt.Sprintf("%x", sha256.Sum256([]byte(req.Content)))
+	contentHash := fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
 
 	// Merge thread tags with entry tags
-	tags := req.Tags
+	tags := append([]string{}, req.Tags...)
 	tags = append(tags, "thread:"+thread.Name)
 
 	// Build initial stability from memory type
@@ -186,10 +295,10 @@ func (s *Service) AppendEntry(threadID string, req *models.AppendEntryRequest) (
 	mem := &models.Memory{
 		ID:          memoryID,
 		WorkspaceID: workspaceID,
-		Content:     req.Content,
+		Content:     content,
 		MemoryType:  memType,
 		Tier:        models.TierShort,
-		Confidence:  confidence,
+		Confidence:  s.calibrated(ctx, "thread", confidence),
 		Tags:        tags,
 		Source:      "thread",
 		ContentHash: contentHash,
@@ -200,49 +309,192 @@ func (s *Service) AppendEntry(threadID string, req *models.AppendEntryRequest) (
 		ThreadID:    &threadID,
 	}
 
-	if err := s.memoryStore.Insert(mem); err != nil {
-		return nil, fmt.Errorf("insert memory: %w", err)
+	entry := &models.ThreadEntry{
+		ID:         uuid.New().String(),
+		ThreadID:   threadID,
+		MemoryID:   memoryID,
+		Section:    section,
+		CreatedAt:  now,
+		Summarized: summarized,
+		Content:    content,
+		MemoryType: memType,
 	}
 
-	// Get next sequence
-	seq, err := s.threadStore.NextSequence(threadID)
+	return store.PendingEntry{Memory: mem, Entry: entry, Tokens: tokens}, nil
+}
+
+// atEntry prefixes a batch error with the index of the entry that caused it,
+// keeping its kind and code.
+func atEntry(i int, err error) error {
+	e := apperr.From(err)
+	return &apperr.Error{Kind: e.Kind, Code: e.Code, Message: fmt.Sprintf("entry %d: %s", i, e.Message), Err: e.Err}
+}
+
+// ReclassifyEntries moves entries between sections of an open thread.
+func (s *Service) ReclassifyEntries(ctx context.Context, threadID string, req *models.ReclassifyEntriesRequest) (*models.ReclassifyEntriesResponse, error) {
+	if len(req.EntryIDs) == 0 {
+		return nil, apperr.ValidationFailed("empty_batch", "entryIds must not be empty")
+	}
+	if !req.Section.IsValid() {
+		return nil, apperr.ValidationFailed("invalid_section", "invalid section: %s", req.Section)
+	}
+
+	thread, err := s.threadStore.GetThread(ctx, threadID)
 	if err != nil {
-		return nil, fmt.Errorf("get next sequence: %w", err)
+		return nil, fmt.Errorf("get thread: %w", err)
+	}
+	if thread == nil {
+		return nil, apperr.NotFound("thread_not_found", "thread not found: %s", threadID)
+	}
+	if thread.Status == models.ThreadStatusClosed {
+		return nil, apperr.Conflict("thread_closed", "cannot reclassify entries of a closed thread")
 	}
 
-	entry := &models.ThreadEntry{
-		ID:        uuid.New().String(),
-		ThreadID:  threadID,
-		MemoryID:  memoryID,
-		Sequence:  seq,
-		Section:   section,
-		CreatedAt: now,
-		Content:   req.Content,
-		MemoryType: memType,
+	n, err := s.threadStore.ReclassifyEntries(ctx, threadID, req.EntryIDs, req.Section)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ReclassifyEntriesResponse{Updated: n, Section: req.Section}, nil
+}
+
+// UpdateEntry edits an entry of an open thread. New content is held to the
+// entry size limit and rewrites the backing memory; what the entry held
+// before goes to the thread's edit log.
+func (s *Service) UpdateEntry(ctx context.Context, threadID, entryID string, req *models.UpdateEntryRequest) (*models.ThreadEntry, error) {
+	if req.Content == nil && req.Section == nil {
+		return nil, apperr.ValidationFailed("empty_update", "content or section is required")
+	}
+	if req.Content != nil && *req.Content == "" {
+		return nil, apperr.ValidationFailed("empty_content", "content must not be empty")
+	}
+	if req.Section != nil && !req.Section.IsValid() {
+		return nil, apperr.ValidationFailed("invalid_section", "invalid section: %s", *req.Section)
+	}
+
+	thread, previous, err := s.editableEntry(ctx, threadID, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := *previous
+	tokens := 0
+	var contentHash string
+	if req.Content != nil && *req.Content != previous.Content {
+		content, summarized, err := s.fitEntry(ctx, thread, *req.Content)
+		if err != nil {
+			return nil, err
+		}
+		entry.Content = content
+		entry.Summarized = summarized
+		tokens = s.estimateTokens(content)
+		contentHash = fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+	}
+	if req.Section != nil {
+		entry.Section = *req.Section
 	}
 
-	if err := s.threadStore.AppendEntry(entry); err != nil {
-		return nil, fmt.Errorf("append entry: %w", err)
+	if err := s.threadStore.UpdateEntry(ctx, &entry, previous, contentHash, tokens); err != nil {
+		return nil, err
 	}
+	return &entry, nil
+}
 
-	return entry, nil
+// DeleteEntry removes an entry of an open thread along with its memory.
+// Later entries move up to keep the sequence contiguous, and the entry is
+// kept in the thread's edit log.
+func (s *Service) DeleteEntry(ctx context.Context, threadID, entryID string) error {
+	_, entry, err := s.editableEntry(ctx, threadID, entryID)
+	if err != nil {
+		return err
+	}
+	return s.threadStore.DeleteEntry(ctx, entry)
+}
+
+// EntryEdits returns the thread's log of entry updates and deletions.
+func (s *Service) EntryEdits(ctx context.Context, threadID string) ([]models.ThreadEntryEdit, error) {
+	thread, err := s.threadStore.GetThread(ctx, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("get thread: %w", err)
+	}
+	if thread == nil {
+		return nil, apperr.NotFound("thread_not_found", "thread not found: %s", threadID)
+	}
+	return s.threadStore.GetEntryEdits(ctx, threadID)
+}
+
+// editableEntry loads an entry for editing; entries of closed threads are
+// final.
+func (s *Service) editableEntry(ctx context.Context, threadID, entryID string) (*models.FeatureThread, *models.ThreadEntry, error) {
+	thread, err := s.threadStore.GetThread(ctx, threadID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get thread: %w", err)
+	}
+	if thread == nil {
+		return nil, nil, apperr.NotFound("thread_not_found", "thread not found: %s", threadID)
+	}
+	if thread.Status == models.ThreadStatusClosed {
+		return nil, nil, apperr.Conflict("thread_closed", "cannot edit entries of a closed thread")
+	}
+	entry, err := s.threadStore.GetEntry(ctx, threadID, entryID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if entry == nil {
+		return nil, nil, apperr.NotFound("entry_not_found", "entry %s not found in thread %s", entryID, threadID)
+	}
+	return thread, entry, nil
+}
+
+// entryLimit returns the maximum token size of a single entry in thread.
+func (s *Service) entryLimit(thread *models.FeatureThread) int {
+	limit := thread.TokenBudget
+	if s.maxEntryTokens > 0 && (limit <= 0 || s.maxEntryTokens < limit) {
+		limit = s.maxEntryTokens
+	}
+	return limit
+}
+
+// fitEntry enforces the entry size limit, condensing oversized content with
+// the summarizer when auto-summarize is enabled. It reports whether the
+// content was condensed.
+func (s *Service) fitEntry(ctx context.Context, thread *models.FeatureThread, content string) (string, bool, error) {
+	limit := s.entryLimit(thread)
+	tokens := s.estimateTokens(content)
+	if limit <= 0 || tokens <= limit {
+		return content, false, nil
+	}
+
+	if s.autoSummarize && s.summarizer != nil && s.summarizer.IsEnabled() {
+		condensed, err := s.summarizer.Condense(ctx, content, limit)
+		if err != nil {
+			s.logger.Warn("failed to condense thread entry", "thread", thread.ID, "error", err)
+		} else if s.estimateTokens(condensed) <= limit {
+			s.logger.Info("condensed thread entry",
+				"thread", thread.ID, "from_tokens", tokens, "to_tokens", s.estimateTokens(condensed))
+			return condensed, true, nil
+		}
+	}
+
+	return "", false, apperr.ValidationFailed("entry_too_large",
+		"entry is ~%d tokens; the limit for this thread is %d", tokens, limit)
 }
 
 // Close closes a thread. If distill is true, it creates permanent APP_KNOWLEDGE
-// memories from decisions, findings, and architecture entries.
-func (s *Service) Close(id string, distill bool) (*models.CloseThreadResponse, error) {
-	thread, err := s.threadStore.GetThread(id)
+// memories from decisions, findings, and architecture entries. Summarizing
+// stops when ctx is done, but the thread is still closed.
+func (s *Service) Close(ctx context.Context, id string, distill bool) (*models.CloseThreadResponse, error) {
+	thread, err := s.threadStore.GetThread(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("get thread: %w", err)
 	}
 	if thread == nil {
-		return nil, fmt.Errorf("thread not found: %s", id)
+		return nil, apperr.NotFound("thread_not_found", "thread not found: %s", id)
 	}
 
 	var distilledIDs []string
 
 	if distill {
-		distilledIDs, err = s.distillThread(thread)
+		distilledIDs, err = s.distillThread(ctx, thread)
 		if err != nil {
 			s.logger.Error("distillation failed", "thread", id, "error", err)
 			// Don't fail the close operation
@@ -251,7 +503,7 @@ func (s *Service) Close(id string, distill bool) (*models.CloseThreadResponse, e
 
 	// Mark as closed
 	closedStatus := models.ThreadStatusClosed
-	_, err = s.threadStore.UpdateThread(id, &models.UpdateThreadRequest{
+	_, err = s.threadStore.UpdateThread(ctx, id, &models.UpdateThreadRequest{
 		Status: &closedStatus,
 	})
 	if err != nil {
@@ -266,7 +518,12 @@ func (s *Service) Close(id string, distill bool) (*models.CloseThreadResponse, e
 }
 
 // distillThread creates permanent memories from valuable thread entries.
-func (s *Service) distillThread(thread *models.FeatureThread) ([]string, error) {
+// Summarizer calls share one distillTimeout budget within ctx; once it runs
+// out, the remaining sections keep their raw entries.
+func (s *Service) distillThread(ctx context.Context, thread *models.FeatureThread) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, distillTimeout)
+	defer cancel()
+
 	// Sections to distill (context and todo are transient)
 	distillSections := []models.ThreadSection{
 		models.ThreadSectionDecisions,
@@ -277,7 +534,7 @@ func (s *Service) distillThread(thread *models.FeatureThread) ([]string, error)
 	var distilledIDs []string
 
 	for _, section := range distillSections {
-		entries, err := s.threadStore.GetEntriesBySection(thread.ID, section)
+		entries, err := s.threadStore.GetEntriesBySection(ctx, thread.ID, section)
 		if err != nil {
 			continue
 		}
@@ -285,12 +542,22 @@ func (s *Service) distillThread(thread *models.FeatureThread) ([]string, error)
 			continue
 		}
 
-		// Combine entries into a single APP_KNOWLEDGE memory per section
+		// Combine entries into a single APP_KNOWLEDGE memory per section,
+		// merged by the summarizer when available
 		var parts []string
 		for _, e := range entries {
 			parts = append(parts, e.Content)
 		}
-		content := fmt.Sprintf("[Thread: %s] [%s] %s", thread.Name, section, strings.Join(parts, " | "))
+		body := strings.Join(parts, " | ")
+		if s.summarizer != nil && s.summarizer.IsEnabled() {
+			distilled, err := s.summarizer.Distill(ctx, string(section), parts)
+			if err != nil {
+				s.logger.Warn("summarizer distillation failed, keeping raw entries", "section", section, "error", err)
+			} else {
+				body = distilled
+			}
+		}
+		content := fmt.Sprintf("[Thread: %s] [%s] %s", thread.Name, section, body)
 
 		now := time.Now().Unix()
 		contentHash := fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
@@ -302,7 +569,7 @@ func (s *Service) distillThread(thread *models.FeatureThread) ([]string, error)
 			Content:     content,
 			MemoryType:  models.MemoryTypeAppKnowledge,
 			Tier:        models.TierLong,
-			Confidence:  0.9,
+			Confidence:  s.calibrated(ctx, "thread-distill", 0.9),
 			Tags:        append(thread.Tags, "thread:"+thread.Name, "distilled", string(section)),
 			Source:      "thread-distill",
 			ContentHash: contentHash,
@@ -311,7 +578,7 @@ func (s *Service) distillThread(thread *models.FeatureThread) ([]string, error)
 			Stability:   30.0,
 		}
 
-		if err := s.memoryStore.Insert(mem); err != nil {
+		if err := s.memoryStore.Insert(ctx, mem); err != nil {
 			s.logger.Error("failed to distill entry", "section", section, "error", err)
 			continue
 		}
@@ -322,16 +589,16 @@ func (s *Service) distillThread(thread *models.FeatureThread) ([]string, error)
 }
 
 // GetContext generates pre-formatted XML context for a single thread.
-func (s *Service) GetContext(id string) (string, error) {
-	thread, err := s.threadStore.GetThread(id)
+func (s *Service) GetContext(ctx context.Context, id string) (string, error) {
+	thread, err := s.threadStore.GetThread(ctx, id)
 	if err != nil {
 		return "", fmt.Errorf("get thread: %w", err)
 	}
 	if thread == nil {
-		return "", fmt.Errorf("thread not found: %s", id)
+		return "", apperr.NotFound("thread_not_found", "thread not found: %s", id)
 	}
 
-	entries, err := s.threadStore.GetEntries(id)
+	entries, err := s.threadStore.GetEntries(ctx, id)
 	if err != nil {
 		return "", fmt.Errorf("get entries: %w", err)
 	}
@@ -341,13 +608,17 @@ func (s *Service) GetContext(id string) (string, error) {
 
 // GetActiveContext generates pre-formatted XML context for all active threads in a workspace.
 // If branch is provided, the matching thread is rendered first with a larger budget share.
-func (s *Service) GetActiveContext(namespace, workspace, branch string) (string, error) {
+func (s *Service) GetActiveContext(ctx context.Context, namespace, workspace, branch string) (string, error) {
 	workspaceID := ""
 	if workspace != "" {
-		workspaceID = store.WorkspaceID(namespace, workspace)
+		id, err := s.workspaceStore.ResolveWorkspaceID(ctx, namespace, workspace)
+		if err != nil {
+			return "", err
+		}
+		workspaceID = id
 	}
 
-	threads, err := s.threadStore.ListThreads(workspaceID, models.ThreadStatusActive, "")
+	threads, err := s.threadStore.ListThreads(ctx, workspaceID, models.ThreadStatusActive, "")
 	if err != nil {
 		return "", fmt.Errorf("list active threads: %w", err)
 	}
@@ -394,7 +665,7 @@ code or re-make decisions already documented here.
 
 	// Render branch-matching thread first (highest priority)
 	if branchThread != nil {
-		entries, err := s.threadStore.GetEntries(branchThread.ID)
+		entries, err := s.threadStore.GetEntries(ctx, branchThread.ID)
 		if err != nil {
 			s.logger.Error("failed to get entries for branch thread", "thread", branchThread.ID, "error", err)
 		} else {
@@ -405,7 +676,7 @@ code or re-make decisions already documented here.
 
 	// Render other active threads
 	for _, thread := range otherThreads {
-		entries, err := s.threadStore.GetEntries(thread.ID)
+		entries, err := s.threadStore.GetEntries(ctx, thread.ID)
 		if err != nil {
 			s.logger.Error("failed to get entries for thread", "thread", thread.ID, "error", err)
 			continue
@@ -438,7 +709,7 @@ func (s *Service) formatThreadContext(thread *models.FeatureThread, entries []mo
 	// 1. Always include summary (highest priority)
 	if thread.Summary != "" {
 		summaryXML := fmt.Sprintf("\n  <thread-summary>%s</thread-summary>", thread.Summary)
-		usedTokens += estimateTokens(summaryXML)
+		usedTokens += s.estimateTokens(summaryXML)
 		sb.WriteString(summaryXML)
 	}
 
@@ -468,7 +739,7 @@ func (s *Service) formatThreadContext(thread *models.FeatureThread, entries []mo
 			continue
 		}
 
-		sectionTokens := estimateTokens(sectionXML)
+		sectionTokens := s.estimateTokens(sectionXML)
 		if usedTokens+sectionTokens > budget {
 			// Include truncation marker
 			remaining := 0
@@ -505,7 +776,7 @@ func (s *Service) formatSection(section models.ThreadSection, entries []models.T
 	for i := len(entries) - 1; i >= 0; i-- {
 		e := entries[i]
 		entryXML := fmt.Sprintf("\n    <entry seq=\"%d\">%s</entry>", e.Sequence, e.Content)
-		entryTokens := estimateTokens(entryXML)
+		entryTokens := s.estimateTokens(entryXML)
 
 		if usedTokens+entryTokens > remainingBudget {
 			remaining := i + 1
@@ -526,7 +797,7 @@ func (s *Service) formatSection(section models.ThreadSection, entries []models.T
 	return sb.String()
 }
 
-// estimateTokens uses the conservative heuristic: len(text) / 4.
-func estimateTokens(text string) int {
-	return len(text) / 4
+// estimateTokens prices text with the configured tokenizer.
+func (s *Service) estimateTokens(text string) int {
+	return s.tokenizer.Count(text)
 }
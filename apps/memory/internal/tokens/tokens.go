@@ -0,0 +1,139 @@
+// Package tokens estimates how many model tokens a text will cost, for
+// budgeting thread context and entries without shipping a BPE vocabulary.
+package tokens
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"unicode"
+)
+
+// Counter estimates the token count of a text.
+type Counter interface {
+	Count(text string) int
+}
+
+// Chars is the legacy len(text)/4 heuristic. It under-counts CJK text and
+// symbol-heavy code, where a token covers far fewer than four bytes.
+type Chars struct{}
+
+func (Chars) Count(text string) int {
+	return len(text) / 4
+}
+
+// Profile approximates a model's tokenizer by how many characters of each
+// kind of run one token covers.
+type Profile struct {
+	Letters float64 // ASCII letters per token within a word
+	Digits  float64 // digits per token within a number
+	Other   float64 // non-ASCII letters (accented, Cyrillic, ...) per token
+	CJK     float64 // tokens per Han, kana or Hangul character
+}
+
+// Profiles are the approximations selectable by name, besides "chars".
+var Profiles = map[string]Profile{
+	// cl100k matches OpenAI's cl100k_base, the closest public vocabulary
+	// to the models Clive budgets for.
+	"cl100k": {Letters: 4, Digits: 3, Other: 2, CJK: 1.2},
+	// claude splits English slightly finer and CJK coarser than cl100k.
+	"claude": {Letters: 3.5, Digits: 3, Other: 2, CJK: 1.5},
+}
+
+// Default is the tokenizer used when none is configured.
+const Default = "cl100k"
+
+// New returns the counter for a tokenizer name: "chars" or a Profiles key.
+func New(name string) (Counter, error) {
+	if name == "chars" {
+		return Chars{}, nil
+	}
+	if p, ok := Profiles[name]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("unknown tokenizer %q (want %s)", name, Names())
+}
+
+// Names lists the accepted tokenizer names.
+func Names() []string {
+	names := []string{"chars"}
+	for name := range Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type runKind int
+
+const (
+	runNone runKind = iota
+	runLetters
+	runDigits
+	runOther
+	runSpace
+)
+
+// Count walks the text in runs: each word or number costs at least one
+// token, symbols cost one each, CJK characters are priced individually and
+// whitespace only counts when it breaks a line or indents.
+func (p Profile) Count(text string) int {
+	var total float64
+	kind, n, newline := runNone, 0, false
+
+	flush := func() {
+		switch kind {
+		case runLetters:
+			total += perRun(n, p.Letters)
+		case runDigits:
+			total += math.Ceil(float64(n) / p.Digits) // numbers split into fixed-size groups
+		case runOther:
+			total += perRun(n, p.Other)
+		case runSpace:
+			if newline || n > 1 {
+				total++
+			}
+		}
+		kind, n, newline = runNone, 0, false
+	}
+	extend := func(k runKind) {
+		if kind != k {
+			flush()
+			kind = k
+		}
+		n++
+	}
+
+	for _, r := range text {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || r == '_'):
+			extend(runLetters)
+		case unicode.IsDigit(r):
+			extend(runDigits)
+		case isCJK(r):
+			flush()
+			total += p.CJK
+		case unicode.IsLetter(r) || unicode.IsMark(r):
+			extend(runOther)
+		case unicode.IsSpace(r):
+			extend(runSpace)
+			if r == '\n' {
+				newline = true
+			}
+		default:
+			flush()
+			total++
+		}
+	}
+	flush()
+	return int(math.Ceil(total))
+}
+
+// perRun prices a run of n characters, at least one token however short.
+func perRun(n int, perToken float64) float64 {
+	return math.Max(1, math.Round(float64(n)/perToken))
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
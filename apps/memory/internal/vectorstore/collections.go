@@ -1,41 +1,201 @@
 package vectorstore
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 )
 
-const collectionPrefix = "clive_memory_"
+// LegacyDimension is the nomic-embed-text vector size. Collections of this
+// size keep the unqualified names they had before embedding backends were
+// pluggable; other sizes get their own collections, so switching backends
+// starts fresh ones (filled by reconcile) instead of corrupting old ones.
+const LegacyDimension = 768
 
-// CollectionManager maps workspace IDs to Qdrant collections and ensures
-// they are created on first use.
+// collectionPrefix names a dimension's collections. The qualified prefix
+// doesn't start with the legacy one, so neither parses the other's names.
+func collectionPrefix(dimension int) string {
+	if dimension == LegacyDimension {
+		return "clive_memory_"
+	}
+	return fmt.Sprintf("clive_mem%d_", dimension)
+}
+
+// CollectionManager routes workspaces' points to Qdrant collections through
+// a ShardStrategy and ensures collections are created on first use.
 type CollectionManager struct {
-	client  *QdrantClient
-	known   map[string]bool
-	mu      sync.RWMutex
+	client     *QdrantClient
+	strategy   ShardStrategy
+	namespaces func(ctx context.Context, workspaceID string) (string, error)
+	known      map[string]bool
+	// Shard counts of split workspaces, read from Qdrant's collection
+	// names on first use by a Splitter; missing means 1.
+	shards       map[string]int
+	shardsLoaded bool
+	nsCache      map[string]string
+	mu           sync.RWMutex
 }
 
 func NewCollectionManager(client *QdrantClient) *CollectionManager {
 	return &CollectionManager{
-		client: client,
-		known:  make(map[string]bool),
+		client:   client,
+		strategy: WorkspaceSharding{},
+		known:    make(map[string]bool),
+		shards:   make(map[string]int),
+		nsCache:  make(map[string]string),
 	}
 }
 
-// CollectionName returns the Qdrant collection name for a workspace ID.
-func CollectionName(workspaceID string) string {
-	return collectionPrefix + workspaceID
+// SetStrategy replaces the default WorkspaceSharding. Points already
+// stored stay where they are until a rebalance moves them.
+func (m *CollectionManager) SetStrategy(s ShardStrategy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.strategy = s
+}
+
+// Strategy returns the strategy routing points.
+func (m *CollectionManager) Strategy() ShardStrategy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.strategy
+}
+
+// SetNamespaceResolver sets how a workspace ID maps to its namespace, which
+// NamespaceSharding routes on. Without one every workspace is in "default".
+func (m *CollectionManager) SetNamespaceResolver(resolve func(ctx context.Context, workspaceID string) (string, error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.namespaces = resolve
 }
 
-// EnsureForWorkspace creates the Qdrant collection for a workspace if it
-// doesn't already exist. Results are cached in-memory.
-func (m *CollectionManager) EnsureForWorkspace(workspaceID string) (string, error) {
-	name := CollectionName(workspaceID)
+// CollectionName returns the Qdrant collection name for a workspace's
+// vectors of the given dimension.
+func CollectionName(workspaceID string, dimension int) string {
+	return collectionPrefix(dimension) + workspaceID
+}
+
+// Route describes a workspace to the strategy.
+func (m *CollectionManager) Route(ctx context.Context, workspaceID string) (Route, error) {
+	r := Route{WorkspaceID: workspaceID, Dimension: m.client.Dimension(), Shards: 1}
 
+	m.mu.RLock()
+	strategy, resolve := m.strategy, m.namespaces
+	ns, haveNS := m.nsCache[workspaceID]
+	m.mu.RUnlock()
+
+	if resolve != nil && !haveNS {
+		var err error
+		if ns, err = resolve(ctx, workspaceID); err != nil {
+			return Route{}, fmt.Errorf("resolve namespace of workspace %s: %w", workspaceID, err)
+		}
+		m.mu.Lock()
+		m.nsCache[workspaceID] = ns
+		m.mu.Unlock()
+	}
+	r.Namespace = ns
+
+	if _, ok := strategy.(Splitter); ok {
+		if err := m.loadShards(ctx); err != nil {
+			return Route{}, err
+		}
+		m.mu.RLock()
+		if n := m.shards[workspaceID]; n > 1 {
+			r.Shards = n
+		}
+		m.mu.RUnlock()
+	}
+	return r, nil
+}
+
+// loadShards reads split workspaces' shard counts from the collections on
+// the server, once.
+func (m *CollectionManager) loadShards(ctx context.Context) error {
+	m.mu.RLock()
+	loaded := m.shardsLoaded
+	m.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	names, err := m.client.ListCollections(ctx)
+	if err != nil {
+		return fmt.Errorf("load shard counts: %w", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, name := range names {
+		base, shard := splitShard(name)
+		if shard == 0 {
+			continue
+		}
+		if id, ok := WorkspaceForCollection(base, m.client.Dimension()); ok && shard+1 > m.shards[id] {
+			m.shards[id] = shard + 1
+		}
+	}
+	m.shardsLoaded = true
+	return nil
+}
+
+// SetShards records that a workspace is now split across n collections,
+// after a rebalance has moved its points.
+func (m *CollectionManager) SetShards(workspaceID string, n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if n > 1 {
+		m.shards[workspaceID] = n
+	} else {
+		delete(m.shards, workspaceID)
+	}
+}
+
+// Collections lists every collection the strategy may keep a workspace's
+// points in, whether or not it exists yet.
+func (m *CollectionManager) Collections(ctx context.Context, workspaceID string) ([]string, error) {
+	r, err := m.Route(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return m.Strategy().Collections(r), nil
+}
+
+// EnsureForWorkspace creates every collection for a workspace that doesn't
+// already exist and returns the first. Results are cached in-memory.
+func (m *CollectionManager) EnsureForWorkspace(ctx context.Context, workspaceID string) (string, error) {
+	names, err := m.Collections(ctx, workspaceID)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range names {
+		if err := m.ensure(ctx, name); err != nil {
+			return "", err
+		}
+	}
+	return names[0], nil
+}
+
+// EnsureForPoint creates the collection a point in the workspace is routed
+// to if it doesn't already exist, and returns its name.
+func (m *CollectionManager) EnsureForPoint(ctx context.Context, workspaceID, pointID string) (string, error) {
+	r, err := m.Route(ctx, workspaceID)
+	if err != nil {
+		return "", err
+	}
+	name := m.Strategy().CollectionFor(r, pointID)
+	if err := m.ensure(ctx, name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func (m *CollectionManager) ensure(ctx context.Context, name string) error {
 	m.mu.RLock()
 	if m.known[name] {
 		m.mu.RUnlock()
-		return name, nil
+		return nil
 	}
 	m.mu.RUnlock()
 
@@ -44,13 +204,138 @@ func (m *CollectionManager) EnsureForWorkspace(workspaceID string) (string, erro
 
 	// Double-check after acquiring write lock
 	if m.known[name] {
-		return name, nil
+		return nil
 	}
 
-	if err := m.client.EnsureCollection(name); err != nil {
-		return "", fmt.Errorf("ensure collection %s: %w", name, err)
+	if err := m.client.EnsureCollection(ctx, name); err != nil {
+		return fmt.Errorf("ensure collection %s: %w", name, err)
 	}
 
 	m.known[name] = true
-	return name, nil
+	return nil
+}
+
+// DropCollection deletes a collection and forgets it was ensured, so a
+// later write recreates it.
+func (m *CollectionManager) DropCollection(ctx context.Context, name string) error {
+	if err := m.client.DeleteCollection(ctx, name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.known, name)
+	return nil
+}
+
+// SearchTarget is one collection to search for a set of workspaces.
+type SearchTarget struct {
+	Collection string
+	// WorkspaceIDs to filter on, set when the collection is shared
+	WorkspaceIDs []string
+}
+
+// SearchTargets returns the existing collections holding the workspaces'
+// points, each listed once.
+func (m *CollectionManager) SearchTargets(ctx context.Context, workspaceIDs []string) ([]SearchTarget, error) {
+	strategy := m.Strategy()
+	byCollection := map[string][]string{}
+	var order []string
+	for _, wsID := range workspaceIDs {
+		r, err := m.Route(ctx, wsID)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range strategy.Collections(r) {
+			if _, seen := byCollection[name]; !seen {
+				order = append(order, name)
+			}
+			byCollection[name] = append(byCollection[name], wsID)
+		}
+	}
+
+	targets := make([]SearchTarget, 0, len(order))
+	for _, name := range order {
+		m.mu.RLock()
+		exists := m.known[name]
+		m.mu.RUnlock()
+		if !exists {
+			var err error
+			if exists, err = m.client.CollectionExists(ctx, name); err != nil || !exists {
+				continue
+			}
+		}
+		t := SearchTarget{Collection: name}
+		if strategy.Shared() {
+			t.WorkspaceIDs = byCollection[name]
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// DeletePoints removes points from every collection the current strategy
+// may keep the workspace's points in, including shards a size rebalance
+// has yet to fill. Collections from a previous strategy are not covered:
+// points there are moved by rebalance or, once their memory is gone,
+// removed by reconcile.
+func (m *CollectionManager) DeletePoints(ctx context.Context, workspaceID string, ids []string) error {
+	names, err := m.Collections(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, name := range names {
+		if err := m.client.DeletePoints(ctx, name, ids); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Vector returns a point's vector from whichever of the workspace's
+// collections holds it, or nil if none does.
+func (m *CollectionManager) Vector(ctx context.Context, workspaceID, pointID string) ([]float32, error) {
+	names, err := m.Collections(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		exists, err := m.client.CollectionExists(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		points, err := m.client.GetPoints(ctx, name, []string{pointID})
+		if err != nil {
+			return nil, err
+		}
+		if len(points) > 0 && len(points[0].Vector) > 0 {
+			return points[0].Vector, nil
+		}
+	}
+	return nil, nil
+}
+
+// WorkspaceForCollection reverses CollectionName and ShardCollectionName,
+// reporting false for collections this server did not create or that hold
+// another dimension.
+func WorkspaceForCollection(name string, dimension int) (string, bool) {
+	base, _ := splitShard(name)
+	id, ok := strings.CutPrefix(base, collectionPrefix(dimension))
+	return id, ok && id != ""
+}
+
+// OwnedCollections filters names to the workspace, shard and namespace
+// collections of the given dimension, sorted.
+func OwnedCollections(names []string, dimension int) []string {
+	var owned []string
+	for _, name := range names {
+		if _, ok := WorkspaceForCollection(name, dimension); ok || IsNamespaceCollection(name, dimension) {
+			owned = append(owned, name)
+		}
+	}
+	sort.Strings(owned)
+	return owned
 }
@@ -2,18 +2,26 @@ package vectorstore
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/metrics"
 )
 
+var upserts = metrics.NewCounter("clive_qdrant_upserts_total",
+	"Qdrant upsert requests by outcome, success or error.", "outcome")
+
 // QdrantClient interfaces with the Qdrant REST API for vector operations.
 type QdrantClient struct {
 	baseURL    string
 	httpClient *http.Client
 	dimension  int
+	pending    sync.WaitGroup // upserts still in flight
 }
 
 func NewQdrantClient(baseURL string, dimension int) *QdrantClient {
@@ -53,16 +61,42 @@ func (c *QdrantClient) HealthCheck() error {
 	return nil
 }
 
-// EnsureCollection creates a collection if it doesn't exist.
-func (c *QdrantClient) EnsureCollection(name string) error {
+// Dimension returns the vector size collections are created with.
+func (c *QdrantClient) Dimension() int {
+	return c.dimension
+}
+
+// EnsureCollection creates a collection if it doesn't exist. An existing
+// collection whose vectors are another size is an error, since every
+// upsert and search against it would fail.
+func (c *QdrantClient) EnsureCollection(ctx context.Context, name string) error {
 	// Check if collection exists
-	resp, err := c.httpClient.Get(c.baseURL + "/collections/" + name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/collections/"+name, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("check collection: %w", err)
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusOK {
+		var info struct {
+			Result struct {
+				Config struct {
+					Params struct {
+						Vectors struct {
+							Size int `json:"size"`
+						} `json:"vectors"`
+					} `json:"params"`
+				} `json:"config"`
+			} `json:"result"`
+		}
+		json.NewDecoder(resp.Body).Decode(&info)
+		if size := info.Result.Config.Params.Vectors.Size; size != 0 && size != c.dimension {
+			return fmt.Errorf("collection %s holds %d-dimension vectors, embedder produces %d", name, size, c.dimension)
+		}
 		return nil // Already exists
 	}
 
@@ -73,27 +107,54 @@ func (c *QdrantClient) EnsureCollection(name string) error {
 			"distance": "Cosine",
 		},
 	}
-	return c.put("/collections/"+name, body)
+	return c.put(ctx, "/collections/"+name, body)
 }
 
 // Upsert inserts or updates a vector point in a collection.
-func (c *QdrantClient) Upsert(collection string, points []Point) error {
+func (c *QdrantClient) Upsert(ctx context.Context, collection string, points []Point) error {
+	c.pending.Add(1)
+	defer c.pending.Done()
+
 	body := map[string]any{
 		"points": points,
 	}
-	return c.put("/collections/"+collection+"/points", body)
+	if err := c.put(ctx, "/collections/"+collection+"/points", body); err != nil {
+		upserts.Inc("error")
+		return err
+	}
+	upserts.Inc("success")
+	return nil
+}
+
+// Flush waits for in-flight upserts to complete or ctx to expire.
+func (c *QdrantClient) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		c.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("flush qdrant upserts: %w", ctx.Err())
+	}
 }
 
-// Search finds the nearest vectors in a collection.
-func (c *QdrantClient) Search(collection string, vector []float32, limit int, minScore float64) ([]SearchResult, error) {
+// Search finds the nearest vectors in a collection. Given workspace IDs,
+// only points whose workspace_id payload is one of them match.
+func (c *QdrantClient) Search(ctx context.Context, collection string, vector []float32, limit int, minScore float64, workspaceIDs ...string) ([]SearchResult, error) {
 	body := map[string]any{
 		"vector":      vector,
 		"limit":       limit,
 		"with_payload": true,
 		"score_threshold": minScore,
 	}
+	if len(workspaceIDs) > 0 {
+		body["filter"] = workspaceFilter(workspaceIDs)
+	}
 
-	respBody, err := c.post("/collections/"+collection+"/points/search", body)
+	respBody, err := c.post(ctx, "/collections/"+collection+"/points/search", body)
 	if err != nil {
 		return nil, err
 	}
@@ -121,17 +182,60 @@ func (c *QdrantClient) Search(collection string, vector []float32, limit int, mi
 }
 
 // DeletePoints removes points by their IDs from a collection.
-func (c *QdrantClient) DeletePoints(collection string, ids []string) error {
+func (c *QdrantClient) DeletePoints(ctx context.Context, collection string, ids []string) error {
 	body := map[string]any{
 		"points": ids,
 	}
-	_, err := c.post("/collections/"+collection+"/points/delete", body)
+	_, err := c.post(ctx, "/collections/"+collection+"/points/delete", body)
 	return err
 }
 
+// GetPoints returns the points with the given IDs from a collection,
+// vectors included. IDs the collection doesn't hold are left out.
+func (c *QdrantClient) GetPoints(ctx context.Context, collection string, ids []string) ([]Point, error) {
+	body := map[string]any{
+		"ids":          ids,
+		"with_vector":  true,
+		"with_payload": false,
+	}
+	respBody, err := c.post(ctx, "/collections/"+collection+"/points", body)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Result []Point `json:"result"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("decode points response: %w", err)
+	}
+	return resp.Result, nil
+}
+
+// DeleteCollection drops a collection and every point in it.
+func (c *QdrantClient) DeleteCollection(ctx context.Context, name string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.baseURL+"/collections/"+name, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("qdrant DELETE /collections/%s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 && resp.StatusCode != http.StatusNotFound {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("qdrant DELETE /collections/%s: status %d: %s", name, resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
 // CollectionExists checks if a collection exists.
-func (c *QdrantClient) CollectionExists(name string) (bool, error) {
-	resp, err := c.httpClient.Get(c.baseURL + "/collections/" + name)
+func (c *QdrantClient) CollectionExists(ctx context.Context, name string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/collections/"+name, nil)
+	if err != nil {
+		return false, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return false, fmt.Errorf("check collection: %w", err)
 	}
@@ -139,13 +243,13 @@ func (c *QdrantClient) CollectionExists(name string) (bool, error) {
 	return resp.StatusCode == http.StatusOK, nil
 }
 
-func (c *QdrantClient) put(path string, body any) error {
+func (c *QdrantClient) put(ctx context.Context, path string, body any) error {
 	data, err := json.Marshal(body)
 	if err != nil {
 		return fmt.Errorf("marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPut, c.baseURL+path, bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.baseURL+path, bytes.NewReader(data))
 	if err != nil {
 		return fmt.Errorf("create request: %w", err)
 	}
@@ -164,13 +268,19 @@ func (c *QdrantClient) put(path string, body any) error {
 	return nil
 }
 
-func (c *QdrantClient) post(path string, body any) ([]byte, error) {
+func (c *QdrantClient) post(ctx context.Context, path string, body any) ([]byte, error) {
 	data, err := json.Marshal(body)
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(c.baseURL+path, "application/json", bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("qdrant POST %s: %w", path, err)
 	}
@@ -186,3 +296,110 @@ func (c *QdrantClient) post(path string, body any) ([]byte, error) {
 	}
 	return respBody, nil
 }
+
+// scrollPageSize is how many point IDs ListPointIDs fetches per request.
+const scrollPageSize = 1000
+
+// ListCollections returns the names of every collection on the server.
+func (c *QdrantClient) ListCollections(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/collections", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("qdrant GET /collections: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("qdrant GET /collections: status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var out struct {
+		Result struct {
+			Collections []struct {
+				Name string `json:"name"`
+			} `json:"collections"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(respBody, &out); err != nil {
+		return nil, fmt.Errorf("decode collections response: %w", err)
+	}
+	names := make([]string, len(out.Result.Collections))
+	for i, col := range out.Result.Collections {
+		names[i] = col.Name
+	}
+	return names, nil
+}
+
+// ListPointIDs scrolls through a collection and returns the ID of every
+// point in it, without payloads or vectors. Given workspace IDs, only
+// points whose workspace_id payload is one of them are listed.
+func (c *QdrantClient) ListPointIDs(ctx context.Context, collection string, workspaceIDs ...string) ([]string, error) {
+	var ids []string
+	err := c.scroll(ctx, collection, false, workspaceIDs, func(p Point) error {
+		ids = append(ids, p.ID)
+		return nil
+	})
+	return ids, err
+}
+
+// ScrollPoints calls fn with every point in a collection, vectors and
+// payloads included, a page at a time. It stops at fn's first error.
+func (c *QdrantClient) ScrollPoints(ctx context.Context, collection string, fn func(Point) error) error {
+	return c.scroll(ctx, collection, true, nil, fn)
+}
+
+func (c *QdrantClient) scroll(ctx context.Context, collection string, full bool, workspaceIDs []string, fn func(Point) error) error {
+	var offset any
+	for {
+		body := map[string]any{
+			"limit":        scrollPageSize,
+			"with_payload": full,
+			"with_vector":  full,
+		}
+		if offset != nil {
+			body["offset"] = offset
+		}
+		if len(workspaceIDs) > 0 {
+			body["filter"] = workspaceFilter(workspaceIDs)
+		}
+
+		respBody, err := c.post(ctx, "/collections/"+collection+"/points/scroll", body)
+		if err != nil {
+			return err
+		}
+		var resp struct {
+			Result struct {
+				Points         []Point `json:"points"`
+				NextPageOffset any     `json:"next_page_offset"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(respBody, &resp); err != nil {
+			return fmt.Errorf("decode scroll response: %w", err)
+		}
+		for _, p := range resp.Result.Points {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		if resp.Result.NextPageOffset == nil {
+			return nil
+		}
+		offset = resp.Result.NextPageOffset
+	}
+}
+
+// workspaceFilter matches points whose workspace_id payload is any of ids.
+func workspaceFilter(ids []string) map[string]any {
+	return map[string]any{
+		"must": []any{
+			map[string]any{"key": "workspace_id", "match": map[string]any{"any": ids}},
+		},
+	}
+}
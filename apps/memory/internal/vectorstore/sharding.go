@@ -0,0 +1,189 @@
+package vectorstore
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Sharding strategy names, as set in QDRANT_SHARDING.
+const (
+	ShardByWorkspace = "workspace"
+	ShardByNamespace = "namespace"
+	ShardBySize      = "size"
+)
+
+// ShardStrategy decides which Qdrant collections hold a workspace's
+// long-term vectors. Routing depends only on the route and the point ID, so
+// every writer and reader agrees on where a point lives.
+type ShardStrategy interface {
+	// Name is the QDRANT_SHARDING value that selects the strategy.
+	Name() string
+	// Collections lists every collection that may hold the workspace's
+	// points.
+	Collections(r Route) []string
+	// CollectionFor returns the collection a point is written to.
+	CollectionFor(r Route, pointID string) string
+	// Shared reports whether a collection may hold several workspaces'
+	// points, so searches must filter on the workspace_id payload.
+	Shared() bool
+}
+
+// Splitter is implemented by strategies that split one workspace across
+// several collections. Shard counts only change on rebalance, which moves
+// the points a new count routes elsewhere.
+type Splitter interface {
+	// ShardCount returns how many collections a workspace holding points
+	// vectors should be split across, at least 1.
+	ShardCount(points int) int
+}
+
+// Route is what a ShardStrategy knows about a workspace.
+type Route struct {
+	WorkspaceID string
+	Namespace   string
+	Dimension   int
+	// Shards is how many collections a Splitter has split the workspace
+	// across; at least 1.
+	Shards int
+}
+
+// DefaultShardMaxPoints is the size strategy's default points per
+// collection.
+const DefaultShardMaxPoints = 100000
+
+// ParseShardStrategy returns the strategy named by QDRANT_SHARDING.
+// maxPoints only applies to the size strategy.
+func ParseShardStrategy(name string, maxPoints int) (ShardStrategy, error) {
+	switch name {
+	case "", ShardByWorkspace:
+		return WorkspaceSharding{}, nil
+	case ShardByNamespace:
+		return NamespaceSharding{}, nil
+	case ShardBySize:
+		if maxPoints < 1 {
+			return nil, fmt.Errorf("size sharding needs a positive max points per collection, got %d", maxPoints)
+		}
+		return SizeSharding{MaxPoints: maxPoints}, nil
+	}
+	return nil, fmt.Errorf("unknown sharding strategy %q: want workspace, namespace or size", name)
+}
+
+// WorkspaceSharding gives each workspace one collection. It is the
+// default and matches the layout from before strategies existed.
+type WorkspaceSharding struct{}
+
+func (WorkspaceSharding) Name() string { return ShardByWorkspace }
+
+func (WorkspaceSharding) Collections(r Route) []string {
+	return []string{CollectionName(r.WorkspaceID, r.Dimension)}
+}
+
+func (WorkspaceSharding) CollectionFor(r Route, _ string) string {
+	return CollectionName(r.WorkspaceID, r.Dimension)
+}
+
+func (WorkspaceSharding) Shared() bool { return false }
+
+// NamespaceSharding gives each namespace one collection shared by its
+// workspaces, for deployments with many small workspaces.
+type NamespaceSharding struct{}
+
+func (NamespaceSharding) Name() string { return ShardByNamespace }
+
+func (NamespaceSharding) Collections(r Route) []string {
+	return []string{NamespaceCollectionName(r.Namespace, r.Dimension)}
+}
+
+func (NamespaceSharding) CollectionFor(r Route, _ string) string {
+	return NamespaceCollectionName(r.Namespace, r.Dimension)
+}
+
+func (NamespaceSharding) Shared() bool { return true }
+
+// SizeSharding splits a workspace into enough collections to keep each
+// under MaxPoints, spreading points across them by a hash of their ID.
+// Shard 0 keeps the workspace's unsplit collection name.
+type SizeSharding struct {
+	MaxPoints int
+}
+
+func (SizeSharding) Name() string { return ShardBySize }
+
+func (SizeSharding) Collections(r Route) []string {
+	names := make([]string, max(r.Shards, 1))
+	for i := range names {
+		names[i] = ShardCollectionName(r.WorkspaceID, r.Dimension, i)
+	}
+	return names
+}
+
+func (SizeSharding) CollectionFor(r Route, pointID string) string {
+	if r.Shards <= 1 {
+		return CollectionName(r.WorkspaceID, r.Dimension)
+	}
+	h := fnv.New32a()
+	h.Write([]byte(pointID))
+	return ShardCollectionName(r.WorkspaceID, r.Dimension, int(h.Sum32()%uint32(r.Shards)))
+}
+
+func (SizeSharding) Shared() bool { return false }
+
+func (s SizeSharding) ShardCount(points int) int {
+	return max(1, (points+s.MaxPoints-1)/s.MaxPoints)
+}
+
+// shardSuffix separates a split workspace's collection name from its
+// shard index. Workspace IDs are hex hashes or "__global__[:namespace]",
+// and namespaces are limited to letters, digits, '-' and '_', so no
+// workspace ID contains a '.'.
+const shardSuffix = ".shard"
+
+// ShardCollectionName names shard i of a workspace split by SizeSharding.
+func ShardCollectionName(workspaceID string, dimension, shard int) string {
+	name := CollectionName(workspaceID, dimension)
+	if shard == 0 {
+		return name
+	}
+	return name + shardSuffix + strconv.Itoa(shard)
+}
+
+// namespacePrefix names a dimension's namespace collections. It shares no
+// prefix with collectionPrefix, so workspace names never parse as these.
+func namespacePrefix(dimension int) string {
+	if dimension == LegacyDimension {
+		return "clive_nsmem_"
+	}
+	return fmt.Sprintf("clive_nsmem%d_", dimension)
+}
+
+// NamespaceCollectionName names the collection NamespaceSharding uses for
+// a namespace.
+func NamespaceCollectionName(namespace string, dimension int) string {
+	if namespace == "" {
+		namespace = "default"
+	}
+	return namespacePrefix(dimension) + namespace
+}
+
+// IsNamespaceCollection reports whether name is a namespace collection of
+// the given dimension.
+func IsNamespaceCollection(name string, dimension int) bool {
+	ns, ok := strings.CutPrefix(name, namespacePrefix(dimension))
+	return ok && ns != ""
+}
+
+// splitShard parses a workspace collection name's suffix into the
+// workspace-level name and shard index.
+func splitShard(name string) (string, int) {
+	i := strings.LastIndex(name, shardSuffix)
+	if i < 0 {
+		return name, 0
+	}
+	n, err := strconv.Atoi(name[i+len(shardSuffix):])
+	if err != nil || n < 1 {
+		return name, 0
+	}
+	return name[:i], n
+}
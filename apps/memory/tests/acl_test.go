@@ -0,0 +1,241 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/api"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+func TestMemoryACLs(t *testing.T) {
+	srv, cleanup := setupIntegrationTestWithAuth(t, api.Auth{
+		APIKey: "shared",
+		Keys: map[string]models.Caller{
+			"alice-token": models.ParseCaller("alice@core"),
+			"bob-token":   models.ParseCaller("bob@core"),
+			"carol-token": models.ParseCaller("carol"),
+		},
+	})
+	defer cleanup()
+
+	call := func(token, method, path string, body any) *http.Response {
+		t.Helper()
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, srv.URL+"/v1"+path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		return resp
+	}
+	store := func(token, content string, visibility models.Visibility) string {
+		t.Helper()
+		resp := call(token, http.MethodPost, "/memories", map[string]any{
+			"workspace":  "/tmp/acl-project",
+			"content":    content,
+			"memoryType": "DECISION",
+			"visibility": visibility,
+		})
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("store %q: expected 201, got %d", content, resp.StatusCode)
+		}
+		var out models.StoreResponse
+		json.NewDecoder(resp.Body).Decode(&out)
+		return out.ID
+	}
+
+	public := store("alice-token", "Deploy notes: the release train leaves on Thursdays", models.VisibilityPublic)
+	team := store("alice-token", "Deploy notes: core team owns the staging database", models.VisibilityTeam)
+	private := store("alice-token", "Deploy notes: alice keeps the rollback script locally", models.VisibilityPrivate)
+
+	visible := func(token string) string {
+		t.Helper()
+		resp := call(token, http.MethodGet, "/memories", nil)
+		defer resp.Body.Close()
+		var list models.ListResponse
+		json.NewDecoder(resp.Body).Decode(&list)
+		var ids []string
+		for _, m := range list.Memories {
+			ids = append(ids, m.ID)
+		}
+		sort.Strings(ids)
+
+		resp = call(token, http.MethodPost, "/memories/search", map[string]any{
+			"workspace":  "/tmp/acl-project",
+			"query":      "deploy notes",
+			"maxResults": 10,
+		})
+		defer resp.Body.Close()
+		var search models.SearchResponse
+		json.NewDecoder(resp.Body).Decode(&search)
+		var found []string
+		for _, r := range search.Results {
+			found = append(found, r.ID)
+		}
+		sort.Strings(found)
+		if strings.Join(found, ",") != strings.Join(ids, ",") {
+			t.Fatalf("%s: search saw %v but list saw %v", token, found, ids)
+		}
+		return strings.Join(ids, ",")
+	}
+	expect := func(ids ...string) string {
+		sort.Strings(ids)
+		return strings.Join(ids, ",")
+	}
+
+	for token, want := range map[string]string{
+		"alice-token": expect(public, team, private),
+		"bob-token":   expect(public, team),
+		"carol-token": expect(public),
+		"shared":      expect(public),
+	} {
+		if got := visible(token); got != want {
+			t.Errorf("%s sees %s, want %s", token, got, want)
+		}
+	}
+
+	for token, want := range map[string]int{
+		"alice-token": http.StatusOK,
+		"bob-token":   http.StatusNotFound,
+		"shared":      http.StatusNotFound,
+	} {
+		resp := call(token, http.MethodGet, "/memories/"+private, nil)
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("%s get private: expected %d, got %d", token, want, resp.StatusCode)
+		}
+	}
+	resp := call("carol-token", http.MethodDelete, "/memories/"+team, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("carol deleting a team memory: expected 404, got %d", resp.StatusCode)
+	}
+
+	// The per-memory impact routes hide memories the same way GET does
+	resp = call("alice-token", http.MethodPost, "/memories/"+private+"/impact", map[string]any{"signal": models.SignalHelpful, "source": "test"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("alice recording impact: expected 200, got %d", resp.StatusCode)
+	}
+	for _, path := range []string{"/impact", "/retrievability"} {
+		resp := call("bob-token", http.MethodGet, "/memories/"+private+path, nil)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("bob reading %s of a private memory: expected 404, got %d", path, resp.StatusCode)
+		}
+	}
+	resp = call("bob-token", http.MethodPost, "/memories/"+private+"/supersede", map[string]any{"newMemoryId": public})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("bob superseding a private memory: expected 404, got %d", resp.StatusCode)
+	}
+	resp = call("bob-token", http.MethodGet, "/memories/impact-leaders", nil)
+	var leaders struct {
+		Memories []*models.Memory `json:"memories"`
+	}
+	json.NewDecoder(resp.Body).Decode(&leaders)
+	resp.Body.Close()
+	for _, m := range leaders.Memories {
+		if m.ID == private {
+			t.Error("bob's impact leaders include alice's private memory")
+		}
+	}
+
+	// Attachments follow their memory's visibility
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/memories/"+private+"/attachments", bytes.NewReader(fakePNG))
+	req.Header.Set("Authorization", "Bearer alice-token")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	var att models.Attachment
+	json.NewDecoder(resp.Body).Decode(&att)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("alice attaching to her private memory: expected 201, got %d", resp.StatusCode)
+	}
+	for _, path := range []string{"/attachments/" + att.ID, "/memories/" + private + "/attachments"} {
+		for token, want := range map[string]int{"alice-token": http.StatusOK, "bob-token": http.StatusNotFound} {
+			resp := call(token, http.MethodGet, path, nil)
+			resp.Body.Close()
+			if resp.StatusCode != want {
+				t.Errorf("%s GET %s: expected %d, got %d", token, path, want, resp.StatusCode)
+			}
+		}
+	}
+	recent := func(token string) int {
+		t.Helper()
+		resp := call(token, http.MethodGet, "/attachments", nil)
+		defer resp.Body.Close()
+		var list struct {
+			Attachments []models.Attachment `json:"attachments"`
+		}
+		json.NewDecoder(resp.Body).Decode(&list)
+		return len(list.Attachments)
+	}
+	if n := recent("bob-token"); n != 0 {
+		t.Errorf("bob's recent attachments include %d on alice's private memory", n)
+	}
+	if n := recent("alice-token"); n != 1 {
+		t.Errorf("expected alice to see her attachment, got %d", n)
+	}
+	for _, token := range []string{"bob-token", "shared"} {
+		resp := call(token, http.MethodDelete, "/attachments/"+att.ID, nil)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s deleting a private memory's attachment: expected 404, got %d", token, resp.StatusCode)
+		}
+	}
+	resp = call("carol-token", http.MethodPost, "/memories/"+team+"/attachments", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("carol attaching to a team memory: expected 404, got %d", resp.StatusCode)
+	}
+
+	resp = call("bob-token", http.MethodPost, "/memories/batch", map[string]any{"ids": []string{public, team, private}})
+	var batch models.BatchGetResponse
+	json.NewDecoder(resp.Body).Decode(&batch)
+	resp.Body.Close()
+	if len(batch.Memories) != 2 || len(batch.Missing) != 1 || batch.Missing[0] != private {
+		t.Errorf("bob batch-get: expected the private memory missing, got %d memories, missing %v", len(batch.Memories), batch.Missing)
+	}
+
+	// Storing the same content as a hidden memory doesn't dedup into it
+	if id := store("bob-token", "Deploy notes: alice keeps the rollback script locally", ""); id == private {
+		t.Error("bob's store was deduplicated into alice's private memory")
+	}
+
+	for token, visibility := range map[string]models.Visibility{
+		"shared":      models.VisibilityPrivate,
+		"carol-token": models.VisibilityTeam,
+		"alice-token": "secret",
+	} {
+		resp := call(token, http.MethodPost, "/memories", map[string]any{
+			"workspace":  "/tmp/acl-project",
+			"content":    "Rejected " + string(visibility),
+			"memoryType": "DECISION",
+			"visibility": visibility,
+		})
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s storing %s: expected 400, got %d", token, visibility, resp.StatusCode)
+		}
+	}
+
+	resp = call("unknown-token", http.MethodGet, "/memories", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unknown token: expected 401, got %d", resp.StatusCode)
+	}
+}
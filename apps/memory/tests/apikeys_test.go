@@ -0,0 +1,271 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/api"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+func TestScopedAPIKeys(t *testing.T) {
+	srv, cleanup := setupIntegrationTestWithAuth(t, api.Auth{APIKey: "shared"})
+	defer cleanup()
+
+	call := func(token, namespace, method, path string, body any) *http.Response {
+		t.Helper()
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, srv.URL+"/v1"+path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		if namespace != "" {
+			req.Header.Set("X-Clive-Namespace", namespace)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		return resp
+	}
+	expect := func(resp *http.Response, status int, code string) {
+		t.Helper()
+		defer resp.Body.Close()
+		var problem struct {
+			Code string `json:"code"`
+		}
+		json.NewDecoder(resp.Body).Decode(&problem)
+		if resp.StatusCode != status || problem.Code != code {
+			t.Fatalf("%s %s: expected %d %q, got %d %q", resp.Request.Method, resp.Request.URL.Path,
+				status, code, resp.StatusCode, problem.Code)
+		}
+	}
+	create := func(req models.CreateAPIKeyRequest) models.CreateAPIKeyResponse {
+		t.Helper()
+		resp := call("shared", "", http.MethodPost, "/keys", req)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("create key %s: expected 201, got %d", req.Name, resp.StatusCode)
+		}
+		var out models.CreateAPIKeyResponse
+		json.NewDecoder(resp.Body).Decode(&out)
+		return out
+	}
+	store := func(token, namespace, workspace, content string) *http.Response {
+		t.Helper()
+		return call(token, namespace, http.MethodPost, "/memories", map[string]any{
+			"workspace":  workspace,
+			"content":    content,
+			"memoryType": "DECISION",
+		})
+	}
+	storedID := func(resp *http.Response) string {
+		t.Helper()
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("store: expected 201, got %d", resp.StatusCode)
+		}
+		var out models.StoreResponse
+		json.NewDecoder(resp.Body).Decode(&out)
+		return out.ID
+	}
+
+	expect(call("shared", "", http.MethodPost, "/keys", models.CreateAPIKeyRequest{Name: "ci", Workspace: "/src/api"}),
+		http.StatusBadRequest, "validation_failed")
+
+	wsKey := create(models.CreateAPIKeyRequest{Name: "api-agent", Namespace: "team", Workspace: "/src/api/"})
+	reader := create(models.CreateAPIKeyRequest{Name: "dashboard", Namespace: "team", Access: models.KeyAccessRead})
+	if wsKey.Token == "" || wsKey.Access != models.KeyAccessReadWrite || wsKey.Workspace != "/src/api" || wsKey.WorkspaceID == "" {
+		t.Fatalf("unexpected workspace key %+v", wsKey)
+	}
+
+	// The workspace key stores into its workspace, by default or by name,
+	// and nowhere else
+	own := storedID(store(wsKey.Token, "", "", "Retry idempotent requests with exponential backoff"))
+	storedID(store(wsKey.Token, "team", "/src/api", "Paginate list endpoints with opaque cursors"))
+	other := storedID(store("shared", "team", "/src/web", "Render dates in the viewer's locale"))
+	expect(store(wsKey.Token, "team", "/src/web", "Bundle the web app with esbuild"), http.StatusForbidden, "key_scope_denied")
+	expect(store(wsKey.Token, "other", "/src/api", "Log in UTC"), http.StatusForbidden, "key_scope_denied")
+
+	resp := call(wsKey.Token, "", http.MethodGet, "/memories", nil)
+	var list models.ListResponse
+	json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	if len(list.Memories) != 2 {
+		t.Fatalf("expected the workspace key to list its 2 memories, got %d", len(list.Memories))
+	}
+	expect(call(wsKey.Token, "", http.MethodGet, "/memories/"+own, nil), http.StatusOK, "")
+	expect(call(wsKey.Token, "", http.MethodGet, "/memories/"+other, nil), http.StatusForbidden, "key_scope_denied")
+	expect(call(wsKey.Token, "", http.MethodDelete, "/memories/"+other, nil), http.StatusForbidden, "key_scope_denied")
+	expect(call(wsKey.Token, "", http.MethodGet, "/memories?workspace_id=elsewhere", nil), http.StatusForbidden, "key_scope_denied")
+
+	resp = call(wsKey.Token, "", http.MethodPost, "/memories/batch", map[string]any{"ids": []string{own, other}})
+	var batch models.BatchGetResponse
+	json.NewDecoder(resp.Body).Decode(&batch)
+	resp.Body.Close()
+	if len(batch.Memories) != 1 || batch.Memories[0].ID != own || len(batch.Missing) != 1 || batch.Missing[0] != other {
+		t.Fatalf("expected the other workspace's memory to read as missing, got %+v", batch)
+	}
+
+	// Links and graphs stay inside the key's workspace
+	expect(call(wsKey.Token, "", http.MethodPost, "/memories/"+own+"/links",
+		models.CreateLinkRequest{TargetID: other, LinkType: models.LinkSupports}), http.StatusForbidden, "key_scope_denied")
+	expect(call("shared", "team", http.MethodPost, "/memories/"+own+"/links",
+		models.CreateLinkRequest{TargetID: other, LinkType: models.LinkSupports}), http.StatusCreated, "")
+	resp = call(wsKey.Token, "", http.MethodGet, "/memories/"+own+"/graph", nil)
+	var graph models.MemoryGraph
+	json.NewDecoder(resp.Body).Decode(&graph)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(graph.Nodes) != 1 || len(graph.Edges) != 0 {
+		t.Fatalf("expected the graph to stop at the workspace, got %d with %d nodes, %d edges",
+			resp.StatusCode, len(graph.Nodes), len(graph.Edges))
+	}
+	expect(call(wsKey.Token, "", http.MethodDelete, "/memories/"+own+"/links/"+other+"?type="+string(models.LinkSupports), nil), http.StatusForbidden, "key_scope_denied")
+	expect(call("shared", "team", http.MethodDelete, "/memories/"+own+"/links/"+other+"?type="+string(models.LinkSupports), nil), http.StatusNoContent, "")
+
+	// Attachments are reached through their memory's workspace
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/memories/"+other+"/attachments", bytes.NewReader(fakePNG))
+	req.Header.Set("Authorization", "Bearer shared")
+	req.Header.Set("X-Clive-Namespace", "team")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	var att models.Attachment
+	json.NewDecoder(resp.Body).Decode(&att)
+	resp.Body.Close()
+	expect(call(wsKey.Token, "", http.MethodGet, "/attachments/"+att.ID, nil), http.StatusForbidden, "key_scope_denied")
+	expect(call(wsKey.Token, "", http.MethodDelete, "/attachments/"+att.ID, nil), http.StatusForbidden, "key_scope_denied")
+	resp = call(wsKey.Token, "", http.MethodGet, "/attachments", nil)
+	var recent struct {
+		Attachments []models.Attachment `json:"attachments"`
+	}
+	json.NewDecoder(resp.Body).Decode(&recent)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(recent.Attachments) != 0 {
+		t.Fatalf("expected no attachments outside the key's workspace, got %d %+v", resp.StatusCode, recent.Attachments)
+	}
+
+	// Routes outside memories and workspaces, and maintenance routes, need
+	// a wider key
+	expect(call(wsKey.Token, "", http.MethodGet, "/sync/blobs", nil), http.StatusForbidden, "key_scope_denied")
+	expect(call(wsKey.Token, "", http.MethodPost, "/workspaces/merge", map[string]any{}), http.StatusForbidden, "key_scope_denied")
+	expect(call(reader.Token, "", http.MethodGet, "/keys", nil), http.StatusForbidden, "key_scope_denied")
+
+	// The read-only key searches its namespace but cannot write
+	resp = call(reader.Token, "", http.MethodPost, "/memories/search", map[string]any{"workspace": "/src/web", "query": "locale dates"})
+	var search models.SearchResponse
+	json.NewDecoder(resp.Body).Decode(&search)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(search.Results) == 0 {
+		t.Fatalf("expected the read-only key to search, got %d with %d results", resp.StatusCode, len(search.Results))
+	}
+	expect(store(reader.Token, "", "/src/web", "Ship on Fridays"), http.StatusForbidden, "key_read_only")
+	resp = call("shared", "team", http.MethodGet, "/memories/"+other, nil)
+	var otherMem models.Memory
+	json.NewDecoder(resp.Body).Decode(&otherMem)
+	resp.Body.Close()
+	expect(call(reader.Token, "", http.MethodPost, "/workspaces/"+otherMem.WorkspaceID+"/export", nil), http.StatusOK, "")
+	expect(call(reader.Token, "", http.MethodPost, "/workspaces/merge", map[string]any{}), http.StatusForbidden, "key_read_only")
+	expect(call(reader.Token, "", http.MethodDelete, "/memories/"+other, nil), http.StatusForbidden, "key_read_only")
+	expect(call(reader.Token, "default", http.MethodGet, "/memories", nil), http.StatusForbidden, "key_scope_denied")
+
+	// Revoked keys stop authenticating and stay listed
+	expect(call("shared", "", http.MethodDelete, "/keys/"+reader.ID, nil), http.StatusOK, "")
+	expect(call(reader.Token, "", http.MethodGet, "/memories", nil), http.StatusUnauthorized, "unauthorized")
+	expect(call("shared", "", http.MethodDelete, "/keys/nope", nil), http.StatusNotFound, "api_key_not_found")
+
+	resp = call("shared", "", http.MethodGet, "/keys", nil)
+	var keys struct {
+		Keys []models.APIKey `json:"keys"`
+	}
+	json.NewDecoder(resp.Body).Decode(&keys)
+	resp.Body.Close()
+	revoked := 0
+	for _, k := range keys.Keys {
+		if k.RevokedAt != nil {
+			revoked++
+		}
+	}
+	if len(keys.Keys) != 2 || revoked != 1 {
+		t.Fatalf("expected 2 keys, 1 revoked, got %+v", keys.Keys)
+	}
+}
+
+func TestScopedAPIKeysNeedAuth(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	resp, err := http.Post(srv.URL+"/v1/keys", "application/json",
+		bytes.NewReader([]byte(`{"name":"ci","namespace":"team"}`)))
+	if err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409 with auth disabled, got %d", resp.StatusCode)
+	}
+}
+
+func TestScopedAPIKeysCannotClaimIdentities(t *testing.T) {
+	srv, cleanup := setupIntegrationTestWithAuth(t, api.Auth{
+		APIKey: "shared",
+		Keys: map[string]models.Caller{
+			"alice-token": models.ParseCaller("alice@core"),
+			"carol-token": models.ParseCaller("carol"),
+		},
+	})
+	defer cleanup()
+
+	call := func(token, method, path string, body any) *http.Response {
+		t.Helper()
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, srv.URL+"/v1"+path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		return resp
+	}
+
+	resp := call("alice-token", http.MethodPost, "/memories", map[string]any{
+		"workspace":  "/tmp/key-identity",
+		"content":    "Alice's private rollback notes",
+		"memoryType": "DECISION",
+		"visibility": models.VisibilityPrivate,
+	})
+	var stored models.StoreResponse
+	json.NewDecoder(resp.Body).Decode(&stored)
+	resp.Body.Close()
+
+	// A per-caller identity cannot mint a key, least of all one named
+	// after someone else
+	resp = call("carol-token", http.MethodPost, "/keys", models.CreateAPIKeyRequest{Name: "alice@core"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("carol minting a key for alice: expected 403, got %d", resp.StatusCode)
+	}
+
+	// A key's name is only a label: the shared key's keys are anonymous
+	resp = call("shared", http.MethodPost, "/keys", models.CreateAPIKeyRequest{Name: "alice@core"})
+	var key models.CreateAPIKeyResponse
+	json.NewDecoder(resp.Body).Decode(&key)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || key.Identity != "" {
+		t.Fatalf("expected an anonymous key, got %d %+v", resp.StatusCode, key.APIKey)
+	}
+	resp = call(key.Token, http.MethodGet, "/memories/"+stored.ID, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("key named alice reading her private memory: expected 404, got %d", resp.StatusCode)
+	}
+}
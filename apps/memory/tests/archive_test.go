@@ -0,0 +1,232 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/api"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+func TestExportImportArchive(t *testing.T) {
+	src, cleanupSrc := setupIntegrationTest(t)
+	defer cleanupSrc()
+	dst, cleanupDst := setupIntegrationTest(t)
+	defer cleanupDst()
+
+	post := func(base, path string, v any, out any) int {
+		t.Helper()
+		body, _ := json.Marshal(v)
+		resp, err := http.Post(base+"/v1"+path, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if out != nil {
+			json.NewDecoder(resp.Body).Decode(out)
+		}
+		return resp.StatusCode
+	}
+
+	var short, long models.StoreResponse
+	post(src.URL, "/memories", models.StoreRequest{Workspace: "/tmp/archive-project",
+		Content: "Archive: the importer skips rows that already exist", MemoryType: models.MemoryTypeGotcha}, &short)
+	post(src.URL, "/memories", models.StoreRequest{Workspace: "/tmp/archive-project",
+		Content: "Archive: long-term vectors are re-embedded on import", MemoryType: models.MemoryTypeDecision,
+		Tier: models.TierLong}, &long)
+	post(src.URL, "/memories/"+short.ID+"/impact", models.RecordImpactRequest{Signal: "helpful", Source: "test"}, nil)
+
+	var thread models.FeatureThread
+	post(src.URL, "/threads", models.CreateThreadRequest{Workspace: "/tmp/archive-project", Name: "archive"}, &thread)
+	var entry models.ThreadEntry
+	post(src.URL, "/threads/"+thread.ID+"/entries", models.AppendEntryRequest{
+		Content: "Finding: archives are gzipped JSON lines", Section: models.ThreadSectionFindings}, &entry)
+
+	var mem models.Memory
+	resp, err := http.Get(src.URL + "/v1/memories/" + short.ID)
+	if err != nil {
+		t.Fatalf("get memory failed: %v", err)
+	}
+	json.NewDecoder(resp.Body).Decode(&mem)
+	resp.Body.Close()
+
+	resp, err = http.Post(src.URL+"/v1/workspaces/"+mem.WorkspaceID+"/export", "application/json", nil)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	archive, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/gzip" {
+		t.Fatalf("export: expected 200 gzip, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	importArchive := func() models.ImportReport {
+		t.Helper()
+		resp, err := http.Post(dst.URL+"/v1/import", "application/gzip", bytes.NewReader(archive))
+		if err != nil {
+			t.Fatalf("import failed: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("import: expected 200, got %d", resp.StatusCode)
+		}
+		var report models.ImportReport
+		json.NewDecoder(resp.Body).Decode(&report)
+		return report
+	}
+
+	report := importArchive()
+	for table, want := range map[string]int{
+		"workspaces": 1, "memories": 3, "feature_threads": 1, "thread_entries": 1, "memory_impacts": 1,
+	} {
+		if got := report.Imported[table]; got != want {
+			t.Errorf("imported %d %s, want %d (report %+v)", got, table, want, report)
+		}
+	}
+	if report.Reembedded != 1 {
+		t.Errorf("expected the long-term memory re-embedded, got %+v", report)
+	}
+
+	var got models.ThreadWithEntries
+	resp, err = http.Get(dst.URL + "/v1/threads/" + thread.ID)
+	if err != nil {
+		t.Fatalf("get thread failed: %v", err)
+	}
+	json.NewDecoder(resp.Body).Decode(&got)
+	resp.Body.Close()
+	if len(got.Entries) != 1 || got.Entries[0].Content != entry.Content {
+		t.Fatalf("expected the thread entry restored, got %+v", got.Entries)
+	}
+
+	var restored models.Memory
+	resp, err = http.Get(dst.URL + "/v1/memories/" + long.ID)
+	if err != nil {
+		t.Fatalf("get memory failed: %v", err)
+	}
+	json.NewDecoder(resp.Body).Decode(&restored)
+	resp.Body.Close()
+	if restored.Tier != models.TierLong || restored.WorkspaceID != mem.WorkspaceID {
+		t.Fatalf("unexpected restored memory: %+v", restored)
+	}
+
+	var search models.SearchResponse
+	post(dst.URL, "/memories/search", models.SearchRequest{Workspace: "/tmp/archive-project",
+		Query: "importer skips rows", SearchMode: "bm25"}, &search)
+	if len(search.Results) == 0 || search.Results[0].ID != short.ID {
+		t.Fatalf("expected the imported memory to be searchable, got %+v", search.Results)
+	}
+
+	// A second import of the same archive changes nothing
+	again := importArchive()
+	if len(again.Imported) != 0 || again.Skipped["memories"] != 3 || again.Skipped["memory_impacts"] != 1 {
+		t.Fatalf("expected a re-import to skip everything, got %+v", again)
+	}
+
+	resp, err = http.Post(dst.URL+"/v1/import", "application/gzip", bytes.NewReader([]byte("not an archive")))
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad archive, got %d", resp.StatusCode)
+	}
+}
+
+func TestExportHonorsVisibility(t *testing.T) {
+	srv, cleanup := setupIntegrationTestWithAuth(t, api.Auth{
+		APIKey: "shared",
+		Keys: map[string]models.Caller{
+			"alice-token": models.ParseCaller("alice@core"),
+			"bob-token":   models.ParseCaller("bob@core"),
+			"carol-token": models.ParseCaller("carol"),
+		},
+	})
+	defer cleanup()
+
+	call := func(token, path string, body any) *http.Response {
+		t.Helper()
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1"+path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST %s: %v", path, err)
+		}
+		return resp
+	}
+	ids := map[models.Visibility]string{}
+	for _, v := range []models.Visibility{models.VisibilityPublic, models.VisibilityTeam, models.VisibilityPrivate} {
+		resp := call("alice-token", "/memories", map[string]any{
+			"workspace":  "/tmp/acl-export",
+			"content":    "Export visibility: a " + string(v) + " memory",
+			"memoryType": "DECISION",
+			"visibility": v,
+		})
+		var out models.StoreResponse
+		json.NewDecoder(resp.Body).Decode(&out)
+		resp.Body.Close()
+		ids[v] = out.ID
+	}
+	resp := call("alice-token", "/memories/batch", map[string]any{"ids": []string{ids[models.VisibilityPublic]}})
+	var batch models.BatchGetResponse
+	json.NewDecoder(resp.Body).Decode(&batch)
+	resp.Body.Close()
+	if len(batch.Memories) != 1 {
+		t.Fatalf("expected the public memory, got %+v", batch)
+	}
+	workspaceID := batch.Memories[0].WorkspaceID
+
+	exported := func(token string) map[string]bool {
+		t.Helper()
+		resp := call(token, "/workspaces/"+workspaceID+"/export", nil)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("export as %s: expected 200, got %d", token, resp.StatusCode)
+		}
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			t.Fatalf("export as %s: %v", token, err)
+		}
+		got := map[string]bool{}
+		scanner := bufio.NewScanner(gz)
+		scanner.Buffer(make([]byte, 1<<20), 1<<20)
+		for scanner.Scan() {
+			var rec struct {
+				Table string         `json:"table"`
+				Row   map[string]any `json:"row"`
+			}
+			json.Unmarshal(scanner.Bytes(), &rec)
+			if rec.Table == "memories" {
+				id, _ := rec.Row["id"].(string)
+				got[id] = true
+			}
+		}
+		return got
+	}
+
+	for token, want := range map[string][]models.Visibility{
+		"alice-token": {models.VisibilityPublic, models.VisibilityTeam, models.VisibilityPrivate},
+		"bob-token":   {models.VisibilityPublic, models.VisibilityTeam},
+		"carol-token": {models.VisibilityPublic},
+		"shared":      {models.VisibilityPublic},
+	} {
+		got := exported(token)
+		if len(got) != len(want) {
+			t.Errorf("export as %s: got %d memories, want %d", token, len(got), len(want))
+		}
+		for _, v := range want {
+			if !got[ids[v]] {
+				t.Errorf("export as %s: missing the %s memory", token, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/mcp"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+// fakePNG is a PNG signature followed by filler; enough for content sniffing.
+var fakePNG = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+
+func TestAttachments(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	body, _ := json.Marshal(models.StoreRequest{
+		Workspace:  "/tmp/test-project",
+		Content:    "Request flow: gateway -> memory server -> qdrant",
+		MemoryType: models.MemoryTypeContext,
+	})
+	resp, err := http.Post(srv.URL+"/memories", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("store request failed: %v", err)
+	}
+	var stored models.StoreResponse
+	json.NewDecoder(resp.Body).Decode(&stored)
+	resp.Body.Close()
+
+	// Upload an image
+	resp, err = http.Post(srv.URL+"/memories/"+stored.ID+"/attachments?filename=diagrams/flow.png",
+		"application/octet-stream", bytes.NewReader(fakePNG))
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	var att models.Attachment
+	json.NewDecoder(resp.Body).Decode(&att)
+	resp.Body.Close()
+	if att.ContentType != "image/png" || att.Filename != "flow.png" || att.Size != int64(len(fakePNG)) {
+		t.Fatalf("unexpected attachment: %+v", att)
+	}
+
+	// Non-images and unknown memories are rejected
+	resp, _ = http.Post(srv.URL+"/memories/"+stored.ID+"/attachments", "text/plain", strings.NewReader("not an image"))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for text upload, got %d", resp.StatusCode)
+	}
+	resp, _ = http.Post(srv.URL+"/memories/missing/attachments", "image/png", bytes.NewReader(fakePNG))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown memory, got %d", resp.StatusCode)
+	}
+
+	// List and fetch the bytes back
+	resp, _ = http.Get(srv.URL + "/memories/" + stored.ID + "/attachments")
+	var list struct {
+		Attachments []models.Attachment `json:"attachments"`
+	}
+	json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	if len(list.Attachments) != 1 || list.Attachments[0].ID != att.ID {
+		t.Fatalf("expected the uploaded attachment, got %+v", list.Attachments)
+	}
+
+	resp, _ = http.Get(srv.URL + "/attachments/" + att.ID)
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "image/png" || !bytes.Equal(data, fakePNG) {
+		t.Fatalf("unexpected attachment body (%s, %d bytes)", resp.Header.Get("Content-Type"), len(data))
+	}
+
+	// Deleting the memory takes its attachments with it
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/memories/"+stored.ID, nil)
+	resp, _ = http.DefaultClient.Do(req)
+	resp.Body.Close()
+	resp, _ = http.Get(srv.URL + "/attachments/" + att.ID)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 after memory delete, got %d", resp.StatusCode)
+	}
+}
+
+func TestMCPAttachmentResources(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	body, _ := json.Marshal(models.StoreRequest{
+		Workspace:  "/tmp/test-project",
+		Content:    "Dashboard layout screenshot",
+		MemoryType: models.MemoryTypeContext,
+	})
+	resp, _ := http.Post(srv.URL+"/memories", "application/json", bytes.NewReader(body))
+	var stored models.StoreResponse
+	json.NewDecoder(resp.Body).Decode(&stored)
+	resp.Body.Close()
+
+	resp, _ = http.Post(srv.URL+"/memories/"+stored.ID+"/attachments", "image/png", bytes.NewReader(fakePNG))
+	var att models.Attachment
+	json.NewDecoder(resp.Body).Decode(&att)
+	resp.Body.Close()
+
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"memory://attachments/` + att.ID + `"}}`,
+	}, "\n")
+	var out bytes.Buffer
+	if err := mcp.NewServer(srv.URL, "").Serve(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+
+	scanner := bufio.NewScanner(&out)
+	scanner.Scan()
+	var listResp struct {
+		Result mcp.ResourcesListResult `json:"result"`
+	}
+	json.Unmarshal(scanner.Bytes(), &listResp)
+	if len(listResp.Result.Resources) != 1 || listResp.Result.Resources[0].URI != "memory://attachments/"+att.ID {
+		t.Fatalf("unexpected resources: %+v", listResp.Result.Resources)
+	}
+
+	scanner.Scan()
+	var readResp struct {
+		Result mcp.ReadResourceResult `json:"result"`
+	}
+	json.Unmarshal(scanner.Bytes(), &readResp)
+	if len(readResp.Result.Contents) != 1 {
+		t.Fatalf("expected one content block, got %s", scanner.Text())
+	}
+	blob, _ := base64.StdEncoding.DecodeString(readResp.Result.Contents[0].Blob)
+	if readResp.Result.Contents[0].MimeType != "image/png" || !bytes.Equal(blob, fakePNG) {
+		t.Fatalf("unexpected resource contents: %+v", readResp.Result.Contents[0])
+	}
+}
@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
+	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/search"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
+)
+
+func TestConfidenceCalibration(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ollamaSrv := fakeOllamaServer()
+	defer ollamaSrv.Close()
+	qdrantSrv := fakeQdrantServer()
+	defer qdrantSrv.Close()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	memoryStore := store.NewMemoryStore(db)
+	workspaceStore := store.NewWorkspaceStore(db)
+	bm25Store := store.NewBM25Store(db)
+	qdrantClient := vectorstore.NewQdrantClient(qdrantSrv.URL, 768)
+	collMgr := vectorstore.NewCollectionManager(qdrantClient)
+	embedder := embedding.NewCachedEmbedder(embedding.NewOllamaClient(ollamaSrv.URL, "nomic-embed-text"),
+		store.NewEmbeddingCacheStore(db), "nomic-embed-text", 768)
+	searcher := search.NewHybridSearcher(memoryStore, bm25Store, store.NewLinkStore(db), qdrantClient, collMgr, 0.7, 0.3, 1.2)
+	svc := memory.NewService(
+		memoryStore, workspaceStore, bm25Store, embedder,
+		qdrantClient, collMgr, searcher, memory.NewDeduplicator(memoryStore, 0.92),
+		memory.NewLifecycleManager(memoryStore, qdrantClient, collMgr, 3, 0.85, logger),
+		72, logger,
+	)
+
+	if _, err := svc.CalibrationReport(ctx, ""); err == nil {
+		t.Fatal("expected an error before calibration is enabled")
+	}
+	svc.SetCalibration(store.NewCalibrationStore(db))
+
+	wsID, err := workspaceStore.EnsureWorkspace(ctx, "default", "/tmp/calibration-project")
+	if err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	insert := func(source string, confidence float64) string {
+		now := time.Now().Unix()
+		mem := &models.Memory{
+			ID:          uuid.New().String(),
+			WorkspaceID: wsID,
+			Content:     "Observation " + uuid.New().String(),
+			MemoryType:  models.MemoryTypeContext,
+			Tier:        models.TierShort,
+			Confidence:  confidence,
+			Source:      source,
+			ContentHash: uuid.New().String(),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := memoryStore.Insert(ctx, mem); err != nil {
+			t.Fatalf("insert memory: %v", err)
+		}
+		return mem.ID
+	}
+
+	// Hooks claim 0.9 but only half of what they record holds up
+	for i := 0; i < 6; i++ {
+		if _, err := memoryStore.RecordImpact(ctx, insert("hook", 0.9), models.SignalHelpful, "test", "", 0); err != nil {
+			t.Fatalf("record impact: %v", err)
+		}
+		replacement := insert("manual", 0.8)
+		if err := memoryStore.Supersede(ctx, insert("pre_compact", 0.9), replacement); err != nil {
+			t.Fatalf("supersede: %v", err)
+		}
+	}
+	// Manual memories are right so far, but there are too few to judge
+	for i := 0; i < 2; i++ {
+		if _, err := memoryStore.RecordImpact(ctx, insert("", 0.7), models.SignalHelpful, "test", "", 0); err != nil {
+			t.Fatalf("record impact: %v", err)
+		}
+	}
+	insert("hook", 0.9) // unresolved, so left out of the accuracy
+
+	report, err := svc.CalibrationReport(ctx, "")
+	if err != nil {
+		t.Fatalf("calibration report: %v", err)
+	}
+	sources := make(map[string]models.SourceCalibration)
+	for _, s := range report.Sources {
+		sources[s.Source] = s
+	}
+
+	hooks, ok := sources[models.SourceGroupHooks]
+	if !ok {
+		t.Fatalf("expected a hooks group, got %+v", report.Sources)
+	}
+	if hooks.Memories != 13 || hooks.Resolved != 12 || hooks.Used != 6 || hooks.Contradicted != 6 {
+		t.Fatalf("unexpected hooks counts: %+v", hooks)
+	}
+	if hooks.MeanConfidence != 0.9 || hooks.Accuracy != 0.5 || hooks.Gap != -0.4 {
+		t.Fatalf("expected hooks to be over-confident by 0.4, got %+v", hooks)
+	}
+	if hooks.SuggestedAdjustment != -0.3 {
+		t.Fatalf("expected the suggestion to be capped at -0.3, got %f", hooks.SuggestedAdjustment)
+	}
+	if b := hooks.Buckets[len(hooks.Buckets)-1]; b.Memories != 12 || b.Accuracy != 0.5 {
+		t.Fatalf("expected every resolved hook memory in the top band, got %+v", b)
+	}
+
+	manual := sources[models.SourceGroupManual]
+	if manual.Resolved != 2 || manual.SuggestedAdjustment != 0 || manual.Note == "" {
+		t.Fatalf("expected no suggestion for a small group, got %+v", manual)
+	}
+
+	applied, err := svc.ApplyCalibration(ctx, "")
+	if err != nil {
+		t.Fatalf("apply calibration: %v", err)
+	}
+	if len(applied.Applied) != 1 || applied.Applied[models.SourceGroupHooks] != -0.3 {
+		t.Fatalf("expected only the hooks adjustment to be applied, got %+v", applied.Applied)
+	}
+
+	resp, err := svc.Store(context.Background(), &models.StoreRequest{
+		Workspace:  "/tmp/calibration-project",
+		Content:    "Session touched the billing retry path",
+		MemoryType: models.MemoryTypeContext,
+		Confidence: 0.9,
+		Source:     "session_summarizer",
+	})
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	got, _ := memoryStore.GetByID(ctx, resp.ID)
+	if got.Confidence < 0.59 || got.Confidence > 0.61 {
+		t.Fatalf("expected a hook memory to be stored at 0.6, got %f", got.Confidence)
+	}
+
+	// A second apply keeps adding to the adjustment, up to the cap
+	if _, err := svc.ApplyCalibration(ctx, ""); err != nil {
+		t.Fatalf("apply calibration: %v", err)
+	}
+	report, _ = svc.CalibrationReport(ctx, "")
+	for _, s := range report.Sources {
+		if s.Source == models.SourceGroupHooks && s.AppliedAdjustment != -0.5 {
+			t.Fatalf("expected the applied adjustment to be capped at -0.5, got %f", s.AppliedAdjustment)
+		}
+	}
+}
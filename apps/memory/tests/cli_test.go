@@ -0,0 +1,385 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/cli"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+func TestCLISearch(t *testing.T) {
+	var got models.SearchRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/memories/search" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		json.NewEncoder(w).Encode(models.SearchResponse{
+			Results: []models.SearchResult{
+				{ID: "0123456789abcdef", Content: "Run tests with\n  -tags sqlite_fts5", Score: 0.91,
+					MemoryType: models.MemoryTypeGotcha, Tier: models.TierLong, Tags: []string{"go"}},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	run := func(args ...string) (string, string, int) {
+		var stdout, stderr bytes.Buffer
+		env := &cli.Env{Stdout: &stdout, Stderr: &stderr, ServerURL: srv.URL}
+		code := cli.Run(env, args)
+		return stdout.String(), stderr.String(), code
+	}
+
+	t.Run("flags after query and type normalization", func(t *testing.T) {
+		out, errOut, code := run("search", "fts5 tests", "--workspace", "/tmp/proj", "--type", "gotcha,working-solution")
+		if code != 0 {
+			t.Fatalf("exit %d: %s", code, errOut)
+		}
+		if got.Query != "fts5 tests" || got.Workspace != "/tmp/proj" {
+			t.Fatalf("unexpected request: %+v", got)
+		}
+		if len(got.MemoryTypes) != 2 || got.MemoryTypes[1] != models.MemoryTypeWorkingSolution {
+			t.Fatalf("unexpected memory types: %v", got.MemoryTypes)
+		}
+		if !strings.Contains(out, "[GOTCHA] 0.91 long 01234567") || !strings.Contains(out, "Run tests with -tags sqlite_fts5") {
+			t.Fatalf("unexpected output:\n%s", out)
+		}
+		if strings.Contains(out, "\033[") {
+			t.Fatal("expected no color codes when color is disabled")
+		}
+	})
+
+	t.Run("json mode", func(t *testing.T) {
+		out, _, code := run("search", "--json", "fts5")
+		if code != 0 {
+			t.Fatalf("exit %d", code)
+		}
+		var resp models.SearchResponse
+		if err := json.Unmarshal([]byte(out), &resp); err != nil || len(resp.Results) != 1 {
+			t.Fatalf("expected JSON response, got %q (%v)", out, err)
+		}
+	})
+
+	t.Run("invalid type and missing query", func(t *testing.T) {
+		if _, _, code := run("search", "q", "--type", "bogus"); code != 1 {
+			t.Fatalf("expected exit 1 for bad type, got %d", code)
+		}
+		if _, _, code := run("search"); code != 1 {
+			t.Fatalf("expected exit 1 for missing query, got %d", code)
+		}
+	})
+}
+
+func TestCLISkillsSyncDryRun(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/skills/sync" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"dryRun":true,"found":2,"changes":[
+			{"name":"review","action":"updated","reason":"description changed"},
+			{"name":"deploy","action":"unchanged"},
+			{"name":"lint","action":"added"},
+			{"name":"old","action":"removed","reason":"no longer on disk"}]}`))
+	}))
+	defer srv.Close()
+
+	var stdout, stderr bytes.Buffer
+	env := &cli.Env{Stdout: &stdout, Stderr: &stderr, ServerURL: srv.URL}
+	if code := cli.Run(env, []string{"skills", "sync", "--dry-run"}); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if query != "dry_run=true" {
+		t.Fatalf("expected dry_run query, got %q", query)
+	}
+
+	out := stdout.String()
+	for _, want := range []string{
+		"dry run: nothing was written",
+		"+ lint   added",
+		"~ review updated   description changed",
+		"- old    removed   no longer on disk",
+		"2 found: 1 added, 1 updated, 1 unchanged, 1 removed, 0 failed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "= deploy") {
+		t.Fatalf("expected unchanged skills to be hidden without --all:\n%s", out)
+	}
+	if strings.Index(out, "+ lint") > strings.Index(out, "~ review") {
+		t.Fatalf("expected changes grouped by action:\n%s", out)
+	}
+}
+
+func TestCLIVectorsRebalance(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/vectors/rebalance" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"dryRun":true,"strategy":"namespace","points":3,"moved":2,"skipped":1,
+			"moves":[{"from":"clive_memory_a","to":"clive_nsmem_default","points":2}],
+			"dropped":["clive_memory_a"]}`))
+	}))
+	defer srv.Close()
+
+	var stdout, stderr bytes.Buffer
+	env := &cli.Env{Stdout: &stdout, Stderr: &stderr, ServerURL: srv.URL}
+	if code := cli.Run(env, []string{"vectors", "rebalance", "--dry-run"}); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if query != "dry_run=true" {
+		t.Fatalf("expected dry_run query, got %q", query)
+	}
+	for _, want := range []string{
+		"dry run: nothing was moved",
+		"clive_memory_a -> clive_nsmem_default  2 points",
+		"- clive_memory_a",
+		"namespace sharding: 3 points, 2 moved, 1 skipped, 1 collections dropped",
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("expected %q in output:\n%s", want, stdout.String())
+		}
+	}
+
+	if code := cli.Run(env, []string{"vectors", "shuffle"}); code == 0 {
+		t.Fatal("expected an unknown subcommand to fail")
+	}
+}
+
+func TestCLIKeys(t *testing.T) {
+	var created models.CreateAPIKeyRequest
+	var revoked string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/keys":
+			json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"k1","name":"ci","prefix":"clive_abcdef","namespace":"team","workspace":"/src/api",
+				"access":"read","createdAt":1700000000,"token":"clive_abcdef0123"}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/v1/keys/k1":
+			revoked = "k1"
+			w.Write([]byte(`{"id":"k1","name":"ci","prefix":"clive_abcdef","access":"read","createdAt":1700000000,"revokedAt":1700000100}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	var stdout, stderr bytes.Buffer
+	env := &cli.Env{Stdout: &stdout, Stderr: &stderr, ServerURL: srv.URL}
+	args := []string{"keys", "create", "--name", "ci", "--scope-namespace", "team", "--scope-workspace", "/src/api", "--read-only"}
+	if code := cli.Run(env, args); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if created.Name != "ci" || created.Namespace != "team" || created.Workspace != "/src/api" || created.Access != models.KeyAccessRead {
+		t.Fatalf("unexpected create request %+v", created)
+	}
+	for _, want := range []string{"team:/src/api", "clive_abcdef0123"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("expected %q in output:\n%s", want, stdout.String())
+		}
+	}
+
+	stdout.Reset()
+	if code := cli.Run(env, []string{"keys", "revoke", "k1"}); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if revoked != "k1" || !strings.Contains(stdout.String(), "revoked") {
+		t.Fatalf("expected k1 revoked, got %q:\n%s", revoked, stdout.String())
+	}
+
+	if code := cli.Run(env, []string{"keys", "create"}); code == 0 {
+		t.Fatal("expected create without --name to fail")
+	}
+}
+
+func TestCLIConventionsSync(t *testing.T) {
+	var stored []models.StoreRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/memories" {
+			http.NotFound(w, r)
+			return
+		}
+		var req models.StoreRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		stored = append(stored, req)
+		// The second convention is already known to the server
+		json.NewEncoder(w).Encode(models.StoreResponse{ID: "m", Deduplicated: len(stored) == 2})
+	}))
+	defer srv.Close()
+
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n\ngo 1.24.0\n"), 0o644)
+	os.WriteFile(filepath.Join(root, ".golangci.yml"), []byte("linters: {}\n"), 0o644)
+
+	var stdout, stderr bytes.Buffer
+	env := &cli.Env{Stdout: &stdout, Stderr: &stderr, ServerURL: srv.URL}
+	if code := cli.Run(env, []string{"conventions", "sync", "--workspace", root}); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+
+	if len(stored) != 2 {
+		t.Fatalf("expected 2 conventions stored, got %d", len(stored))
+	}
+	first := stored[0]
+	if first.Workspace != root || first.Tier != models.TierLong || first.Source != "conventions" ||
+		!strings.HasPrefix(first.Content, "[Convention: language] Go module example.com/app") {
+		t.Fatalf("unexpected store request: %+v", first)
+	}
+	if len(first.RelatedFiles) != 1 || first.RelatedFiles[0] != "go.mod" {
+		t.Fatalf("expected go.mod as the related file, got %v", first.RelatedFiles)
+	}
+	if !strings.Contains(stdout.String(), "2 conventions: 1 stored, 1 already known") {
+		t.Fatalf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+func TestCLIConfigSync(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	src := t.TempDir()
+	os.MkdirAll(filepath.Join(src, "profiles"), 0o700)
+	os.WriteFile(filepath.Join(src, "config.json"), []byte(`{"keybindings":{"quit":"ctrl+q"}}`), 0o644)
+	os.WriteFile(filepath.Join(src, "profiles", "fast.json"), []byte(`{"model":"haiku"}`), 0o600)
+
+	run := func(passphrase string, args ...string) (string, string, int) {
+		var stdout, stderr bytes.Buffer
+		env := &cli.Env{Stdout: &stdout, Stderr: &stderr, ServerURL: srv.URL, SyncPassphrase: passphrase}
+		code := cli.Run(env, args)
+		return stdout.String(), stderr.String(), code
+	}
+
+	if _, stderr, code := run("", "config", "push", "--dir", src); code != 1 || !strings.Contains(stderr, "CLIVE_SYNC_PASSPHRASE") {
+		t.Fatalf("expected push without a passphrase to fail, got %d: %s", code, stderr)
+	}
+
+	out, stderr, code := run("correct horse", "config", "push", "--dir", src)
+	if code != 0 || !strings.Contains(out, "pushed 2 files") || !strings.Contains(out, "default v1") {
+		t.Fatalf("push failed: %d %s %s", code, out, stderr)
+	}
+
+	// The server only ever holds ciphertext
+	resp, err := http.Get(srv.URL + "/v1/sync/blobs/default")
+	if err != nil {
+		t.Fatalf("get blob: %v", err)
+	}
+	var blob models.SyncBlob
+	json.NewDecoder(resp.Body).Decode(&blob)
+	resp.Body.Close()
+	if blob.Version != 1 || len(blob.Data) == 0 || bytes.Contains(blob.Data, []byte("keybindings")) {
+		t.Fatalf("expected an encrypted v1 blob, got version %d with %d bytes", blob.Version, len(blob.Data))
+	}
+
+	dst := t.TempDir()
+	if _, stderr, code := run("wrong", "config", "pull", "--dir", dst); code != 1 || !strings.Contains(stderr, "cannot decrypt") {
+		t.Fatalf("expected a wrong passphrase to fail, got %d: %s", code, stderr)
+	}
+	out, stderr, code = run("correct horse", "config", "pull", "--dir", dst)
+	if code != 0 || !strings.Contains(out, "pulled default v1: 2 files") {
+		t.Fatalf("pull failed: %d %s %s", code, out, stderr)
+	}
+	got, _ := os.ReadFile(filepath.Join(dst, "profiles", "fast.json"))
+	if string(got) != `{"model":"haiku"}` {
+		t.Fatalf("expected the nested profile to be restored, got %q", got)
+	}
+	if fi, _ := os.Stat(filepath.Join(dst, "profiles", "fast.json")); fi.Mode().Perm() != 0o600 {
+		t.Fatalf("expected file modes to be restored, got %v", fi.Mode())
+	}
+
+	// A push based on a stale version is refused
+	body, _ := json.Marshal(models.PutSyncBlobRequest{Data: []byte("x"), BaseVersion: new(int)})
+	req, _ := http.NewRequest(http.MethodPut, srv.URL+"/v1/sync/blobs/default", bytes.NewReader(body))
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("put blob: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409 for a stale base version, got %d", resp.StatusCode)
+	}
+
+	out, _, _ = run("", "config", "list")
+	if !strings.Contains(out, "default") || !strings.Contains(out, "v1") {
+		t.Fatalf("expected the bundle to be listed, got %q", out)
+	}
+}
+
+func TestCLICompletionAndManPage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/workspaces" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode([]models.Workspace{{ID: "a", Path: "/src/api"}, {ID: "b", Path: "/src/web"}})
+	}))
+	defer srv.Close()
+
+	complete := func(words ...string) []string {
+		var stdout, stderr bytes.Buffer
+		env := &cli.Env{Stdout: &stdout, Stderr: &stderr, ServerURL: srv.URL}
+		if code := cli.Run(env, append([]string{"__complete"}, words...)); code != 0 {
+			t.Fatalf("__complete %v: exit %d: %s", words, code, stderr.String())
+		}
+		return strings.Fields(stdout.String())
+	}
+
+	cases := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"co"}, "completion config conventions"},
+		{[]string{"config", ""}, "push pull list"},
+		{[]string{"config", "push", ""}, ""},
+		{[]string{"search", "--m"}, "--min-score"},
+		{[]string{"search", "--type", "gotcha,w"}, "gotcha,working-solution"},
+		{[]string{"search", "--workspace", "/src/a"}, "/src/api"},
+		{[]string{"search", "--json", ""}, ""},
+	}
+	for _, tc := range cases {
+		if got := strings.Join(complete(tc.words...), " "); got != tc.want {
+			t.Errorf("complete %q = %q, want %q", tc.words, got, tc.want)
+		}
+	}
+
+	var usage bytes.Buffer
+	cli.Run(&cli.Env{Stdout: &usage, Stderr: &usage}, nil)
+	if strings.Contains(usage.String(), "__complete") {
+		t.Fatal("expected the completion callback to be hidden from usage")
+	}
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		var out bytes.Buffer
+		if code := cli.Run(&cli.Env{Stdout: &out, Stderr: &out}, []string{"completion", shell}); code != 0 {
+			t.Fatalf("completion %s: exit %d: %s", shell, code, out.String())
+		}
+		if !strings.Contains(out.String(), "clive-memory __complete") {
+			t.Fatalf("expected the %s script to call back into __complete:\n%s", shell, out.String())
+		}
+	}
+
+	var man bytes.Buffer
+	if code := cli.Run(&cli.Env{Stdout: &man, Stderr: &man}, []string{"man"}); code != 0 {
+		t.Fatalf("man: exit %d: %s", code, man.String())
+	}
+	for _, want := range []string{".TH CLIVE-MEMORY 1", ".SS search", `\fB\-\-min\-score\fR \fIfloat\fR`, ".B CLIVE_SYNC_PASSPHRASE"} {
+		if !strings.Contains(man.String(), want) {
+			t.Fatalf("man page is missing %q:\n%s", want, man.String())
+		}
+	}
+}
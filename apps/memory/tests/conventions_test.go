@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/conventions"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+func writeConventionFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestConventionsScan(t *testing.T) {
+	root := t.TempDir()
+	writeConventionFiles(t, root, map[string]string{
+		".editorconfig":       "root = true\n\n[*]\nindent_style = space\nindent_size = 2\nend_of_line = lf\n\n[Makefile]\nindent_style = tab\n",
+		".prettierrc":         `{"semi": false, "singleQuote": true}`,
+		"package.json":        `{"packageManager": "pnpm@9.1.0", "scripts": {"test": "vitest", "lint": "biome check ."}}`,
+		"pnpm-workspace.yaml": "packages:\n  - apps/*\n",
+		"go.mod":              "module example.com/app\n\ngo 1.24.0\n",
+		"pyproject.toml":      "[project]\nname = \"app\"\n\n[tool.ruff]\nline-length = 100\n",
+		".github/workflows/ci.yml": `
+jobs:
+  test:
+    steps:
+      - uses: actions/checkout@v4
+      - run: pnpm install
+      - run: |
+          go test ./...
+          go vet ./...
+`,
+	})
+
+	found, err := conventions.Scan(root)
+	if err != nil {
+		t.Fatalf("Scan error: %v", err)
+	}
+
+	want := []struct{ file, content string }{
+		{".editorconfig", "Editor settings: indent with 2 spaces, LF line endings."},
+		{".prettierrc", "Code is formatted with Prettier (no semicolons, single quotes)"},
+		{"package.json", "pnpm workspace monorepo"},
+		{"package.json", "run tests with `pnpm test`, lint with `pnpm lint`"},
+		{"go.mod", "Go module example.com/app targeting Go 1.24.0"},
+		{"pyproject.toml", "Lint Python with Ruff."},
+		{"pyproject.toml", "limited to 100 characters"},
+		{".github/workflows/ci.yml", "runs `pnpm install`, `go test ./...`;"},
+	}
+	for _, w := range want {
+		ok := false
+		for _, c := range found {
+			if len(c.Files) == 1 && c.Files[0] == w.file && strings.Contains(c.Content, w.content) {
+				ok = true
+			}
+		}
+		if !ok {
+			t.Errorf("expected a convention from %s containing %q, got:", w.file, w.content)
+			for _, c := range found {
+				t.Logf("  %s %v: %s", c.MemoryType, c.Files, c.Content)
+			}
+		}
+	}
+
+	for _, c := range found {
+		if c.MemoryType != models.MemoryTypePattern && c.MemoryType != models.MemoryTypePreference {
+			t.Errorf("unexpected memory type %s for %q", c.MemoryType, c.Content)
+		}
+	}
+}
+
+func TestConventionsScanEmptyProject(t *testing.T) {
+	found, err := conventions.Scan(t.TempDir())
+	if err != nil {
+		t.Fatalf("Scan error: %v", err)
+	}
+	if len(found) != 0 {
+		t.Fatalf("expected no conventions, got %d", len(found))
+	}
+}
+
+func TestConventionsScanMissingRoot(t *testing.T) {
+	if _, err := conventions.Scan("/nonexistent/path"); err == nil {
+		t.Fatal("expected an error for a missing root")
+	}
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 )
 
 func TestDeduplicator(t *testing.T) {
+	ctx := context.Background()
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
@@ -20,7 +22,7 @@ func TestDeduplicator(t *testing.T) {
 	ws := store.NewWorkspaceStore(db)
 	dedup := memoryPkg.NewDeduplicator(ms, 0.92)
 
-	wsID, _ := ws.EnsureWorkspace("default", "/tmp/dedup-test")
+	wsID, _ := ws.EnsureWorkspace(ctx, "default", "/tmp/dedup-test")
 
 	t.Run("detects exact content hash duplicate", func(t *testing.T) {
 		content := "Use npm ci instead of npm install in CI"
@@ -33,12 +35,12 @@ func TestDeduplicator(t *testing.T) {
 			MemoryType: models.MemoryTypeWorkingSolution, Tier: models.TierShort,
 			Confidence: 0.9, ContentHash: hash, CreatedAt: now, UpdatedAt: now,
 		}
-		ms.Insert(mem)
+		ms.Insert(ctx, mem)
 
 		// IsDuplicate uses ContentHash from embedding package, but here we
 		// test hash-based dedup by inserting with a known hash and querying
 		// with the same content.
-		found, err := ms.FindByContentHash(wsID, hash)
+		found, err := ms.FindByContentHash(ctx, wsID, hash)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -60,9 +62,9 @@ func TestDeduplicator(t *testing.T) {
 			Embedding: search.Float32ToBytes(vec1),
 			CreatedAt: now, UpdatedAt: now,
 		}
-		ms.Insert(mem)
+		ms.Insert(ctx, mem)
 
-		dupID, err := dedup.IsDuplicate(wsID, "different content but same vector", vec2)
+		dupID, err := dedup.IsDuplicate(ctx, wsID, "different content but same vector", vec2)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -73,7 +75,7 @@ func TestDeduplicator(t *testing.T) {
 
 	t.Run("allows unique content", func(t *testing.T) {
 		vec := []float32{0.0, 0.0, 1.0, 0.0} // Very different from existing
-		dupID, err := dedup.IsDuplicate(wsID, "completely unique content xyz", vec)
+		dupID, err := dedup.IsDuplicate(ctx, wsID, "completely unique content xyz", vec)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -0,0 +1,233 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
+)
+
+func TestEmbeddingBackends(t *testing.T) {
+	var lastBody map[string]any
+	var lastAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lastBody = nil
+		json.Unmarshal(body, &lastBody)
+		lastAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/embeddings":
+			json.NewEncoder(w).Encode(map[string]any{
+				"data": []map[string]any{{"embedding": []float32{0.1, 0.2, 0.3}, "index": 0}},
+			})
+		case "/embed":
+			json.NewEncoder(w).Encode([][]float32{{0.4, 0.5}})
+		case "/v1/models", "/health":
+			w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	openai := embedding.NewOpenAIClient(srv.URL+"/v1/", "sk-test", "text-embedding-3-large", 256)
+	vec, err := openai.Embed(ctx, "hello")
+	if err != nil || len(vec) != 3 {
+		t.Fatalf("openai embed: %v %v", vec, err)
+	}
+	if lastAuth != "Bearer sk-test" || lastBody["input"] != "hello" || lastBody["dimensions"] != float64(256) {
+		t.Fatalf("unexpected openai request: auth %q body %v", lastAuth, lastBody)
+	}
+	if err := openai.HealthCheck(); err != nil {
+		t.Fatalf("openai health: %v", err)
+	}
+
+	// No dimensions means the field is left out, for servers that lack it
+	embedding.NewOpenAIClient(srv.URL+"/v1", "", "local-model", 0).Embed(ctx, "hello")
+	if _, ok := lastBody["dimensions"]; ok || lastAuth != "" {
+		t.Fatalf("expected no dimensions or auth, got auth %q body %v", lastAuth, lastBody)
+	}
+
+	voyage := embedding.NewVoyageClient(srv.URL+"/v1", "pa-test", "voyage-3", 0)
+	if vec, err := voyage.Embed(ctx, "hello"); err != nil || len(vec) != 3 {
+		t.Fatalf("voyage embed: %v %v", vec, err)
+	}
+	if inputs, _ := lastBody["input"].([]any); len(inputs) != 1 || inputs[0] != "hello" || lastAuth != "Bearer pa-test" {
+		t.Fatalf("unexpected voyage request: auth %q body %v", lastAuth, lastBody)
+	}
+	if _, ok := lastBody["output_dimension"]; ok {
+		t.Fatalf("expected no output_dimension, got %v", lastBody)
+	}
+
+	tei := embedding.NewTEIClient(srv.URL)
+	if vec, err := tei.Embed(ctx, "hello"); err != nil || len(vec) != 2 {
+		t.Fatalf("tei embed: %v %v", vec, err)
+	}
+	if lastBody["inputs"] != "hello" {
+		t.Fatalf("unexpected tei request: %v", lastBody)
+	}
+	if err := tei.HealthCheck(); err != nil {
+		t.Fatalf("tei health: %v", err)
+	}
+
+	// A backend returning vectors of the wrong size is caught before they
+	// reach the cache or Qdrant
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	cached := embedding.NewCachedEmbedder(tei, store.NewEmbeddingCacheStore(db), "all-MiniLM-L6-v2", 384)
+	if _, err := cached.Embed(ctx, "hello"); err == nil || !strings.Contains(err.Error(), "2 dimensions") {
+		t.Fatalf("expected a dimension mismatch error, got %v", err)
+	}
+}
+
+func TestCollectionsPerDimension(t *testing.T) {
+	ctx := context.Background()
+	sizes := map[string]int{"clive_memory_ws1": 768}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/collections/")
+		switch r.Method {
+		case http.MethodGet:
+			size, ok := sizes[name]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"result": map[string]any{
+				"config": map[string]any{"params": map[string]any{"vectors": map[string]any{"size": size}}},
+			}})
+		case http.MethodPut:
+			var body struct {
+				Vectors struct {
+					Size int `json:"size"`
+				} `json:"vectors"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			sizes[name] = body.Vectors.Size
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	// The legacy dimension keeps its unqualified collection names
+	legacy := vectorstore.NewCollectionManager(vectorstore.NewQdrantClient(srv.URL, 768))
+	if name, err := legacy.EnsureForWorkspace(ctx, "ws1"); err != nil || name != "clive_memory_ws1" {
+		t.Fatalf("legacy collection: %q %v", name, err)
+	}
+
+	// Another backend's dimension gets collections of its own
+	openai := vectorstore.NewCollectionManager(vectorstore.NewQdrantClient(srv.URL, 1536))
+	name, err := openai.EnsureForWorkspace(ctx, "ws1")
+	if err != nil || name != "clive_mem1536_ws1" || sizes[name] != 1536 {
+		t.Fatalf("1536 collection: %q %v (sizes %v)", name, err, sizes)
+	}
+	if id, ok := vectorstore.WorkspaceForCollection(name, 1536); !ok || id != "ws1" {
+		t.Fatalf("expected %s to map back to ws1, got %q", name, id)
+	}
+	if _, ok := vectorstore.WorkspaceForCollection(name, 768); ok {
+		t.Fatalf("legacy dimension claimed %s", name)
+	}
+	if _, ok := vectorstore.WorkspaceForCollection("clive_memory_ws1", 1536); ok {
+		t.Fatal("1536 dimension claimed the legacy collection")
+	}
+
+	// A collection of the wrong size under the expected name is refused
+	sizes["clive_memory_ws2"] = 384
+	if _, err := legacy.EnsureForWorkspace(ctx, "ws2"); err == nil || !strings.Contains(err.Error(), "384-dimension") {
+		t.Fatalf("expected a size mismatch error, got %v", err)
+	}
+}
+
+// gatedEmbedder blocks every embed until release is closed or the embed
+// is cancelled.
+type gatedEmbedder struct {
+	calls     atomic.Int32
+	cancelled chan struct{}
+	release   chan struct{}
+}
+
+func (g *gatedEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	g.calls.Add(1)
+	select {
+	case <-g.release:
+		return []float32{0.1, 0.2, 0.3}, nil
+	case <-ctx.Done():
+		close(g.cancelled)
+		return nil, ctx.Err()
+	}
+}
+
+func (g *gatedEmbedder) HealthCheck() error { return nil }
+
+func TestCachedEmbedderCoalescesInflight(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	upstream := &gatedEmbedder{cancelled: make(chan struct{}), release: make(chan struct{})}
+	embedder := embedding.NewCachedEmbedder(upstream, store.NewEmbeddingCacheStore(db), "test-model", 3)
+
+	var wg sync.WaitGroup
+	vecs := make([][]float32, 8)
+	errs := make([]error, 8)
+	for i := range vecs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vecs[i], errs[i] = embedder.Embed(context.Background(), "same content")
+		}()
+	}
+	for upstream.calls.Load() == 0 {
+		runtime.Gosched()
+	}
+	close(upstream.release)
+	wg.Wait()
+
+	for i := range vecs {
+		if errs[i] != nil || len(vecs[i]) != 3 {
+			t.Fatalf("caller %d: %v %v", i, vecs[i], errs[i])
+		}
+	}
+	vecs[0][0] = 9
+	if vecs[1][0] != 0.1 {
+		t.Fatal("expected each caller to get its own copy of the vector")
+	}
+	if n := upstream.calls.Load(); n != 1 {
+		t.Fatalf("expected identical concurrent embeds to share one upstream call, got %d", n)
+	}
+
+	// An embed no one is waiting for any more is cancelled upstream
+	upstream.release = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := embedder.Embed(ctx, "other content")
+		done <- err
+	}()
+	for upstream.calls.Load() == 1 {
+		runtime.Gosched()
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("expected the cancelled caller to get context.Canceled, got %v", err)
+	}
+	select {
+	case <-upstream.cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the abandoned embed to be cancelled upstream")
+	}
+}
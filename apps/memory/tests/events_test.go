@@ -0,0 +1,231 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/api"
+	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+func TestMemoryEventStream(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	resp, err := http.Get(srv.URL + "/v1/events?types=bogus")
+	if err != nil {
+		t.Fatalf("get events: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown event type, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/v1/events?types=stored,deduplicated,superseded", nil)
+	req.Header.Set("X-Clive-Namespace", "team")
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	defer stream.Body.Close()
+	if ct := stream.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+	received := make(chan models.MemoryEvent, 16)
+	go func() {
+		scanner := bufio.NewScanner(stream.Body)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				var e models.MemoryEvent
+				json.Unmarshal([]byte(data), &e)
+				received <- e
+			}
+		}
+		close(received)
+	}()
+	next := func() models.MemoryEvent {
+		t.Helper()
+		select {
+		case e := <-received:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an event")
+		}
+		return models.MemoryEvent{}
+	}
+
+	post := func(namespace, path string, body any) models.StoreResponse {
+		t.Helper()
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1"+path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Clive-Namespace", namespace)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		var out models.StoreResponse
+		json.NewDecoder(resp.Body).Decode(&out)
+		return out
+	}
+	store := func(namespace, content string) models.StoreResponse {
+		t.Helper()
+		return post(namespace, "/memories", map[string]any{
+			"workspace":  "/src/api",
+			"content":    content,
+			"memoryType": "DECISION",
+		})
+	}
+
+	// Other namespaces are filtered out of a namespaced stream
+	store("default", "Use tabs in the default namespace")
+	old := store("team", "Retry idempotent requests three times").ID
+	e := next()
+	if e.Type != models.MemoryEventStored || e.MemoryID != old || e.Namespace != "team" || e.MemoryType != models.MemoryTypeDecision {
+		t.Fatalf("unexpected stored event %+v", e)
+	}
+
+	if dup := store("team", "Retry idempotent requests three times"); !dup.Deduplicated {
+		t.Fatalf("expected a duplicate, got %+v", dup)
+	}
+	if e := next(); e.Type != models.MemoryEventDeduplicated || e.MemoryID != old {
+		t.Fatalf("unexpected deduplicated event %+v", e)
+	}
+
+	replacement := store("team", "Retry idempotent requests with exponential backoff").ID
+	next()
+	post("team", "/memories/"+old+"/supersede", map[string]any{"newMemoryId": replacement})
+	if e := next(); e.Type != models.MemoryEventSuperseded || e.MemoryID != old || e.RelatedID != replacement {
+		t.Fatalf("unexpected superseded event %+v", e)
+	}
+}
+
+func TestMemoryEventStreamAccess(t *testing.T) {
+	srv, cleanup := setupIntegrationTestWithAuth(t, api.Auth{
+		APIKey: "shared",
+		Keys: map[string]models.Caller{
+			"alice-token": models.ParseCaller("alice@core"),
+			"bob-token":   models.ParseCaller("bob@core"),
+			"carol-token": models.ParseCaller("carol"),
+		},
+	})
+	defer cleanup()
+	var streams []*http.Response
+	defer func() {
+		for _, s := range streams {
+			s.Body.Close()
+		}
+	}()
+
+	call := func(token, method, path string, body any) *http.Response {
+		t.Helper()
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, srv.URL+"/v1"+path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		return resp
+	}
+	// subscribe returns the IDs of the memories stored events are sent for
+	subscribe := func(token string) <-chan string {
+		t.Helper()
+		stream := call(token, http.MethodGet, "/events?types=stored", nil)
+		streams = append(streams, stream)
+		if stream.StatusCode != http.StatusOK {
+			t.Fatalf("open stream: expected 200, got %d", stream.StatusCode)
+		}
+		ids := make(chan string, 16)
+		go func() {
+			scanner := bufio.NewScanner(stream.Body)
+			for scanner.Scan() {
+				if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+					var e models.MemoryEvent
+					json.Unmarshal([]byte(data), &e)
+					ids <- e.MemoryID
+				}
+			}
+			close(ids)
+		}()
+		return ids
+	}
+	store := func(token, workspace, content string, visibility models.Visibility) string {
+		t.Helper()
+		resp := call(token, http.MethodPost, "/memories", map[string]any{
+			"workspace":  workspace,
+			"content":    content,
+			"memoryType": "DECISION",
+			"visibility": visibility,
+		})
+		defer resp.Body.Close()
+		var out models.StoreResponse
+		json.NewDecoder(resp.Body).Decode(&out)
+		if out.ID == "" {
+			t.Fatalf("store %q: status %d", content, resp.StatusCode)
+		}
+		return out.ID
+	}
+
+	resp := call("shared", http.MethodPost, "/keys", models.CreateAPIKeyRequest{Name: "web-agent", Namespace: "default", Workspace: "/src/web"})
+	var key models.CreateAPIKeyResponse
+	json.NewDecoder(resp.Body).Decode(&key)
+	resp.Body.Close()
+
+	bob, carol, scoped := subscribe("bob-token"), subscribe("carol-token"), subscribe(key.Token)
+
+	store("alice-token", "/src/api", "Alice's private notes on the auth rewrite", models.VisibilityPrivate)
+	team := store("alice-token", "/src/api", "The core team owns the auth service", models.VisibilityTeam)
+	public := store("alice-token", "/src/api", "The auth service issues JWTs", models.VisibilityPublic)
+	web := store("alice-token", "/src/web", "The web app stores JWTs in memory", models.VisibilityPublic)
+
+	expect := func(name string, ids <-chan string, want ...string) {
+		t.Helper()
+		for _, id := range want {
+			select {
+			case got := <-ids:
+				if got != id {
+					t.Fatalf("%s: expected an event for %s, got one for %s", name, id, got)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("%s: timed out waiting for an event for %s", name, id)
+			}
+		}
+	}
+	// Events for memories a subscriber can't read, or outside its key's
+	// workspace, are skipped
+	expect("bob", bob, team, public, web)
+	expect("carol", carol, public, web)
+	expect("workspace-scoped key", scoped, web)
+}
+
+func TestEventBusClose(t *testing.T) {
+	bus := memory.NewEventBus()
+	events, unsubscribe := bus.Subscribe(1)
+	bus.Publish(models.MemoryEvent{Type: models.MemoryEventPromoted, MemoryID: "a"})
+	bus.Publish(models.MemoryEvent{Type: models.MemoryEventPromoted, MemoryID: "b"})
+
+	if e := <-events; e.MemoryID != "a" || e.At == 0 {
+		t.Fatalf("unexpected event %+v", e)
+	}
+	bus.Close()
+	if _, ok := <-events; ok {
+		t.Fatal("expected a full subscriber to miss the second event and the channel to close")
+	}
+	unsubscribe()
+
+	if _, ok := <-func() <-chan models.MemoryEvent { ch, _ := bus.Subscribe(1); return ch }(); ok {
+		t.Fatal("expected subscriptions to a closed bus to end immediately")
+	}
+}
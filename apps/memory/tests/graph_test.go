@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+func TestMemoryGraph(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	call := func(method, path string, body any, out any) int {
+		t.Helper()
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, srv.URL+"/v1"+path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		if out != nil {
+			json.NewDecoder(resp.Body).Decode(out)
+		}
+		return resp.StatusCode
+	}
+	store := func(content string) string {
+		t.Helper()
+		var out models.StoreResponse
+		call(http.MethodPost, "/memories", models.StoreRequest{
+			Workspace: "/tmp/graph-project", Content: content, MemoryType: models.MemoryTypeDecision,
+		}, &out)
+		return out.ID
+	}
+	link := func(from, to string, linkType models.LinkType) int {
+		t.Helper()
+		return call(http.MethodPost, "/memories/"+from+"/links", models.CreateLinkRequest{TargetID: to, LinkType: linkType}, nil)
+	}
+
+	a := store("Graph: retries use exponential backoff with jitter")
+	b := store("Graph: jitter avoids thundering herds after an outage")
+	c := store("Graph: the outage in March came from synchronized retries")
+	d := store("Graph: a cron job restarted every worker at midnight")
+
+	if status := link(a, b, models.LinkSupports); status != http.StatusCreated {
+		t.Fatalf("create link: expected 201, got %d", status)
+	}
+	link(c, b, models.LinkRefines)
+	link(c, d, models.LinkCausedBy)
+
+	nodes := func(depth string) (string, int) {
+		t.Helper()
+		var g models.MemoryGraph
+		if status := call(http.MethodGet, "/memories/"+a+"/graph?depth="+depth, nil, &g); status != http.StatusOK {
+			t.Fatalf("graph depth %s: expected 200, got %d", depth, status)
+		}
+		var ids []string
+		for _, n := range g.Nodes {
+			ids = append(ids, n.Memory.ID)
+		}
+		sort.Strings(ids)
+		return strings.Join(ids, ","), len(g.Edges)
+	}
+	expect := func(ids ...string) string {
+		sort.Strings(ids)
+		return strings.Join(ids, ",")
+	}
+	for depth, want := range map[string]struct {
+		nodes string
+		edges int
+	}{
+		"1": {expect(a, b), 1},
+		"2": {expect(a, b, c), 2},
+		"3": {expect(a, b, c, d), 3},
+	} {
+		if got, edges := nodes(depth); got != want.nodes || edges != want.edges {
+			t.Errorf("depth %s: got nodes %s and %d edges, want %s and %d", depth, got, edges, want.nodes, want.edges)
+		}
+	}
+
+	for name, status := range map[string]int{
+		"bad type":  link(a, c, "blocks"),
+		"self link": link(a, a, models.LinkSupports),
+		"missing":   link(a, "no-such-memory", models.LinkSupports),
+		"depth":     call(http.MethodGet, "/memories/"+a+"/graph?depth=9", nil, nil),
+	} {
+		want := http.StatusBadRequest
+		if name == "missing" {
+			want = http.StatusNotFound
+		}
+		if status != want {
+			t.Errorf("%s: expected %d, got %d", name, want, status)
+		}
+	}
+
+	if status := call(http.MethodDelete, "/memories/"+c+"/links/"+d+"?type=caused_by", nil, nil); status != http.StatusNoContent {
+		t.Fatalf("unlink: expected 204, got %d", status)
+	}
+	if status := call(http.MethodDelete, "/memories/"+c+"/links/"+d+"?type=caused_by", nil, nil); status != http.StatusNotFound {
+		t.Fatalf("second unlink: expected 404, got %d", status)
+	}
+	if got, _ := nodes("3"); got != expect(a, b, c) {
+		t.Fatalf("expected d unreachable after unlink, got %s", got)
+	}
+
+	// Search spreads activation along supporting links but not contradictions
+	supported := store("Graph: circuit breakers trip after five failures")
+	contradicted := store("Graph: breakers should never be used in batch jobs")
+	hit := store("Graph: zebrafish thresholds tune the breaker")
+	link(hit, supported, models.LinkSupports)
+	link(hit, contradicted, models.LinkContradicts)
+
+	var search models.SearchResponse
+	call(http.MethodPost, "/memories/search", models.SearchRequest{
+		Workspace: "/tmp/graph-project", Query: "zebrafish", SearchMode: "bm25",
+	}, &search)
+	found := map[string]bool{}
+	for _, r := range search.Results {
+		found[r.ID] = true
+	}
+	if !found[hit] || !found[supported] || found[contradicted] {
+		t.Fatalf("expected the hit and its supported memory only, got %+v", search.Results)
+	}
+}
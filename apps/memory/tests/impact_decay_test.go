@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+func TestImpactDecay(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ms := store.NewMemoryStore(db)
+	ws := store.NewWorkspaceStore(db)
+	wsID, _ := ws.EnsureWorkspace(ctx, "default", "/tmp/test-project")
+
+	near := func(got, want float64) bool { return math.Abs(got-want) < 0.01 }
+
+	if got := store.ImpactAfter(0.8, 90, 90); !near(got, 0.4) {
+		t.Fatalf("expected one half-life to halve the score, got %f", got)
+	}
+	if got := store.ImpactAfter(0.8, 90, 0); got != 0.8 {
+		t.Fatalf("expected no decay with a zero half-life, got %f", got)
+	}
+
+	insert := func(score float64, ageDays int) string {
+		now := time.Now().Unix()
+		updated := now - int64(ageDays)*86400
+		mem := &models.Memory{
+			ID:              uuid.New().String(),
+			WorkspaceID:     wsID,
+			Content:         "Decision recorded " + uuid.New().String(),
+			MemoryType:      models.MemoryTypeDecision,
+			Tier:            models.TierLong,
+			Confidence:      0.9,
+			ContentHash:     uuid.New().String(),
+			CreatedAt:       updated,
+			UpdatedAt:       updated,
+			ImpactScore:     score,
+			ImpactUpdatedAt: &updated,
+		}
+		if err := ms.Insert(ctx, mem); err != nil {
+			t.Fatalf("insert failed: %v", err)
+		}
+		return mem.ID
+	}
+
+	stale := insert(0.8, 180)
+	fresh := insert(0.8, 0)
+	faded := insert(0.0015, 90)
+
+	n, err := ms.DecayImpact(ctx, 90)
+	if err != nil {
+		t.Fatalf("decay failed: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected the stale and faded memories to decay, got %d", n)
+	}
+
+	got, _ := ms.GetByID(ctx, stale)
+	if !near(got.ImpactScore, 0.2) {
+		t.Fatalf("expected two half-lives to quarter the score, got %f", got.ImpactScore)
+	}
+	got, _ = ms.GetByID(ctx, fresh)
+	if got.ImpactScore != 0.8 {
+		t.Fatalf("expected a current score to be left alone, got %f", got.ImpactScore)
+	}
+	got, _ = ms.GetByID(ctx, faded)
+	if got.ImpactScore != 0 {
+		t.Fatalf("expected a negligible score to be cleared, got %f", got.ImpactScore)
+	}
+
+	// A new signal is added to the score as it stands today
+	old := insert(0.8, 90)
+	score, err := ms.RecordImpact(ctx, old, models.SignalHelpful, "test", "", 90)
+	if err != nil {
+		t.Fatalf("record impact failed: %v", err)
+	}
+	if !near(score, 0.4+models.SignalDeltas[models.SignalHelpful]) {
+		t.Fatalf("expected the decayed score plus the signal, got %f", score)
+	}
+	got, _ = ms.GetByID(ctx, old)
+	if got.ImpactUpdatedAt == nil || time.Now().Unix()-*got.ImpactUpdatedAt > 5 {
+		t.Fatalf("expected impactUpdatedAt to be reset, got %v", got.ImpactUpdatedAt)
+	}
+}
+
+func TestRecalculateImpact(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ms := store.NewMemoryStore(db)
+	ws := store.NewWorkspaceStore(db)
+	wsID, _ := ws.EnsureWorkspace(ctx, "default", "/tmp/test-project")
+
+	near := func(got, want float64) bool { return math.Abs(got-want) < 0.01 }
+	insert := func(score float64) string {
+		now := time.Now().Unix()
+		mem := &models.Memory{
+			ID:          uuid.New().String(),
+			WorkspaceID: wsID,
+			Content:     "Imported note " + uuid.New().String(),
+			MemoryType:  models.MemoryTypeContext,
+			Tier:        models.TierShort,
+			Confidence:  0.8,
+			ContentHash: uuid.New().String(),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			ImpactScore: score,
+		}
+		if err := ms.Insert(ctx, mem); err != nil {
+			t.Fatalf("insert failed: %v", err)
+		}
+		return mem.ID
+	}
+
+	// Replaying events decays the score between them
+	now := time.Now().Unix()
+	score, at := store.ImpactFromEvents([]models.ImpactEvent{
+		{Signal: models.SignalPromoted, CreatedAt: now - 90*86400},
+		{Signal: "retired-signal", CreatedAt: now - 60*86400},
+		{Signal: models.SignalHelpful, CreatedAt: now},
+	}, 90)
+	if !near(score, 0.125+0.15) || at != now {
+		t.Fatalf("expected the promoted signal halved plus helpful, got %f at %d", score, at)
+	}
+
+	cited := insert(0)
+	for i := 0; i < 2; i++ {
+		if _, err := ms.RecordImpact(ctx, cited, models.SignalCited, "test", "", 0); err != nil {
+			t.Fatalf("record impact: %v", err)
+		}
+	}
+	imported := insert(0.9) // a score with no events behind it
+	untouched := insert(0)
+
+	// The delta table changes after the events were recorded
+	defer func(old float64) { models.SignalDeltas[models.SignalCited] = old }(models.SignalDeltas[models.SignalCited])
+	models.SignalDeltas[models.SignalCited] = 0.3
+
+	scanned, changed, batches := 0, 0, 0
+	afterID := ""
+	for {
+		lastID, n, c, err := ms.RecalculateImpactBatch(ctx, afterID, 2, 0)
+		if err != nil {
+			t.Fatalf("recalculate batch: %v", err)
+		}
+		if n == 0 {
+			break
+		}
+		afterID, scanned, changed, batches = lastID, scanned+n, changed+c, batches+1
+	}
+	if scanned != 3 || batches != 2 || changed != 2 {
+		t.Fatalf("expected 3 memories over 2 batches with 2 changes, got %d/%d/%d", scanned, batches, changed)
+	}
+
+	got, _ := ms.GetByID(ctx, cited)
+	if !near(got.ImpactScore, 0.6) {
+		t.Fatalf("expected two cited signals at the new delta, got %f", got.ImpactScore)
+	}
+	got, _ = ms.GetByID(ctx, imported)
+	if got.ImpactScore != 0 || got.ImpactUpdatedAt != nil {
+		t.Fatalf("expected a score without events to be cleared, got %f", got.ImpactScore)
+	}
+	got, _ = ms.GetByID(ctx, untouched)
+	if got.ImpactScore != 0 {
+		t.Fatalf("expected an untouched memory to stay at zero, got %f", got.ImpactScore)
+	}
+}
@@ -2,17 +2,22 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"log/slog"
 
 	"github.com/iammorganparry/clive/apps/memory/internal/api"
+	"github.com/iammorganparry/clive/apps/memory/internal/attachments"
 	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
 	"github.com/iammorganparry/clive/apps/memory/internal/memory"
 	"github.com/iammorganparry/clive/apps/memory/internal/models"
@@ -20,6 +25,7 @@ import (
 	"github.com/iammorganparry/clive/apps/memory/internal/sessions"
 	"github.com/iammorganparry/clive/apps/memory/internal/store"
 	"github.com/iammorganparry/clive/apps/memory/internal/threads"
+	"github.com/iammorganparry/clive/apps/memory/internal/tokens"
 	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
 )
 
@@ -77,6 +83,12 @@ func fakeQdrantServer() *httptest.Server {
 
 func setupIntegrationTest(t *testing.T) (*httptest.Server, func()) {
 	t.Helper()
+	return setupIntegrationTestWithAuth(t, api.Auth{})
+}
+
+// setupIntegrationTestWithAuth is setupIntegrationTest with bearer auth on.
+func setupIntegrationTestWithAuth(t *testing.T, auth api.Auth) (*httptest.Server, func()) {
+	t.Helper()
 
 	dir := t.TempDir()
 	dbPath := filepath.Join(dir, "test.db")
@@ -120,12 +132,25 @@ func setupIntegrationTest(t *testing.T) (*httptest.Server, func()) {
 
 	sessStore := sessions.NewSessionStore(db)
 	obsStore := sessions.NewObservationStore(db)
-	summarizer := sessions.NewSummarizer(ollamaSrv.URL, "test-model", false, logger)
+	summarizer := sessions.NewSummarizer(sessions.NewOllamaProvider(ollamaSrv.URL), "test-model", false, logger)
 
 	threadStore := store.NewThreadStore(db)
-	threadSvc := threads.NewService(threadStore, memoryStore, workspaceStore, logger)
-
-	router := api.NewRouter(db, svc, ollamaClient, qdrantClient, nil, sessStore, obsStore, summarizer, threadSvc, "", logger)
+	threadSvc := threads.NewService(threadStore, memoryStore, workspaceStore, summarizer, 1000, false, logger)
+	svc.SetThreadStore(threadStore)
+	calibrationStore := store.NewCalibrationStore(db)
+	svc.SetCalibration(calibrationStore)
+	threadSvc.SetCalibration(calibrationStore)
+	threadSvc.SetTokenizer(tokens.Profiles[tokens.Default])
+	svc.SetArchive(store.NewArchiveStore(db))
+	svc.SetLinkStore(linkStore)
+
+	compactor := memory.NewCompactor(svc, db, store.NewCompactionStore(db), nil, nil, 0, logger)
+
+	attachmentSvc := attachments.NewService(filepath.Join(dir, "attachments"), 64<<10,
+		store.NewAttachmentStore(db), memoryStore, nil, logger)
+	compactor.AddPruner("attachments", attachmentSvc.Prune)
+
+	router := api.NewRouter(db, svc, ollamaClient, nil, qdrantClient, nil, sessStore, obsStore, summarizer, threadSvc, nil, compactor, attachmentSvc, api.Timeouts{}, auth, logger)
 	srv := httptest.NewServer(router)
 
 	cleanup := func() {
@@ -469,6 +494,25 @@ func TestValidation(t *testing.T) {
 		}
 	})
 
+	t.Run("fields without structured", func(t *testing.T) {
+		storeReq := models.StoreRequest{
+			Workspace:  "/tmp/test",
+			MemoryType: models.MemoryTypeDecision,
+			Fields:     map[string]string{"decision": "Use SQLite"},
+		}
+		body, _ := json.Marshal(storeReq)
+		resp, err := http.Post(srv.URL+"/memories", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("store request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var p api.Problem
+		json.NewDecoder(resp.Body).Decode(&p)
+		if resp.StatusCode != http.StatusBadRequest || p.Code != "fields_require_structured" {
+			t.Fatalf("expected 400 fields_require_structured, got %d %+v", resp.StatusCode, p)
+		}
+	})
+
 	t.Run("invalid memory type", func(t *testing.T) {
 		storeReq := map[string]any{
 			"workspace":  "/tmp/test",
@@ -493,3 +537,952 @@ func TestValidation(t *testing.T) {
 		}
 	})
 }
+
+func TestSearchGroupByType(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	bulkReq := models.BulkStoreRequest{
+		Workspace: "/tmp/test-project",
+		Memories: []models.BulkMemory{
+			{Content: "sqlite gotcha one: WAL needs shared memory", MemoryType: models.MemoryTypeGotcha, Confidence: 0.9},
+			{Content: "sqlite gotcha two: busy timeout must be set", MemoryType: models.MemoryTypeGotcha, Confidence: 0.9},
+			{Content: "sqlite gotcha three: fts5 needs a build tag", MemoryType: models.MemoryTypeGotcha, Confidence: 0.9},
+			{Content: "sqlite pattern: open one connection per process", MemoryType: models.MemoryTypePattern, Confidence: 0.9},
+		},
+	}
+	body, _ := json.Marshal(bulkReq)
+	resp, err := http.Post(srv.URL+"/memories/bulk", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("bulk store failed: %v", err)
+	}
+	resp.Body.Close()
+
+	searchReq := models.SearchRequest{
+		Workspace:   "/tmp/test-project",
+		Query:       "sqlite",
+		MaxResults:  10,
+		SearchMode:  models.SearchModeHybrid,
+		GroupByType: true,
+		TypeCaps:    map[models.MemoryType]int{models.MemoryTypeGotcha: 2},
+	}
+	body, _ = json.Marshal(searchReq)
+	searchResp, err := http.Post(srv.URL+"/memories/search", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("search request failed: %v", err)
+	}
+	defer searchResp.Body.Close()
+
+	var result models.SearchResponse
+	json.NewDecoder(searchResp.Body).Decode(&result)
+
+	counts := make(map[models.MemoryType]int)
+	for _, g := range result.Groups {
+		counts[g.MemoryType] = g.Count
+		if len(g.IDs) != g.Count {
+			t.Fatalf("group %s: %d ids for count %d", g.MemoryType, len(g.IDs), g.Count)
+		}
+	}
+	if counts[models.MemoryTypeGotcha] != 2 {
+		t.Fatalf("expected GOTCHA capped at 2, got %d", counts[models.MemoryTypeGotcha])
+	}
+	if counts[models.MemoryTypePattern] != 1 {
+		t.Fatalf("expected 1 PATTERN result, got %d", counts[models.MemoryTypePattern])
+	}
+	if len(result.Results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(result.Results))
+	}
+}
+
+func TestProblemResponses(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	decodeProblem := func(t *testing.T, resp *http.Response) api.Problem {
+		t.Helper()
+		if ct := resp.Header.Get("Content-Type"); ct != "application/problem+json" {
+			t.Fatalf("expected problem+json, got %q", ct)
+		}
+		var p api.Problem
+		if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+			t.Fatalf("decode problem: %v", err)
+		}
+		return p
+	}
+
+	t.Run("typed not found error", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/memories/does-not-exist", nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("delete failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d", resp.StatusCode)
+		}
+		p := decodeProblem(t, resp)
+		if p.Code != "memory_not_found" || p.Status != http.StatusNotFound {
+			t.Fatalf("unexpected problem: %+v", p)
+		}
+	})
+
+	t.Run("validation failure", func(t *testing.T) {
+		body, _ := json.Marshal(models.SearchRequest{Query: ""})
+		resp, err := http.Post(srv.URL+"/memories/search", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("search failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		p := decodeProblem(t, resp)
+		if p.Code != "validation_failed" || p.Detail != "query is required" {
+			t.Fatalf("unexpected problem: %+v", p)
+		}
+	})
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	post := func(key string, content string) (*http.Response, models.StoreResponse) {
+		t.Helper()
+		body, _ := json.Marshal(models.StoreRequest{
+			Workspace:  "/tmp/test-project",
+			Content:    content,
+			MemoryType: models.MemoryTypeContext,
+		})
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/memories", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", key)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("store request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var sr models.StoreResponse
+		json.NewDecoder(resp.Body).Decode(&sr)
+		return resp, sr
+	}
+
+	first, firstBody := post("key-1", "Retry-safe store at 10:00:01")
+	if first.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", first.StatusCode)
+	}
+
+	replay, replayBody := post("key-1", "Retry-safe store at 10:00:01")
+	if replay.StatusCode != http.StatusCreated || replay.Header.Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected replayed 201, got %d (replayed=%q)", replay.StatusCode, replay.Header.Get("Idempotent-Replayed"))
+	}
+	if replayBody.ID != firstBody.ID {
+		t.Fatalf("expected replayed ID %s, got %s", firstBody.ID, replayBody.ID)
+	}
+
+	reused, _ := post("key-1", "Retry-safe store at 10:00:02")
+	if reused.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for reused key, got %d", reused.StatusCode)
+	}
+
+	other, otherBody := post("key-2", "Retry-safe store at 10:00:02")
+	if other.StatusCode != http.StatusCreated || otherBody.ID == firstBody.ID {
+		t.Fatalf("expected a new memory for a new key, got %d %s", other.StatusCode, otherBody.ID)
+	}
+}
+
+func TestThreadEntryTokenBudget(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	body, _ := json.Marshal(models.CreateThreadRequest{Workspace: "/tmp/test-project", Name: "budget"})
+	resp, err := http.Post(srv.URL+"/threads", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("create thread failed: %v", err)
+	}
+	var thread models.FeatureThread
+	json.NewDecoder(resp.Body).Decode(&thread)
+	resp.Body.Close()
+
+	appendEntry := func(content string) *http.Response {
+		t.Helper()
+		body, _ := json.Marshal(models.AppendEntryRequest{Content: content, Section: models.ThreadSectionFindings})
+		resp, err := http.Post(srv.URL+"/threads/"+thread.ID+"/entries", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("append failed: %v", err)
+		}
+		return resp
+	}
+
+	resp = appendEntry(strings.Repeat("a", 400))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	// ~1250 tokens exceeds the 1000 token entry limit
+	resp = appendEntry(strings.Repeat("b", 5000))
+	var p api.Problem
+	json.NewDecoder(resp.Body).Decode(&p)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || p.Code != "entry_too_large" {
+		t.Fatalf("expected 400 entry_too_large, got %d %+v", resp.StatusCode, p)
+	}
+
+	resp, err = http.Get(srv.URL + "/threads/" + thread.ID)
+	if err != nil {
+		t.Fatalf("get thread failed: %v", err)
+	}
+	var got models.ThreadWithEntries
+	json.NewDecoder(resp.Body).Decode(&got)
+	resp.Body.Close()
+	if got.TokenUsage != 100 || got.EntryCount != 1 {
+		t.Fatalf("expected 100 tokens over 1 entry, got %d over %d", got.TokenUsage, got.EntryCount)
+	}
+
+	resp, err = http.Get(srv.URL + "/threads?workspace=/tmp/test-project")
+	if err != nil {
+		t.Fatalf("list threads failed: %v", err)
+	}
+	var list struct {
+		Threads []models.FeatureThread `json:"threads"`
+	}
+	json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	if len(list.Threads) != 1 || list.Threads[0].TokenUsage != 100 {
+		t.Fatalf("expected token usage in list response, got %+v", list.Threads)
+	}
+}
+
+func TestCompactionHistory(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	for i := 0; i < 2; i++ {
+		resp, err := http.Post(srv.URL+"/memories/compact", "application/json", nil)
+		if err != nil {
+			t.Fatalf("compact failed: %v", err)
+		}
+		var report models.CompactionReport
+		json.NewDecoder(resp.Body).Decode(&report)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || report.ID == "" || report.Trigger != "manual" {
+			t.Fatalf("expected a manual compaction report, got %d %+v", resp.StatusCode, report)
+		}
+		if report.DBSizeBefore <= 0 {
+			t.Fatalf("expected database size to be measured, got %d", report.DBSizeBefore)
+		}
+	}
+
+	resp, err := http.Get(srv.URL + "/compact/history?limit=1")
+	if err != nil {
+		t.Fatalf("history failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var history struct {
+		Runs []models.CompactionReport `json:"runs"`
+	}
+	json.NewDecoder(resp.Body).Decode(&history)
+	if len(history.Runs) != 1 || history.Runs[0].Trigger != "manual" {
+		t.Fatalf("expected 1 run with limit=1, got %+v", history.Runs)
+	}
+
+	metrics, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("metrics failed: %v", err)
+	}
+	defer metrics.Body.Close()
+	body, _ := io.ReadAll(metrics.Body)
+	for _, line := range []string{
+		`clive_compaction_runs_total{trigger="manual",outcome="success"} 2`,
+		`clive_compaction_memories_total{action="expired"} 0`,
+		"# TYPE clive_compaction_last_duration_seconds gauge",
+	} {
+		if !strings.Contains(string(body), line) {
+			t.Errorf("expected %q in metrics:\n%s", line, body)
+		}
+	}
+}
+
+func TestCompactionReportWebhook(t *testing.T) {
+	received := make(chan map[string]any, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload
+	}))
+	defer hook.Close()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	if memory.NewReportNotifier("", memory.ReportMail{}, logger) != nil {
+		t.Fatal("expected no notifier without a delivery channel")
+	}
+
+	notifier := memory.NewReportNotifier(hook.URL, memory.ReportMail{}, logger)
+	notifier.Deliver(&models.CompactionReport{
+		Trigger:         "scheduled",
+		CompactResponse: models.CompactResponse{Expired: 4, Promoted: 1},
+		DBSizeBefore:    3 << 20,
+		DBSizeAfter:     2 << 20,
+		DBSizeDelta:     -1 << 20,
+	})
+
+	payload := <-received
+	text, _ := payload["text"].(string)
+	for _, want := range []string{"Memory compaction (scheduled)", "Expired: 4", "Promoted to long-term: 1", "3.0 MB -> 2.0 MB (-1.0 MB)"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in report text, got:\n%s", want, text)
+		}
+	}
+	if _, ok := payload["report"].(map[string]any); !ok {
+		t.Fatalf("expected structured report in payload, got %v", payload)
+	}
+}
+
+func TestSearchSnippets(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	content := strings.Repeat("Unrelated setup notes about the project layout. ", 10) +
+		"The migration runner must enable the sqlite_fts5 build tag before tests run. " +
+		strings.Repeat("More unrelated closing remarks about formatting. ", 10)
+	body, _ := json.Marshal(models.StoreRequest{
+		Workspace:  "/tmp/test-project",
+		Content:    content,
+		MemoryType: models.MemoryTypeGotcha,
+	})
+	resp, err := http.Post(srv.URL+"/memories", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+	resp.Body.Close()
+
+	body, _ = json.Marshal(models.SearchRequest{
+		Workspace:  "/tmp/test-project",
+		Query:      "migration runner",
+		SearchMode: models.SearchModeBM25,
+	})
+	resp, err = http.Post(srv.URL+"/memories/search/index", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var index models.SearchIndexResponse
+	json.NewDecoder(resp.Body).Decode(&index)
+	if len(index.Results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(index.Results))
+	}
+	snippet := index.Results[0].Snippet
+	if !strings.Contains(snippet, "<mark>migration</mark> <mark>runner</mark>") {
+		t.Fatalf("expected highlighted terms in snippet, got %q", snippet)
+	}
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") {
+		t.Fatalf("expected snippet centered on the match, got %q", snippet)
+	}
+}
+
+func TestRetrievabilityCurve(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	body, _ := json.Marshal(models.StoreRequest{
+		Workspace:  "/tmp/test-project",
+		Content:    "The staging database is reset every Monday morning",
+		MemoryType: models.MemoryTypeContext,
+	})
+	resp, err := http.Post(srv.URL+"/memories", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+	var stored models.StoreResponse
+	json.NewDecoder(resp.Body).Decode(&stored)
+	resp.Body.Close()
+
+	getCurve := func(query string) (*http.Response, models.RetrievabilityCurve) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + "/memories/" + stored.ID + "/retrievability" + query)
+		if err != nil {
+			t.Fatalf("curve request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var curve models.RetrievabilityCurve
+		json.NewDecoder(resp.Body).Decode(&curve)
+		return resp, curve
+	}
+
+	resp, curve := getCurve("?days=90")
+	if resp.StatusCode != http.StatusOK || len(curve.Points) != 91 {
+		t.Fatalf("expected 91 points, got %d (%d)", len(curve.Points), resp.StatusCode)
+	}
+	if curve.Points[0].Retrievability < 0.99 || curve.Points[1].Retrievability >= curve.Points[0].Retrievability {
+		t.Fatalf("expected a decaying curve from ~1.0, got %+v", curve.Points[:2])
+	}
+	// CONTEXT memories start at stability 2: exp(-d/2) hits 0.05 on day 6
+	if curve.ForgottenInDays == nil || *curve.ForgottenInDays != 6 {
+		t.Fatalf("expected to be forgotten in 6 days, got %v", curve.ForgottenInDays)
+	}
+	if curve.Points[0].Reinforced != 0 {
+		t.Fatal("expected no reinforced curve for a never-accessed memory")
+	}
+
+	// A search hit counts as an access, so the reinforced curve appears
+	body, _ = json.Marshal(models.SearchRequest{
+		Workspace:  "/tmp/test-project",
+		Query:      "staging database reset",
+		MinScore:   0.01,
+		SearchMode: models.SearchModeHybrid,
+	})
+	resp, err = http.Post(srv.URL+"/memories/search", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	resp.Body.Close()
+
+	_, curve = getCurve("?days=30")
+	if curve.AccessCount != 1 || curve.AccessIntervalDays != 1 {
+		t.Fatalf("expected one access at the minimum interval, got %+v", curve)
+	}
+	// The access reinforced stability with the same boost the curve replays
+	if want := 2 * store.StabilityBoost(0); math.Abs(curve.Stability-want) > 1e-9 {
+		t.Fatalf("expected stability %.2f after one access, got %.2f", want, curve.Stability)
+	}
+	last := curve.Points[len(curve.Points)-1]
+	if last.Reinforced <= last.Retrievability {
+		t.Fatalf("expected reinforcement to slow decay, got %+v", last)
+	}
+
+	if resp, _ := getCurve("?days=91"); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for days=91, got %d", resp.StatusCode)
+	}
+	resp, err = http.Get(srv.URL + "/memories/missing/retrievability")
+	if err != nil {
+		t.Fatalf("curve request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown memory, got %d", resp.StatusCode)
+	}
+}
+
+func TestThreadBatchEntries(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	body, _ := json.Marshal(models.CreateThreadRequest{Workspace: "/tmp/test-project", Name: "batch"})
+	resp, err := http.Post(srv.URL+"/threads", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("create thread failed: %v", err)
+	}
+	var thread models.FeatureThread
+	json.NewDecoder(resp.Body).Decode(&thread)
+	resp.Body.Close()
+
+	post := func(path string, v any, out any) int {
+		t.Helper()
+		body, _ := json.Marshal(v)
+		resp, err := http.Post(srv.URL+"/threads/"+thread.ID+path, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		json.NewDecoder(resp.Body).Decode(out)
+		return resp.StatusCode
+	}
+	getThread := func() models.ThreadWithEntries {
+		t.Helper()
+		resp, err := http.Get(srv.URL + "/threads/" + thread.ID)
+		if err != nil {
+			t.Fatalf("get thread failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var got models.ThreadWithEntries
+		json.NewDecoder(resp.Body).Decode(&got)
+		return got
+	}
+
+	var appended models.AppendEntriesResponse
+	status := post("/entries/batch", models.AppendEntriesRequest{
+		Workspace: "/tmp/test-project",
+		Entries: []models.AppendEntryRequest{
+			{Content: "Finding: retries hide the race", Section: models.ThreadSectionFindings},
+			{Content: "Finding: the cache is per process", Section: models.ThreadSectionFindings},
+			{Content: "Todo: add a lock around refresh", Section: models.ThreadSectionTodo},
+		},
+	}, &appended)
+	if status != http.StatusCreated || len(appended.Entries) != 3 {
+		t.Fatalf("expected 3 entries appended, got %d %+v", status, appended)
+	}
+	for i, e := range appended.Entries {
+		if e.Sequence != i+1 {
+			t.Fatalf("expected sequence %d, got %d", i+1, e.Sequence)
+		}
+	}
+
+	// One oversized entry rejects the whole batch
+	var p api.Problem
+	status = post("/entries/batch", models.AppendEntriesRequest{
+		Entries: []models.AppendEntryRequest{
+			{Content: "Finding: small enough"},
+			{Content: strings.Repeat("b", 5000)},
+		},
+	}, &p)
+	if status != http.StatusBadRequest || p.Code != "entry_too_large" || !strings.HasPrefix(p.Detail, "entry 1:") {
+		t.Fatalf("expected 400 entry_too_large for entry 1, got %d %+v", status, p)
+	}
+	if got := getThread(); got.EntryCount != 3 || len(got.Entries) != 3 {
+		t.Fatalf("expected a rejected batch to write nothing, got %d entries", got.EntryCount)
+	}
+
+	var reclassified models.ReclassifyEntriesResponse
+	status = post("/entries/reclassify", models.ReclassifyEntriesRequest{
+		EntryIDs: []string{appended.Entries[0].ID, appended.Entries[1].ID},
+		Section:  models.ThreadSectionDecisions,
+	}, &reclassified)
+	if status != http.StatusOK || reclassified.Updated != 2 {
+		t.Fatalf("expected 2 entries reclassified, got %d %+v", status, reclassified)
+	}
+
+	// An entry from outside the thread moves nothing
+	p = api.Problem{}
+	status = post("/entries/reclassify", models.ReclassifyEntriesRequest{
+		EntryIDs: []string{appended.Entries[2].ID, "missing"},
+		Section:  models.ThreadSectionDecisions,
+	}, &p)
+	if status != http.StatusNotFound || p.Code != "entry_not_found" {
+		t.Fatalf("expected 404 entry_not_found, got %d %+v", status, p)
+	}
+
+	sections := map[models.ThreadSection]int{}
+	for _, e := range getThread().Entries {
+		sections[e.Section]++
+	}
+	if sections[models.ThreadSectionDecisions] != 2 || sections[models.ThreadSectionTodo] != 1 {
+		t.Fatalf("unexpected sections after reclassify: %v", sections)
+	}
+}
+
+func TestThreadEntryEdits(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	body, _ := json.Marshal(models.CreateThreadRequest{Workspace: "/tmp/test-project", Name: "edits"})
+	resp, err := http.Post(srv.URL+"/threads", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("create thread failed: %v", err)
+	}
+	var thread models.FeatureThread
+	json.NewDecoder(resp.Body).Decode(&thread)
+	resp.Body.Close()
+
+	do := func(method, path string, v any, out any) int {
+		t.Helper()
+		var body bytes.Buffer
+		if v != nil {
+			json.NewEncoder(&body).Encode(v)
+		}
+		req, _ := http.NewRequest(method, srv.URL+"/threads/"+thread.ID+path, &body)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		if out != nil {
+			json.NewDecoder(resp.Body).Decode(out)
+		}
+		return resp.StatusCode
+	}
+
+	var appended models.AppendEntriesResponse
+	do(http.MethodPost, "/entries/batch", models.AppendEntriesRequest{
+		Workspace: "/tmp/test-project",
+		Entries: []models.AppendEntryRequest{
+			{Content: "Finding: the cahce is per process", Section: models.ThreadSectionFindings},
+			{Content: "Finding: a duplicate note", Section: models.ThreadSectionFindings},
+			{Content: "Todo: add a lock around refresh", Section: models.ThreadSectionFindings},
+		},
+	}, &appended)
+	if len(appended.Entries) != 3 {
+		t.Fatalf("expected 3 entries appended, got %+v", appended)
+	}
+	typo, dup, todo := appended.Entries[0], appended.Entries[1], appended.Entries[2]
+
+	fixed := "Finding: the cache is per process"
+	todoSection := models.ThreadSectionTodo
+	var updated models.ThreadEntry
+	if status := do(http.MethodPatch, "/entries/"+typo.ID, models.UpdateEntryRequest{Content: &fixed}, &updated); status != http.StatusOK {
+		t.Fatalf("update content: expected 200, got %d", status)
+	}
+	if updated.Content != fixed || updated.Section != models.ThreadSectionFindings {
+		t.Fatalf("unexpected entry after update: %+v", updated)
+	}
+	if status := do(http.MethodPatch, "/entries/"+todo.ID, models.UpdateEntryRequest{Section: &todoSection}, nil); status != http.StatusOK {
+		t.Fatalf("update section: expected 200, got %d", status)
+	}
+
+	var p api.Problem
+	if status := do(http.MethodPatch, "/entries/missing", models.UpdateEntryRequest{Content: &fixed}, &p); status != http.StatusNotFound || p.Code != "entry_not_found" {
+		t.Fatalf("expected 404 entry_not_found, got %d %+v", status, p)
+	}
+
+	if status := do(http.MethodDelete, "/entries/"+dup.ID, nil, nil); status != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d", status)
+	}
+
+	var got models.ThreadWithEntries
+	do(http.MethodGet, "", nil, &got)
+	if got.EntryCount != 2 || len(got.Entries) != 2 {
+		t.Fatalf("expected 2 entries after delete, got %d (%d listed)", got.EntryCount, len(got.Entries))
+	}
+	if e := got.Entries[0]; e.ID != typo.ID || e.Content != fixed || e.Sequence != 1 {
+		t.Fatalf("unexpected first entry: %+v", e)
+	}
+	if e := got.Entries[1]; e.ID != todo.ID || e.Section != models.ThreadSectionTodo || e.Sequence != 2 {
+		t.Fatalf("expected the todo entry resequenced to 2, got %+v", e)
+	}
+
+	// The backing memories follow their entries
+	resp, err = http.Get(srv.URL + "/memories/" + typo.MemoryID)
+	if err != nil {
+		t.Fatalf("get memory failed: %v", err)
+	}
+	var mem models.Memory
+	json.NewDecoder(resp.Body).Decode(&mem)
+	resp.Body.Close()
+	if mem.Content != fixed {
+		t.Fatalf("expected memory content updated, got %q", mem.Content)
+	}
+	resp, err = http.Get(srv.URL + "/memories/" + dup.MemoryID)
+	if err != nil {
+		t.Fatalf("get memory failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected deleted entry's memory gone, got %d", resp.StatusCode)
+	}
+
+	var edits models.EntryEditsResponse
+	do(http.MethodGet, "/edits", nil, &edits)
+	if len(edits.Edits) != 3 {
+		t.Fatalf("expected 3 edits logged, got %+v", edits.Edits)
+	}
+	if e := edits.Edits[0]; e.EntryID != typo.ID || e.Action != models.EntryEditUpdated || e.PreviousContent != typo.Content {
+		t.Fatalf("unexpected first edit: %+v", e)
+	}
+	if e := edits.Edits[2]; e.EntryID != dup.ID || e.Action != models.EntryEditDeleted || e.PreviousContent != dup.Content {
+		t.Fatalf("unexpected delete edit: %+v", e)
+	}
+
+	do(http.MethodPost, "/close", models.CloseThreadRequest{}, nil)
+	p = api.Problem{}
+	if status := do(http.MethodDelete, "/entries/"+todo.ID, nil, &p); status != http.StatusConflict || p.Code != "thread_closed" {
+		t.Fatalf("expected 409 thread_closed, got %d %+v", status, p)
+	}
+}
+
+// wordCounter prices text at one token per word, far below len/4.
+type wordCounter struct{}
+
+func (wordCounter) Count(text string) int { return len(strings.Fields(text)) }
+
+func TestThreadTokenUsageAcrossTokenizers(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	svc := threads.NewService(store.NewThreadStore(db), store.NewMemoryStore(db), store.NewWorkspaceStore(db), nil, 0, false, logger)
+
+	thread, err := svc.Create(ctx, &models.CreateThreadRequest{Workspace: "/tmp/test-project", Name: "tokens"})
+	if err != nil {
+		t.Fatalf("create thread: %v", err)
+	}
+	// Priced by the default len/4 heuristic
+	first, err := svc.AppendEntry(ctx, thread.ID, &models.AppendEntryRequest{Content: strings.Repeat("word ", 80)})
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	second, err := svc.AppendEntry(ctx, thread.ID, &models.AppendEntryRequest{Content: strings.Repeat("note ", 40)})
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	usage := func() int {
+		t.Helper()
+		got, err := svc.Get(ctx, thread.ID)
+		if err != nil {
+			t.Fatalf("get thread: %v", err)
+		}
+		return got.TokenUsage
+	}
+	if got := usage(); got != 150 {
+		t.Fatalf("expected 150 tokens, got %d", got)
+	}
+
+	// Edits and deletes after a tokenizer change undo what each entry added
+	svc.SetTokenizer(wordCounter{})
+	edited := "three words now"
+	if _, err := svc.UpdateEntry(ctx, thread.ID, first.ID, &models.UpdateEntryRequest{Content: &edited}); err != nil {
+		t.Fatalf("update entry: %v", err)
+	}
+	if got := usage(); got != 3+50 {
+		t.Fatalf("expected 53 tokens after the edit, got %d", got)
+	}
+	if err := svc.DeleteEntry(ctx, thread.ID, second.ID); err != nil {
+		t.Fatalf("delete entry: %v", err)
+	}
+	if got := usage(); got != 3 {
+		t.Fatalf("expected 3 tokens after the delete, got %d", got)
+	}
+}
+
+func TestSearchAnchoredToThread(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	body, _ := json.Marshal(models.CreateThreadRequest{Workspace: "/tmp/test-project", Name: "retries"})
+	resp, err := http.Post(srv.URL+"/threads", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("create thread failed: %v", err)
+	}
+	var thread models.FeatureThread
+	json.NewDecoder(resp.Body).Decode(&thread)
+	resp.Body.Close()
+
+	body, _ = json.Marshal(models.AppendEntryRequest{
+		Content: "Queue worker retries use exponential backoff capped at five attempts",
+		Section: models.ThreadSectionDecisions,
+	})
+	resp, err = http.Post(srv.URL+"/threads/"+thread.ID+"/entries", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("append failed: %v", err)
+	}
+	var entry models.ThreadEntry
+	json.NewDecoder(resp.Body).Decode(&entry)
+	resp.Body.Close()
+
+	for _, content := range []string{
+		"Queue worker retries are logged to the shared retries table",
+		"Queue worker retries must be idempotent across deploys",
+	} {
+		body, _ = json.Marshal(models.StoreRequest{
+			Workspace:  "/tmp/test-project",
+			Content:    content,
+			MemoryType: models.MemoryTypeGotcha,
+		})
+		resp, err = http.Post(srv.URL+"/memories", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("store failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	search := func(threadID string) (int, models.SearchResponse) {
+		t.Helper()
+		body, _ := json.Marshal(models.SearchRequest{
+			Workspace:  "/tmp/test-project",
+			Query:      "queue worker retries",
+			MaxResults: 10,
+			SearchMode: models.SearchModeBM25,
+			ThreadID:   threadID,
+		})
+		resp, err := http.Post(srv.URL+"/memories/search", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("search failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var out models.SearchResponse
+		json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+
+	// Nothing is linked yet, so only the entry itself is anchored
+	status, anchored := search(thread.ID)
+	if status != http.StatusOK || len(anchored.Results) != 3 {
+		t.Fatalf("expected 3 results with a thread, got %d %+v", status, anchored)
+	}
+	if top := anchored.Results[0]; top.ID != entry.MemoryID || !top.ThreadAnchored {
+		t.Fatalf("expected the thread entry to rank first and be anchored, got %+v", top)
+	}
+	for _, r := range anchored.Results[1:] {
+		if r.ThreadAnchored {
+			t.Fatalf("expected only the thread entry to be anchored, got %s", r.ID)
+		}
+	}
+
+	status, plain := search("")
+	if status != http.StatusOK || len(plain.Results) != 3 {
+		t.Fatalf("expected 3 results without a thread, got %d %+v", status, plain)
+	}
+	for _, r := range plain.Results {
+		if r.ThreadAnchored {
+			t.Fatalf("expected no anchored results without a thread, got %s", r.ID)
+		}
+	}
+
+	// Co-retrieval has now linked the other memories to the entry
+	_, anchored = search(thread.ID)
+	for _, r := range anchored.Results {
+		if !r.ThreadAnchored {
+			t.Fatalf("expected memories linked to the entry to be anchored, got %s", r.ID)
+		}
+	}
+
+	body, _ = json.Marshal(models.SearchRequest{Workspace: "/tmp/test-project", Query: "retries", ThreadID: "missing"})
+	resp, err = http.Post(srv.URL+"/memories/search", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	var p api.Problem
+	json.NewDecoder(resp.Body).Decode(&p)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound || p.Code != "thread_not_found" {
+		t.Fatalf("expected 404 thread_not_found, got %d %+v", resp.StatusCode, p)
+	}
+}
+
+func TestAPIVersioning(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	do := func(method, path, version, idemKey string, v any) *http.Response {
+		t.Helper()
+		var body io.Reader
+		if v != nil {
+			data, _ := json.Marshal(v)
+			body = bytes.NewReader(data)
+		}
+		req, _ := http.NewRequest(method, srv.URL+path, body)
+		req.Header.Set("Content-Type", "application/json")
+		if version != "" {
+			req.Header.Set("X-Clive-API-Version", version)
+		}
+		if idemKey != "" {
+			req.Header.Set("Idempotency-Key", idemKey)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	storeReq := models.StoreRequest{
+		Workspace:  "/tmp/test-project",
+		Content:    "Hook scripts call the unversioned API",
+		MemoryType: models.MemoryTypeContext,
+	}
+
+	// Legacy paths keep working but point at their successor
+	legacy := do(http.MethodPost, "/memories", "", "versioned-key", storeReq)
+	if legacy.StatusCode != http.StatusCreated {
+		t.Fatalf("expected legacy store to succeed, got %d", legacy.StatusCode)
+	}
+	if legacy.Header.Get("Deprecation") != "true" || legacy.Header.Get("Link") != `</v1/memories>; rel="successor-version"` {
+		t.Fatalf("expected deprecation headers, got %v", legacy.Header)
+	}
+	var stored models.StoreResponse
+	json.NewDecoder(legacy.Body).Decode(&stored)
+
+	// The versioned route serves the same data without deprecation headers
+	search := do(http.MethodPost, "/v1/memories/search", "", "", models.SearchRequest{
+		Workspace: "/tmp/test-project",
+		Query:     "unversioned API",
+	})
+	if search.StatusCode != http.StatusOK {
+		t.Fatalf("expected versioned search to succeed, got %d", search.StatusCode)
+	}
+	if search.Header.Get("Deprecation") != "" || search.Header.Get("X-Clive-API-Version") != "1" {
+		t.Fatalf("unexpected versioned headers: %v", search.Header)
+	}
+	var found models.SearchResponse
+	json.NewDecoder(search.Body).Decode(&found)
+	if len(found.Results) == 0 || found.Results[0].ID != stored.ID {
+		t.Fatalf("expected the legacy-stored memory to be found under /v1, got %+v", found.Results)
+	}
+
+	// A retry that moved to /v1 replays the original response
+	replay := do(http.MethodPost, "/v1/memories", "", "versioned-key", storeReq)
+	if replay.Header.Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected an idempotent replay across versions, got %d", replay.StatusCode)
+	}
+
+	if resp := do(http.MethodGet, "/v1/memories/"+stored.ID, "1", "", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected a matching version header to be accepted, got %d", resp.StatusCode)
+	}
+	if resp := do(http.MethodGet, "/memories/"+stored.ID, "v1", "", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected a legacy path to accept version 1, got %d", resp.StatusCode)
+	}
+
+	unsupported := do(http.MethodGet, "/v1/memories/"+stored.ID, "2", "", nil)
+	var p api.Problem
+	json.NewDecoder(unsupported.Body).Decode(&p)
+	if unsupported.StatusCode != http.StatusBadRequest || p.Code != "unsupported_api_version" {
+		t.Fatalf("expected 400 unsupported_api_version, got %d %+v", unsupported.StatusCode, p)
+	}
+
+	if resp := do(http.MethodGet, "/health", "", "", nil); resp.Header.Get("Deprecation") != "" {
+		t.Fatal("expected health probes to stay unversioned without deprecation")
+	}
+}
+
+func TestRecalculateImpactEndpoint(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	var ids []string
+	for _, content := range []string{"Retry budget is three attempts", "Queue consumers ack after commit", "Feature flags live in LaunchDarkly"} {
+		body, _ := json.Marshal(models.StoreRequest{Workspace: "/tmp/test-project", Content: content, MemoryType: models.MemoryTypeDecision})
+		resp, err := http.Post(srv.URL+"/memories", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("store failed: %v", err)
+		}
+		var sr models.StoreResponse
+		json.NewDecoder(resp.Body).Decode(&sr)
+		resp.Body.Close()
+		ids = append(ids, sr.ID)
+	}
+	body, _ := json.Marshal(models.RecordImpactRequest{Signal: models.SignalHelpful, Source: "test"})
+	resp, err := http.Post(srv.URL+"/memories/"+ids[0]+"/impact", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("record impact failed: %v", err)
+	}
+	resp.Body.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/memories/impact/recalculate?batch_size=2", nil)
+	req.Header.Set("Accept", "application/x-ndjson")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("recalculate failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf("expected an NDJSON stream, got %q", ct)
+	}
+
+	var lines []models.RecalculateImpactProgress
+	dec := json.NewDecoder(resp.Body)
+	for dec.More() {
+		var p models.RecalculateImpactProgress
+		if err := dec.Decode(&p); err != nil {
+			t.Fatalf("decode progress: %v", err)
+		}
+		lines = append(lines, p)
+	}
+	if len(lines) != 3 || lines[0].Scanned != 2 || lines[0].Done {
+		t.Fatalf("expected two progress lines and a result, got %+v", lines)
+	}
+	final := lines[len(lines)-1]
+	if !final.Done || final.Total != 3 || final.Scanned != 3 || final.Batches != 2 || final.Changed != 0 {
+		t.Fatalf("expected a consistent log to need no changes, got %+v", final)
+	}
+
+	resp, err = http.Post(srv.URL+"/memories/impact/recalculate?batch_size=0", "application/json", nil)
+	if err != nil {
+		t.Fatalf("recalculate failed: %v", err)
+	}
+	var p api.Problem
+	json.NewDecoder(resp.Body).Decode(&p)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || p.Code != "invalid_batch_size" {
+		t.Fatalf("expected 400 invalid_batch_size, got %d %+v", resp.StatusCode, p)
+	}
+}
@@ -0,0 +1,282 @@
+package tests
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
+	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/search"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
+)
+
+// lifecycleTest wires a service and compactor to a Qdrant stand-in that
+// keeps vectors, so memories can move between tiers both ways.
+type lifecycleTest struct {
+	t           *testing.T
+	points      *pointStore
+	memoryStore *store.MemoryStore
+	qdrant      *vectorstore.QdrantClient
+	collMgr     *vectorstore.CollectionManager
+	lifecycle   *memory.LifecycleManager
+	svc         *memory.Service
+	compactor   *memory.Compactor
+	wsID        string
+}
+
+func setupLifecycleTest(t *testing.T) (*lifecycleTest, func()) {
+	t.Helper()
+	db, cleanupDB := setupTestDB(t)
+	ollamaSrv := fakeOllamaServer()
+	points := &pointStore{collections: map[string]map[string]bool{}}
+	qdrantSrv := points.server()
+	cleanup := func() {
+		qdrantSrv.Close()
+		ollamaSrv.Close()
+		cleanupDB()
+	}
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	memoryStore := store.NewMemoryStore(db)
+	workspaceStore := store.NewWorkspaceStore(db)
+	bm25Store := store.NewBM25Store(db)
+	qdrantClient := vectorstore.NewQdrantClient(qdrantSrv.URL, 768)
+	collMgr := vectorstore.NewCollectionManager(qdrantClient)
+	embedder := embedding.NewCachedEmbedder(embedding.NewOllamaClient(ollamaSrv.URL, "nomic-embed-text"),
+		store.NewEmbeddingCacheStore(db), "nomic-embed-text", 768)
+	searcher := search.NewHybridSearcher(memoryStore, bm25Store, store.NewLinkStore(db), qdrantClient, collMgr, 0.7, 0.3, 1.2)
+	lifecycle := memory.NewLifecycleManager(memoryStore, qdrantClient, collMgr, 3, 0.85, logger)
+	svc := memory.NewService(
+		memoryStore, workspaceStore, bm25Store, embedder,
+		qdrantClient, collMgr, searcher, memory.NewDeduplicator(memoryStore, 0.92),
+		lifecycle, 72, logger,
+	)
+
+	wsID, err := workspaceStore.EnsureWorkspace(context.Background(), "default", "/tmp/lifecycle-project")
+	if err != nil {
+		cleanup()
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	return &lifecycleTest{
+		t:           t,
+		points:      points,
+		memoryStore: memoryStore,
+		qdrant:      qdrantClient,
+		collMgr:     collMgr,
+		lifecycle:   lifecycle,
+		svc:         svc,
+		compactor:   memory.NewCompactor(svc, db, store.NewCompactionStore(db), nil, nil, 0, logger),
+		wsID:        wsID,
+	}, cleanup
+}
+
+// lifecycleVector is a 768-dimension vector pointing along (lead, 1).
+func lifecycleVector(lead float32) []float32 {
+	v := make([]float32, 768)
+	v[0], v[1] = lead, 1
+	return v
+}
+
+// insert stores a memory ageDays old. Short-term memories keep vec in
+// SQLite; long-term ones have it upserted to Qdrant and, when promoted,
+// are marked as promoted by compaction.
+func (lt *lifecycleTest) insert(mem models.Memory, vec []float32, ageDays int, promoted bool) string {
+	lt.t.Helper()
+	ctx := context.Background()
+	now := time.Now().Unix()
+	created := now - int64(ageDays)*86400
+	expires := now + 3600
+	mem.ID = uuid.New().String()
+	if mem.WorkspaceID == "" {
+		mem.WorkspaceID = lt.wsID
+	}
+	if mem.MemoryType == "" {
+		mem.MemoryType = models.MemoryTypeDecision
+	}
+	if mem.Visibility == "" {
+		mem.Visibility = models.VisibilityPublic
+	}
+	mem.Content = "Lifecycle " + mem.ID
+	mem.Confidence = 0.5
+	mem.ContentHash = uuid.New().String()
+	mem.CreatedAt, mem.UpdatedAt = created, created
+	mem.Stability = 5
+	mem.Owner = "alice"
+	if mem.Tier == models.TierShort {
+		mem.Embedding = search.Float32ToBytes(vec)
+		mem.ExpiresAt = &expires
+	}
+	if err := lt.memoryStore.Insert(ctx, &mem); err != nil {
+		lt.t.Fatalf("insert memory: %v", err)
+	}
+	if mem.Tier == models.TierLong {
+		col, err := lt.collMgr.EnsureForPoint(ctx, mem.WorkspaceID, mem.ID)
+		if err != nil {
+			lt.t.Fatalf("ensure collection: %v", err)
+		}
+		if err := lt.qdrant.Upsert(ctx, col, []vectorstore.Point{{ID: mem.ID, Vector: vec}}); err != nil {
+			lt.t.Fatalf("upsert: %v", err)
+		}
+		if promoted {
+			if err := lt.memoryStore.Promote(ctx, mem.ID); err != nil {
+				lt.t.Fatalf("promote: %v", err)
+			}
+		}
+	}
+	return mem.ID
+}
+
+func TestCompactionDemotesAndConsolidates(t *testing.T) {
+	lt, cleanup := setupLifecycleTest(t)
+	defer cleanup()
+	ctx := context.Background()
+	lt.lifecycle.SetDemotion(true)
+	lt.lifecycle.SetConsolidation(true)
+
+	short, long := models.Memory{Tier: models.TierShort}, models.Memory{Tier: models.TierLong}
+	recalled := lt.insert(models.Memory{Tier: models.TierShort, AccessCount: 2}, lifecycleVector(1), 0, false)
+	duplicate := lt.insert(short, lifecycleVector(1.01), 0, false)
+	// As similar, but private: only memories with the same access control
+	// are consolidated
+	private := lt.insert(models.Memory{Tier: models.TierShort, Visibility: models.VisibilityPrivate}, lifecycleVector(0.99), 0, false)
+	unrelated := lt.insert(short, lifecycleVector(-1), 0, false)
+	faded := lt.insert(long, lifecycleVector(0.5), 60, true)
+	fresh := lt.insert(long, lifecycleVector(0.5), 0, true)
+	// Stored straight to long-term rather than promoted, as conventions
+	// and imports are
+	stored := lt.insert(long, lifecycleVector(0.5), 60, false)
+
+	events, unsubscribe := lt.svc.Subscribe(16)
+	defer unsubscribe()
+
+	report, err := lt.compactor.Run(ctx, memory.CompactTriggerManual)
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if report.Consolidated != 1 || report.Demoted != 1 || report.Promoted != 0 {
+		t.Fatalf("expected 1 consolidated and 1 demoted, got %+v", report.CompactResponse)
+	}
+
+	got, _ := lt.memoryStore.GetByID(ctx, duplicate)
+	if got.SupersededBy == nil || *got.SupersededBy != recalled {
+		t.Fatalf("expected the duplicate to be superseded by the recalled memory, got %v", got.SupersededBy)
+	}
+	for _, id := range []string{recalled, private, unrelated} {
+		if got, _ := lt.memoryStore.GetByID(ctx, id); got.SupersededBy != nil {
+			t.Fatalf("expected %s to be left alone, superseded by %s", id, *got.SupersededBy)
+		}
+	}
+
+	got, _ = lt.memoryStore.GetByID(ctx, faded)
+	if got.Tier != models.TierShort || got.ExpiresAt == nil || got.AccessCount != 0 {
+		t.Fatalf("expected the faded memory back in short-term with an expiry, got %+v", got)
+	}
+	if v := search.BytesToFloat32(got.Embedding); !slices.Equal(v, lifecycleVector(0.5)) {
+		t.Fatal("expected the faded memory's vector to move into SQLite")
+	}
+	for _, id := range []string{fresh, stored} {
+		if got, _ := lt.memoryStore.GetByID(ctx, id); got.Tier != models.TierLong {
+			t.Fatalf("expected %s to stay long-term, got tier %s", id, got.Tier)
+		}
+	}
+	col, _ := lt.collMgr.EnsureForPoint(ctx, lt.wsID, faded)
+	if ids := lt.points.ids(col); slices.Contains(ids, faded) || !slices.Contains(ids, fresh) {
+		t.Fatalf("expected only the demoted memory's point to be deleted, collection holds %v", ids)
+	}
+
+	seen := map[models.MemoryEventType]string{}
+	for len(events) > 0 {
+		e := <-events
+		seen[e.Type] = e.MemoryID
+	}
+	if seen[models.MemoryEventDemoted] != faded || seen[models.MemoryEventSuperseded] != duplicate {
+		t.Fatalf("expected demoted and superseded events, got %v", seen)
+	}
+
+	history, err := lt.compactor.History(ctx, 1)
+	if err != nil || len(history) != 1 {
+		t.Fatalf("history: %v %v", history, err)
+	}
+	if history[0].Demoted != 1 || history[0].Consolidated != 1 {
+		t.Fatalf("expected the counts in the history, got %+v", history[0].CompactResponse)
+	}
+	text := memory.FormatCompactionReport(history[0])
+	for _, line := range []string{"Consolidated into near-duplicates: 1", "Demoted to short-term: 1"} {
+		if !strings.Contains(text, line) {
+			t.Fatalf("report is missing %q:\n%s", line, text)
+		}
+	}
+}
+
+func TestCompactionKeepsSkillHintsAndGlobalMemories(t *testing.T) {
+	lt, cleanup := setupLifecycleTest(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	skillHint := func(promoted bool) string {
+		return lt.insert(models.Memory{
+			WorkspaceID: models.GlobalWorkspaceID,
+			MemoryType:  models.MemoryTypeSkillHint,
+			Tier:        models.TierLong,
+			Source:      "skill-sync",
+		}, lifecycleVector(0.5), 60, promoted)
+	}
+	faded := lt.insert(models.Memory{Tier: models.TierLong}, lifecycleVector(0.5), 60, true)
+	duplicate := lt.insert(models.Memory{Tier: models.TierShort}, lifecycleVector(1), 0, false)
+	lt.insert(models.Memory{Tier: models.TierShort}, lifecycleVector(1.01), 0, false)
+
+	// Off by default: nothing is demoted or consolidated
+	hint := skillHint(false)
+	report, err := lt.compactor.Run(ctx, memory.CompactTriggerManual)
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if report.Demoted != 0 || report.Consolidated != 0 {
+		t.Fatalf("expected demotion and consolidation off by default, got %+v", report.CompactResponse)
+	}
+	for _, id := range []string{hint, faded} {
+		if got, _ := lt.memoryStore.GetByID(ctx, id); got == nil || got.Tier != models.TierLong {
+			t.Fatalf("expected %s to stay long-term, got %+v", id, got)
+		}
+	}
+	if got, _ := lt.memoryStore.GetByID(ctx, duplicate); got.SupersededBy != nil {
+		t.Fatalf("expected no consolidation by default, superseded by %s", *got.SupersededBy)
+	}
+
+	// Turned on, skill hints and global memories are still left alone, even
+	// ones compaction promoted
+	lt.lifecycle.SetDemotion(true)
+	lt.lifecycle.SetConsolidation(true)
+	promotedHint := skillHint(true)
+	global := lt.insert(models.Memory{WorkspaceID: models.GlobalWorkspaceID, Tier: models.TierLong},
+		lifecycleVector(0.5), 60, true)
+	globalShort := lt.insert(models.Memory{WorkspaceID: models.GlobalWorkspaceID, Tier: models.TierShort},
+		lifecycleVector(-1), 0, false)
+	lt.insert(models.Memory{WorkspaceID: models.GlobalWorkspaceID, Tier: models.TierShort},
+		lifecycleVector(-1.01), 0, false)
+
+	report, err = lt.compactor.Run(ctx, memory.CompactTriggerManual)
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if report.Demoted != 1 || report.Consolidated != 1 {
+		t.Fatalf("expected only the project memories demoted and consolidated, got %+v", report.CompactResponse)
+	}
+	for _, id := range []string{hint, promotedHint, global} {
+		if got, _ := lt.memoryStore.GetByID(ctx, id); got == nil || got.Tier != models.TierLong {
+			t.Fatalf("expected %s to stay long-term, got %+v", id, got)
+		}
+	}
+	if got, _ := lt.memoryStore.GetByID(ctx, globalShort); got.SupersededBy != nil {
+		t.Fatalf("expected global memories not to be consolidated, superseded by %s", *got.SupersededBy)
+	}
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+func TestCompactionVacuumsDatabase(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	var ids []string
+	for i := 0; i < 40; i++ {
+		body, _ := json.Marshal(models.StoreRequest{
+			Workspace:  "/tmp/test-project",
+			Content:    fmt.Sprintf("Bulky note %d: %s", i, strings.Repeat("padding text ", 400)),
+			MemoryType: models.MemoryTypeContext,
+		})
+		resp, err := http.Post(srv.URL+"/memories", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("store failed: %v", err)
+		}
+		var stored models.StoreResponse
+		json.NewDecoder(resp.Body).Decode(&stored)
+		resp.Body.Close()
+		ids = append(ids, stored.ID)
+	}
+	for _, id := range ids {
+		req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/memories/"+id, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("delete failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := http.Post(srv.URL+"/memories/compact", "application/json", nil)
+	if err != nil {
+		t.Fatalf("compact failed: %v", err)
+	}
+	var report models.CompactionReport
+	json.NewDecoder(resp.Body).Decode(&report)
+	resp.Body.Close()
+	if report.ReclaimedBytes <= 0 {
+		t.Fatalf("expected vacuum to reclaim space, got %+v", report)
+	}
+
+	resp, err = http.Get(srv.URL + "/stats/db")
+	if err != nil {
+		t.Fatalf("db stats failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var stats models.DBStats
+	json.NewDecoder(resp.Body).Decode(&stats)
+	if stats.FileBytes <= 0 || stats.UsedBytes <= 0 || stats.PageSize <= 0 {
+		t.Fatalf("expected database size to be reported, got %+v", stats)
+	}
+	if stats.FreeBytes != 0 {
+		t.Fatalf("expected no free pages after vacuum, got %d bytes", stats.FreeBytes)
+	}
+}
+
+func TestLegacyDatabaseSwitchesToIncrementalVacuum(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+
+	// A database created before incremental auto-vacuum
+	legacy, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := legacy.Exec("CREATE TABLE legacy (x TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	legacy.Close()
+
+	db, err := store.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open legacy database: %v", err)
+	}
+	defer db.Close()
+
+	// Opening must not rewrite the file; the switch waits for maintenance
+	var mode int
+	db.QueryRow("PRAGMA auto_vacuum").Scan(&mode)
+	if mode == 2 {
+		t.Fatal("expected startup to leave the legacy auto_vacuum mode alone")
+	}
+
+	result, err := db.Vacuum(context.Background())
+	if err != nil {
+		t.Fatalf("vacuum: %v", err)
+	}
+	if !result.FullVacuum {
+		t.Fatalf("expected the first vacuum to be a full one, got %+v", result)
+	}
+	db.QueryRow("PRAGMA auto_vacuum").Scan(&mode)
+	if mode != 2 {
+		t.Fatalf("expected incremental auto_vacuum after maintenance, got %d", mode)
+	}
+
+	result, err = db.Vacuum(context.Background())
+	if err != nil {
+		t.Fatalf("second vacuum: %v", err)
+	}
+	if result.FullVacuum {
+		t.Fatal("expected later vacuums to stay incremental")
+	}
+}
+
+func TestSizeAlertWebhook(t *testing.T) {
+	received := make(chan map[string]any, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload
+	}))
+	defer hook.Close()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	notifier := memory.NewReportNotifier(hook.URL, memory.ReportMail{}, logger)
+	notifier.Alert(&models.DBStats{
+		FileBytes:      3 << 20,
+		UsedBytes:      2 << 20,
+		ThresholdBytes: 2 << 20,
+		OverThreshold:  true,
+	})
+
+	payload := <-received
+	text, _ := payload["text"].(string)
+	if !strings.Contains(text, "over size threshold: 3.0 MB of 2.0 MB") {
+		t.Fatalf("unexpected alert text:\n%s", text)
+	}
+	if _, ok := payload["dbStats"].(map[string]any); !ok {
+		t.Fatalf("expected structured stats in payload, got %v", payload)
+	}
+}
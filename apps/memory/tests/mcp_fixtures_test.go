@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/mcp"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+func TestMCPFixtureMode(t *testing.T) {
+	// No memory server is running at this URL; every call must hit fixtures.
+	server := mcp.NewServer("http://127.0.0.1:1", "")
+	if err := server.UseFixtures(filepath.Join("..", "fixtures", "mcp")); err != nil {
+		t.Fatalf("use fixtures: %v", err)
+	}
+
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"memory_search_index","arguments":{"workspace":"/tmp/x","query":"fts5"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"memory_store","arguments":{"workspace":"/tmp/x","content":"c","memoryType":"GOTCHA"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"memory_unknown","arguments":{}}}`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := server.Serve(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+
+	var results []mcp.CallToolResult
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var resp struct {
+			Result mcp.CallToolResult `json:"result"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		results = append(results, resp.Result)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 responses, got %d", len(results))
+	}
+
+	var search models.SearchIndexResponse
+	if err := json.Unmarshal([]byte(results[0].Content[0].Text), &search); err != nil || results[0].IsError {
+		t.Fatalf("expected search fixture, got %q (%v)", results[0].Content[0].Text, err)
+	}
+	if len(search.Results) != 2 {
+		t.Fatalf("expected 2 fixture results, got %d", len(search.Results))
+	}
+
+	want, _ := os.ReadFile(filepath.Join("..", "fixtures", "mcp", "memory_store.json"))
+	if results[1].IsError || results[1].Content[0].Text != string(want) {
+		t.Fatalf("expected store fixture, got %q", results[1].Content[0].Text)
+	}
+
+	if !results[2].IsError {
+		t.Fatal("expected unknown tool to be an error in fixture mode")
+	}
+}
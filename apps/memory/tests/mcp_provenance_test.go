@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/mcp"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+func TestMCPStoreProvenance(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	server := mcp.NewServer(srv.URL, "")
+	server.SetDefaultEpic("CLIVE-42")
+
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"memory_store","arguments":{"workspace":"/tmp/test-project","content":"The FTS index must be rebuilt after bulk imports","memoryType":"GOTCHA","provenance":{"taskId":"CLIVE-43","files":["internal/store/bm25.go"],"commitSha":"ABC1234"}}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"memory_store","arguments":{"workspace":"/tmp/test-project","content":"Bad provenance","memoryType":"GOTCHA","provenance":{"commitSha":"not-a-sha"}}}}`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := server.Serve(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+
+	var results []mcp.CallToolResult
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var resp struct {
+			Result mcp.CallToolResult `json:"result"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		results = append(results, resp.Result)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 responses, got %d", len(results))
+	}
+
+	var stored models.StoreResponse
+	if err := json.Unmarshal([]byte(results[0].Content[0].Text), &stored); err != nil || results[0].IsError {
+		t.Fatalf("store failed: %q (%v)", results[0].Content[0].Text, err)
+	}
+
+	resp, err := http.Get(srv.URL + "/memories/" + stored.ID)
+	if err != nil {
+		t.Fatalf("get memory failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var mem models.Memory
+	json.NewDecoder(resp.Body).Decode(&mem)
+
+	ctx := mem.EncodingContext
+	if ctx == nil || ctx.TaskID != "CLIVE-43" || ctx.EpicID != "CLIVE-42" || ctx.CommitSHA != "abc1234" {
+		t.Fatalf("expected provenance in encoding context, got %+v", ctx)
+	}
+	if len(mem.RelatedFiles) != 1 || mem.RelatedFiles[0] != "internal/store/bm25.go" {
+		t.Fatalf("expected provenance files in related files, got %v", mem.RelatedFiles)
+	}
+
+	if !results[1].IsError || !strings.HasPrefix(results[1].Content[0].Text, "invalid_commit_sha:") {
+		t.Fatalf("expected invalid_commit_sha error, got %q", results[1].Content[0].Text)
+	}
+}
@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/mcp"
+)
+
+func TestMCPBackendReconnect(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	// Reserve an address, then leave it closed to simulate a restarting backend
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	server := mcp.NewServer("http://"+addr, "")
+	server.SetRetry(2, 10*time.Millisecond)
+
+	call := func(id int, tool, args string) mcp.CallToolResult {
+		t.Helper()
+		in := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":%q,"arguments":%s}}`, id, tool, args)
+		var out bytes.Buffer
+		if err := server.Serve(strings.NewReader(in), &out); err != nil {
+			t.Fatalf("serve: %v", err)
+		}
+		var resp struct {
+			Result mcp.CallToolResult `json:"result"`
+		}
+		if err := json.Unmarshal(out.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return resp.Result
+	}
+	status := func() mcp.BackendStatus {
+		t.Helper()
+		var st mcp.BackendStatus
+		res := call(99, "memory_status", `{}`)
+		if res.IsError || json.Unmarshal([]byte(res.Content[0].Text), &st) != nil {
+			t.Fatalf("memory_status failed: %q", res.Content[0].Text)
+		}
+		return st
+	}
+	searchArgs := `{"workspace":"/tmp/reconnect-project","query":"anything"}`
+
+	res := call(1, "memory_search_index", searchArgs)
+	if !res.IsError || !strings.HasPrefix(res.Content[0].Text, "backend_unavailable:") {
+		t.Fatalf("expected backend_unavailable while down, got %q", res.Content[0].Text)
+	}
+	if st := status(); st.Connected || st.DownSince == 0 || st.ConsecutiveFailures < 3 {
+		t.Fatalf("expected a disconnected status, got %+v", st)
+	}
+
+	// The backend comes back while a call is backing off
+	target, _ := url.Parse(srv.URL)
+	restarted := make(chan net.Listener, 1)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			close(restarted)
+			return
+		}
+		restarted <- ln
+		http.Serve(ln, httputil.NewSingleHostReverseProxy(target))
+	}()
+	defer func() {
+		if ln, ok := <-restarted; ok {
+			ln.Close()
+		}
+	}()
+	server.SetRetry(20, 25*time.Millisecond)
+
+	if res := call(2, "memory_search_index", searchArgs); res.IsError {
+		t.Fatalf("expected the call to succeed once the backend returned, got %q", res.Content[0].Text)
+	}
+	if st := status(); !st.Connected || st.Health == "" || st.ConsecutiveFailures != 0 || st.DownSince != 0 {
+		t.Fatalf("expected a connected status, got %+v", st)
+	}
+}
+
+func TestMCPSendsAPIKey(t *testing.T) {
+	seen := make(map[string]string)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen[r.URL.Path] = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/v1/attachments":
+			w.Write([]byte(`{"attachments":[]}`))
+		default:
+			w.Write([]byte(`{"results":[]}`))
+		}
+	}))
+	defer backend.Close()
+
+	server := mcp.NewServer(backend.URL, "")
+	server.SetAPIKey("sk-mcp")
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"memory_search_index","arguments":{"workspace":"/tmp/p","query":"auth"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"resources/list","params":{}}`,
+	}, "\n")
+	var out bytes.Buffer
+	if err := server.Serve(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+
+	// Tool calls and resource reads both authenticate
+	for _, path := range []string{"/v1/memories/search/index", "/v1/attachments"} {
+		if got := seen[path]; got != "Bearer sk-mcp" {
+			t.Fatalf("expected %s to carry the bearer token, got %q (seen %v)", path, got, seen)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	post := func(path string, v any) {
+		t.Helper()
+		body, _ := json.Marshal(v)
+		resp, err := http.Post(srv.URL+path, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			t.Fatalf("POST %s: got %d", path, resp.StatusCode)
+		}
+	}
+
+	store := models.StoreRequest{
+		Workspace:  "/tmp/metrics-project",
+		Content:    "Run migrations before starting the API server locally",
+		MemoryType: models.MemoryTypeWorkingSolution,
+		Tier:       models.TierShort,
+		Confidence: 0.8,
+	}
+	post("/memories", store)
+	// The same content again is deduplicated
+	post("/memories", store)
+	post("/memories/search", models.SearchRequest{
+		Workspace:  store.Workspace,
+		Query:      "starting the API server",
+		MaxResults: 5,
+		SearchMode: models.SearchModeHybrid,
+	})
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("metrics failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("expected text/plain, got %q", ct)
+	}
+	body, _ := io.ReadAll(resp.Body)
+
+	// Counters are process-wide, so only check the series exist; the tier
+	// gauge is read from this server's database.
+	for _, line := range []string{
+		`clive_search_duration_seconds_count{mode="hybrid"}`,
+		`clive_search_duration_seconds_bucket{mode="hybrid",le="+Inf"}`,
+		`clive_embedding_cache_lookups_total{result="hit"}`,
+		`clive_memory_stores_total{result="deduplicated"}`,
+		`clive_memory_stores_total{result="stored"}`,
+		`clive_qdrant_upserts_total{outcome="success"}`,
+		`clive_memories{tier="short"} 1`,
+		`clive_memories{tier="long"} 0`,
+	} {
+		if !strings.Contains(string(body), line) {
+			t.Errorf("expected %q in metrics:\n%s", line, body)
+		}
+	}
+}
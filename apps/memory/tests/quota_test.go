@@ -0,0 +1,243 @@
+package tests
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
+	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/search"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+	"github.com/iammorganparry/clive/apps/memory/internal/threads"
+	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
+)
+
+// quotaTestService builds a memory service over db with Ollama at
+// ollamaURL and Qdrant at qdrantURL.
+func quotaTestService(t *testing.T, db *store.DB, ollamaURL, qdrantURL string) (*memory.Service, *store.MemoryStore, *store.WorkspaceStore) {
+	t.Helper()
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	memoryStore := store.NewMemoryStore(db)
+	workspaceStore := store.NewWorkspaceStore(db)
+	bm25Store := store.NewBM25Store(db)
+	qdrantClient := vectorstore.NewQdrantClient(qdrantURL, 768)
+	collMgr := vectorstore.NewCollectionManager(qdrantClient)
+	embedder := embedding.NewCachedEmbedder(embedding.NewOllamaClient(ollamaURL, "nomic-embed-text"),
+		store.NewEmbeddingCacheStore(db), "nomic-embed-text", 768)
+	searcher := search.NewHybridSearcher(memoryStore, bm25Store, store.NewLinkStore(db), qdrantClient, collMgr, 0.7, 0.3, 1.2)
+	svc := memory.NewService(
+		memoryStore, workspaceStore, bm25Store, embedder,
+		qdrantClient, collMgr, searcher, memory.NewDeduplicator(memoryStore, 0.92),
+		memory.NewLifecycleManager(memoryStore, qdrantClient, collMgr, 3, 0.85, logger),
+		72, logger,
+	)
+	return svc, memoryStore, workspaceStore
+}
+
+// staleMemory inserts a memory nobody has touched in months, the first
+// to go when a quota evicts.
+func staleMemory(t *testing.T, memoryStore *store.MemoryStore, wsID string) *models.Memory {
+	t.Helper()
+	lastAccessed := time.Now().Add(-120 * 24 * time.Hour).Unix()
+	stale := &models.Memory{
+		ID:             uuid.New().String(),
+		WorkspaceID:    wsID,
+		Content:        "Old note about a deleted service",
+		MemoryType:     models.MemoryTypeContext,
+		Tier:           models.TierShort,
+		Confidence:     0.8,
+		ContentHash:    uuid.New().String(),
+		CreatedAt:      lastAccessed,
+		UpdatedAt:      lastAccessed,
+		Stability:      1,
+		LastAccessedAt: &lastAccessed,
+	}
+	if err := memoryStore.Insert(context.Background(), stale); err != nil {
+		t.Fatalf("insert stale memory: %v", err)
+	}
+	return stale
+}
+
+func TestWorkspaceQuota(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ollamaSrv := fakeOllamaServer()
+	defer ollamaSrv.Close()
+	qdrantSrv := fakeQdrantServer()
+	defer qdrantSrv.Close()
+	svc, memoryStore, workspaceStore := quotaTestService(t, db, ollamaSrv.URL, qdrantSrv.URL)
+
+	ctx := context.Background()
+	storeMem := func(content string, global bool) (*models.StoreResponse, error) {
+		return svc.Store(ctx, &models.StoreRequest{
+			Workspace:  "/tmp/quota-project",
+			Content:    content,
+			MemoryType: models.MemoryTypeContext,
+			Global:     global,
+		})
+	}
+
+	wsID, err := workspaceStore.EnsureWorkspace(ctx, "default", "/tmp/quota-project")
+	if err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	stale := staleMemory(t, memoryStore, wsID)
+	if _, err := storeMem("Builds run on the shared runner pool", false); err != nil {
+		t.Fatalf("store: %v", err)
+	}
+
+	svc.SetQuota(memory.Quota{MaxMemories: 2, Overflow: memory.QuotaReject})
+
+	_, err = storeMem("Deploys need the staging VPN", false)
+	if !apperr.Is(err, apperr.KindQuotaExceeded) {
+		t.Fatalf("expected quota_exceeded for a full workspace, got %v", err)
+	}
+	if _, err := storeMem("Global memories are not subject to quotas", true); err != nil {
+		t.Fatalf("expected a global store to ignore the quota, got %v", err)
+	}
+
+	stats, err := svc.GetWorkspaceStats(ctx, wsID)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.Quota == nil || stats.Quota.Memories != 2 || stats.Quota.MaxMemories != 2 || stats.Quota.Overflow != "reject" {
+		t.Fatalf("unexpected quota usage: %+v", stats.Quota)
+	}
+	if stats.ContentBytes == 0 || stats.Quota.Bytes != stats.ContentBytes {
+		t.Fatalf("expected content bytes in stats, got %d", stats.ContentBytes)
+	}
+
+	svc.SetQuota(memory.Quota{MaxMemories: 2, MaxBytes: 200, Overflow: memory.QuotaEvict})
+
+	resp, err := storeMem("Deploys need the staging VPN", false)
+	if err != nil {
+		t.Fatalf("expected eviction to make room, got %v", err)
+	}
+	if resp.Evicted != 1 {
+		t.Fatalf("expected one memory evicted, got %d", resp.Evicted)
+	}
+	if got, _ := memoryStore.GetByID(ctx, stale.ID); got != nil {
+		t.Fatal("expected the least retrievable memory to be evicted")
+	}
+
+	// Nothing can be evicted to fit a memory bigger than the whole quota
+	_, err = storeMem(strings.Repeat("x", 201), false)
+	if !apperr.Is(err, apperr.KindQuotaExceeded) {
+		t.Fatalf("expected quota_exceeded for an oversized memory, got %v", err)
+	}
+}
+
+func TestQuotaEvictsOnlyAfterInsert(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	var failUpserts atomic.Bool
+	fake := fakeQdrantServer()
+	defer fake.Close()
+	qdrantSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failUpserts.Load() && r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/points") {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer qdrantSrv.Close()
+	ollamaSrv := fakeOllamaServer()
+	defer ollamaSrv.Close()
+	svc, memoryStore, workspaceStore := quotaTestService(t, db, ollamaSrv.URL, qdrantSrv.URL)
+
+	wsID, err := workspaceStore.EnsureWorkspace(ctx, "default", "/tmp/quota-project")
+	if err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	stale := staleMemory(t, memoryStore, wsID)
+	svc.SetQuota(memory.Quota{MaxMemories: 1, Overflow: memory.QuotaEvict})
+
+	storeLong := func() (*models.StoreResponse, error) {
+		return svc.Store(context.Background(), &models.StoreRequest{
+			Workspace:  "/tmp/quota-project",
+			Content:    "Vectors for long-term memories live in Qdrant",
+			MemoryType: models.MemoryTypeDecision,
+			Tier:       models.TierLong,
+		})
+	}
+
+	failUpserts.Store(true)
+	if _, err := storeLong(); err == nil {
+		t.Fatal("expected the store to fail while Qdrant is down")
+	}
+	if got, _ := memoryStore.GetByID(ctx, stale.ID); got == nil {
+		t.Fatal("a failed store must not evict anything")
+	}
+
+	failUpserts.Store(false)
+	resp, err := storeLong()
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if resp.Evicted != 1 {
+		t.Fatalf("expected one memory evicted, got %d", resp.Evicted)
+	}
+	if got, _ := memoryStore.GetByID(ctx, stale.ID); got != nil {
+		t.Fatal("expected the stale memory to be evicted")
+	}
+}
+
+func TestQuotaCountsThreadEntries(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ollamaSrv := fakeOllamaServer()
+	defer ollamaSrv.Close()
+	qdrantSrv := fakeQdrantServer()
+	defer qdrantSrv.Close()
+	svc, memoryStore, workspaceStore := quotaTestService(t, db, ollamaSrv.URL, qdrantSrv.URL)
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	threadSvc := threads.NewService(store.NewThreadStore(db), memoryStore, workspaceStore, nil, 0, false, logger)
+
+	thread, err := threadSvc.Create(ctx, &models.CreateThreadRequest{Workspace: "/tmp/quota-project", Name: "quota"})
+	if err != nil {
+		t.Fatalf("create thread: %v", err)
+	}
+	stale := staleMemory(t, memoryStore, thread.WorkspaceID)
+	svc.SetQuota(memory.Quota{MaxMemories: 2, Overflow: memory.QuotaReject})
+
+	append := func(content string) error {
+		_, err := threadSvc.AppendEntry(ctx, thread.ID, &models.AppendEntryRequest{Content: content})
+		return err
+	}
+	if err := append("Finding: the first entry fits"); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := append("Finding: the second entry does not"); !apperr.Is(err, apperr.KindQuotaExceeded) {
+		t.Fatalf("expected quota_exceeded for an entry over quota, got %v", err)
+	}
+
+	svc.SetQuota(memory.Quota{MaxMemories: 2, Overflow: memory.QuotaEvict})
+	if err := append("Finding: eviction makes room for entries"); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if got, _ := memoryStore.GetByID(ctx, stale.ID); got != nil {
+		t.Fatal("expected the stale memory evicted for the entry")
+	}
+
+	// Entries are never evicted, so with only entries left nothing fits
+	if err := append("Finding: no room left"); !apperr.Is(err, apperr.KindQuotaExceeded) {
+		t.Fatalf("expected quota_exceeded with only thread entries left, got %v", err)
+	}
+	count, _, err := memoryStore.WorkspaceUsage(ctx, thread.WorkspaceID)
+	if err != nil || count != 2 {
+		t.Fatalf("expected 2 memories in the workspace, got %d (%v)", count, err)
+	}
+}
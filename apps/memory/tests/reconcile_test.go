@@ -0,0 +1,285 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
+	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/search"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
+)
+
+// pointStore is a Qdrant stand-in that remembers which point IDs each
+// collection holds, and pages scrolls two points at a time. Searches match
+// every point, filtered like scrolls on the workspace_id payload.
+type pointStore struct {
+	mu          sync.Mutex
+	collections map[string]map[string]bool
+	workspaces  map[string]string    // point ID -> workspace_id payload
+	vectors     map[string][]float32 // point ID -> vector, for upserted points
+}
+
+// matching returns a collection's point IDs from offset on, keeping those
+// whose workspace is in a request's workspace_id filter, if it has one.
+func (p *pointStore) matching(collection, offset string, filter json.RawMessage) []string {
+	var f struct {
+		Must []struct {
+			Match struct {
+				Any []string `json:"any"`
+			} `json:"match"`
+		} `json:"must"`
+	}
+	json.Unmarshal(filter, &f)
+	var ids []string
+	for id := range p.collections[collection] {
+		if id < offset {
+			continue
+		}
+		if len(f.Must) > 0 && !slices.Contains(f.Must[0].Match.Any, p.workspaces[id]) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (p *pointStore) ids(collection string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var ids []string
+	for id := range p.collections[collection] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (p *pointStore) server() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/collections":
+			var cols []map[string]string
+			for name := range p.collections {
+				cols = append(cols, map[string]string{"name": name})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"result": map[string]any{"collections": cols}})
+		case r.Method == http.MethodGet && len(parts) == 2:
+			if p.collections[parts[1]] == nil {
+				http.NotFound(w, r)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+		case r.Method == http.MethodPut && len(parts) == 2:
+			if p.collections[parts[1]] == nil {
+				p.collections[parts[1]] = map[string]bool{}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+		case r.Method == http.MethodDelete && len(parts) == 2:
+			delete(p.collections, parts[1])
+			json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+		case r.Method == http.MethodPut && len(parts) == 3:
+			var req struct {
+				Points []struct {
+					ID      string    `json:"id"`
+					Vector  []float32 `json:"vector"`
+					Payload struct {
+						WorkspaceID string `json:"workspace_id"`
+					} `json:"payload"`
+				} `json:"points"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			for _, pt := range req.Points {
+				p.collections[parts[1]][pt.ID] = true
+				if p.workspaces == nil {
+					p.workspaces = map[string]string{}
+				}
+				p.workspaces[pt.ID] = pt.Payload.WorkspaceID
+				if p.vectors == nil {
+					p.vectors = map[string][]float32{}
+				}
+				p.vectors[pt.ID] = pt.Vector
+			}
+			json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+		case r.Method == http.MethodPost && len(parts) == 3:
+			var req struct {
+				IDs []string `json:"ids"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			points := []map[string]any{}
+			for _, id := range req.IDs {
+				if p.collections[parts[1]][id] {
+					points = append(points, map[string]any{"id": id, "vector": p.vectors[id]})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"result": points})
+		case r.Method == http.MethodPost && len(parts) == 4 && parts[3] == "scroll":
+			var req struct {
+				Offset string          `json:"offset"`
+				Filter json.RawMessage `json:"filter"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			ids := p.matching(parts[1], req.Offset, req.Filter)
+			var next any
+			if len(ids) > 2 {
+				next, ids = ids[2], ids[:2]
+			}
+			points := make([]map[string]string, len(ids))
+			for i, id := range ids {
+				points[i] = map[string]string{"id": id}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"result": map[string]any{"points": points, "next_page_offset": next}})
+		case r.Method == http.MethodPost && len(parts) == 4 && parts[3] == "delete":
+			var req struct {
+				Points []string `json:"points"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			for _, id := range req.Points {
+				delete(p.collections[parts[1]], id)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+		case r.Method == http.MethodPost && len(parts) == 4 && parts[3] == "search":
+			var req struct {
+				Filter json.RawMessage `json:"filter"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			results := []map[string]any{}
+			for _, id := range p.matching(parts[1], "", req.Filter) {
+				results = append(results, map[string]any{"id": id, "score": 0.9})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"result": results})
+		case r.Method == http.MethodPost:
+			json.NewEncoder(w).Encode(map[string]any{"result": []any{}})
+		default:
+			json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+		}
+	}))
+}
+
+func TestReconcileVectors(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ollamaSrv := fakeOllamaServer()
+	defer ollamaSrv.Close()
+	points := &pointStore{collections: map[string]map[string]bool{}}
+	qdrantSrv := points.server()
+	defer qdrantSrv.Close()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	memoryStore := store.NewMemoryStore(db)
+	workspaceStore := store.NewWorkspaceStore(db)
+	bm25Store := store.NewBM25Store(db)
+	qdrantClient := vectorstore.NewQdrantClient(qdrantSrv.URL, 768)
+	collMgr := vectorstore.NewCollectionManager(qdrantClient)
+	embedder := embedding.NewCachedEmbedder(embedding.NewOllamaClient(ollamaSrv.URL, "nomic-embed-text"),
+		store.NewEmbeddingCacheStore(db), "nomic-embed-text", 768)
+	searcher := search.NewHybridSearcher(memoryStore, bm25Store, store.NewLinkStore(db), qdrantClient, collMgr, 0.7, 0.3, 1.2)
+	svc := memory.NewService(
+		memoryStore, workspaceStore, bm25Store, embedder,
+		qdrantClient, collMgr, searcher, memory.NewDeduplicator(memoryStore, 0.92),
+		memory.NewLifecycleManager(memoryStore, qdrantClient, collMgr, 3, 0.85, logger),
+		72, logger,
+	)
+
+	wsID, err := workspaceStore.EnsureWorkspace(context.Background(), "default", "/tmp/reconcile-project")
+	if err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	collection, err := collMgr.EnsureForWorkspace(context.Background(), wsID)
+	if err != nil {
+		t.Fatalf("ensure collection: %v", err)
+	}
+	insert := func(tier models.Tier, withPoint bool) string {
+		now := time.Now().Unix()
+		mem := &models.Memory{
+			ID:          uuid.New().String(),
+			WorkspaceID: wsID,
+			Content:     "Reconcile " + uuid.New().String(),
+			MemoryType:  models.MemoryTypeDecision,
+			Tier:        tier,
+			Confidence:  0.9,
+			ContentHash: uuid.New().String(),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := memoryStore.Insert(context.Background(), mem); err != nil {
+			t.Fatalf("insert memory: %v", err)
+		}
+		if withPoint {
+			points.collections[collection][mem.ID] = true
+		}
+		return mem.ID
+	}
+
+	healthy := insert(models.TierLong, true)
+	lost := insert(models.TierLong, false)
+	superseded := insert(models.TierLong, true)
+	if err := memoryStore.Supersede(context.Background(), superseded, healthy); err != nil {
+		t.Fatalf("supersede: %v", err)
+	}
+	insert(models.TierShort, false)
+	deleted := uuid.New().String()
+	points.collections[collection][deleted] = true
+	// A collection for a workspace with nothing left in SQLite
+	stale := vectorstore.CollectionName(uuid.New().String(), 768)
+	points.collections[stale] = map[string]bool{uuid.New().String(): true}
+
+	ctx := context.Background()
+	dry, err := svc.ReconcileVectors(ctx, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if dry.Orphans != 3 || dry.Missing != 1 || dry.Deleted != 0 || dry.Reupserted != 0 {
+		t.Fatalf("dry run: expected 3 orphans and 1 missing untouched, got %+v", dry)
+	}
+	if len(dry.Collections) != 2 {
+		t.Fatalf("expected drift for 2 collections, got %+v", dry.Collections)
+	}
+	if got := len(points.ids(collection)); got != 3 {
+		t.Fatalf("dry run changed the collection: %d points", got)
+	}
+
+	report, err := svc.ReconcileVectors(ctx, false)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if report.Deleted != 3 || report.Reupserted != 1 {
+		t.Fatalf("expected 3 deleted and 1 re-upserted, got %+v", report)
+	}
+	want := []string{healthy, lost}
+	sort.Strings(want)
+	if got := points.ids(collection); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("collection holds %v, want %v", got, want)
+	}
+	if got := points.ids(stale); len(got) != 0 {
+		t.Fatalf("stale collection still holds %v", got)
+	}
+
+	after, err := svc.ReconcileVectors(ctx, true)
+	if err != nil {
+		t.Fatalf("dry run after repair: %v", err)
+	}
+	if after.Orphans != 0 || after.Missing != 0 {
+		t.Fatalf("expected no drift after repair, got %+v", after)
+	}
+}
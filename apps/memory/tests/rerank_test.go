@@ -0,0 +1,197 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/api"
+	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
+	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/search"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
+)
+
+func TestSearchRerank(t *testing.T) {
+	// A Cohere-style reranker that prefers documents mentioning "breaker"
+	var failing atomic.Bool
+	var calls atomic.Int32
+	rerankSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path != "/rerank" || failing.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var req struct {
+			Model     string   `json:"model"`
+			Query     string   `json:"query"`
+			Documents []string `json:"documents"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		type score struct {
+			Index          int     `json:"index"`
+			RelevanceScore float64 `json:"relevance_score"`
+		}
+		var results []score
+		for i, doc := range req.Documents {
+			s := 0.1
+			if strings.Contains(doc, "breaker") {
+				s = 0.9
+			}
+			results = append(results, score{Index: i, RelevanceScore: s})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"results": results})
+	}))
+	defer rerankSrv.Close()
+	ollamaSrv := fakeOllamaServer()
+	defer ollamaSrv.Close()
+	qdrantSrv := fakeQdrantServer()
+	defer qdrantSrv.Close()
+
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	memoryStore := store.NewMemoryStore(db)
+	bm25Store := store.NewBM25Store(db)
+	ollamaClient := embedding.NewOllamaClient(ollamaSrv.URL, "nomic-embed-text")
+	qdrantClient := vectorstore.NewQdrantClient(qdrantSrv.URL, 768)
+	collMgr := vectorstore.NewCollectionManager(qdrantClient)
+	embedder := embedding.NewCachedEmbedder(ollamaClient, store.NewEmbeddingCacheStore(db), "nomic-embed-text", 768)
+	searcher := search.NewHybridSearcher(memoryStore, bm25Store, nil, qdrantClient, collMgr, 0.7, 0.3, 1.2)
+	searcher.SetReranker(embedding.NewRemoteReranker(rerankSrv.URL, "", "rerank-test"), 0)
+	svc := memory.NewService(
+		memoryStore, store.NewWorkspaceStore(db), bm25Store, embedder,
+		qdrantClient, collMgr, searcher, memory.NewDeduplicator(memoryStore, 0.92),
+		memory.NewLifecycleManager(memoryStore, qdrantClient, collMgr, 3, 0.85, logger),
+		72, logger,
+	)
+	router := api.NewRouter(db, svc, ollamaClient, nil, qdrantClient, nil, nil, nil, nil, nil, nil, nil, nil, api.Timeouts{}, api.Auth{}, logger)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	post := func(path string, v, out any) int {
+		t.Helper()
+		body, _ := json.Marshal(v)
+		resp, err := http.Post(srv.URL+path, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if out != nil {
+			json.NewDecoder(resp.Body).Decode(out)
+		}
+		return resp.StatusCode
+	}
+
+	var breaker models.StoreResponse
+	for _, content := range []string{
+		"Rerank: zebrafish zebrafish zebrafish tanks need weekly water changes",
+		"Rerank: zebrafish zebrafish larvae are fed twice a day",
+		"Rerank: the zebrafish rig trips its breaker when the heater starts",
+	} {
+		var out models.StoreResponse
+		post("/memories", models.StoreRequest{Workspace: "/tmp/rerank-project", Content: content, MemoryType: models.MemoryTypeContext}, &out)
+		if strings.Contains(content, "breaker") {
+			breaker = out
+		}
+	}
+
+	find := func(rerank bool) models.SearchResponse {
+		t.Helper()
+		var resp models.SearchResponse
+		status := post("/memories/search", models.SearchRequest{
+			Workspace: "/tmp/rerank-project", Query: "zebrafish", SearchMode: "bm25", Rerank: rerank,
+		}, &resp)
+		if status != http.StatusOK || len(resp.Results) != 3 {
+			t.Fatalf("search (rerank %v): expected 200 with 3 results, got %d %+v", rerank, status, resp)
+		}
+		return resp
+	}
+
+	plain := find(false)
+	if plain.Meta.Reranked || plain.Results[0].RerankScore != nil || calls.Load() != 0 {
+		t.Fatalf("expected no reranking unless asked for, got %+v", plain)
+	}
+	if plain.Results[0].ID == breaker.ID {
+		t.Fatalf("expected keyword scoring to rank the breaker memory below the others")
+	}
+
+	reranked := find(true)
+	if !reranked.Meta.Reranked || reranked.Results[0].ID != breaker.ID {
+		t.Fatalf("expected the reranker to promote the breaker memory, got %+v", reranked.Results)
+	}
+	if s := reranked.Results[0].RerankScore; s == nil || *s != 0.9 {
+		t.Fatalf("expected rerankScore 0.9, got %v", s)
+	}
+	for i := 1; i < len(reranked.Results); i++ {
+		if reranked.Results[i].Score > reranked.Results[i-1].Score {
+			t.Fatalf("expected scores to follow the reranked order, got %+v", reranked.Results)
+		}
+	}
+
+	// A failing reranker leaves the blended order in place
+	failing.Store(true)
+	degraded := find(true)
+	if degraded.Meta.Reranked || degraded.Results[0].ID != plain.Results[0].ID {
+		t.Fatalf("expected blended order when the reranker fails, got %+v", degraded)
+	}
+
+	// Servers without RERANK_MODEL refuse the option
+	plainSrv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+	body, _ := json.Marshal(models.SearchRequest{Workspace: "/tmp/rerank-project", Query: "zebrafish", Rerank: true})
+	resp, err := http.Post(plainSrv.URL+"/memories/search", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	defer resp.Body.Close()
+	var problem api.Problem
+	json.NewDecoder(resp.Body).Decode(&problem)
+	if resp.StatusCode != http.StatusConflict || problem.Code != "rerank_disabled" {
+		t.Fatalf("expected 409 rerank_disabled, got %d %+v", resp.StatusCode, problem)
+	}
+}
+
+func TestOllamaReranker(t *testing.T) {
+	var prompt string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Prompt string `json:"prompt"`
+			Format string `json:"format"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/api/generate" || req.Format != "json" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		prompt = req.Prompt
+		json.NewEncoder(w).Encode(map[string]any{"response": `{"scores": [2, 8]}`})
+	}))
+	defer srv.Close()
+
+	rr := embedding.NewOllamaReranker(srv.URL, "qwen2.5:1.5b")
+	scores, err := rr.Rerank(context.Background(), "query", []string{"first", "second"})
+	if err != nil || len(scores) != 2 || scores[1] != 8 {
+		t.Fatalf("expected scores [2 8], got %v (%v)", scores, err)
+	}
+	if !strings.Contains(prompt, "Query: query") || !strings.Contains(prompt, "Passage 2:\nsecond") {
+		t.Fatalf("expected the query and numbered passages in the prompt, got:\n%s", prompt)
+	}
+	if _, err := rr.Rerank(context.Background(), "query", []string{"one", "two", "three"}); err == nil {
+		t.Fatal("expected an error when the model returns the wrong number of scores")
+	}
+}
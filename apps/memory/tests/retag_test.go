@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+func postRetag(t *testing.T, url string, req models.RetagRequest) (*http.Response, models.RetagResponse) {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	resp, err := http.Post(url+"/memories/retag", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("retag failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var result models.RetagResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp, result
+}
+
+func listByTag(t *testing.T, url, tag string) []*models.Memory {
+	t.Helper()
+	resp, err := http.Get(url + "/memories?tags=" + tag)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var result models.ListResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	return result.Memories
+}
+
+func TestRetagMemories(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	bulkReq := models.BulkStoreRequest{
+		Workspace: "/tmp/test-project",
+		Memories: []models.BulkMemory{
+			{Content: "Layers compose services", MemoryType: models.MemoryTypePattern, Confidence: 0.9, Tags: []string{"effectts", "di"}},
+			{Content: "Prefer Effect.gen over pipe chains", MemoryType: models.MemoryTypePreference, Confidence: 0.9, Tags: []string{"style", "effectts"}},
+			{Content: "Already uses the new tag", MemoryType: models.MemoryTypePattern, Confidence: 0.9, Tags: []string{"effectts", "effect-ts"}},
+			{Content: "Unrelated chi router note", MemoryType: models.MemoryTypePattern, Confidence: 0.9, Tags: []string{"go"}},
+		},
+	}
+	body, _ := json.Marshal(bulkReq)
+	resp, err := http.Post(srv.URL+"/memories/bulk", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("bulk store failed: %v", err)
+	}
+	resp.Body.Close()
+
+	rename := models.RetagRequest{
+		Filter: models.ListRequest{Tags: []string{"effectts"}},
+		Add:    []string{"effect-ts"},
+		Remove: []string{"effectts"},
+		DryRun: true,
+	}
+	resp, preview := postRetag(t, srv.URL, rename)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if preview.Matched != 3 || preview.Changed != 3 || !preview.DryRun || len(preview.Sample) != 3 {
+		t.Fatalf("unexpected dry run: %+v", preview)
+	}
+	if got := len(listByTag(t, srv.URL, "effectts")); got != 3 {
+		t.Fatalf("dry run changed tags: %d memories still expected under effectts, got %d", 3, got)
+	}
+
+	rename.DryRun = false
+	_, applied := postRetag(t, srv.URL, rename)
+	if applied.Matched != 3 || applied.Changed != 3 || applied.DryRun {
+		t.Fatalf("unexpected retag: %+v", applied)
+	}
+	if got := len(listByTag(t, srv.URL, "effectts")); got != 0 {
+		t.Fatalf("expected no memories left under effectts, got %d", got)
+	}
+	renamed := listByTag(t, srv.URL, "effect-ts")
+	if len(renamed) != 3 {
+		t.Fatalf("expected 3 memories under effect-ts, got %d", len(renamed))
+	}
+	for _, m := range renamed {
+		switch m.Content {
+		case "Layers compose services":
+			if !slices.Equal(m.Tags, []string{"di", "effect-ts"}) {
+				t.Errorf("unexpected tags %v", m.Tags)
+			}
+		case "Already uses the new tag":
+			if !slices.Equal(m.Tags, []string{"effect-ts"}) {
+				t.Errorf("tag added twice: %v", m.Tags)
+			}
+		}
+	}
+	if got := listByTag(t, srv.URL, "go"); len(got) != 1 || !slices.Equal(got[0].Tags, []string{"go"}) {
+		t.Fatalf("memory outside the filter was retagged: %+v", got)
+	}
+
+	// Running it again matches nothing
+	_, again := postRetag(t, srv.URL, rename)
+	if again.Matched != 0 || again.Changed != 0 {
+		t.Fatalf("expected nothing to retag, got %+v", again)
+	}
+}
+
+func TestRetagValidation(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	cases := []struct {
+		name string
+		req  models.RetagRequest
+		code string
+	}{
+		{"no edits", models.RetagRequest{Filter: models.ListRequest{Tags: []string{"a"}}}, "empty_retag"},
+		{"no filter", models.RetagRequest{Add: []string{"a"}}, "empty_filter"},
+		{"add and remove", models.RetagRequest{Filter: models.ListRequest{Tier: "short"}, Add: []string{"a"}, Remove: []string{"a"}}, "conflicting_tags"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, _ := json.Marshal(tc.req)
+			resp, err := http.Post(srv.URL+"/memories/retag", "application/json", bytes.NewReader(body))
+			if err != nil {
+				t.Fatalf("retag failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", resp.StatusCode)
+			}
+			var problem struct {
+				Code string `json:"code"`
+			}
+			json.NewDecoder(resp.Body).Decode(&problem)
+			if problem.Code != tc.code {
+				t.Fatalf("expected code %s, got %s", tc.code, problem.Code)
+			}
+		})
+	}
+}
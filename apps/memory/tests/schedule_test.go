@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/schedule"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2025, time.January, 15, 10, 30, 20, 0, time.UTC)
+
+	for spec, want := range map[string]time.Time{
+		"6h":           from.Add(6 * time.Hour),
+		"0 3 * * *":    time.Date(2025, time.January, 16, 3, 0, 0, 0, time.UTC),
+		"*/15 * * * *": time.Date(2025, time.January, 15, 10, 45, 0, 0, time.UTC),
+		"30 10 * * *":  time.Date(2025, time.January, 16, 10, 30, 0, 0, time.UTC),
+		"0 9 * * 1-5":  time.Date(2025, time.January, 16, 9, 0, 0, 0, time.UTC),
+		"0 0 * * 7":    time.Date(2025, time.January, 19, 0, 0, 0, 0, time.UTC),
+		"0 0 1 */3 *":  time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC),
+		// Both day fields restricted: either one matches
+		"0 12 20 * 5": time.Date(2025, time.January, 17, 12, 0, 0, 0, time.UTC),
+		"@monthly":    time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		s, err := schedule.Parse(spec)
+		if err != nil {
+			t.Errorf("parse %q: %v", spec, err)
+			continue
+		}
+		if got := s.Next(from); !got.Equal(want) {
+			t.Errorf("%q: next after %s is %s, want %s", spec, from, got, want)
+		}
+	}
+
+	if next := mustParse(t, "0 0 30 2 *").Next(from); !next.IsZero() {
+		t.Errorf("expected Feb 30 never to run, got %s", next)
+	}
+
+	for _, bad := range []string{"", "10s", "* * * *", "60 * * * *", "0 0 * * 8", "5-1 * * * *", "*/0 * * * *", "daily"} {
+		if _, err := schedule.Parse(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func mustParse(t *testing.T, spec string) schedule.Schedule {
+	t.Helper()
+	s, err := schedule.Parse(spec)
+	if err != nil {
+		t.Fatalf("parse %q: %v", spec, err)
+	}
+	return s
+}
@@ -0,0 +1,283 @@
+package tests
+
+// Search path benchmarks. Run them with
+//
+//	go test -tags sqlite_fts5 -run '^$' -bench . ./tests/
+//
+// Each benchmark runs against a seeded corpus of 1k and 10k short-term
+// memories; set SEARCH_BENCH_LARGE=1 to add 100k (seeding it takes a
+// minute or so). Corpora are generated from a fixed seed, so runs on the
+// same machine are comparable. Fusion has no entry point of its own: its
+// cost is BenchmarkHybridSearch less the BM25 and short-term scan
+// benchmarks at the same size.
+//
+// TestSearchPerformanceBudget enforces searchBudgets on the 1k corpus as
+// part of the normal test run. It measures wall-clock time, so the budgets
+// are generous; it takes a few seconds and is skipped with -short or
+// SEARCH_BENCH_BUDGET=0.
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/search"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
+)
+
+const benchDim = 768
+
+// searchBudgets are the per-operation ceilings on the 1k corpus. They sit
+// 5-10x above a run on a shared CI VM (BM25 ~50ms, short-term scan ~15ms,
+// hybrid ~75ms), so they only trip on a real regression: an extra query
+// per result, a lost index, or a scan that stopped being linear.
+var searchBudgets = map[string]time.Duration{
+	"embed-cache-hit": 1 * time.Millisecond,
+	"bm25":            500 * time.Millisecond,
+	"short-term-scan": 150 * time.Millisecond,
+	"hybrid":          750 * time.Millisecond,
+	"cosine-768":      20 * time.Microsecond,
+}
+
+var benchWords = strings.Fields(`
+	cache retry queue worker deploy build config schema migration index
+	token budget thread session hook embedding vector search rank score
+	timeout deadline lock mutex channel handler router middleware auth
+	sqlite qdrant ollama compaction lifecycle promotion decay impact link`)
+
+// searchCorpus is a seeded database for one corpus size.
+type searchCorpus struct {
+	searcher    *search.HybridSearcher
+	memoryStore *store.MemoryStore
+	bm25Store   *store.BM25Store
+	embedder    *embedding.CachedEmbedder
+	wsIDs       []string
+	query       []float32
+}
+
+var (
+	corporaMu sync.Mutex
+	corpora   = map[int]*searchCorpus{}
+)
+
+func benchSizes() []int {
+	sizes := []int{1000, 10000}
+	if os.Getenv("SEARCH_BENCH_LARGE") == "1" {
+		sizes = append(sizes, 100000)
+	}
+	return sizes
+}
+
+func benchVector(rng *rand.Rand) []float32 {
+	v := make([]float32, benchDim)
+	for i := range v {
+		v[i] = rng.Float32()*2 - 1
+	}
+	return v
+}
+
+// loadSearchCorpus seeds (once per process) a workspace of n short-term
+// memories with random embeddings and text drawn from benchWords.
+func loadSearchCorpus(tb testing.TB, n int) *searchCorpus {
+	ctx := context.Background()
+	tb.Helper()
+	corporaMu.Lock()
+	defer corporaMu.Unlock()
+	if c, ok := corpora[n]; ok {
+		return c
+	}
+
+	dir, err := os.MkdirTemp("", "search-bench")
+	if err != nil {
+		tb.Fatalf("temp dir: %v", err)
+	}
+	db, err := store.Open(dir + "/bench.db")
+	if err != nil {
+		tb.Fatalf("open db: %v", err)
+	}
+	// Corpora are shared by every benchmark in the run and live until the
+	// process exits.
+	ollamaSrv := fakeOllamaServer()
+	qdrantSrv := fakeQdrantServer()
+
+	memoryStore := store.NewMemoryStore(db)
+	bm25Store := store.NewBM25Store(db)
+	qdrantClient := vectorstore.NewQdrantClient(qdrantSrv.URL, benchDim)
+	wsID, err := store.NewWorkspaceStore(db).EnsureWorkspace(ctx, "default", fmt.Sprintf("/tmp/bench-%d", n))
+	if err != nil {
+		tb.Fatalf("ensure workspace: %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(int64(n)))
+	now := time.Now().Unix()
+	types := []models.MemoryType{models.MemoryTypeContext, models.MemoryTypeDecision, models.MemoryTypeGotcha, models.MemoryTypePattern}
+	for i := 0; i < n; i++ {
+		words := make([]string, 12)
+		for j := range words {
+			words[j] = benchWords[rng.Intn(len(benchWords))]
+		}
+		mem := &models.Memory{
+			ID:          fmt.Sprintf("bench-%07d", i),
+			WorkspaceID: wsID,
+			Content:     strings.Join(words, " "),
+			MemoryType:  types[i%len(types)],
+			Tier:        models.TierShort,
+			Confidence:  0.8,
+			ContentHash: fmt.Sprintf("bench-%d-%d", n, i),
+			Embedding:   search.Float32ToBytes(benchVector(rng)),
+			CreatedAt:   now - int64(rng.Intn(30*86400)),
+			UpdatedAt:   now,
+			Stability:   5,
+		}
+		if err := memoryStore.Insert(ctx, mem); err != nil {
+			tb.Fatalf("seed memory %d: %v", i, err)
+		}
+	}
+
+	c := &searchCorpus{
+		searcher: search.NewHybridSearcher(memoryStore, bm25Store, store.NewLinkStore(db), qdrantClient,
+			vectorstore.NewCollectionManager(qdrantClient), 0.7, 0.3, 1.2),
+		memoryStore: memoryStore,
+		bm25Store:   bm25Store,
+		embedder: embedding.NewCachedEmbedder(embedding.NewOllamaClient(ollamaSrv.URL, "nomic-embed-text"),
+			store.NewEmbeddingCacheStore(db), "nomic-embed-text", benchDim),
+		wsIDs: []string{wsID},
+		query: benchVector(rng),
+	}
+	corpora[n] = c
+	return c
+}
+
+func (c *searchCorpus) params(mode models.SearchMode) search.SearchParams {
+	return search.SearchParams{
+		QueryVector:  c.query,
+		QueryText:    "retry queue timeout",
+		WorkspaceIDs: c.wsIDs,
+		MaxResults:   10,
+		MinScore:     0,
+		Tier:         string(models.TierShort),
+		SearchMode:   mode,
+	}
+}
+
+func benchEachSize(b *testing.B, fn func(b *testing.B, c *searchCorpus)) {
+	for _, n := range benchSizes() {
+		b.Run(fmt.Sprintf("%dk", n/1000), func(b *testing.B) {
+			c := loadSearchCorpus(b, n)
+			b.ResetTimer()
+			fn(b, c)
+		})
+	}
+}
+
+func benchCosine(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	x, y := benchVector(rng), benchVector(rng)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		search.CosineSimilarity(x, y)
+	}
+}
+
+func benchEmbedHit(b *testing.B, c *searchCorpus) {
+	ctx := context.Background()
+	if _, err := c.embedder.Embed(ctx, "cached query"); err != nil {
+		b.Fatalf("prime cache: %v", err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.embedder.Embed(ctx, "cached query"); err != nil {
+			b.Fatalf("embed: %v", err)
+		}
+	}
+}
+
+func benchEmbedMiss(b *testing.B, c *searchCorpus) {
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.embedder.Embed(ctx, fmt.Sprintf("uncached query %d %d", time.Now().UnixNano(), i)); err != nil {
+			b.Fatalf("embed: %v", err)
+		}
+	}
+}
+
+func benchBM25(b *testing.B, c *searchCorpus) {
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.bm25Store.Search(ctx, "retry queue timeout", c.wsIDs, 30); err != nil {
+			b.Fatalf("bm25: %v", err)
+		}
+	}
+}
+
+func benchShortTermScan(b *testing.B, c *searchCorpus) {
+	for i := 0; i < b.N; i++ {
+		mems, err := c.memoryStore.GetShortTermWithEmbeddings(context.Background(), c.wsIDs)
+		if err != nil {
+			b.Fatalf("load short-term: %v", err)
+		}
+		for _, m := range mems {
+			search.CosineSimilarity(c.query, search.BytesToFloat32(m.Embedding))
+		}
+	}
+}
+
+func benchSearch(mode models.SearchMode) func(b *testing.B, c *searchCorpus) {
+	return func(b *testing.B, c *searchCorpus) {
+		ctx := context.Background()
+		params := c.params(mode)
+		for i := 0; i < b.N; i++ {
+			if _, _, _, _, err := c.searcher.Search(ctx, params); err != nil {
+				b.Fatalf("search: %v", err)
+			}
+		}
+	}
+}
+
+func BenchmarkCosineSimilarity(b *testing.B) { benchCosine(b) }
+
+func BenchmarkEmbeddingCache(b *testing.B) {
+	c := loadSearchCorpus(b, benchSizes()[0])
+	b.Run("hit", func(b *testing.B) { benchEmbedHit(b, c) })
+	b.Run("miss", func(b *testing.B) { benchEmbedMiss(b, c) })
+}
+
+func BenchmarkBM25Search(b *testing.B) { benchEachSize(b, benchBM25) }
+
+func BenchmarkShortTermScan(b *testing.B) { benchEachSize(b, benchShortTermScan) }
+
+func BenchmarkHybridSearch(b *testing.B) { benchEachSize(b, benchSearch(models.SearchModeHybrid)) }
+
+func TestSearchPerformanceBudget(t *testing.T) {
+	if testing.Short() || os.Getenv("SEARCH_BENCH_BUDGET") == "0" {
+		t.Skip("search performance budgets are skipped with -short or SEARCH_BENCH_BUDGET=0")
+	}
+	c := loadSearchCorpus(t, 1000)
+
+	runs := map[string]func(b *testing.B){
+		"embed-cache-hit": func(b *testing.B) { benchEmbedHit(b, c) },
+		"bm25":            func(b *testing.B) { benchBM25(b, c) },
+		"short-term-scan": func(b *testing.B) { benchShortTermScan(b, c) },
+		"hybrid":          func(b *testing.B) { benchSearch(models.SearchModeHybrid)(b, c) },
+		"cosine-768":      benchCosine,
+	}
+	for name, budget := range searchBudgets {
+		res := testing.Benchmark(runs[name])
+		if res.N == 0 {
+			t.Fatalf("%s: benchmark did not run", name)
+		}
+		got := time.Duration(res.NsPerOp())
+		t.Logf("%s: %s/op (budget %s)", name, got, budget)
+		if got > budget {
+			t.Errorf("%s took %s/op, over its %s budget", name, got, budget)
+		}
+	}
+}
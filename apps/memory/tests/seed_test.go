@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/api"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/seed"
+)
+
+const seedYAML = `workspaces:
+  - path: /tmp/seed-empty
+memories:
+  - workspace: /tmp/seed-project
+    content: Seeded decision about using SQLite for local state
+    memoryType: DECISION
+    tier: long
+    tags: [storage]
+threads:
+  - workspace: /tmp/seed-project
+    name: seeded-thread
+    entries:
+      - section: findings
+        content: Seeded finding about WAL checkpoints
+      - content: Seeded context entry
+`
+
+const seedNDJSON = `{"kind":"memory","workspace":"/tmp/seed-project","content":"Seeded gotcha about FTS rebuilds","memoryType":"GOTCHA"}
+
+{"kind":"memory","global":true,"content":"Seeded global preference for small commits","memoryType":"PREFERENCE"}
+`
+
+func TestSeedFixtures(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "01-project.yaml"), []byte(seedYAML), 0o644)
+	os.WriteFile(filepath.Join(dir, "02-more.ndjson"), []byte(seedNDJSON), 0o644)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a fixture"), 0o644)
+
+	load := func(dir string, out any) int {
+		t.Helper()
+		body, _ := json.Marshal(map[string]string{"dir": dir})
+		resp, err := http.Post(srv.URL+"/v1/admin/seed", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("seed failed: %v", err)
+		}
+		defer resp.Body.Close()
+		json.NewDecoder(resp.Body).Decode(out)
+		return resp.StatusCode
+	}
+
+	var report seed.Report
+	if status := load(dir, &report); status != http.StatusOK {
+		t.Fatalf("seed: expected 200, got %d", status)
+	}
+	want := seed.Report{Files: 2, Workspaces: 1, Memories: 3, Threads: 1, Entries: 2}
+	if report != want {
+		t.Fatalf("seed report %+v, want %+v", report, want)
+	}
+
+	var search models.SearchResponse
+	body, _ := json.Marshal(models.SearchRequest{Workspace: "/tmp/seed-project", Query: "FTS rebuilds", SearchMode: "bm25"})
+	resp, err := http.Post(srv.URL+"/v1/memories/search", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	json.NewDecoder(resp.Body).Decode(&search)
+	resp.Body.Close()
+	if len(search.Results) == 0 || search.Results[0].Content != "Seeded gotcha about FTS rebuilds" {
+		t.Fatalf("expected the seeded memory to be searchable, got %+v", search.Results)
+	}
+
+	// Loading the same fixtures again adds nothing
+	var again seed.Report
+	load(dir, &again)
+	if again.Memories != 0 || again.Deduplicated != 3 || again.Threads != 0 || again.SkippedThreads != 1 {
+		t.Fatalf("expected a second load to add nothing, got %+v", again)
+	}
+
+	// A malformed fixture rejects the whole directory
+	bad := t.TempDir()
+	os.WriteFile(filepath.Join(bad, "01-ok.ndjson"), []byte(`{"kind":"memory","content":"Never stored","memoryType":"DECISION"}`), 0o644)
+	os.WriteFile(filepath.Join(bad, "02-bad.yaml"), []byte("memories:\n  - content: Bad\n    memoryType: NOPE\n"), 0o644)
+	var p api.Problem
+	if status := load(bad, &p); status != http.StatusBadRequest || p.Code != "invalid_fixture" {
+		t.Fatalf("expected 400 invalid_fixture, got %d %+v", status, p)
+	}
+	body, _ = json.Marshal(models.SearchRequest{Query: "Never stored", SearchMode: "bm25"})
+	resp, err = http.Post(srv.URL+"/v1/memories/search", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	search = models.SearchResponse{}
+	json.NewDecoder(resp.Body).Decode(&search)
+	resp.Body.Close()
+	if len(search.Results) != 0 {
+		t.Fatalf("expected nothing loaded from a bad directory, got %+v", search.Results)
+	}
+}
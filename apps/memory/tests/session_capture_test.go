@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/mcp"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+func TestMCPSessionObservationCapture(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	server := mcp.NewServer(srv.URL, "")
+
+	// 25 observations: one full batch is sent as they arrive, the rest at
+	// session_end
+	var lines []string
+	for i := 1; i <= 25; i++ {
+		lines = append(lines, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"session_observe","arguments":{"sessionId":"sess-capture","toolName":"Edit","input":"internal/store/bm25.go step %d","output":"ok"}}}`, i, i))
+	}
+	lines = append(lines,
+		`{"jsonrpc":"2.0","id":26,"method":"tools/call","params":{"name":"session_observe","arguments":{"sessionId":"sess-capture"}}}`,
+		`{"jsonrpc":"2.0","id":27,"method":"tools/call","params":{"name":"session_end","arguments":{"sessionId":"sess-capture","workspace":"/tmp/test-project"}}}`,
+	)
+
+	var out bytes.Buffer
+	if err := server.Serve(strings.NewReader(strings.Join(lines, "\n")), &out); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+
+	var results []mcp.CallToolResult
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var resp struct {
+			Result mcp.CallToolResult `json:"result"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		results = append(results, resp.Result)
+	}
+	if len(results) != 27 {
+		t.Fatalf("expected 27 responses, got %d", len(results))
+	}
+	for i, want := range map[int]int{0: 1, 18: 19, 19: 0, 24: 5} {
+		var observed struct {
+			Buffered int `json:"buffered"`
+		}
+		json.Unmarshal([]byte(results[i].Content[0].Text), &observed)
+		if results[i].IsError || observed.Buffered != want {
+			t.Fatalf("observation %d: expected %d buffered, got %q", i+1, want, results[i].Content[0].Text)
+		}
+	}
+	if !results[25].IsError {
+		t.Fatalf("expected an observation without toolName to fail, got %q", results[25].Content[0].Text)
+	}
+
+	var ended models.SummarizeResponse
+	if err := json.Unmarshal([]byte(results[26].Content[0].Text), &ended); err != nil || results[26].IsError {
+		t.Fatalf("session_end failed: %q (%v)", results[26].Content[0].Text, err)
+	}
+	if ended.SummaryMemoryID == "" || !strings.Contains(ended.Summary, "bm25.go") {
+		t.Fatalf("expected a summary of the observations, got %+v", ended)
+	}
+
+	resp, err := http.Get(srv.URL + "/v1/sessions/sess-capture/observations")
+	if err != nil {
+		t.Fatalf("list observations: %v", err)
+	}
+	var list struct {
+		Observations []models.Observation `json:"observations"`
+	}
+	json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	if len(list.Observations) != 25 || list.Observations[24].Sequence != 25 {
+		t.Fatalf("expected 25 observations in order, got %d", len(list.Observations))
+	}
+
+	resp, err = http.Get(srv.URL + "/v1/memories/" + ended.SummaryMemoryID)
+	if err != nil {
+		t.Fatalf("get summary: %v", err)
+	}
+	var mem models.Memory
+	json.NewDecoder(resp.Body).Decode(&mem)
+	resp.Body.Close()
+	if mem.MemoryType != models.MemoryTypeSessionSummary || mem.SessionID != "sess-capture" {
+		t.Fatalf("expected a session summary memory for the session, got %+v", mem)
+	}
+
+	resp, err = http.Get(srv.URL + "/v1/sessions/sess-capture")
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	var sess models.Session
+	json.NewDecoder(resp.Body).Decode(&sess)
+	resp.Body.Close()
+	if sess.EndedAt == nil || sess.SummaryMemoryID != ended.SummaryMemoryID {
+		t.Fatalf("expected the session ended and linked to its summary, got %+v", sess)
+	}
+}
+
+func TestObservationBatchValidation(t *testing.T) {
+	srv, cleanup := setupIntegrationTest(t)
+	defer cleanup()
+
+	post := func(path, body string) int {
+		t.Helper()
+		resp, err := http.Post(srv.URL+"/v1"+path, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("post %s: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := post("/sessions/s1/observations/batch", `{"observations":[]}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an empty batch, got %d", code)
+	}
+	if code := post("/sessions/s1/observations/batch", `{"observations":[{"toolName":"Read"},{"input":"x"}]}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an observation without toolName, got %d", code)
+	}
+	if code := post("/sessions/s1/observations/batch", `{"observations":[{"toolName":"Read","success":true}]}`); code != http.StatusCreated {
+		t.Fatalf("expected 201 for a valid batch, got %d", code)
+	}
+
+	// A session with nothing to summarize ends without a summary memory
+	resp, err := http.Post(srv.URL+"/v1/sessions/s2/end", "application/json", strings.NewReader(`{"workspace":"/tmp/test-project"}`))
+	if err != nil {
+		t.Fatalf("end session: %v", err)
+	}
+	var ended models.SummarizeResponse
+	json.NewDecoder(resp.Body).Decode(&ended)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || ended.SessionID != "s2" || ended.SummaryMemoryID != "" {
+		t.Fatalf("expected s2 ended without a summary, got %d %+v", resp.StatusCode, ended)
+	}
+}
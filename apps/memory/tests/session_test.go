@@ -1,9 +1,18 @@
 package tests
 
 import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/sessions"
 )
 
 func TestSessionSummaryMemoryType(t *testing.T) {
@@ -91,3 +100,82 @@ func TestSummarizeResponse(t *testing.T) {
 		t.Error("expected non-empty summaryMemoryId")
 	}
 }
+
+func TestOpenAICompatibleSummarizer(t *testing.T) {
+	var seenModels []string
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		var req struct {
+			Model string `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		seenModels = append(seenModels, req.Model)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":" DECISIONS: use WAL "}}],"usage":{"prompt_tokens":80,"completion_tokens":20}}`))
+	}))
+	defer srv.Close()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	s := sessions.NewSummarizer(sessions.NewOpenAIProvider(srv.URL+"/v1/", "sk-test"), "small-model", true, logger)
+	s.SetFeatureModel(sessions.FeatureDistill, "large-model")
+	s.SetDailyTokenCap(sessions.FeatureSession, 100)
+
+	summary, err := s.Summarize(context.Background(), "user: should we use WAL?")
+	if err != nil || summary != "DECISIONS: use WAL" {
+		t.Fatalf("unexpected summary %q (%v)", summary, err)
+	}
+	if auth != "Bearer sk-test" {
+		t.Fatalf("expected bearer auth, got %q", auth)
+	}
+	if _, err := s.Distill(context.Background(), "decisions", []string{"use WAL"}); err != nil {
+		t.Fatalf("distill: %v", err)
+	}
+	if strings.Join(seenModels, ",") != "small-model,large-model" {
+		t.Fatalf("expected per-feature models, got %v", seenModels)
+	}
+
+	// The first summary used the whole 100-token session budget
+	if used := s.TokensUsedToday()[sessions.FeatureSession]; used != 100 {
+		t.Fatalf("expected 100 session tokens used, got %d", used)
+	}
+	if _, err := s.Summarize(context.Background(), "another transcript"); err == nil || !strings.Contains(err.Error(), "daily token cap") {
+		t.Fatalf("expected the daily cap to block summarization, got %v", err)
+	}
+	if _, err := s.Condense(context.Background(), "a long note", 50); err != nil {
+		t.Fatalf("caps are per feature, condense should still run: %v", err)
+	}
+}
+
+func TestSummarizerHonoursContext(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	s := sessions.NewSummarizer(sessions.NewOllamaProvider(srv.URL), "test-model", true, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := s.Distill(ctx, "decisions", []string{"use WAL"}); err == nil {
+		t.Fatal("expected distill to fail once the context expired")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected distill to stop with its context, took %v", elapsed)
+	}
+
+	// A context that is already done never reaches the provider
+	if _, err := s.Summarize(ctx, "transcript"); err != context.DeadlineExceeded {
+		t.Fatalf("expected the expired context's error, got %v", err)
+	}
+}
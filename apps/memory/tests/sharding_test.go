@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
+	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/search"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
+)
+
+func TestShardStrategies(t *testing.T) {
+	for _, bad := range []struct {
+		name      string
+		maxPoints int
+	}{{"tenant", 0}, {"size", 0}} {
+		if _, err := vectorstore.ParseShardStrategy(bad.name, bad.maxPoints); err == nil {
+			t.Errorf("expected %q with max points %d to be rejected", bad.name, bad.maxPoints)
+		}
+	}
+
+	size, err := vectorstore.ParseShardStrategy("size", 100)
+	if err != nil {
+		t.Fatalf("parse size: %v", err)
+	}
+	if n := size.(vectorstore.Splitter).ShardCount(250); n != 3 {
+		t.Fatalf("expected 250 points to need 3 shards of 100, got %d", n)
+	}
+
+	route := vectorstore.Route{WorkspaceID: "abc", Dimension: 768, Shards: 3}
+	names := size.Collections(route)
+	if strings.Join(names, ",") != "clive_memory_abc,clive_memory_abc.shard1,clive_memory_abc.shard2" {
+		t.Fatalf("unexpected shard collections %v", names)
+	}
+	used := map[string]bool{}
+	for _, id := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		name := size.CollectionFor(route, id)
+		if name != size.CollectionFor(route, id) {
+			t.Fatalf("routing of %q is not stable", id)
+		}
+		used[name] = true
+		if ws, ok := vectorstore.WorkspaceForCollection(name, 768); !ok || ws != "abc" {
+			t.Fatalf("shard %s parses to workspace %q, %v", name, ws, ok)
+		}
+	}
+	if len(used) < 2 {
+		t.Fatalf("expected points spread across shards, all went to %v", used)
+	}
+
+	// Namespaces may end in what looks like a shard suffix; the global
+	// workspace of one must not parse as a shard of another's
+	lookalike := store.NamespacedGlobalID("a_shard1")
+	for _, name := range []string{
+		vectorstore.CollectionName(lookalike, 768),
+		vectorstore.ShardCollectionName(lookalike, 768, 2),
+	} {
+		if ws, ok := vectorstore.WorkspaceForCollection(name, 768); !ok || ws != lookalike {
+			t.Fatalf("%s parses to workspace %q, want %q", name, ws, lookalike)
+		}
+	}
+	if ws, _ := vectorstore.WorkspaceForCollection(vectorstore.ShardCollectionName(store.NamespacedGlobalID("a"), 768, 1), 768); ws == lookalike {
+		t.Fatalf("shard 1 of namespace a's global workspace collides with namespace a_shard1's")
+	}
+
+	ns := vectorstore.NamespaceSharding{}.CollectionFor(vectorstore.Route{WorkspaceID: "abc", Namespace: "team", Dimension: 768}, "a")
+	if _, ok := vectorstore.WorkspaceForCollection(ns, 768); ok {
+		t.Fatalf("namespace collection %s parsed as a workspace collection", ns)
+	}
+}
+
+func TestRebalanceVectors(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ollamaSrv := fakeOllamaServer()
+	defer ollamaSrv.Close()
+	points := &pointStore{collections: map[string]map[string]bool{}}
+	qdrantSrv := points.server()
+	defer qdrantSrv.Close()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	memoryStore := store.NewMemoryStore(db)
+	workspaceStore := store.NewWorkspaceStore(db)
+	bm25Store := store.NewBM25Store(db)
+	qdrantClient := vectorstore.NewQdrantClient(qdrantSrv.URL, 768)
+	collMgr := vectorstore.NewCollectionManager(qdrantClient)
+	collMgr.SetNamespaceResolver(workspaceStore.Namespace)
+	embedder := embedding.NewCachedEmbedder(embedding.NewOllamaClient(ollamaSrv.URL, "nomic-embed-text"),
+		store.NewEmbeddingCacheStore(db), "nomic-embed-text", 768)
+	searcher := search.NewHybridSearcher(memoryStore, bm25Store, store.NewLinkStore(db), qdrantClient, collMgr, 0.7, 0.3, 1.2)
+	svc := memory.NewService(
+		memoryStore, workspaceStore, bm25Store, embedder,
+		qdrantClient, collMgr, searcher, memory.NewDeduplicator(memoryStore, 0.92),
+		memory.NewLifecycleManager(memoryStore, qdrantClient, collMgr, 3, 0.85, logger),
+		72, logger,
+	)
+	ctx := context.Background()
+
+	storeIn := func(workspace, content string) string {
+		t.Helper()
+		resp, err := svc.Store(ctx, &models.StoreRequest{
+			Namespace:  "team",
+			Workspace:  workspace,
+			Content:    content,
+			MemoryType: models.MemoryTypeDecision,
+			Tier:       models.TierLong,
+			Confidence: 0.9,
+		})
+		if err != nil {
+			t.Fatalf("store: %v", err)
+		}
+		return resp.ID
+	}
+	api := storeIn("/src/api", "Retry idempotent requests with exponential backoff")
+	for _, content := range []string{
+		"Paginate list endpoints with opaque cursors",
+		"Return problem+json bodies for every error",
+		"Version the API under a /v1 prefix",
+		"Reject unknown JSON fields on write endpoints",
+	} {
+		storeIn("/src/api", content)
+	}
+	web := storeIn("/src/web", "Render dates in the viewer's locale")
+	apiID, _ := workspaceStore.ResolveWorkspaceID(ctx, "team", "/src/api")
+	webID, _ := workspaceStore.ResolveWorkspaceID(ctx, "team", "/src/web")
+	apiCollection := vectorstore.CollectionName(apiID, 768)
+	if len(points.ids(apiCollection)) != 5 || len(points.ids(vectorstore.CollectionName(webID, 768))) != 1 {
+		t.Fatalf("expected a collection per workspace before switching strategy, got %v", points.collections)
+	}
+
+	// Workspace to namespace collections
+	collMgr.SetStrategy(vectorstore.NamespaceSharding{})
+	dry, err := svc.RebalanceVectors(ctx, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if dry.Moved != 6 || len(dry.Dropped) != 2 || len(points.ids(apiCollection)) != 5 {
+		t.Fatalf("dry run: expected 6 moves and 2 drops planned and nothing touched, got %+v", dry)
+	}
+	report, err := svc.RebalanceVectors(ctx, false)
+	if err != nil {
+		t.Fatalf("rebalance: %v", err)
+	}
+	shared := vectorstore.NamespaceCollectionName("team", 768)
+	if report.Moved != 6 || len(points.ids(shared)) != 6 {
+		t.Fatalf("expected all 6 points in %s, got %+v and %v", shared, report, points.ids(shared))
+	}
+	if _, ok := points.collections[apiCollection]; ok {
+		t.Fatalf("expected the emptied workspace collection to be dropped")
+	}
+
+	// Searches filter the shared collection by workspace
+	resp, err := svc.Search(ctx, &models.SearchRequest{
+		Namespace:  "team",
+		Workspace:  "/src/web",
+		Query:      "locale dates",
+		MaxResults: 10,
+		SearchMode: models.SearchModeVector,
+	})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].ID != web {
+		t.Fatalf("expected only the web workspace's memory, got %+v", resp.Results)
+	}
+
+	// Namespace collection to size-based shards of at most 2 points
+	collMgr.SetStrategy(vectorstore.SizeSharding{MaxPoints: 2})
+	if _, err := svc.RebalanceVectors(ctx, false); err != nil {
+		t.Fatalf("rebalance to size: %v", err)
+	}
+	if _, ok := points.collections[shared]; ok {
+		t.Fatalf("expected the emptied namespace collection to be dropped")
+	}
+	total := 0
+	for i := range 3 {
+		name := vectorstore.ShardCollectionName(apiID, 768, i)
+		if _, ok := points.collections[name]; !ok {
+			t.Fatalf("expected shard %s to exist", name)
+		}
+		total += len(points.ids(name))
+	}
+	if total != 5 {
+		t.Fatalf("expected the api workspace's 5 points across 3 shards, got %d", total)
+	}
+
+	// A restarted server reads the shard count back from Qdrant
+	restarted := vectorstore.NewCollectionManager(qdrantClient)
+	restarted.SetStrategy(vectorstore.SizeSharding{MaxPoints: 2})
+	names, err := restarted.Collections(ctx, apiID)
+	if err != nil || len(names) != 3 {
+		t.Fatalf("expected 3 collections after restart, got %v, %v", names, err)
+	}
+	target, err := restarted.EnsureForPoint(ctx, apiID, api)
+	if err != nil || !points.collections[target][api] {
+		t.Fatalf("expected %s to route to the shard holding it, got %s, %v", api, target, err)
+	}
+
+	again, err := svc.RebalanceVectors(ctx, false)
+	if err != nil || again.Moved != 0 || len(again.Dropped) != 0 {
+		t.Fatalf("expected a second rebalance to do nothing, got %+v, %v", again, err)
+	}
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/shutdown"
+)
+
+func TestShutdownCoordinator(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("drains in-flight operations and rejects new ones", func(t *testing.T) {
+		c := shutdown.NewCoordinator(logger)
+		var flushed bool
+		c.AddFlusher("test", func(ctx context.Context) error {
+			flushed = true
+			return nil
+		})
+
+		done, ok := c.Begin("POST /memories/bulk")
+		if !ok {
+			t.Fatal("expected operation to be accepted")
+		}
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			done()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		m := c.Drain(ctx)
+
+		if !m.Clean || m.InFlightAtStart != 1 || m.Drained != 1 {
+			t.Fatalf("unexpected manifest: %+v", m)
+		}
+		if !flushed {
+			t.Fatal("expected flusher to run")
+		}
+		if _, ok := c.Begin("POST /memories"); ok {
+			t.Fatal("expected new operations to be rejected while draining")
+		}
+	})
+
+	t.Run("reports abandoned operations and flush errors", func(t *testing.T) {
+		c := shutdown.NewCoordinator(logger)
+		c.AddFlusher("broken", func(ctx context.Context) error {
+			return errors.New("boom")
+		})
+		if _, ok := c.Begin("POST /memories"); !ok {
+			t.Fatal("expected operation to be accepted")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		m := c.Drain(ctx)
+
+		if m.Clean {
+			t.Fatal("expected unclean shutdown")
+		}
+		if len(m.Abandoned) != 1 || m.Abandoned[0] != "POST /memories" {
+			t.Fatalf("expected abandoned operation, got %v", m.Abandoned)
+		}
+		if len(m.FlushErrors) != 1 {
+			t.Fatalf("expected one flush error, got %v", m.FlushErrors)
+		}
+	})
+
+	t.Run("flushes after the drain times out", func(t *testing.T) {
+		c := shutdown.NewCoordinator(logger)
+		var flushErr error
+		c.AddFlusher("sqlite", func(ctx context.Context) error {
+			flushErr = ctx.Err()
+			return flushErr
+		})
+		if _, ok := c.Begin("POST /memories/bulk"); !ok {
+			t.Fatal("expected operation to be accepted")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		m := c.Drain(ctx)
+
+		if len(m.Abandoned) != 1 {
+			t.Fatalf("expected the drain to time out, got %+v", m)
+		}
+		if flushErr != nil || len(m.Flushed) != 1 {
+			t.Fatalf("expected the flusher to run with a live context, got %v (%+v)", flushErr, m)
+		}
+	})
+}
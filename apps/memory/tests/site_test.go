@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/site"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+)
+
+func TestStaticSite(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	wsID, err := store.NewWorkspaceStore(db).EnsureWorkspace(ctx, "default", "/tmp/site-project")
+	if err != nil {
+		t.Fatalf("ensure workspace: %v", err)
+	}
+	memoryStore := store.NewMemoryStore(db)
+	now := time.Now().Unix()
+	n := 0
+	insert := func(content string, tier models.Tier, mutate func(*models.Memory)) string {
+		t.Helper()
+		n++
+		m := &models.Memory{
+			ID:          fmt.Sprintf("site-%d", n),
+			WorkspaceID: wsID,
+			Content:     content,
+			MemoryType:  models.MemoryTypeDecision,
+			Tier:        tier,
+			Confidence:  0.9,
+			ContentHash: fmt.Sprintf("site-hash-%d", n),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			Stability:   5,
+		}
+		if mutate != nil {
+			mutate(m)
+		}
+		if err := memoryStore.Insert(ctx, m); err != nil {
+			t.Fatalf("insert %q: %v", content, err)
+		}
+		return m.ID
+	}
+
+	published := insert("Use <b>WAL</b> mode for the local store", models.TierLong, nil)
+	insert("Short-term scratch note", models.TierShort, nil)
+	insert("Private deploy credentials live in the vault", models.TierLong, func(m *models.Memory) {
+		m.Visibility, m.Owner = models.VisibilityPrivate, "alice"
+	})
+	old := insert("Superseded advice about journal mode", models.TierLong, nil)
+	if err := memoryStore.Supersede(ctx, old, published); err != nil {
+		t.Fatalf("supersede: %v", err)
+	}
+
+	if err := store.NewThreadStore(db).CreateThread(ctx, &models.FeatureThread{
+		ID: "site-thread", WorkspaceID: wsID, Name: "Retry Storms", Description: "Why workers retried in lockstep",
+		Status: models.ThreadStatusClosed, CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("create thread: %v", err)
+	}
+	distilled := insert("[Thread: Retry Storms] [findings] Jitter spreads retries out", models.TierLong, func(m *models.Memory) {
+		m.MemoryType = models.MemoryTypeAppKnowledge
+		m.Source = "thread-distill"
+		m.Tags = []string{"thread:Retry Storms", "distilled", "findings"}
+	})
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "CNAME"), []byte("kb.example.com"), 0o644)
+	os.MkdirAll(filepath.Join(dir, "threads"), 0o755)
+	os.WriteFile(filepath.Join(dir, "threads", "stale.html"), []byte("old"), 0o644)
+
+	report, err := site.NewGenerator(db).Generate(ctx, wsID, dir)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if report.Memories != 2 || report.Threads != 1 {
+		t.Fatalf("expected 2 memories and 1 thread, got %+v", report)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		return string(data)
+	}
+	index := read("index.html")
+	if !strings.Contains(index, "Use &lt;b&gt;WAL&lt;/b&gt; mode") || !strings.Contains(index, `href="threads/retry-storms.html"`) {
+		t.Fatalf("index is missing the memory or thread link:\n%s", index)
+	}
+	for _, hidden := range []string{"scratch", "vault", "journal mode"} {
+		if strings.Contains(index, hidden) {
+			t.Errorf("index published %q", hidden)
+		}
+	}
+
+	thread := read("threads/retry-storms.html")
+	if !strings.Contains(thread, "Why workers retried in lockstep") || !strings.Contains(thread, "Jitter spreads retries out") ||
+		strings.Contains(thread, "[Thread:") || !strings.Contains(thread, `href="../style.css"`) {
+		t.Fatalf("unexpected thread page:\n%s", thread)
+	}
+
+	var docs []struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal([]byte(read("search-index.json")), &docs); err != nil || len(docs) != 2 {
+		t.Fatalf("expected 2 indexed docs, got %v (%v)", docs, err)
+	}
+	urls := map[string]string{}
+	for _, d := range docs {
+		urls[d.ID] = d.URL
+	}
+	if urls[published] != "index.html#m-"+published || urls[distilled] != "threads/retry-storms.html#m-"+distilled {
+		t.Fatalf("unexpected index URLs: %v", urls)
+	}
+	read("search.js")
+	read("style.css")
+
+	// Pages carry the index themselves and load no scripts from elsewhere
+	for _, name := range []string{"index.html", "threads/retry-storms.html"} {
+		page := read(name)
+		start := strings.Index(page, `<script id="search-index" type="application/json">`)
+		if start < 0 || strings.Contains(page, "https://") {
+			t.Fatalf("%s: expected an embedded index and no remote scripts:\n%s", name, page)
+		}
+		embedded := page[start+len(`<script id="search-index" type="application/json">`):]
+		embedded = embedded[:strings.Index(embedded, "</script>")]
+		var inPage []struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal([]byte(embedded), &inPage); err != nil || len(inPage) != 2 {
+			t.Errorf("%s: expected 2 docs in the embedded index, got %v (%v)", name, inPage, err)
+		}
+	}
+
+	// Regenerating clears the previous pages but leaves other files alone
+	if _, err := os.Stat(filepath.Join(dir, "threads", "stale.html")); !os.IsNotExist(err) {
+		t.Error("expected the stale thread page removed")
+	}
+	if read("CNAME") != "kb.example.com" {
+		t.Error("expected unrelated files kept")
+	}
+}
@@ -0,0 +1,204 @@
+package tests
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
+	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/search"
+	"github.com/iammorganparry/clive/apps/memory/internal/skills"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
+)
+
+func newSkillSync(t *testing.T, db *store.DB, ollamaURL, qdrantURL, model, skillDir string) *skills.SyncService {
+	t.Helper()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	memoryStore := store.NewMemoryStore(db)
+	bm25Store := store.NewBM25Store(db)
+	qdrantClient := vectorstore.NewQdrantClient(qdrantURL, 768)
+	collMgr := vectorstore.NewCollectionManager(qdrantClient)
+	embedder := embedding.NewCachedEmbedder(
+		embedding.NewOllamaClient(ollamaURL, model), store.NewEmbeddingCacheStore(db), model, 768)
+	searcher := search.NewHybridSearcher(
+		memoryStore, bm25Store, store.NewLinkStore(db), qdrantClient, collMgr,
+		0.7, 0.3, 1.2,
+	)
+	svc := memory.NewService(
+		memoryStore, store.NewWorkspaceStore(db), bm25Store, embedder,
+		qdrantClient, collMgr, searcher,
+		memory.NewDeduplicator(memoryStore, 0.92),
+		memory.NewLifecycleManager(memoryStore, qdrantClient, collMgr, 3, 0.85, logger),
+		72, logger,
+	)
+	return skills.NewSyncService(svc, memoryStore, collMgr, []string{skillDir}, logger)
+}
+
+func writeSkill(t *testing.T, dir, name, description string) {
+	t.Helper()
+	skillDir := filepath.Join(dir, name)
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\nname: " + name + "\ndescription: " + description + "\n---\n\nBody\n"
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSkillSyncReembedsOnModelChange(t *testing.T) {
+	ctx := context.Background()
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+
+	ollamaSrv := fakeOllamaServer()
+	defer ollamaSrv.Close()
+	qdrantSrv := fakeQdrantServer()
+	defer qdrantSrv.Close()
+
+	skillDir := t.TempDir()
+	writeSkill(t, skillDir, "deploy", "Deploy the app to staging")
+	writeSkill(t, skillDir, "review", "Review a pull request")
+
+	oldSync := newSkillSync(t, db, ollamaSrv.URL, qdrantSrv.URL, "nomic-embed-text", skillDir)
+	result, err := oldSync.Sync(ctx)
+	if err != nil {
+		t.Fatalf("initial sync: %v", err)
+	}
+	if result.Stored != 2 || result.Reembedded != 0 {
+		t.Fatalf("initial sync: expected 2 stored, got %+v", result)
+	}
+
+	// Same model, same files: nothing to do
+	result, err = oldSync.Sync(ctx)
+	if err != nil {
+		t.Fatalf("resync: %v", err)
+	}
+	if result.Unchanged != 2 || result.Stored != 0 {
+		t.Fatalf("resync: expected 2 unchanged, got %+v", result)
+	}
+
+	// Switch embedder and drop one skill
+	if err := os.RemoveAll(filepath.Join(skillDir, "review")); err != nil {
+		t.Fatal(err)
+	}
+	newSync := newSkillSync(t, db, ollamaSrv.URL, qdrantSrv.URL, "mxbai-embed-large", skillDir)
+	result, err = newSync.Sync(ctx)
+	if err != nil {
+		t.Fatalf("sync after model change: %v", err)
+	}
+	if result.Reembedded != 1 || result.Removed != 1 || result.Errors != 0 {
+		t.Fatalf("expected 1 re-embedded and 1 removed, got %+v", result)
+	}
+	if result.EmbeddingModel != "mxbai-embed-large" {
+		t.Fatalf("expected embedding model in result, got %q", result.EmbeddingModel)
+	}
+	if len(result.ReembeddedSkills) != 1 || result.ReembeddedSkills[0] != "deploy" {
+		t.Fatalf("expected deploy to be re-embedded, got %v", result.ReembeddedSkills)
+	}
+
+	hints, err := store.NewMemoryStore(db).GetByTypeAndWorkspace(ctx,
+		string(models.MemoryTypeSkillHint), models.GlobalWorkspaceID)
+	if err != nil {
+		t.Fatalf("load skill hints: %v", err)
+	}
+	if len(hints) != 1 || hints[0].EmbeddingModel != "mxbai-embed-large" {
+		t.Fatalf("expected one hint on the new model, got %d", len(hints))
+	}
+}
+
+func TestSkillSyncDryRun(t *testing.T) {
+	ctx := context.Background()
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+
+	ollamaSrv := fakeOllamaServer()
+	defer ollamaSrv.Close()
+	qdrantSrv := fakeQdrantServer()
+	defer qdrantSrv.Close()
+
+	skillDir := t.TempDir()
+	writeSkill(t, skillDir, "deploy", "Deploy the app to staging")
+	writeSkill(t, skillDir, "review", "Review a pull request")
+
+	sync := newSkillSync(t, db, ollamaSrv.URL, qdrantSrv.URL, "nomic-embed-text", skillDir)
+	if _, err := sync.Sync(ctx); err != nil {
+		t.Fatalf("initial sync: %v", err)
+	}
+
+	writeSkill(t, skillDir, "review", "Review a pull request for security issues")
+	writeSkill(t, skillDir, "lint", "Run the linters and fix what they report")
+	if err := os.RemoveAll(filepath.Join(skillDir, "deploy")); err != nil {
+		t.Fatal(err)
+	}
+
+	actions := func(result *skills.SyncResult) map[string]string {
+		got := make(map[string]string, len(result.Changes))
+		for _, c := range result.Changes {
+			got[c.Name] = c.Action
+		}
+		return got
+	}
+	want := map[string]string{
+		"review": skills.ActionUpdated,
+		"lint":   skills.ActionAdded,
+		"deploy": skills.ActionRemoved,
+	}
+	hints := func() []*models.Memory {
+		hints, err := store.NewMemoryStore(db).GetByTypeAndWorkspace(ctx,
+			string(models.MemoryTypeSkillHint), models.GlobalWorkspaceID)
+		if err != nil {
+			t.Fatalf("load skill hints: %v", err)
+		}
+		return hints
+	}
+	before := hints()
+
+	preview, err := sync.Preview(ctx)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if !preview.DryRun || preview.Stored != 2 || preview.Removed != 2 {
+		t.Fatalf("unexpected preview counts: %+v", preview)
+	}
+	if got := actions(preview); len(got) != 3 || got["review"] != want["review"] || got["lint"] != want["lint"] || got["deploy"] != want["deploy"] {
+		t.Fatalf("unexpected preview changes: %+v", preview.Changes)
+	}
+	after := hints()
+	if len(after) != len(before) {
+		t.Fatalf("expected a dry run to write nothing, hints went from %d to %d", len(before), len(after))
+	}
+	for i := range after {
+		if after[i].ContentHash != before[i].ContentHash {
+			t.Fatal("expected a dry run to leave stored hints untouched")
+		}
+	}
+
+	result, err := sync.Sync(ctx)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if result.DryRun || len(result.Changes) != len(preview.Changes) {
+		t.Fatalf("expected the sync to match its preview, got %+v", result.Changes)
+	}
+	for name, action := range actions(result) {
+		if want[name] != action {
+			t.Fatalf("expected %s to be %s, got %s", name, want[name], action)
+		}
+	}
+	if n := len(hints()); n != 2 {
+		t.Fatalf("expected 2 hints after sync, got %d", n)
+	}
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+)
+
+func TestApplyTemplate(t *testing.T) {
+	t.Run("non-structured request is untouched", func(t *testing.T) {
+		req := &models.StoreRequest{Content: "free form", MemoryType: models.MemoryTypeDecision}
+		if err := memory.ApplyTemplate(req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if req.Content != "free form" {
+			t.Fatalf("content changed: %q", req.Content)
+		}
+	})
+
+	t.Run("renders content from fields in template order", func(t *testing.T) {
+		req := &models.StoreRequest{
+			MemoryType: models.MemoryTypeDecision,
+			Structured: true,
+			Fields: map[string]string{
+				"consequences": "Migrations must be idempotent",
+				"decision":     "Use SQLite with WAL",
+				"context":      "Single-node deployment",
+			},
+		}
+		if err := memory.ApplyTemplate(req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := "Context: Single-node deployment\nDecision: Use SQLite with WAL\nConsequences: Migrations must be idempotent"
+		if req.Content != want {
+			t.Fatalf("expected %q, got %q", want, req.Content)
+		}
+	})
+
+	t.Run("missing required field is rejected", func(t *testing.T) {
+		req := &models.StoreRequest{
+			MemoryType: models.MemoryTypeGotcha,
+			Structured: true,
+			Fields:     map[string]string{"symptom": "tests hang"},
+		}
+		err := memory.ApplyTemplate(req)
+		if err == nil || !strings.Contains(err.Error(), "cause") {
+			t.Fatalf("expected missing cause error, got %v", err)
+		}
+	})
+
+	t.Run("unknown field is rejected", func(t *testing.T) {
+		req := &models.StoreRequest{
+			MemoryType: models.MemoryTypePattern,
+			Structured: true,
+			Fields:     map[string]string{"pattern": "a", "rationale": "b", "bogus": "c"},
+		}
+		if err := memory.ApplyTemplate(req); err == nil {
+			t.Fatal("expected error for unknown field")
+		}
+	})
+
+	t.Run("structured content is validated for sections", func(t *testing.T) {
+		req := &models.StoreRequest{
+			MemoryType: models.MemoryTypeFailure,
+			Structured: true,
+			Content:    "Attempt: mocking the db\nOutcome: flaky tests",
+		}
+		err := memory.ApplyTemplate(req)
+		if err == nil || !strings.Contains(err.Error(), "lesson") {
+			t.Fatalf("expected missing lesson error, got %v", err)
+		}
+
+		req.Content += "\nLesson: use a temp sqlite file"
+		if err := memory.ApplyTemplate(req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("fields without structured are rejected", func(t *testing.T) {
+		req := &models.StoreRequest{
+			MemoryType: models.MemoryTypeDecision,
+			Fields:     map[string]string{"decision": "Use SQLite"},
+		}
+		if err := memory.ApplyTemplate(req); err == nil || !strings.Contains(err.Error(), "structured") {
+			t.Fatalf("expected fields to require structured, got %v", err)
+		}
+	})
+
+	t.Run("type without template is rejected", func(t *testing.T) {
+		req := &models.StoreRequest{
+			MemoryType: models.MemoryTypeContext,
+			Structured: true,
+			Content:    "anything",
+		}
+		if err := memory.ApplyTemplate(req); err == nil {
+			t.Fatal("expected error for type without template")
+		}
+	})
+}
+
+func TestBulkStoreAppliesTemplateAndProvenance(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ollamaSrv := fakeOllamaServer()
+	defer ollamaSrv.Close()
+	qdrantSrv := fakeQdrantServer()
+	defer qdrantSrv.Close()
+	svc, memoryStore, _ := quotaTestService(t, db, ollamaSrv.URL, qdrantSrv.URL)
+
+	resp, err := svc.BulkStore(context.Background(), &models.BulkStoreRequest{
+		Workspace: "/tmp/test-project",
+		Memories: []models.BulkMemory{
+			{
+				MemoryType: models.MemoryTypeGotcha,
+				Structured: true,
+				Fields: map[string]string{
+					"symptom": "Tests hang on CI",
+					"cause":   "The fake server never closes",
+					"fix":     "Defer Close in every test",
+				},
+				Provenance: &models.Provenance{TaskID: "task-1", CommitSHA: "ABC1234", Files: []string{"tests/helpers.go"}},
+			},
+			{MemoryType: models.MemoryTypeGotcha, Structured: true, Fields: map[string]string{"symptom": "only a symptom"}},
+			{Content: "Bad SHA", MemoryType: models.MemoryTypeContext, Provenance: &models.Provenance{CommitSHA: "not-a-sha"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("bulk store: %v", err)
+	}
+	if resp.Stored != 1 || resp.Failed != 2 {
+		t.Fatalf("expected 1 stored and 2 failed, got %+v", resp)
+	}
+
+	memories, _, err := memoryStore.List(context.Background(), &models.ListRequest{Limit: 10})
+	if err != nil || len(memories) != 1 {
+		t.Fatalf("expected one stored memory, got %d (%v)", len(memories), err)
+	}
+	m := memories[0]
+	want := "Symptom: Tests hang on CI\nCause: The fake server never closes\nFix: Defer Close in every test"
+	if m.Content != want {
+		t.Fatalf("expected rendered content %q, got %q", want, m.Content)
+	}
+	if m.EncodingContext == nil || m.EncodingContext.TaskID != "task-1" || m.EncodingContext.CommitSHA != "abc1234" {
+		t.Fatalf("expected provenance in the encoding context, got %+v", m.EncodingContext)
+	}
+	if len(m.RelatedFiles) != 1 || m.RelatedFiles[0] != "tests/helpers.go" {
+		t.Fatalf("expected provenance files in related files, got %v", m.RelatedFiles)
+	}
+}
@@ -0,0 +1,259 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/api"
+	"github.com/iammorganparry/clive/apps/memory/internal/apperr"
+	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
+	"github.com/iammorganparry/clive/apps/memory/internal/memory"
+	"github.com/iammorganparry/clive/apps/memory/internal/models"
+	"github.com/iammorganparry/clive/apps/memory/internal/search"
+	"github.com/iammorganparry/clive/apps/memory/internal/store"
+	"github.com/iammorganparry/clive/apps/memory/internal/threads"
+	"github.com/iammorganparry/clive/apps/memory/internal/vectorstore"
+)
+
+func TestEndpointTimeoutCancelsEmbedding(t *testing.T) {
+	// An Ollama that never answers; it reports when the caller gives up
+	abandoned := make(chan struct{}, 4)
+	ollamaSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices a disconnect once the body is consumed
+		io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+			abandoned <- struct{}{}
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer ollamaSrv.Close()
+	qdrantSrv := fakeQdrantServer()
+	defer qdrantSrv.Close()
+
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	memoryStore := store.NewMemoryStore(db)
+	bm25Store := store.NewBM25Store(db)
+	ollamaClient := embedding.NewOllamaClient(ollamaSrv.URL, "nomic-embed-text")
+	qdrantClient := vectorstore.NewQdrantClient(qdrantSrv.URL, 768)
+	collMgr := vectorstore.NewCollectionManager(qdrantClient)
+	embedder := embedding.NewCachedEmbedder(ollamaClient, store.NewEmbeddingCacheStore(db), "nomic-embed-text", 768)
+	searcher := search.NewHybridSearcher(memoryStore, bm25Store, store.NewLinkStore(db), qdrantClient, collMgr, 0.7, 0.3, 1.2)
+	svc := memory.NewService(
+		memoryStore, store.NewWorkspaceStore(db), bm25Store, embedder,
+		qdrantClient, collMgr, searcher, memory.NewDeduplicator(memoryStore, 0.92),
+		memory.NewLifecycleManager(memoryStore, qdrantClient, collMgr, 3, 0.85, logger),
+		72, logger,
+	)
+
+	timeouts := api.Timeouts{Search: 100 * time.Millisecond, Store: 100 * time.Millisecond}
+	router := api.NewRouter(db, svc, ollamaClient, nil, qdrantClient, nil, nil, nil, nil, nil, nil, nil, nil, timeouts, api.Auth{}, logger)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	post := func(path string, v any) (int, api.Problem) {
+		body, _ := json.Marshal(v)
+		start := time.Now()
+		resp, err := http.Post(srv.URL+path, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("POST %s took %s, expected the deadline to cut it short", path, elapsed)
+		}
+		var problem api.Problem
+		json.NewDecoder(resp.Body).Decode(&problem)
+		return resp.StatusCode, problem
+	}
+
+	status, problem := post("/memories/search", models.SearchRequest{
+		Workspace: "/tmp/test-project",
+		Query:     "anything",
+	})
+	if status != http.StatusGatewayTimeout || problem.Code != "request_timeout" {
+		t.Fatalf("expected 504 request_timeout for search, got %d %+v", status, problem)
+	}
+
+	status, problem = post("/memories", models.StoreRequest{
+		Workspace:  "/tmp/test-project",
+		Content:    "never stored",
+		MemoryType: models.MemoryTypeContext,
+	})
+	if status != http.StatusGatewayTimeout || problem.Code != "request_timeout" {
+		t.Fatalf("expected 504 request_timeout for store, got %d %+v", status, problem)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-abandoned:
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected the embedding request to be cancelled")
+		}
+	}
+
+	_, total, err := memoryStore.List(context.Background(), &models.ListRequest{Page: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("list memories: %v", err)
+	}
+	if total != 0 {
+		t.Fatalf("expected nothing stored after a timed-out request, got %d", total)
+	}
+}
+
+func TestRefusedStoreDeletesVector(t *testing.T) {
+	ctx := context.Background()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	var deleted atomic.Int32
+	fake := fakeQdrantServer()
+	defer fake.Close()
+	qdrantSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/points/delete") {
+			deleted.Add(1)
+		}
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer qdrantSrv.Close()
+	ollamaSrv := fakeOllamaServer()
+	defer ollamaSrv.Close()
+	svc, memoryStore, workspaceStore := quotaTestService(t, db, ollamaSrv.URL, qdrantSrv.URL)
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	threadSvc := threads.NewService(store.NewThreadStore(db), memoryStore, workspaceStore, nil, 0, false, logger)
+
+	// A workspace full of thread entries has nothing to evict
+	thread, err := threadSvc.Create(ctx, &models.CreateThreadRequest{Workspace: "/tmp/quota-project", Name: "full"})
+	if err != nil {
+		t.Fatalf("create thread: %v", err)
+	}
+	if _, err := threadSvc.AppendEntry(ctx, thread.ID, &models.AppendEntryRequest{Content: "Finding: fills the quota"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	svc.SetQuota(memory.Quota{MaxMemories: 1, Overflow: memory.QuotaEvict})
+
+	_, err = svc.Store(context.Background(), &models.StoreRequest{
+		Workspace:  "/tmp/quota-project",
+		Content:    "Refused after its vector was written",
+		MemoryType: models.MemoryTypeDecision,
+		Tier:       models.TierLong,
+	})
+	if !apperr.Is(err, apperr.KindQuotaExceeded) {
+		t.Fatalf("expected quota_exceeded, got %v", err)
+	}
+	if deleted.Load() != 1 {
+		t.Fatalf("expected the orphaned vector deleted, got %d deletes", deleted.Load())
+	}
+}
+
+func TestCancelledStoreDeletesVector(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The client goes away once the vector is written, before the memory
+	// reaches SQLite: the upsert takes the database's only connection and
+	// cancels once the store is waiting for it
+	var deleted atomic.Int32
+	fake := fakeQdrantServer()
+	defer fake.Close()
+	qdrantSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.Config.Handler.ServeHTTP(w, r)
+		switch {
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/points"):
+			waits := db.DB.Stats().WaitCount
+			tx, err := db.BeginTx(context.Background(), nil)
+			if err != nil {
+				t.Errorf("hold connection: %v", err)
+				return
+			}
+			go func() {
+				for db.DB.Stats().WaitCount == waits {
+					time.Sleep(time.Millisecond)
+				}
+				cancel()
+				tx.Rollback()
+			}()
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/points/delete"):
+			deleted.Add(1)
+		}
+	}))
+	defer qdrantSrv.Close()
+	ollamaSrv := fakeOllamaServer()
+	defer ollamaSrv.Close()
+	svc, memoryStore, _ := quotaTestService(t, db, ollamaSrv.URL, qdrantSrv.URL)
+
+	_, err := svc.Store(ctx, &models.StoreRequest{
+		Workspace:  "/tmp/cancel-project",
+		Content:    "Cancelled after its vector was written",
+		MemoryType: models.MemoryTypeDecision,
+		Tier:       models.TierLong,
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if deleted.Load() != 1 {
+		t.Fatalf("expected the orphaned vector deleted, got %d deletes", deleted.Load())
+	}
+	if n, _ := memoryStore.CountMemories(context.Background()); n != 0 {
+		t.Fatalf("expected nothing stored, got %d memories", n)
+	}
+}
+
+func TestBulkStoreCancelKeepsCounts(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The second embed cancels the batch and waits for the caller to go
+	var embeds atomic.Int32
+	fake := fakeOllamaServer()
+	defer fake.Close()
+	ollamaSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/embed" && embeds.Add(1) == 2 {
+			io.Copy(io.Discard, r.Body)
+			cancel()
+			<-r.Context().Done()
+			return
+		}
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer ollamaSrv.Close()
+	qdrantSrv := fakeQdrantServer()
+	defer qdrantSrv.Close()
+	svc, _, _ := quotaTestService(t, db, ollamaSrv.URL, qdrantSrv.URL)
+
+	resp, err := svc.BulkStore(ctx, &models.BulkStoreRequest{
+		Workspace: "/tmp/test-project",
+		Memories: []models.BulkMemory{
+			{Content: "Stored before the cancel", MemoryType: models.MemoryTypeContext},
+			{Content: "Cancelled mid-embed", MemoryType: models.MemoryTypeContext},
+			{Content: "Never reached", MemoryType: models.MemoryTypeContext},
+		},
+	})
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if resp == nil || resp.Stored != 1 || resp.Failed != 0 {
+		t.Fatalf("expected the partial counts with the error, got %+v", resp)
+	}
+}
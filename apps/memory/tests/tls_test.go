@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/tlsconfig"
+)
+
+func TestSelfSignedMTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, err := tlsconfig.EnsureSelfSigned(dir)
+	if err != nil {
+		t.Fatalf("generate self-signed cert: %v", err)
+	}
+
+	// A second call reuses the existing pair
+	before, _ := os.ReadFile(certFile)
+	if _, _, err := tlsconfig.EnsureSelfSigned(dir); err != nil {
+		t.Fatalf("reuse self-signed cert: %v", err)
+	}
+	after, _ := os.ReadFile(certFile)
+	if string(before) != string(after) {
+		t.Fatal("expected the existing certificate to be reused")
+	}
+
+	// The self-signed cert doubles as the client CA for local mTLS
+	serverCfg, err := tlsconfig.Server(certFile, keyFile, certFile)
+	if err != nil {
+		t.Fatalf("server config: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.TLS = serverCfg
+	srv.StartTLS()
+	defer srv.Close()
+
+	get := func(caFile, clientCert, clientKey string) error {
+		cfg, err := tlsconfig.Client(caFile, clientCert, clientKey)
+		if err != nil {
+			t.Fatalf("client config: %v", err)
+		}
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+		resp, err := client.Get(srv.URL + "/health")
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	if err := get(certFile, "", ""); err == nil {
+		t.Fatal("expected the handshake to fail without a client certificate")
+	}
+	if err := get(certFile, certFile, keyFile); err != nil {
+		t.Fatalf("expected mTLS request to succeed: %v", err)
+	}
+
+	if cfg, err := tlsconfig.Client("", "", ""); cfg != nil || err != nil {
+		t.Fatalf("expected no client TLS config when nothing is set, got %v, %v", cfg, err)
+	}
+}
+
+func TestSelfSignedRenewsNearExpiry(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+
+	// A pair that expires tomorrow must not be reused
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "clive-memory"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+
+	if _, _, err := tlsconfig.EnsureSelfSigned(dir); err != nil {
+		t.Fatalf("renew self-signed cert: %v", err)
+	}
+	data, _ := os.ReadFile(certFile)
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatal("expected a PEM certificate after renewal")
+	}
+	renewed, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("parse renewed cert: %v", err)
+	}
+	if time.Until(renewed.NotAfter) < 300*24*time.Hour {
+		t.Fatalf("expected a fresh certificate, got one expiring %v", renewed.NotAfter)
+	}
+
+	// A corrupt certificate is replaced too
+	os.WriteFile(certFile, []byte("not a certificate"), 0o644)
+	if _, _, err := tlsconfig.EnsureSelfSigned(dir); err != nil {
+		t.Fatalf("replace corrupt cert: %v", err)
+	}
+	if _, err := tlsconfig.Server(certFile, keyFile, ""); err != nil {
+		t.Fatalf("expected a usable pair after replacing a corrupt cert: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/tokens"
+)
+
+func TestTokensProfileCount(t *testing.T) {
+	cl100k := tokens.Profiles["cl100k"]
+
+	cases := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"empty", "", 0},
+		{"prose", "the quick brown fox", 4},
+		{"long word", strings.Repeat("a", 400), 100},
+		{"number", "1234567", 3},
+		{"code", "if (x != nil) {\n\treturn err\n}", 14},
+		{"cjk", "日本語のテキスト", 10},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cl100k.Count(tc.text); got != tc.want {
+				t.Fatalf("Count(%q) = %d, want %d", tc.text, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTokensProfileOutcountsCharsForCJKAndCode(t *testing.T) {
+	cjk := strings.Repeat("缓存失效需要重新加载配置", 20)
+	code := strings.Repeat("m[k]=append(m[k],v);", 20)
+
+	for _, text := range []string{cjk, code} {
+		chars, approx := tokens.Chars{}.Count(text), tokens.Profiles["cl100k"].Count(text)
+		if approx <= chars {
+			t.Fatalf("expected the approximation to price %q above len/4 (%d), got %d", text[:24], chars, approx)
+		}
+	}
+}
+
+func TestTokensNew(t *testing.T) {
+	for _, name := range tokens.Names() {
+		if _, err := tokens.New(name); err != nil {
+			t.Fatalf("New(%q): %v", name, err)
+		}
+	}
+	if _, err := tokens.New("gpt-2"); err == nil {
+		t.Fatal("expected an unknown tokenizer to be rejected")
+	}
+}
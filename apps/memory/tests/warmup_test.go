@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iammorganparry/clive/apps/memory/internal/api"
+	"github.com/iammorganparry/clive/apps/memory/internal/embedding"
+)
+
+// flakyEmbedder fails every embed while failing is set.
+type flakyEmbedder struct {
+	failing atomic.Bool
+	calls   atomic.Int32
+}
+
+func (f *flakyEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	f.calls.Add(1)
+	if f.failing.Load() {
+		return nil, errors.New("model not loaded")
+	}
+	return []float32{0.1, 0.2, 0.3}, nil
+}
+
+func (f *flakyEmbedder) HealthCheck() error { return nil }
+
+// flakyReranker fails every rerank while failing is set.
+type flakyReranker struct {
+	failing atomic.Bool
+	calls   atomic.Int32
+}
+
+func (f *flakyReranker) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	f.calls.Add(1)
+	if f.failing.Load() {
+		return nil, errors.New("rerank model not loaded")
+	}
+	return make([]float64, len(documents)), nil
+}
+
+func TestWarmerWarmsEmbedderAndReranker(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	embedder := &flakyEmbedder{}
+	reranker := &flakyReranker{}
+	reranker.failing.Store(true)
+
+	w := embedding.NewWarmer(embedder, 0, logger)
+	w.SetReranker(reranker)
+	if w.Ready() || w.Status().State != embedding.WarmupPending {
+		t.Fatalf("expected a new warmer to be pending, got %+v", w.Status())
+	}
+
+	// The embedding model loaded but the rerank model did not
+	if err := w.Warmup(context.Background()); err == nil {
+		t.Fatal("expected warm-up to fail while the reranker is down")
+	}
+	if w.Ready() || w.Status().State != embedding.WarmupFailed || w.Status().Error == "" {
+		t.Fatalf("expected a failed warm-up with its error, got %+v", w.Status())
+	}
+
+	reranker.failing.Store(false)
+	if err := w.Warmup(context.Background()); err != nil {
+		t.Fatalf("warm-up: %v", err)
+	}
+	if !w.Ready() || w.Status().Error != "" || w.Status().LastWarmAt == 0 {
+		t.Fatalf("expected a ready warmer, got %+v", w.Status())
+	}
+	if embedder.calls.Load() != 2 || reranker.calls.Load() != 2 {
+		t.Fatalf("expected both models to be called each time, got %d embeds and %d reranks",
+			embedder.calls.Load(), reranker.calls.Load())
+	}
+
+	// A failed keepalive doesn't unload a model that already loaded
+	embedder.failing.Store(true)
+	if err := w.Warmup(context.Background()); err == nil {
+		t.Fatal("expected the keepalive to fail")
+	}
+	if !w.Ready() {
+		t.Fatalf("expected the warmer to stay ready, got %+v", w.Status())
+	}
+
+	// Only the reranker is local: no embeds are sent
+	rerankOnly := embedding.NewWarmer(nil, 0, logger)
+	rerankOnly.SetReranker(reranker)
+	before := embedder.calls.Load()
+	if err := rerankOnly.Warmup(context.Background()); err != nil || !rerankOnly.Ready() {
+		t.Fatalf("expected a rerank-only warm-up to succeed, got %v", err)
+	}
+	if embedder.calls.Load() != before {
+		t.Fatal("expected a rerank-only warmer not to embed")
+	}
+}
+
+func TestReadyAfterWarmup(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	embedder := &flakyEmbedder{}
+	embedder.failing.Store(true)
+	w := embedding.NewWarmer(embedder, 0, logger)
+	h := api.NewHealthHandler(nil, embedder, nil, w)
+
+	ready := func() (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		h.Ready(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		var body map[string]any
+		json.NewDecoder(rec.Body).Decode(&body)
+		return rec.Code, body
+	}
+
+	if code, body := ready(); code != http.StatusServiceUnavailable || body["ready"] != false {
+		t.Fatalf("expected 503 before warm-up, got %d %v", code, body)
+	}
+
+	w.Warmup(context.Background())
+	if code, _ := ready(); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 after a failed warm-up, got %d", code)
+	}
+
+	embedder.failing.Store(false)
+	if err := w.Warmup(context.Background()); err != nil {
+		t.Fatalf("warm-up: %v", err)
+	}
+	code, body := ready()
+	if code != http.StatusOK || body["ready"] != true {
+		t.Fatalf("expected 200 once warmed up, got %d %v", code, body)
+	}
+	if warmup, _ := body["warmup"].(map[string]any); warmup["state"] != string(embedding.WarmupReady) {
+		t.Fatalf("expected the warm-up state to be reported, got %v", body["warmup"])
+	}
+
+	// Without a warmer the server is ready straight away
+	rec := httptest.NewRecorder()
+	api.NewHealthHandler(nil, embedder, nil, nil).Ready(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 with warm-up disabled, got %d", rec.Code)
+	}
+}
+
+// hangingEmbedder never answers, like a backend stuck loading a model.
+type hangingEmbedder struct{}
+
+func (hangingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (hangingEmbedder) HealthCheck() error { return nil }
+
+func TestWarmerStopsWithItsContext(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	w := embedding.NewWarmer(hangingEmbedder{}, time.Second, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(stopped)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a stuck warm-up to stop when its context is cancelled")
+	}
+	if w.Ready() || w.Status().State != embedding.WarmupFailed {
+		t.Fatalf("expected the cancelled warm-up to be reported as failed, got %+v", w.Status())
+	}
+}
@@ -27,10 +27,14 @@ LOCAL_SKILLS_DIR=".claude/skills"
 # PROGRESS_FILE is set after argument parsing to support epic-scoped paths
 
 # Completion markers
-COMPLETION_MARKER="<promise>ALL_TASKS_COMPLETE</promise>"
-TASK_COMPLETE_MARKER="<promise>TASK_COMPLETE</promise>"
+# (overridable through the agent contract environment, see packages/agent-contract)
+COMPLETION_MARKER="${CLIVE_ALL_TASKS_COMPLETE_MARKER:-<promise>ALL_TASKS_COMPLETE</promise>}"
+TASK_COMPLETE_MARKER="${CLIVE_TASK_COMPLETE_MARKER:-<promise>TASK_COMPLETE</promise>}"
 LEGACY_ALL_COMPLETE="<promise>ALL_SUITES_COMPLETE</promise>"
 
+# Exit code that tells the caller every task is complete
+ALL_COMPLETE_EXIT_CODE="${CLIVE_ALL_TASKS_COMPLETE_EXIT_CODE:-10}"
+
 # Defaults
 ITERATION=1
 MAX_ITERATIONS=50
@@ -311,7 +315,7 @@ if [ -z "$SKILL_OVERRIDE" ]; then
                 echo "No ready tasks available"
             fi
             echo "✅ All ready tasks complete!"
-            exit 10  # Special exit code for "all complete"
+            exit "$ALL_COMPLETE_EXIT_CODE"  # Special exit code for "all complete"
         fi
     elif [ "$TRACKER" = "linear" ]; then
         # Linear: Claude will fetch the next task via MCP tools
@@ -565,7 +569,7 @@ fi
             echo "- Do NOT fetch tasks again"
             echo ""
             echo "If all tasks are complete:"
-            echo "- Output: ALL_TASKS_COMPLETE"
+            echo "- Output: $COMPLETION_MARKER"
             echo "- STOP immediately"
             echo ""
         else
@@ -595,13 +599,13 @@ fi
             echo "   - title - use for display and commit messages"
             echo ""
             echo "4. If no tasks found:"
-            echo "   - Output: ALL_TASKS_COMPLETE"
+            echo "   - Output: $COMPLETION_MARKER"
             echo "   - STOP immediately"
             echo ""
             echo "5. If authentication fails:"
             echo "   - Output: ERROR: Linear MCP is not authenticated."
             echo "   - Output: To authenticate: Cancel build (press 'c'), run 'claude', authenticate with Linear MCP, restart with /build"
-            echo "   - Output: TASK_COMPLETE"
+            echo "   - Output: $TASK_COMPLETE_MARKER"
             echo "   - STOP immediately"
             echo ""
             echo "Then proceed to your skill file workflow."
@@ -804,9 +808,9 @@ else
 fi
 
 # Check completion status from progress file
-if grep -q "$COMPLETION_MARKER" "$PROGRESS_FILE" 2>/dev/null || \
-   grep -q "$LEGACY_ALL_COMPLETE" "$PROGRESS_FILE" 2>/dev/null; then
-    exit 10  # All tasks complete
+if grep -qF "$COMPLETION_MARKER" "$PROGRESS_FILE" 2>/dev/null || \
+   grep -qF "$LEGACY_ALL_COMPLETE" "$PROGRESS_FILE" 2>/dev/null; then
+    exit "$ALL_COMPLETE_EXIT_CODE"  # All tasks complete
 fi
 
 # Default: task complete, continue to next iteration
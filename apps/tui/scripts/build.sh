@@ -1,6 +1,9 @@
 #!/bin/bash
 # Build command - Generic work execution loop with skill-based dispatch
-# Usage: ./build.sh [--once] [--max-iterations N] [--fresh] [--skill SKILL] [-i|--interactive] [extra context]
+# Usage: ./build.sh [--once] [--max-iterations N] [--fresh] [--skill SKILL] [-i|--interactive]
+#                   [--max-retries N] [--retry-backoff SECONDS] [--on-failure stop|skip]
+#                   [--max-cpu-seconds N] [--max-memory-mb N] [--max-file-mb N]
+#                   [--no-journal] [--model MODEL] [--dry-run] [--resume] [extra context]
 
 set -e
 
@@ -19,8 +22,9 @@ LOCAL_SKILLS_DIR=".claude/skills"
 PROGRESS_FILE=".claude/progress.txt"
 
 # Completion markers (generic markers for all skills)
-COMPLETION_MARKER="<promise>ALL_TASKS_COMPLETE</promise>"
-TASK_COMPLETE_MARKER="<promise>TASK_COMPLETE</promise>"
+# (overridable through the agent contract environment, see packages/agent-contract)
+COMPLETION_MARKER="${CLIVE_ALL_TASKS_COMPLETE_MARKER:-<promise>ALL_TASKS_COMPLETE</promise>}"
+TASK_COMPLETE_MARKER="${CLIVE_TASK_COMPLETE_MARKER:-<promise>TASK_COMPLETE</promise>}"
 
 # Legacy markers (for backwards compatibility)
 LEGACY_ALL_COMPLETE="<promise>ALL_SUITES_COMPLETE</promise>"
@@ -36,6 +40,76 @@ SKILL_OVERRIDE=""
 EPIC_FILTER=""
 EXTRA_CONTEXT=""
 WORKTREE_PATH_OVERRIDE=""
+RESUME=false
+DRY_RUN=false
+MAX_ITERATIONS_SET=false
+START_ITERATION=1
+
+# Loop state for --resume
+SESSION_STATE="$(pwd)/.claude/session-state.json"
+
+# Retries for a failed iteration (backoff doubles each time), then stop or skip the task
+MAX_RETRIES="${CLIVE_BUILD_MAX_RETRIES:-0}"
+RETRY_BACKOFF="${CLIVE_BUILD_RETRY_BACKOFF:-30}"
+ON_FAILURE="${CLIVE_BUILD_ON_FAILURE:-stop}"
+
+# Working tree checkpoints for /rollback, newest MAX_CHECKPOINTS kept
+CHECKPOINT_LOG=".claude/checkpoints.log"
+MAX_CHECKPOINTS="${CLIVE_BUILD_CHECKPOINTS:-20}"
+
+# Expected edit scope (globs, one per line) and the per-iteration report
+SCOPE_FILE=".claude/build-scope"
+ITERATION_REPORT=".claude/iteration-report.jsonl"
+
+# Scratchpad diffs shown after each iteration
+SCRATCHPAD_DIFF_DIR=".claude/scratchpad-diffs"
+SCRATCHPAD_PREVIEW=40
+
+# Agent resource limits (0 = none) and how often its RSS is sampled
+MAX_CPU_SECONDS="${CLIVE_BUILD_MAX_CPU_SECONDS:-0}"
+MAX_MEMORY_MB="${CLIVE_BUILD_MAX_MEMORY_MB:-0}"
+MAX_FILE_MB="${CLIVE_BUILD_MAX_FILE_MB:-0}"
+RSS_SAMPLE_SECONDS=5
+
+# Seconds between SIGTERM and SIGKILL when stopping the agent on shutdown
+SHUTDOWN_GRACE="${CLIVE_BUILD_SHUTDOWN_GRACE:-10}"
+
+# Environment rules for the agent ("allow GLOB" / "deny GLOB", last match wins)
+BUILD_ENV_FILE=".claude/build-env"
+BUILD_ENV_RULES=(
+    "deny AWS_*" "deny AZURE_*" "deny ARM_CLIENT_SECRET" "deny GOOGLE_APPLICATION_CREDENTIALS"
+    "deny GOOGLE_CREDENTIALS" "deny CLOUDSDK_AUTH_*" "deny DIGITALOCEAN_*" "deny HEROKU_API_KEY"
+    "deny GITHUB_TOKEN" "deny GH_TOKEN" "deny GITLAB_TOKEN" "deny NPM_TOKEN" "deny NODE_AUTH_TOKEN"
+    "deny DOCKER_PASSWORD" "deny OPENAI_API_KEY" "deny VAULT_TOKEN"
+    "deny *_SECRET" "deny *_SECRET_KEY" "deny *_PASSWORD" "deny *_PRIVATE_KEY"
+    "allow ANTHROPIC_*" "allow CLAUDE_*" "allow CLIVE_*"
+)
+
+# Journal to the epic's feature thread in the memory server
+JOURNAL="${CLIVE_BUILD_JOURNAL:-1}"
+JOURNAL_TIMEOUT=5
+AGENT_OUTPUT=".claude/.build-agent-output"
+
+# Agent model (empty = claude's default) and the --dry-run token estimate per iteration
+MODEL="${CLIVE_BUILD_MODEL:-}"
+EST_INPUT_TOKENS="${CLIVE_BUILD_EST_INPUT_TOKENS:-200000}"
+EST_OUTPUT_TOKENS="${CLIVE_BUILD_EST_OUTPUT_TOKENS:-20000}"
+
+# Loop helpers
+# shellcheck source=lib/build-agent.sh
+source "$SCRIPT_DIR/lib/build-agent.sh"
+# shellcheck source=lib/build-checks.sh
+source "$SCRIPT_DIR/lib/build-checks.sh"
+# shellcheck source=lib/build-dry-run.sh
+source "$SCRIPT_DIR/lib/build-dry-run.sh"
+# shellcheck source=lib/build-env.sh
+source "$SCRIPT_DIR/lib/build-env.sh"
+# shellcheck source=lib/build-journal.sh
+source "$SCRIPT_DIR/lib/build-journal.sh"
+# shellcheck source=lib/build-resume.sh
+source "$SCRIPT_DIR/lib/build-resume.sh"
+# shellcheck source=lib/epic-config.sh
+source "$SCRIPT_DIR/lib/epic-config.sh"
 
 # Check for tailspin (tspin) for prettier log output
 if command -v tspin &>/dev/null; then
@@ -70,8 +144,25 @@ while [[ $# -gt 0 ]]; do
             ;;
         --max-iterations)
             MAX_ITERATIONS="$2"
+            MAX_ITERATIONS_SET=true
             shift 2
             ;;
+        --resume)
+            RESUME=true
+            shift
+            ;;
+        --no-journal)
+            JOURNAL=0
+            shift
+            ;;
+        --model)
+            MODEL="$2"
+            shift 2
+            ;;
+        --dry-run)
+            DRY_RUN=true
+            shift
+            ;;
         --fresh)
             FRESH=true
             shift
@@ -96,6 +187,30 @@ while [[ $# -gt 0 ]]; do
             WORKTREE_PATH_OVERRIDE="$2"
             shift 2
             ;;
+        --max-retries)
+            MAX_RETRIES="$2"
+            shift 2
+            ;;
+        --retry-backoff)
+            RETRY_BACKOFF="$2"
+            shift 2
+            ;;
+        --on-failure)
+            ON_FAILURE="$2"
+            shift 2
+            ;;
+        --max-cpu-seconds)
+            MAX_CPU_SECONDS="$2"
+            shift 2
+            ;;
+        --max-memory-mb)
+            MAX_MEMORY_MB="$2"
+            shift 2
+            ;;
+        --max-file-mb)
+            MAX_FILE_MB="$2"
+            shift 2
+            ;;
         *)
             EXTRA_CONTEXT="$EXTRA_CONTEXT $1"
             shift
@@ -103,6 +218,56 @@ while [[ $# -gt 0 ]]; do
     esac
 done
 
+if ! [[ "$MAX_ITERATIONS" =~ ^[0-9]+$ ]]; then
+    echo "❌ Error: --max-iterations must be a non-negative integer, got '$MAX_ITERATIONS'"
+    exit 1
+fi
+
+if [ "$RESUME" = true ]; then
+    restore_session_state
+fi
+
+if ! [[ "$MAX_RETRIES" =~ ^[0-9]+$ ]]; then
+    echo "❌ Error: --max-retries must be a non-negative integer, got '$MAX_RETRIES'"
+    exit 1
+fi
+if ! [[ "$MAX_CHECKPOINTS" =~ ^[0-9]+$ ]]; then
+    echo "❌ Error: CLIVE_BUILD_CHECKPOINTS must be a non-negative integer, got '$MAX_CHECKPOINTS'"
+    exit 1
+fi
+if ! [[ "$SHUTDOWN_GRACE" =~ ^[0-9]+$ ]]; then
+    echo "❌ Error: CLIVE_BUILD_SHUTDOWN_GRACE must be a whole number of seconds, got '$SHUTDOWN_GRACE'"
+    exit 1
+fi
+if ! [[ "$RETRY_BACKOFF" =~ ^[0-9]+$ ]]; then
+    echo "❌ Error: --retry-backoff must be a whole number of seconds, got '$RETRY_BACKOFF'"
+    exit 1
+fi
+for limit in "--max-cpu-seconds:$MAX_CPU_SECONDS" "--max-memory-mb:$MAX_MEMORY_MB" "--max-file-mb:$MAX_FILE_MB" \
+    "CLIVE_BUILD_EST_INPUT_TOKENS:$EST_INPUT_TOKENS" "CLIVE_BUILD_EST_OUTPUT_TOKENS:$EST_OUTPUT_TOKENS"; do
+    if ! [[ "${limit#*:}" =~ ^[0-9]+$ ]]; then
+        echo "❌ Error: ${limit%%:*} must be a non-negative integer, got '${limit#*:}'"
+        exit 1
+    fi
+done
+if [ "$ON_FAILURE" != "stop" ] && [ "$ON_FAILURE" != "skip" ]; then
+    echo "❌ Error: --on-failure must be 'stop' or 'skip', got '$ON_FAILURE'"
+    exit 1
+fi
+# Skipping marks the failed task blocked; with --skill there is no task to
+# mark, so the loop would just pick the same work again
+if [ "$ON_FAILURE" = "skip" ] && [ -n "$SKILL_OVERRIDE" ]; then
+    echo "❌ Error: --on-failure skip needs tasks from beads and can't be used with --skill"
+    exit 1
+fi
+
+# Scratchpad path, as build-iteration.sh sets it
+if [ -n "$EPIC_FILTER" ]; then
+    SCRATCHPAD_FILE=".claude/epics/$EPIC_FILTER/scratchpad.md"
+else
+    SCRATCHPAD_FILE=".claude/scratchpad.md"
+fi
+
 # Verify skills directory exists
 if [ ! -d "$SKILLS_DIR" ]; then
     echo "❌ Error: Skills directory not found at $SKILLS_DIR"
@@ -179,80 +344,88 @@ if [ -n "$EPIC_FILTER" ]; then
                 BRANCH_NAME="${EPIC_FILTER}-${WORKTREE_NAME}"
             fi
 
-            # Create worktrees parent directory if needed
-            if [ ! -d "$WORKTREES_DIR" ]; then
-                echo "📁 Creating worktrees directory: $WORKTREES_DIR"
-                mkdir -p "$WORKTREES_DIR"
-            fi
-
-            # Check if worktree already exists
-            if [ -d "$WORKTREE_PATH" ]; then
-                echo "📂 Worktree exists: $WORKTREE_PATH"
-
-                # Check if on correct branch
-                CURRENT_BRANCH=$(git -C "$WORKTREE_PATH" branch --show-current 2>/dev/null)
-                if [ "$CURRENT_BRANCH" != "$BRANCH_NAME" ]; then
-                    echo "⚠️  Worktree on branch '$CURRENT_BRANCH', switching to '$BRANCH_NAME'"
-                    git -C "$WORKTREE_PATH" checkout "$BRANCH_NAME" 2>/dev/null || \
-                    git -C "$WORKTREE_PATH" checkout -b "$BRANCH_NAME" 2>/dev/null || \
-                    echo "   Note: Could not switch branches, continuing on $CURRENT_BRANCH"
-                fi
+            if [ "$DRY_RUN" = true ]; then
+                # Nothing is created or switched; the plan is shown for
+                # the worktree the build would use
+                echo "📂 Worktree (not created or switched in a dry run): $WORKTREE_PATH"
+                WORKING_DIR="$WORKTREE_PATH"
+                WORKTREE_ACTIVE=true
             else
-                # Create new worktree with branch
-                echo "📂 Creating worktree: $WORKTREE_NAME"
-                echo "   Path: $WORKTREE_PATH"
-                echo "   Branch: $BRANCH_NAME"
-                echo "   Base: $BASE_BRANCH"
-
-                # Fetch latest from remote
-                git fetch origin "$BASE_BRANCH" 2>/dev/null || true
-
-                # Create worktree with new branch based on origin/main (or base branch)
-                if git worktree add "$WORKTREE_PATH" -b "$BRANCH_NAME" "origin/$BASE_BRANCH" 2>/dev/null; then
-                    echo "✅ Worktree created successfully"
-
-                    # Install dependencies in fresh worktree
-                    echo "📦 Installing dependencies in new worktree..."
-                    if [ -f "$WORKTREE_PATH/yarn.lock" ]; then
-                        (cd "$WORKTREE_PATH" && yarn install --frozen-lockfile 2>/dev/null || yarn install)
-                        echo "✅ Dependencies installed"
-
-                        # Build packages if this is a monorepo with a build:packages script
-                        if [ -f "$WORKTREE_PATH/package.json" ] && grep -q '"build:packages"' "$WORKTREE_PATH/package.json" 2>/dev/null; then
-                            echo "🔨 Building packages..."
-                            (cd "$WORKTREE_PATH" && yarn build:packages 2>/dev/null || yarn build 2>/dev/null || true)
-                            echo "✅ Packages built"
-                        fi
-                    elif [ -f "$WORKTREE_PATH/package-lock.json" ]; then
-                        (cd "$WORKTREE_PATH" && npm ci 2>/dev/null || npm install)
-                        echo "✅ Dependencies installed"
-                    elif [ -f "$WORKTREE_PATH/pnpm-lock.yaml" ]; then
-                        (cd "$WORKTREE_PATH" && pnpm install --frozen-lockfile 2>/dev/null || pnpm install)
-                        echo "✅ Dependencies installed"
+                # Create worktrees parent directory if needed
+                if [ ! -d "$WORKTREES_DIR" ]; then
+                    echo "📁 Creating worktrees directory: $WORKTREES_DIR"
+                    mkdir -p "$WORKTREES_DIR"
+                fi
+
+                # Check if worktree already exists
+                if [ -d "$WORKTREE_PATH" ]; then
+                    echo "📂 Worktree exists: $WORKTREE_PATH"
+
+                    # Check if on correct branch
+                    CURRENT_BRANCH=$(git -C "$WORKTREE_PATH" branch --show-current 2>/dev/null)
+                    if [ "$CURRENT_BRANCH" != "$BRANCH_NAME" ]; then
+                        echo "⚠️  Worktree on branch '$CURRENT_BRANCH', switching to '$BRANCH_NAME'"
+                        git -C "$WORKTREE_PATH" checkout "$BRANCH_NAME" 2>/dev/null || \
+                        git -C "$WORKTREE_PATH" checkout -b "$BRANCH_NAME" 2>/dev/null || \
+                        echo "   Note: Could not switch branches, continuing on $CURRENT_BRANCH"
                     fi
                 else
-                    # Fallback: try creating without -b flag (branch might exist)
-                    if git worktree add "$WORKTREE_PATH" "$BRANCH_NAME" 2>/dev/null; then
-                        echo "✅ Worktree created (existing branch)"
+                    # Create new worktree with branch
+                    echo "📂 Creating worktree: $WORKTREE_NAME"
+                    echo "   Path: $WORKTREE_PATH"
+                    echo "   Branch: $BRANCH_NAME"
+                    echo "   Base: $BASE_BRANCH"
+
+                    # Fetch latest from remote
+                    git fetch origin "$BASE_BRANCH" 2>/dev/null || true
+
+                    # Create worktree with new branch based on origin/main (or base branch)
+                    if git worktree add "$WORKTREE_PATH" -b "$BRANCH_NAME" "origin/$BASE_BRANCH" 2>/dev/null; then
+                        echo "✅ Worktree created successfully"
+
+                        # Install dependencies in fresh worktree
+                        echo "📦 Installing dependencies in new worktree..."
+                        if [ -f "$WORKTREE_PATH/yarn.lock" ]; then
+                            (cd "$WORKTREE_PATH" && yarn install --frozen-lockfile 2>/dev/null || yarn install)
+                            echo "✅ Dependencies installed"
+
+                            # Build packages if this is a monorepo with a build:packages script
+                            if [ -f "$WORKTREE_PATH/package.json" ] && grep -q '"build:packages"' "$WORKTREE_PATH/package.json" 2>/dev/null; then
+                                echo "🔨 Building packages..."
+                                (cd "$WORKTREE_PATH" && yarn build:packages 2>/dev/null || yarn build 2>/dev/null || true)
+                                echo "✅ Packages built"
+                            fi
+                        elif [ -f "$WORKTREE_PATH/package-lock.json" ]; then
+                            (cd "$WORKTREE_PATH" && npm ci 2>/dev/null || npm install)
+                            echo "✅ Dependencies installed"
+                        elif [ -f "$WORKTREE_PATH/pnpm-lock.yaml" ]; then
+                            (cd "$WORKTREE_PATH" && pnpm install --frozen-lockfile 2>/dev/null || pnpm install)
+                            echo "✅ Dependencies installed"
+                        fi
                     else
-                        echo "⚠️  Warning: Could not create worktree, continuing in main repo"
-                        WORKTREE_PATH=""
+                        # Fallback: try creating without -b flag (branch might exist)
+                        if git worktree add "$WORKTREE_PATH" "$BRANCH_NAME" 2>/dev/null; then
+                            echo "✅ Worktree created (existing branch)"
+                        else
+                            echo "⚠️  Warning: Could not create worktree, continuing in main repo"
+                            WORKTREE_PATH=""
+                        fi
                     fi
                 fi
-            fi
 
-            # Change to worktree directory if setup succeeded
-            if [ -n "$WORKTREE_PATH" ] && [ -d "$WORKTREE_PATH" ]; then
-                echo "📍 Working in: $WORKTREE_PATH"
-                cd "$WORKTREE_PATH"
-                WORKING_DIR="$WORKTREE_PATH"
-                WORKTREE_ACTIVE=true
-
-                # Write worktree state for other tools
-                mkdir -p .claude
-                echo "$WORKTREE_PATH" > .claude/.worktree-path
-                echo "$BRANCH_NAME" > .claude/.worktree-branch
-                echo "$ORIGINAL_WORKING_DIR" > .claude/.worktree-origin
+                # Change to worktree directory if setup succeeded
+                if [ -n "$WORKTREE_PATH" ] && [ -d "$WORKTREE_PATH" ]; then
+                    echo "📍 Working in: $WORKTREE_PATH"
+                    cd "$WORKTREE_PATH"
+                    WORKING_DIR="$WORKTREE_PATH"
+                    WORKTREE_ACTIVE=true
+
+                    # Write worktree state for other tools
+                    mkdir -p .claude
+                    echo "$WORKTREE_PATH" > .claude/.worktree-path
+                    echo "$BRANCH_NAME" > .claude/.worktree-branch
+                    echo "$ORIGINAL_WORKING_DIR" > .claude/.worktree-origin
+                fi
             fi
         fi
     fi
@@ -260,26 +433,41 @@ fi
 
 echo ""
 
-# Clear progress if --fresh
-if [ "$FRESH" = true ]; then
-    rm -f "$PROGRESS_FILE"
-    echo "🧹 Cleared progress file"
+load_agent_env
+# The epic's own variables come after the rules, so they always reach the agent
+if [ -n "$EPIC_FILTER" ]; then
+    load_epic_config "$(epic_config_file "$ORIGINAL_WORKING_DIR" "$EPIC_FILTER")"
+    export_epic_env
 fi
+start_journal
+
+if [ "$DRY_RUN" = false ]; then
+    # Clear progress if --fresh
+    if [ "$FRESH" = true ]; then
+        rm -f "$PROGRESS_FILE" "$SESSION_STATE"
+        rm -rf "$SCRATCHPAD_DIFF_DIR"
+        echo "🧹 Cleared progress file"
+    fi
 
-# Ensure .claude directory exists
-mkdir -p .claude
+    # Ensure .claude directory exists
+    mkdir -p .claude
 
-# Initialize progress file if needed
-if [ ! -f "$PROGRESS_FILE" ]; then
-    echo "# Work Execution Progress" > "$PROGRESS_FILE"
-    echo "Started: $(date -Iseconds)" >> "$PROGRESS_FILE"
-    echo "" >> "$PROGRESS_FILE"
-fi
+    # Initialize progress file if needed
+    if [ ! -f "$PROGRESS_FILE" ]; then
+        echo "# Work Execution Progress" > "$PROGRESS_FILE"
+        echo "Started: $(date -Iseconds)" >> "$PROGRESS_FILE"
+        echo "" >> "$PROGRESS_FILE"
+    fi
 
-# Write state files for TUI integration
-echo "$MAX_ITERATIONS" > .claude/.build-max-iterations
+    # Write state files for TUI integration
+    echo "$MAX_ITERATIONS" > .claude/.build-max-iterations
+fi
 
-echo "🚀 Starting build loop"
+if [ "$DRY_RUN" = true ]; then
+    echo "🔍 Dry run - nothing is started, created or saved"
+else
+    echo "🚀 Starting build loop"
+fi
 echo "   Task source: beads (bd ready)"
 echo "   Built-in skills: $SKILLS_DIR"
 if [ -d "$LOCAL_SKILLS_DIR" ] && [ -n "$(ls -A "$LOCAL_SKILLS_DIR" 2>/dev/null)" ]; then
@@ -300,6 +488,7 @@ if [ "$ONCE" = true ]; then
 else
     echo "   Max iterations: $MAX_ITERATIONS"
 fi
+echo "   Model: ${MODEL:-the claude CLI default}"
 if [ "$STREAMING" = true ]; then
     echo "   Mode: streaming (TUI output)"
 elif [ "$INTERACTIVE" = true ]; then
@@ -310,6 +499,19 @@ fi
 if [ "$HAS_TSPIN" = true ] && [ "$INTERACTIVE" = false ]; then
     echo "   Log highlighting: tailspin"
 fi
+if [ "$MAX_CPU_SECONDS" -gt 0 ] || [ "$MAX_MEMORY_MB" -gt 0 ] || [ "$MAX_FILE_MB" -gt 0 ]; then
+    echo "   Limits: cpu ${MAX_CPU_SECONDS}s, memory ${MAX_MEMORY_MB}MB, file size ${MAX_FILE_MB}MB (0 = none)"
+fi
+if [ "${#AGENT_ENV_WITHHELD[@]}" -gt 0 ]; then
+    echo "   Environment: withholding ${AGENT_ENV_WITHHELD[*]} from the agent"
+fi
+if [ "${#EPIC_ENV[@]}" -gt 0 ] || [ "${#EPIC_SETUP[@]}" -gt 0 ] || [ "${#EPIC_TEARDOWN[@]}" -gt 0 ]; then
+    echo "   Epic config: ${#EPIC_ENV[@]} env vars, ${#EPIC_SETUP[@]} setup and ${#EPIC_TEARDOWN[@]} teardown commands"
+fi
+if [ -n "$JOURNAL_THREAD" ]; then
+    echo "   Journal: feature thread $JOURNAL_THREAD"
+fi
+echo "   Retries: $MAX_RETRIES per task (backoff ${RETRY_BACKOFF}s, then $ON_FAILURE)"
 echo "   Progress: $PROGRESS_FILE"
 echo ""
 
@@ -318,11 +520,25 @@ TEMP_PROMPT=$(mktemp)
 mv "$TEMP_PROMPT" "${TEMP_PROMPT}.md"
 TEMP_PROMPT="${TEMP_PROMPT}.md"
 
+# Loop status for the saved state: "running" while the loop goes, then how it ended
+BUILD_STATUS=""
+NEXT_ITERATION="$START_ITERATION"
+
 # Cleanup function
 cleanup() {
-    rm -f "$TEMP_PROMPT"
+    save_session_state
+    stop_rss_monitor
+    run_epic_teardown
+    rm -f "$TEMP_PROMPT" "${SCRATCHPAD_BEFORE:-}"
 }
 trap cleanup EXIT
+trap 'on_shutdown_signal HUP 129' HUP
+trap 'on_shutdown_signal INT 130' INT
+trap 'on_shutdown_signal TERM 143' TERM
+
+if [ "$DRY_RUN" = false ]; then
+    run_epic_setup
+fi
 
 # Function to extract skill from beads task
 get_task_skill() {
@@ -367,132 +583,100 @@ get_skill_file() {
     fi
 }
 
-# The Build Loop (Ralph Wiggum pattern)
-for ((i=1; i<=MAX_ITERATIONS; i++)); do
-    # Write current iteration for TUI
-    echo "$i" > .claude/.build-iteration
-
-    if [ "$ONCE" = true ]; then
-        echo "🔄 Running single iteration"
+# The ready tasks from beads, as a JSON array in the order the build takes
+# them: with --epic, only the epic's tasks.
+ready_tasks() {
+    if [ -n "$EPIC_FILTER" ]; then
+        # Filter ready tasks to those under the specified epic
+        # Derive parent from ID convention: "epic-id.1" -> parent is "epic-id"
+        # Uses .parent if set, otherwise derives from ID by removing last .N segment
+        # Use higher limit to ensure we find tasks under the epic
+        bd ready --json --limit 100 2>/dev/null | jq --arg epic "$EPIC_FILTER" '
+          [.[] |
+            ((.parent // null) as $explicit_parent |
+             (.id | split(".") | if length > 1 then .[:-1] | join(".") else "" end) as $derived_parent |
+             ($explicit_parent // $derived_parent)) as $parent |
+            select($parent == $epic)
+          ]
+        '
     else
-        echo "🔄 Iteration $i/$MAX_ITERATIONS"
-    fi
-    echo ""
-
-    # Determine skill for this iteration
-    SKILL="$SKILL_OVERRIDE"
-    TASK_ID=""
-    TASK_TITLE=""
-
-    if [ -z "$SKILL_OVERRIDE" ]; then
-        # Get next task from beads, optionally filtered by epic
-        if [ -n "$EPIC_FILTER" ]; then
-            # Filter ready tasks to those under the specified epic
-            # Derive parent from ID convention: "epic-id.1" -> parent is "epic-id"
-            # Uses .parent if set, otherwise derives from ID by removing last .N segment
-            # Use higher limit to ensure we find tasks under the epic
-            NEXT_TASK=$(bd ready --json --limit 100 2>/dev/null | jq -r --arg epic "$EPIC_FILTER" '
-              [.[] |
-                ((.parent // null) as $explicit_parent |
-                 (.id | split(".") | if length > 1 then .[:-1] | join(".") else "" end) as $derived_parent |
-                 ($explicit_parent // $derived_parent)) as $parent |
-                select($parent == $epic)
-              ] | .[0] // empty
-            ')
-        else
-            NEXT_TASK=$(bd ready --json 2>/dev/null | jq -r '.[0] // empty')
-        fi
-        if [ -n "$NEXT_TASK" ] && [ "$NEXT_TASK" != "null" ]; then
-            TASK_ID=$(echo "$NEXT_TASK" | jq -r '.id // empty')
-            TASK_TITLE=$(echo "$NEXT_TASK" | jq -r '.title // empty')
-            SKILL=$(get_task_skill "$NEXT_TASK")
-            echo "   Task: $TASK_TITLE ($TASK_ID)"
-            echo "   Skill: $SKILL"
-        else
-            # No ready tasks available
-            if [ -n "$EPIC_FILTER" ]; then
-                echo "   No ready tasks under epic $EPIC_FILTER"
-            else
-                echo "   No ready tasks available"
-            fi
-            echo ""
-            echo "✅ All ready tasks complete!"
-            exit 0
-        fi
-    fi
-
-    # Default skill if none determined
-    if [ -z "$SKILL" ]; then
-        SKILL="feature"
+        bd ready --json 2>/dev/null
     fi
+}
 
-    # Get skill file
-    SKILL_FILE=$(get_skill_file "$SKILL")
-    echo "   Skill file: $SKILL_FILE"
+# Print the prompt for the given iteration of the task in TASK_ID,
+# TASK_TITLE, SKILL and SKILL_FILE.
+write_prompt() {
+    echo "# Task Execution - Iteration $1/$MAX_ITERATIONS"
     echo ""
-
-    # Build the execution prompt
-    {
-        echo "# Task Execution - Iteration $i/$MAX_ITERATIONS"
-        echo ""
-        echo "## Context"
-        echo "- Working directory: \`$(pwd)\`"
-        echo "- Task source: beads (bd ready)"
-        echo "- Progress: $PROGRESS_FILE"
-        echo "- Skill: $SKILL"
-        if [ -n "$TASK_ID" ]; then
-            echo "- Task ID: $TASK_ID"
-            echo "- Task: $TASK_TITLE"
-        fi
-        if [ "$WORKTREE_ACTIVE" = true ]; then
-            echo ""
-            echo "## Worktree Context"
-            echo "- **Worktree Active:** Yes"
-            echo "- **Worktree Path:** \`$WORKTREE_PATH\`"
-            echo "- **Branch:** \`$BRANCH_NAME\`"
-            echo "- **Main Repo:** \`$ORIGINAL_WORKING_DIR\`"
-            echo ""
-            echo "**Important:** You are working in an isolated git worktree. All changes are on branch \`$BRANCH_NAME\`."
-            echo "Commits in this worktree do not affect the main repo until the branch is merged."
-        fi
-        echo ""
-        echo "**Note:** All bash commands execute from the working directory above. File paths are relative to this directory."
+    echo "## Context"
+    echo "- Working directory: \`$WORKING_DIR\`"
+    echo "- Task source: beads (bd ready)"
+    echo "- Progress: $PROGRESS_FILE"
+    echo "- Skill: $SKILL"
+    if [ -n "$TASK_ID" ]; then
+        echo "- Task ID: $TASK_ID"
+        echo "- Task: $TASK_TITLE"
+    fi
+    if [ "$WORKTREE_ACTIVE" = true ]; then
         echo ""
-        if [ -n "$EXTRA_CONTEXT" ]; then
-            echo "## Additional Context"
-            echo "$EXTRA_CONTEXT"
-            echo ""
-        fi
-        echo "## Instructions"
+        echo "## Worktree Context"
+        echo "- **Worktree Active:** Yes"
+        echo "- **Worktree Path:** \`$WORKTREE_PATH\`"
+        echo "- **Branch:** \`$BRANCH_NAME\`"
+        echo "- **Main Repo:** \`$ORIGINAL_WORKING_DIR\`"
         echo ""
-        echo "1. Read the skill file for execution instructions: $SKILL_FILE"
-        echo "2. Use beads as source of truth: run 'bd show $TASK_ID' for task details"
-        echo "3. Execute ONE task only following the skill instructions"
-        echo "4. Update beads status after completion: bd close $TASK_ID"
-        echo "5. Output completion marker and STOP"
+        echo "**Important:** You are working in an isolated git worktree. All changes are on branch \`$BRANCH_NAME\`."
+        echo "Commits in this worktree do not affect the main repo until the branch is merged."
+    fi
+    echo ""
+    echo "**Note:** All bash commands execute from the working directory above. File paths are relative to this directory."
+    echo ""
+    if [ -n "$EXTRA_CONTEXT" ]; then
+        echo "## Additional Context"
+        echo "$EXTRA_CONTEXT"
         echo ""
-        echo "## Completion Markers"
-        echo "- Task done: $TASK_COMPLETE_MARKER"
-        echo "- All tasks done: $COMPLETION_MARKER"
+    fi
+    echo "## Instructions"
+    echo ""
+    echo "1. Read the skill file for execution instructions: $SKILL_FILE"
+    echo "2. Use beads as source of truth: run 'bd show $TASK_ID' for task details"
+    echo "3. Execute ONE task only following the skill instructions"
+    echo "4. Update beads status after completion: bd close $TASK_ID"
+    echo "5. Output completion marker and STOP"
+    echo ""
+    echo "## Completion Markers"
+    echo "- Task done: $TASK_COMPLETE_MARKER"
+    echo "- All tasks done: $COMPLETION_MARKER"
+    echo ""
+    echo "## CRITICAL"
+    echo "- Follow the skill file instructions exactly"
+    echo "- Update beads status (bd close) when task is complete"
+    echo "- Create a LOCAL git commit before outputting completion marker"
+    echo "- STOP immediately after outputting completion marker"
+    echo "- If you discover out-of-scope work, create a beads task for it (see skill file)"
+    if [ -n "$JOURNAL_THREAD" ]; then
         echo ""
-        echo "## CRITICAL"
-        echo "- Follow the skill file instructions exactly"
-        echo "- Update beads status (bd close) when task is complete"
-        echo "- Create a LOCAL git commit before outputting completion marker"
-        echo "- STOP immediately after outputting completion marker"
-        echo "- If you discover out-of-scope work, create a beads task for it (see skill file)"
-    } > "$TEMP_PROMPT"
-
-    # Build claude args
+        echo "## Decisions"
+        echo "State each design or implementation decision on its own line as \"Decision: <what and why>\"."
+        echo "These lines are journaled to the feature thread \`$JOURNAL_THREAD\` for later sessions."
+    fi
+}
+
+# Set CLAUDE_ARGS for the mode the loop runs in.
+set_claude_args() {
     # --add-dir gives Claude access to read files
     # --permission-mode acceptEdits allows file edits without prompting
     # but still requires approval for dangerous operations (bash, etc.)
     # Full permissions for build agents - they need to run bd commands, git, etc.
-    CLAUDE_ARGS=(--add-dir "$(dirname "$TEMP_PROMPT")" --add-dir "$(pwd)" --add-dir "$PLUGIN_DIR" --dangerously-skip-permissions)
+    CLAUDE_ARGS=(--add-dir "$(dirname "$TEMP_PROMPT")" --add-dir "$WORKING_DIR" --add-dir "$PLUGIN_DIR" --dangerously-skip-permissions)
 
     # Add local skills directory if it exists (project-specific custom skills)
     if [ -d "$LOCAL_SKILLS_DIR" ]; then
-        CLAUDE_ARGS+=(--add-dir "$(pwd)/$LOCAL_SKILLS_DIR")
+        CLAUDE_ARGS+=(--add-dir "$WORKING_DIR/$LOCAL_SKILLS_DIR")
+    fi
+    if [ -n "$MODEL" ]; then
+        CLAUDE_ARGS+=(--model "$MODEL")
     fi
 
     if [ "$STREAMING" = true ]; then
@@ -517,17 +701,22 @@ for ((i=1; i<=MAX_ITERATIONS; i++)); do
         # Non-interactive, non-streaming mode (e.g., with tspin)
         CLAUDE_ARGS=(-p --verbose --output-format stream-json "${CLAUDE_ARGS[@]}")
     fi
+}
 
+# Run the agent once for the prompt in $TEMP_PROMPT. Returns the agent's
+# exit status.
+run_agent() {
     # Invoke claude - use CLI directly for streaming (TUI), docker sandbox for interactive
     if [ "$STREAMING" = true ]; then
         # Streaming mode - prompt passed as file argument, NDJSON output
         # NOT using stdin for prompt because it breaks multi-iteration loops
         # (stdin closes after first iteration, subsequent iterations get EOF)
         echo "$TEMP_PROMPT" > .claude/.build-prompt-path
-        claude "${CLAUDE_ARGS[@]}" "Read and execute all instructions in the file: $TEMP_PROMPT" 2>&1
+        agent_exec "${CLAUDE_ARGS[@]}" "Read and execute all instructions in the file: $TEMP_PROMPT" 2>&1 | tee -a "$AGENT_OUTPUT"
+        return "${PIPESTATUS[0]}"
     elif [ "$HAS_TSPIN" = true ] && [ "$INTERACTIVE" = false ]; then
         # Non-streaming with tspin - use Claude CLI directly, pipe to tspin
-        claude "${CLAUDE_ARGS[@]}" "Read and execute all instructions in the file: $TEMP_PROMPT" 2>&1 | while IFS= read -r line; do
+        agent_exec "${CLAUDE_ARGS[@]}" "Read and execute all instructions in the file: $TEMP_PROMPT" 2>&1 | tee -a "$AGENT_OUTPUT" | while IFS= read -r line; do
             if [[ -n "$line" ]]; then
                 text=$(echo "$line" | jq -r '
                     if .type == "content_block_delta" and .delta.type == "text_delta" then .delta.text
@@ -541,23 +730,149 @@ for ((i=1; i<=MAX_ITERATIONS; i++)); do
                 fi
             fi
         done | tspin
+        return "${PIPESTATUS[0]}"
+    elif [ "$INTERACTIVE" = false ]; then
+        # Non-streaming without tspin - NDJSON output as is
+        agent_exec "${CLAUDE_ARGS[@]}" "Read and execute all instructions in the file: $TEMP_PROMPT" 2>&1 | tee -a "$AGENT_OUTPUT"
+        return "${PIPESTATUS[0]}"
     else
         # Interactive mode - no -p flag, let Claude handle TTY directly
-        claude "${CLAUDE_ARGS[@]}" "Read and execute all instructions in the file: $TEMP_PROMPT"
+        agent_exec "${CLAUDE_ARGS[@]}" "Read and execute all instructions in the file: $TEMP_PROMPT"
+    fi
+}
+
+if [ "$DRY_RUN" = true ]; then
+    show_dry_run
+    exit 0
+fi
+
+# The Build Loop (Ralph Wiggum pattern)
+BUILD_STATUS=running
+for ((i=START_ITERATION; i<=MAX_ITERATIONS; i++)); do
+    # Write current iteration for TUI
+    echo "$i" > .claude/.build-iteration
+    NEXT_ITERATION="$i"
+    save_session_state
+
+    if [ "$ONCE" = true ]; then
+        echo "🔄 Running single iteration"
+    else
+        echo "🔄 Iteration $i/$MAX_ITERATIONS"
+    fi
+    echo ""
+
+    # Determine skill for this iteration
+    SKILL="$SKILL_OVERRIDE"
+    TASK_ID=""
+    TASK_TITLE=""
+
+    if [ -z "$SKILL_OVERRIDE" ]; then
+        # Get next task from beads, optionally filtered by epic
+        NEXT_TASK=$(ready_tasks | jq -c '.[0] // empty')
+        if [ -n "$NEXT_TASK" ] && [ "$NEXT_TASK" != "null" ]; then
+            TASK_ID=$(echo "$NEXT_TASK" | jq -r '.id // empty')
+            TASK_TITLE=$(echo "$NEXT_TASK" | jq -r '.title // empty')
+            SKILL=$(get_task_skill "$NEXT_TASK")
+            echo "   Task: $TASK_TITLE ($TASK_ID)"
+            echo "   Skill: $SKILL"
+        else
+            # No ready tasks available
+            if [ -n "$EPIC_FILTER" ]; then
+                echo "   No ready tasks under epic $EPIC_FILTER"
+            else
+                echo "   No ready tasks available"
+            fi
+            echo ""
+            echo "✅ All ready tasks complete!"
+            BUILD_STATUS=complete
+            exit 0
+        fi
+    fi
+
+    # Default skill if none determined
+    if [ -z "$SKILL" ]; then
+        SKILL="feature"
+    fi
+
+    # Get skill file
+    SKILL_FILE=$(get_skill_file "$SKILL")
+    echo "   Skill file: $SKILL_FILE"
+    create_checkpoint "$i" "$TASK_ID"
+    snapshot_scratchpad
+    echo "Build iteration $i started ${TASK_ID:+$TASK_ID: $TASK_TITLE }(skill: $SKILL)" | journal context CONTEXT
+    echo ""
+
+    # Build the execution prompt
+    write_prompt "$i" > "$TEMP_PROMPT"
+
+    set_claude_args
+
+    # Run the agent, retrying failed attempts with exponential backoff
+    : > "$AGENT_OUTPUT"
+    start_rss_monitor
+    run_agent_with_retries
+    stop_rss_monitor
+    echo "   Peak agent memory: $((PEAK_RSS_KB / 1024))MB"
+
+    if [ "$AGENT_STATUS" -eq 0 ]; then
+        check_iteration_scope "$i" "$TASK_ID" "${NEXT_TASK:-}" "succeeded"
+    else
+        check_iteration_scope "$i" "$TASK_ID" "${NEXT_TASK:-}" "failed"
+    fi
+    show_scratchpad_diff "$i"
+    iteration_decisions "$i" | journal decisions DECISION
+    if [ "$AGENT_STATUS" -eq 0 ]; then
+        iteration_summary "$i" "succeeded" | journal context CONTEXT
+    else
+        iteration_summary "$i" "failed with status $AGENT_STATUS" | journal context CONTEXT
+    fi
+
+    if [ "$AGENT_STATUS" -ne 0 ]; then
+        BUILD_STATUS=failed
+        echo ""
+        echo "❌ Agent failed with status $AGENT_STATUS after $((ATTEMPT + 1)) attempt(s)"
+        echo "Failed: ${TASK_ID:-iteration $i} (exit $AGENT_STATUS, $((ATTEMPT + 1)) attempts) $(date -Iseconds)" >> "$PROGRESS_FILE"
+        if [ "$ON_FAILURE" = "stop" ]; then
+            exit "$AGENT_STATUS"
+        fi
+        if [ -z "$TASK_ID" ]; then
+            echo "❌ No task ID to mark blocked, so the failure can't be skipped - stopping"
+            exit "$AGENT_STATUS"
+        fi
+        # Blocked tasks drop out of 'bd ready', so the loop moves on
+        if ! bd update "$TASK_ID" --status blocked >/dev/null 2>&1; then
+            echo "❌ Could not mark $TASK_ID blocked, so the failure can't be skipped - stopping"
+            exit "$AGENT_STATUS"
+        fi
+        echo "⏭️  Skipping $TASK_ID (marked blocked)"
+        BUILD_STATUS=running
+        NEXT_ITERATION=$((i + 1))
+        if [ "$ONCE" = true ]; then
+            BUILD_STATUS=stopped
+            exit "$AGENT_STATUS"
+        fi
+        echo ""
+        echo "---"
+        echo ""
+        continue
     fi
 
     # Check for completion markers in progress file (generic and legacy)
-    if grep -q "$COMPLETION_MARKER" "$PROGRESS_FILE" 2>/dev/null || \
-       grep -q "$LEGACY_ALL_COMPLETE" "$PROGRESS_FILE" 2>/dev/null; then
+    if grep -qF "$COMPLETION_MARKER" "$PROGRESS_FILE" 2>/dev/null || \
+       grep -qF "$LEGACY_ALL_COMPLETE" "$PROGRESS_FILE" 2>/dev/null; then
         echo ""
         echo "✅ All tasks complete!"
+        BUILD_STATUS=complete
         exit 0
     fi
+    NEXT_ITERATION=$((i + 1))
+    save_session_state
 
     # Check --once flag
     if [ "$ONCE" = true ]; then
         echo ""
         echo "🔄 Single iteration complete (--once flag)"
+        BUILD_STATUS=stopped
         exit 0
     fi
 
@@ -566,6 +881,7 @@ for ((i=1; i<=MAX_ITERATIONS; i++)); do
     echo ""
 done
 
+BUILD_STATUS=stopped
 echo "⚠️ Max iterations ($MAX_ITERATIONS) reached"
 echo "   Check $PROGRESS_FILE to see what was completed"
 exit 1
@@ -0,0 +1,181 @@
+#!/bin/bash
+# Build loop helpers: run the agent under its resource limits, retry it
+# with backoff, and stop it and everything it started on shutdown.
+# Sourced by build.sh.
+
+# Run claude with the resource limits and without the withheld
+# environment variables. The subshell keeps both off the loop; exec hands
+# its process to claude.
+agent_exec() {
+    (
+        if [ "${#AGENT_ENV_WITHHELD[@]}" -gt 0 ]; then
+            unset "${AGENT_ENV_WITHHELD[@]}"
+        fi
+        if [ "$MAX_CPU_SECONDS" -gt 0 ]; then
+            ulimit -t "$MAX_CPU_SECONDS"
+        fi
+        if [ "$MAX_MEMORY_MB" -gt 0 ]; then
+            ulimit -v $((MAX_MEMORY_MB * 1024)) 2>/dev/null || \
+                echo "⚠️  Memory limit not supported on this platform, running without it" >&2
+        fi
+        if [ "$MAX_FILE_MB" -gt 0 ]; then
+            ulimit -f $((MAX_FILE_MB * 1024))
+        fi
+        exec claude "$@"
+    )
+}
+
+# Explain exit statuses that come from hitting a resource limit.
+explain_limit_exit() {
+    case "$1" in
+        152) echo "   The agent hit the CPU limit (--max-cpu-seconds $MAX_CPU_SECONDS)" ;;
+        153) echo "   The agent hit the file size limit (--max-file-mb $MAX_FILE_MB)" ;;
+    esac
+}
+
+AGENT_PGID=""
+# Run the agent in the background and wait for it, so the signal traps
+# run while it works rather than once it exits. Outside interactive mode
+# it gets its own process group (job control is on just long enough to
+# start it); interactive claude stays in ours to keep the terminal. Sets
+# AGENT_STATUS.
+run_agent_and_wait() {
+    local pid
+    if [ "$INTERACTIVE" = false ]; then
+        set -m
+    fi
+    run_agent <&0 &
+    pid=$!
+    set +m
+    if [ "$INTERACTIVE" = false ]; then
+        AGENT_PGID=$pid
+    fi
+    AGENT_STATUS=0
+    wait "$pid" || AGENT_STATUS=$?
+    AGENT_PGID=""
+}
+
+# Run the agent until it succeeds or MAX_RETRIES retries are used up,
+# doubling the wait between attempts. Sets AGENT_STATUS and ATTEMPT.
+run_agent_with_retries() {
+    local delay="$RETRY_BACKOFF"
+    ATTEMPT=0
+    while true; do
+        run_agent_and_wait
+        [ "$AGENT_STATUS" -eq 0 ] && break
+        explain_limit_exit "$AGENT_STATUS"
+
+        if [ "$ATTEMPT" -ge "$MAX_RETRIES" ]; then
+            break
+        fi
+        ATTEMPT=$((ATTEMPT + 1))
+        echo ""
+        echo "⚠️ Agent exited with status $AGENT_STATUS - retry $ATTEMPT/$MAX_RETRIES in ${delay}s"
+        echo "$ATTEMPT" > .claude/.build-retry # Current retry, for TUI
+        sleep "$delay"
+        delay=$((delay * 2))
+    done
+    rm -f .claude/.build-retry
+}
+
+# PIDs of the processes descended from the given PID, leaving out the
+# subtree under the optional second PID (the caller's own subshell).
+descendant_pids() {
+    ps -A -o pid= -o ppid= | awk -v root="$1" -v skip="${2:-0}" '
+        { parent[$1] = $2 }
+        END {
+            for (p in parent) {
+                q = p
+                while (q != root && q != skip && q in parent && q > 1) q = parent[q]
+                if (q == root && p != root) print p
+            }
+        }'
+}
+
+# Signal the agent's process group, when it has its own, and everything
+# the loop started.
+signal_agent() {
+    local sig="$1" pids
+    if [ -n "$AGENT_PGID" ]; then
+        kill "-$sig" -- "-$AGENT_PGID" 2>/dev/null || true
+    fi
+    pids=$(descendant_pids $$ "$BASHPID")
+    if [ -n "$pids" ]; then
+        # shellcheck disable=SC2086 # one PID per word
+        kill "-$sig" $pids 2>/dev/null || true
+    fi
+}
+
+# Stop the agent: SIGTERM, then SIGKILL whatever is left after
+# SHUTDOWN_GRACE seconds.
+stop_agent() {
+    local waited=0
+    signal_agent TERM
+    while [ "$waited" -lt "$SHUTDOWN_GRACE" ] && [ -n "$(descendant_pids $$ "$BASHPID")" ]; do
+        sleep 1
+        waited=$((waited + 1))
+    done
+    signal_agent KILL
+}
+
+# Stop the build on SIGHUP, SIGINT or SIGTERM. Output may be going to a
+# terminal or pipe that is already gone, so writes must not end the
+# handler early.
+on_shutdown_signal() {
+    local name="$1" code="$2"
+    trap '' HUP INT TERM PIPE
+    echo "" 2>/dev/null || true
+    echo "🛑 SIG$name received - stopping the agent" 2>/dev/null || true
+    stop_rss_monitor
+    stop_agent
+    if [ "$BUILD_STATUS" = "running" ]; then
+        BUILD_STATUS=interrupted
+        echo "Interrupted: ${TASK_ID:-iteration $NEXT_ITERATION} by SIG$name $(date -Iseconds)" >> "$PROGRESS_FILE" 2>/dev/null || true
+    fi
+    exit "$code"
+}
+
+# Total RSS in KB of the processes descended from root, leaving out the
+# subtree of skip.
+descendant_rss() {
+    ps -A -o pid= -o ppid= -o rss= | awk -v root="$1" -v skip="${2:-0}" '
+        { parent[$1] = $2; rss[$1] = $3 }
+        END {
+            for (p in parent) {
+                q = p
+                while (q != root && q != skip && q in parent && q > 1) q = parent[q]
+                if (q == root && p != root) total += rss[p]
+            }
+            print total + 0
+        }'
+}
+
+RSS_MONITOR_PID=""
+# Sample the agent's RSS in the background while it runs, writing the
+# latest total to .claude/.build-rss (for the TUI status bar) and the
+# peak to .claude/.build-rss-peak.
+start_rss_monitor() {
+    local root=$$
+    echo 0 > .claude/.build-rss-peak
+    (
+        peak=0
+        while true; do
+            rss=$(descendant_rss "$root" "$BASHPID" 2>/dev/null || echo 0)
+            [ "$rss" -gt "$peak" ] && peak=$rss
+            echo "$rss" > .claude/.build-rss
+            echo "$peak" > .claude/.build-rss-peak
+            sleep "$RSS_SAMPLE_SECONDS"
+        done
+    ) &
+    RSS_MONITOR_PID=$!
+}
+
+# Stop the RSS monitor, leaving the iteration's peak in PEAK_RSS_KB.
+stop_rss_monitor() {
+    [ -n "$RSS_MONITOR_PID" ] || return 0
+    kill "$RSS_MONITOR_PID" 2>/dev/null || true
+    wait "$RSS_MONITOR_PID" 2>/dev/null || true
+    RSS_MONITOR_PID=""
+    PEAK_RSS_KB=$(cat .claude/.build-rss-peak 2>/dev/null || echo 0)
+    rm -f .claude/.build-rss .claude/.build-rss-peak
+}
@@ -0,0 +1,143 @@
+#!/bin/bash
+# Build loop helpers for each iteration: checkpoint the tree for
+# /rollback, flag edits outside the task's scope and show what changed in
+# the scratchpad. Sourced by build.sh.
+
+# Checkpoint the working tree before an iteration. git stash create records
+# tracked changes as a commit without touching the tree or the stash list;
+# a clean tree is checkpointed at HEAD. Untracked files are not captured,
+# but are listed in UNTRACKED_BEFORE for the scope check. Sets
+# CHECKPOINT_SHA (empty outside a git repo); with checkpoints off it is
+# still set, just not kept for /rollback.
+create_checkpoint() {
+    local iteration="$1" task="$2" ref
+    CHECKPOINT_SHA=""
+    UNTRACKED_BEFORE=""
+    if ! git rev-parse --verify -q HEAD >/dev/null 2>&1; then
+        return 0
+    fi
+    CHECKPOINT_SHA=$(git stash create "clive checkpoint: iteration $iteration ${task}" 2>/dev/null || true)
+    if [ -z "$CHECKPOINT_SHA" ]; then
+        CHECKPOINT_SHA=$(git rev-parse HEAD)
+    fi
+    UNTRACKED_BEFORE=$(git ls-files --others --exclude-standard)
+    if [ "$MAX_CHECKPOINTS" -eq 0 ]; then
+        return 0
+    fi
+    ref="refs/clive/checkpoints/$(date +%s)-$iteration"
+    git update-ref "$ref" "$CHECKPOINT_SHA"
+    printf '%s\t%s\t%s\t%s\t%s\n' "$(date -Iseconds)" "$iteration" "$ref" "$CHECKPOINT_SHA" "${task:--}" >> "$CHECKPOINT_LOG"
+
+    # Drop the oldest checkpoints beyond MAX_CHECKPOINTS
+    git for-each-ref --format='%(refname)' refs/clive/checkpoints/ | sort -t/ -k4 -n | \
+        awk -v keep="$MAX_CHECKPOINTS" '{ refs[NR] = $0 } END { for (i = 1; i <= NR - keep; i++) print refs[i] }' | \
+        while read -r old; do git update-ref -d "$old"; done
+    echo "   Checkpoint: ${CHECKPOINT_SHA:0:10} (/rollback to restore)"
+}
+
+# Scope globs for a task: the project's build-scope file, then the task's
+# scope:<glob> labels.
+task_scope_globs() {
+    local task_json="$1"
+    if [ -f "$SCOPE_FILE" ]; then
+        sed -e 's/#.*//' -e 's/^[[:space:]]*//' -e 's/[[:space:]]*$//' "$SCOPE_FILE" | sed '/^$/d'
+    fi
+    if [ -n "$task_json" ]; then
+        echo "$task_json" | jq -r '.labels[]? // empty' 2>/dev/null | sed -n 's/^scope://p'
+    fi
+}
+
+# Compare what the agent changed since the checkpoint with the task's
+# scope. Prints a warning block for out-of-scope edits and appends the
+# iteration to ITERATION_REPORT.
+check_iteration_scope() {
+    local iteration="$1" task_id="$2" task_json="$3" status="$4"
+    local changed globs file glob matched
+    local out_of_scope=()
+    [ -n "$CHECKPOINT_SHA" ] || return 0
+
+    # Tracked files changed since the checkpoint (committed or not), plus
+    # files that were not there before. The loop's own state is left out.
+    changed=$( {
+        git diff --name-only "$CHECKPOINT_SHA" 2>/dev/null
+        git ls-files --others --exclude-standard | grep -vxF -f <(printf '%s\n' "$UNTRACKED_BEFORE") || true
+    } | grep -Ev '^\.(claude|beads)/' | sort -u || true)
+    globs=$(task_scope_globs "$task_json")
+
+    if [ -n "$globs" ]; then
+        while IFS= read -r file; do
+            [ -n "$file" ] || continue
+            matched=false
+            while IFS= read -r glob; do
+                # shellcheck disable=SC2053 # glob match is intended
+                if [[ "$file" == $glob ]]; then
+                    matched=true
+                    break
+                fi
+            done <<< "$globs"
+            [ "$matched" = true ] || out_of_scope+=("$file")
+        done <<< "$changed"
+    fi
+
+    if [ "${#out_of_scope[@]}" -gt 0 ]; then
+        echo ""
+        echo "⚠️  Out-of-scope edits in iteration $iteration${task_id:+ ($task_id)}:"
+        printf '   - %s\n' "${out_of_scope[@]}"
+        echo "   Expected scope: $(echo "$globs" | paste -sd' ' -)"
+        echo "Out of scope: ${task_id:-iteration $iteration} changed ${out_of_scope[*]} $(date -Iseconds)" >> "$PROGRESS_FILE"
+    fi
+
+    jq -cn \
+        --argjson iteration "$iteration" \
+        --arg taskId "$task_id" \
+        --arg status "$status" \
+        --arg checkpoint "$CHECKPOINT_SHA" \
+        --arg changed "$changed" \
+        --arg outOfScope "$(printf '%s\n' "${out_of_scope[@]}")" \
+        --argjson peakRssKb "${PEAK_RSS_KB:-0}" \
+        --arg at "$(date -Iseconds)" \
+        '{iteration: $iteration, taskId: $taskId, status: $status, checkpoint: $checkpoint,
+          changed: ($changed | split("\n") | map(select(. != ""))),
+          outOfScope: ($outOfScope | split("\n") | map(select(. != ""))),
+          peakRssKb: $peakRssKb, at: $at}' \
+        >> "$ITERATION_REPORT"
+}
+
+SCRATCHPAD_BEFORE=""
+# Snapshot the scratchpad before an iteration, for show_scratchpad_diff.
+snapshot_scratchpad() {
+    SCRATCHPAD_BEFORE=$(mktemp)
+    if [ -f "$SCRATCHPAD_FILE" ]; then
+        cp "$SCRATCHPAD_FILE" "$SCRATCHPAD_BEFORE"
+    fi
+}
+
+# Show what the iteration changed in the scratchpad as a block, folded to
+# SCRATCHPAD_PREVIEW lines, and keep the full diff for later.
+show_scratchpad_diff() {
+    local iteration="$1" after="$SCRATCHPAD_FILE" diff_file added removed total
+    [ -n "$SCRATCHPAD_BEFORE" ] || return 0
+    [ -f "$after" ] || after=/dev/null
+    mkdir -p "$SCRATCHPAD_DIFF_DIR"
+    diff_file="$SCRATCHPAD_DIFF_DIR/iteration-$iteration.diff"
+
+    if diff -u --label "scratchpad before iteration $iteration" --label "scratchpad after iteration $iteration" \
+        "$SCRATCHPAD_BEFORE" "$after" > "$diff_file"; then
+        rm -f "$diff_file"
+        echo ""
+        echo "📝 Scratchpad unchanged in iteration $iteration"
+    else
+        added=$(grep -c '^+[^+]' "$diff_file" || true)
+        removed=$(grep -c '^-[^-]' "$diff_file" || true)
+        total=$(tail -n +3 "$diff_file" | wc -l | tr -d ' ')
+        echo ""
+        echo "┌─ 📝 Scratchpad, iteration $iteration: +$added -$removed lines"
+        tail -n +3 "$diff_file" | head -n "$SCRATCHPAD_PREVIEW" | sed 's/^/│ /'
+        if [ "$total" -gt "$SCRATCHPAD_PREVIEW" ]; then
+            echo "│ … $((total - SCRATCHPAD_PREVIEW)) more lines"
+        fi
+        echo "└─ Full diff: $diff_file"
+    fi
+    rm -f "$SCRATCHPAD_BEFORE"
+    SCRATCHPAD_BEFORE=""
+}
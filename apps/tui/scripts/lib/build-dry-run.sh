@@ -0,0 +1,65 @@
+#!/bin/bash
+# Build loop helpers for --dry-run: show the plan for the next iteration
+# and estimate what the ready tasks will cost. Sourced by build.sh.
+
+# Price of a million input and output tokens on the model, in USD, for
+# the --dry-run estimate. An unset model is priced as claude's default,
+# Sonnet.
+model_price() {
+    case "$1" in
+        *opus*) echo "15 75" ;;
+        *haiku*) echo "0.8 4" ;;
+        *) echo "3 15" ;;
+    esac
+}
+
+# --dry-run: show the ready tasks, the prompt and command for the next
+# iteration and an estimated cost, then stop.
+show_dry_run() {
+    local tasks count iterations prompt price tokens_in task n=0
+    iterations=$((MAX_ITERATIONS - START_ITERATION + 1))
+    [ "$ONCE" = true ] && iterations=1
+
+    echo "📋 Ready tasks, in the order the build takes them:"
+    SKILL="$SKILL_OVERRIDE"
+    TASK_ID=""
+    TASK_TITLE=""
+    if [ -n "$SKILL_OVERRIDE" ]; then
+        echo "   None taken - every iteration runs the $SKILL_OVERRIDE skill"
+    else
+        tasks=$(ready_tasks)
+        [ -n "$tasks" ] || tasks='[]'
+        count=$(echo "$tasks" | jq 'length')
+        if [ "$count" -eq 0 ]; then
+            echo "   None - the build would stop without running the agent"
+            return 0
+        fi
+        while IFS= read -r task; do
+            n=$((n + 1))
+            echo "   $n. $(echo "$task" | jq -r '.id') $(echo "$task" | jq -r '.title') (skill: $(get_task_skill "$task"))"
+        done < <(echo "$tasks" | jq -c '.[]')
+        [ "$count" -lt "$iterations" ] && iterations=$count
+        NEXT_TASK=$(echo "$tasks" | jq -c '.[0]')
+        TASK_ID=$(echo "$NEXT_TASK" | jq -r '.id // empty')
+        TASK_TITLE=$(echo "$NEXT_TASK" | jq -r '.title // empty')
+        SKILL=$(get_task_skill "$NEXT_TASK")
+    fi
+    SKILL="${SKILL:-feature}"
+    SKILL_FILE=$(get_skill_file "$SKILL")
+
+    prompt=$(write_prompt "$START_ITERATION")
+    set_claude_args
+    echo ""
+    echo "┌─ 📄 Prompt for iteration $START_ITERATION${TASK_ID:+ ($TASK_ID)}"
+    echo "$prompt" | sed 's/^/│ /'
+    echo "└─ Skill file: $SKILL_FILE"
+    echo ""
+    echo "🤖 Command: claude ${CLAUDE_ARGS[*]} \"Read and execute all instructions in the file: <prompt>\""
+
+    price=$(model_price "$MODEL")
+    tokens_in=$(( (${#prompt} + $(wc -c < "$SKILL_FILE")) / 4 + EST_INPUT_TOKENS ))
+    echo "$iterations $tokens_in $EST_OUTPUT_TOKENS $price" | awk '{
+        cost = $1 * ($2 * $4 + $3 * $5) / 1000000
+        printf "💰 Estimated cost: ~$%.2f for %d iteration(s), at ~%dk input and ~%dk output tokens each ($%s/$%s per million)\n", cost, $1, $2 / 1000, $3 / 1000, $4, $5
+    }'
+}
@@ -0,0 +1,50 @@
+#!/bin/bash
+# Build loop helpers: work out which exported variables the agent doesn't
+# get, from BUILD_ENV_RULES and the project's and epic's rule files.
+# Sourced by build.sh.
+
+# Add the rules in a file, one per line, after the ones already loaded.
+load_build_env_rules() {
+    local file="$1" line verdict pattern extra n=0
+    [ -f "$file" ] || return 0
+    while IFS= read -r line || [ -n "$line" ]; do
+        n=$((n + 1))
+        line="${line%%#*}"
+        read -r verdict pattern extra <<< "$line"
+        [ -z "$verdict" ] && continue
+        if { [ "$verdict" != "allow" ] && [ "$verdict" != "deny" ]; } || [ -z "$pattern" ] || [ -n "$extra" ]; then
+            echo "❌ Error: $file:$n: expected 'allow GLOB' or 'deny GLOB', got '$line'"
+            exit 1
+        fi
+        BUILD_ENV_RULES+=("$verdict $pattern")
+    done < "$file"
+}
+
+# Names of the exported variables the rules keep from the agent.
+withheld_env_vars() {
+    local name rule verdict
+    while IFS= read -r name; do
+        verdict=allow
+        for rule in "${BUILD_ENV_RULES[@]}"; do
+            # shellcheck disable=SC2053 # the rule's pattern is a glob
+            [[ $name == ${rule#* } ]] && verdict="${rule%% *}"
+        done
+        if [ "$verdict" = "deny" ]; then
+            echo "$name"
+        fi
+    done < <(compgen -e)
+}
+
+# Add the project's and the epic's rules, read from the directory the
+# agent runs in, and set AGENT_ENV_WITHHELD.
+load_agent_env() {
+    local name
+    load_build_env_rules "$BUILD_ENV_FILE"
+    if [ -n "$EPIC_FILTER" ]; then
+        load_build_env_rules ".claude/epics/$EPIC_FILTER/build-env"
+    fi
+    AGENT_ENV_WITHHELD=()
+    while IFS= read -r name; do
+        AGENT_ENV_WITHHELD+=("$name")
+    done < <(withheld_env_vars)
+}
@@ -0,0 +1,112 @@
+#!/bin/bash
+# Build loop helpers: journal an epic's build to its feature thread in the
+# memory server, the thread named after the epic's branch as the memory
+# hooks name it. Sourced by build.sh.
+
+# Find or create the feature thread, reading the server settings as the
+# memory hooks do. A dry run doesn't contact the server, and one that
+# can't be reached turns the journal off.
+start_journal() {
+    JOURNAL_THREAD=""
+    JOURNAL_THREAD_ID=""
+    if [ -z "${CLIVE_MEMORY_URL:-}" ] && [ -f "$HOME/.claude/memory/env" ]; then
+        # shellcheck source=/dev/null
+        source "$HOME/.claude/memory/env"
+    fi
+    MEMORY_SERVER="${CLIVE_MEMORY_URL:-http://localhost:8741}"
+    if [ "$JOURNAL" != "1" ] || [ -z "$EPIC_FILTER" ]; then
+        return 0
+    fi
+    JOURNAL_THREAD="${BRANCH_NAME:-$EPIC_FILTER}"
+    if [ "$DRY_RUN" = false ] && ! JOURNAL_THREAD_ID=$(journal_thread_id "$JOURNAL_THREAD"); then
+        JOURNAL_THREAD=""
+        JOURNAL_THREAD_ID=""
+        echo "⚠️  Memory server at $MEMORY_SERVER not reachable - not journaling to a feature thread"
+    fi
+}
+
+# Call the memory server for the journal. Prints the response body.
+journal_api() {
+    local method="$1" path="$2" body="${3:-}"
+    local args=(-s -f --max-time "$JOURNAL_TIMEOUT" -X "$method")
+    if [ -n "${CLIVE_MEMORY_API_KEY:-}" ]; then
+        args+=(-H "Authorization: Bearer $CLIVE_MEMORY_API_KEY")
+    fi
+    if [ -n "${CLIVE_NAMESPACE:-}" ]; then
+        args+=(-H "X-Clive-Namespace: $CLIVE_NAMESPACE")
+    fi
+    if [ -n "${CLIVE_MEMORY_CA_FILE:-}" ]; then
+        args+=(--cacert "$CLIVE_MEMORY_CA_FILE")
+    fi
+    if [ -n "$body" ]; then
+        args+=(-H "Content-Type: application/json" -d "$body")
+    fi
+    curl "${args[@]}" "$MEMORY_SERVER/v1$path" 2>/dev/null
+}
+
+# The ID of the open feature thread with the given name in this
+# workspace, creating the thread if there is none.
+journal_thread_id() {
+    local name="$1" response
+    response=$(journal_api GET "/threads?workspace=$(jq -rn --arg s "$WORKING_DIR" '$s | @uri')&name=$(jq -rn --arg s "$name" '$s | @uri')") || return 1
+    response=$(echo "$response" | jq -c '[.threads[]? | select(.status != "closed")][0] // empty')
+    if [ -z "$response" ]; then
+        response=$(journal_api POST /threads "$(jq -n --arg ws "$WORKING_DIR" --arg name "$name" --arg epic "$EPIC_FILTER" \
+            '{workspace: $ws, name: $name, description: "Feature thread for epic \($epic), journaled by the build loop"}')") || return 1
+    fi
+    echo "$response" | jq -er '.id'
+}
+
+# Append the lines of stdin to the feature thread as entries in the given
+# section, with the given memory type.
+journal() {
+    local section="$1" memory_type="$2" entries
+    [ -n "$JOURNAL_THREAD_ID" ] || return 0
+    entries=$(jq -Rn --arg section "$section" --arg type "$memory_type" \
+        '[inputs | select(test("\\S")) | {content: ., section: $section, memoryType: $type}]')
+    [ "$entries" != "[]" ] || return 0
+    if ! journal_api POST "/threads/$JOURNAL_THREAD_ID/entries/batch" \
+        "$(jq -n --arg ws "$WORKING_DIR" --argjson entries "$entries" '{workspace: $ws, entries: $entries}')" >/dev/null; then
+        echo "⚠️  Could not journal to feature thread $JOURNAL_THREAD"
+    fi
+}
+
+# Decisions the agent stated in the iteration, one per line: lines of its
+# output starting "Decision:" and the bullets it added under a "Key
+# Decisions" heading in the scratchpad (see skills/feature.md).
+iteration_decisions() {
+    local diff_file="$SCRATCHPAD_DIFF_DIR/iteration-$1.diff"
+    {
+        if [ -f "$AGENT_OUTPUT" ]; then
+            jq -rR 'fromjson? | select(.type == "assistant") | .message.content[]? | select(.type == "text") | .text' "$AGENT_OUTPUT"
+        fi
+        if [ -f "$diff_file" ]; then
+            tail -n +3 "$diff_file" | sed -n 's/^+//p'
+        fi
+    } | awk '
+        /^[[:space:]]*#+[[:space:]]*[Kk]ey [Dd]ecisions/ { in_list = 1; next }
+        in_list && /^[[:space:]]*[-*][[:space:]]+/ {
+            sub(/^[[:space:]]*[-*][[:space:]]+/, "")
+            if ($0 !~ /^\[.*\]$/) print
+            next
+        }
+        /^[[:space:]]*$/ { next }
+        { in_list = 0 }
+        tolower($0) ~ /^[[:space:]]*(\*\*)?decision(:\*\*|\*\*:|:)[[:space:]]/ {
+            sub(/^[[:space:]]*(\*\*)?[^:]*:(\*\*)?[[:space:]]+/, "")
+            print
+        }
+    ' | awk '!seen[$0]++'
+}
+
+# How the iteration ended, in a line, with what it changed from its
+# ITERATION_REPORT record.
+iteration_summary() {
+    local iteration="$1" result="$2" record changes=""
+    record=$(tail -n 1 "$ITERATION_REPORT" 2>/dev/null || true)
+    if [ -n "$record" ] && [ "$(echo "$record" | jq -r '.iteration')" = "$iteration" ]; then
+        changes=$(echo "$record" | jq -r '", changed \(.changed | length) file(s)"
+            + if (.outOfScope | length) > 0 then ", outside the task scope: \(.outOfScope | join(", "))" else "" end')
+    fi
+    echo "Build iteration $iteration${TASK_ID:+ ($TASK_ID: $TASK_TITLE)} $result after $((ATTEMPT + 1)) attempt(s)$changes"
+}
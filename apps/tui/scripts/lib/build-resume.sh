@@ -0,0 +1,58 @@
+#!/bin/bash
+# Build loop helpers: save the loop's state after every iteration and
+# restore it for --resume. The state lives with the directory the build
+# was started from, not the epic's worktree. Sourced by build.sh.
+
+# Restore the saved loop. Flags given alongside --resume win over the
+# saved values.
+restore_session_state() {
+    if [ ! -f "$SESSION_STATE" ]; then
+        echo "❌ Error: No saved build to resume ($SESSION_STATE not found)"
+        exit 1
+    fi
+    SAVED_STATUS=$(jq -r '.status // empty' "$SESSION_STATE")
+    if [ "$SAVED_STATUS" = "complete" ]; then
+        echo "✅ The saved build already finished - nothing to resume"
+        exit 0
+    fi
+    [ -z "$EPIC_FILTER" ] && EPIC_FILTER=$(jq -r '.epic // empty' "$SESSION_STATE")
+    [ -z "$SKILL_OVERRIDE" ] && SKILL_OVERRIDE=$(jq -r '.skill // empty' "$SESSION_STATE")
+    [ -z "$WORKTREE_PATH_OVERRIDE" ] && WORKTREE_PATH_OVERRIDE=$(jq -r '.worktreePath // empty' "$SESSION_STATE")
+    [ -z "$EXTRA_CONTEXT" ] && EXTRA_CONTEXT=$(jq -r '.extraContext // empty' "$SESSION_STATE")
+    if [ "$MAX_ITERATIONS_SET" = false ]; then
+        MAX_ITERATIONS=$(jq -r '.maxIterations // 50' "$SESSION_STATE")
+    fi
+    START_ITERATION=$(jq -r '.nextIteration // 1' "$SESSION_STATE")
+    if [ "$START_ITERATION" -gt "$MAX_ITERATIONS" ]; then
+        echo "❌ Error: The saved build used all $MAX_ITERATIONS iterations - pass a higher --max-iterations to continue"
+        exit 1
+    fi
+    echo "⏯️  Resuming build from iteration $START_ITERATION (last status: ${SAVED_STATUS:-unknown})"
+}
+
+# Save the loop state for --resume. BUILD_STATUS is "running" while the
+# loop goes, then how it ended. A build stopped by a signal is saved as
+# "interrupted" and one killed outright is left as "running"; both resume
+# at the iteration they were in.
+save_session_state() {
+    [ -n "$BUILD_STATUS" ] || return 0
+    mkdir -p "$(dirname "$SESSION_STATE")"
+    jq -n \
+        --arg status "$BUILD_STATUS" \
+        --argjson nextIteration "$NEXT_ITERATION" \
+        --argjson maxIterations "$MAX_ITERATIONS" \
+        --arg epic "$EPIC_FILTER" \
+        --arg skill "$SKILL_OVERRIDE" \
+        --arg worktreePath "${WORKTREE_PATH:-}" \
+        --arg branch "$BRANCH_NAME" \
+        --arg taskId "${TASK_ID:-}" \
+        --arg checkpoint "${CHECKPOINT_SHA:-}" \
+        --arg extraContext "$EXTRA_CONTEXT" \
+        --arg progressFile "$WORKING_DIR/$PROGRESS_FILE" \
+        --arg updatedAt "$(date -Iseconds)" \
+        '{status: $status, nextIteration: $nextIteration, maxIterations: $maxIterations,
+          epic: $epic, skill: $skill, worktreePath: $worktreePath, branch: $branch,
+          lastTaskId: $taskId, lastCheckpoint: $checkpoint, extraContext: $extraContext,
+          progressFile: $progressFile, updatedAt: $updatedAt}' > "$SESSION_STATE.tmp" &&
+        mv "$SESSION_STATE.tmp" "$SESSION_STATE"
+}
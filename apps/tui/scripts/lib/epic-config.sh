@@ -0,0 +1,92 @@
+#!/bin/bash
+# Per-epic config: environment variables and setup/teardown commands from
+# .clive/epics/<id>.yaml, the file the TUI's E key edits.
+# Sourced by build.sh and plan.sh.
+
+EPIC_ENV=()
+EPIC_SETUP=()
+EPIC_TEARDOWN=()
+EPIC_SETUP_STARTED=false
+
+# Path of an epic's config under a workspace, with the ID kept to one
+# path segment the way the TUI names it.
+epic_config_file() {
+    echo "$1/.clive/epics/${2//[^A-Za-z0-9._-]/-}.yaml"
+}
+
+# Strip one pair of matching quotes around a value.
+epic_config_unquote() {
+    local value="$1"
+    if [[ $value =~ ^\"(.*)\"$ ]] || [[ $value =~ ^\'(.*)\'$ ]]; then
+        value="${BASH_REMATCH[1]}"
+    fi
+    printf '%s' "$value"
+}
+
+# Read an epic's config into EPIC_ENV, EPIC_SETUP and EPIC_TEARDOWN.
+load_epic_config() {
+    local file="$1" line trimmed section="" n=0
+    [ -f "$file" ] || return 0
+    while IFS= read -r line || [ -n "$line" ]; do
+        n=$((n + 1))
+        line="${line%%[[:space:]]#*}"
+        trimmed="${line#"${line%%[![:space:]]*}"}"
+        trimmed="${trimmed%"${trimmed##*[![:space:]]}"}"
+        [ -z "$trimmed" ] || [[ $trimmed == \#* ]] && continue
+        if [[ $line != [[:space:]]* ]]; then
+            case "$trimmed" in
+                env:|setup:|teardown:) section="${trimmed%:}" ;;
+                *) section="" ;;
+            esac
+            [ -n "$section" ] && continue
+        elif [ "$section" = "env" ] && [[ $trimmed =~ ^([A-Za-z_][A-Za-z0-9_]*):[[:space:]]*(.*)$ ]]; then
+            EPIC_ENV+=("${BASH_REMATCH[1]}=$(epic_config_unquote "${BASH_REMATCH[2]}")")
+            continue
+        elif [ "$section" = "setup" ] && [[ $trimmed =~ ^-[[:space:]]+(.+)$ ]]; then
+            EPIC_SETUP+=("$(epic_config_unquote "${BASH_REMATCH[1]}")")
+            continue
+        elif [ "$section" = "teardown" ] && [[ $trimmed =~ ^-[[:space:]]+(.+)$ ]]; then
+            EPIC_TEARDOWN+=("$(epic_config_unquote "${BASH_REMATCH[1]}")")
+            continue
+        fi
+        case "$section" in
+            env) echo "❌ Error: $file:$n: expected 'NAME: value', got '$trimmed'" ;;
+            setup|teardown) echo "❌ Error: $file:$n: expected '- command', got '$trimmed'" ;;
+            *) echo "❌ Error: $file:$n: expected 'env:', 'setup:' or 'teardown:', got '$trimmed'" ;;
+        esac
+        exit 1
+    done < "$file"
+}
+
+# Export the epic's variables.
+export_epic_env() {
+    local pair
+    for pair in "${EPIC_ENV[@]}"; do
+        export "${pair?}"
+    done
+}
+
+# Run the epic's setup commands, stopping at the first that fails.
+run_epic_setup() {
+    local cmd
+    EPIC_SETUP_STARTED=true
+    for cmd in "${EPIC_SETUP[@]}"; do
+        echo "🔧 Setup: $cmd"
+        if ! bash -c "$cmd"; then
+            echo "❌ Error: epic setup command failed: $cmd"
+            exit 1
+        fi
+    done
+}
+
+# Run the epic's teardown commands once setup has started, carrying on
+# past failures.
+run_epic_teardown() {
+    local cmd
+    [ "$EPIC_SETUP_STARTED" = true ] || return 0
+    EPIC_SETUP_STARTED=false
+    for cmd in "${EPIC_TEARDOWN[@]}"; do
+        echo "🧹 Teardown: $cmd"
+        bash -c "$cmd" || echo "⚠️  Epic teardown command failed: $cmd"
+    done
+}
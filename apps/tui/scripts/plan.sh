@@ -1,6 +1,7 @@
 #!/bin/bash
 # Plan command - creates work plan using beads epics/tasks
-# Usage: ./plan.sh [--streaming] [custom request]
+# Usage: ./plan.sh [--streaming] [--parent ID] [--template NAME] [custom request]
+#        ./plan.sh --from-file plan.md [--parent ID]
 
 set -e
 
@@ -13,6 +14,9 @@ while [ -L "$SOURCE" ]; do
 done
 SCRIPT_DIR="$(cd -P "$(dirname "$SOURCE")" && pwd)"
 
+# shellcheck source=lib/epic-config.sh
+source "$SCRIPT_DIR/lib/epic-config.sh"
+
 PLUGIN_DIR="$SCRIPT_DIR/../commands"
 PLAN_PROMPT="$PLUGIN_DIR/plan.md"
 LOCAL_SKILLS_DIR=".claude/skills"
@@ -20,6 +24,8 @@ LOCAL_SKILLS_DIR=".claude/skills"
 # Defaults
 STREAMING=false
 PARENT_ID=""
+FROM_FILE=""
+TEMPLATE=""
 
 # Parse arguments
 POSITIONAL_ARGS=()
@@ -33,6 +39,14 @@ while [[ $# -gt 0 ]]; do
             PARENT_ID="$2"
             shift 2
             ;;
+        --from-file)
+            FROM_FILE="$2"
+            shift 2
+            ;;
+        --template)
+            TEMPLATE="$2"
+            shift 2
+            ;;
         *)
             POSITIONAL_ARGS+=("$1")
             shift
@@ -43,9 +57,20 @@ done
 # Restore positional args
 set -- "${POSITIONAL_ARGS[@]}"
 
+# A plan that's already written is imported as tasks, without a planning session
+if [ -n "$FROM_FILE" ]; then
+    exec bun "$SCRIPT_DIR/../src/plan-from-file.ts" "$FROM_FILE" ${PARENT_ID:+--parent "$PARENT_ID"}
+fi
+
 # Export parent ID for planning agent (if provided, skip creating a new parent issue)
 export CLIVE_PARENT_ID="$PARENT_ID"
 
+# Apply the epic's env vars and setup/teardown commands when planning under one
+if [ -n "$PARENT_ID" ]; then
+    load_epic_config "$(epic_config_file "$(pwd)" "$PARENT_ID")"
+    export_epic_env
+fi
+
 # Generate unique slug for plan file
 PLAN_SLUG=$(date +%s)-$(openssl rand -hex 4 | cut -c1-8)
 
@@ -70,6 +95,21 @@ if [ ! -f "$PLAN_PROMPT" ]; then
     exit 1
 fi
 
+# Planning template: the project's .clive/templates first, then the built-ins
+TEMPLATE_FILE=""
+if [ -n "$TEMPLATE" ]; then
+    for candidate in ".clive/templates/$TEMPLATE.md" "$SCRIPT_DIR/../templates/plan/$TEMPLATE.md"; do
+        if [ -f "$candidate" ]; then
+            TEMPLATE_FILE="$candidate"
+            break
+        fi
+    done
+    if [ -z "$TEMPLATE_FILE" ]; then
+        echo "Error: Planning template '$TEMPLATE' not found in .clive/templates or $SCRIPT_DIR/../templates/plan"
+        exit 1
+    fi
+fi
+
 # Detect configured task tracker
 CLIVE_CONFIG="${CLIVE_CONFIG:-$HOME/.clive/config.json}"
 if [ -f "$CLIVE_CONFIG" ]; then
@@ -91,7 +131,8 @@ if [ "$TRACKER" = "beads" ]; then
         bd init || true
     fi
 elif [ "$TRACKER" = "linear" ]; then
-    LINEAR_TEAM_ID=$(jq -r '.linear.team_id // empty' "$CLIVE_CONFIG" 2>/dev/null)
+    # An existing epic's team, when the caller passes it, wins over the primary team
+    LINEAR_TEAM_ID="${CLIVE_TEAM_ID:-$(jq -r '.linear.team_id // empty' "$CLIVE_CONFIG" 2>/dev/null)}"
     if [ -z "$LINEAR_TEAM_ID" ]; then
         echo "Error: Linear team ID not configured in $CLIVE_CONFIG"
         exit 1
@@ -118,7 +159,7 @@ mkdir -p "$PLAN_DIR"
 TEMP_PROMPT=$(mktemp)
 mv "$TEMP_PROMPT" "${TEMP_PROMPT}.md"
 TEMP_PROMPT="${TEMP_PROMPT}.md"
-trap "rm -f $TEMP_PROMPT" EXIT
+trap 'rm -f "$TEMP_PROMPT"; run_epic_teardown' EXIT
 
 # Write prompt to temp file (strip frontmatter from source, inject args and context)
 {
@@ -131,8 +172,14 @@ trap "rm -f $TEMP_PROMPT" EXIT
     echo "All file paths should be relative to this directory unless absolute paths are needed."
     echo ""
     sed '1{/^---$/!q;};1,/^---$/d' "$PLAN_PROMPT"
+    if [ -n "$TEMPLATE_FILE" ]; then
+        echo ""
+        awk 'NR == 1 && /^---$/ { front = 1; next } front && /^---$/ { front = 0; next } !front' "$TEMPLATE_FILE"
+    fi
 } > "$TEMP_PROMPT"
 
+run_epic_setup
+
 echo "Starting Claude planning session..."
 echo ""
 
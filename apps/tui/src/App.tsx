@@ -32,6 +32,16 @@ import { OneDarkPro } from "./styles/theme";
 import type { Session } from "./types";
 import type { WorkerConfig } from "./types/views";
 import { buildClaudeCommand, type SessionMode } from "./utils/build-claude-command";
+import { ensureEpicConfig, loadEpicConfig } from "./utils/epic-config";
+import { loadPlanTemplates, type PlanTemplate } from "./utils/plan-templates";
+import {
+  buildIssueList,
+  conversationsForIssue,
+  filterConversations,
+  filterIssues,
+  incompleteBlockers,
+  UNATTACHED_GROUP_ID,
+} from "./utils/selection-filter";
 
 // Create QueryClient instance
 const queryClient = new QueryClient({
@@ -76,6 +86,15 @@ function AppContent() {
   const setupOptions = ["linear", "beads"];
   const [configFlow, setConfigFlow] = useState<"linear" | "beads" | null>(null);
 
+  // Blocked epic the user has been warned about; a second Enter starts it
+  const [confirmBlockedId, setConfirmBlockedId] = useState<string | null>(null);
+  // Planning template picker opened by P; option 0 plans without a template
+  const [templatePicker, setTemplatePicker] = useState<{
+    issue: Session;
+    templates: PlanTemplate[];
+    selectedIndex: number;
+  } | null>(null);
+
   // Tmux session manager (shared between worker and interactive modes)
   const tmuxRef = useRef<TmuxSessionManager | null>(null);
   const worktreeManagerRef = useRef<WorktreeManager | null>(null);
@@ -324,6 +343,11 @@ function AppContent() {
   // Selection state using XState machine
   const selectionState = useSelectionState(sessions, conversations);
 
+  // Moving off a blocked epic drops the pending confirmation
+  useEffect(() => {
+    setConfirmBlockedId(null);
+  }, [selectionState.selectedIndex, selectionState.searchQuery]);
+
   // Handler for conversation resume — creates a tmux window with --resume
   const handleConversationResume = useCallback(
     (conversation: Conversation) => {
@@ -485,6 +509,32 @@ function AppContent() {
         process.exit(0);
       }
     } else if (viewMode === "selection") {
+      // Template picker takes every key while it's open
+      if (templatePicker) {
+        const optionCount = templatePicker.templates.length + 1;
+        if (event.name === "escape") {
+          setTemplatePicker(null);
+        } else if (event.name === "up" || event.sequence === "k") {
+          setTemplatePicker({
+            ...templatePicker,
+            selectedIndex:
+              (templatePicker.selectedIndex - 1 + optionCount) % optionCount,
+          });
+        } else if (event.name === "down" || event.sequence === "j") {
+          setTemplatePicker({
+            ...templatePicker,
+            selectedIndex: (templatePicker.selectedIndex + 1) % optionCount,
+          });
+        } else if (event.name === "return" || event.name === "enter") {
+          setTemplatePicker(null);
+          handlePlanForIssue(
+            templatePicker.issue,
+            templatePicker.templates[templatePicker.selectedIndex - 1],
+          );
+        }
+        return;
+      }
+
       // Escape - clear search, go back to level 1, or go back
       if (event.name === "escape") {
         if (selectionState.searchQuery) {
@@ -504,6 +554,45 @@ function AppContent() {
         return;
       }
 
+      // P - plan new tasks under the highlighted epic. Once a search is
+      // being typed, P goes into the query like any other letter
+      if (
+        event.sequence === "P" &&
+        !selectionState.searchQuery &&
+        selectionState.isLevel1
+      ) {
+        const issue = filterIssues(
+          buildIssueList(sessions, conversations),
+          selectionState.searchQuery,
+        )[selectionState.selectedIndex];
+        if (issue && issue.id !== UNATTACHED_GROUP_ID) {
+          // Offer the planning templates first, when there are any
+          const templates = loadPlanTemplates(workspaceRoot);
+          if (templates.length > 0) {
+            setTemplatePicker({ issue, templates, selectedIndex: 0 });
+          } else {
+            handlePlanForIssue(issue);
+          }
+        }
+        return;
+      }
+
+      // E - edit the highlighted epic's env and setup/teardown commands
+      if (
+        event.sequence === "E" &&
+        !selectionState.searchQuery &&
+        selectionState.isLevel1
+      ) {
+        const issue = filterIssues(
+          buildIssueList(sessions, conversations),
+          selectionState.searchQuery,
+        )[selectionState.selectedIndex];
+        if (issue && issue.id !== UNATTACHED_GROUP_ID) {
+          handleEditEpicConfig(issue);
+        }
+        return;
+      }
+
       // Printable characters - add to search query
       if (
         event.sequence &&
@@ -529,6 +618,14 @@ function AppContent() {
         selectionState.navigateDown();
         return;
       }
+      if (event.name === "pageup" || event.name === "left") {
+        selectionState.pageUp();
+        return;
+      }
+      if (event.name === "pagedown" || event.name === "right") {
+        selectionState.pageDown();
+        return;
+      }
 
       // Enter to select
       if (event.name === "return" || event.name === "enter") {
@@ -538,74 +635,34 @@ function AppContent() {
             return;
           }
 
-          const issuesWithOther: Session[] = [];
-          const unattachedCount = conversations.filter(
-            (c) => !c.linearProjectId && !c.linearTaskId,
-          ).length;
-          if (unattachedCount > 0) {
-            issuesWithOther.push({
-              id: "__unattached__",
-              name: `Other Conversations (${unattachedCount})`,
-              createdAt: new Date(),
-              source: "linear" as const,
-            });
-          }
-          issuesWithOther.push(...sessions);
-
-          const filteredSessions = selectionState.searchQuery
-            ? issuesWithOther.filter((s) => {
-                const query = selectionState.searchQuery.toLowerCase();
-                const identifier =
-                  s.linearData?.identifier?.toLowerCase() || "";
-                const title = s.name.toLowerCase();
-                return identifier.includes(query) || title.includes(query);
-              })
-            : issuesWithOther;
-
-          const displayIssues = filteredSessions.slice(0, 10);
-          const issue = displayIssues[selectionState.selectedIndex];
+          const issue = filterIssues(
+            buildIssueList(sessions, conversations),
+            selectionState.searchQuery,
+          )[selectionState.selectedIndex];
           if (issue) {
-            if (issue.id === "__unattached__") {
+            if (issue.id === UNATTACHED_GROUP_ID) {
               selectionState.selectIssue(issue);
             } else {
-              handleCreateNewForIssue(issue);
+              handleStartIssue(issue);
             }
           }
         } else if (selectionState.isLevel2) {
           if (selectionState.selectedIndex === -1) {
             if (selectionState.selectedIssue) {
-              handleCreateNewForIssue(selectionState.selectedIssue);
+              handleStartIssue(selectionState.selectedIssue);
             }
             return;
           }
 
           if (!selectionState.selectedIssue) return;
 
-          const selectedIssue = selectionState.selectedIssue;
-          const isUnattachedGroup = selectedIssue.id === "__unattached__";
-
-          const conversationsForIssue = isUnattachedGroup
-            ? conversations.filter((c) => !c.linearProjectId && !c.linearTaskId)
-            : conversations.filter((c) => {
-                const issueLinearId = selectedIssue.linearData?.id;
-                return (
-                  c.linearProjectId === issueLinearId ||
-                  c.linearTaskId === issueLinearId
-                );
-              });
-
-          const filteredConversations = selectionState.searchQuery
-            ? conversationsForIssue.filter((c) => {
-                const query = selectionState.searchQuery.toLowerCase();
-                const display = c.display.toLowerCase();
-                const slug = c.slug?.toLowerCase() || "";
-                return display.includes(query) || slug.includes(query);
-              })
-            : conversationsForIssue;
-
-          const displayConversations = filteredConversations.slice(0, 10);
-          const conversation =
-            displayConversations[selectionState.selectedIndex];
+          const conversation = filterConversations(
+            conversationsForIssue(
+              selectionState.selectedIssue,
+              conversations,
+            ),
+            selectionState.searchQuery,
+          )[selectionState.selectedIndex];
           if (conversation) {
             handleConversationResume(conversation);
           }
@@ -678,11 +735,16 @@ function AppContent() {
       const prompt = issue.linearData?.identifier
         ? `Work on ${issue.linearData.identifier}: ${issue.name}`
         : `Work on: ${issue.name}`;
+      const epicConfig = loadEpicConfig(
+        workspaceRoot,
+        issue.linearData?.identifier || issue.id,
+      );
 
       const claudeCmd = buildClaudeCommand({
         prompt,
         workspaceRoot,
         permissionMode: "bypassPermissions",
+        ...epicConfig,
       });
 
       try {
@@ -699,6 +761,84 @@ function AppContent() {
     [workspaceRoot],
   );
 
+  // Handler for planning more tasks under an existing epic — spawns a plan
+  // session with the epic passed as parent, shaped by the chosen template
+  const handlePlanForIssue = useCallback(
+    (issue: Session, template?: PlanTemplate) => {
+      const identifier = issue.linearData?.identifier || issue.id;
+      const chatId = `plan-${issue.id.slice(0, 8)}-${Date.now()}`;
+      const epicConfig = loadEpicConfig(workspaceRoot, identifier);
+
+      const claudeCmd = buildClaudeCommand({
+        mode: "plan",
+        prompt: `Plan new tasks for ${identifier}: ${issue.name}`,
+        workspaceRoot,
+        permissionMode: "bypassPermissions",
+        appendSystemPrompt: template?.content,
+        setup: epicConfig?.setup,
+        teardown: epicConfig?.teardown,
+        env: {
+          ...epicConfig?.env,
+          CLIVE_PARENT_ID: issue.id,
+          CLIVE_EPIC_IDENTIFIER: identifier,
+          // Tasks go to the team that owns the epic
+          ...(issue.linearData && { CLIVE_TEAM_ID: issue.linearData.team.id }),
+        },
+      });
+
+      try {
+        tmuxRef.current?.createWindow({
+          id: chatId,
+          name: `plan-${identifier}`,
+          cwd: workspaceRoot,
+          command: claudeCmd,
+        });
+      } catch (error) {
+        console.error("Failed to create tmux window:", error);
+      }
+    },
+    [workspaceRoot],
+  );
+
+  // Handler for editing an epic's env and setup/teardown commands — opens
+  // .clive/epics/<id>.yaml in $EDITOR in a tmux window
+  const handleEditEpicConfig = useCallback(
+    (issue: Session) => {
+      const identifier = issue.linearData?.identifier || issue.id;
+      const editor = process.env.VISUAL || process.env.EDITOR || "vi";
+
+      try {
+        const configPath = ensureEpicConfig(workspaceRoot, identifier);
+        tmuxRef.current?.createWindow({
+          id: `env-${issue.id.slice(0, 8)}-${Date.now()}`,
+          name: `env-${identifier}`,
+          cwd: workspaceRoot,
+          command: `${editor} '${configPath.replace(/'/g, "'\\''")}'`,
+        });
+      } catch (error) {
+        console.error("Failed to open epic config:", error);
+      }
+    },
+    [workspaceRoot],
+  );
+
+  // Start work on an issue, asking for confirmation first when an epic
+  // blocking it is still incomplete
+  const handleStartIssue = useCallback(
+    (issue: Session) => {
+      if (
+        incompleteBlockers(issue).length > 0 &&
+        confirmBlockedId !== issue.id
+      ) {
+        setConfirmBlockedId(issue.id);
+        return;
+      }
+      setConfirmBlockedId(null);
+      handleCreateNewForIssue(issue);
+    },
+    [confirmBlockedId, handleCreateNewForIssue],
+  );
+
   // Handler for config flow completion
   const handleConfigComplete = (configData: {
     apiKey: string;
@@ -797,6 +937,7 @@ function AppContent() {
         currentConfig={{
           apiKey: config?.linear?.apiKey || "",
           teamID: config?.linear?.teamID || "",
+          teamIDs: config?.linear?.teamIDs,
         }}
         onSave={(linearConfig) => {
           updateConfig({
@@ -825,13 +966,26 @@ function AppContent() {
         selectedIndex={selectionState.selectedIndex}
         searchQuery={selectionState.searchQuery}
         selectedIssue={selectionState.selectedIssue}
+        confirmBlockedId={confirmBlockedId}
+        templatePicker={
+          templatePicker && {
+            epicName:
+              templatePicker.issue.linearData?.identifier ||
+              templatePicker.issue.name,
+            options: [
+              { name: "No template", description: "" },
+              ...templatePicker.templates,
+            ],
+            selectedIndex: templatePicker.selectedIndex,
+          }
+        }
         onSelectIssue={(issue) => {
           selectionState.selectIssue(issue);
         }}
         onResumeConversation={handleConversationResume}
         onCreateNew={(issue) => {
           if (issue) {
-            handleCreateNewForIssue(issue);
+            handleStartIssue(issue);
           } else {
             handleCreateNewWithoutIssue();
           }
@@ -72,6 +72,20 @@ export function HelpView({ width, height, onClose }: HelpViewProps) {
             <text fg={OneDarkPro.foreground.secondary}>New session</text>
           </text>
 
+          <text fg={OneDarkPro.foreground.primary}>
+            <text fg={OneDarkPro.syntax.yellow}>
+              <b>P{" "}</b>
+            </text>
+            <text fg={OneDarkPro.foreground.secondary}>Plan tasks for the highlighted epic, picking a template if any</text>
+          </text>
+
+          <text fg={OneDarkPro.foreground.primary}>
+            <text fg={OneDarkPro.syntax.yellow}>
+              <b>E{" "}</b>
+            </text>
+            <text fg={OneDarkPro.foreground.secondary}>Edit the highlighted epic's env and setup commands</text>
+          </text>
+
           <text fg={OneDarkPro.foreground.primary}>
             <text fg={OneDarkPro.syntax.yellow}>
               <b>↑/k{" "}</b>
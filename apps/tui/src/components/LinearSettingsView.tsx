@@ -1,6 +1,6 @@
 /**
  * LinearSettingsView Component
- * Settings view for editing Linear API key and team after initial setup
+ * Settings view for editing Linear API key and teams after initial setup
  */
 
 import type { InputRenderable } from "@opentui/core";
@@ -23,8 +23,9 @@ interface LinearSettingsViewProps {
   currentConfig: {
     apiKey: string;
     teamID: string;
+    teamIDs?: string[];
   };
-  onSave: (config: { apiKey: string; teamID: string }) => void;
+  onSave: (config: { apiKey: string; teamID: string; teamIDs?: string[] }) => void;
   onCancel: () => void;
 }
 
@@ -49,6 +50,10 @@ export function LinearSettingsView({
   const [focusedField, setFocusedField] = useState<FocusedField>("api_key");
   const [apiKey, setApiKey] = useState(currentConfig.apiKey);
   const [teamID, setTeamID] = useState(currentConfig.teamID);
+  // Further teams whose epics are listed alongside the primary team's
+  const [extraTeamIDs, setExtraTeamIDs] = useState<string[]>(
+    currentConfig.teamIDs ?? [],
+  );
   const [currentTeam, setCurrentTeam] = useState<LinearTeam | null>(null);
   const [teams, setTeams] = useState<LinearTeam[]>([]);
   const [selectedTeamIndex, setSelectedTeamIndex] = useState(0);
@@ -115,6 +120,15 @@ export function LinearSettingsView({
         setSelectedTeamIndex((prev) =>
           prev < teams.length - 1 ? prev + 1 : 0,
         );
+      } else if (event.sequence === " ") {
+        const team = teams[selectedTeamIndex];
+        if (team) {
+          setExtraTeamIDs((prev) =>
+            prev.includes(team.id)
+              ? prev.filter((id) => id !== team.id)
+              : [...prev, team.id],
+          );
+        }
       } else if (event.name === "return") {
         const team = teams[selectedTeamIndex];
         if (team) {
@@ -182,6 +196,14 @@ export function LinearSettingsView({
     }
   };
 
+  // Config to save; extra teams are left out when there are none
+  const linearConfig = (primaryTeamID: string) => {
+    const teamIDs = extraTeamIDs.filter((id) => id !== primaryTeamID);
+    return teamIDs.length > 0
+      ? { apiKey, teamID: primaryTeamID, teamIDs }
+      : { apiKey, teamID: primaryTeamID };
+  };
+
   const handleTeamSelect = async (team: LinearTeam) => {
     setViewState("validating");
     setError("");
@@ -191,7 +213,7 @@ export function LinearSettingsView({
       setTeamID(team.id);
       setCurrentTeam(team);
       // Auto-save after team selection
-      onSave({ apiKey, teamID: team.id });
+      onSave(linearConfig(team.id));
     } catch (err) {
       setError(err instanceof Error ? err.message : "Validation failed");
       setViewState("selecting_team");
@@ -203,7 +225,7 @@ export function LinearSettingsView({
       setError("Both API key and team are required");
       return;
     }
-    onSave({ apiKey, teamID });
+    onSave(linearConfig(teamID));
   };
 
   return (
@@ -268,7 +290,7 @@ export function LinearSettingsView({
 
             {/* Team Field */}
             <box width={60} marginTop={3}>
-              <text fg={OneDarkPro.foreground.muted}>Team</text>
+              <text fg={OneDarkPro.foreground.muted}>Teams</text>
             </box>
             <box
               width={60}
@@ -289,6 +311,9 @@ export function LinearSettingsView({
                   {currentTeam
                     ? `${currentTeam.name} (${currentTeam.key})`
                     : teamID || "Not selected"}
+                  {extraTeamIDs.filter((id) => id !== teamID).length > 0
+                    ? ` + ${extraTeamIDs.filter((id) => id !== teamID).length} more`
+                    : ""}
                 </text>
                 <text fg={OneDarkPro.foreground.muted}>
                   {focusedField === "team" ? "[Enter to select]" : ""}
@@ -364,11 +389,16 @@ export function LinearSettingsView({
             <text fg={OneDarkPro.foreground.primary} marginTop={2}>
               Select your Linear team:
             </text>
+            <text fg={OneDarkPro.foreground.muted} marginTop={1}>
+              Enter picks the team new epics go to · Space also lists a team's epics
+            </text>
 
             <box marginTop={2} flexDirection="column" width={50}>
               {teams.slice(0, 9).map((team, i) => {
                 const isSelected = i === selectedTeamIndex;
                 const isCurrent = team.id === teamID;
+                const isExtra = !isCurrent && extraTeamIDs.includes(team.id);
+                const marker = isCurrent ? " ✓" : isExtra ? " +" : "";
 
                 return (
                   <box
@@ -389,9 +419,9 @@ export function LinearSettingsView({
                       }
                     >
                       {isSelected ? (
-                        <b>{"▸ "}{i + 1}. {team.name} ({team.key}){isCurrent ? " ✓" : ""}</b>
+                        <b>{"▸ "}{i + 1}. {team.name} ({team.key}){marker}</b>
                       ) : (
-                        <>{"  "}{i + 1}. {team.name} ({team.key}){isCurrent ? " ✓" : ""}</>
+                        <>{"  "}{i + 1}. {team.name} ({team.key}){marker}</>
                       )}
                     </text>
                   </box>
@@ -407,7 +437,7 @@ export function LinearSettingsView({
 
             <box marginTop={4} flexDirection="column" alignItems="center">
               <text fg={OneDarkPro.foreground.secondary}>
-                1-9 Select · ↑/↓ Navigate · Enter Confirm · Esc Cancel
+                1-9 Select · ↑/↓ Navigate · Space Also list · Enter Confirm · Esc Cancel
               </text>
             </box>
           </>
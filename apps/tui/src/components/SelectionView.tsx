@@ -8,13 +8,17 @@ import { useMemo } from "react";
 import type { Conversation } from "../services/ConversationService";
 import { OneDarkPro } from "../styles/theme";
 import type { Session } from "../types";
+import {
+  buildIssueList,
+  conversationsForIssue,
+  filterConversations,
+  filterIssues,
+  incompleteBlockers,
+  pageFor,
+  UNATTACHED_GROUP_ID,
+} from "../utils/selection-filter";
 import { LoadingSpinner } from "./LoadingSpinner";
 
-/**
- * Special session ID for the "Other Conversations" group
- */
-const UNATTACHED_GROUP_ID = "__unattached__";
-
 /**
  * Format timestamp as relative time
  */
@@ -44,6 +48,12 @@ interface SelectionViewProps {
   selectedIndex: number;
   searchQuery: string;
   selectedIssue: Session | null; // null = show issues, Session = show conversations for this issue
+  confirmBlockedId?: string | null; // blocked issue waiting for a second Enter
+  templatePicker?: {
+    epicName: string;
+    options: Array<{ name: string; description: string }>;
+    selectedIndex: number;
+  } | null; // planning template choice open after P
   onSelectIssue: (session: Session) => void;
   onResumeConversation: (conversation: Conversation) => void;
   onCreateNew: (issue?: Session) => void;
@@ -62,71 +72,39 @@ export function SelectionView({
   selectedIndex,
   searchQuery,
   selectedIssue,
+  confirmBlockedId,
+  templatePicker,
   onSelectIssue,
   onResumeConversation,
   onCreateNew,
   onBack,
 }: SelectionViewProps) {
-  // Separate conversations into attached and unattached
-  const { attachedConversations, unattachedConversations } = useMemo(() => {
-    const attached: Conversation[] = [];
-    const unattached: Conversation[] = [];
-
-    conversations.forEach((conv) => {
-      if (conv.linearProjectId || conv.linearTaskId) {
-        attached.push(conv);
-      } else {
-        unattached.push(conv);
-      }
-    });
-
-    // Sort unattached by timestamp (newest first)
-    unattached.sort((a, b) => b.timestamp - a.timestamp);
-
-    return {
-      attachedConversations: attached,
-      unattachedConversations: unattached,
-    };
-  }, [conversations]);
-
-  // Create "Other Conversations" group if there are unattached conversations
-  const issuesWithOther = useMemo(() => {
-    const items: Session[] = [];
-
-    // Always add "Other Conversations" at the TOP if there are unattached conversations
-    if (unattachedConversations.length > 0) {
-      items.push({
-        id: UNATTACHED_GROUP_ID,
-        name: `Other Conversations (${unattachedConversations.length})`,
-        createdAt: new Date(),
-        source: "linear" as const,
-        // No linearData - this marks it as the unattached group
-      });
-    }
-
-    // Add all Linear sessions after
-    items.push(...sessions);
-
-    return items;
-  }, [sessions, unattachedConversations.length]);
+  // "Other Conversations" group first, then issues by recent activity
+  const issuesWithOther = useMemo(
+    () => buildIssueList(sessions, conversations),
+    [sessions, conversations],
+  );
 
   // Level 1: Show issues (when selectedIssue is null)
   // Level 2: Show conversations for selected issue (when selectedIssue is set)
 
   if (!selectedIssue) {
-    // Level 1: Filter sessions/issues by search query (including "Other Conversations")
-    const filteredSessions = searchQuery
-      ? issuesWithOther.filter((s) => {
-          const query = searchQuery.toLowerCase();
-          const identifier = s.linearData?.identifier?.toLowerCase() || "";
-          const title = s.name.toLowerCase();
-          return identifier.includes(query) || title.includes(query);
-        })
-      : issuesWithOther;
-
-    const displayIssues = filteredSessions.slice(0, 10);
+    // Level 1: Fuzzy-filter issues by search query (including "Other Conversations")
+    const filteredSessions = filterIssues(issuesWithOther, searchQuery);
+
+    const { page, pageCount, start, end } = pageFor(
+      selectedIndex,
+      filteredSessions.length,
+    );
+    const displayIssues = filteredSessions.slice(start, end);
     const totalDisplayed = displayIssues.length;
 
+    // Unfinished epics blocking the highlighted issue
+    const highlighted = filteredSessions[selectedIndex];
+    const highlightedBlockers = highlighted
+      ? incompleteBlockers(highlighted)
+      : [];
+
     return (
       <box
         width={width}
@@ -247,6 +225,7 @@ export function SelectionView({
                   {searchQuery
                     ? ` (${issuesWithOther.length} total)`
                     : " items"}
+                  {pageCount > 1 ? ` · page ${page + 1}/${pageCount}` : ""}
                 </text>
 
                 {/* Items */}
@@ -284,14 +263,16 @@ export function SelectionView({
 
                     {/* Linear issues and Other Conversations */}
                     {displayIssues.map((session, i) => {
-                      const isSelected = i === selectedIndex;
+                      const isSelected = start + i === selectedIndex;
                       const isUnattachedGroup =
                         session.id === UNATTACHED_GROUP_ID;
 
                       // Get identifier from linearData if available
                       const identifier = session.linearData?.identifier || "";
                       const prefix = identifier ? `${identifier} ` : "";
-                      const maxNameLength = identifier ? 30 : 35;
+                      const isBlocked = incompleteBlockers(session).length > 0;
+                      const maxNameLength =
+                        (identifier ? 30 : 35) - (isBlocked ? 2 : 0);
 
                       const name =
                         session.name.length > maxNameLength
@@ -309,6 +290,7 @@ export function SelectionView({
                               ? OneDarkPro.background.highlight
                               : "transparent"
                           }
+                          flexDirection="row"
                           paddingLeft={1}
                           paddingRight={1}
                           borderStyle={
@@ -337,6 +319,9 @@ export function SelectionView({
                               <>{isSelected ? "▸ " : "  "}{icon} {identifier ? prefix : ""}{name}</>
                             )}
                           </text>
+                          {isBlocked && (
+                            <text fg={OneDarkPro.syntax.yellow}>{" ⛔"}</text>
+                          )}
                         </box>
                       );
                     })}
@@ -345,10 +330,61 @@ export function SelectionView({
               </box>
             )}
 
+          {/* Dependency warning for the highlighted issue */}
+          {highlightedBlockers.length > 0 && (
+            <box marginTop={1} flexDirection="column" alignItems="center">
+              <text fg={OneDarkPro.syntax.yellow}>
+                ⛔ Blocked by{" "}
+                {highlightedBlockers.map((b) => b.name).join(", ")}
+              </text>
+              {confirmBlockedId === highlighted?.id && (
+                <text fg={OneDarkPro.syntax.yellow}>
+                  Press Enter again to start it anyway
+                </text>
+              )}
+            </box>
+          )}
+
+          {/* Planning template picker */}
+          {templatePicker && (
+            <box
+              marginTop={1}
+              padding={1}
+              width={70}
+              flexDirection="column"
+              borderStyle="rounded"
+              borderColor={OneDarkPro.syntax.blue}
+            >
+              <text fg={OneDarkPro.foreground.primary}>
+                Plan {templatePicker.epicName} with a template:
+              </text>
+              {templatePicker.options.map((option, i) => {
+                const isSelected = i === templatePicker.selectedIndex;
+                return (
+                  <text
+                    key={option.name}
+                    fg={
+                      isSelected
+                        ? OneDarkPro.syntax.blue
+                        : OneDarkPro.foreground.primary
+                    }
+                  >
+                    {isSelected ? "▸ " : "  "}
+                    {option.name}
+                    {option.description ? ` — ${option.description}` : ""}
+                  </text>
+                );
+              })}
+              <text fg={OneDarkPro.foreground.muted}>
+                ↑↓ Select • Enter Plan • Esc Cancel
+              </text>
+            </box>
+          )}
+
           {/* Keyboard hints */}
           <box marginTop={4} flexDirection="column" alignItems="center">
             <text fg={OneDarkPro.foreground.muted}>
-              Type to search • ↑↓ Select • Enter Choose • Esc Back • q Quit
+              Type to search • ↑↓ Select • ←→ Page • Enter Choose • P Plan • E Env • Esc Back • q Quit
             </text>
           </box>
         </box>
@@ -360,28 +396,23 @@ export function SelectionView({
   // Check if this is the "Other Conversations" group
   const isUnattachedGroup = selectedIssue.id === UNATTACHED_GROUP_ID;
 
-  // Filter conversations based on selection
-  const conversationsForIssue = isUnattachedGroup
-    ? unattachedConversations
-    : attachedConversations.filter((c) => {
-        const issueLinearId = selectedIssue.linearData?.id;
-        return (
-          c.linearProjectId === issueLinearId ||
-          c.linearTaskId === issueLinearId
-        );
-      });
-
-  // Filter by search query
-  const filteredConversations = searchQuery
-    ? conversationsForIssue.filter((c) => {
-        const query = searchQuery.toLowerCase();
-        const display = c.display.toLowerCase();
-        const slug = c.slug?.toLowerCase() || "";
-        return display.includes(query) || slug.includes(query);
-      })
-    : conversationsForIssue;
-
-  const displayConversations = filteredConversations.slice(0, 10);
+  // Conversations for this issue, newest first
+  const issueConversations = conversationsForIssue(
+    selectedIssue,
+    conversations,
+  );
+
+  // Fuzzy-filter by search query
+  const filteredConversations = filterConversations(
+    issueConversations,
+    searchQuery,
+  );
+
+  const { page, pageCount, start, end } = pageFor(
+    selectedIndex,
+    filteredConversations.length,
+  );
+  const displayConversations = filteredConversations.slice(start, end);
   const totalDisplayed = displayConversations.length;
 
   // Level 2: Render conversations for the selected issue
@@ -428,7 +459,7 @@ export function SelectionView({
         )}
 
         {/* Empty state */}
-        {!conversationsLoading && conversationsForIssue.length === 0 && (
+        {!conversationsLoading && issueConversations.length === 0 && (
           <box marginTop={3} flexDirection="column" alignItems="center">
             <text fg={OneDarkPro.foreground.muted}>
               No conversations for this issue yet.
@@ -441,7 +472,7 @@ export function SelectionView({
 
         {/* Conversation list */}
         {!conversationsLoading &&
-          (conversationsForIssue.length > 0 || !searchQuery) && (
+          (issueConversations.length > 0 || !searchQuery) && (
             <box marginTop={2} flexDirection="column" width={70}>
               {/* Search box */}
               <box
@@ -475,8 +506,9 @@ export function SelectionView({
               <text fg={OneDarkPro.foreground.muted}>
                 {totalDisplayed} of {filteredConversations.length}
                 {searchQuery
-                  ? ` (${conversationsForIssue.length} total)`
+                  ? ` (${issueConversations.length} total)`
                   : " conversations"}
+                {pageCount > 1 ? ` · page ${page + 1}/${pageCount}` : ""}
               </text>
 
               {/* Items */}
@@ -514,7 +546,7 @@ export function SelectionView({
 
                   {/* Conversations for this issue */}
                   {displayConversations.map((conversation, i) => {
-                    const isSelected = i === selectedIndex;
+                    const isSelected = start + i === selectedIndex;
 
                     // Format timestamp
                     const date = new Date(conversation.timestamp);
@@ -563,7 +595,7 @@ export function SelectionView({
         {/* Keyboard hints */}
         <box marginTop={4} flexDirection="column" alignItems="center">
           <text fg={OneDarkPro.foreground.muted}>
-            Type to search • ↑↓ Select • Enter Resume/Start • Esc{" "}
+            Type to search • ↑↓ Select • ←→ Page • Enter Resume/Start • Esc{" "}
             {searchQuery ? "Clear" : "Back to Issues"} • q Quit
           </text>
         </box>
@@ -1,10 +1,21 @@
 /**
  * Sidebar Component
- * Shows task list grouped by status with modern, fun design
+ * Shows task list grouped by status with modern, fun design.
+ * When focused: / filters by title or ID, Tab cycles status tabs,
+ * ↑↓ and PgUp/PgDn scroll the list.
  */
 
+import { useKeyboard } from "@opentui/react";
+import { useState } from "react";
 import { OneDarkPro } from "../styles/theme";
 import type { Session, Task } from "../types";
+import {
+  clampScroll,
+  filterTasks,
+  nextTaskTab,
+  TASK_TABS,
+  type TaskTab,
+} from "../utils/task-filter";
 import { getTaskStatus } from "../utils/taskHelpers";
 
 interface SidebarProps {
@@ -13,6 +24,31 @@ interface SidebarProps {
   tasks: Task[];
   activeSession?: Session | null;
   layout?: "vertical" | "horizontal";
+  focused?: boolean;
+}
+
+const TAB_LABELS: Record<TaskTab, string> = {
+  all: "All ",
+  in_progress: "⚡",
+  blocked: "⊗",
+  pending: "○",
+  completed: "✓",
+};
+
+function getStatusIcon(task: Task): string {
+  const status = getTaskStatus(task);
+  if (status === "in_progress") return "⚡";
+  if (status === "blocked") return "⊗";
+  if (status === "completed") return "✓";
+  return "○";
+}
+
+function getStatusColor(task: Task): string {
+  const status = getTaskStatus(task);
+  if (status === "in_progress") return OneDarkPro.syntax.yellow;
+  if (status === "blocked") return OneDarkPro.syntax.red;
+  if (status === "completed") return OneDarkPro.syntax.green;
+  return OneDarkPro.syntax.cyan;
 }
 
 export function Sidebar({
@@ -21,12 +57,81 @@ export function Sidebar({
   tasks,
   activeSession,
   layout = "vertical",
+  focused = false,
 }: SidebarProps) {
-  // Group tasks by status
-  const inProgress = tasks.filter((t) => getTaskStatus(t) === "in_progress");
-  const pending = tasks.filter((t) => getTaskStatus(t) === "pending");
+  const [query, setQuery] = useState("");
+  const [filtering, setFiltering] = useState(false);
+  const [tab, setTab] = useState<TaskTab>("all");
+  const [scrollOffset, setScrollOffset] = useState(0);
+
   const completed = tasks.filter((t) => getTaskStatus(t) === "completed");
-  const blocked = tasks.filter((t) => getTaskStatus(t) === "blocked");
+  const filtered = filterTasks(tasks, query, tab);
+  const tabCount = (t: TaskTab) =>
+    t === "all"
+      ? tasks.length
+      : tasks.filter((task) => getTaskStatus(task) === t).length;
+
+  // Rows left for the task list: the vertical layout spends the rest on the
+  // logo, session name, tasks header, progress bar, tabs and filter box
+  const pageRows = Math.max(
+    layout === "horizontal" ? height - 2 : height - 16,
+    3,
+  );
+
+  // Keyboard handling when sidebar is focused
+  useKeyboard((event) => {
+    if (!focused) return;
+
+    if (filtering) {
+      if (event.name === "escape") {
+        setQuery("");
+        setFiltering(false);
+      } else if (event.name === "return") {
+        setFiltering(false);
+      } else if (event.name === "backspace") {
+        setQuery((q) => q.slice(0, -1));
+      } else if (
+        event.sequence &&
+        event.sequence.length === 1 &&
+        event.name !== "tab" &&
+        !event.ctrl &&
+        !event.meta
+      ) {
+        setQuery((q) => q + event.sequence);
+      }
+      setScrollOffset(0);
+      return;
+    }
+
+    if (event.sequence === "/") {
+      setFiltering(true);
+      return;
+    }
+    if (event.name === "tab") {
+      setTab(nextTaskTab);
+      setScrollOffset(0);
+      return;
+    }
+    if (event.name === "up" || event.sequence === "k") {
+      setScrollOffset((o) => clampScroll(o - 1, filtered.length, pageRows));
+      return;
+    }
+    if (event.name === "down" || event.sequence === "j") {
+      setScrollOffset((o) => clampScroll(o + 1, filtered.length, pageRows));
+      return;
+    }
+    if (event.name === "pageup") {
+      setScrollOffset((o) =>
+        clampScroll(o - pageRows, filtered.length, pageRows),
+      );
+      return;
+    }
+    if (event.name === "pagedown") {
+      setScrollOffset((o) =>
+        clampScroll(o + pageRows, filtered.length, pageRows),
+      );
+    }
+  });
 
   const truncate = (text: string, maxLen: number) => {
     return text.length > maxLen ? `${text.substring(0, maxLen - 1)}…` : text;
@@ -40,30 +145,13 @@ export function Sidebar({
 
   // --- Compact horizontal layout ---
   if (layout === "horizontal") {
-    // Prioritized task list: in_progress, blocked, pending, completed
-    const allTasks = [...inProgress, ...blocked, ...pending, ...completed];
     const barWidth = Math.max(width - 4, 10);
     const filledBars = Math.floor(barWidth * (progressPercent / 100));
     const emptyBars = barWidth - filledBars;
 
-    const getStatusIcon = (task: Task) => {
-      const status = getTaskStatus(task);
-      if (status === "in_progress") return "⚡";
-      if (status === "blocked") return "⊗";
-      if (status === "completed") return "✓";
-      return "○";
-    };
-
-    const getStatusColor = (task: Task) => {
-      const status = getTaskStatus(task);
-      if (status === "in_progress") return OneDarkPro.syntax.yellow;
-      if (status === "blocked") return OneDarkPro.syntax.red;
-      if (status === "completed") return OneDarkPro.syntax.green;
-      return OneDarkPro.syntax.cyan;
-    };
-
     // Row 1 = progress summary, remaining rows = task list
     const taskRows = Math.max(height - 2, 0);
+    const scroll = clampScroll(scrollOffset, filtered.length, taskRows);
 
     return (
       <box
@@ -89,20 +177,22 @@ export function Sidebar({
         </box>
 
         {/* Task rows */}
-        {allTasks.length === 0 && taskRows > 0 && (
-          <text fg={OneDarkPro.foreground.muted}>{"  No tasks yet"}</text>
+        {filtered.length === 0 && taskRows > 0 && (
+          <text fg={OneDarkPro.foreground.muted}>
+            {tasks.length === 0 ? "  No tasks yet" : "  No matching tasks"}
+          </text>
         )}
-        {allTasks.slice(0, taskRows).map((task, i) => (
-          <box key={i} flexDirection="row">
+        {filtered.slice(scroll, scroll + taskRows).map((task) => (
+          <box key={task.id} flexDirection="row">
             <text fg={getStatusColor(task)}>{getStatusIcon(task)} </text>
             <text fg={OneDarkPro.foreground.primary}>
               {truncate(task.title, width - 5)}
             </text>
           </box>
         ))}
-        {allTasks.length > taskRows && taskRows > 0 && (
+        {filtered.length > scroll + taskRows && taskRows > 0 && (
           <text fg={OneDarkPro.foreground.comment}>
-            {"  … "}{allTasks.length - taskRows}{" more"}
+            {"  ↓ "}{filtered.length - scroll - taskRows}{" more"}
           </text>
         )}
       </box>
@@ -110,7 +200,9 @@ export function Sidebar({
   }
 
   // --- Vertical layout (default) ---
-  const maxDisplay = 8;
+  const scroll = clampScroll(scrollOffset, filtered.length, pageRows);
+  const visible = filtered.slice(scroll, scroll + pageRows);
+  const below = filtered.length - scroll - visible.length;
 
   return (
     <box
@@ -177,109 +269,63 @@ export function Sidebar({
         </box>
       )}
 
-      {/* In Progress Section */}
-      {inProgress.length > 0 && (
+      {tasks.length > 0 && (
         <box flexDirection="column" marginTop={1}>
-          <box flexDirection="row" marginBottom={0}>
-            <text fg={OneDarkPro.syntax.yellow}>⚡ In Progress </text>
-            <text fg={OneDarkPro.foreground.muted}>({inProgress.length})</text>
-          </box>
-          {inProgress.slice(0, maxDisplay).map((task, i) => (
-            <box key={i} flexDirection="row" paddingLeft={1} marginTop={0}>
-              <text fg={OneDarkPro.syntax.yellow}>▸ </text>
-              <text fg={OneDarkPro.foreground.primary}>
-                {truncate(task.title, width - 5)}
+          {/* Status tabs */}
+          <box flexDirection="row">
+            {TASK_TABS.map((t) => (
+              <text
+                key={t}
+                fg={t === tab ? OneDarkPro.syntax.blue : OneDarkPro.foreground.muted}
+              >
+                {t === tab ? <b>{TAB_LABELS[t]}</b> : TAB_LABELS[t]}
+                {tabCount(t)}{" "}
               </text>
-            </box>
-          ))}
-          {inProgress.length > maxDisplay && (
+            ))}
+          </box>
+
+          {/* Filter box */}
+          <box flexDirection="row">
+            <text fg={filtering ? OneDarkPro.syntax.green : OneDarkPro.foreground.comment}>
+              {"/ "}
+            </text>
             <text
-              fg={OneDarkPro.foreground.comment}
-              paddingLeft={1}
-              marginTop={0}
+              fg={query ? OneDarkPro.foreground.primary : OneDarkPro.foreground.comment}
             >
-              … {inProgress.length - maxDisplay} more
+              {query || (filtering ? "" : "filter")}
+              {filtering ? "▏" : ""}
             </text>
-          )}
-        </box>
-      )}
-
-      {/* Blocked Section */}
-      {blocked.length > 0 && (
-        <box flexDirection="column" marginTop={1}>
-          <box flexDirection="row" marginBottom={0}>
-            <text fg={OneDarkPro.syntax.red}>🚫 Blocked </text>
-            <text fg={OneDarkPro.foreground.muted}>({blocked.length})</text>
           </box>
-          {blocked.slice(0, maxDisplay).map((task, i) => (
-            <box key={i} flexDirection="row" paddingLeft={1} marginTop={0}>
-              <text fg={OneDarkPro.syntax.red}>⊗ </text>
-              <text fg={OneDarkPro.foreground.muted}>
-                {truncate(task.title, width - 5)}
-              </text>
-            </box>
-          ))}
-          {blocked.length > maxDisplay && (
-            <text
-              fg={OneDarkPro.foreground.comment}
-              paddingLeft={1}
-              marginTop={0}
-            >
-              … {blocked.length - maxDisplay} more
+
+          {scroll > 0 && (
+            <text fg={OneDarkPro.foreground.comment} paddingLeft={1}>
+              ↑ {scroll} more
             </text>
           )}
-        </box>
-      )}
-
-      {/* Pending Section */}
-      {pending.length > 0 && (
-        <box flexDirection="column" marginTop={1}>
-          <box flexDirection="row" marginBottom={0}>
-            <text fg={OneDarkPro.syntax.cyan}>⏳ Pending </text>
-            <text fg={OneDarkPro.foreground.muted}>({pending.length})</text>
-          </box>
-          {pending.slice(0, maxDisplay).map((task, i) => (
-            <box key={i} flexDirection="row" paddingLeft={1} marginTop={0}>
-              <text fg={OneDarkPro.syntax.cyan}>○ </text>
-              <text fg={OneDarkPro.foreground.muted}>
-                {truncate(task.title, width - 5)}
-              </text>
-            </box>
-          ))}
-          {pending.length > maxDisplay && (
-            <text
-              fg={OneDarkPro.foreground.comment}
-              paddingLeft={1}
-              marginTop={0}
-            >
-              … {pending.length - maxDisplay} more
+          {filtered.length === 0 && (
+            <text fg={OneDarkPro.foreground.comment} paddingLeft={1}>
+              No matching tasks
             </text>
           )}
-        </box>
-      )}
-
-      {/* Completed Section */}
-      {completed.length > 0 && (
-        <box flexDirection="column" marginTop={1}>
-          <box flexDirection="row" marginBottom={0}>
-            <text fg={OneDarkPro.syntax.green}>✓ Done </text>
-            <text fg={OneDarkPro.foreground.muted}>({completed.length})</text>
-          </box>
-          {completed.slice(0, maxDisplay).map((task, i) => (
-            <box key={i} flexDirection="row" paddingLeft={1} marginTop={0}>
-              <text fg={OneDarkPro.syntax.green}>✓ </text>
-              <text fg={OneDarkPro.foreground.comment}>
+          {visible.map((task) => (
+            <box key={task.id} flexDirection="row" paddingLeft={1}>
+              <text fg={getStatusColor(task)}>{getStatusIcon(task)} </text>
+              <text
+                fg={
+                  getTaskStatus(task) === "in_progress"
+                    ? OneDarkPro.foreground.primary
+                    : getTaskStatus(task) === "completed"
+                      ? OneDarkPro.foreground.comment
+                      : OneDarkPro.foreground.muted
+                }
+              >
                 {truncate(task.title, width - 5)}
               </text>
             </box>
           ))}
-          {completed.length > maxDisplay && (
-            <text
-              fg={OneDarkPro.foreground.comment}
-              paddingLeft={1}
-              marginTop={0}
-            >
-              … {completed.length - maxDisplay} more
+          {below > 0 && (
+            <text fg={OneDarkPro.foreground.comment} paddingLeft={1}>
+              ↓ {below} more
             </text>
           )}
         </box>
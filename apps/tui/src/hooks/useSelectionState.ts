@@ -8,6 +8,11 @@ import { useEffect, useRef } from "react";
 import { assign, setup } from "xstate";
 import type { Conversation } from "../services/ConversationService";
 import type { Session } from "../types";
+import {
+  SELECTION_PAGE_SIZE,
+  selectableItems,
+  UNATTACHED_GROUP_ID,
+} from "../utils/selection-filter";
 
 export interface SelectionContext {
   // Level tracking
@@ -31,8 +36,28 @@ export type SelectionEvent =
   | { type: "CLEAR_SEARCH" }
   | { type: "NAVIGATE_UP" }
   | { type: "NAVIGATE_DOWN" }
+  | { type: "PAGE_UP" }
+  | { type: "PAGE_DOWN" }
   | { type: "UPDATE_DATA"; sessions: Session[]; conversations: Conversation[] };
 
+/**
+ * Range of selectedIndex at the current level. -1 is the "Create New" row,
+ * which is hidden while searching and in the "Other Conversations" group.
+ */
+function selectionBounds(context: SelectionContext) {
+  const { selectedIssue, searchQuery, sessions, conversations } = context;
+  const total = selectableItems(
+    selectedIssue,
+    sessions,
+    conversations,
+    searchQuery,
+  ).length;
+  const canCreate =
+    !searchQuery && selectedIssue?.id !== UNATTACHED_GROUP_ID;
+  const minIndex = canCreate ? -1 : 0;
+  return { minIndex, maxIndex: Math.max(total - 1, minIndex) };
+}
+
 /**
  * Selection State Machine
  * Two-level hierarchy: level1 (issues) -> level2 (conversations)
@@ -69,138 +94,31 @@ const selectionMachine = setup({
     }),
     navigateUp: assign({
       selectedIndex: ({ context }) => {
-        const {
-          selectedIssue,
-          selectedIndex,
-          searchQuery,
-          sessions,
-          conversations,
-        } = context;
-
-        if (!selectedIssue) {
-          // Level 1: Navigate issues
-          // Include "Other Conversations" in navigation calculation
-          const unattachedCount = conversations.filter(
-            (c) => !c.linearProjectId && !c.linearTaskId,
-          ).length;
-
-          const filteredSessions = searchQuery
-            ? sessions.filter((s) => {
-                const query = searchQuery.toLowerCase();
-                const identifier =
-                  s.linearData?.identifier?.toLowerCase() || "";
-                const title = s.name.toLowerCase();
-                return identifier.includes(query) || title.includes(query);
-              })
-            : sessions;
-
-          // Add 1 for "Other Conversations" if there are unattached conversations
-          const totalItems =
-            unattachedCount > 0
-              ? filteredSessions.length + 1
-              : filteredSessions.length;
-          const maxIndex = Math.min(totalItems - 1, 9);
-          const minIndex = searchQuery ? 0 : -1; // Allow -1 for "Create New"
-
-          return selectedIndex > minIndex ? selectedIndex - 1 : maxIndex;
-        } else {
-          // Level 2: Navigate conversations
-          // Check if this is the "Other Conversations" group (no linearData)
-          const isUnattachedGroup = !selectedIssue.linearData;
-
-          const conversationsForIssue = isUnattachedGroup
-            ? conversations.filter((c) => !c.linearProjectId && !c.linearTaskId)
-            : conversations.filter((c) => {
-                const issueLinearId = selectedIssue.linearData?.id;
-                return (
-                  c.linearProjectId === issueLinearId ||
-                  c.linearTaskId === issueLinearId
-                );
-              });
-
-          const filteredConversations = searchQuery
-            ? conversationsForIssue.filter((c) => {
-                const query = searchQuery.toLowerCase();
-                const display = c.display.toLowerCase();
-                const slug = c.slug?.toLowerCase() || "";
-                return display.includes(query) || slug.includes(query);
-              })
-            : conversationsForIssue;
-
-          const maxIndex = Math.min(filteredConversations.length - 1, 9);
-          // Don't allow "Create New" for unattached group
-          const minIndex = searchQuery || isUnattachedGroup ? 0 : -1;
-
-          return selectedIndex > minIndex ? selectedIndex - 1 : maxIndex;
-        }
+        const { selectedIndex } = context;
+        const { minIndex, maxIndex } = selectionBounds(context);
+        return selectedIndex > minIndex ? selectedIndex - 1 : maxIndex;
       },
     }),
     navigateDown: assign({
       selectedIndex: ({ context }) => {
-        const {
-          selectedIssue,
-          selectedIndex,
-          searchQuery,
-          sessions,
-          conversations,
-        } = context;
-
-        if (!selectedIssue) {
-          // Level 1: Navigate issues
-          // Include "Other Conversations" in navigation calculation
-          const unattachedCount = conversations.filter(
-            (c) => !c.linearProjectId && !c.linearTaskId,
-          ).length;
-
-          const filteredSessions = searchQuery
-            ? sessions.filter((s) => {
-                const query = searchQuery.toLowerCase();
-                const identifier =
-                  s.linearData?.identifier?.toLowerCase() || "";
-                const title = s.name.toLowerCase();
-                return identifier.includes(query) || title.includes(query);
-              })
-            : sessions;
-
-          // Add 1 for "Other Conversations" if there are unattached conversations
-          const totalItems =
-            unattachedCount > 0
-              ? filteredSessions.length + 1
-              : filteredSessions.length;
-          const maxIndex = Math.min(totalItems - 1, 9);
-          const minIndex = searchQuery ? 0 : -1; // Allow -1 for "Create New"
-
-          return selectedIndex < maxIndex ? selectedIndex + 1 : minIndex;
-        } else {
-          // Level 2: Navigate conversations
-          // Check if this is the "Other Conversations" group (no linearData)
-          const isUnattachedGroup = !selectedIssue.linearData;
-
-          const conversationsForIssue = isUnattachedGroup
-            ? conversations.filter((c) => !c.linearProjectId && !c.linearTaskId)
-            : conversations.filter((c) => {
-                const issueLinearId = selectedIssue.linearData?.id;
-                return (
-                  c.linearProjectId === issueLinearId ||
-                  c.linearTaskId === issueLinearId
-                );
-              });
-
-          const filteredConversations = searchQuery
-            ? conversationsForIssue.filter((c) => {
-                const query = searchQuery.toLowerCase();
-                const display = c.display.toLowerCase();
-                const slug = c.slug?.toLowerCase() || "";
-                return display.includes(query) || slug.includes(query);
-              })
-            : conversationsForIssue;
-
-          const maxIndex = Math.min(filteredConversations.length - 1, 9);
-          // Don't allow "Create New" for unattached group
-          const minIndex = searchQuery || isUnattachedGroup ? 0 : -1;
-
-          return selectedIndex < maxIndex ? selectedIndex + 1 : minIndex;
-        }
+        const { selectedIndex } = context;
+        const { minIndex, maxIndex } = selectionBounds(context);
+        return selectedIndex < maxIndex ? selectedIndex + 1 : minIndex;
+      },
+    }),
+    pageUp: assign({
+      selectedIndex: ({ context }) => {
+        const { minIndex } = selectionBounds(context);
+        return Math.max(context.selectedIndex - SELECTION_PAGE_SIZE, minIndex);
+      },
+    }),
+    pageDown: assign({
+      selectedIndex: ({ context }) => {
+        const { maxIndex } = selectionBounds(context);
+        return Math.min(
+          Math.max(context.selectedIndex, 0) + SELECTION_PAGE_SIZE,
+          maxIndex,
+        );
       },
     }),
     updateData: assign({
@@ -247,6 +165,12 @@ const selectionMachine = setup({
         NAVIGATE_DOWN: {
           actions: "navigateDown",
         },
+        PAGE_UP: {
+          actions: "pageUp",
+        },
+        PAGE_DOWN: {
+          actions: "pageDown",
+        },
         UPDATE_DATA: {
           actions: "updateData",
         },
@@ -277,6 +201,12 @@ const selectionMachine = setup({
         NAVIGATE_DOWN: {
           actions: "navigateDown",
         },
+        PAGE_UP: {
+          actions: "pageUp",
+        },
+        PAGE_DOWN: {
+          actions: "pageDown",
+        },
         UPDATE_DATA: {
           actions: "updateData",
         },
@@ -329,5 +259,7 @@ export function useSelectionState(
     clearSearch: () => send({ type: "CLEAR_SEARCH" }),
     navigateUp: () => send({ type: "NAVIGATE_UP" }),
     navigateDown: () => send({ type: "NAVIGATE_DOWN" }),
+    pageUp: () => send({ type: "PAGE_UP" }),
+    pageDown: () => send({ type: "PAGE_DOWN" }),
   };
 }
@@ -0,0 +1,61 @@
+/**
+ * Entry point for `plan.sh --from-file`
+ * Imports a hand-written Markdown checklist as an epic's tasks, skipping
+ * the LLM planning session.
+ *
+ * Usage: bun src/plan-from-file.ts <plan.md> [--parent <epic-id>]
+ */
+
+import * as fs from "node:fs";
+import * as path from "node:path";
+import { Effect } from "effect";
+import { importPlan } from "./services/PlanImportService";
+import { loadConfig } from "./utils/config-loader";
+import { countPlanItems, parsePlanChecklist } from "./utils/plan-checklist";
+
+const args = process.argv.slice(2);
+const parentIndex = args.indexOf("--parent");
+const parentId = parentIndex !== -1 ? args[parentIndex + 1] : undefined;
+const file = args.find(
+  (arg, i) => !arg.startsWith("--") && (parentIndex === -1 || i !== parentIndex + 1),
+);
+
+if (!file || (parentIndex !== -1 && !parentId)) {
+  console.error("Usage: plan-from-file <plan.md> [--parent <epic-id>]");
+  process.exit(1);
+}
+if (!fs.existsSync(file)) {
+  console.error(`Error: plan file not found: ${file}`);
+  process.exit(1);
+}
+
+const plan = parsePlanChecklist(fs.readFileSync(file, "utf-8"));
+if (countPlanItems(plan.tasks) === 0) {
+  console.error(`Error: no checklist items ("- [ ] task") in ${file}`);
+  process.exit(1);
+}
+
+// Beads is the tracker when the workspace has no config
+const config = loadConfig() ?? {};
+
+const outcome = await Effect.runPromise(
+  Effect.either(
+    importPlan(config, plan, {
+      parentId,
+      fallbackTitle: path.basename(file, path.extname(file)),
+    }),
+  ),
+);
+if (outcome._tag === "Left") {
+  console.error(`Error: ${outcome.left.message}`);
+  process.exit(1);
+}
+
+const result = outcome.right;
+console.log(
+  `✓ ${result.epicCreated ? "Created epic" : "Using epic"} ${result.epicId}`,
+);
+console.log(`✓ Created ${result.created} tasks from ${file}`);
+if (result.skipped > 0) {
+  console.log(`  Skipped ${result.skipped} items already checked off`);
+}
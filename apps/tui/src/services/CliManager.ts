@@ -10,6 +10,13 @@
  */
 
 import { EventEmitter } from "node:events";
+import {
+  type AgentContract,
+  DEFAULT_AGENT_CONTRACT,
+  detectCompletion,
+  longestMarkerLength,
+  resolveAgentContract,
+} from "@clive/agent-contract";
 import {
   type ClaudeCliEvent,
   ClaudeCliService,
@@ -66,6 +73,9 @@ export class CliManager extends EventEmitter {
   // Accumulation buffer for completion marker detection across streaming chunks
   private accumulatedText = "";
 
+  // Completion markers, resolved from the environment on each execution
+  private contract: AgentContract = DEFAULT_AGENT_CONTRACT;
+
   // Set when stopForIteration() kills the process; suppresses the expected SIGTERM error
   private stoppingForIteration = false;
 
@@ -107,6 +117,8 @@ export class CliManager extends EventEmitter {
       historyLength: this.conversationHistory.length,
     });
 
+    this.contract = resolveAgentContract();
+
     // Start conversation logging if mode is set
     if (options.mode) {
       this.conversationLogger.start(options.workspaceRoot, options.mode);
@@ -447,36 +459,20 @@ export class CliManager extends EventEmitter {
         // Detect completion markers in streaming text
         this.accumulatedText += event.content;
 
-        if (
-          this.accumulatedText.includes(
-            "<promise>ALL_TASKS_COMPLETE</promise>",
-          )
-        ) {
-          debugLog("CliManager", "ALL_TASKS_COMPLETE marker detected — stopping process");
+        const signal = detectCompletion(this.accumulatedText, this.contract);
+        if (signal) {
+          debugLog("CliManager", `${signal} marker detected — stopping process`);
           this.accumulatedText = "";
-          this.emit("all-tasks-complete");
+          this.emit(signal);
           // Kill the process so the stream ends and "complete" fires.
           // Use stopForIteration to avoid emitting "killed" (which resets loop state).
           this.stopForIteration();
-        } else if (
-          this.accumulatedText.includes("<promise>TASK_COMPLETE</promise>")
-        ) {
-          debugLog("CliManager", "TASK_COMPLETE marker detected — stopping process");
-          this.accumulatedText = "";
-          this.emit("task-complete");
-          this.stopForIteration();
-        } else if (
-          this.accumulatedText.includes("<promise>REVIEW_COMPLETE</promise>")
-        ) {
-          debugLog("CliManager", "REVIEW_COMPLETE marker detected — stopping process");
-          this.accumulatedText = "";
-          this.emit("review-complete");
-          this.stopForIteration();
         }
 
         // Keep buffer bounded to handle markers spanning chunks
-        if (this.accumulatedText.length > 200) {
-          this.accumulatedText = this.accumulatedText.slice(-100);
+        const keep = longestMarkerLength(this.contract);
+        if (this.accumulatedText.length > keep * 2) {
+          this.accumulatedText = this.accumulatedText.slice(-keep);
         }
 
         // Track assistant response in conversation history
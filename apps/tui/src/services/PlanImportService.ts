@@ -0,0 +1,143 @@
+/**
+ * PlanImportService - Create an epic's tasks from a written plan
+ * Turns a parsed Markdown checklist into tracker issues through BeadsService
+ * or LinearService, under a new epic or an existing one.
+ */
+
+import {
+  BeadsService,
+  BeadsServiceLive,
+  LinearService,
+  makeLinearServiceLive,
+} from "@clive/claude-services";
+import { Effect } from "effect";
+import type { Config } from "../types";
+import type { PlanChecklist, PlanItem } from "../utils/plan-checklist";
+import { TaskServiceConfigError } from "./TaskService";
+
+export interface PlanImportOptions {
+  /** Existing epic to add the tasks to; a new epic is created without one */
+  parentId?: string;
+  /** Epic title when the plan has no heading */
+  fallbackTitle: string;
+}
+
+export interface PlanImportResult {
+  epicId: string;
+  epicCreated: boolean;
+  /** Tasks and subtasks created */
+  created: number;
+  /** Items already checked off; their subtasks are left out with them */
+  skipped: number;
+}
+
+/**
+ * Message of an Error or of a tracker's tagged error class
+ */
+function describeError(error: unknown): string {
+  const message = (error as { message?: unknown } | null)?.message;
+  return typeof message === "string" ? message : String(error);
+}
+
+/**
+ * Create the plan's open items as tasks, nested items as their subtasks
+ */
+export function importPlan(
+  config: Config,
+  plan: PlanChecklist,
+  options: PlanImportOptions,
+): Effect.Effect<PlanImportResult, TaskServiceConfigError> {
+  const title = plan.title || options.fallbackTitle;
+  const result: PlanImportResult = {
+    epicId: "",
+    epicCreated: false,
+    created: 0,
+    skipped: 0,
+  };
+
+  // Walk the tree depth-first so each item's parent exists before it
+  const createItems = (
+    items: PlanItem[],
+    parentId: string,
+    create: (item: PlanItem, parentId: string) => Effect.Effect<string, unknown>,
+  ): Effect.Effect<void, unknown> =>
+    Effect.gen(function* () {
+      for (const item of items) {
+        if (item.done) {
+          result.skipped++;
+          continue;
+        }
+        const id = yield* create(item, parentId);
+        result.created++;
+        yield* createItems(item.subtasks, id, create);
+      }
+    });
+
+  const program =
+    config.issueTracker === "linear" && config.linear
+      ? Effect.gen(function* () {
+          const linearService = yield* LinearService;
+          const linear = config.linear!;
+
+          // Tasks go to the team that owns the epic
+          const epic = options.parentId
+            ? yield* linearService.getIssue(options.parentId)
+            : yield* linearService.createIssue({
+                teamId: linear.teamID,
+                title,
+                description: plan.description || undefined,
+              });
+          result.epicId = epic.identifier;
+          result.epicCreated = !options.parentId;
+
+          yield* createItems(plan.tasks, epic.id, (item, parentId) =>
+            linearService
+              .createIssue({
+                teamId: epic.team.id,
+                title: item.title,
+                description: item.description || undefined,
+                parentId,
+              })
+              .pipe(Effect.map((issue) => issue.id)),
+          );
+          return result;
+        }).pipe(Effect.provide(makeLinearServiceLive(config.linear)))
+      : Effect.gen(function* () {
+          const beadsService = yield* BeadsService;
+
+          const epicId = options.parentId
+            ? options.parentId
+            : (yield* beadsService.create({
+                title,
+                type: "epic",
+                description: plan.description || undefined,
+              })).id;
+          result.epicId = epicId;
+          result.epicCreated = !options.parentId;
+
+          yield* createItems(plan.tasks, epicId, (item, parent) =>
+            beadsService
+              .create({
+                title: item.title,
+                type: "task",
+                priority: 2,
+                description: item.description || undefined,
+                parent,
+              })
+              .pipe(Effect.map((issue) => issue.id)),
+          );
+          return result;
+        }).pipe(Effect.provide(BeadsServiceLive));
+
+  return (program as Effect.Effect<PlanImportResult, unknown>).pipe(
+    Effect.catchAll((error) =>
+      Effect.fail(
+        new TaskServiceConfigError({
+          message: `Failed to import plan after creating ${result.created} tasks${
+            result.epicId ? ` under ${result.epicId}` : ""
+          }: ${describeError(error)}`,
+        }),
+      ),
+    ),
+  );
+}
@@ -12,6 +12,7 @@ import {
 } from "@clive/claude-services";
 import { Data, Effect } from "effect";
 import type { Config, Session, Task } from "../types";
+import { linearTeamIDs } from "../utils/config-loader";
 
 // Error types
 export class TaskServiceConfigError extends Data.TaggedError(
@@ -39,12 +40,12 @@ export function createTaskService(config: Config) {
     loadSessions: provide(
       Effect.gen(function* () {
         if (config.issueTracker === "linear" && config.linear) {
-          // Fetch all top-level issues in the team
+          // Fetch all top-level issues in the configured teams
           const linearService = yield* LinearService;
 
-          // Fetch all issues in the team (top-level only - no parent)
+          // Fetch all issues in the teams (top-level only - no parent)
           const allIssues = yield* linearService.listIssues({
-            teamId: config.linear.teamID,
+            teamIds: linearTeamIDs(config.linear),
             filter: { parent: { null: true } },
             limit: 100,
           });
@@ -74,6 +75,13 @@ export function createTaskService(config: Config) {
               description: issue.description,
               createdAt: issue.createdAt,
               source: "linear",
+              blockedBy: issue.blockedBy?.map((blocker) => ({
+                id: blocker.id,
+                name: `${blocker.identifier} ${blocker.title}`,
+                complete:
+                  blocker.state.type === "completed" ||
+                  blocker.state.type === "canceled",
+              })),
               linearData: issue,
             }),
           );
@@ -89,6 +97,19 @@ export function createTaskService(config: Config) {
           const beadsService = yield* BeadsService;
           const epics = yield* beadsService.list({ type: "epic" });
 
+          // Resolve blocking epics, looking up any the list left out
+          // (e.g. closed ones). An unknown blocker counts as incomplete.
+          const known = new Map(epics.map((epic) => [epic.id, epic]));
+          const missing = [
+            ...new Set(epics.flatMap((epic) => epic.blockedBy ?? [])),
+          ].filter((id) => !known.has(id));
+          const fetched = yield* Effect.forEach(missing, (id) =>
+            beadsService.show(id).pipe(Effect.option),
+          );
+          for (const issue of fetched) {
+            if (issue._tag === "Some") known.set(issue.value.id, issue.value);
+          }
+
           return epics.map(
             (epic): Session => ({
               id: epic.id,
@@ -96,6 +117,14 @@ export function createTaskService(config: Config) {
               description: epic.description,
               createdAt: epic.createdAt,
               source: "beads",
+              blockedBy: epic.blockedBy?.map((id) => {
+                const blocker = known.get(id);
+                return {
+                  id,
+                  name: blocker ? `${id} ${blocker.title}` : id,
+                  complete: blocker?.status === "closed",
+                };
+              }),
               beadsData: epic,
             }),
           );
@@ -181,7 +210,7 @@ export function createTaskService(config: Config) {
           // Load Linear issues with 'started' state
           const linearService = yield* LinearService;
           const issues = yield* linearService.listIssues({
-            teamId: config.linear.teamID,
+            teamIds: linearTeamIDs(config.linear),
             stateType: "started",
           });
 
@@ -214,9 +243,11 @@ export function createTaskService(config: Config) {
             // Update Linear issue state
             const linearService = yield* LinearService;
 
-            // Map status to Linear state
+            // Map status to a state of the task's own team, which may not
+            // be the primary one
+            const task = yield* linearService.getIssue(taskId);
             const states = yield* linearService.listWorkflowStates(
-              config.linear.teamID,
+              task.team.id,
             );
             const targetState = states.find((s) => {
               if (status === "in_progress") return s.type === "started";
@@ -255,10 +286,11 @@ export function createTaskService(config: Config) {
       provide(
         Effect.gen(function* () {
           if (config.issueTracker === "linear" && config.linear) {
-            // Create Linear issue
+            // Create Linear issue in the team that owns the epic
             const linearService = yield* LinearService;
+            const epic = yield* linearService.getIssue(sessionId);
             const issue = yield* linearService.createIssue({
-              teamId: config.linear.teamID,
+              teamId: epic.team.id,
               title,
               projectId: sessionId,
             });
@@ -130,8 +130,10 @@ export class TmuxSessionManager extends EventEmitter {
   createWindow(opts: CreateWindowOptions): TmuxWindow {
     const { id, name, cwd, command } = opts;
 
-    // Create the window with the command
-    const escapedCmd = command.replace(/"/g, '\\"');
+    // Create the window with the command. Everything the shell expands in
+    // double quotes is escaped, so $VARS in prompts and epic setup commands
+    // reach the window's shell as written
+    const escapedCmd = command.replace(/["\\$`]/g, "\\$&");
     const escapedName = name.replace(/[^a-zA-Z0-9._-]/g, "-").slice(0, 30);
 
     try {
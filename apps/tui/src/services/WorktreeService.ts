@@ -415,6 +415,7 @@ export class WorktreeService {
       ".claude/skills",
       ".clive/knowledge",
       ".clive/rules",
+      ".clive/epics",
     ];
 
     for (const file of filesToCopy) {
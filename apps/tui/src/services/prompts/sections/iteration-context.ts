@@ -1,7 +1,9 @@
 import * as fs from "node:fs";
 import * as path from "node:path";
+import { type AgentContract, resolveAgentContract } from "@clive/agent-contract";
 import { Effect } from "effect";
-import type { BuildConfig, PromptBuildError } from "../types";
+import type { BuildConfig } from "../types";
+import { PromptBuildError } from "../types";
 
 /**
  * Iteration context section
@@ -44,7 +46,14 @@ export const iterationContext = (
     }
 
     // Completion marker instructions
-    sections.push(buildCompletionInstructions(epicId));
+    const contract = yield* Effect.try({
+      try: () => resolveAgentContract(),
+      catch: (error) =>
+        new PromptBuildError({
+          message: error instanceof Error ? error.message : String(error),
+        }),
+    });
+    sections.push(buildCompletionInstructions(contract, epicId));
 
     return `\n${sections.join("\n\n")}\n`;
   });
@@ -114,7 +123,10 @@ function readLearnings(workspaceRoot?: string): string | null {
 /**
  * Build completion marker instructions for the agent.
  */
-function buildCompletionInstructions(epicId?: string): string {
+function buildCompletionInstructions(
+  contract: AgentContract,
+  epicId?: string,
+): string {
   const scratchpadPath = epicId
     ? `.claude/epics/${epicId}/scratchpad.md`
     : ".claude/scratchpad.md";
@@ -137,8 +149,8 @@ ${linearUpdateStep}
    - Any issues encountered
    - Context for the next iteration
 5. Emit EXACTLY ONE of these markers as the LAST thing you output:
-   - If more tasks remain: <promise>TASK_COMPLETE</promise>
-   - If ALL tasks are done: <promise>ALL_TASKS_COMPLETE</promise>
+   - If more tasks remain: ${contract.markers.taskComplete}
+   - If ALL tasks are done: ${contract.markers.allTasksComplete}
 6. STOP IMMEDIATELY after emitting the marker. Do not output anything else.
 
 IMPORTANT: The TUI controls the iteration loop. Do NOT try to do multiple tasks in one invocation.
@@ -13,6 +13,15 @@ export type { BeadsIssue, LinearIssue } from "@clive/claude-services";
  */
 export type Task = BeadsIssue | LinearIssue;
 
+/**
+ * An epic that has to be finished before another one can start
+ */
+export interface EpicBlocker {
+  id: string;
+  name: string;
+  complete: boolean;
+}
+
 /**
  * Session represents a working context (Linear epic or Beads epic)
  */
@@ -22,6 +31,8 @@ export interface Session {
   description?: string;
   createdAt: Date;
   source: "beads" | "linear";
+  // Epics blocking this one, from the tracker's dependency relations
+  blockedBy?: EpicBlocker[];
   // Source-specific data
   beadsData?: BeadsIssue;
   linearData?: LinearIssue;
@@ -107,7 +118,10 @@ export interface QuestionOption {
 
 export interface LinearConfig {
   apiKey: string;
+  // Team new epics are created in
   teamID: string;
+  // Further teams whose epics are listed alongside the primary team's
+  teamIDs?: string[];
 }
 
 export interface WorkerConfig {
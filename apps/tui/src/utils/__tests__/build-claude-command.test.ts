@@ -0,0 +1,59 @@
+/**
+ * Build Claude Command Tests
+ *
+ * Tests the claude CLI command string:
+ * - Environment assignments ahead of the command
+ * - Setup and teardown commands around it
+ * - Shell escaping of the prompt
+ */
+
+import { describe, expect, it } from "vitest";
+import { buildClaudeCommand } from "../build-claude-command";
+
+describe("buildClaudeCommand", () => {
+  it("sets env vars ahead of the claude command", () => {
+    const cmd = buildClaudeCommand({
+      workspaceRoot: "/tmp/project",
+      env: { CLIVE_PARENT_ID: "abc-123", CLIVE_EPIC_IDENTIFIER: "ENG-7" },
+    });
+
+    expect(
+      cmd.startsWith(
+        "CLIVE_PARENT_ID='abc-123' CLIVE_EPIC_IDENTIFIER='ENG-7' claude ",
+      ),
+    ).toBe(true);
+  });
+
+  it("starts with claude when no env is given", () => {
+    const cmd = buildClaudeCommand({ workspaceRoot: "/tmp/project" });
+
+    expect(cmd.startsWith("claude --model opus ")).toBe(true);
+  });
+
+  it("escapes single quotes in the prompt", () => {
+    const cmd = buildClaudeCommand({
+      workspaceRoot: "/tmp/project",
+      prompt: "Plan new tasks for ENG-7: Don't break it",
+    });
+
+    expect(cmd.endsWith("'Plan new tasks for ENG-7: Don'\\''t break it'")).toBe(
+      true,
+    );
+  });
+
+  it("runs setup before claude and teardown after it", () => {
+    const cmd = buildClaudeCommand({
+      workspaceRoot: "/tmp/project",
+      env: { DATABASE_URL: "postgres://localhost/checkout" },
+      setup: ["docker compose up -d db", "make migrate"],
+      teardown: ["docker compose down"],
+    });
+
+    expect(
+      cmd.startsWith(
+        "export DATABASE_URL='postgres://localhost/checkout'; { docker compose up -d db; } && { make migrate; } && claude ",
+      ),
+    ).toBe(true);
+    expect(cmd.endsWith("; docker compose down")).toBe(true);
+  });
+});
@@ -5,6 +5,7 @@
  * - Environment file loading and saving
  * - Config file loading with priority (workspace > global)
  * - Linear config normalization (snake_case, camelCase)
+ * - Multiple Linear teams
  * - API key priority (env var > .env file > config file)
  * - Config merging and sensitive value extraction
  */
@@ -40,6 +41,7 @@ describe("config-loader", () => {
   let getConfigDir: typeof import("../config-loader").getConfigDir;
   let getConfigPath: typeof import("../config-loader").getConfigPath;
   let validatePathTraversal: typeof import("../config-loader").validatePathTraversal;
+  let linearTeamIDs: typeof import("../config-loader").linearTeamIDs;
 
   beforeEach(async () => {
     // Reset mocks
@@ -61,6 +63,7 @@ describe("config-loader", () => {
     getConfigDir = module.getConfigDir;
     getConfigPath = module.getConfigPath;
     validatePathTraversal = module.validatePathTraversal;
+    linearTeamIDs = module.linearTeamIDs;
   });
 
   afterEach(() => {
@@ -385,6 +388,45 @@ REAL_KEY=actual_value`;
       expect(result?.worker?.autoConnect).toBe(true);
     });
 
+    it("should read further Linear teams alongside the primary one", async () => {
+      const config = {
+        issueTracker: "linear",
+        linear: {
+          apiKey: "key",
+          teamID: "team_a",
+          teamIDs: ["team_b", "team_a", "team_c"],
+        },
+      };
+
+      vi.mocked(fs.existsSync).mockReturnValue(true);
+      vi.mocked(fs.readFileSync).mockReturnValue(JSON.stringify(config));
+
+      const result = loadConfig("/workspace");
+
+      expect(result?.linear?.teamID).toBe("team_a");
+      expect(result?.linear?.teamIDs).toEqual(["team_b", "team_c"]);
+      expect(linearTeamIDs(result!.linear!)).toEqual([
+        "team_a",
+        "team_b",
+        "team_c",
+      ]);
+    });
+
+    it("should take the first of team_ids as the primary team", async () => {
+      const config = {
+        issue_tracker: "linear",
+        linear: { api_key: "key", team_ids: ["team_a", "team_b"] },
+      };
+
+      vi.mocked(fs.existsSync).mockReturnValue(true);
+      vi.mocked(fs.readFileSync).mockReturnValue(JSON.stringify(config));
+
+      const result = loadConfig("/workspace");
+
+      expect(result?.linear?.teamID).toBe("team_a");
+      expect(result?.linear?.teamIDs).toEqual(["team_b"]);
+    });
+
     it("should isolate config between different workspaces", async () => {
       // Test that loading from workspace A doesn't affect workspace B
       const workspaceAConfig = {
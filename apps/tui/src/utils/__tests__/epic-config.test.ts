@@ -0,0 +1,104 @@
+/**
+ * Epic Config Tests
+ *
+ * Tests the per-epic config file:
+ * - Parsing env, setup and teardown sections
+ * - Errors naming the offending line
+ * - Creating the file from the template
+ */
+
+import * as fs from "node:fs";
+import * as os from "node:os";
+import * as path from "node:path";
+import { afterEach, beforeEach, describe, expect, it } from "vitest";
+import {
+  ensureEpicConfig,
+  epicConfigPath,
+  loadEpicConfig,
+  parseEpicConfig,
+} from "../epic-config";
+
+describe("parseEpicConfig", () => {
+  it("reads env vars and commands", () => {
+    const config = parseEpicConfig(`# Checkout epic
+env:
+  FEATURE_CHECKOUT: "true"
+  DATABASE_URL: postgres://localhost/checkout  # local db
+  EMPTY:
+setup:
+  - docker compose up -d db
+  - 'make migrate'
+teardown:
+  - docker compose down
+`);
+
+    expect(config).toEqual({
+      env: {
+        FEATURE_CHECKOUT: "true",
+        DATABASE_URL: "postgres://localhost/checkout",
+        EMPTY: "",
+      },
+      setup: ["docker compose up -d db", "make migrate"],
+      teardown: ["docker compose down"],
+    });
+  });
+
+  it("reads the template as an empty config", () => {
+    const dir = fs.mkdtempSync(path.join(os.tmpdir(), "epic-config-"));
+    try {
+      const configPath = ensureEpicConfig(dir, "ENG-7");
+      expect(configPath).toBe(epicConfigPath(dir, "ENG-7"));
+      expect(parseEpicConfig(fs.readFileSync(configPath, "utf-8"))).toEqual({
+        env: {},
+        setup: [],
+        teardown: [],
+      });
+    } finally {
+      fs.rmSync(dir, { recursive: true, force: true });
+    }
+  });
+
+  it("names the line it can't read", () => {
+    expect(() => parseEpicConfig("env:\n  not a var\n")).toThrow(
+      "line 2: expected NAME: value",
+    );
+    expect(() => parseEpicConfig("setup:\n  docker compose up\n")).toThrow(
+      "line 2: expected - command",
+    );
+    expect(() => parseEpicConfig("services:\n")).toThrow(
+      "line 1: expected env:, setup: or teardown:",
+    );
+  });
+});
+
+describe("loadEpicConfig", () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = fs.mkdtempSync(path.join(os.tmpdir(), "epic-config-"));
+  });
+
+  afterEach(() => {
+    fs.rmSync(dir, { recursive: true, force: true });
+  });
+
+  it("returns null when the epic has no config", () => {
+    expect(loadEpicConfig(dir, "ENG-7")).toBeNull();
+  });
+
+  it("keeps the epic ID to one path segment", () => {
+    expect(epicConfigPath(dir, "../ENG/7")).toBe(
+      path.join(dir, ".clive", "epics", "..-ENG-7.yaml"),
+    );
+  });
+
+  it("leaves an existing config alone", () => {
+    const configPath = epicConfigPath(dir, "ENG-7");
+    fs.mkdirSync(path.dirname(configPath), { recursive: true });
+    fs.writeFileSync(configPath, "env:\n  FLAG: on\n");
+
+    ensureEpicConfig(dir, "ENG-7");
+
+    expect(loadEpicConfig(dir, "ENG-7")?.env).toEqual({ FLAG: "on" });
+  });
+});
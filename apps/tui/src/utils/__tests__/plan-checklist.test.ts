@@ -0,0 +1,85 @@
+/**
+ * Plan Checklist Tests
+ *
+ * Tests parsing a written plan into an epic's tasks:
+ * - Epic title and description from the heading
+ * - Nested items as subtasks
+ * - Indented text, bullets and code as descriptions
+ * - Checked items marked done
+ */
+
+import { describe, expect, it } from "vitest";
+import { countPlanItems, parsePlanChecklist } from "../plan-checklist";
+
+const PLAN = `# Checkout revamp
+
+Move checkout to the new payments API.
+
+## Tasks
+
+- [ ] User can see their cart
+  Shows every item with its price.
+  - [ ] GET /cart returns the cart
+    - Empty carts return []
+  - [x] Cart table exists
+- [ ] User can pay by card
+  \`\`\`bash
+  curl -X POST /pay
+  \`\`\`
+1. [ ] Receipts are emailed
+
+Notes after the list are not part of any task.
+`;
+
+describe("parsePlanChecklist", () => {
+  it("takes the epic from the first heading", () => {
+    const plan = parsePlanChecklist(PLAN);
+
+    expect(plan.title).toBe("Checkout revamp");
+    expect(plan.description).toBe(
+      "Move checkout to the new payments API.",
+    );
+  });
+
+  it("nests indented items as subtasks", () => {
+    const plan = parsePlanChecklist(PLAN);
+
+    expect(plan.tasks.map((task) => task.title)).toEqual([
+      "User can see their cart",
+      "User can pay by card",
+      "Receipts are emailed",
+    ]);
+    expect(plan.tasks[0]!.subtasks.map((task) => task.title)).toEqual([
+      "GET /cart returns the cart",
+      "Cart table exists",
+    ]);
+    expect(countPlanItems(plan.tasks)).toBe(5);
+  });
+
+  it("keeps indented text under an item as its description", () => {
+    const plan = parsePlanChecklist(PLAN);
+
+    expect(plan.tasks[0]!.description).toBe("Shows every item with its price.");
+    expect(plan.tasks[0]!.subtasks[0]!.description).toBe(
+      "- Empty carts return []",
+    );
+    expect(plan.tasks[1]!.description).toBe(
+      "```bash\ncurl -X POST /pay\n```",
+    );
+    expect(plan.tasks[2]!.description).toBe("");
+  });
+
+  it("marks checked items done", () => {
+    const plan = parsePlanChecklist(PLAN);
+
+    expect(plan.tasks[0]!.subtasks[1]!.done).toBe(true);
+    expect(countPlanItems(plan.tasks, (item) => !item.done)).toBe(4);
+  });
+
+  it("has no tasks without checkboxes", () => {
+    const plan = parsePlanChecklist("# Ideas\n\n- a plain bullet\n");
+
+    expect(plan.title).toBe("Ideas");
+    expect(plan.tasks).toEqual([]);
+  });
+});
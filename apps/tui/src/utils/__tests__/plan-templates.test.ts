@@ -0,0 +1,78 @@
+/**
+ * Plan Templates Tests
+ *
+ * Tests loading planning templates:
+ * - Built-in templates with their frontmatter
+ * - Project templates from .clive/templates added and overriding built-ins
+ */
+
+import * as fs from "node:fs";
+import * as os from "node:os";
+import * as path from "node:path";
+import { afterEach, beforeEach, describe, expect, it } from "vitest";
+import { loadPlanTemplates } from "../plan-templates";
+
+describe("loadPlanTemplates", () => {
+  let workspace: string;
+  let builtinDir: string;
+
+  const write = (file: string, text: string) => {
+    fs.mkdirSync(path.dirname(file), { recursive: true });
+    fs.writeFileSync(file, text);
+  };
+
+  beforeEach(() => {
+    workspace = fs.mkdtempSync(path.join(os.tmpdir(), "plan-templates-"));
+    builtinDir = path.join(workspace, "builtin");
+    write(
+      path.join(builtinDir, "bugfix.md"),
+      "---\nname: Bugfix\ndescription: Fix a defect\n---\nReproduce first.\n",
+    );
+    write(
+      path.join(builtinDir, "infra.md"),
+      "---\nname: Infra\n---\nRoll back each step.\n",
+    );
+  });
+
+  afterEach(() => {
+    fs.rmSync(workspace, { recursive: true, force: true });
+  });
+
+  it("reads the built-in templates", () => {
+    expect(loadPlanTemplates(workspace, builtinDir)).toEqual([
+      {
+        id: "bugfix",
+        name: "Bugfix",
+        description: "Fix a defect",
+        content: "Reproduce first.",
+      },
+      { id: "infra", name: "Infra", description: "", content: "Roll back each step." },
+    ]);
+  });
+
+  it("adds project templates and lets them replace built-ins", () => {
+    const projectDir = path.join(workspace, ".clive", "templates");
+    write(
+      path.join(projectDir, "bugfix.md"),
+      "---\nname: Bugfix (ours)\n---\nLink the incident.\n",
+    );
+    write(path.join(projectDir, "data-migration.md"), "Backfill in batches.\n");
+
+    const templates = loadPlanTemplates(workspace, builtinDir);
+
+    expect(templates.map((t) => t.id)).toEqual([
+      "bugfix",
+      "infra",
+      "data-migration",
+    ]);
+    expect(templates[0]!.name).toBe("Bugfix (ours)");
+    expect(templates[0]!.content).toBe("Link the incident.");
+    expect(templates[2]!.name).toBe("data-migration");
+  });
+
+  it("ships the four built-in templates", () => {
+    const ids = loadPlanTemplates(workspace).map((t) => t.id);
+
+    expect(ids).toEqual(["bugfix", "infra", "library-change", "web-app-feature"]);
+  });
+});
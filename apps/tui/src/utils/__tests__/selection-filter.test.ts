@@ -0,0 +1,133 @@
+/**
+ * Selection Filter Tests
+ *
+ * Tests the selection screen list helpers:
+ * - Fuzzy matching and ranking
+ * - Sorting issues by recent activity
+ * - Paging around the selected row
+ * - Finding unfinished blocking epics
+ */
+
+import { describe, expect, it } from "vitest";
+import type { Conversation } from "../../services/ConversationService";
+import type { Session } from "../../types";
+import {
+  buildIssueList,
+  filterIssues,
+  fuzzyScore,
+  incompleteBlockers,
+  pageFor,
+  UNATTACHED_GROUP_ID,
+} from "../selection-filter";
+
+function issue(id: string, name: string, updatedAt: string): Session {
+  return {
+    id,
+    name,
+    createdAt: new Date("2026-01-01T00:00:00Z"),
+    source: "linear",
+    linearData: {
+      id: `lin-${id}`,
+      identifier: id.toUpperCase(),
+      title: name,
+      updatedAt: new Date(updatedAt),
+    } as Session["linearData"],
+  };
+}
+
+function conversation(
+  sessionId: string,
+  timestamp: number,
+  linearProjectId?: string,
+): Conversation {
+  return {
+    sessionId,
+    project: "/src/clive",
+    display: `conversation ${sessionId}`,
+    timestamp,
+    linearProjectId,
+  };
+}
+
+describe("selection-filter", () => {
+  describe("fuzzyScore", () => {
+    it("matches characters in order", () => {
+      expect(fuzzyScore("ath", "Auth middleware")).not.toBeNull();
+      expect(fuzzyScore("amw", "Auth middleware")).not.toBeNull();
+      expect(fuzzyScore("hua", "Auth")).toBeNull();
+    });
+
+    it("ranks substrings and word starts above scattered matches", () => {
+      const substring = fuzzyScore("mid", "Auth middleware");
+      const scattered = fuzzyScore("mid", "Make it dark");
+      expect(substring).not.toBeNull();
+      expect(scattered).not.toBeNull();
+      expect(substring as number).toBeGreaterThan(scattered as number);
+    });
+  });
+
+  describe("buildIssueList", () => {
+    it("puts other conversations first, then issues by recent activity", () => {
+      const sessions = [
+        issue("cl-1", "Old epic", "2026-02-01T00:00:00Z"),
+        issue("cl-2", "Updated epic", "2026-03-01T00:00:00Z"),
+        issue("cl-3", "Active epic", "2026-01-15T00:00:00Z"),
+      ];
+      const conversations = [
+        conversation("a", Date.parse("2026-04-01T00:00:00Z"), "lin-cl-3"),
+        conversation("b", Date.parse("2026-01-02T00:00:00Z")),
+      ];
+
+      const ids = buildIssueList(sessions, conversations).map((s) => s.id);
+      expect(ids).toEqual([UNATTACHED_GROUP_ID, "cl-3", "cl-2", "cl-1"]);
+    });
+  });
+
+  describe("filterIssues", () => {
+    it("fuzzy-filters by identifier and title, best match first", () => {
+      const issues = [
+        issue("cl-1", "Rework auth middleware", "2026-03-01T00:00:00Z"),
+        issue("cl-2", "Authentication", "2026-02-01T00:00:00Z"),
+        issue("cl-3", "Billing", "2026-01-01T00:00:00Z"),
+      ];
+
+      expect(filterIssues(issues, "auth").map((s) => s.id)).toEqual([
+        "cl-2",
+        "cl-1",
+      ]);
+      expect(filterIssues(issues, "CL3").map((s) => s.id)).toEqual(["cl-3"]);
+      expect(filterIssues(issues, "")).toBe(issues);
+    });
+  });
+
+  describe("pageFor", () => {
+    it("returns the page holding the selected row", () => {
+      expect(pageFor(-1, 25)).toEqual({
+        page: 0,
+        pageCount: 3,
+        start: 0,
+        end: 10,
+      });
+      expect(pageFor(13, 25)).toMatchObject({ page: 1, start: 10, end: 20 });
+      expect(pageFor(24, 25)).toMatchObject({ page: 2, start: 20, end: 25 });
+      expect(pageFor(0, 0)).toMatchObject({ page: 0, pageCount: 1, end: 0 });
+    });
+  });
+
+  describe("incompleteBlockers", () => {
+    it("returns only the blocking epics that are not complete", () => {
+      const blocked: Session = {
+        ...issue("cl-4", "Billing v2", "2026-03-01T00:00:00Z"),
+        blockedBy: [
+          { id: "cl-1", name: "CL-1 Rework auth", complete: true },
+          { id: "cl-2", name: "CL-2 Authentication", complete: false },
+        ],
+      };
+
+      expect(incompleteBlockers(blocked).map((b) => b.id)).toEqual(["cl-2"]);
+      expect(
+        incompleteBlockers(issue("cl-3", "Billing", "2026-01-01T00:00:00Z")),
+      ).toEqual([]);
+    });
+  });
+});
@@ -0,0 +1,90 @@
+/**
+ * Task Filter Tests
+ *
+ * Tests the sidebar task list helpers:
+ * - Status tabs and their order
+ * - Fuzzy filtering by title and ID
+ * - Scroll clamping
+ */
+
+import { describe, expect, it } from "vitest";
+import type { BeadsIssue, Task } from "../../types";
+import {
+  clampScroll,
+  filterTasks,
+  nextTaskTab,
+  taskIdentifier,
+} from "../task-filter";
+
+function task(id: string, title: string, status: BeadsIssue["status"]): Task {
+  return {
+    id,
+    title,
+    status,
+    type: "task",
+    priority: 2,
+    createdAt: new Date("2026-01-01T00:00:00Z"),
+    updatedAt: new Date("2026-01-01T00:00:00Z"),
+  };
+}
+
+const tasks = [
+  task("bd-1", "Write migration", "closed"),
+  task("bd-2", "Add login form", "open"),
+  task("bd-3", "Fix flaky test", "in_progress"),
+  task("bd-4", "Wait on API keys", "blocked"),
+  task("bd-5", "Add logout button", "open"),
+];
+
+describe("filterTasks", () => {
+  it("lists every task grouped by status on the all tab", () => {
+    expect(filterTasks(tasks, "", "all").map((t) => t.id)).toEqual([
+      "bd-3",
+      "bd-4",
+      "bd-2",
+      "bd-5",
+      "bd-1",
+    ]);
+  });
+
+  it("keeps only the tab's status", () => {
+    expect(filterTasks(tasks, "", "pending").map((t) => t.id)).toEqual([
+      "bd-2",
+      "bd-5",
+    ]);
+  });
+
+  it("fuzzy-matches titles within the tab", () => {
+    expect(filterTasks(tasks, "logout", "pending").map((t) => t.id)).toEqual([
+      "bd-5",
+    ]);
+    expect(filterTasks(tasks, "logout", "completed")).toEqual([]);
+  });
+
+  it("matches task IDs", () => {
+    expect(filterTasks(tasks, "bd-4", "all")[0]?.id).toBe("bd-4");
+  });
+});
+
+describe("nextTaskTab", () => {
+  it("cycles through the tabs and wraps to all", () => {
+    expect(nextTaskTab("all")).toBe("in_progress");
+    expect(nextTaskTab("completed")).toBe("all");
+  });
+});
+
+describe("taskIdentifier", () => {
+  it("uses the Linear identifier when there is one", () => {
+    const linear = { id: "uuid-1", identifier: "ENG-12" } as Task;
+    expect(taskIdentifier(linear)).toBe("ENG-12");
+    expect(taskIdentifier(tasks[0]!)).toBe("bd-1");
+  });
+});
+
+describe("clampScroll", () => {
+  it("keeps the window inside the list", () => {
+    expect(clampScroll(-3, 50, 10)).toBe(0);
+    expect(clampScroll(45, 50, 10)).toBe(40);
+    expect(clampScroll(5, 4, 10)).toBe(0);
+  });
+});
@@ -24,6 +24,8 @@ export interface BuildClaudeCommandOptions {
   model?: string;
   /** System prompt override (defaults to command file content) */
   systemPrompt?: string;
+  /** Added after the system prompt (e.g., a planning template) */
+  appendSystemPrompt?: string;
   /** Allowed tools override */
   allowedTools?: string[];
   /** Disallowed tools override */
@@ -34,12 +36,45 @@ export interface BuildClaudeCommandOptions {
   addDirs?: string[];
   /** Permission mode (defaults to bypassPermissions for worker, plan for interactive) */
   permissionMode?: string;
+  /** Environment variables set for the claude process (e.g., CLIVE_PARENT_ID) */
+  env?: Record<string, string>;
+  /** Commands run before claude starts; claude only starts if they all succeed */
+  setup?: string[];
+  /** Commands run after claude exits */
+  teardown?: string[];
 }
 
 /**
  * Build a claude CLI command string for interactive execution in a tmux window.
  */
 export function buildClaudeCommand(opts: BuildClaudeCommandOptions): string {
+  const assignments = Object.entries(opts.env ?? {}).map(
+    ([key, value]) => `${key}=${shellEscape(value)}`,
+  );
+  const claude = claudeArgs(opts).join(" ");
+  const setup = opts.setup ?? [];
+  const teardown = opts.teardown ?? [];
+
+  // Environment — shell assignments ahead of the command
+  if (setup.length === 0 && teardown.length === 0) {
+    return [...assignments, claude].join(" ");
+  }
+
+  // With setup/teardown the environment is exported so the commands see it
+  // too, and teardown runs however claude or the setup ended
+  const parts: string[] = [];
+  if (assignments.length > 0) {
+    parts.push(`export ${assignments.join(" ")}`);
+  }
+  parts.push([...setup.map((cmd) => `{ ${cmd}; }`), claude].join(" && "));
+  parts.push(...teardown);
+  return parts.join("; ");
+}
+
+/**
+ * The claude invocation itself, without environment or setup commands.
+ */
+function claudeArgs(opts: BuildClaudeCommandOptions): string[] {
   const args: string[] = ["claude"];
 
   // Load command file for mode-specific config
@@ -58,6 +93,9 @@ export function buildClaudeCommand(opts: BuildClaudeCommandOptions): string {
     // For long system prompts, use --system-prompt with shell escaping
     args.push("--system-prompt", shellEscape(systemPrompt));
   }
+  if (opts.appendSystemPrompt) {
+    args.push("--append-system-prompt", shellEscape(opts.appendSystemPrompt));
+  }
 
   // Permission mode
   const permissionMode = opts.permissionMode || "bypassPermissions";
@@ -98,7 +136,7 @@ export function buildClaudeCommand(opts: BuildClaudeCommandOptions): string {
     args.push(shellEscape(opts.prompt));
   }
 
-  return args.join(" ");
+  return args;
 }
 
 /**
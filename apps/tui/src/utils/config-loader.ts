@@ -237,9 +237,29 @@ function normalizeLinearConfig(
     (linear.team_id as string) ||
     (linear.teamId as string);
 
-  if (!apiKey || !teamID) return undefined;
+  const rawTeamIDs =
+    (linear.teamIDs as unknown) ??
+    (linear.team_ids as unknown) ??
+    (linear.teamIds as unknown);
+  const teamIDs = Array.isArray(rawTeamIDs)
+    ? rawTeamIDs.filter((id): id is string => typeof id === "string" && id !== "")
+    : [];
+
+  // A config listing only teamIDs takes its first team as the primary one
+  const primaryTeamID = teamID || teamIDs[0];
+  if (!apiKey || !primaryTeamID) return undefined;
+
+  const extraTeamIDs = teamIDs.filter((id) => id !== primaryTeamID);
+  return extraTeamIDs.length > 0
+    ? { apiKey, teamID: primaryTeamID, teamIDs: extraTeamIDs }
+    : { apiKey, teamID: primaryTeamID };
+}
 
-  return { apiKey, teamID };
+/**
+ * Every team whose epics are listed: the primary team first, then the rest
+ */
+export function linearTeamIDs(linear: LinearConfig): string[] {
+  return [...new Set([linear.teamID, ...(linear.teamIDs ?? [])])];
 }
 
 /**
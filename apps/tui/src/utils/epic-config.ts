@@ -0,0 +1,132 @@
+/**
+ * Per-epic session config
+ * Environment variables and setup/teardown commands for the plan and build
+ * sessions of one epic, kept in the workspace at .clive/epics/<id>.yaml.
+ *
+ * The file is a small YAML subset, also read by scripts/lib/epic-config.sh:
+ *
+ *   env:
+ *     FEATURE_CHECKOUT: "true"
+ *   setup:
+ *     - docker compose up -d db
+ *   teardown:
+ *     - docker compose down
+ */
+
+import * as fs from "node:fs";
+import * as path from "node:path";
+
+export interface EpicConfig {
+  /** Variables exported for the epic's sessions */
+  env: Record<string, string>;
+  /** Commands run before the session starts, stopping at the first failure */
+  setup: string[];
+  /** Commands run after the session ends */
+  teardown: string[];
+}
+
+type Section = keyof EpicConfig;
+
+const SECTIONS: Section[] = ["env", "setup", "teardown"];
+
+const ENV_NAME = /^[A-Za-z_][A-Za-z0-9_]*$/;
+
+/**
+ * Written when an epic's config is opened for the first time
+ */
+const TEMPLATE = `# Environment and commands for this epic's plan and build sessions
+env:
+  # FEATURE_FLAG: "on"
+setup:
+  # - docker compose up -d db
+teardown:
+  # - docker compose down
+`;
+
+/**
+ * Path of an epic's config file under the workspace
+ */
+export function epicConfigPath(workspaceRoot: string, epicId: string): string {
+  // Identifiers are used as file names, so keep them to one path segment
+  const safeId = epicId.replace(/[^A-Za-z0-9._-]/g, "-");
+  return path.join(workspaceRoot, ".clive", "epics", `${safeId}.yaml`);
+}
+
+/**
+ * Strip one pair of matching quotes around a value
+ */
+function unquote(value: string): string {
+  const match = value.match(/^(["'])(.*)\1$/);
+  return match ? match[2]! : value;
+}
+
+/**
+ * Parse an epic config file. Throws on lines outside the supported subset,
+ * naming the line, so a typo doesn't silently drop a variable.
+ */
+export function parseEpicConfig(text: string): EpicConfig {
+  const config: EpicConfig = { env: {}, setup: [], teardown: [] };
+  let section: Section | null = null;
+
+  text.split("\n").forEach((raw, index) => {
+    const line = raw.replace(/\s+#.*$/, "").trimEnd();
+    const trimmed = line.trim();
+    if (!trimmed || trimmed.startsWith("#")) return;
+    const fail = (expected: string) => {
+      throw new Error(`line ${index + 1}: expected ${expected}, got '${trimmed}'`);
+    };
+
+    if (!/^\s/.test(line)) {
+      const name = trimmed.replace(/:$/, "") as Section;
+      if (!trimmed.endsWith(":") || !SECTIONS.includes(name)) {
+        fail("env:, setup: or teardown:");
+      }
+      section = name;
+      return;
+    }
+
+    if (section === "env") {
+      const match = trimmed.match(/^([^:\s]+):\s*(.*)$/);
+      if (!match || !ENV_NAME.test(match[1]!)) fail("NAME: value");
+      config.env[match![1]!] = unquote(match![2]!);
+    } else if (section === "setup" || section === "teardown") {
+      const match = trimmed.match(/^-\s+(.+)$/);
+      if (!match) fail("- command");
+      config[section].push(unquote(match![1]!));
+    } else {
+      fail("env:, setup: or teardown:");
+    }
+  });
+
+  return config;
+}
+
+/**
+ * Load an epic's config, or null when the epic has none
+ */
+export function loadEpicConfig(
+  workspaceRoot: string,
+  epicId: string,
+): EpicConfig | null {
+  const configPath = epicConfigPath(workspaceRoot, epicId);
+  if (!fs.existsSync(configPath)) return null;
+  try {
+    return parseEpicConfig(fs.readFileSync(configPath, "utf-8"));
+  } catch (error) {
+    console.error(`[epic-config] Ignoring ${configPath}:`, error);
+    return null;
+  }
+}
+
+/**
+ * Create an epic's config from the template if it doesn't exist yet, and
+ * return its path for editing
+ */
+export function ensureEpicConfig(workspaceRoot: string, epicId: string): string {
+  const configPath = epicConfigPath(workspaceRoot, epicId);
+  if (!fs.existsSync(configPath)) {
+    fs.mkdirSync(path.dirname(configPath), { recursive: true });
+    fs.writeFileSync(configPath, TEMPLATE, "utf-8");
+  }
+  return configPath;
+}
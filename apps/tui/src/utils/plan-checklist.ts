@@ -0,0 +1,140 @@
+/**
+ * Plan checklist parser
+ * Reads a hand-written Markdown plan into an epic's tasks, so a plan that
+ * already exists can be imported without an LLM planning session.
+ *
+ * - The first `# Heading` is the epic title, text under it (up to the first
+ *   item, leaving out other headings) its description
+ * - Each `- [ ] item` is a task; items nested under it are its subtasks
+ * - Other lines indented under an item (text, plain bullets, code) are the
+ *   item's description
+ * - `- [x] item` marks work that is already done
+ */
+
+export interface PlanItem {
+  title: string;
+  description: string;
+  done: boolean;
+  subtasks: PlanItem[];
+}
+
+export interface PlanChecklist {
+  /** Epic title from the first top-level heading, if any */
+  title?: string;
+  /** Text between the title and the first item */
+  description: string;
+  tasks: PlanItem[];
+}
+
+const CHECKBOX_ITEM = /^(\s*)(?:[-*+]|\d+[.)])\s+\[([ xX])\]\s+(.+)$/;
+const FENCE = /^\s*(```|~~~)/;
+
+/**
+ * Width of a line's leading whitespace, with tabs as four spaces
+ */
+function indentOf(line: string): number {
+  const leading = line.match(/^\s*/)![0];
+  return leading.replace(/\t/g, "    ").length;
+}
+
+/**
+ * Drop the indentation every non-blank line shares, and blank lines at
+ * either end
+ */
+function dedent(lines: string[]): string {
+  const indents = lines
+    .filter((line) => line.trim() !== "")
+    .map((line) => indentOf(line));
+  const shared = indents.length > 0 ? Math.min(...indents) : 0;
+  return lines
+    .map((line) => line.replace(/\t/g, "    ").slice(shared).trimEnd())
+    .join("\n")
+    .trim();
+}
+
+/**
+ * Parse a Markdown checklist into an epic title, description and tasks
+ */
+export function parsePlanChecklist(markdown: string): PlanChecklist {
+  const plan: PlanChecklist = { description: "", tasks: [] };
+  const epicLines: string[] = [];
+  // Open items, innermost last, with the description lines gathered so far
+  const stack: Array<{ indent: number; item: PlanItem; lines: string[] }> = [];
+  const finished: Array<{ item: PlanItem; lines: string[] }> = [];
+  let inFence = false;
+
+  const closeTo = (indent: number) => {
+    while (stack.length > 0 && stack[stack.length - 1]!.indent >= indent) {
+      finished.push(stack.pop()!);
+    }
+  };
+
+  for (const line of markdown.split("\n")) {
+    const current = stack[stack.length - 1];
+
+    if (FENCE.test(line)) inFence = !inFence;
+    if (inFence || FENCE.test(line)) {
+      if (current) current.lines.push(line);
+      else if (plan.title !== undefined) epicLines.push(line);
+      continue;
+    }
+
+    const match = line.match(CHECKBOX_ITEM);
+    if (match) {
+      const indent = indentOf(match[1]!);
+      closeTo(indent);
+      const item: PlanItem = {
+        title: match[3]!.trim(),
+        description: "",
+        done: match[2] !== " ",
+        subtasks: [],
+      };
+      const parent = stack[stack.length - 1];
+      (parent ? parent.item.subtasks : plan.tasks).push(item);
+      stack.push({ indent, item, lines: [] });
+      continue;
+    }
+
+    if (line.trim() === "") {
+      if (current) current.lines.push(line);
+      else if (plan.title !== undefined) epicLines.push(line);
+      continue;
+    }
+
+    // Text indented under an open item describes the innermost one it's under
+    closeTo(indentOf(line));
+    const owner = stack[stack.length - 1];
+    if (owner) {
+      owner.lines.push(line);
+    } else if (plan.title === undefined && /^#\s+/.test(line)) {
+      plan.title = line.replace(/^#\s+/, "").trim();
+    } else if (
+      plan.title !== undefined &&
+      plan.tasks.length === 0 &&
+      !/^#+\s/.test(line)
+    ) {
+      epicLines.push(line);
+    }
+  }
+  closeTo(0);
+
+  for (const { item, lines } of finished) {
+    item.description = dedent(lines);
+  }
+  plan.description = dedent(epicLines);
+  return plan;
+}
+
+/**
+ * Number of items in a task tree, optionally only those still to do
+ */
+export function countPlanItems(
+  items: PlanItem[],
+  filter: (item: PlanItem) => boolean = () => true,
+): number {
+  return items.reduce(
+    (count, item) =>
+      count + (filter(item) ? 1 : 0) + countPlanItems(item.subtasks, filter),
+    0,
+  );
+}
@@ -0,0 +1,66 @@
+/**
+ * Planning templates
+ * Shape how a plan session structures its epic and tasks for a kind of
+ * work (web app feature, library change, bugfix, infra).
+ *
+ * Built-in templates live in the TUI's templates/plan directory. A project
+ * adds its own, or replaces a built-in of the same file name, in
+ * .clive/templates/<id>.md. Each file has `name` and `description`
+ * frontmatter; its body is added to the plan session's system prompt.
+ */
+
+import * as fs from "node:fs";
+import * as path from "node:path";
+import matter from "gray-matter";
+
+export interface PlanTemplate {
+  /** File name without .md */
+  id: string;
+  name: string;
+  description: string;
+  content: string;
+}
+
+/**
+ * Templates in a directory, keyed by ID
+ */
+function readTemplates(dir: string): Map<string, PlanTemplate> {
+  const templates = new Map<string, PlanTemplate>();
+  if (!fs.existsSync(dir)) return templates;
+
+  for (const file of fs.readdirSync(dir).sort()) {
+    if (!file.endsWith(".md")) continue;
+    const id = file.slice(0, -".md".length);
+    try {
+      const { data, content } = matter(
+        fs.readFileSync(path.join(dir, file), "utf-8"),
+      );
+      templates.set(id, {
+        id,
+        name: typeof data.name === "string" ? data.name : id,
+        description: typeof data.description === "string" ? data.description : "",
+        content: content.trim(),
+      });
+    } catch (error) {
+      console.error(`[plan-templates] Ignoring ${file}:`, error);
+    }
+  }
+  return templates;
+}
+
+/**
+ * The built-in templates followed by the project's, a project template
+ * taking the place of a built-in with the same ID
+ */
+export function loadPlanTemplates(
+  workspaceRoot: string,
+  builtinDir: string = path.join(__dirname, "../../templates/plan"),
+): PlanTemplate[] {
+  const templates = readTemplates(builtinDir);
+  for (const [id, template] of readTemplates(
+    path.join(workspaceRoot, ".clive", "templates"),
+  )) {
+    templates.set(id, template);
+  }
+  return [...templates.values()];
+}
@@ -0,0 +1,235 @@
+/**
+ * Selection filter utilities
+ * Builds, fuzzy-filters, sorts and pages the issue and conversation lists
+ * shown on the selection screen. The view, the selection state machine and
+ * the key handler all go through these so they agree on what row an index
+ * points at.
+ */
+
+import type { Conversation } from "../services/ConversationService";
+import type { EpicBlocker, Session } from "../types";
+
+/**
+ * Special session ID for the "Other Conversations" group
+ */
+export const UNATTACHED_GROUP_ID = "__unattached__";
+
+/**
+ * Number of rows shown per page on the selection screen
+ */
+export const SELECTION_PAGE_SIZE = 10;
+
+/**
+ * Score how well a query fuzzy-matches some text: every query character
+ * must appear in order. Consecutive matches and matches at the start of a
+ * word score higher, gaps score lower. Returns null when it does not match.
+ */
+export function fuzzyScore(query: string, text: string): number | null {
+  const q = query.toLowerCase();
+  const t = text.toLowerCase();
+  if (!q) return 0;
+
+  let score = 0;
+  let ti = 0;
+  let prev = -1;
+  for (const ch of q) {
+    const found = t.indexOf(ch, ti);
+    if (found === -1) return null;
+
+    score += 1;
+    if (found === prev + 1) {
+      score += 3; // consecutive
+    } else if (prev >= 0) {
+      score -= Math.min(found - prev - 1, 3); // gap
+    }
+    if (found === 0 || /[\s\-_/.:]/.test(t[found - 1] ?? "")) {
+      score += 2; // word start
+    }
+    prev = found;
+    ti = found + 1;
+  }
+
+  // Whole-substring matches rank above scattered ones
+  if (t.includes(q)) score += q.length;
+  return score;
+}
+
+/**
+ * Best fuzzy score of a query across several fields
+ */
+function bestScore(query: string, fields: string[]): number | null {
+  let best: number | null = null;
+  for (const field of fields) {
+    const score = fuzzyScore(query, field);
+    if (score !== null && (best === null || score > best)) {
+      best = score;
+    }
+  }
+  return best;
+}
+
+/**
+ * Keep the items matching the query, best match first. Ties keep their
+ * input order, so an activity-sorted list stays sorted within a score.
+ */
+export function fuzzyFilter<T>(
+  items: T[],
+  query: string,
+  fields: (item: T) => string[],
+): T[] {
+  if (!query) return items;
+  const matches: { item: T; index: number; score: number }[] = [];
+  items.forEach((item, index) => {
+    const score = bestScore(query, fields(item));
+    if (score !== null) matches.push({ item, index, score });
+  });
+  return matches
+    .sort((a, b) => b.score - a.score || a.index - b.index)
+    .map((m) => m.item);
+}
+
+function toTime(value: Date | string | number | undefined): number {
+  if (value === undefined) return 0;
+  const time = new Date(value).getTime();
+  return Number.isNaN(time) ? 0 : time;
+}
+
+/**
+ * Whether a conversation is not linked to any Linear issue or project
+ */
+export function isUnattached(conversation: Conversation): boolean {
+  return !conversation.linearProjectId && !conversation.linearTaskId;
+}
+
+/**
+ * Conversations belonging to an issue (or to the "Other Conversations"
+ * group), newest first
+ */
+export function conversationsForIssue(
+  issue: Session,
+  conversations: Conversation[],
+): Conversation[] {
+  const linearId = issue.linearData?.id;
+  const matches =
+    issue.id === UNATTACHED_GROUP_ID
+      ? conversations.filter(isUnattached)
+      : conversations.filter(
+          (c) =>
+            linearId !== undefined &&
+            (c.linearProjectId === linearId || c.linearTaskId === linearId),
+        );
+  return [...matches].sort((a, b) => b.timestamp - a.timestamp);
+}
+
+/**
+ * Most recent activity on an issue: its latest conversation, or failing
+ * that when the issue was last updated or created
+ */
+export function lastActivity(
+  session: Session,
+  conversations: Conversation[],
+): number {
+  const latest = conversationsForIssue(session, conversations)[0];
+  return Math.max(
+    latest?.timestamp ?? 0,
+    toTime(session.linearData?.updatedAt),
+    toTime(session.createdAt),
+  );
+}
+
+/**
+ * Level 1 list: the "Other Conversations" group at the top when there are
+ * unattached conversations, then the issues by most recent activity
+ */
+export function buildIssueList(
+  sessions: Session[],
+  conversations: Conversation[],
+): Session[] {
+  const items: Session[] = [];
+
+  const unattachedCount = conversations.filter(isUnattached).length;
+  if (unattachedCount > 0) {
+    items.push({
+      id: UNATTACHED_GROUP_ID,
+      name: `Other Conversations (${unattachedCount})`,
+      createdAt: new Date(),
+      source: "linear" as const,
+      // No linearData - this marks it as the unattached group
+    });
+  }
+
+  const activity = new Map(
+    sessions.map((s) => [s.id, lastActivity(s, conversations)]),
+  );
+  items.push(
+    ...[...sessions].sort(
+      (a, b) => (activity.get(b.id) ?? 0) - (activity.get(a.id) ?? 0),
+    ),
+  );
+  return items;
+}
+
+/**
+ * Blocking epics that are not finished yet. Starting work on an issue
+ * with any of these should be confirmed first.
+ */
+export function incompleteBlockers(session: Session): EpicBlocker[] {
+  return (session.blockedBy ?? []).filter((blocker) => !blocker.complete);
+}
+
+/**
+ * Fuzzy-filter issues by identifier and title
+ */
+export function filterIssues(issues: Session[], query: string): Session[] {
+  return fuzzyFilter(issues, query, (s) => [
+    s.linearData?.identifier ?? "",
+    s.name,
+  ]);
+}
+
+/**
+ * Fuzzy-filter conversations by first message and slug
+ */
+export function filterConversations(
+  conversations: Conversation[],
+  query: string,
+): Conversation[] {
+  return fuzzyFilter(conversations, query, (c) => [c.display, c.slug ?? ""]);
+}
+
+/**
+ * The rows selectable at the current level, in display order
+ */
+export function selectableItems(
+  selectedIssue: Session | null,
+  sessions: Session[],
+  conversations: Conversation[],
+  searchQuery: string,
+): Session[] | Conversation[] {
+  if (!selectedIssue) {
+    return filterIssues(buildIssueList(sessions, conversations), searchQuery);
+  }
+  return filterConversations(
+    conversationsForIssue(selectedIssue, conversations),
+    searchQuery,
+  );
+}
+
+/**
+ * The page holding the selected row. The "Create New" row (index -1)
+ * sits above the first page.
+ */
+export function pageFor(selectedIndex: number, total: number) {
+  const pageCount = Math.max(1, Math.ceil(total / SELECTION_PAGE_SIZE));
+  const page = Math.min(
+    Math.floor(Math.max(selectedIndex, 0) / SELECTION_PAGE_SIZE),
+    pageCount - 1,
+  );
+  const start = page * SELECTION_PAGE_SIZE;
+  return {
+    page,
+    pageCount,
+    start,
+    end: Math.min(start + SELECTION_PAGE_SIZE, total),
+  };
+}
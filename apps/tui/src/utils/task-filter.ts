@@ -0,0 +1,68 @@
+/**
+ * Task filter utilities
+ * Status tabs, fuzzy filtering and scrolling for the sidebar task list, so
+ * epics with hundreds of tasks stay navigable instead of being cut off.
+ */
+
+import type { Task } from "../types";
+import { fuzzyFilter } from "./selection-filter";
+import { getTaskStatus, type NormalizedStatus } from "./taskHelpers";
+
+/**
+ * Status tab shown above the sidebar task list
+ */
+export type TaskTab = "all" | NormalizedStatus;
+
+/**
+ * Tabs in the order Tab cycles through them
+ */
+export const TASK_TABS: TaskTab[] = [
+  "all",
+  "in_progress",
+  "blocked",
+  "pending",
+  "completed",
+];
+
+/**
+ * Order tasks are listed in under the "all" tab
+ */
+const STATUS_ORDER: NormalizedStatus[] = [
+  "in_progress",
+  "blocked",
+  "pending",
+  "completed",
+];
+
+/**
+ * The tab after the given one, wrapping back to "all"
+ */
+export function nextTaskTab(tab: TaskTab): TaskTab {
+  return TASK_TABS[(TASK_TABS.indexOf(tab) + 1) % TASK_TABS.length] ?? "all";
+}
+
+/**
+ * Short identifier of a task: the Linear identifier, or the beads ID
+ */
+export function taskIdentifier(task: Task): string {
+  return "identifier" in task && task.identifier ? task.identifier : task.id;
+}
+
+/**
+ * Tasks on a tab grouped by status, fuzzy-filtered by title and ID. A
+ * query ranks the best match first.
+ */
+export function filterTasks(tasks: Task[], query: string, tab: TaskTab): Task[] {
+  const statuses = tab === "all" ? STATUS_ORDER : [tab];
+  const onTab = statuses.flatMap((status) =>
+    tasks.filter((t) => getTaskStatus(t) === status),
+  );
+  return fuzzyFilter(onTab, query, (t) => [taskIdentifier(t), t.title]);
+}
+
+/**
+ * Clamp a scroll offset so a window of `rows` stays within `total` items
+ */
+export function clampScroll(offset: number, total: number, rows: number): number {
+  return Math.max(0, Math.min(offset, total - Math.max(rows, 1)));
+}
@@ -8,6 +8,11 @@
 import { execSync } from "node:child_process";
 import { randomUUID } from "node:crypto";
 import { EventEmitter } from "node:events";
+import {
+  type AgentContract,
+  detectCompletion,
+  resolveAgentContract,
+} from "@clive/agent-contract";
 import {
   type ClaudeCliEvent,
   ClaudeCliService,
@@ -56,7 +61,7 @@ NEVER run:
  * Build mode system prompt — tells the agent to execute tasks one at a time
  * with completion markers for the orchestrator's ralph loop.
  */
-const BUILD_SYSTEM_PROMPT = `# Clive Build Mode
+const buildSystemPrompt = (contract: AgentContract) => `# Clive Build Mode
 
 You are Clive, a build execution agent. Execute tasks ONE AT A TIME from Claude Tasks (linked to Linear issues).
 
@@ -73,8 +78,8 @@ You are Clive, a build execution agent. Execute tasks ONE AT A TIME from Claude
 ## Completion Protocol
 
 After completing ONE task, emit EXACTLY ONE of these markers as the LAST thing you output:
-- If more tasks remain: <promise>TASK_COMPLETE</promise>
-- If ALL tasks are done: <promise>ALL_TASKS_COMPLETE</promise>
+- If more tasks remain: ${contract.markers.taskComplete}
+- If ALL tasks are done: ${contract.markers.allTasksComplete}
 
 STOP IMMEDIATELY after emitting the marker. Do not output anything else.
 Execute ONE task per invocation. The orchestrator controls the iteration loop.
@@ -85,10 +90,6 @@ Execute ONE task per invocation. The orchestrator controls the iteration loop.
 **ALWAYS work on your current clive/* feature branch.**
 Commit locally only. The orchestrator handles push + PR creation after all tasks complete.`;
 
-/** Marker constants for build loop detection */
-const TASK_COMPLETE_MARKER = "<promise>TASK_COMPLETE</promise>";
-const ALL_TASKS_COMPLETE_MARKER = "<promise>ALL_TASKS_COMPLETE</promise>";
-
 /** Maximum iterations for a build loop */
 const MAX_BUILD_ITERATIONS = 10;
 
@@ -118,11 +119,14 @@ export class LocalExecutor extends EventEmitter {
   private activeSessions = new Map<string, ActiveSession>();
   private workspaceRoot: string;
   private worktreeManager?: WorktreeManager;
+  /** Completion markers the build loop prompts for and watches */
+  private contract: AgentContract;
 
   constructor(workspaceRoot: string, worktreeManager?: WorktreeManager) {
     super();
     this.workspaceRoot = workspaceRoot;
     this.worktreeManager = worktreeManager;
+    this.contract = resolveAgentContract();
 
     // Create Effect runtime with ClaudeCliService
     const layer = ClaudeCliService.Default;
@@ -176,7 +180,7 @@ export class LocalExecutor extends EventEmitter {
 
     // Select system prompt and initial prompt based on mode
     const systemPrompt = effectiveMode === "build"
-      ? BUILD_SYSTEM_PROMPT
+      ? buildSystemPrompt(this.contract)
       : PLANNING_SKILL_PROMPT;
 
     const prompt = effectiveMode === "build"
@@ -446,6 +450,7 @@ export class LocalExecutor extends EventEmitter {
     sessionWorkspace: string,
     worktreePath?: string,
   ): Promise<"task-complete" | "all-tasks-complete" | "error"> {
+    const contract = this.contract;
     return new Promise((resolve) => {
       let accumulatedText = "";
       let resolved = false;
@@ -495,18 +500,14 @@ export class LocalExecutor extends EventEmitter {
               if (event.type === "text") {
                 accumulatedText += event.content;
 
-                if (accumulatedText.includes(ALL_TASKS_COMPLETE_MARKER)) {
-                  console.log(
-                    `[LocalExecutor] Detected ALL_TASKS_COMPLETE marker`,
-                  );
-                  handle.kill();
-                  resolveOnce("all-tasks-complete");
-                } else if (accumulatedText.includes(TASK_COMPLETE_MARKER)) {
-                  console.log(
-                    `[LocalExecutor] Detected TASK_COMPLETE marker`,
-                  );
+                const signal = detectCompletion(accumulatedText, contract);
+                if (
+                  signal === "all-tasks-complete" ||
+                  signal === "task-complete"
+                ) {
+                  console.log(`[LocalExecutor] Detected ${signal} marker`);
                   handle.kill();
-                  resolveOnce("task-complete");
+                  resolveOnce(signal);
                 }
               }
             }),
@@ -12,6 +12,10 @@ fi
 MEMORY_SERVER="${CLIVE_MEMORY_URL:-https://memory-production-23b6.up.railway.app}"
 MEMORY_API_KEY="${CLIVE_MEMORY_API_KEY:-}"
 CLIVE_NAMESPACE="${CLIVE_NAMESPACE:-}"
+# TLS: trust a self-signed server cert and/or present a client cert (mTLS)
+MEMORY_CA_FILE="${CLIVE_MEMORY_CA_FILE:-}"
+MEMORY_CLIENT_CERT="${CLIVE_MEMORY_CLIENT_CERT:-}"
+MEMORY_CLIENT_KEY="${CLIVE_MEMORY_CLIENT_KEY:-}"
 HOOK_TIMEOUT=5  # seconds
 
 # Read stdin JSON once and cache it. Call early in each hook.
@@ -61,6 +65,13 @@ api_call() {
     args+=(-H "Authorization: Bearer ${MEMORY_API_KEY}")
   fi
 
+  if [ -n "$MEMORY_CA_FILE" ]; then
+    args+=(--cacert "$MEMORY_CA_FILE")
+  fi
+  if [ -n "$MEMORY_CLIENT_CERT" ]; then
+    args+=(--cert "$MEMORY_CLIENT_CERT" --key "${MEMORY_CLIENT_KEY:-$MEMORY_CLIENT_CERT}")
+  fi
+
   # Add namespace header if set
   if [ -n "$CLIVE_NAMESPACE" ]; then
     args+=(-H "X-Clive-Namespace: ${CLIVE_NAMESPACE}")
@@ -39,6 +39,6 @@ SUPERSEDED=$(echo "$RESPONSE" | jq -r '.supersededId // empty' 2>/dev/null) || t
 if [ -n "$SUPERSEDED" ]; then
   echo "Memory ${OLD_ID} superseded by ${NEW_ID}."
 else
-  ERROR=$(echo "$RESPONSE" | jq -r '.error // empty' 2>/dev/null) || true
+  ERROR=$(echo "$RESPONSE" | jq -r '.detail // .error // empty' 2>/dev/null) || true
   echo "Supersede failed: ${ERROR:-unknown error}" >&2
 fi
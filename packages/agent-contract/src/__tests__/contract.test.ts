@@ -0,0 +1,97 @@
+/**
+ * Tests for the Agent Contract
+ *
+ * Verifies defaults, environment and override precedence, validation and
+ * marker detection.
+ */
+
+import { describe, expect, it } from "vitest";
+import {
+  AGENT_CONTRACT_ENV,
+  agentContractEnv,
+  DEFAULT_AGENT_CONTRACT,
+  detectCompletion,
+  resolveAgentContract,
+} from "../index";
+
+describe("resolveAgentContract", () => {
+  it("uses the defaults when nothing is configured", () => {
+    expect(resolveAgentContract({}, {})).toEqual(DEFAULT_AGENT_CONTRACT);
+    expect(DEFAULT_AGENT_CONTRACT.markers.taskComplete).toBe(
+      "<promise>TASK_COMPLETE</promise>",
+    );
+    expect(DEFAULT_AGENT_CONTRACT.exitCodes.allTasksComplete).toBe(10);
+  });
+
+  it("prefers overrides over environment variables", () => {
+    const contract = resolveAgentContract(
+      { markers: { taskComplete: "[[TAREA_HECHA]]" } },
+      {
+        [AGENT_CONTRACT_ENV.taskComplete]: "[[IGNORED]]",
+        [AGENT_CONTRACT_ENV.allTasksComplete]: "[[TODO_HECHO]]",
+        [AGENT_CONTRACT_ENV.allTasksCompleteExitCode]: "42",
+      },
+    );
+
+    expect(contract.markers.taskComplete).toBe("[[TAREA_HECHA]]");
+    expect(contract.markers.allTasksComplete).toBe("[[TODO_HECHO]]");
+    expect(contract.markers.reviewComplete).toBe(
+      DEFAULT_AGENT_CONTRACT.markers.reviewComplete,
+    );
+    expect(contract.exitCodes.allTasksComplete).toBe(42);
+  });
+
+  it("rejects markers that cannot be told apart", () => {
+    expect(() =>
+      resolveAgentContract(
+        { markers: { taskComplete: "DONE", allTasksComplete: "ALL DONE" } },
+        {},
+      ),
+    ).toThrow(/also matches/);
+    expect(() =>
+      resolveAgentContract({}, { [AGENT_CONTRACT_ENV.reviewComplete]: " " }),
+    ).not.toThrow();
+  });
+
+  it("rejects exit codes that collide with success, failure or signals", () => {
+    for (const code of ["0", "1", "130", "ten"]) {
+      expect(() =>
+        resolveAgentContract(
+          {},
+          { [AGENT_CONTRACT_ENV.allTasksCompleteExitCode]: code },
+        ),
+      ).toThrow(/exit code/);
+    }
+  });
+});
+
+describe("detectCompletion", () => {
+  it("finds the marker, all tasks complete first", () => {
+    const contract = DEFAULT_AGENT_CONTRACT;
+    expect(
+      detectCompletion("done <promise>TASK_COMPLETE</promise>", contract),
+    ).toBe("task-complete");
+    expect(
+      detectCompletion(
+        "<promise>TASK_COMPLETE</promise><promise>ALL_TASKS_COMPLETE</promise>",
+        contract,
+      ),
+    ).toBe("all-tasks-complete");
+    expect(detectCompletion("still working", contract)).toBeNull();
+  });
+});
+
+describe("agentContractEnv", () => {
+  it("round-trips through resolveAgentContract", () => {
+    const contract = resolveAgentContract(
+      {
+        markers: { reviewComplete: "<<REVIEWED>>" },
+        exitCodes: { allTasksComplete: 20 },
+      },
+      {},
+    );
+    expect(resolveAgentContract({}, agentContractEnv(contract))).toEqual(
+      contract,
+    );
+  });
+});
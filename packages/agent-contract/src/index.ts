@@ -0,0 +1,188 @@
+/**
+ * Agent Contract Package
+ *
+ * The protocol an agent uses to tell Clive's build loops it is done: the
+ * completion markers it prints and the exit code the iteration scripts use
+ * when every task is complete. The defaults can be replaced, e.g. for
+ * custom prompts or agents that answer in another language, through
+ * environment variables (which also reach the shell scripts) or explicit
+ * overrides.
+ */
+
+/**
+ * Completion markers and special exit codes
+ */
+export interface AgentContract {
+  markers: {
+    /** One task is done and more remain */
+    taskComplete: string;
+    /** Every task is done */
+    allTasksComplete: string;
+    /** A review pass is done */
+    reviewComplete: string;
+  };
+  exitCodes: {
+    /** Exit code of an iteration script when every task is done */
+    allTasksComplete: number;
+  };
+}
+
+/**
+ * Overrides for part of the contract
+ */
+export interface AgentContractOverrides {
+  markers?: Partial<AgentContract["markers"]>;
+  exitCodes?: Partial<AgentContract["exitCodes"]>;
+}
+
+/**
+ * Wrap a marker name in the promise tags agents are asked to emit
+ */
+export function promiseMarker(name: string): string {
+  return `<promise>${name}</promise>`;
+}
+
+/**
+ * The contract used when nothing is configured
+ */
+export const DEFAULT_AGENT_CONTRACT: AgentContract = {
+  markers: {
+    taskComplete: promiseMarker("TASK_COMPLETE"),
+    allTasksComplete: promiseMarker("ALL_TASKS_COMPLETE"),
+    reviewComplete: promiseMarker("REVIEW_COMPLETE"),
+  },
+  exitCodes: {
+    allTasksComplete: 10,
+  },
+};
+
+/**
+ * Environment variables that configure the contract. The build scripts
+ * read the same names.
+ */
+export const AGENT_CONTRACT_ENV = {
+  taskComplete: "CLIVE_TASK_COMPLETE_MARKER",
+  allTasksComplete: "CLIVE_ALL_TASKS_COMPLETE_MARKER",
+  reviewComplete: "CLIVE_REVIEW_COMPLETE_MARKER",
+  allTasksCompleteExitCode: "CLIVE_ALL_TASKS_COMPLETE_EXIT_CODE",
+} as const;
+
+/**
+ * Resolve the contract: overrides win over environment variables, which
+ * win over the defaults. Throws if the result is unusable.
+ */
+export function resolveAgentContract(
+  overrides: AgentContractOverrides = {},
+  env: Record<string, string | undefined> = process.env,
+): AgentContract {
+  const fromEnv = (name: string) => env[name]?.trim() || undefined;
+
+  const exitCodeEnv = fromEnv(AGENT_CONTRACT_ENV.allTasksCompleteExitCode);
+  const contract: AgentContract = {
+    markers: {
+      taskComplete:
+        overrides.markers?.taskComplete ??
+        fromEnv(AGENT_CONTRACT_ENV.taskComplete) ??
+        DEFAULT_AGENT_CONTRACT.markers.taskComplete,
+      allTasksComplete:
+        overrides.markers?.allTasksComplete ??
+        fromEnv(AGENT_CONTRACT_ENV.allTasksComplete) ??
+        DEFAULT_AGENT_CONTRACT.markers.allTasksComplete,
+      reviewComplete:
+        overrides.markers?.reviewComplete ??
+        fromEnv(AGENT_CONTRACT_ENV.reviewComplete) ??
+        DEFAULT_AGENT_CONTRACT.markers.reviewComplete,
+    },
+    exitCodes: {
+      allTasksComplete:
+        overrides.exitCodes?.allTasksComplete ??
+        (exitCodeEnv !== undefined
+          ? Number(exitCodeEnv)
+          : DEFAULT_AGENT_CONTRACT.exitCodes.allTasksComplete),
+    },
+  };
+
+  validateAgentContract(contract);
+  return contract;
+}
+
+/**
+ * Check that markers are set and tell apart, and that the exit code can
+ * not be mistaken for success, a plain failure or a signal.
+ */
+export function validateAgentContract(contract: AgentContract): void {
+  const markers = Object.entries(contract.markers);
+  for (const [name, marker] of markers) {
+    if (!marker.trim()) {
+      throw new Error(`invalid agent contract: ${name} marker is empty`);
+    }
+  }
+  for (const [name, marker] of markers) {
+    for (const [other, otherMarker] of markers) {
+      if (name !== other && otherMarker.includes(marker)) {
+        throw new Error(
+          `invalid agent contract: ${name} marker "${marker}" also matches the ${other} marker`,
+        );
+      }
+    }
+  }
+
+  const code = contract.exitCodes.allTasksComplete;
+  if (!Number.isInteger(code) || code < 2 || code > 125) {
+    throw new Error(
+      `invalid agent contract: allTasksComplete exit code must be an integer from 2 to 125, got ${code}`,
+    );
+  }
+}
+
+/**
+ * The contract as environment variables, to hand to spawned scripts
+ */
+export function agentContractEnv(
+  contract: AgentContract,
+): Record<string, string> {
+  return {
+    [AGENT_CONTRACT_ENV.taskComplete]: contract.markers.taskComplete,
+    [AGENT_CONTRACT_ENV.allTasksComplete]: contract.markers.allTasksComplete,
+    [AGENT_CONTRACT_ENV.reviewComplete]: contract.markers.reviewComplete,
+    [AGENT_CONTRACT_ENV.allTasksCompleteExitCode]: String(
+      contract.exitCodes.allTasksComplete,
+    ),
+  };
+}
+
+/**
+ * What an agent signalled with a completion marker
+ */
+export type CompletionSignal =
+  | "all-tasks-complete"
+  | "task-complete"
+  | "review-complete";
+
+/**
+ * Find the completion marker in agent output, if any. All tasks complete
+ * takes precedence when several appear.
+ */
+export function detectCompletion(
+  text: string,
+  contract: AgentContract,
+): CompletionSignal | null {
+  if (text.includes(contract.markers.allTasksComplete)) {
+    return "all-tasks-complete";
+  }
+  if (text.includes(contract.markers.taskComplete)) {
+    return "task-complete";
+  }
+  if (text.includes(contract.markers.reviewComplete)) {
+    return "review-complete";
+  }
+  return null;
+}
+
+/**
+ * Length of the longest marker, i.e. how much streamed text must be kept
+ * to spot a marker split across chunks
+ */
+export function longestMarkerLength(contract: AgentContract): number {
+  return Math.max(...Object.values(contract.markers).map((m) => m.length));
+}
@@ -43,6 +43,8 @@ export interface BeadsCreateOptions {
   priority?: number;
   description?: string;
   assignee?: string;
+  /** Parent epic or task; the new issue gets a child ID under it */
+  parent?: string;
 }
 
 export interface BeadsUpdateOptions {
@@ -202,6 +204,9 @@ export const BeadsServiceLive = Layer.succeed(
         if (options.assignee) {
           args.push(`--assignee=${options.assignee}`);
         }
+        if (options.parent) {
+          args.push(`--parent=${options.parent}`);
+        }
 
         args.push("--format=json");
 
@@ -59,6 +59,13 @@ export interface LinearIssue {
   children?: {
     nodes: Array<{ id: string }>;
   };
+  // Issues with a "blocks" relation pointing at this one
+  blockedBy?: Array<{
+    id: string;
+    identifier: string;
+    title: string;
+    state: { type: LinearIssue["state"]["type"] };
+  }>;
   createdAt: Date;
   updatedAt: Date;
   url: string;
@@ -99,6 +106,7 @@ export interface LinearCreateIssueOptions {
   projectId?: string;
   stateId?: string;
   labelIds?: string[];
+  parentId?: string;
 }
 
 export interface LinearUpdateIssueOptions {
@@ -113,6 +121,8 @@ export interface LinearUpdateIssueOptions {
 
 export interface LinearListIssuesOptions {
   teamId?: string;
+  /** Issues in any of these teams (merged into one list) */
+  teamIds?: string[];
   projectId?: string;
   assigneeId?: string;
   stateType?: "backlog" | "unstarted" | "started" | "completed" | "canceled";
@@ -358,6 +368,10 @@ export const makeLinearServiceLive = (config: LinearConfig) =>
             filters.push("team: { id: { eq: $teamId } }");
             variables.teamId = options.teamId;
           }
+          if (options?.teamIds && options.teamIds.length > 0) {
+            filters.push("team: { id: { in: $teamIds } }");
+            variables.teamIds = options.teamIds;
+          }
           if (options?.projectId) {
             filters.push("project: { id: { eq: $projectId } }");
             variables.projectId = options.projectId;
@@ -385,6 +399,7 @@ export const makeLinearServiceLive = (config: LinearConfig) =>
           // Determine correct GraphQL types for variables
           const variableTypes: Record<string, string> = {
             teamId: "ID",
+            teamIds: "[ID!]",
             projectId: "ID",
             assigneeId: "ID",
             stateType: "String",
@@ -443,6 +458,19 @@ export const makeLinearServiceLive = (config: LinearConfig) =>
                       id
                     }
                   }
+                  inverseRelations {
+                    nodes {
+                      type
+                      issue {
+                        id
+                        identifier
+                        title
+                        state {
+                          type
+                        }
+                      }
+                    }
+                  }
                   createdAt
                   updatedAt
                   url
@@ -674,6 +702,7 @@ export const makeLinearServiceLive = (config: LinearConfig) =>
           if (options.projectId) input.projectId = options.projectId;
           if (options.stateId) input.stateId = options.stateId;
           if (options.labelIds) input.labelIds = options.labelIds;
+          if (options.parentId) input.parentId = options.parentId;
 
           const response = yield* executeGraphQL<{
             issueCreate: { success: boolean; issue: unknown };
@@ -863,6 +892,16 @@ function parseLinearIssue(raw: any): LinearIssue {
           nodes: raw.children.nodes ?? [],
         }
       : undefined,
+    blockedBy: raw.inverseRelations
+      ? (raw.inverseRelations.nodes ?? [])
+          .filter((relation: any) => relation.type === "blocks")
+          .map((relation: any) => ({
+            id: relation.issue.id,
+            identifier: relation.issue.identifier,
+            title: relation.issue.title,
+            state: { type: relation.issue.state.type },
+          }))
+      : undefined,
     createdAt: new Date(raw.createdAt),
     updatedAt: new Date(raw.updatedAt),
     url: raw.url,